@@ -0,0 +1,154 @@
+package base
+
+import (
+	"fmt"
+	"reflect"
+	"unsafe"
+)
+
+// FittedChecker is implemented by estimators which know whether they have been fitted
+type FittedChecker interface {
+	IsFitted() bool
+}
+
+// CheckFitted returns an error wrapping ErrNotFitted if m is a FittedChecker which has not been fitted
+func CheckFitted(m interface{}) error {
+	if fc, ok := m.(FittedChecker); ok && !fc.IsFitted() {
+		return fmt.Errorf("%w: %T", ErrNotFitted, m)
+	}
+	return nil
+}
+
+// MustBeFitted panics with the CheckFitted error of m, if any
+func MustBeFitted(m interface{}) {
+	if err := CheckFitted(m); err != nil {
+		panic(err)
+	}
+}
+
+// FittedClone returns a copy of the fitted estimator p sharing no mutable state with it. see DeepCopy
+func FittedClone(p Predicter) Predicter { return DeepCopy(p).(Predicter) }
+
+// FittedTransformerClone returns a copy of the fitted transformer t sharing no mutable state with it. see DeepCopy
+func FittedTransformerClone(t Transformer) Transformer { return DeepCopy(t).(Transformer) }
+
+// DeepCopy returns a copy of v sharing no mutable state with it.
+// pointers, slices, maps and interfaces are copied recursively, unexported fields included, and pointers to a same value stay so.
+// slices viewing a same array are copied separately. funcs and channels are shared, sync types are reset,
+// and a SourceCloner is copied by its Clone method
+func DeepCopy(v interface{}) interface{} {
+	if v == nil {
+		return nil
+	}
+	c := deepCopier{seen: map[deepCopyKey]reflect.Value{}}
+	return c.copy(reflect.ValueOf(v)).Interface()
+}
+
+type deepCopyKey struct {
+	ptr uintptr
+	typ reflect.Type
+	len int
+}
+
+type deepCopier struct {
+	seen map[deepCopyKey]reflect.Value
+}
+
+// readable returns v, or a copy of v, from which unexported fields can be read
+func readable(v reflect.Value) reflect.Value {
+	if !v.CanAddr() {
+		a := reflect.New(v.Type()).Elem()
+		a.Set(v)
+		v = a
+	}
+	return v
+}
+
+// field returns the i-th field of addressable struct v, readable and settable even if unexported
+func field(v reflect.Value, i int) reflect.Value {
+	f := v.Field(i)
+	return reflect.NewAt(f.Type(), unsafe.Pointer(f.UnsafeAddr())).Elem()
+}
+
+func isPlainKind(k reflect.Kind) bool {
+	return k >= reflect.Bool && k <= reflect.Complex128 || k == reflect.String
+}
+
+func (c *deepCopier) copy(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+		if sc, ok := v.Interface().(SourceCloner); ok {
+			if cv := reflect.ValueOf(sc.Clone()); cv.Type() == v.Type() {
+				return cv
+			}
+		}
+		key := deepCopyKey{ptr: v.Pointer(), typ: v.Type()}
+		if p, ok := c.seen[key]; ok {
+			return p
+		}
+		p := reflect.New(v.Type().Elem())
+		c.seen[key] = p
+		p.Elem().Set(c.copy(v.Elem()))
+		return p
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		r := reflect.New(v.Type()).Elem()
+		r.Set(c.copy(v.Elem()))
+		return r
+	case reflect.Struct:
+		r := reflect.New(v.Type()).Elem()
+		if v.Type().PkgPath() == "sync" {
+			return r
+		}
+		v = readable(v)
+		for i := 0; i < v.NumField(); i++ {
+			field(r, i).Set(c.copy(field(v, i)))
+		}
+		return r
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		key := deepCopyKey{ptr: v.Pointer(), typ: v.Type(), len: v.Len()}
+		if s, ok := c.seen[key]; ok {
+			return s
+		}
+		r := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		c.seen[key] = r
+		if isPlainKind(v.Type().Elem().Kind()) {
+			reflect.Copy(r, v)
+			return r
+		}
+		for i := 0; i < v.Len(); i++ {
+			r.Index(i).Set(c.copy(v.Index(i)))
+		}
+		return r
+	case reflect.Array:
+		r := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			r.Index(i).Set(c.copy(v.Index(i)))
+		}
+		return r
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		key := deepCopyKey{ptr: v.Pointer(), typ: v.Type()}
+		if m, ok := c.seen[key]; ok {
+			return m
+		}
+		r := reflect.MakeMapWithSize(v.Type(), v.Len())
+		c.seen[key] = r
+		iter := v.MapRange()
+		for iter.Next() {
+			r.SetMapIndex(c.copy(iter.Key()), c.copy(iter.Value()))
+		}
+		return r
+	}
+	return v
+}
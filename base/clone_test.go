@@ -0,0 +1,82 @@
+package base
+
+import (
+	"errors"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+type cloneTestNode struct {
+	Next *cloneTestNode
+	Val  int
+}
+
+type cloneTest struct {
+	Coef       *mat.Dense
+	Classes    [][]float64
+	Shared     []float64
+	View       []float64
+	Index      map[string][]int
+	Node, Same *cloneTestNode
+	Func       func() int
+	Source     Source
+	hidden     []float64
+	fitted     bool
+}
+
+func (m *cloneTest) IsFitted() bool { return m.fitted }
+
+func TestDeepCopy(t *testing.T) {
+	node := &cloneTestNode{Val: 1}
+	node.Next = node
+	shared := []float64{1, 2, 3}
+	m := &cloneTest{
+		Coef:    mat.NewDense(2, 2, []float64{1, 2, 3, 4}),
+		Classes: [][]float64{{0, 1}},
+		Shared:  shared,
+		View:    shared,
+		Index:   map[string][]int{"a": {1}},
+		Node:    node,
+		Same:    node,
+		Func:    func() int { return 42 },
+		Source:  NewSource(7),
+		hidden:  []float64{5},
+	}
+	clone := DeepCopy(m).(*cloneTest)
+	m.Coef.Set(0, 0, -1)
+	m.Classes[0][1] = -1
+	m.Shared[0] = -1
+	m.Index["a"][0] = -1
+	m.Node.Val = -1
+	m.hidden[0] = -1
+	if clone.Coef.At(0, 0) != 1 || clone.Classes[0][1] != 1 || clone.Shared[0] != 1 || clone.Index["a"][0] != 1 || clone.Node.Val != 1 || clone.hidden[0] != 5 {
+		t.Errorf("clone shares state with original %+v", clone)
+	}
+	if &clone.Shared[0] != &clone.View[0] || clone.Node != clone.Same || clone.Node.Next != clone.Node {
+		t.Error("aliasing must be preserved in clone")
+	}
+	if clone.Func() != 42 {
+		t.Error("funcs must be kept")
+	}
+	if clone.Source == m.Source || clone.Source.Uint64() != m.Source.Uint64() {
+		t.Error("source must be cloned")
+	}
+	if DeepCopy(nil) != nil {
+		t.Error("DeepCopy(nil) must be nil")
+	}
+}
+
+func TestCheckFitted(t *testing.T) {
+	m := &cloneTest{}
+	if err := CheckFitted(m); !errors.Is(err, ErrNotFitted) {
+		t.Errorf("expected ErrNotFitted, got %v", err)
+	}
+	m.fitted = true
+	if err := CheckFitted(m); err != nil {
+		t.Error(err)
+	}
+	if err := CheckFitted(struct{}{}); err != nil {
+		t.Errorf("a non FittedChecker must be considered fitted, got %v", err)
+	}
+}
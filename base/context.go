@@ -0,0 +1,125 @@
+package base
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"time"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// Progress is reported to a ProgressFunc at each epoch of a long fit. Loss is NaN for estimators without a loss
+type Progress struct {
+	Epoch   int
+	Loss    float64
+	Elapsed time.Duration
+}
+
+// ProgressFunc receives the progress of a fit. returning a non-nil error stops the fit, which returns that error
+type ProgressFunc func(Progress) error
+
+type progressKey struct{}
+
+// WithProgress returns a copy of ctx carrying fn. FitContext methods report their progress to fn
+func WithProgress(ctx context.Context, fn ProgressFunc) context.Context {
+	return context.WithValue(ctx, progressKey{}, fn)
+}
+
+// ProgressFromContext returns the ProgressFunc set by WithProgress, or nil
+func ProgressFromContext(ctx context.Context) ProgressFunc {
+	if ctx == nil {
+		return nil
+	}
+	fn, _ := ctx.Value(progressKey{}).(ProgressFunc)
+	return fn
+}
+
+// ContextFiter is implemented by estimators whose fit can be cancelled
+type ContextFiter interface {
+	FitContext(ctx context.Context, X, Y mat.Matrix) error
+}
+
+// FitContext calls m.FitContext if m is a ContextFiter. else it checks ctx and calls m.Fit, returning a panic as an error
+func FitContext(ctx context.Context, m Fiter, X, Y mat.Matrix) error {
+	if cf, ok := m.(ContextFiter); ok {
+		return cf.FitContext(ctx, X, Y)
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if fe, ok := m.(FiterE); ok {
+		return fe.FitE(X, Y)
+	}
+	return FitE(m, X, Y)
+}
+
+// Monitor is used inside fit loops to report progress to the ProgressFunc of a context and to know when to stop.
+// a nil *Monitor never stops and reports nothing
+type Monitor struct {
+	ctx   context.Context
+	fn    ProgressFunc
+	start time.Time
+	mu    sync.Mutex
+	err   error
+}
+
+// NewMonitor returns a Monitor for ctx
+func NewMonitor(ctx context.Context) *Monitor {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return &Monitor{ctx: ctx, fn: ProgressFromContext(ctx), start: time.Now()}
+}
+
+// Report sends epoch and loss to the ProgressFunc and returns a non-nil error if the fit must stop
+func (mon *Monitor) Report(epoch int, loss float64) error {
+	if mon == nil {
+		return nil
+	}
+	if err := mon.Err(); err != nil {
+		return err
+	}
+	if mon.fn == nil {
+		return nil
+	}
+	mon.mu.Lock()
+	defer mon.mu.Unlock()
+	if err := mon.fn(Progress{Epoch: epoch, Loss: loss, Elapsed: time.Since(mon.start)}); err != nil && mon.err == nil {
+		mon.err = err
+	}
+	return mon.err
+}
+
+// Err returns the reason why the fit must stop: the error returned by the ProgressFunc or the context error
+func (mon *Monitor) Err() error {
+	if mon == nil {
+		return nil
+	}
+	mon.mu.Lock()
+	defer mon.mu.Unlock()
+	if mon.err == nil {
+		mon.err = mon.ctx.Err()
+	}
+	return mon.err
+}
+
+// ParallelizeContext is Parallelize checking ctx between chunks of samples. f may be called several times by each thread.
+// it returns ctx.Err(), samples may be left unprocessed if it is not nil
+func ParallelizeContext(ctx context.Context, threads, NSamples int, f func(th, start, end int)) error {
+	if threads <= 0 {
+		threads = runtime.NumCPU()
+	}
+	const chunksPerThread = 8
+	Parallelize(threads, NSamples, func(th, start, end int) {
+		chunk := (end - start + chunksPerThread - 1) / chunksPerThread
+		for s := start; s < end && ctx.Err() == nil; s += chunk {
+			e := s + chunk
+			if e > end {
+				e = end
+			}
+			f(th, s, e)
+		}
+	})
+	return ctx.Err()
+}
@@ -0,0 +1,78 @@
+package base
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync/atomic"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestMonitor(t *testing.T) {
+	var nilMon *Monitor
+	if err := nilMon.Report(1, 0); err != nil {
+		t.Errorf("nil Monitor must not stop, got %v", err)
+	}
+	errStop := errors.New("stop")
+	var epochs []int
+	ctx := WithProgress(context.Background(), func(p Progress) error {
+		epochs = append(epochs, p.Epoch)
+		if p.Epoch == 2 {
+			return errStop
+		}
+		return nil
+	})
+	mon := NewMonitor(ctx)
+	for epoch := 1; epoch <= 3; epoch++ {
+		if err := mon.Report(epoch, math.NaN()); err != nil {
+			if !errors.Is(err, errStop) {
+				t.Errorf("expected errStop, got %v", err)
+			}
+			break
+		}
+	}
+	if len(epochs) != 2 || !errors.Is(mon.Err(), errStop) {
+		t.Errorf("expected 2 epochs and errStop, got %v %v", epochs, mon.Err())
+	}
+
+	cctx, cancel := context.WithCancel(context.Background())
+	mon = NewMonitor(cctx)
+	if err := mon.Report(1, 0); err != nil {
+		t.Error(err)
+	}
+	cancel()
+	if err := mon.Report(2, 0); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestFitContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	X := mat.NewDense(3, 2, nil)
+	if err := FitContext(ctx, &panicker{}, X, nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	if err := FitContext(context.Background(), &panicker{err: ErrInvalidParam}, X, nil); !errors.Is(err, ErrInvalidParam) {
+		t.Errorf("expected ErrInvalidParam, got %v", err)
+	}
+}
+
+func TestParallelizeContext(t *testing.T) {
+	var n int64
+	if err := ParallelizeContext(context.Background(), 2, 100, func(th, start, end int) {
+		atomic.AddInt64(&n, int64(end-start))
+	}); err != nil || n != 100 {
+		t.Errorf("expected 100 samples processed, got %d %v", n, err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	n = 0
+	if err := ParallelizeContext(ctx, 2, 100, func(th, start, end int) {
+		atomic.AddInt64(&n, int64(end-start))
+	}); !errors.Is(err, context.Canceled) || n != 0 {
+		t.Errorf("expected context.Canceled and no sample processed, got %d %v", n, err)
+	}
+}
@@ -0,0 +1,121 @@
+package base
+
+import (
+	"errors"
+	"fmt"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// errors returned by the FitE, PredictE and TransformE methods. test them with errors.Is
+var (
+	// ErrShapeMismatch is returned when matrices dimensions are not compatible
+	ErrShapeMismatch = errors.New("shape mismatch")
+	// ErrNotFitted is returned when an estimator is used before being fitted
+	ErrNotFitted = errors.New("not fitted")
+	// ErrInvalidParam is returned when an estimator parameter has an invalid value
+	ErrInvalidParam = errors.New("invalid parameter")
+)
+
+// FiterE is the error returning counterpart of Fiter
+type FiterE interface {
+	FitE(X, Y mat.Matrix) error
+}
+
+// PredicterE is a Predicter whose FitE and PredictE methods return an error instead of panicking
+type PredicterE interface {
+	Predicter
+	FiterE
+	PredictE(X mat.Matrix, Y mat.Mutable) (*mat.Dense, error)
+}
+
+// TransformerE is a Transformer whose FitE and TransformE methods return an error instead of panicking
+type TransformerE interface {
+	Transformer
+	FiterE
+	TransformE(X, Y mat.Matrix) (Xout, Yout *mat.Dense, err error)
+}
+
+// Recover stores a recovered panic into *err. use it as: defer base.Recover(&err)
+func Recover(err *error) {
+	if r := recover(); r != nil {
+		if e, ok := r.(error); ok {
+			*err = e
+		} else {
+			*err = fmt.Errorf("%v", r)
+		}
+	}
+}
+
+func isNilMatrix(X mat.Matrix) bool {
+	if X == nil {
+		return true
+	}
+	if d, ok := X.(*mat.Dense); ok && (d == nil || d.IsZero()) {
+		return true
+	}
+	return false
+}
+
+// CheckXY returns an ErrShapeMismatch error if X is missing or if X and Y have a different number of rows. Y may be nil
+func CheckXY(X, Y mat.Matrix) error {
+	if isNilMatrix(X) {
+		return fmt.Errorf("%w: X is empty", ErrShapeMismatch)
+	}
+	if isNilMatrix(Y) {
+		return nil
+	}
+	rx, _ := X.Dims()
+	ry, _ := Y.Dims()
+	if rx != ry {
+		return fmt.Errorf("%w: X has %d rows, Y has %d", ErrShapeMismatch, rx, ry)
+	}
+	return nil
+}
+
+// CheckNFeatures returns an ErrShapeMismatch error if X has not nFeatures columns
+func CheckNFeatures(X mat.Matrix, nFeatures int) error {
+	if isNilMatrix(X) {
+		return fmt.Errorf("%w: X is empty", ErrShapeMismatch)
+	}
+	if _, c := X.Dims(); c != nFeatures {
+		return fmt.Errorf("%w: X has %d features, expected %d", ErrShapeMismatch, c, nFeatures)
+	}
+	return nil
+}
+
+// FitE checks X and Y and calls m.Fit, returning a panic as an error
+func FitE(m Fiter, X, Y mat.Matrix) (err error) {
+	if err = CheckXY(X, Y); err != nil {
+		return
+	}
+	defer Recover(&err)
+	m.Fit(X, Y)
+	return
+}
+
+// PredictE checks that m is fitted, checks X and Y and calls m.Predict, returning a panic as an error
+func PredictE(m Predicter, X mat.Matrix, Y mat.Mutable) (Ypred *mat.Dense, err error) {
+	if err = CheckFitted(m); err != nil {
+		return
+	}
+	if err = CheckXY(X, Y); err != nil {
+		return
+	}
+	defer Recover(&err)
+	Ypred = m.Predict(X, Y)
+	return
+}
+
+// TransformE checks that m is fitted, checks X and Y and calls m.Transform, returning a panic as an error
+func TransformE(m Transformer, X, Y mat.Matrix) (Xout, Yout *mat.Dense, err error) {
+	if err = CheckFitted(m); err != nil {
+		return
+	}
+	if err = CheckXY(X, Y); err != nil {
+		return
+	}
+	defer Recover(&err)
+	Xout, Yout = m.Transform(X, Y)
+	return
+}
@@ -0,0 +1,39 @@
+package base
+
+import (
+	"errors"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+type panicker struct{ err interface{} }
+
+func (m *panicker) Fit(X, Y mat.Matrix) Fiter { panic(m.err) }
+
+func TestFitE(t *testing.T) {
+	X, Y := mat.NewDense(3, 2, nil), mat.NewDense(2, 1, nil)
+	if err := FitE(&panicker{}, X, Y); !errors.Is(err, ErrShapeMismatch) {
+		t.Errorf("expected ErrShapeMismatch, got %v", err)
+	}
+	if err := FitE(&panicker{}, nil, nil); !errors.Is(err, ErrShapeMismatch) {
+		t.Errorf("expected ErrShapeMismatch for nil X, got %v", err)
+	}
+	if err := FitE(&panicker{err: ErrInvalidParam}, X, nil); !errors.Is(err, ErrInvalidParam) {
+		t.Errorf("expected ErrInvalidParam, got %v", err)
+	}
+	if err := FitE(&panicker{err: "boom"}, X, nil); err == nil || err.Error() != "boom" {
+		t.Errorf("expected boom, got %v", err)
+	}
+}
+
+func TestMatDimsCheck(t *testing.T) {
+	var err error
+	func() {
+		defer Recover(&err)
+		MatDimsCheck(".", mat.NewDense(2, 2, nil), mat.NewDense(2, 3, nil), mat.NewDense(2, 2, nil))
+	}()
+	if !errors.Is(err, ErrShapeMismatch) {
+		t.Errorf("expected ErrShapeMismatch, got %v", err)
+	}
+}
@@ -0,0 +1,149 @@
+package base
+
+import (
+	"fmt"
+
+	"gonum.org/v1/gonum/blas/blas32"
+	"gonum.org/v1/gonum/mat"
+)
+
+// General32 is a float32 row major matrix, like blas32.General. it implements mat.Matrix with half the memory of a *mat.Dense.
+// estimators having a float32 path don't convert a General32 X to float64
+type General32 blas32.General
+
+var _ mat.Matrix = General32{}
+
+// NewGeneral32 returns a General32 with r rows and c columns. data is used as is if not nil
+func NewGeneral32(r, c int, data []float32) General32 {
+	if data == nil {
+		data = make([]float32, r*c)
+	}
+	if len(data) != r*c {
+		panic(fmt.Errorf("%w: len(data)=%d for %dx%d", ErrShapeMismatch, len(data), r, c))
+	}
+	return General32{Rows: r, Cols: c, Stride: c, Data: data}
+}
+
+// General32Of returns a float32 copy of m
+func General32Of(m mat.Matrix) General32 {
+	if g, ok := m.(General32); ok {
+		return NewGeneral32(g.Rows, g.Cols, nil).copyFrom(g)
+	}
+	r, c := m.Dims()
+	g := NewGeneral32(r, c, nil)
+	if d, ok := m.(mat.RawMatrixer); ok {
+		dm := d.RawMatrix()
+		for i := 0; i < r; i++ {
+			row := g.RawRowView(i)
+			for j, v := range dm.Data[i*dm.Stride : i*dm.Stride+c] {
+				row[j] = float32(v)
+			}
+		}
+		return g
+	}
+	for i := 0; i < r; i++ {
+		for j := 0; j < c; j++ {
+			g.Data[i*g.Stride+j] = float32(m.At(i, j))
+		}
+	}
+	return g
+}
+
+// IsFloat32 returns true if m is a General32
+func IsFloat32(m mat.Matrix) bool {
+	_, ok := m.(General32)
+	return ok
+}
+
+// asGeneral32 returns m or the matrix transposed by m if it is a General32
+func asGeneral32(m mat.Matrix) (g General32, transposed, ok bool) {
+	switch v := m.(type) {
+	case General32:
+		return v, false, true
+	case MatTranspose:
+		g, ok = v.Matrix.(General32)
+		return g, true, ok
+	}
+	return
+}
+
+func (m General32) copyFrom(src General32) General32 {
+	for i := 0; i < m.Rows; i++ {
+		copy(m.RawRowView(i), src.RawRowView(i))
+	}
+	return m
+}
+
+// Dims for General32
+func (m General32) Dims() (int, int) { return m.Rows, m.Cols }
+
+// At for General32
+func (m General32) At(i, j int) float64 {
+	if uint(i) >= uint(m.Rows) || uint(j) >= uint(m.Cols) {
+		panic(mat.ErrIndexOutOfRange)
+	}
+	return float64(m.Data[i*m.Stride+j])
+}
+
+// Set for General32
+func (m General32) Set(i, j int, v float64) {
+	if uint(i) >= uint(m.Rows) || uint(j) >= uint(m.Cols) {
+		panic(mat.ErrIndexOutOfRange)
+	}
+	m.Data[i*m.Stride+j] = float32(v)
+}
+
+// T for General32
+func (m General32) T() mat.Matrix { return MatTranspose{Matrix: m} }
+
+// RawRowView returns row i of m, sharing its storage
+func (m General32) RawRowView(i int) []float32 { return m.Data[i*m.Stride : i*m.Stride+m.Cols] }
+
+// Row returns row i of m as float64 in dst, which is allocated if nil
+func (m General32) Row(dst []float64, i int) []float64 {
+	if dst == nil {
+		dst = make([]float64, m.Cols)
+	}
+	for j, v := range m.RawRowView(i) {
+		dst[j] = float64(v)
+	}
+	return dst
+}
+
+// RowSlice returns rows i to k-1 of m, sharing its storage
+func (m General32) RowSlice(i, k int) General32 {
+	if k < i || i < 0 || k > m.Rows {
+		panic(mat.ErrIndexOutOfRange)
+	}
+	if k == i {
+		return General32{Cols: m.Cols, Stride: m.Stride}
+	}
+	return General32{Rows: k - i, Cols: m.Cols, Stride: m.Stride, Data: m.Data[i*m.Stride : (k-1)*m.Stride+m.Cols]}
+}
+
+// SelectRows returns a General32 made of copies of rows of m
+func (m General32) SelectRows(rows []int) General32 {
+	g := NewGeneral32(len(rows), m.Cols, nil)
+	for ii, i := range rows {
+		copy(g.RawRowView(ii), m.RawRowView(i))
+	}
+	return g
+}
+
+// ToDense returns a float64 copy of m
+func (m General32) ToDense() *mat.Dense {
+	d := mat.NewDense(m.Rows, m.Cols, nil)
+	for i := 0; i < m.Rows; i++ {
+		m.Row(d.RawRowView(i), i)
+	}
+	return d
+}
+
+// Dot32 returns the dot product of a and b
+func Dot32(a, b []float32) float64 {
+	var s float64
+	for i, v := range a {
+		s += float64(v) * float64(b[i])
+	}
+	return s
+}
@@ -0,0 +1,37 @@
+package base
+
+import (
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestGeneral32(t *testing.T) {
+	X := randomSparseDense(6, 4, .8)
+	X32 := General32Of(X)
+	if !mat.EqualApprox(X, X32, 1e-6) || !mat.EqualApprox(X, ToDense(X32), 1e-6) || !mat.EqualApprox(X.T(), X32.T(), 1e-6) {
+		t.Error("General32 differs from its float64 origin")
+	}
+	if !mat.EqualApprox(X.Slice(1, 4, 0, 4), X32.RowSlice(1, 4), 1e-6) {
+		t.Error("RowSlice differs")
+	}
+	if sel := X32.SelectRows([]int{5, 2}); !mat.EqualApprox(X.RowView(2).T(), sel.RowSlice(1, 2), 1e-6) {
+		t.Error("SelectRows differs")
+	}
+	X32.Set(0, 0, 3)
+	if X32.At(0, 0) != 3 || X.At(0, 0) == 3 {
+		t.Error("General32Of must copy")
+	}
+	B := randomSparseDense(4, 3, 1)
+	want := &mat.Dense{}
+	want.Mul(X32, B)
+	if got := MatMul(&mat.Dense{}, X32, B); !mat.EqualApprox(want, got, 1e-12) {
+		t.Errorf("MatMul: expected\n%g\ngot\n%g", mat.Formatted(want), mat.Formatted(got))
+	}
+	C := randomSparseDense(6, 2, 1)
+	want.Reset()
+	want.Mul(X32.T(), C)
+	if got := MatMul(&mat.Dense{}, X32.T(), C); !mat.EqualApprox(want, got, 1e-12) {
+		t.Errorf("MatMul transposed: expected\n%g\ngot\n%g", mat.Formatted(want), mat.Formatted(got))
+	}
+}
@@ -26,3 +26,18 @@ type Transformer interface {
 	FitTransform(X, Y mat.Matrix) (Xout, Yout *mat.Dense)
 	TransformerClone() Transformer
 }
+
+// ProbaPredicter is a classifier which estimates class probabilities.
+// PredictProba returns a column per class, classes sorted by value, the columns of each output following each other.
+// a binary output has 2 columns. if Y is nil, it is allocated
+type ProbaPredicter interface {
+	Predicter
+	PredictProba(X mat.Matrix, Y mat.Mutable) *mat.Dense
+}
+
+// DecisionFunctioner is a classifier which returns a confidence score for each class, higher meaning more confident.
+// binary outputs may have a single column, the score of the positive class. if Y is nil, it is allocated
+type DecisionFunctioner interface {
+	Predicter
+	DecisionFunction(X mat.Matrix, Y mat.Mutable) *mat.Dense
+}
@@ -99,11 +99,11 @@ func MatDimsCheck(op string, R, X, Y mat.Matrix) {
 	switch op {
 	case "+", "-", "*", "/":
 		if rx != ry || cx != cy || rr != rx || cr != cx {
-			panic(fmt.Errorf("%s %s", op, MatDimsString(R, X, Y)))
+			panic(fmt.Errorf("%w: %s %s", ErrShapeMismatch, op, MatDimsString(R, X, Y)))
 		}
 	case ".":
 		if cx != ry || rr != rx || cr != cy {
-			panic(fmt.Errorf("%s %s", op, MatDimsString(R, X, Y)))
+			panic(fmt.Errorf("%w: %s %s", ErrShapeMismatch, op, MatDimsString(R, X, Y)))
 		}
 	}
 }
@@ -196,8 +196,15 @@ func MatGeneralColSlice(M blas64.General, j, l int) blas64.General {
 
 // ToDense returns w view of m if m is a RawMatrixer, et returns a dense copy of m
 func ToDense(m mat.Matrix) *mat.Dense {
-	if d, ok := m.(*mat.Dense); ok {
-		return d
+	switch v := m.(type) {
+	case *mat.Dense:
+		return v
+	case *CSR:
+		return v.ToDense()
+	case *CSC:
+		return v.ToDense()
+	case General32:
+		return v.ToDense()
 	}
 	if m == mat.Matrix(nil) {
 		return &mat.Dense{}
@@ -214,11 +221,15 @@ func ToDense(m mat.Matrix) *mat.Dense {
 	return ret
 }
 
-// FromDense fills dst (mat.Mutable) with src (mat.Dense)
+// FromDense fills dst (mat.Mutable) with src (mat.Dense). an empty *mat.Dense dst is set to a copy of dense
 func FromDense(dst mat.Mutable, dense *mat.Dense) *mat.Dense {
 	if dst == mat.Mutable(nil) {
 		return dense
 	}
+	if d, ok := dst.(*mat.Dense); ok && d != dense && d.IsZero() {
+		d.CloneFrom(dense)
+		return d
+	}
 	src := dense.RawMatrix()
 	if rawmatrixer, ok := dst.(mat.RawMatrixer); ok {
 		dstmat := rawmatrixer.RawMatrix()
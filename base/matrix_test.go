@@ -78,5 +78,12 @@ func TestToDense(t *testing.T) {
 	if fmt.Sprintf("%g", mat.Formatted(m2)) != "[1]" {
 		t.Fail()
 	}
-
+	d3 := &mat.Dense{}
+	if m3 := FromDense(d3, d); m3 != d3 || !mat.Equal(d3, d) {
+		t.Error("an empty dst must be filled with src")
+	}
+	d.Set(0, 0, 2)
+	if d3.At(0, 0) != 1 {
+		t.Error("an empty dst must not share the storage of src")
+	}
 }
@@ -0,0 +1,333 @@
+package base
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ParamSeparator separates the name of a nested estimator from the name of its parameter, as in "svc__C"
+const ParamSeparator = "__"
+
+// Params is implemented by estimators whose parameters can be read and set by name, as sklearn get_params and set_params.
+// keys of nested estimators parameters are prefixed by the field or step name and ParamSeparator
+type Params interface {
+	GetParams() map[string]interface{}
+	SetParams(params map[string]interface{}) error
+}
+
+// GetParams returns m.GetParams() if m is a Params, else GetFieldParams(m)
+func GetParams(m interface{}) map[string]interface{} {
+	if p, ok := m.(Params); ok {
+		return p.GetParams()
+	}
+	return GetFieldParams(m)
+}
+
+// SetParams calls m.SetParams if m is a Params, else SetFieldParams(m, params)
+func SetParams(m interface{}, params map[string]interface{}) error {
+	if p, ok := m.(Params); ok {
+		return p.SetParams(params)
+	}
+	return SetFieldParams(m, params)
+}
+
+// GetFieldParams returns the exported fields of the struct pointed by m which have a scalar, string, func, interface
+// or slice of scalars type. fields of embedded structs are included.
+// fields tagged `param:"-"`, such as the members filled by Fit, are not parameters.
+// fields holding a Params are also returned with their own params under "field__param" keys
+func GetFieldParams(m interface{}) map[string]interface{} {
+	params := make(map[string]interface{})
+	v := reflect.Indirect(reflect.ValueOf(m))
+	if v.Kind() != reflect.Struct {
+		return params
+	}
+	for _, f := range paramFields(v) {
+		fv := v.FieldByIndex(f.Index)
+		switch {
+		case isParamType(f.Type):
+			params[f.Name] = fv.Interface()
+		case fv.Kind() == reflect.Ptr && !fv.IsNil() && fv.Type().Implements(paramsType):
+			params[f.Name] = fv.Interface()
+		default:
+			continue
+		}
+		if (fv.Kind() == reflect.Interface || fv.Kind() == reflect.Ptr) && !fv.IsNil() {
+			if nested, ok := fv.Interface().(Params); ok {
+				for k, nv := range nested.GetParams() {
+					params[f.Name+ParamSeparator+k] = nv
+				}
+			}
+		}
+	}
+	return params
+}
+
+// SetFieldParams sets the exported fields of the struct pointed by m which GetFieldParams returns.
+// names are matched ignoring case and underscores, so "max_iter" sets MaxIter. numeric values are converted when it
+// can be done without loss. a "field__param" key sets param on the estimator held by field.
+// an ErrInvalidParam error is returned for unknown names and values of the wrong type, m being then left unchanged
+func SetFieldParams(m interface{}, params map[string]interface{}) error {
+	v := reflect.ValueOf(m)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("%w: %T is not a pointer to a struct", ErrInvalidParam, m)
+	}
+	v = v.Elem()
+	own, nested := SplitParams(params)
+	// values are converted and nested params are checked on copies of the nested estimators before any field is set
+	fields, values := make([]reflect.Value, 0, len(own)), make([]reflect.Value, 0, len(own))
+	for _, k := range sortedParamKeys(own) {
+		field, ok := findParamField(v, k)
+		if !ok {
+			return fmt.Errorf("%w: no parameter %s in %T", ErrInvalidParam, k, m)
+		}
+		value := reflect.New(field.Type()).Elem()
+		if err := SetParamValue(value, own[k]); err != nil {
+			return fmt.Errorf("%s: %w", k, err)
+		}
+		fields, values = append(fields, field), append(values, value)
+	}
+	targets := make([]interface{}, 0, len(nested))
+	for _, k := range sortedNestedKeys(nested) {
+		field, ok := findParamField(v, k)
+		if !ok {
+			return fmt.Errorf("%w: no parameter %s in %T", ErrInvalidParam, k, m)
+		}
+		var target interface{}
+		switch {
+		case field.Kind() == reflect.Struct:
+			target = field.Addr().Interface()
+		case field.Kind() == reflect.Ptr && !field.IsNil():
+			target = field.Interface()
+		case field.Kind() == reflect.Interface && !field.IsNil() && field.Elem().Kind() == reflect.Ptr:
+			target = field.Interface()
+		default:
+			return fmt.Errorf("%w: %s in %T does not hold an estimator", ErrInvalidParam, k, m)
+		}
+		if err := SetParams(DeepCopy(target), nested[k]); err != nil {
+			return fmt.Errorf("%s: %w", k, err)
+		}
+		targets = append(targets, target)
+	}
+	for i, field := range fields {
+		field.Set(values[i])
+	}
+	for i, k := range sortedNestedKeys(nested) {
+		if err := SetParams(targets[i], nested[k]); err != nil {
+			return fmt.Errorf("%s: %w", k, err)
+		}
+	}
+	return nil
+}
+
+// SplitParams separates params without ParamSeparator from nested ones, which are grouped by prefix
+func SplitParams(params map[string]interface{}) (own map[string]interface{}, nested map[string]map[string]interface{}) {
+	own = make(map[string]interface{})
+	nested = make(map[string]map[string]interface{})
+	for k, v := range params {
+		i := strings.Index(k, ParamSeparator)
+		if i < 0 {
+			own[k] = v
+			continue
+		}
+		prefix, name := k[:i], k[i+len(ParamSeparator):]
+		if nested[prefix] == nil {
+			nested[prefix] = make(map[string]interface{})
+		}
+		nested[prefix][name] = v
+	}
+	return
+}
+
+// ParamNameEqual returns true if a and b are the same parameter name ignoring case and underscores
+func ParamNameEqual(a, b string) bool {
+	return normalizeParamName(a) == normalizeParamName(b)
+}
+
+func normalizeParamName(name string) string {
+	return strings.ToLower(strings.Replace(name, "_", "", -1))
+}
+
+var paramsType = reflect.TypeOf((*Params)(nil)).Elem()
+
+func sortedParamKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sortedNestedKeys(m map[string]map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// paramFields returns exported fields of v, including those of embedded structs, but those tagged `param:"-"`.
+// outer fields shadow embedded ones
+func paramFields(v reflect.Value) []reflect.StructField {
+	var fields []reflect.StructField
+	seen := make(map[string]bool)
+	var walk func(t reflect.Type, index []int)
+	walk = func(t reflect.Type, index []int) {
+		var embedded []reflect.StructField
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			f.Index = append(append([]int{}, index...), i)
+			if f.Tag.Get("param") == "-" {
+				continue
+			}
+			if f.Anonymous {
+				if f.Type.Kind() == reflect.Struct {
+					embedded = append(embedded, f)
+				}
+				continue
+			}
+			if f.PkgPath != "" || seen[f.Name] {
+				continue
+			}
+			seen[f.Name] = true
+			fields = append(fields, f)
+		}
+		for _, f := range embedded {
+			walk(f.Type, f.Index)
+		}
+	}
+	walk(v.Type(), nil)
+	return fields
+}
+
+func findParamField(v reflect.Value, name string) (reflect.Value, bool) {
+	for _, f := range paramFields(v) {
+		if ParamNameEqual(f.Name, name) {
+			return v.FieldByIndex(f.Index), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+func isScalarKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+func isParamType(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Interface, reflect.Func:
+		return true
+	case reflect.Slice:
+		return isScalarKind(t.Elem().Kind())
+	}
+	return isScalarKind(t.Kind())
+}
+
+// SetParamValue sets field to value, converting numbers and slices when no information is lost
+func SetParamValue(field reflect.Value, value interface{}) error {
+	if !field.CanSet() {
+		return fmt.Errorf("%w: field can't be set", ErrInvalidParam)
+	}
+	failed := func() error {
+		return fmt.Errorf("%w: can't set %s to %v (%T)", ErrInvalidParam, field.Type(), value, value)
+	}
+	if value == nil {
+		switch field.Kind() {
+		case reflect.Interface, reflect.Func, reflect.Slice, reflect.Ptr, reflect.Map:
+			field.Set(reflect.Zero(field.Type()))
+			return nil
+		}
+		return failed()
+	}
+	vv := reflect.ValueOf(value)
+	if vv.Type().AssignableTo(field.Type()) {
+		field.Set(vv)
+		return nil
+	}
+	switch field.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, ok := toInt64(vv)
+		if !ok || field.OverflowInt(i) {
+			return failed()
+		}
+		field.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		i, ok := toInt64(vv)
+		if !ok || i < 0 || field.OverflowUint(uint64(i)) {
+			return failed()
+		}
+		field.SetUint(uint64(i))
+	case reflect.Float32, reflect.Float64:
+		f, ok := toFloat64(vv)
+		if !ok {
+			return failed()
+		}
+		field.SetFloat(f)
+	case reflect.String:
+		if vv.Kind() != reflect.String {
+			return failed()
+		}
+		field.SetString(vv.String())
+	case reflect.Bool:
+		if vv.Kind() != reflect.Bool {
+			return failed()
+		}
+		field.SetBool(vv.Bool())
+	case reflect.Slice:
+		if vv.Kind() != reflect.Slice && vv.Kind() != reflect.Array {
+			return failed()
+		}
+		s := reflect.MakeSlice(field.Type(), vv.Len(), vv.Len())
+		for i := 0; i < vv.Len(); i++ {
+			if err := SetParamValue(s.Index(i), vv.Index(i).Interface()); err != nil {
+				return failed()
+			}
+		}
+		field.Set(s)
+	default:
+		return failed()
+	}
+	return nil
+}
+
+func toInt64(v reflect.Value) (int64, bool) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if v.Uint() > math.MaxInt64 {
+			return 0, false
+		}
+		return int64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		if f != math.Trunc(f) || f < math.MinInt64 || f > math.MaxInt64 {
+			return 0, false
+		}
+		return int64(f), true
+	}
+	return 0, false
+}
+
+func toFloat64(v reflect.Value) (float64, bool) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	}
+	return 0, false
+}
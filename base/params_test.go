@@ -0,0 +1,82 @@
+package base
+
+import (
+	"errors"
+	"testing"
+)
+
+type paramsTestEmbedded struct {
+	Tol  float64
+	Name string
+}
+
+type paramsTest struct {
+	paramsTestEmbedded
+	Name        string
+	MaxIter     int
+	Alpha       float32
+	HiddenSizes []int
+	Options     paramsTestEmbedded
+	Child       interface{}
+	unexported  int
+	// Labels is filled by Fit
+	Labels []int `param:"-"`
+}
+
+func TestSetFieldParams(t *testing.T) {
+	m := &paramsTest{Child: &paramsTest{}}
+	err := SetFieldParams(m, map[string]interface{}{
+		"max_iter":     10.,
+		"alpha":        1,
+		"tol":          1e-3,
+		"name":         "outer",
+		"hidden_sizes": []interface{}{10., 5.},
+		"options__tol": 2,
+		"child__name":  "child",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.MaxIter != 10 || m.Alpha != 1 || m.Tol != 1e-3 || m.Name != "outer" || m.paramsTestEmbedded.Name != "" ||
+		len(m.HiddenSizes) != 2 || m.HiddenSizes[1] != 5 || m.Options.Tol != 2 || m.Child.(*paramsTest).Name != "child" {
+		t.Errorf("unexpected %#v", m)
+	}
+	for _, params := range []map[string]interface{}{
+		{"nosuchparam": 1},
+		{"unexported": 1},
+		{"max_iter": 1.5},
+		{"max_iter": "10"},
+		{"name": 1},
+		{"hidden_sizes": []string{"a"}},
+		{"child__nosuchparam": 1},
+		{"max_iter__tol": 1},
+		{"labels": []int{1}},
+		{"max_iter": 20, "name": 1},
+		{"max_iter": 20, "child__nosuchparam": 1},
+		{"child__max_iter": 20, "child__name": 1},
+	} {
+		if err := SetFieldParams(m, params); !errors.Is(err, ErrInvalidParam) {
+			t.Errorf("%v: expected ErrInvalidParam, got %v", params, err)
+		}
+		if m.MaxIter != 10 || m.Labels != nil || m.Child.(*paramsTest).MaxIter != 0 {
+			t.Errorf("%v: m must be left unchanged on error, got %#v", params, m)
+		}
+	}
+}
+
+func TestGetFieldParams(t *testing.T) {
+	m := &paramsTest{MaxIter: 3, paramsTestEmbedded: paramsTestEmbedded{Tol: 1e-4}}
+	params := GetFieldParams(m)
+	if params["MaxIter"] != 3 || params["Tol"] != 1e-4 {
+		t.Errorf("unexpected %v", params)
+	}
+	if _, ok := params["Options"]; ok {
+		t.Error("struct fields must not be returned")
+	}
+	if _, ok := params["unexported"]; ok {
+		t.Error("unexported fields must not be returned")
+	}
+	if _, ok := params["Labels"]; ok {
+		t.Error("fields tagged param:\"-\" must not be returned")
+	}
+}
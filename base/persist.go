@@ -0,0 +1,780 @@
+package base
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"sync"
+
+	"gonum.org/v1/gonum/blas/blas64"
+	"gonum.org/v1/gonum/mat"
+)
+
+// PersistFormat and PersistVersion are written in the header of files written by Save
+const (
+	PersistFormat  = "github.com/pa-m/sklearn"
+	PersistVersion = 1
+)
+
+var persistMagic = []byte("GOSKLRN\x00")
+
+// ErrNotPersistable is returned when a model holds state which can't be saved, such as a user func or an interface
+// holding a value of an unregistered type
+var ErrNotPersistable = errors.New("not persistable")
+
+// Persister is implemented by models which can be saved by Save and reloaded by Load
+type Persister interface {
+	MarshalState() (*State, error)
+	UnmarshalState(st *State) error
+}
+
+// State is the saved content of a model: JSON encoded fields, matrices saved as binary blobs and sub-models
+type State struct {
+	Type     string
+	Fields   map[string]json.RawMessage
+	Matrices map[string]*mat.Dense
+	Children map[string]*State
+	// maxLen bounds the number of elements of the slices and maps read from a loaded state, 0 if unbounded
+	maxLen int
+}
+
+// NewState returns an empty State
+func NewState() *State {
+	return &State{Fields: map[string]json.RawMessage{}, Matrices: map[string]*mat.Dense{}, Children: map[string]*State{}}
+}
+
+// Set stores v JSON encoded under name
+func (st *State) Set(name string, v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	st.Fields[name] = b
+	return nil
+}
+
+// Get decodes field name into v. it returns false if name was not saved
+func (st *State) Get(name string, v interface{}) (bool, error) {
+	b, ok := st.Fields[name]
+	if !ok {
+		return false, nil
+	}
+	if err := json.Unmarshal(b, v); err != nil {
+		return true, fmt.Errorf("%s: %w", name, err)
+	}
+	return true, nil
+}
+
+// SetChild stores the state of sub-model p under name
+func (st *State) SetChild(name string, p Persister) error {
+	child, err := marshalPersister(p)
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	st.Children[name] = child
+	return nil
+}
+
+// GetChild returns the sub-model saved under name, or nil if name was not saved
+func (st *State) GetChild(name string) (Persister, error) {
+	child, ok := st.Children[name]
+	if !ok {
+		return nil, nil
+	}
+	p, err := unmarshalPersister(child)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return p, nil
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]reflect.Type{}
+)
+
+// Register makes the type of p known to Load. p must be a pointer. packages register their models in init
+func Register(p Persister) {
+	t := reflect.TypeOf(p)
+	if t.Kind() != reflect.Ptr {
+		panic(fmt.Errorf("%w: Register expects a pointer, got %s", ErrInvalidParam, t))
+	}
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[persistTypeName(t)] = t.Elem()
+}
+
+func persistTypeName(t reflect.Type) string {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.PkgPath() + "." + t.Name()
+}
+
+func marshalPersister(p Persister) (*State, error) {
+	st, err := p.MarshalState()
+	if err != nil {
+		return nil, err
+	}
+	st.Type = persistTypeName(reflect.TypeOf(p))
+	return st, nil
+}
+
+func unmarshalPersister(st *State) (Persister, error) {
+	registryMu.RLock()
+	t, ok := registry[st.Type]
+	registryMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: unregistered type %q", ErrNotPersistable, st.Type)
+	}
+	p := reflect.New(t).Interface().(Persister)
+	if err := p.UnmarshalState(st); err != nil {
+		return nil, fmt.Errorf("%s: %w", st.Type, err)
+	}
+	return p, nil
+}
+
+type matrixHeader struct {
+	Rows   int `json:"rows"`
+	Cols   int `json:"cols"`
+	Offset int `json:"offset"`
+}
+
+type stateHeader struct {
+	Type     string                     `json:"type"`
+	Fields   map[string]json.RawMessage `json:"fields,omitempty"`
+	Matrices map[string]matrixHeader    `json:"matrices,omitempty"`
+	Children map[string]*stateHeader    `json:"children,omitempty"`
+}
+
+type fileHeader struct {
+	Format  string       `json:"format"`
+	Version int          `json:"version"`
+	Root    *stateHeader `json:"root"`
+}
+
+// Save writes p to w. the format is a magic string, the length of a JSON header as a little endian uint64,
+// the JSON header and the matrices as little endian float64 blobs
+func Save(w io.Writer, p Persister) error {
+	st, err := marshalPersister(p)
+	if err != nil {
+		return err
+	}
+	var blobs []*mat.Dense
+	offset := 0
+	var headerOf func(st *State) *stateHeader
+	headerOf = func(st *State) *stateHeader {
+		h := &stateHeader{Type: st.Type, Fields: st.Fields, Matrices: map[string]matrixHeader{}, Children: map[string]*stateHeader{}}
+		for _, name := range sortedKeys(st.Matrices) {
+			m := st.Matrices[name]
+			r, c := 0, 0
+			if !m.IsZero() {
+				r, c = m.Dims()
+			}
+			h.Matrices[name] = matrixHeader{Rows: r, Cols: c, Offset: offset}
+			offset += r * c
+			blobs = append(blobs, m)
+		}
+		for name, child := range st.Children {
+			h.Children[name] = headerOf(child)
+		}
+		return h
+	}
+	header, err := json.Marshal(fileHeader{Format: PersistFormat, Version: PersistVersion, Root: headerOf(st)})
+	if err != nil {
+		return err
+	}
+	bw := bufio.NewWriter(w)
+	bw.Write(persistMagic)
+	binary.Write(bw, binary.LittleEndian, uint64(len(header)))
+	bw.Write(header)
+	buf := make([]byte, 8)
+	for _, m := range blobs {
+		if m.IsZero() {
+			continue
+		}
+		r, c := m.Dims()
+		for i := 0; i < r; i++ {
+			for j := 0; j < c; j++ {
+				binary.LittleEndian.PutUint64(buf, math.Float64bits(m.At(i, j)))
+				if _, err := bw.Write(buf); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return bw.Flush()
+}
+
+// sortedKeys gives a stable order to matrices
+func sortedKeys(m map[string]*mat.Dense) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// LoadPersister reads a model written by Save. its type must have been registered.
+// a malformed file gives an error, never a panic
+func LoadPersister(r io.Reader) (p Persister, err error) {
+	defer Recover(&err)
+	magic := make([]byte, len(persistMagic))
+	if _, err := io.ReadFull(r, magic); err != nil {
+		return nil, err
+	}
+	if string(magic) != string(persistMagic) {
+		return nil, fmt.Errorf("%w: bad magic", ErrNotPersistable)
+	}
+	var headerLen uint64
+	if err := binary.Read(r, binary.LittleEndian, &headerLen); err != nil {
+		return nil, err
+	}
+	if headerLen > maxHeaderLen {
+		return nil, fmt.Errorf("%w: header length %d", ErrNotPersistable, headerLen)
+	}
+	// ReadAll grows its buffer as data comes, so a truncated file doesn't allocate headerLen bytes
+	headerBytes, err := io.ReadAll(io.LimitReader(r, int64(headerLen)))
+	if err != nil {
+		return nil, err
+	}
+	if uint64(len(headerBytes)) != headerLen {
+		return nil, io.ErrUnexpectedEOF
+	}
+	var header fileHeader
+	if err := json.Unmarshal(headerBytes, &header); err != nil {
+		return nil, err
+	}
+	if header.Format != PersistFormat || header.Version < 1 || header.Version > PersistVersion || header.Root == nil {
+		return nil, fmt.Errorf("%w: unsupported format %q version %d", ErrNotPersistable, header.Format, header.Version)
+	}
+	n, err := blobSize(header.Root)
+	if err != nil {
+		return nil, err
+	}
+	data, err := readFloats(bufio.NewReader(r), n)
+	if err != nil {
+		return nil, err
+	}
+	var stateOf func(h *stateHeader) (*State, error)
+	stateOf = func(h *stateHeader) (*State, error) {
+		st := NewState()
+		st.Type = h.Type
+		// saved elements of slices and maps take header bytes, so only nil pointers could make a longer slice
+		st.maxLen = len(headerBytes)
+		if h.Fields != nil {
+			st.Fields = h.Fields
+		}
+		for name, m := range h.Matrices {
+			if m.Rows*m.Cols == 0 {
+				st.Matrices[name] = &mat.Dense{}
+				continue
+			}
+			st.Matrices[name] = mat.NewDense(m.Rows, m.Cols, data[m.Offset:m.Offset+m.Rows*m.Cols:m.Offset+m.Rows*m.Cols])
+		}
+		for name, child := range h.Children {
+			cst, err := stateOf(child)
+			if err != nil {
+				return nil, err
+			}
+			st.Children[name] = cst
+		}
+		return st, nil
+	}
+	st, err := stateOf(header.Root)
+	if err != nil {
+		return nil, err
+	}
+	return unmarshalPersister(st)
+}
+
+const (
+	// maxHeaderLen bounds the JSON header of files read by LoadPersister
+	maxHeaderLen = 1 << 30
+	maxInt       = int(^uint(0) >> 1)
+)
+
+// blobSize checks the matrices of h and its children and returns the number of float64 in the blob of the file
+func blobSize(h *stateHeader) (int, error) {
+	if h == nil {
+		return 0, fmt.Errorf("%w: nil state", ErrNotPersistable)
+	}
+	n := 0
+	for name, m := range h.Matrices {
+		if m.Rows < 0 || m.Cols < 0 || m.Offset < 0 || (m.Cols > 0 && m.Rows > (maxInt-m.Offset)/m.Cols) {
+			return 0, fmt.Errorf("%w: bad matrix %s %dx%d at %d", ErrNotPersistable, name, m.Rows, m.Cols, m.Offset)
+		}
+		if end := m.Offset + m.Rows*m.Cols; end > n {
+			n = end
+		}
+	}
+	for name, child := range h.Children {
+		end, err := blobSize(child)
+		if err != nil {
+			return 0, fmt.Errorf("%s: %w", name, err)
+		}
+		if end > n {
+			n = end
+		}
+	}
+	return n, nil
+}
+
+// readFloats reads n little endian float64. data grows by chunks so that a truncated file fails before allocating n float64
+func readFloats(r io.Reader, n int) ([]float64, error) {
+	const chunk = 1 << 16
+	data := []float64{}
+	buf := make([]byte, 8*chunk)
+	for len(data) < n {
+		k := n - len(data)
+		if k > chunk {
+			k = chunk
+		}
+		if _, err := io.ReadFull(r, buf[:8*k]); err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return nil, err
+		}
+		for i := 0; i < k; i++ {
+			data = append(data, math.Float64frombits(binary.LittleEndian.Uint64(buf[8*i:])))
+		}
+	}
+	return data, nil
+}
+
+// Load reads a Predicter written by Save
+func Load(r io.Reader) (Predicter, error) {
+	p, err := LoadPersister(r)
+	if err != nil {
+		return nil, err
+	}
+	m, ok := p.(Predicter)
+	if !ok {
+		return nil, fmt.Errorf("%w: %T is not a Predicter", ErrNotPersistable, p)
+	}
+	return m, nil
+}
+
+// LoadTransformer reads a Transformer written by Save
+func LoadTransformer(r io.Reader) (Transformer, error) {
+	p, err := LoadPersister(r)
+	if err != nil {
+		return nil, err
+	}
+	m, ok := p.(Transformer)
+	if !ok {
+		return nil, fmt.Errorf("%w: %T is not a Transformer", ErrNotPersistable, p)
+	}
+	return m, nil
+}
+
+var (
+	denseType         = reflect.TypeOf((*mat.Dense)(nil))
+	generalType       = reflect.TypeOf(blas64.General{})
+	float64SliceType  = reflect.TypeOf([]float64(nil))
+	persisterType     = reflect.TypeOf((*Persister)(nil)).Elem()
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+)
+
+// MarshalFields saves the exported fields of the struct pointed to by v.
+// *mat.Dense, blas64.General and []float64 are saved as matrices, sub-models implementing Persister as children.
+// interfaces are saved with the type of their value, which must be a Persister or a type listed in valueTypes.
+// only fields tagged `persist:"-"` and interfaces holding a Source (random states are not saved) are skipped;
+// any other func, channel or interface value returns ErrNotPersistable
+func MarshalFields(v interface{}) (*State, error) {
+	st := NewState()
+	if err := st.marshalStruct("", reflect.ValueOf(v).Elem()); err != nil {
+		return nil, err
+	}
+	return st, nil
+}
+
+// UnmarshalFields restores into the struct pointed to by v the fields saved by MarshalFields. missing fields are left unchanged
+func UnmarshalFields(v interface{}, st *State) error {
+	return st.unmarshalStruct("", reflect.ValueOf(v).Elem())
+}
+
+// valueTypes are the types of the values of interfaces which can be saved by MarshalFields, such as MaxFeatures or ClassWeight
+var valueTypes = map[string]reflect.Type{}
+
+func init() {
+	for _, v := range []interface{}{
+		false, "", int(0), int64(0), float64(0),
+		[]int{}, []float64{}, []string{}, []interface{}{},
+		map[string]int{}, map[string]float64{}, map[int]float64{}, map[float64]float64{}, map[string]interface{}{},
+		&mat.Dense{},
+	} {
+		t := reflect.TypeOf(v)
+		valueTypes[t.String()] = t
+	}
+}
+
+// interfaceValue is saved for an interface field, its value is saved under name.value
+type interfaceValue struct {
+	Type string `json:"type"`
+}
+
+// persistSkipped returns true for struct fields tagged `persist:"-"`
+func persistSkipped(f reflect.StructField) bool {
+	return f.Tag.Get("persist") == "-"
+}
+
+// jsonMap returns true for maps which are saved as JSON objects. other maps are saved as slices of keys and values
+func jsonMap(t reflect.Type) bool {
+	switch t.Key().Kind() {
+	case reflect.String, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return !walked(t.Elem())
+	}
+	return false
+}
+
+// walked returns true for types which are not saved as plain JSON
+func walked(t reflect.Type) bool {
+	return walkedSeen(t, map[reflect.Type]bool{})
+}
+
+func walkedSeen(t reflect.Type, seen map[reflect.Type]bool) bool {
+	if seen[t] {
+		return false
+	}
+	seen[t] = true
+	switch t {
+	case denseType, generalType, float64SliceType:
+		return true
+	}
+	if t.Implements(jsonMarshalerType) {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Interface, reflect.Func, reflect.Chan, reflect.UnsafePointer, reflect.Ptr, reflect.Float32, reflect.Float64:
+		return true
+	case reflect.Slice, reflect.Array:
+		return walkedSeen(t.Elem(), seen)
+	case reflect.Map:
+		return true
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if f := t.Field(i); f.PkgPath == "" && walkedSeen(f.Type, seen) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (st *State) marshalStruct(prefix string, v reflect.Value) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		if f := t.Field(i); f.PkgPath == "" && !persistSkipped(f) {
+			if err := st.marshalValue(prefix+f.Name, v.Field(i)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (st *State) unmarshalStruct(prefix string, v reflect.Value) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		if f := t.Field(i); f.PkgPath == "" && !persistSkipped(f) {
+			if err := st.unmarshalValue(prefix+f.Name, v.Field(i)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (st *State) marshalValue(name string, v reflect.Value) error {
+	switch v.Type() {
+	case denseType:
+		if !v.IsNil() {
+			st.Matrices[name] = v.Interface().(*mat.Dense)
+		}
+		return nil
+	case generalType:
+		g := v.Interface().(blas64.General)
+		if g.Data != nil {
+			m := &mat.Dense{}
+			if g.Rows > 0 && g.Cols > 0 {
+				m.SetRawMatrix(g)
+			}
+			st.Matrices[name] = m
+		}
+		return nil
+	case float64SliceType:
+		if !v.IsNil() {
+			m := &mat.Dense{}
+			if v.Len() > 0 {
+				m = mat.NewDense(1, v.Len(), v.Interface().([]float64))
+			}
+			st.Matrices[name] = m
+		}
+		return nil
+	}
+	if !walked(v.Type()) {
+		return st.Set(name, v.Interface())
+	}
+	switch v.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return st.Set(name, formatFloat(v.Float()))
+	case reflect.Interface, reflect.Ptr:
+		if v.IsNil() {
+			return nil
+		}
+		if p, ok := v.Interface().(Persister); ok {
+			return st.SetChild(name, p)
+		}
+		e := v.Elem()
+		if v.Kind() == reflect.Ptr && e.Kind() == reflect.Struct {
+			if !hasExportedFields(e.Type()) {
+				return nil
+			}
+			st.Fields[name] = json.RawMessage("{}")
+			return st.marshalStruct(name+".", e)
+		}
+		if v.Kind() == reflect.Interface {
+			if _, ok := e.Interface().(Source); ok {
+				return nil
+			}
+			if valueTypes[e.Type().String()] != e.Type() {
+				return fmt.Errorf("%w: %s holds a %s", ErrNotPersistable, name, e.Type())
+			}
+			if err := st.Set(name, interfaceValue{Type: e.Type().String()}); err != nil {
+				return err
+			}
+			return st.marshalValue(name+".value", e)
+		}
+		return st.marshalValue(name, e)
+	case reflect.Struct:
+		return st.marshalStruct(name+".", v)
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return nil
+		}
+		if err := st.Set(name, v.Len()); err != nil {
+			return err
+		}
+		for i := 0; i < v.Len(); i++ {
+			if err := st.marshalValue(name+"."+strconv.Itoa(i), v.Index(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		if v.IsNil() {
+			return nil
+		}
+		if jsonMap(v.Type()) {
+			return st.Set(name, v.Interface())
+		}
+		keys := v.MapKeys()
+		sortValues(keys)
+		k := reflect.MakeSlice(reflect.SliceOf(v.Type().Key()), len(keys), len(keys))
+		e := reflect.MakeSlice(reflect.SliceOf(v.Type().Elem()), len(keys), len(keys))
+		for i, key := range keys {
+			k.Index(i).Set(key)
+			e.Index(i).Set(v.MapIndex(key))
+		}
+		if err := st.Set(name, len(keys)); err != nil {
+			return err
+		}
+		if err := st.marshalValue(name+".keys", k); err != nil {
+			return err
+		}
+		return st.marshalValue(name+".values", e)
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		if v.IsNil() {
+			return nil
+		}
+		return fmt.Errorf("%w: %s is a %s", ErrNotPersistable, name, v.Type())
+	}
+	return nil
+}
+
+// sortValues gives a stable order to the keys of maps
+func sortValues(keys []reflect.Value) {
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		switch a.Kind() {
+		case reflect.Float32, reflect.Float64:
+			return a.Float() < b.Float()
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return a.Int() < b.Int()
+		case reflect.String:
+			return a.String() < b.String()
+		}
+		return fmt.Sprint(a.Interface()) < fmt.Sprint(b.Interface())
+	})
+}
+
+func hasExportedFields(t reflect.Type) bool {
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).PkgPath == "" {
+			return true
+		}
+	}
+	return false
+}
+
+func (st *State) unmarshalValue(name string, v reflect.Value) error {
+	switch v.Type() {
+	case denseType:
+		if m, ok := st.Matrices[name]; ok {
+			v.Set(reflect.ValueOf(m))
+		}
+		return nil
+	case generalType:
+		if m, ok := st.Matrices[name]; ok {
+			g := blas64.General{Data: []float64{}}
+			if !m.IsZero() {
+				g = mat.DenseCopyOf(m).RawMatrix()
+			}
+			v.Set(reflect.ValueOf(g))
+		}
+		return nil
+	case float64SliceType:
+		if m, ok := st.Matrices[name]; ok {
+			s := []float64{}
+			if !m.IsZero() {
+				s = mat.DenseCopyOf(m).RawMatrix().Data
+			}
+			v.Set(reflect.ValueOf(s))
+		}
+		return nil
+	}
+	if !walked(v.Type()) {
+		_, err := st.Get(name, v.Addr().Interface())
+		return err
+	}
+	switch v.Kind() {
+	case reflect.Float32, reflect.Float64:
+		var s string
+		if ok, err := st.Get(name, &s); !ok || err != nil {
+			return err
+		}
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		v.SetFloat(f)
+	case reflect.Interface, reflect.Ptr:
+		if _, ok := st.Children[name]; ok {
+			p, err := st.GetChild(name)
+			if err != nil {
+				return err
+			}
+			pv := reflect.ValueOf(p)
+			if !pv.Type().AssignableTo(v.Type()) {
+				return fmt.Errorf("%w: %s: %s is not assignable to %s", ErrNotPersistable, name, pv.Type(), v.Type())
+			}
+			v.Set(pv)
+			return nil
+		}
+		if _, ok := st.Fields[name]; !ok {
+			return nil
+		}
+		if v.Kind() == reflect.Interface {
+			var iv interfaceValue
+			if _, err := st.Get(name, &iv); err != nil {
+				return err
+			}
+			t, ok := valueTypes[iv.Type]
+			if !ok {
+				return fmt.Errorf("%w: %s holds an unknown type %q", ErrNotPersistable, name, iv.Type)
+			}
+			if !t.AssignableTo(v.Type()) {
+				return fmt.Errorf("%w: %s: %s is not assignable to %s", ErrNotPersistable, name, t, v.Type())
+			}
+			x := reflect.New(t).Elem()
+			if err := st.unmarshalValue(name+".value", x); err != nil {
+				return err
+			}
+			v.Set(x)
+			return nil
+		}
+		e := reflect.New(v.Type().Elem())
+		var err error
+		if e.Elem().Kind() == reflect.Struct {
+			err = st.unmarshalStruct(name+".", e.Elem())
+		} else {
+			err = st.unmarshalValue(name, e.Elem())
+		}
+		if err != nil {
+			return err
+		}
+		v.Set(e)
+	case reflect.Struct:
+		return st.unmarshalStruct(name+".", v)
+	case reflect.Map:
+		if jsonMap(v.Type()) {
+			_, err := st.Get(name, v.Addr().Interface())
+			return err
+		}
+		var n int
+		if ok, err := st.Get(name, &n); !ok || err != nil {
+			return err
+		}
+		if err := st.checkLen(name, n); err != nil {
+			return err
+		}
+		k := reflect.New(reflect.SliceOf(v.Type().Key())).Elem()
+		e := reflect.New(reflect.SliceOf(v.Type().Elem())).Elem()
+		if err := st.unmarshalValue(name+".keys", k); err != nil {
+			return err
+		}
+		if err := st.unmarshalValue(name+".values", e); err != nil {
+			return err
+		}
+		if k.Len() != n || e.Len() != n {
+			return fmt.Errorf("%w: %s has %d keys and %d values, expected %d", ErrShapeMismatch, name, k.Len(), e.Len(), n)
+		}
+		m := reflect.MakeMapWithSize(v.Type(), n)
+		for i := 0; i < n; i++ {
+			m.SetMapIndex(k.Index(i), e.Index(i))
+		}
+		v.Set(m)
+	case reflect.Slice, reflect.Array:
+		var n int
+		if ok, err := st.Get(name, &n); !ok || err != nil {
+			return err
+		}
+		if err := st.checkLen(name, n); err != nil {
+			return err
+		}
+		if v.Kind() == reflect.Slice {
+			v.Set(reflect.MakeSlice(v.Type(), n, n))
+		} else if n > v.Len() {
+			return fmt.Errorf("%w: %s has %d elements, expected %d", ErrShapeMismatch, name, n, v.Len())
+		}
+		for i := 0; i < n; i++ {
+			if err := st.unmarshalValue(name+"."+strconv.Itoa(i), v.Index(i)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// checkLen returns an error if n can't be the number of elements of slice or map name
+func (st *State) checkLen(name string, n int) error {
+	if n < 0 || (st.maxLen > 0 && n > st.maxLen) {
+		return fmt.Errorf("%w: %s has %d elements", ErrNotPersistable, name, n)
+	}
+	return nil
+}
+
+// formatFloat is used instead of JSON numbers which can't hold NaN and Inf
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
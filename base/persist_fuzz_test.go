@@ -0,0 +1,29 @@
+//go:build go1.18
+// +build go1.18
+
+package base
+
+import (
+	"bytes"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+func FuzzLoadPersister(f *testing.F) {
+	Register(&persistTest{})
+	Register(&persistTestChild{})
+	buf := new(bytes.Buffer)
+	if err := Save(buf, &persistTest{
+		M: mat.NewDense(2, 2, []float64{1, 2, 3, 4}), F: []float64{5, 6}, Nested: []persistTestNested{{K: 1, Coefs: []float64{7}}},
+		Classes: []interface{}{"a", 1.5}, Child: &persistTestChild{Name: "child"}, FloatMap: map[float64]float64{1: 2},
+	}); err != nil {
+		f.Fatal(err)
+	}
+	f.Add(buf.Bytes())
+	f.Add([]byte("not a model file"))
+	f.Fuzz(func(t *testing.T, b []byte) {
+		// malformed files must give an error, not panic
+		LoadPersister(bytes.NewReader(b))
+	})
+}
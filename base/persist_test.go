@@ -0,0 +1,217 @@
+package base
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+
+	"gonum.org/v1/gonum/blas/blas64"
+	"gonum.org/v1/gonum/mat"
+)
+
+type persistTestChild struct {
+	Name string
+}
+
+func (m *persistTestChild) MarshalState() (*State, error)  { return MarshalFields(m) }
+func (m *persistTestChild) UnmarshalState(st *State) error { return UnmarshalFields(m, st) }
+
+type persistTestNested struct {
+	K     int
+	Coefs []float64
+}
+
+type persistTest struct {
+	A        float64
+	Inf      float64
+	S        string
+	Ints     []int
+	M        *mat.Dense
+	G        blas64.General
+	F        []float64
+	FF       [][]float64
+	P        *persistTestNested
+	Nested   []persistTestNested
+	Iface    interface{}
+	Frac     interface{}
+	Count    interface{}
+	Weights  interface{}
+	Classes  []interface{}
+	Child    *persistTestChild
+	Fn       func() `persist:"-"`
+	Source   Source
+	Map      map[string]int
+	FloatMap map[float64]float64
+	unexport int
+}
+
+func (m *persistTest) MarshalState() (*State, error)  { return MarshalFields(m) }
+func (m *persistTest) UnmarshalState(st *State) error { return UnmarshalFields(m, st) }
+
+func TestSaveLoad(t *testing.T) {
+	Register(&persistTest{})
+	Register(&persistTestChild{})
+	m := &persistTest{
+		A: math.Pi, Inf: math.Inf(-1), S: "s", Ints: []int{1, 2},
+		M:      mat.NewDense(2, 3, []float64{1, 2, 3, 4, 5, math.NaN()}),
+		G:      blas64.General{Rows: 2, Cols: 2, Stride: 3, Data: []float64{1, 2, 0, 3, 4, 0}},
+		F:      []float64{5, 6},
+		FF:     [][]float64{{1}, {2, 3}},
+		P:      &persistTestNested{K: 3, Coefs: []float64{7}},
+		Nested: []persistTestNested{{K: 1}, {K: 2, Coefs: []float64{8, 9}}},
+		Iface:  "rbf", Frac: .5, Count: 3, Weights: map[float64]float64{0: 2, 1: .5}, Classes: []interface{}{"a", 1.5},
+		Child: &persistTestChild{Name: "child"},
+		Fn:    func() {}, Source: NewSource(7), Map: map[string]int{"a": 1}, FloatMap: map[float64]float64{-1: math.NaN()},
+		unexport: 1,
+	}
+	buf := new(bytes.Buffer)
+	if err := Save(buf, m); err != nil {
+		t.Fatal(err)
+	}
+	p, err := LoadPersister(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m2 := p.(*persistTest)
+	if m2.A != m.A || !math.IsInf(m2.Inf, -1) || m2.S != "s" || len(m2.Ints) != 2 || m2.Ints[1] != 2 {
+		t.Errorf("scalars not restored: %#v", m2)
+	}
+	if r, c := m2.M.Dims(); r != 2 || c != 3 || m2.M.At(1, 1) != 5 || !math.IsNaN(m2.M.At(1, 2)) {
+		t.Errorf("M not restored: %v", mat.Formatted(m2.M))
+	}
+	if m2.G.Rows != 2 || m2.G.Cols != 2 || m2.G.Data[m2.G.Stride+1] != 4 {
+		t.Errorf("G not restored: %#v", m2.G)
+	}
+	if len(m2.F) != 2 || m2.F[1] != 6 || len(m2.FF) != 2 || m2.FF[1][1] != 3 {
+		t.Errorf("slices not restored: %v %v", m2.F, m2.FF)
+	}
+	if m2.P == nil || m2.P.K != 3 || m2.P.Coefs[0] != 7 || len(m2.Nested) != 2 || m2.Nested[1].Coefs[1] != 9 {
+		t.Errorf("nested structs not restored: %#v %#v", m2.P, m2.Nested)
+	}
+	if m2.Iface != "rbf" || m2.Child == nil || m2.Child.Name != "child" || m2.Map["a"] != 1 {
+		t.Errorf("interface, child or map not restored: %#v", m2)
+	}
+	if m2.Frac != .5 || m2.Count != 3 || len(m2.Classes) != 2 || m2.Classes[0] != "a" || m2.Classes[1] != 1.5 {
+		t.Errorf("interface values not restored: %#v %#v %#v", m2.Frac, m2.Count, m2.Classes)
+	}
+	if w, ok := m2.Weights.(map[float64]float64); !ok || len(w) != 2 || w[0] != 2 || w[1] != .5 {
+		t.Errorf("Weights not restored: %#v", m2.Weights)
+	}
+	if len(m2.FloatMap) != 1 || !math.IsNaN(m2.FloatMap[-1]) {
+		t.Errorf("FloatMap not restored: %#v", m2.FloatMap)
+	}
+	if m2.Fn != nil || m2.Source != nil || m2.unexport != 0 {
+		t.Errorf("func, source and unexported fields should not be restored")
+	}
+}
+
+type persistTestFunc struct {
+	Fn    func()
+	Iface interface{}
+}
+
+func (m *persistTestFunc) MarshalState() (*State, error)  { return MarshalFields(m) }
+func (m *persistTestFunc) UnmarshalState(st *State) error { return UnmarshalFields(m, st) }
+
+func TestSaveErrors(t *testing.T) {
+	for _, m := range []*persistTestFunc{
+		{Fn: func() {}},
+		{Iface: func() {}},
+		{Iface: struct{ A int }{1}},
+		{Iface: []interface{}{func() {}}},
+	} {
+		if err := Save(new(bytes.Buffer), m); !errors.Is(err, ErrNotPersistable) {
+			t.Errorf("expected ErrNotPersistable for %#v, got %v", m, err)
+		}
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	if _, err := LoadPersister(bytes.NewBufferString("not a model file")); !errors.Is(err, ErrNotPersistable) {
+		t.Errorf("expected ErrNotPersistable for bad magic, got %v", err)
+	}
+	Register(&persistTestChild{})
+	buf := new(bytes.Buffer)
+	if err := Save(buf, &persistTestChild{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(buf); !errors.Is(err, ErrNotPersistable) {
+		t.Errorf("expected ErrNotPersistable for a non Predicter, got %v", err)
+	}
+}
+
+func TestLoadCorrupt(t *testing.T) {
+	Register(&persistTest{})
+	buf := new(bytes.Buffer)
+	if err := Save(buf, &persistTest{M: mat.NewDense(2, 2, []float64{1, 2, 3, 4})}); err != nil {
+		t.Fatal(err)
+	}
+	saved := buf.Bytes()
+	headerLen := int(binary.LittleEndian.Uint64(saved[len(persistMagic):]))
+	headerStart := len(persistMagic) + 8
+	if !strings.Contains(string(saved[headerStart:headerStart+headerLen]), `{"rows":2,"cols":2,"offset":0}`) {
+		t.Fatalf("unexpected header %s", saved[headerStart:headerStart+headerLen])
+	}
+	withHeaderLen := func(n uint64) []byte {
+		b := append([]byte{}, saved...)
+		binary.LittleEndian.PutUint64(b[len(persistMagic):], n)
+		return b
+	}
+	withMatrix := func(m string) []byte {
+		header := strings.Replace(string(saved[headerStart:headerStart+headerLen]), `{"rows":2,"cols":2,"offset":0}`, m, 1)
+		b := append([]byte{}, saved[:headerStart]...)
+		binary.LittleEndian.PutUint64(b[len(persistMagic):], uint64(len(header)))
+		return append(append(b, header...), saved[headerStart+headerLen:]...)
+	}
+	for name, b := range map[string][]byte{
+		"huge header":     withHeaderLen(1 << 62),
+		"long header":     withHeaderLen(uint64(len(saved))),
+		"truncated blob":  saved[:len(saved)-1],
+		"negative rows":   withMatrix(`{"rows":-1,"cols":2,"offset":0}`),
+		"negative offset": withMatrix(`{"rows":2,"cols":2,"offset":-4}`),
+		"overflow":        withMatrix(`{"rows":4611686018427387904,"cols":4,"offset":0}`),
+		"huge matrix":     withMatrix(`{"rows":1073741824,"cols":1073741824,"offset":0}`),
+	} {
+		if _, err := LoadPersister(bytes.NewReader(b)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+	if _, err := LoadPersister(bytes.NewReader(saved)); err != nil {
+		t.Errorf("unmodified file: %v", err)
+	}
+}
+
+func TestLoadCorruptLengths(t *testing.T) {
+	Register(&persistTest{})
+	buf := new(bytes.Buffer)
+	if err := Save(buf, &persistTest{Nested: []persistTestNested{{K: 1}, {K: 2}}, FloatMap: map[float64]float64{1: 2}}); err != nil {
+		t.Fatal(err)
+	}
+	saved := buf.Bytes()
+	headerLen := int(binary.LittleEndian.Uint64(saved[len(persistMagic):]))
+	headerStart := len(persistMagic) + 8
+	withField := func(old, new string) []byte {
+		header := string(saved[headerStart : headerStart+headerLen])
+		if !strings.Contains(header, old) {
+			t.Fatalf("%s not found in %s", old, header)
+		}
+		header = strings.Replace(header, old, new, 1)
+		b := append([]byte{}, saved[:headerStart]...)
+		binary.LittleEndian.PutUint64(b[len(persistMagic):], uint64(len(header)))
+		return append(append(b, header...), saved[headerStart+headerLen:]...)
+	}
+	for name, b := range map[string][]byte{
+		"negative slice length": withField(`"Nested":2`, `"Nested":-5`),
+		"huge slice length":     withField(`"Nested":2`, `"Nested":4000000000000`),
+		"negative map length":   withField(`"FloatMap":1`, `"FloatMap":-5`),
+		"huge map length":       withField(`"FloatMap":1`, `"FloatMap":4000000000000`),
+		"wrong field type":      withField(`"Nested":2`, `"Nested":"2"`),
+	} {
+		if _, err := LoadPersister(bytes.NewReader(b)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
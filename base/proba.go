@@ -0,0 +1,43 @@
+package base
+
+import (
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// SoftmaxRows replaces each row of Y by its softmax
+func SoftmaxRows(Y *mat.Dense) {
+	y := Y.RawMatrix()
+	for i, off := 0, 0; i < y.Rows; i, off = i+1, off+y.Stride {
+		row := y.Data[off : off+y.Cols]
+		max := math.Inf(-1)
+		for _, v := range row {
+			if v > max {
+				max = v
+			}
+		}
+		sum := 0.
+		for j, v := range row {
+			row[j] = math.Exp(v - max)
+			sum += row[j]
+		}
+		for j := range row {
+			row[j] /= sum
+		}
+	}
+}
+
+// BinaryProba returns 2 columns 1-p,p for each column p of positive class probabilities of P
+func BinaryProba(P mat.Matrix) *mat.Dense {
+	r, c := P.Dims()
+	Y := mat.NewDense(r, 2*c, nil)
+	for i := 0; i < r; i++ {
+		for j := 0; j < c; j++ {
+			p := P.At(i, j)
+			Y.Set(i, 2*j, 1-p)
+			Y.Set(i, 2*j+1, p)
+		}
+	}
+	return Y
+}
@@ -0,0 +1,384 @@
+package base
+
+import (
+	"fmt"
+	"sort"
+
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mat"
+)
+
+// CSR is a compressed sparse row matrix, as scipy.sparse.csr_matrix.
+// the column indices of the non zero values of row i are Indices[Indptr[i]:Indptr[i+1]], in increasing order,
+// and their values are Data[Indptr[i]:Indptr[i+1]]
+type CSR struct {
+	Rows, Cols int
+	Indptr     []int
+	Indices    []int
+	Data       []float64
+}
+
+// CSC is a compressed sparse column matrix, as scipy.sparse.csc_matrix.
+// the row indices of the non zero values of column j are Indices[Indptr[j]:Indptr[j+1]], in increasing order,
+// and their values are Data[Indptr[j]:Indptr[j+1]]
+type CSC struct {
+	Rows, Cols int
+	Indptr     []int
+	Indices    []int
+	Data       []float64
+}
+
+// SparseVector is a row of a CSR or a column of a CSC. it has N elements, those at increasing Indices being Data
+type SparseVector struct {
+	N       int
+	Indices []int
+	Data    []float64
+}
+
+var (
+	_ mat.RowNonZeroDoer = &CSR{}
+	_ mat.ColNonZeroDoer = &CSC{}
+)
+
+// NewCSR returns a *CSR with r rows and c columns from its compressed representation. the slices are not copied
+func NewCSR(r, c int, indptr, indices []int, data []float64) *CSR {
+	checkCompressed(r, c, indptr, indices, data)
+	return &CSR{Rows: r, Cols: c, Indptr: indptr, Indices: indices, Data: data}
+}
+
+// NewCSC returns a *CSC with r rows and c columns from its compressed representation. the slices are not copied
+func NewCSC(r, c int, indptr, indices []int, data []float64) *CSC {
+	checkCompressed(c, r, indptr, indices, data)
+	return &CSC{Rows: r, Cols: c, Indptr: indptr, Indices: indices, Data: data}
+}
+
+// checkCompressed panics unless indptr starts at 0 and is non-decreasing up to at most len(data), and the indices of each
+// of the n vectors are increasing and in [0,m)
+func checkCompressed(n, m int, indptr, indices []int, data []float64) {
+	if len(indptr) != n+1 || len(indices) != len(data) || indptr[0] != 0 || indptr[n] > len(data) {
+		panic(fmt.Errorf("%w: indptr %d, indices %d, data %d for %d vectors", ErrShapeMismatch, len(indptr), len(indices), len(data), n))
+	}
+	for k := 0; k < n; k++ {
+		if indptr[k+1] < indptr[k] {
+			panic(fmt.Errorf("%w: indptr decreases from %d to %d at %d", ErrShapeMismatch, indptr[k], indptr[k+1], k))
+		}
+		for p := indptr[k]; p < indptr[k+1]; p++ {
+			if i := indices[p]; i < 0 || i >= m {
+				panic(fmt.Errorf("%w: index %d out of range [0,%d)", ErrShapeMismatch, i, m))
+			}
+			if p > indptr[k] && indices[p] <= indices[p-1] {
+				panic(fmt.Errorf("%w: indices of vector %d are not increasing", ErrShapeMismatch, k))
+			}
+		}
+	}
+}
+
+// IsSparse returns true if m is a *CSR or a *CSC
+func IsSparse(m mat.Matrix) bool {
+	switch m.(type) {
+	case *CSR, *CSC:
+		return true
+	}
+	return false
+}
+
+// ToCSR returns m if it is a *CSR, else a *CSR copy of its non zero values
+func ToCSR(m mat.Matrix) *CSR {
+	switch v := m.(type) {
+	case *CSR:
+		return v
+	case *CSC:
+		return v.ToCSR()
+	}
+	r, c := m.Dims()
+	csr := &CSR{Rows: r, Cols: c, Indptr: make([]int, r+1)}
+	row := make([]float64, c)
+	for i := 0; i < r; i++ {
+		mat.Row(row, i, m)
+		for j, v := range row {
+			if v != 0 {
+				csr.Indices = append(csr.Indices, j)
+				csr.Data = append(csr.Data, v)
+			}
+		}
+		csr.Indptr[i+1] = len(csr.Data)
+	}
+	return csr
+}
+
+// ToCSC returns m if it is a *CSC, else a *CSC copy of its non zero values
+func ToCSC(m mat.Matrix) *CSC {
+	if v, ok := m.(*CSC); ok {
+		return v
+	}
+	return ToCSR(m).ToCSC()
+}
+
+// Dims for CSR
+func (m *CSR) Dims() (int, int) { return m.Rows, m.Cols }
+
+// At for CSR
+func (m *CSR) At(i, j int) float64 {
+	if j < 0 || j >= m.Cols {
+		panic(mat.ErrColAccess)
+	}
+	return m.Row(i).AtVec(j)
+}
+
+// T returns the transpose of m, a *CSC sharing its storage
+func (m *CSR) T() mat.Matrix {
+	return &CSC{Rows: m.Cols, Cols: m.Rows, Indptr: m.Indptr, Indices: m.Indices, Data: m.Data}
+}
+
+// NNZ returns the number of stored values
+func (m *CSR) NNZ() int { return m.Indptr[m.Rows] - m.Indptr[0] }
+
+// Row returns row i of m, sharing its storage
+func (m *CSR) Row(i int) SparseVector {
+	if i < 0 || i >= m.Rows {
+		panic(mat.ErrRowAccess)
+	}
+	start, end := m.Indptr[i], m.Indptr[i+1]
+	return SparseVector{N: m.Cols, Indices: m.Indices[start:end], Data: m.Data[start:end]}
+}
+
+// DoNonZero calls fn for each stored value of m
+func (m *CSR) DoNonZero(fn func(i, j int, v float64)) {
+	for i := 0; i < m.Rows; i++ {
+		m.DoRowNonZero(i, fn)
+	}
+}
+
+// DoRowNonZero calls fn for each stored value of row i of m
+func (m *CSR) DoRowNonZero(i int, fn func(i, j int, v float64)) {
+	for p := m.Indptr[i]; p < m.Indptr[i+1]; p++ {
+		fn(i, m.Indices[p], m.Data[p])
+	}
+}
+
+// RowSlice returns rows i to k-1 of m, sharing its storage
+func (m *CSR) RowSlice(i, k int) *CSR {
+	if i < 0 || k > m.Rows || k < i {
+		panic(mat.ErrRowAccess)
+	}
+	return &CSR{Rows: k - i, Cols: m.Cols, Indptr: m.Indptr[i : k+1], Indices: m.Indices, Data: m.Data}
+}
+
+// SelectRows returns a *CSR copy of the rows of m whose indices are in rows
+func (m *CSR) SelectRows(rows []int) *CSR {
+	nnz := 0
+	for _, i := range rows {
+		nnz += m.Indptr[i+1] - m.Indptr[i]
+	}
+	csr := &CSR{Rows: len(rows), Cols: m.Cols, Indptr: make([]int, len(rows)+1), Indices: make([]int, 0, nnz), Data: make([]float64, 0, nnz)}
+	for r, i := range rows {
+		row := m.Row(i)
+		csr.Indices = append(csr.Indices, row.Indices...)
+		csr.Data = append(csr.Data, row.Data...)
+		csr.Indptr[r+1] = len(csr.Data)
+	}
+	return csr
+}
+
+// ToCSC returns a *CSC copy of m
+func (m *CSR) ToCSC() *CSC {
+	indptr, indices, data := transposeCompressed(m.Rows, m.Cols, m.Indptr, m.Indices, m.Data)
+	return &CSC{Rows: m.Rows, Cols: m.Cols, Indptr: indptr, Indices: indices, Data: data}
+}
+
+// ToDense returns a *mat.Dense copy of m
+func (m *CSR) ToDense() *mat.Dense {
+	d := mat.NewDense(m.Rows, m.Cols, nil)
+	m.DoNonZero(func(i, j int, v float64) { d.Set(i, j, v) })
+	return d
+}
+
+// Dims for CSC
+func (m *CSC) Dims() (int, int) { return m.Rows, m.Cols }
+
+// At for CSC
+func (m *CSC) At(i, j int) float64 {
+	if i < 0 || i >= m.Rows {
+		panic(mat.ErrRowAccess)
+	}
+	return m.Col(j).AtVec(i)
+}
+
+// T returns the transpose of m, a *CSR sharing its storage
+func (m *CSC) T() mat.Matrix {
+	return &CSR{Rows: m.Cols, Cols: m.Rows, Indptr: m.Indptr, Indices: m.Indices, Data: m.Data}
+}
+
+// NNZ returns the number of stored values
+func (m *CSC) NNZ() int { return m.Indptr[m.Cols] - m.Indptr[0] }
+
+// Col returns column j of m, sharing its storage
+func (m *CSC) Col(j int) SparseVector {
+	if j < 0 || j >= m.Cols {
+		panic(mat.ErrColAccess)
+	}
+	start, end := m.Indptr[j], m.Indptr[j+1]
+	return SparseVector{N: m.Rows, Indices: m.Indices[start:end], Data: m.Data[start:end]}
+}
+
+// DoNonZero calls fn for each stored value of m
+func (m *CSC) DoNonZero(fn func(i, j int, v float64)) {
+	for j := 0; j < m.Cols; j++ {
+		m.DoColNonZero(j, fn)
+	}
+}
+
+// DoColNonZero calls fn for each stored value of column j of m
+func (m *CSC) DoColNonZero(j int, fn func(i, j int, v float64)) {
+	for p := m.Indptr[j]; p < m.Indptr[j+1]; p++ {
+		fn(m.Indices[p], j, m.Data[p])
+	}
+}
+
+// ToCSR returns a *CSR copy of m
+func (m *CSC) ToCSR() *CSR {
+	indptr, indices, data := transposeCompressed(m.Cols, m.Rows, m.Indptr, m.Indices, m.Data)
+	return &CSR{Rows: m.Rows, Cols: m.Cols, Indptr: indptr, Indices: indices, Data: data}
+}
+
+// ToDense returns a *mat.Dense copy of m
+func (m *CSC) ToDense() *mat.Dense {
+	d := mat.NewDense(m.Rows, m.Cols, nil)
+	m.DoNonZero(func(i, j int, v float64) { d.Set(i, j, v) })
+	return d
+}
+
+// transposeCompressed converts n compressed vectors of length l to l compressed vectors of length n
+func transposeCompressed(n, l int, indptr, indices []int, data []float64) (tindptr, tindices []int, tdata []float64) {
+	nnz := indptr[n] - indptr[0]
+	tindptr = make([]int, l+1)
+	for _, j := range indices[indptr[0]:indptr[n]] {
+		tindptr[j+1]++
+	}
+	for j := 0; j < l; j++ {
+		tindptr[j+1] += tindptr[j]
+	}
+	tindices, tdata = make([]int, nnz), make([]float64, nnz)
+	next := append([]int{}, tindptr[:l]...)
+	for i := 0; i < n; i++ {
+		for p := indptr[i]; p < indptr[i+1]; p++ {
+			j := indices[p]
+			tindices[next[j]] = i
+			tdata[next[j]] = data[p]
+			next[j]++
+		}
+	}
+	return
+}
+
+// Len returns the number of elements of v
+func (v SparseVector) Len() int { return v.N }
+
+// AtVec returns element i of v
+func (v SparseVector) AtVec(i int) float64 {
+	if i < 0 || i >= v.N {
+		panic(mat.ErrVectorAccess)
+	}
+	if p := sort.SearchInts(v.Indices, i); p < len(v.Indices) && v.Indices[p] == i {
+		return v.Data[p]
+	}
+	return 0
+}
+
+// Dot returns the dot product of v and w
+func (v SparseVector) Dot(w SparseVector) (s float64) {
+	for p, q := 0, 0; p < len(v.Indices) && q < len(w.Indices); {
+		switch {
+		case v.Indices[p] < w.Indices[q]:
+			p++
+		case v.Indices[p] > w.Indices[q]:
+			q++
+		default:
+			s += v.Data[p] * w.Data[q]
+			p++
+			q++
+		}
+	}
+	return
+}
+
+// DotDense returns the dot product of v and the dense vector w
+func (v SparseVector) DotDense(w []float64) (s float64) {
+	for p, i := range v.Indices {
+		s += v.Data[p] * w[i]
+	}
+	return
+}
+
+// AddScaledTo adds alpha*v to the dense vector dst
+func (v SparseVector) AddScaledTo(dst []float64, alpha float64) {
+	for p, i := range v.Indices {
+		dst[i] += alpha * v.Data[p]
+	}
+}
+
+// Dense fills dst, which must have v.N zeroed elements, with v. a new slice is allocated if dst is nil
+func (v SparseVector) Dense(dst []float64) []float64 {
+	if dst == nil {
+		dst = make([]float64, v.N)
+	}
+	for p, i := range v.Indices {
+		dst[i] = v.Data[p]
+	}
+	return dst
+}
+
+// MatMul sets dst to a·b and returns it. a *CSR or *CSC a or b, and a General32 a or a.T(), are not densified. dst is allocated if empty
+func MatMul(dst *mat.Dense, a, b mat.Matrix) *mat.Dense {
+	aSparse, bSparse := IsSparse(a), IsSparse(b)
+	a32, aTransposed, aFloat32 := asGeneral32(a)
+	if !aSparse && !bSparse && !aFloat32 {
+		dst.Mul(a, b)
+		return dst
+	}
+	ar, ac := a.Dims()
+	br, bc := b.Dims()
+	if ac != br {
+		panic(fmt.Errorf("%w: . %s", ErrShapeMismatch, MatDimsString(a, b)))
+	}
+	if dst.IsZero() {
+		*dst = *mat.NewDense(ar, bc, nil)
+	} else {
+		MatDimsCheck(".", dst, a, b)
+		dst.Zero()
+	}
+	d := dst.RawMatrix()
+	dstRow := func(i int) []float64 { return d.Data[i*d.Stride : i*d.Stride+d.Cols] }
+	switch {
+	case aFloat32 && !bSparse:
+		B := ToDense(b)
+		for r := 0; r < a32.Rows; r++ {
+			for k, v := range a32.RawRowView(r) {
+				if v == 0 {
+					continue
+				}
+				if aTransposed {
+					floats.AddScaled(dstRow(k), float64(v), B.RawRowView(r))
+				} else {
+					floats.AddScaled(dstRow(r), float64(v), B.RawRowView(k))
+				}
+			}
+		}
+	case aSparse && bSparse:
+		B := ToCSR(b)
+		a.(mat.NonZeroDoer).DoNonZero(func(i, k int, v float64) { B.Row(k).AddScaledTo(dstRow(i), v) })
+	case aSparse:
+		B := ToDense(b)
+		a.(mat.NonZeroDoer).DoNonZero(func(i, k int, v float64) { floats.AddScaled(dstRow(i), v, B.RawRowView(k)) })
+	default:
+		if !aFloat32 {
+			a = ToDense(a)
+		}
+		b.(mat.NonZeroDoer).DoNonZero(func(k, j int, v float64) {
+			for i := 0; i < ar; i++ {
+				d.Data[i*d.Stride+j] += a.At(i, k) * v
+			}
+		})
+	}
+	return dst
+}
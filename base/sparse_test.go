@@ -0,0 +1,89 @@
+package base
+
+import (
+	"testing"
+
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/mat"
+)
+
+func randomSparseDense(r, c int, density float64) *mat.Dense {
+	X := mat.NewDense(r, c, nil)
+	X.Apply(func(i, j int, v float64) float64 {
+		if rand.Float64() < density {
+			return rand.NormFloat64()
+		}
+		return 0
+	}, X)
+	return X
+}
+
+func TestCSR(t *testing.T) {
+	X := randomSparseDense(7, 5, .3)
+	csr, csc := ToCSR(X), ToCSC(X)
+	for _, m := range []mat.Matrix{csr, csc, csr.ToCSC(), csc.ToCSR(), csr.T().T(), csc.T().T()} {
+		if !mat.Equal(X, m) || !mat.Equal(X, ToDense(m)) {
+			t.Errorf("%T differs from its dense origin", m)
+		}
+	}
+	if !mat.Equal(X.T(), csr.T()) {
+		t.Error("T differs")
+	}
+	if !mat.Equal(X.Slice(2, 5, 0, 5), csr.RowSlice(2, 5)) {
+		t.Error("RowSlice differs")
+	}
+	sel := csr.SelectRows([]int{4, 0, 4})
+	for i, r := range []int{4, 0, 4} {
+		if !mat.Equal(X.RowView(r).T(), sel.RowSlice(i, i+1)) {
+			t.Errorf("SelectRows row %d differs", i)
+		}
+	}
+	if nnz := csr.RowSlice(2, 5).NNZ(); nnz != csr.Indptr[5]-csr.Indptr[2] {
+		t.Errorf("unexpected NNZ %d", nnz)
+	}
+	a, b := csr.Row(1), csr.Row(3)
+	if got, want := a.Dot(b), mat.Dot(X.RowView(1), X.RowView(3)); got != want {
+		t.Errorf("Dot: expected %g, got %g", want, got)
+	}
+	for _, c := range []struct {
+		name    string
+		indptr  []int
+		indices []int
+	}{
+		{"short indptr", []int{0, 1}, []int{0}},
+		{"indptr not starting at 0", []int{1, 1, 2}, []int{0, 1}},
+		{"decreasing indptr", []int{0, 2, 1}, []int{0, 1}},
+		{"index out of range", []int{0, 1, 2}, []int{0, 2}},
+		{"unsorted indices", []int{0, 2, 2}, []int{1, 0}},
+		{"duplicate indices", []int{0, 2, 2}, []int{1, 1}},
+	} {
+		for _, newCompressed := range []func(){
+			func() { NewCSR(2, 2, c.indptr, c.indices, []float64{1, 2}) },
+			func() { NewCSC(2, 2, c.indptr, c.indices, []float64{1, 2}) },
+		} {
+			func() {
+				defer func() {
+					if r := recover(); r == nil {
+						t.Errorf("expected a panic for %s", c.name)
+					}
+				}()
+				newCompressed()
+			}()
+		}
+	}
+	NewCSR(2, 2, []int{0, 2, 2}, []int{0, 1}, []float64{1, 2})
+}
+
+func TestMatMul(t *testing.T) {
+	A, B := randomSparseDense(6, 4, .4), randomSparseDense(4, 3, .4)
+	want := &mat.Dense{}
+	want.Mul(A, B)
+	for _, ab := range [][2]mat.Matrix{{ToCSR(A), B}, {A, ToCSC(B)}, {ToCSR(A), ToCSR(B)}} {
+		if got := MatMul(&mat.Dense{}, ab[0], ab[1]); !mat.EqualApprox(want, got, 1e-12) {
+			t.Errorf("%T.%T: expected\n%g\ngot\n%g", ab[0], ab[1], mat.Formatted(want), mat.Formatted(got))
+		}
+	}
+	if got := MatMul(&mat.Dense{}, ToCSC(B).T(), ToCSR(A).T()); !mat.EqualApprox(want.T(), got, 1e-12) {
+		t.Errorf("transposed: expected\n%g\ngot\n%g", mat.Formatted(want.T()), mat.Formatted(got))
+	}
+}
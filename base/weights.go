@@ -0,0 +1,82 @@
+package base
+
+import (
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// WeightedFiter is implemented by estimators whose fit accepts a weight per sample
+type WeightedFiter interface {
+	Fiter
+	FitWeighted(X, Y mat.Matrix, sampleWeight []float64) Fiter
+}
+
+// CheckSampleWeight returns an error wrapping ErrShapeMismatch if sampleWeight has not a weight per row of X,
+// or wrapping ErrInvalidParam if a weight is negative or not finite, or if all weights are zero. a nil sampleWeight is valid
+func CheckSampleWeight(X mat.Matrix, sampleWeight []float64) error {
+	if sampleWeight == nil {
+		return nil
+	}
+	if nSamples, _ := X.Dims(); nSamples != len(sampleWeight) {
+		return fmt.Errorf("%w: %d sample weights for %d samples", ErrShapeMismatch, len(sampleWeight), nSamples)
+	}
+	sum := 0.
+	for i, w := range sampleWeight {
+		if w < 0 || math.IsNaN(w) || math.IsInf(w, 0) {
+			return fmt.Errorf("%w: invalid sample weight %g at %d", ErrInvalidParam, w, i)
+		}
+		sum += w
+	}
+	if sum == 0 {
+		return fmt.Errorf("%w: all sample weights are zero", ErrInvalidParam)
+	}
+	return nil
+}
+
+// IsUniformWeight returns true if sampleWeight is nil or all its weights are equal
+func IsUniformWeight(sampleWeight []float64) bool {
+	for _, w := range sampleWeight {
+		if w != sampleWeight[0] {
+			return false
+		}
+	}
+	return true
+}
+
+// FitWeighted checks sampleWeight and calls m.FitWeighted if m is a WeightedFiter, else m.Fit if sampleWeight is uniform.
+// it returns a panic as an error
+func FitWeighted(m Fiter, X, Y mat.Matrix, sampleWeight []float64) (err error) {
+	if err = CheckSampleWeight(X, sampleWeight); err != nil {
+		return
+	}
+	wf, ok := m.(WeightedFiter)
+	if !ok {
+		if !IsUniformWeight(sampleWeight) {
+			return fmt.Errorf("%w: %T does not support sample weights", ErrInvalidParam, m)
+		}
+		if fe, ok := m.(FiterE); ok {
+			return fe.FitE(X, Y)
+		}
+		return FitE(m, X, Y)
+	}
+	if err = CheckXY(X, Y); err != nil {
+		return
+	}
+	defer Recover(&err)
+	wf.FitWeighted(X, Y, sampleWeight)
+	return
+}
+
+// SelectWeights returns the weights of the samples in indices, or nil if sampleWeight is nil
+func SelectWeights(sampleWeight []float64, indices []int) []float64 {
+	if sampleWeight == nil {
+		return nil
+	}
+	w := make([]float64, len(indices))
+	for i, idx := range indices {
+		w[i] = sampleWeight[idx]
+	}
+	return w
+}
@@ -0,0 +1,60 @@
+package base
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+type weightsTestFiter struct{ sampleWeight []float64 }
+
+func (m *weightsTestFiter) Fit(X, Y mat.Matrix) Fiter { return m.FitWeighted(X, Y, nil) }
+
+func (m *weightsTestFiter) FitWeighted(X, Y mat.Matrix, sampleWeight []float64) Fiter {
+	m.sampleWeight = sampleWeight
+	return m
+}
+
+type unweightedTestFiter struct{ fitted bool }
+
+func (m *unweightedTestFiter) Fit(X, Y mat.Matrix) Fiter { m.fitted = true; return m }
+
+func TestCheckSampleWeight(t *testing.T) {
+	X := mat.NewDense(3, 1, nil)
+	for _, tc := range []struct {
+		w   []float64
+		err error
+	}{
+		{nil, nil},
+		{[]float64{1, 0, 2}, nil},
+		{[]float64{1, 2}, ErrShapeMismatch},
+		{[]float64{1, -1, 2}, ErrInvalidParam},
+		{[]float64{1, math.NaN(), 2}, ErrInvalidParam},
+		{[]float64{0, 0, 0}, ErrInvalidParam},
+	} {
+		if err := CheckSampleWeight(X, tc.w); !errors.Is(err, tc.err) || (err == nil) != (tc.err == nil) {
+			t.Errorf("%v: expected %v, got %v", tc.w, tc.err, err)
+		}
+	}
+}
+
+func TestFitWeighted(t *testing.T) {
+	X, Y := mat.NewDense(3, 1, nil), mat.NewDense(3, 1, nil)
+	w := []float64{1, 2, 3}
+	wf := &weightsTestFiter{}
+	if err := FitWeighted(wf, X, Y, w); err != nil || &wf.sampleWeight[0] != &w[0] {
+		t.Errorf("weights must be passed to FitWeighted, got %v %v", wf.sampleWeight, err)
+	}
+	uf := &unweightedTestFiter{}
+	if err := FitWeighted(uf, X, Y, w); !errors.Is(err, ErrInvalidParam) || uf.fitted {
+		t.Errorf("expected ErrInvalidParam, got %v", err)
+	}
+	if err := FitWeighted(uf, X, Y, []float64{2, 2, 2}); err != nil || !uf.fitted {
+		t.Errorf("uniform weights must fall back to Fit, got %v", err)
+	}
+	if w := SelectWeights(w, []int{2, 0}); w[0] != 3 || w[1] != 1 {
+		t.Errorf("unexpected SelectWeights %v", w)
+	}
+}
@@ -0,0 +1,467 @@
+package cluster
+
+import (
+	"container/heap"
+	"fmt"
+	"math"
+	"runtime"
+	"sort"
+
+	"github.com/pa-m/sklearn/base"
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mat"
+)
+
+// AgglomerativeClustering builds a hierarchy of clusters by merging, starting from the samples, the pair of clusters with
+// the lowest linkage distance until one cluster remains. Labels are the clusters before the last NClusters-1 merges, or
+// before the merges at a linkage distance of at least DistanceThreshold
+type AgglomerativeClustering struct {
+	// NClusters is the number of clusters of Labels. it must be 0 when DistanceThreshold is set
+	NClusters int
+	// Linkage is the distance between clusters: "ward" (default) the increase of variance of the merged cluster,
+	// "complete" the maximum, "average" the mean or "single" the minimum distance between their samples
+	Linkage string
+	// Distance between samples, default EuclideanDistance, which ward requires
+	Distance func(a, b mat.Vector) float64 `persist:"-"`
+	// Connectivity is an optional NSamples x NSamples matrix, such as the KNeighborsGraph of a neighbors.NearestNeighbors,
+	// whose non zero elements connect samples. only connected clusters are merged. its connected components are first
+	// connected by their nearest samples
+	Connectivity mat.Matrix `persist:"-"`
+	// DistanceThreshold, if positive, is the linkage distance from which clusters are not merged in Labels
+	DistanceThreshold float64
+	NJobs             int
+	// Runtime filled members
+	// Labels is the cluster of each sample
+	Labels []int `param:"-"`
+	// NClustersFound is the number of clusters of Labels
+	NClustersFound int `param:"-"`
+	// NLeaves is the number of samples
+	NLeaves int `param:"-"`
+	// NConnectedComponents is the number of connected components of Connectivity, 1 without Connectivity
+	NConnectedComponents int `param:"-"`
+	// Children are the clusters merged at each step, n < NLeaves being a sample and n >= NLeaves the cluster formed at
+	// step n-NLeaves
+	Children [][2]int `param:"-"`
+	// Distances are the linkage distances of the merges
+	Distances []float64 `param:"-"`
+}
+
+// NewAgglomerativeClustering returns an AgglomerativeClustering with ward linkage
+func NewAgglomerativeClustering(nClusters int) *AgglomerativeClustering {
+	return &AgglomerativeClustering{NClusters: nClusters, Linkage: "ward"}
+}
+
+// PredicterClone for AgglomerativeClustering
+func (m *AgglomerativeClustering) PredicterClone() base.Predicter {
+	clone := *m
+	clone.Labels, clone.Children, clone.Distances = nil, nil, nil
+	return base.DeepCopy(&clone).(*AgglomerativeClustering)
+}
+
+// IsFitted returns true when Labels have been computed. see base.FittedChecker
+func (m *AgglomerativeClustering) IsFitted() bool { return m.Labels != nil }
+
+// IsClassifier returns true for AgglomerativeClustering
+func (m *AgglomerativeClustering) IsClassifier() bool { return true }
+
+func (m *AgglomerativeClustering) checkParams() error {
+	if m.NClusters < 0 || m.DistanceThreshold < 0 {
+		return fmt.Errorf("%w: NClusters and DistanceThreshold must be >= 0", base.ErrInvalidParam)
+	}
+	if (m.NClusters > 0) == (m.DistanceThreshold > 0) {
+		return fmt.Errorf("%w: exactly one of NClusters and DistanceThreshold must be set", base.ErrInvalidParam)
+	}
+	switch m.Linkage {
+	case "", "ward":
+		if !isEuclidean(m.Distance) {
+			return fmt.Errorf("%w: ward linkage requires EuclideanDistance", base.ErrInvalidParam)
+		}
+	case "complete", "average", "single":
+	default:
+		return fmt.Errorf("%w: Linkage must be ward, complete, average or single, got %q", base.ErrInvalidParam, m.Linkage)
+	}
+	return nil
+}
+
+// Fit builds the hierarchy of clusters of the samples of X. Y is not used
+func (m *AgglomerativeClustering) Fit(Xmatrix, Ymatrix mat.Matrix) base.Fiter {
+	if err := m.checkParams(); err != nil {
+		panic(err)
+	}
+	X := base.ToDense(Xmatrix)
+	NSamples, _ := X.Dims()
+	if NSamples < m.NClusters {
+		panic(fmt.Errorf("%w: NSamples<m.NClusters %d<%d", base.ErrInvalidParam, NSamples, m.NClusters))
+	}
+	if m.Connectivity != nil {
+		if r, c := m.Connectivity.Dims(); r != NSamples || c != NSamples {
+			panic(fmt.Errorf("%w: Connectivity must be %dx%d, got %dx%d", base.ErrShapeMismatch, NSamples, NSamples, r, c))
+		}
+	}
+	if m.Distance == nil {
+		m.Distance = EuclideanDistance
+	}
+	if m.NJobs <= 0 {
+		m.NJobs = runtime.NumCPU()
+	}
+	m.NLeaves, m.NConnectedComponents = NSamples, 1
+	if m.Connectivity == nil {
+		m.nnChain(X)
+	} else {
+		m.connectedTree(X)
+	}
+	m.cut()
+	return m
+}
+
+// FitE is Fit returning an error instead of panicking
+func (m *AgglomerativeClustering) FitE(X, Y mat.Matrix) error {
+	if err := m.checkParams(); err != nil {
+		return err
+	}
+	return base.FitE(m, X, Y)
+}
+
+// lanceWilliams returns the linkage distance between the cluster merging a and b and a cluster k from the distances
+// between them and the number of samples of each
+func (m *AgglomerativeClustering) lanceWilliams(dak, dbk, dab float64, na, nb, nk int) float64 {
+	switch m.Linkage {
+	case "single":
+		return math.Min(dak, dbk)
+	case "complete":
+		return math.Max(dak, dbk)
+	case "average":
+		return (float64(na)*dak + float64(nb)*dbk) / float64(na+nb)
+	default:
+		d2 := (float64(na+nk)*dak*dak + float64(nb+nk)*dbk*dbk - float64(nk)*dab*dab) / float64(na+nb+nk)
+		return math.Sqrt(math.Max(d2, 0))
+	}
+}
+
+// nnChain merges clusters by the nearest neighbor chain algorithm, updating a condensed matrix of the distances between
+// clusters by the Lance-Williams formula of the linkage, then orders the merges by distance
+func (m *AgglomerativeClustering) nnChain(X *mat.Dense) {
+	n, _ := X.Dims()
+	D := make([]float64, n*(n-1)/2)
+	at := func(i, j int) *float64 {
+		if i > j {
+			i, j = j, i
+		}
+		return &D[n*i-i*(i+1)/2+j-i-1]
+	}
+	base.Parallelize(m.NJobs, n, func(th, start, end int) {
+		for i := start; i < end; i++ {
+			for j := i + 1; j < n; j++ {
+				*at(i, j) = m.Distance(X.RowView(i), X.RowView(j))
+			}
+		}
+	})
+	size := make([]int, n)
+	for i := range size {
+		size[i] = 1
+	}
+	type merge struct {
+		a, b int
+		d    float64
+	}
+	merges := make([]merge, 0, n-1)
+	chain := make([]int, 0, n)
+	for len(merges) < n-1 {
+		if len(chain) == 0 {
+			for i := range size {
+				if size[i] > 0 {
+					chain = append(chain, i)
+					break
+				}
+			}
+		}
+		// grow the chain of nearest neighbors until two clusters are each other's nearest neighbor
+		var a, b int
+		for {
+			a, b = chain[len(chain)-1], -1
+			dmin := math.Inf(1)
+			if len(chain) > 1 {
+				b = chain[len(chain)-2]
+				dmin = *at(a, b)
+			}
+			for k := range size {
+				if size[k] > 0 && k != a && *at(a, k) < dmin {
+					b, dmin = k, *at(a, k)
+				}
+			}
+			if len(chain) > 1 && b == chain[len(chain)-2] {
+				break
+			}
+			chain = append(chain, b)
+		}
+		chain = chain[:len(chain)-2]
+		if a > b {
+			a, b = b, a
+		}
+		dab := *at(a, b)
+		merges = append(merges, merge{a, b, dab})
+		// the merged cluster replaces b
+		for k := range size {
+			if size[k] > 0 && k != a && k != b {
+				*at(b, k) = m.lanceWilliams(*at(a, k), *at(b, k), dab, size[a], size[b], size[k])
+			}
+		}
+		size[b] += size[a]
+		size[a] = 0
+	}
+	sort.SliceStable(merges, func(i, j int) bool { return merges[i].d < merges[j].d })
+	// number the clusters in the order of merges, following the cluster of each sample
+	clusterOf := make([]int, n)
+	for i := range clusterOf {
+		clusterOf[i] = i
+	}
+	m.Children, m.Distances = make([][2]int, n-1), make([]float64, n-1)
+	for step, mg := range merges {
+		a, b := clusterOf[mg.a], clusterOf[mg.b]
+		if a > b {
+			a, b = b, a
+		}
+		m.Children[step], m.Distances[step] = [2]int{a, b}, mg.d
+		clusterOf[mg.a], clusterOf[mg.b] = n+step, n+step
+	}
+}
+
+// linkageEdge is the linkage distance between connected clusters a < b
+type linkageEdge struct {
+	d    float64
+	a, b int
+}
+
+// linkageHeap is a min-heap of edges by distance
+type linkageHeap []linkageEdge
+
+func (h linkageHeap) Len() int { return len(h) }
+func (h linkageHeap) Less(i, j int) bool {
+	if h[i].d != h[j].d {
+		return h[i].d < h[j].d
+	}
+	if h[i].a != h[j].a {
+		return h[i].a < h[j].a
+	}
+	return h[i].b < h[j].b
+}
+func (h linkageHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *linkageHeap) Push(x interface{}) { *h = append(*h, x.(linkageEdge)) }
+func (h *linkageHeap) Pop() interface{} {
+	old := *h
+	e := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return e
+}
+
+// connectedTree merges the pair of connected clusters with the lowest linkage distance. ward distances are computed
+// from the centroids of clusters, other linkages from the distances between connected samples
+func (m *AgglomerativeClustering) connectedTree(X *mat.Dense) {
+	n, NFeatures := X.Dims()
+	// neighbors[c] maps the clusters connected to cluster c to their linkage distance
+	neighbors := make([]map[int]float64, 2*n-1)
+	for i := 0; i < n; i++ {
+		neighbors[i] = make(map[int]float64)
+	}
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			if i != j && m.Connectivity.At(i, j) != 0 {
+				d := m.Distance(X.RowView(i), X.RowView(j))
+				neighbors[i][j], neighbors[j][i] = d, d
+			}
+		}
+	}
+	m.connectComponents(X, neighbors[:n])
+
+	size := make([]int, 2*n-1)
+	sums := mat.NewDense(2*n-1, NFeatures, nil)
+	for i := 0; i < n; i++ {
+		size[i] = 1
+		sums.SetRow(i, X.RawRowView(i))
+	}
+	h := &linkageHeap{}
+	for i := 0; i < n; i++ {
+		for j, d := range neighbors[i] {
+			if i < j {
+				*h = append(*h, linkageEdge{d, i, j})
+			}
+		}
+	}
+	heap.Init(h)
+	active := make([]bool, 2*n-1)
+	for i := 0; i < n; i++ {
+		active[i] = true
+	}
+	centroid, other := make([]float64, NFeatures), make([]float64, NFeatures)
+	m.Children, m.Distances = make([][2]int, 0, n-1), make([]float64, 0, n-1)
+	for c := n; c < 2*n-1; c++ {
+		e := heap.Pop(h).(linkageEdge)
+		for !active[e.a] || !active[e.b] {
+			e = heap.Pop(h).(linkageEdge)
+		}
+		a, b := e.a, e.b
+		active[a], active[b], active[c] = false, false, true
+		size[c] = size[a] + size[b]
+		floats.AddTo(sums.RawRowView(c), sums.RawRowView(a), sums.RawRowView(b))
+		neighbors[c] = make(map[int]float64)
+		for _, pair := range [2][2]int{{a, b}, {b, a}} {
+			for k, d := range neighbors[pair[0]] {
+				if k == pair[1] {
+					continue
+				}
+				if _, done := neighbors[c][k]; done {
+					continue
+				}
+				// a cluster connected to only one of a and b keeps its distance to it, except for ward
+				switch dk, ok := neighbors[pair[1]][k]; {
+				case m.Linkage == "" || m.Linkage == "ward":
+					floats.ScaleTo(centroid, 1/float64(size[c]), sums.RawRowView(c))
+					floats.ScaleTo(other, 1/float64(size[k]), sums.RawRowView(k))
+					d = math.Sqrt(2*float64(size[c]*size[k])/float64(size[c]+size[k])) * floats.Distance(centroid, other, 2)
+				case ok:
+					d = m.lanceWilliams(d, dk, e.d, size[pair[0]], size[pair[1]], size[k])
+				}
+				neighbors[c][k] = d
+			}
+		}
+		for k, d := range neighbors[c] {
+			delete(neighbors[k], a)
+			delete(neighbors[k], b)
+			neighbors[k][c] = d
+			heap.Push(h, linkageEdge{d, k, c})
+		}
+		neighbors[a], neighbors[b] = nil, nil
+		m.Children = append(m.Children, [2]int{a, b})
+		m.Distances = append(m.Distances, e.d)
+	}
+}
+
+// connectComponents sets NConnectedComponents and connects each pair of connected components of the graph of samples
+// by an edge between their nearest samples
+func (m *AgglomerativeClustering) connectComponents(X *mat.Dense, neighbors []map[int]float64) {
+	n := len(neighbors)
+	component := make([]int, n)
+	for i := range component {
+		component[i] = -1
+	}
+	var members [][]int
+	for i := range component {
+		if component[i] >= 0 {
+			continue
+		}
+		c := len(members)
+		component[i] = c
+		stack, samples := []int{i}, []int{}
+		for len(stack) > 0 {
+			s := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			samples = append(samples, s)
+			for j := range neighbors[s] {
+				if component[j] < 0 {
+					component[j] = c
+					stack = append(stack, j)
+				}
+			}
+		}
+		members = append(members, samples)
+	}
+	m.NConnectedComponents = len(members)
+	for ci := range members {
+		for cj := ci + 1; cj < len(members); cj++ {
+			bi, bj, dmin := -1, -1, math.Inf(1)
+			for _, i := range members[ci] {
+				for _, j := range members[cj] {
+					if d := m.Distance(X.RowView(i), X.RowView(j)); bi < 0 || d < dmin {
+						bi, bj, dmin = i, j, d
+					}
+				}
+			}
+			neighbors[bi][bj], neighbors[bj][bi] = dmin, dmin
+		}
+	}
+}
+
+// cut sets Labels to the clusters before the last NClusters-1 merges, or before the merges at a distance of at least
+// DistanceThreshold. clusters are numbered in the order of their first sample
+func (m *AgglomerativeClustering) cut() {
+	n := m.NLeaves
+	m.NClustersFound = m.NClusters
+	if m.DistanceThreshold > 0 {
+		m.NClustersFound = 1
+		for _, d := range m.Distances {
+			if d >= m.DistanceThreshold {
+				m.NClustersFound++
+			}
+		}
+	}
+	parent := make([]int, 2*n-1)
+	for i := range parent {
+		parent[i] = i
+	}
+	for step, children := range m.Children[:n-m.NClustersFound] {
+		parent[children[0]], parent[children[1]] = n+step, n+step
+	}
+	labelOf := make(map[int]int)
+	m.Labels = make([]int, n)
+	for i := range m.Labels {
+		root := i
+		for parent[root] != root {
+			root = parent[root]
+		}
+		if _, ok := labelOf[root]; !ok {
+			labelOf[root] = len(labelOf)
+		}
+		m.Labels[i] = labelOf[root]
+	}
+}
+
+// LinkageMatrix returns the merges in the format of scipy.cluster.hierarchy.linkage, to draw a dendrogram: row i holds
+// the Children merged at step i, their linkage distance and the number of samples of the merged cluster
+func (m *AgglomerativeClustering) LinkageMatrix() *mat.Dense {
+	base.MustBeFitted(m)
+	n := m.NLeaves
+	if n < 2 {
+		return &mat.Dense{}
+	}
+	size := make([]int, 2*n-1)
+	for i := 0; i < n; i++ {
+		size[i] = 1
+	}
+	Z := mat.NewDense(n-1, 4, nil)
+	for step, children := range m.Children {
+		size[n+step] = size[children[0]] + size[children[1]]
+		Z.SetRow(step, []float64{float64(children[0]), float64(children[1]), m.Distances[step], float64(size[n+step])})
+	}
+	return Z
+}
+
+// GetNOutputs returns output columns number for Y to pass to predict
+func (m *AgglomerativeClustering) GetNOutputs() int { return 1 }
+
+// Predict for AgglomerativeClustering returns Labels in Y. X must be the one passed to Fit
+func (m *AgglomerativeClustering) Predict(X mat.Matrix, Ymutable mat.Mutable) *mat.Dense {
+	base.MustBeFitted(m)
+	Y := base.ToDense(Ymutable)
+	nSamples, _ := X.Dims()
+	if Y.IsZero() {
+		*Y = *mat.NewDense(nSamples, m.GetNOutputs(), nil)
+	}
+	if ySamples, yCols := Y.Dims(); nSamples != len(m.Labels) || ySamples != len(m.Labels) || yCols != 1 {
+		panic(fmt.Errorf("%w: X must be the one passed to Fit and Y must have size samples*1", base.ErrShapeMismatch))
+	}
+	for i, label := range m.Labels {
+		Y.Set(i, 0, float64(label))
+	}
+	return base.FromDense(Ymutable, Y)
+}
+
+// PredictE is Predict returning an error instead of panicking
+func (m *AgglomerativeClustering) PredictE(X mat.Matrix, Y mat.Mutable) (*mat.Dense, error) {
+	if !m.IsFitted() {
+		return nil, base.ErrNotFitted
+	}
+	return base.PredictE(m, X, Y)
+}
+
+// Score for AgglomerativeClustering returns 1
+func (m *AgglomerativeClustering) Score(X, Y mat.Matrix) float64 { return 1 }
@@ -0,0 +1,189 @@
+package cluster
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/pa-m/sklearn/base"
+	"github.com/pa-m/sklearn/datasets"
+	"github.com/pa-m/sklearn/neighbors"
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mat"
+)
+
+var (
+	_ base.Predicter  = &AgglomerativeClustering{}
+	_ base.PredicterE = &AgglomerativeClustering{}
+)
+
+func ExampleAgglomerativeClustering() {
+	X := mat.NewDense(4, 1, []float64{0, 1, 3, 7})
+	m := NewAgglomerativeClustering(2)
+	m.Linkage = "single"
+	m.Fit(X, nil)
+	fmt.Println(m.Labels)
+	fmt.Printf("%g\n", mat.Formatted(m.LinkageMatrix()))
+	// Output:
+	// [0 0 0 1]
+	// ⎡0  1  1  2⎤
+	// ⎢2  4  2  3⎥
+	// ⎣3  5  4  4⎦
+}
+
+// bruteLinkage returns the distances of merges of the clusters of X with the lowest linkage and the clusters before the
+// last nClusters-1 merges, computing linkages from their definition
+func bruteLinkage(X *mat.Dense, linkage string, nClusters int) (distances []float64, labels []int) {
+	n, _ := X.Dims()
+	clusters := make([][]int, n)
+	for i := range clusters {
+		clusters[i] = []int{i}
+	}
+	dist := func(a, b []int) float64 {
+		var d float64
+		switch linkage {
+		case "ward":
+			ca, cb := make([]float64, 2), make([]float64, 2)
+			for _, i := range a {
+				floats.AddScaled(ca, 1/float64(len(a)), X.RawRowView(i))
+			}
+			for _, i := range b {
+				floats.AddScaled(cb, 1/float64(len(b)), X.RawRowView(i))
+			}
+			return math.Sqrt(2*float64(len(a)*len(b))/float64(len(a)+len(b))) * floats.Distance(ca, cb, 2)
+		case "single":
+			d = math.Inf(1)
+		}
+		for _, i := range a {
+			for _, j := range b {
+				dij := floats.Distance(X.RawRowView(i), X.RawRowView(j), 2)
+				switch linkage {
+				case "single":
+					d = math.Min(d, dij)
+				case "complete":
+					d = math.Max(d, dij)
+				default:
+					d += dij / float64(len(a)*len(b))
+				}
+			}
+		}
+		return d
+	}
+	for len(clusters) > 1 {
+		if len(clusters) == nClusters {
+			labels = make([]int, n)
+			for c, samples := range clusters {
+				for _, i := range samples {
+					labels[i] = c
+				}
+			}
+		}
+		ba, bb, dmin := 0, 1, math.Inf(1)
+		for a := range clusters {
+			for b := a + 1; b < len(clusters); b++ {
+				if d := dist(clusters[a], clusters[b]); d < dmin {
+					ba, bb, dmin = a, b, d
+				}
+			}
+		}
+		distances = append(distances, dmin)
+		clusters[ba] = append(clusters[ba], clusters[bb]...)
+		clusters = append(clusters[:bb], clusters[bb+1:]...)
+	}
+	return
+}
+
+// samePartition returns true if labels a and b group the samples the same way
+func samePartition(a, b []int) bool {
+	ab, ba := map[int]int{}, map[int]int{}
+	for i := range a {
+		if l, ok := ab[a[i]]; ok && l != b[i] {
+			return false
+		}
+		if l, ok := ba[b[i]]; ok && l != a[i] {
+			return false
+		}
+		ab[a[i]], ba[b[i]] = b[i], a[i]
+	}
+	return true
+}
+
+func TestAgglomerativeClustering_Linkage(t *testing.T) {
+	X, _ := datasets.MakeBlobs(&datasets.MakeBlobsConfig{NSamples: 40, Centers: 3, ClusterStd: 2, RandomState: base.NewSource(7)})
+	full := mat.NewDense(40, 40, nil)
+	for i := range full.RawMatrix().Data {
+		full.RawMatrix().Data[i] = 1
+	}
+	for _, linkage := range []string{"ward", "complete", "average", "single"} {
+		expectedDistances, expectedLabels := bruteLinkage(X, linkage, 3)
+		// a complete connectivity graph gives the same hierarchy
+		for _, connectivity := range []mat.Matrix{nil, full} {
+			m := NewAgglomerativeClustering(3)
+			m.Linkage, m.Connectivity = linkage, connectivity
+			m.Fit(X, nil)
+			Z := m.LinkageMatrix()
+			if !floats.EqualApprox(mat.Col(nil, 2, Z), expectedDistances, 1e-9) {
+				t.Errorf("%s: expected distances %.3g, got %.3g", linkage, expectedDistances, mat.Col(nil, 2, Z))
+			}
+			if !samePartition(m.Labels, expectedLabels) || m.NClustersFound != 3 {
+				t.Errorf("%s: expected labels %v, got %v", linkage, expectedLabels, m.Labels)
+			}
+			if Z.At(38, 3) != 40 {
+				t.Errorf("%s: expected the last merge to hold all samples, got %g", linkage, Z.At(38, 3))
+			}
+		}
+	}
+}
+
+func TestAgglomerativeClustering_Connectivity(t *testing.T) {
+	// 0 and 2 are nearest but not connected
+	X := mat.NewDense(3, 1, []float64{0, 5, 1})
+	m := &AgglomerativeClustering{NClusters: 1, Linkage: "single", Connectivity: mat.NewDense(3, 3, []float64{0, 1, 0, 1, 0, 1, 0, 1, 0})}
+	m.Fit(X, nil)
+	if expected := [][2]int{{1, 2}, {0, 3}}; !reflect.DeepEqual(m.Children, expected) || !floats.Equal(m.Distances, []float64{4, 5}) {
+		t.Errorf("expected children %v at distances [4 5], got %v at %g", expected, m.Children, m.Distances)
+	}
+
+	// a graph of 5 nearest neighbors has a connected component per blob, which are connected to build the tree
+	centers := mat.NewDense(4, 2, []float64{0, 0, 20, 0, 0, 20, 20, 20})
+	X, Y := datasets.MakeBlobs(&datasets.MakeBlobsConfig{NSamples: 200, Centers: centers, ClusterStd: 1, RandomState: base.NewSource(7)})
+	nn := neighbors.NewNearestNeighbors()
+	nn.Fit(X, nil)
+	m = NewAgglomerativeClustering(4)
+	m.Connectivity = nn.KNeighborsGraph(X, 5, "connectivity", false)
+	m.Fit(X, nil)
+	if m.NConnectedComponents != 4 || len(m.Children) != 199 {
+		t.Errorf("expected 4 connected components and a complete tree, got %d and %d merges", m.NConnectedComponents, len(m.Children))
+	}
+	if !samePartition(m.Labels, intsOf(Y)) {
+		t.Error("expected a cluster per blob")
+	}
+}
+
+func TestAgglomerativeClustering_DistanceThreshold(t *testing.T) {
+	centers := mat.NewDense(3, 2, []float64{0, 0, 20, 0, 0, 20})
+	X, Y := datasets.MakeBlobs(&datasets.MakeBlobsConfig{NSamples: 150, Centers: centers, ClusterStd: 1, RandomState: base.NewSource(7)})
+	m := &AgglomerativeClustering{Linkage: "average", DistanceThreshold: 10}
+	if err := m.FitE(X, nil); err != nil {
+		t.Fatal(err)
+	}
+	if m.NClustersFound != 3 || !samePartition(m.Labels, intsOf(Y)) {
+		t.Errorf("expected a cluster per blob, got %d clusters", m.NClustersFound)
+	}
+	if !mat.Equal(m.Predict(X, nil), mat.NewDense(150, 1, intsToFloats(m.Labels))) {
+		t.Error("expected Predict to return Labels")
+	}
+
+	for _, m := range []*AgglomerativeClustering{
+		{NClusters: 2, DistanceThreshold: 1},
+		{},
+		{NClusters: 2, Linkage: "median"},
+		{NClusters: 2, Distance: MinkowskiDistance(1)},
+	} {
+		if err := m.FitE(X, nil); !errors.Is(err, base.ErrInvalidParam) {
+			t.Errorf("%+v: expected ErrInvalidParam, got %v", m, err)
+		}
+	}
+}
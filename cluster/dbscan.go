@@ -1,6 +1,7 @@
 package cluster
 
 import (
+	"fmt"
 	"runtime"
 
 	"github.com/pa-m/sklearn/base"
@@ -25,9 +26,9 @@ type DBSCAN struct {
 	DBSCANConfig
 	SampleWeight []float64
 	// members filled by Fit
-	NeighborsModel    *neighbors.NearestNeighbors
-	Labels            []int
-	CoreSampleIndices []int
+	NeighborsModel    *neighbors.NearestNeighbors `param:"-"`
+	Labels            []int                       `param:"-"`
+	CoreSampleIndices []int                       `param:"-"`
 }
 
 // NewDBSCAN creates an *DBSCAN
@@ -64,9 +65,13 @@ func NewDBSCAN(config *DBSCANConfig) *DBSCAN {
 // PredicterClone for DBSCAN
 func (m *DBSCAN) PredicterClone() base.Predicter {
 	clone := *m
-	return &clone
+	clone.NeighborsModel, clone.Labels, clone.CoreSampleIndices = nil, nil, nil
+	return base.DeepCopy(&clone).(*DBSCAN)
 }
 
+// IsFitted returns true when Labels have been computed. see base.FittedChecker
+func (m *DBSCAN) IsFitted() bool { return m.Labels != nil }
+
 // IsClassifier returns true for DBSCAN
 func (m *DBSCAN) IsClassifier() bool { return true }
 
@@ -123,6 +128,16 @@ func (m *DBSCAN) Fit(Xmatrix, Ymatrix mat.Matrix) base.Fiter {
 
 }
 
+// FitE is Fit returning an error instead of panicking
+func (m *DBSCAN) FitE(X, Y mat.Matrix) error {
+	if m.SampleWeight != nil {
+		if n, _ := X.Dims(); len(m.SampleWeight) != n {
+			return fmt.Errorf("%w: SampleWeight has %d elements for %d samples", base.ErrShapeMismatch, len(m.SampleWeight), n)
+		}
+	}
+	return base.FitE(m, X, Y)
+}
+
 // GetNOutputs returns output columns number for Y to pass to predict
 func (m *DBSCAN) GetNOutputs() int { return 1 }
 
@@ -136,7 +151,7 @@ func (m *DBSCAN) Predict(X mat.Matrix, Ymutable mat.Mutable) *mat.Dense {
 	// return m.Labels in Y
 	ySamples, yCols := Y.Dims()
 	if nSamples != len(m.Labels) || ySamples != len(m.Labels) || yCols != 1 {
-		panic("X must me the same passed to Fit and Y must have size samples*1")
+		panic(fmt.Errorf("%w: X must me the same passed to Fit and Y must have size samples*1", base.ErrShapeMismatch))
 	}
 	for i, label := range m.Labels {
 
@@ -145,6 +160,14 @@ func (m *DBSCAN) Predict(X mat.Matrix, Ymutable mat.Mutable) *mat.Dense {
 	return base.FromDense(Ymutable, Y)
 }
 
+// PredictE is Predict returning an error instead of panicking
+func (m *DBSCAN) PredictE(X mat.Matrix, Y mat.Mutable) (*mat.Dense, error) {
+	if !m.IsFitted() {
+		return nil, base.ErrNotFitted
+	}
+	return base.PredictE(m, X, Y)
+}
+
 // Score for DBSCAN returns 1
 func (m *DBSCAN) Score(X, Y mat.Matrix) float64 { return 1 }
 
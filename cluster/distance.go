@@ -2,6 +2,7 @@ package cluster
 
 import (
 	"math"
+	"reflect"
 
 	"gonum.org/v1/gonum/mat"
 )
@@ -67,3 +68,8 @@ func EuclideanDistance(a, b mat.Vector) float64 {
 	}
 	return math.Sqrt(d2)
 }
+
+// isEuclidean returns true if distance is nil or EuclideanDistance
+func isEuclidean(distance func(a, b mat.Vector) float64) bool {
+	return distance == nil || reflect.ValueOf(distance).Pointer() == reflect.ValueOf(EuclideanDistance).Pointer()
+}
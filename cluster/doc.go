@@ -1,2 +1,2 @@
-// Package cluster gathers popular unsupervised clustering algorithms. contains DBSCAN and KMeans.
+// Package cluster gathers popular unsupervised clustering algorithms. contains AgglomerativeClustering, DBSCAN, HDBSCAN, KMeans, MiniBatchKMeans and OPTICS.
 package cluster
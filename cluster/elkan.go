@@ -0,0 +1,124 @@
+package cluster
+
+import (
+	"math"
+
+	"github.com/pa-m/sklearn/base"
+	"gonum.org/v1/gonum/mat"
+)
+
+// elkan assigns samples to their nearest centroid, skipping the distances ruled out by the triangle inequality.
+// upper bounds the distance of each sample to its centroid and lower[sample*NClusters+ic] its distance to centroid ic.
+// see Elkan, Using the triangle inequality to accelerate k-means, ICML 2003
+type elkan struct {
+	m         *KMeans
+	X         mat.Matrix
+	centroids *mat.Dense
+	// previous is a copy of centroids before their update
+	previous     *mat.Dense
+	labels       []int
+	upper, lower []float64
+	threads      int
+}
+
+// newElkan returns the bounds of samples to centroids, computing every distance and assigning samples to their nearest centroid
+func newElkan(m *KMeans, X mat.Matrix, centroids *mat.Dense, labels []int, upper []float64, threads int) *elkan {
+	NSamples, NFeatures := X.Dims()
+	e := &elkan{m: m, X: X, centroids: centroids, previous: mat.DenseCopyOf(centroids), labels: labels, upper: upper,
+		lower: make([]float64, NSamples*m.NClusters), threads: threads}
+	base.Parallelize(threads, NSamples, func(th, start, end int) {
+		buf := mat.NewVecDense(NFeatures, nil)
+		for sample := start; sample < end; sample++ {
+			row := rowVector(buf, sample, X)
+			lower := e.lower[sample*m.NClusters : (sample+1)*m.NClusters]
+			labels[sample] = -1
+			for ic := range lower {
+				lower[ic] = m.Distance(row, centroids.RowView(ic))
+				if labels[sample] < 0 || lower[ic] < upper[sample] {
+					labels[sample], upper[sample] = ic, lower[ic]
+				}
+			}
+		}
+	})
+	return e
+}
+
+// assign shifts the bounds by the moves of the centroids since previous and reassigns samples whose upper bound exceeds
+// a lower bound and half the distance between the centroids. it returns the number of changed labels
+func (e *elkan) assign() int {
+	m, k := e.m, e.m.NClusters
+	NSamples, NFeatures := e.X.Dims()
+	moves := make([]float64, k)
+	// half the distances between centroids, and half the distance of each centroid to the nearest other one
+	halfDist, halfNearest := make([]float64, k*k), make([]float64, k)
+	for ic := 0; ic < k; ic++ {
+		moves[ic] = m.Distance(e.previous.RowView(ic), e.centroids.RowView(ic))
+		halfNearest[ic] = math.Inf(1)
+	}
+	for ic := 0; ic < k; ic++ {
+		for jc := 0; jc < ic; jc++ {
+			d := m.Distance(e.centroids.RowView(ic), e.centroids.RowView(jc)) / 2
+			halfDist[ic*k+jc], halfDist[jc*k+ic] = d, d
+			halfNearest[ic] = math.Min(halfNearest[ic], d)
+			halfNearest[jc] = math.Min(halfNearest[jc], d)
+		}
+	}
+	changed := make([]int, e.threads)
+	base.Parallelize(e.threads, NSamples, func(th, start, end int) {
+		buf := mat.NewVecDense(NFeatures, nil)
+		for sample := start; sample < end; sample++ {
+			label := e.labels[sample]
+			lower := e.lower[sample*k : (sample+1)*k]
+			for ic := range lower {
+				lower[ic] = math.Max(lower[ic]-moves[ic], 0)
+			}
+			upper := e.upper[sample] + moves[label]
+			if upper <= halfNearest[label] {
+				e.upper[sample] = upper
+				continue
+			}
+			var row mat.Vector
+			tight := false
+			for ic := 0; ic < k; ic++ {
+				if ic == label || upper <= lower[ic] || upper <= halfDist[label*k+ic] {
+					continue
+				}
+				if row == nil {
+					row = rowVector(buf, sample, e.X)
+				}
+				if !tight {
+					upper = m.Distance(row, e.centroids.RowView(label))
+					lower[label], tight = upper, true
+					if upper <= lower[ic] || upper <= halfDist[label*k+ic] {
+						continue
+					}
+				}
+				lower[ic] = m.Distance(row, e.centroids.RowView(ic))
+				if lower[ic] < upper {
+					label, upper = ic, lower[ic]
+				}
+			}
+			if label != e.labels[sample] {
+				e.labels[sample] = label
+				changed[th]++
+			}
+			e.upper[sample] = upper
+		}
+	})
+	n := 0
+	for _, c := range changed {
+		n += c
+	}
+	return n
+}
+
+// tighten sets upper to the distance of each sample to its centroid
+func (e *elkan) tighten() {
+	NSamples, NFeatures := e.X.Dims()
+	base.Parallelize(e.threads, NSamples, func(th, start, end int) {
+		buf := mat.NewVecDense(NFeatures, nil)
+		for sample := start; sample < end; sample++ {
+			e.upper[sample] = e.m.Distance(rowVector(buf, sample, e.X), e.centroids.RowView(e.labels[sample]))
+		}
+	})
+}
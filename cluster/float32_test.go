@@ -0,0 +1,22 @@
+package cluster
+
+import (
+	"testing"
+
+	"github.com/pa-m/sklearn/base"
+	"github.com/pa-m/sklearn/datasets"
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestKMeans_Float32(t *testing.T) {
+	X := base.General32Of(datasets.LoadIris().X).ToDense()
+	dense, f32 := &KMeans{NClusters: 3, RandomState: base.NewSource(7)}, &KMeans{NClusters: 3, RandomState: base.NewSource(7)}
+	dense.Fit(X, nil)
+	f32.Fit(base.General32Of(X), nil)
+	if !mat.EqualApprox(dense.Centroids, f32.Centroids, 1e-10) {
+		t.Errorf("dense and float32 centroids differ\n%g\n%g", mat.Formatted(dense.Centroids), mat.Formatted(f32.Centroids))
+	}
+	if !mat.EqualApprox(dense.DecisionFunction(X, nil), f32.DecisionFunction(base.General32Of(X), nil), 1e-10) {
+		t.Error("dense and float32 decision functions differ")
+	}
+}
@@ -0,0 +1,500 @@
+package cluster
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/pa-m/sklearn/base"
+	"gonum.org/v1/gonum/mat"
+)
+
+// HDBSCAN is a hierarchical DBSCAN. it builds the single linkage tree of the samples for the mutual reachability
+// distance, the greatest of the distance between two samples and of their core distances, which holds the DBSCAN
+// clusterings for every Eps. its clusters of at least MinClusterSize samples form a tree of clusters, from which the
+// clusters persisting over the widest range of densities are selected, so that clusters may have different densities
+type HDBSCAN struct {
+	// MinClusterSize is the minimum number of samples of a cluster, default 5
+	MinClusterSize int
+	// MinSamples is the number of samples, the sample included, in the neighborhood of a core sample, default MinClusterSize
+	MinSamples int
+	// ClusterSelectionEpsilon, if positive, merges the clusters split at a distance below it
+	ClusterSelectionEpsilon float64
+	// MaxClusterSize, if positive, limits the size of the clusters selected by eom
+	MaxClusterSize int
+	// Alpha divides the distances between samples, default 1
+	Alpha float64
+	// ClusterSelectionMethod is "eom" (default), the excess of mass selecting the most stable clusters, or "leaf", the
+	// leaves of the tree of clusters
+	ClusterSelectionMethod string
+	// AllowSingleCluster allows the selection of the root of the tree of clusters, holding all samples
+	AllowSingleCluster bool
+	// Metric, P, Algorithm, LeafSize and NJobs configure the neighbors.NearestNeighbors computing core distances
+	Metric    string
+	P         float64
+	Algorithm string
+	LeafSize  int
+	NJobs     int
+	// Runtime filled members
+	// Labels is the cluster of each sample, -1 for noise
+	Labels []int `param:"-"`
+	// Probabilities is the strength of the membership of each sample to its cluster, from 0 for noise to 1
+	Probabilities []float64 `param:"-"`
+	// OutlierScores are the GLOSH outlier scores of samples, from 0 for the samples of the densest part of their cluster
+	// to 1 for outliers
+	OutlierScores []float64 `param:"-"`
+}
+
+// NewHDBSCAN returns an HDBSCAN with MinClusterSize 5 and the eom ClusterSelectionMethod
+func NewHDBSCAN() *HDBSCAN {
+	return &HDBSCAN{MinClusterSize: 5, Alpha: 1, ClusterSelectionMethod: "eom", Metric: "euclidean", P: 2, Algorithm: "auto", LeafSize: 30}
+}
+
+// PredicterClone for HDBSCAN
+func (m *HDBSCAN) PredicterClone() base.Predicter {
+	clone := *m
+	clone.Labels, clone.Probabilities, clone.OutlierScores = nil, nil, nil
+	return base.DeepCopy(&clone).(*HDBSCAN)
+}
+
+// IsFitted returns true when Labels have been computed. see base.FittedChecker
+func (m *HDBSCAN) IsFitted() bool { return m.Labels != nil }
+
+// IsClassifier returns true for HDBSCAN
+func (m *HDBSCAN) IsClassifier() bool { return true }
+
+func (m *HDBSCAN) checkParams() error {
+	if m.MinClusterSize == 1 || m.MinClusterSize < 0 || m.MinSamples < 0 || m.MaxClusterSize < 0 {
+		return fmt.Errorf("%w: MinClusterSize must be >= 2, MinSamples and MaxClusterSize >= 0", base.ErrInvalidParam)
+	}
+	if m.ClusterSelectionEpsilon < 0 || m.Alpha < 0 {
+		return fmt.Errorf("%w: ClusterSelectionEpsilon and Alpha must be >= 0", base.ErrInvalidParam)
+	}
+	switch m.ClusterSelectionMethod {
+	case "", "eom", "leaf":
+	default:
+		return fmt.Errorf("%w: ClusterSelectionMethod must be eom or leaf, got %q", base.ErrInvalidParam, m.ClusterSelectionMethod)
+	}
+	return nil
+}
+
+// linkageRow is a merge of the single linkage tree. nodes n < NSamples are samples, nodes n >= NSamples the cluster
+// formed by merge n-NSamples
+type linkageRow struct {
+	left, right int
+	distance    float64
+	size        int
+}
+
+// condensedRow is an edge of the condensed tree: child, a sample or a cluster of at least MinClusterSize samples,
+// leaves cluster parent at lambda, the inverse of a distance. clusters are numbered from NSamples, the root
+type condensedRow struct {
+	parent, child int
+	lambda        float64
+	size          int
+}
+
+// Fit clusters the samples of X. Y is not used
+func (m *HDBSCAN) Fit(Xmatrix, Ymatrix mat.Matrix) base.Fiter {
+	if err := m.checkParams(); err != nil {
+		panic(err)
+	}
+	X := base.ToDense(Xmatrix)
+	NSamples, _ := X.Dims()
+	minClusterSize, minSamples := m.MinClusterSize, m.MinSamples
+	if minClusterSize == 0 {
+		minClusterSize = 5
+	}
+	if minSamples == 0 {
+		minSamples = minClusterSize
+	}
+	if minSamples > NSamples {
+		panic(fmt.Errorf("%w: MinSamples %d > NSamples %d", base.ErrInvalidParam, minSamples, NSamples))
+	}
+	if NSamples < 2 {
+		panic(fmt.Errorf("%w: HDBSCAN needs at least 2 samples", base.ErrInvalidParam))
+	}
+	nbrs := newNeighbors(X, m.Metric, m.P, m.Algorithm, m.LeafSize, m.NJobs)
+	alpha := m.Alpha
+	if alpha == 0 {
+		alpha = 1
+	}
+	core := coreDistances(nbrs, X, minSamples)
+	for i := range core {
+		core[i] /= alpha
+	}
+	tree := condenseTree(singleLinkage(m.mutualReachabilityMST(X, core, func(i, j int) float64 {
+		return nbrs.Distance(X.RowView(i), X.RowView(j)) / alpha
+	}), NSamples), minClusterSize)
+	m.selectClusters(tree, NSamples)
+	m.OutlierScores = outlierScores(tree, NSamples)
+	return m
+}
+
+// FitE is Fit returning an error instead of panicking
+func (m *HDBSCAN) FitE(X, Y mat.Matrix) error {
+	if err := m.checkParams(); err != nil {
+		return err
+	}
+	return base.FitE(m, X, Y)
+}
+
+// mutualReachabilityMST returns the edges of the minimum spanning tree of samples for the mutual reachability distance,
+// built by Prim's algorithm
+func (m *HDBSCAN) mutualReachabilityMST(X *mat.Dense, core []float64, distance func(i, j int) float64) []linkageRow {
+	n := len(core)
+	inTree := make([]bool, n)
+	reach, source := make([]float64, n), make([]int, n)
+	for i := range reach {
+		reach[i] = math.Inf(1)
+	}
+	mr := make([]float64, n)
+	edges := make([]linkageRow, 0, n-1)
+	current := 0
+	for len(edges) < n-1 {
+		inTree[current] = true
+		base.Parallelize(m.NJobs, n, func(th, start, end int) {
+			for j := start; j < end; j++ {
+				if !inTree[j] {
+					mr[j] = math.Max(math.Max(core[current], core[j]), distance(current, j))
+				}
+			}
+		})
+		next := -1
+		for j := range mr {
+			if inTree[j] {
+				continue
+			}
+			if mr[j] <= reach[j] {
+				reach[j], source[j] = mr[j], current
+			}
+			if next < 0 || reach[j] < reach[next] {
+				next = j
+			}
+		}
+		edges = append(edges, linkageRow{left: source[next], right: next, distance: reach[next]})
+		current = next
+	}
+	return edges
+}
+
+// singleLinkage returns the single linkage tree of the n samples from the edges of their minimum spanning tree
+func singleLinkage(edges []linkageRow, n int) []linkageRow {
+	sort.SliceStable(edges, func(i, j int) bool { return edges[i].distance < edges[j].distance })
+	parent, size := make([]int, 2*n-1), make([]int, 2*n-1)
+	for i := range parent {
+		parent[i] = i
+		if i < n {
+			size[i] = 1
+		}
+	}
+	find := func(x int) int {
+		root := x
+		for parent[root] != root {
+			root = parent[root]
+		}
+		for parent[x] != root {
+			parent[x], x = root, parent[x]
+		}
+		return root
+	}
+	tree := make([]linkageRow, len(edges))
+	for step, e := range edges {
+		a, b := find(e.left), find(e.right)
+		node := n + step
+		parent[a], parent[b], size[node] = node, node, size[a]+size[b]
+		tree[step] = linkageRow{left: a, right: b, distance: e.distance, size: size[node]}
+	}
+	return tree
+}
+
+// condenseTree returns the tree of the clusters of at least minClusterSize samples of the single linkage tree. a
+// split leaving less than minClusterSize samples on a side is the loss of these samples by the cluster
+func condenseTree(hierarchy []linkageRow, minClusterSize int) []condensedRow {
+	n := len(hierarchy) + 1
+	root := 2 * (n - 1)
+	sizeOf := func(node int) int {
+		if node < n {
+			return 1
+		}
+		return hierarchy[node-n].size
+	}
+	// samples returns the samples under node
+	samples := func(node int) []int {
+		var leaves []int
+		for queue := []int{node}; len(queue) > 0; queue = queue[1:] {
+			if queue[0] < n {
+				leaves = append(leaves, queue[0])
+			} else {
+				queue = append(queue, hierarchy[queue[0]-n].left, hierarchy[queue[0]-n].right)
+			}
+		}
+		return leaves
+	}
+	relabel := make([]int, root+1)
+	relabel[root] = n
+	nextLabel := n + 1
+	var tree []condensedRow
+	for queue := []int{root}; len(queue) > 0; queue = queue[1:] {
+		node := queue[0]
+		if node < n {
+			continue
+		}
+		row := hierarchy[node-n]
+		lambda := math.Inf(1)
+		if row.distance > 0 {
+			lambda = 1 / row.distance
+		}
+		parent := relabel[node]
+		for _, child := range []int{row.left, row.right} {
+			switch {
+			case sizeOf(child) < minClusterSize:
+				for _, sample := range samples(child) {
+					tree = append(tree, condensedRow{parent, sample, lambda, 1})
+				}
+			case sizeOf(row.left) >= minClusterSize && sizeOf(row.right) >= minClusterSize:
+				// a true split into two clusters
+				relabel[child] = nextLabel
+				nextLabel++
+				tree = append(tree, condensedRow{parent, relabel[child], lambda, sizeOf(child)})
+				queue = append(queue, child)
+			default:
+				// the cluster continues in child
+				relabel[child] = parent
+				queue = append(queue, child)
+			}
+		}
+	}
+	return tree
+}
+
+// selectClusters sets Labels and Probabilities from the clusters selected in the condensed tree
+func (m *HDBSCAN) selectClusters(tree []condensedRow, n int) {
+	// the stability of a cluster is the sum over its samples of the lambdas during which they are in the cluster
+	nClusters := n + 1
+	for _, row := range tree {
+		if row.child >= nClusters {
+			nClusters = row.child + 1
+		}
+	}
+	nClusters -= n
+	birth, stability := make([]float64, nClusters), make([]float64, nClusters)
+	children := make([][]int, nClusters)
+	size := make([]int, nClusters)
+	for _, row := range tree {
+		if row.child >= n {
+			birth[row.child-n], size[row.child-n] = row.lambda, row.size
+			children[row.parent-n] = append(children[row.parent-n], row.child-n)
+		}
+	}
+	for _, c := range children[0] {
+		size[0] += size[c]
+	}
+	for _, row := range tree {
+		stability[row.parent-n] += (row.lambda - birth[row.parent-n]) * float64(row.size)
+	}
+	maxClusterSize := m.MaxClusterSize
+	if maxClusterSize <= 0 {
+		maxClusterSize = n + 1
+	}
+	isCluster := make([]bool, nClusters)
+	first := 1
+	if m.AllowSingleCluster {
+		first = 0
+	}
+	var descendants func(c int, f func(int))
+	descendants = func(c int, f func(int)) {
+		for _, child := range children[c] {
+			f(child)
+			descendants(child, f)
+		}
+	}
+	var selected []int
+	if m.ClusterSelectionMethod == "leaf" {
+		for c := 1; c < nClusters; c++ {
+			if len(children[c]) == 0 {
+				selected = append(selected, c)
+			}
+		}
+	} else {
+		for c := first; c < nClusters; c++ {
+			isCluster[c] = true
+		}
+		// clusters are numbered after their parent
+		for c := nClusters - 1; c >= first; c-- {
+			subtree := 0.
+			for _, child := range children[c] {
+				subtree += stability[child]
+			}
+			if subtree > stability[c] || size[c] > maxClusterSize {
+				isCluster[c] = false
+				stability[c] = subtree
+			} else {
+				descendants(c, func(d int) { isCluster[d] = false })
+			}
+		}
+		for c, ok := range isCluster {
+			if ok {
+				selected = append(selected, c)
+			}
+		}
+	}
+	if m.ClusterSelectionEpsilon > 0 && nClusters > 1 && !(len(selected) == 1 && selected[0] == 0) {
+		selected = m.epsilonSearch(selected, birth, children)
+	}
+	m.label(tree, n, selected)
+}
+
+// epsilonSearch replaces the selected clusters born at a distance below ClusterSelectionEpsilon by their ancestor
+// born at a greater distance
+func (m *HDBSCAN) epsilonSearch(selected []int, birth []float64, children [][]int) []int {
+	parentOf := make([]int, len(children))
+	for c, cs := range children {
+		for _, child := range cs {
+			parentOf[child] = c
+		}
+	}
+	var result []int
+	seen := map[int]bool{}
+	processed := map[int]bool{}
+	for _, c := range selected {
+		if c == 0 || 1/birth[c] >= m.ClusterSelectionEpsilon {
+			if !seen[c] {
+				seen[c] = true
+				result = append(result, c)
+			}
+			continue
+		}
+		if processed[c] {
+			continue
+		}
+		ancestor := c
+		for {
+			parent := parentOf[ancestor]
+			if parent == 0 {
+				if m.AllowSingleCluster {
+					ancestor = 0
+				}
+				break
+			}
+			ancestor = parent
+			if 1/birth[parent] > m.ClusterSelectionEpsilon {
+				break
+			}
+		}
+		if !seen[ancestor] {
+			seen[ancestor] = true
+			result = append(result, ancestor)
+		}
+		var mark func(int)
+		mark = func(d int) {
+			for _, child := range children[d] {
+				processed[child] = true
+				mark(child)
+			}
+		}
+		mark(ancestor)
+	}
+	sort.Ints(result)
+	return result
+}
+
+// label sets Labels, the selected cluster containing each sample, and Probabilities, the lambda at which a sample leaves
+// its cluster relative to the greatest lambda of the cluster
+func (m *HDBSCAN) label(tree []condensedRow, n int, selected []int) {
+	labelOf := map[int]int{}
+	for label, c := range selected {
+		labelOf[n+c] = label
+	}
+	// the cluster of a node is its nearest selected ancestor. rows are ordered from the root
+	clusterOf := map[int]int{}
+	for _, row := range tree {
+		cluster, ok := clusterOf[row.parent]
+		if !ok {
+			cluster = row.parent
+		}
+		if _, isSelected := labelOf[row.child]; !isSelected {
+			clusterOf[row.child] = cluster
+		}
+	}
+	maxLambda := map[int]float64{}
+	for _, row := range tree {
+		if row.lambda > maxLambda[row.parent] {
+			maxLambda[row.parent] = row.lambda
+		}
+	}
+	root := n
+	m.Labels, m.Probabilities = make([]int, n), make([]float64, n)
+	for _, row := range tree {
+		if row.child >= n {
+			continue
+		}
+		i, cluster := row.child, clusterOf[row.child]
+		label, ok := labelOf[cluster]
+		if cluster == root && ok {
+			// the single cluster keeps the samples not leaving the root before its last split, or before ClusterSelectionEpsilon
+			threshold := maxLambda[root]
+			if m.ClusterSelectionEpsilon > 0 {
+				threshold = 1 / m.ClusterSelectionEpsilon
+			}
+			ok = row.lambda >= threshold
+		}
+		if !ok {
+			m.Labels[i] = -1
+			continue
+		}
+		m.Labels[i] = label
+		if lambdaMax := maxLambda[cluster]; lambdaMax == 0 || math.IsInf(row.lambda, 1) {
+			m.Probabilities[i] = 1
+		} else {
+			m.Probabilities[i] = math.Min(row.lambda, lambdaMax) / lambdaMax
+		}
+	}
+}
+
+// outlierScores returns the GLOSH scores of samples: one minus the ratio of the lambda at which a sample leaves its
+// cluster to the greatest lambda of the samples of the cluster and of its descendants
+func outlierScores(tree []condensedRow, n int) []float64 {
+	deaths := map[int]float64{}
+	for _, row := range tree {
+		if row.lambda > deaths[row.parent] {
+			deaths[row.parent] = row.lambda
+		}
+	}
+	// rows are in breadth first order, from the root
+	for k := len(tree) - 1; k >= 0; k-- {
+		if row := tree[k]; row.child >= n && deaths[row.child] > deaths[row.parent] {
+			deaths[row.parent] = deaths[row.child]
+		}
+	}
+	scores := make([]float64, n)
+	for _, row := range tree {
+		if row.child >= n {
+			continue
+		}
+		if lambdaMax := deaths[row.parent]; lambdaMax != 0 && !math.IsInf(row.lambda, 1) {
+			scores[row.child] = (lambdaMax - row.lambda) / lambdaMax
+		}
+	}
+	return scores
+}
+
+// GetNOutputs returns output columns number for Y to pass to predict
+func (m *HDBSCAN) GetNOutputs() int { return 1 }
+
+// Predict for HDBSCAN returns Labels in Y. X must be the one passed to Fit
+func (m *HDBSCAN) Predict(X mat.Matrix, Ymutable mat.Mutable) *mat.Dense {
+	base.MustBeFitted(m)
+	return labelsTo(Ymutable, X, m.Labels)
+}
+
+// PredictE is Predict returning an error instead of panicking
+func (m *HDBSCAN) PredictE(X mat.Matrix, Y mat.Mutable) (*mat.Dense, error) {
+	if !m.IsFitted() {
+		return nil, base.ErrNotFitted
+	}
+	return base.PredictE(m, X, Y)
+}
+
+// Score for HDBSCAN returns 1
+func (m *HDBSCAN) Score(X, Y mat.Matrix) float64 { return 1 }
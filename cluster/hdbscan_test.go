@@ -0,0 +1,143 @@
+package cluster
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"testing"
+
+	"github.com/pa-m/sklearn/base"
+	"github.com/pa-m/sklearn/datasets"
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mat"
+)
+
+func ExampleHDBSCAN() {
+	// 2 dense blobs close to each other, a sparse blob and noise
+	X, _ := densityBlobs()
+	m := NewHDBSCAN()
+	m.MinClusterSize = 10
+	m.Fit(X, nil)
+	fmt.Println(m.Labels[0], m.Labels[100], m.Labels[200])
+	// a greater ClusterSelectionEpsilon merges the dense blobs
+	m.ClusterSelectionEpsilon = 2
+	m.Fit(X, nil)
+	fmt.Println(m.Labels[0], m.Labels[100], m.Labels[200])
+	// Output:
+	// 1 2 0
+	// 0 0 1
+}
+
+func TestHDBSCAN(t *testing.T) {
+	X, _ := densityBlobs()
+	for _, method := range []string{"eom", "leaf"} {
+		m := NewHDBSCAN()
+		m.MinClusterSize, m.ClusterSelectionMethod = 20, method
+		m.Fit(X, nil)
+		if err := checkBlobs(m.Labels); err != nil {
+			t.Errorf("%s: %s", method, err)
+		}
+		var noise int
+		var blobScore, noiseScore float64
+		for i, label := range m.Labels {
+			if p := m.Probabilities[i]; p < 0 || p > 1 || (p == 0) != (label < 0) {
+				t.Fatalf("%s: expected a probability in (0, 1] for a clustered sample, 0 for noise, got %g for label %d", method, p, label)
+			}
+			if s := m.OutlierScores[i]; s < 0 || s > 1 {
+				t.Fatalf("%s: expected an outlier score in [0, 1], got %g", method, s)
+			}
+			if i < 300 {
+				blobScore += m.OutlierScores[i] / 300
+			} else {
+				noiseScore += m.OutlierScores[i] / 20
+				if label < 0 {
+					noise++
+				}
+			}
+		}
+		if noise < 10 || noiseScore < 2*blobScore {
+			t.Errorf("%s: expected most noise samples labelled -1 and higher outlier scores, got %d and %g vs %g", method, noise, noiseScore, blobScore)
+		}
+	}
+}
+
+func TestHDBSCAN_SingleLinkage(t *testing.T) {
+	// with MinSamples 1, the mutual reachability distance is the distance and the tree the single linkage tree
+	X := datasets.LoadIris().X
+	n, _ := X.Dims()
+	m := NewHDBSCAN()
+	tree := singleLinkage(m.mutualReachabilityMST(X, make([]float64, n), func(i, j int) float64 {
+		return EuclideanDistance(X.RowView(i), X.RowView(j))
+	}), n)
+	ac := NewAgglomerativeClustering(1)
+	ac.Linkage = "single"
+	ac.Fit(X, nil)
+	Z := ac.LinkageMatrix()
+	for step, row := range tree {
+		if math.Abs(row.distance-Z.At(step, 2)) > 1e-12 {
+			t.Fatalf("step %d: expected distance %g, got %g", step, Z.At(step, 2), row.distance)
+		}
+	}
+	if tree[n-2].size != n {
+		t.Errorf("expected a root of %d samples, got %d", n, tree[n-2].size)
+	}
+	// each sample leaves the condensed tree once, and clusters hold at least MinClusterSize samples
+	condensed := condenseTree(tree, 10)
+	var samples []int
+	for _, row := range condensed {
+		if row.child < n {
+			samples = append(samples, row.child)
+		} else if row.size < 10 {
+			t.Errorf("expected clusters of at least 10 samples, got %d", row.size)
+		}
+	}
+	sort.Ints(samples)
+	if len(samples) != n || samples[0] != 0 || samples[n-1] != n-1 {
+		t.Errorf("expected each sample once in the condensed tree, got %d samples", len(samples))
+	}
+}
+
+func TestHDBSCAN_SingleCluster(t *testing.T) {
+	X, _ := densityBlobs()
+	blob := X.Slice(200, 300, 0, 2)
+	m := NewHDBSCAN()
+	m.Fit(blob, nil)
+	if top := floats.Max(intsToFloats(m.Labels)); top < 1 {
+		t.Errorf("expected the blob split in clusters, got %v", m.Labels)
+	}
+	m.AllowSingleCluster = true
+	m.Fit(blob, nil)
+	if top := floats.Max(intsToFloats(m.Labels)); top != 0 {
+		t.Errorf("expected a single cluster, got %v", m.Labels)
+	}
+	// no cluster is small enough
+	m.MaxClusterSize = 2
+	m.Fit(blob, nil)
+	if top := floats.Max(intsToFloats(m.Labels)); top != -1 {
+		t.Errorf("expected only noise, got %v", m.Labels)
+	}
+}
+
+func TestHDBSCAN_Errors(t *testing.T) {
+	X := datasets.LoadIris().X
+	for _, m := range []*HDBSCAN{
+		{MinClusterSize: 1},
+		{MinSamples: -1},
+		{Alpha: -1},
+		{ClusterSelectionMethod: "excess"},
+		{MinSamples: 200},
+	} {
+		if err := m.FitE(X, nil); !errors.Is(err, base.ErrInvalidParam) {
+			t.Errorf("%+v: expected ErrInvalidParam, got %v", m, err)
+		}
+	}
+	m := NewHDBSCAN()
+	if _, err := m.PredictE(X, nil); !errors.Is(err, base.ErrNotFitted) {
+		t.Errorf("expected ErrNotFitted, got %v", err)
+	}
+	m.Fit(X, nil)
+	if _, err := m.PredictE(mat.NewDense(3, 4, nil), nil); !errors.Is(err, base.ErrShapeMismatch) {
+		t.Errorf("expected ErrShapeMismatch for other samples than the fitted ones, got %v", err)
+	}
+}
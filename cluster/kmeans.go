@@ -1,158 +1,435 @@
 package cluster
 
 import (
+	"context"
 	"fmt"
+	"math"
 	"runtime"
-	"sync"
+	"sort"
+	"time"
+
+	"golang.org/x/exp/rand"
 
 	"github.com/pa-m/sklearn/base"
 
+	"gonum.org/v1/gonum/floats"
 	"gonum.org/v1/gonum/mat"
 )
 
-// KMeans grouping algo
+// KMeans grouping algo. NInit seedings of the centroids are refined by the Lloyd or Elkan algorithm in parallel, keeping the one
+// with the lowest inertia. a seeding stops when labels are stable, when the centroids move by less than Tol or after MaxIter iterations
 type KMeans struct {
 	// Required members
 	NClusters int
 	// Optional members
-	NJobs    int
-	Distance func(X, Y mat.Vector) float64
+	// Init is the seeding of the centroids: "k-means++" (default) or "random" samples
+	Init string
+	// Algorithm is "lloyd" (default) or "elkan", which skips the distances ruled out by the triangle inequality.
+	// elkan is faster for well separated clusters in few dimensions, but stores NClusters bounds per sample
+	Algorithm string
+	// NInit is the number of seedings, default 1 for k-means++ and 10 for random
+	NInit int
+	// MaxIter is the maximum number of iterations of a seeding, default 300
+	MaxIter int
+	// Tol is the tolerance on the sum of squared moves of the centroids, relative to the mean variance of the features
+	Tol         float64
+	RandomState base.RandomState
+	NJobs       int
+	Distance    func(X, Y mat.Vector) float64 `persist:"-"`
 	// Runtime filled members
-	Centroids *mat.Dense
+	Centroids *mat.Dense `param:"-"`
+	// Labels is the index of the centroid of each training sample
+	Labels []int `param:"-"`
+	// Inertia is the weighted sum of squared distances of training samples to their centroid
+	Inertia float64 `param:"-"`
+	// NIter is the number of iterations of the kept seeding
+	NIter int `param:"-"`
+}
+
+// NewKMeans returns a KMeans with k-means++ seeding, the lloyd algorithm, MaxIter 300 and Tol 1e-4
+func NewKMeans(nClusters int) *KMeans {
+	return &KMeans{NClusters: nClusters, Init: "k-means++", Algorithm: "lloyd", MaxIter: 300, Tol: 1e-4}
 }
 
 // PredicterClone for KMeans
 func (m *KMeans) PredicterClone() base.Predicter {
 	clone := *m
-	return &clone
+	clone.Centroids, clone.Labels = nil, nil
+	return base.DeepCopy(&clone).(*KMeans)
 }
 
+// IsFitted returns true when Centroids have been computed. see base.FittedChecker
+func (m *KMeans) IsFitted() bool { return m.Centroids != nil }
+
 // IsClassifier returns true for KMeans
 func (m *KMeans) IsClassifier() bool { return true }
 
-// Nearest returns index of the nearest centroid
-func (m *KMeans) _nearest(scaledRow mat.Vector) int {
-	best := -1
-	var bestDist float64
+// nearest returns the index of the nearest centroid and its distance
+func (m *KMeans) nearest(centroids *mat.Dense, row mat.Vector) (best int, bestDist float64) {
+	best = -1
 	for ic := 0; ic < m.NClusters; ic++ {
-		d := m.Distance(scaledRow, m.Centroids.RowView(ic))
+		d := m.Distance(row, centroids.RowView(ic))
 		if best < 0 || d < bestDist {
 			best = ic
 			bestDist = d
 		}
 	}
-	return best
+	return
 }
 
 // Fit compute centroids
 // Y is useless here but we want all classifiers have the same interface. pass nil
 func (m *KMeans) Fit(Xmatrix, Ymatrix mat.Matrix) base.Fiter {
-	X := (Xmatrix)
-	NSamples, NFeatures := X.Dims()
-	if NSamples < m.NClusters {
-		panic(fmt.Errorf("NSamples<m.NClusters %d<%d", NSamples, m.NClusters))
+	return m.FitWeighted(Xmatrix, Ymatrix, nil)
+}
+
+// FitWeighted compute centroids as the weighted means of their samples
+func (m *KMeans) FitWeighted(Xmatrix, Ymatrix mat.Matrix, sampleWeight []float64) base.Fiter {
+	if err := m.checkParams(); err != nil {
+		panic(err)
 	}
-	if m.Distance == nil {
-		m.Distance = EuclideanDistance
+	if err := m.fit(Xmatrix, sampleWeight, nil); err != nil {
+		panic(err)
+	}
+	return m
+}
 
+// FitContext is FitE stopping when ctx is done. progress is reported to the base.ProgressFunc of ctx after each iteration
+// of each seeding, with its inertia as loss, or an upper bound of it for elkan
+func (m *KMeans) FitContext(ctx context.Context, X, Y mat.Matrix) (err error) {
+	if err = m.checkParams(); err != nil {
+		return
+	}
+	if err = base.CheckXY(X, Y); err != nil {
+		return
 	}
+	defer base.Recover(&err)
+	return m.fit(X, nil, base.NewMonitor(ctx))
+}
 
-	m.Centroids = mat.NewDense(m.NClusters, NFeatures, nil)
-	row := make([]float64, NFeatures)
-	for ic := 0; ic < m.NClusters; ic++ {
-		mat.Row(row, ic, X)
-		m.Centroids.SetRow(ic, row)
+func (m *KMeans) checkParams() error {
+	if m.NClusters <= 0 {
+		return fmt.Errorf("%w: NClusters must be positive, got %d", base.ErrInvalidParam, m.NClusters)
+	}
+	if m.Init != "" && m.Init != "k-means++" && m.Init != "random" {
+		return fmt.Errorf("%w: Init must be k-means++ or random, got %q", base.ErrInvalidParam, m.Init)
+	}
+	if m.Algorithm != "" && m.Algorithm != "lloyd" && m.Algorithm != "elkan" {
+		return fmt.Errorf("%w: Algorithm must be lloyd or elkan, got %q", base.ErrInvalidParam, m.Algorithm)
+	}
+	if m.NInit < 0 || m.MaxIter < 0 || m.Tol < 0 {
+		return fmt.Errorf("%w: NInit, MaxIter and Tol must be >= 0", base.ErrInvalidParam)
 	}
-	NearestCentroid := make([]int, NSamples)
-	CentroidCount := make([]int, m.NClusters)
-	epoch := 0
-	changed := true
-	unchangeCount := 0
+	return nil
+}
+
+// kmeansRun is the outcome of a seeding
+type kmeansRun struct {
+	centroids *mat.Dense
+	labels    []int
+	inertia   float64
+	nIter     int
+	err       error
+}
 
+func (m *KMeans) fit(X mat.Matrix, sampleWeight []float64, mon *base.Monitor) error {
+	NSamples, _ := X.Dims()
+	if NSamples < m.NClusters {
+		panic(fmt.Errorf("%w: NSamples<m.NClusters %d<%d", base.ErrInvalidParam, NSamples, m.NClusters))
+	}
+	if err := base.CheckSampleWeight(X, sampleWeight); err != nil {
+		return err
+	}
+	m.setDefaults()
+	nInit := m.NInit
+	if nInit == 0 {
+		nInit = 1
+		if m.Init == "random" {
+			nInit = 10
+		}
+	}
+	maxIter := m.MaxIter
+	if maxIter == 0 {
+		maxIter = 300
+	}
+	src := m.randomState()
+	sources := make([]base.RandomState, nInit)
+	for r := range sources {
+		sources[r] = base.NewSource(src.Uint64())
+	}
+	// seedings share the NJobs threads
+	threads := m.NJobs / nInit
+	if threads < 1 {
+		threads = 1
+	}
+	tol := m.Tol * meanVariance(X)
+	runs := make([]kmeansRun, nInit)
+	base.Parallelize(m.NJobs, nInit, func(th, start, end int) {
+		for r := start; r < end; r++ {
+			runs[r] = m.run(X, sampleWeight, rand.New(sources[r]), maxIter, tol, threads, mon)
+		}
+	})
+	best := -1
+	for r, run := range runs {
+		if run.err != nil {
+			return run.err
+		}
+		if best < 0 || run.inertia < runs[best].inertia {
+			best = r
+		}
+	}
+	m.Centroids, m.Labels, m.Inertia, m.NIter = runs[best].centroids, runs[best].labels, runs[best].inertia, runs[best].nIter
+	return nil
+}
+
+// setDefaults sets Distance to EuclideanDistance and NJobs to runtime.NumCPU() if unset
+func (m *KMeans) setDefaults() {
+	if m.Distance == nil {
+		m.Distance = EuclideanDistance
+	}
 	if m.NJobs <= 0 {
 		m.NJobs = runtime.NumCPU()
 	}
+}
 
-	for unchangeCount < 2 {
-		epoch++
-		changed = false
-		// find nearest centroids
-		m.predict(X, NearestCentroid, CentroidCount, &changed)
-		// recompute centroids
-		m.Centroids.Sub(m.Centroids, m.Centroids)
-		var mu sync.Mutex // mu locks m.Centroids modifications
-		base.Parallelize(m.NJobs, NSamples, func(th, start, end int) {
-			row := make([]float64, NFeatures)
-			for sample := start; sample < end; sample++ {
-				ic := NearestCentroid[sample]
-				mu.Lock()
-				c := m.Centroids.RowView(ic)
-				mat.Row(row, sample, X)
-				c.(*mat.VecDense).AddScaledVec(c, 1./float64(CentroidCount[ic]), mat.NewVecDense(NFeatures, row))
-				mu.Unlock()
-			}
-		})
-		if changed {
-			unchangeCount = 0
+// randomState returns RandomState, or a time seeded source if it is nil
+func (m *KMeans) randomState() base.RandomState {
+	if m.RandomState == nil {
+		return base.NewLockedSource(uint64(time.Now().UnixNano()))
+	}
+	return m.RandomState
+}
+
+// run seeds centroids and alternates the assignment of samples to their nearest centroid, by the Lloyd or Elkan algorithm,
+// and the computation of centroids as the weighted means of their samples
+func (m *KMeans) run(X mat.Matrix, sampleWeight []float64, rnd *rand.Rand, maxIter int, tol float64, threads int, mon *base.Monitor) (run kmeansRun) {
+	NSamples, _ := X.Dims()
+	if m.Init == "random" {
+		run.centroids = m.randomInit(X, rnd)
+	} else {
+		run.centroids = m.kmeansPlusPlus(X, sampleWeight, rnd)
+	}
+	run.labels = make([]int, NSamples)
+	// dist is the distance of each sample to its centroid, or an upper bound of it for elkan
+	dist := make([]float64, NSamples)
+	var e *elkan
+	if m.Algorithm == "elkan" {
+		e = newElkan(m, X, run.centroids, run.labels, dist, threads)
+	} else {
+		m.assign(X, run.centroids, run.labels, dist, threads)
+	}
+	for run.nIter < maxIter {
+		run.nIter++
+		if e != nil {
+			e.previous.Copy(run.centroids)
+		}
+		shift := m.updateCentroids(X, sampleWeight, run.centroids, run.labels, dist, threads)
+		var changed int
+		if e != nil {
+			changed = e.assign()
 		} else {
-			unchangeCount++
+			changed = m.assign(X, run.centroids, run.labels, dist, threads)
+		}
+		run.inertia = weightedSumOfSquares(dist, sampleWeight)
+		if run.err = mon.Report(run.nIter, run.inertia); run.err != nil {
+			return
+		}
+		if changed == 0 || shift <= tol {
+			break
 		}
 	}
-	return m
+	if e != nil {
+		e.tighten()
+		run.inertia = weightedSumOfSquares(dist, sampleWeight)
+	}
+	return
 }
 
-// GetNOutputs returns output columns number for Y to pass to predict
-func (m *KMeans) GetNOutputs() int { return 1 }
+// weightedSumOfSquares returns the sum of the squares of dist weighted by sampleWeight
+func weightedSumOfSquares(dist, sampleWeight []float64) float64 {
+	sum := 0.
+	for sample, d := range dist {
+		sum += sampleWeightAt(sampleWeight, sample) * d * d
+	}
+	return sum
+}
 
-func (m *KMeans) predict(Xscaled mat.Matrix, y, CentroidCount []int, changed *bool) {
-	NSamples, NFeatures := Xscaled.Dims()
-	if y == nil {
-		y = make([]int, NSamples)
+// randomInit returns NClusters distinct samples drawn at random
+func (m *KMeans) randomInit(X mat.Matrix, rnd *rand.Rand) *mat.Dense {
+	NSamples, NFeatures := X.Dims()
+	centroids := mat.NewDense(m.NClusters, NFeatures, nil)
+	for ic, sample := range rnd.Perm(NSamples)[:m.NClusters] {
+		matRow(centroids.RawRowView(ic), sample, X)
 	}
-	for ic := range CentroidCount {
-		CentroidCount[ic] = 0
+	return centroids
+}
+
+// kmeansPlusPlus returns centroids seeded by k-means++: the first centroid is a sample drawn with a probability proportional
+// to its weight, each next one is the best of 2+log(NClusters) samples drawn with a probability proportional to their weighted
+// squared distance to the nearest centroid, the best candidate being the one minimizing the sum of these squared distances
+func (m *KMeans) kmeansPlusPlus(X mat.Matrix, sampleWeight []float64, rnd *rand.Rand) *mat.Dense {
+	NSamples, NFeatures := X.Dims()
+	centroids := mat.NewDense(m.NClusters, NFeatures, nil)
+	nLocalTrials := 2 + int(math.Log(float64(m.NClusters)))
+	buf, candidate := mat.NewVecDense(NFeatures, nil), mat.NewVecDense(NFeatures, nil)
+	// closest is the weighted squared distance of each sample to its nearest centroid, cumulated in cum for sampling
+	closest, cum := make([]float64, NSamples), make([]float64, NSamples)
+	draw := func(p []float64) int {
+		sum := 0.
+		for sample, v := range p {
+			sum += v
+			cum[sample] = sum
+		}
+		if sum <= 0 {
+			return rnd.Intn(NSamples)
+		}
+		x := rnd.Float64() * sum
+		sample := sort.Search(NSamples, func(i int) bool { return cum[i] > x })
+		if sample == NSamples {
+			sample--
+		}
+		return sample
 	}
-	var m1, m2 sync.Mutex
-	base.Parallelize(runtime.NumCPU(), NSamples, func(th, start, end int) {
-		var row mat.Vector
-		var Xrv mat.RowViewer
-		var isrv bool
-		if Xrv, isrv = Xscaled.(mat.RowViewer); !isrv {
-			row = mat.NewVecDense(NFeatures, nil)
+	weights := make([]float64, NSamples)
+	for sample := range weights {
+		weights[sample] = sampleWeightAt(sampleWeight, sample)
+	}
+	matRow(centroids.RawRowView(0), draw(weights), X)
+	for sample := range closest {
+		d := m.Distance(rowVector(buf, sample, X), centroids.RowView(0))
+		closest[sample] = weights[sample] * d * d
+	}
+	next, bestNext := make([]float64, NSamples), make([]float64, NSamples)
+	for ic := 1; ic < m.NClusters; ic++ {
+		bestPotential, best := math.Inf(1), 0
+		for trial := 0; trial < nLocalTrials; trial++ {
+			c := draw(closest)
+			matRow(candidate.RawVector().Data, c, X)
+			potential := 0.
+			for sample := range next {
+				d := m.Distance(rowVector(buf, sample, X), candidate)
+				next[sample] = math.Min(closest[sample], weights[sample]*d*d)
+				potential += next[sample]
+			}
+			if potential < bestPotential {
+				bestPotential, best = potential, c
+				next, bestNext = bestNext, next
+			}
+		}
+		matRow(centroids.RawRowView(ic), best, X)
+		closest, bestNext = bestNext, closest
+	}
+	return centroids
+}
+
+// updateCentroids moves centroids to the weighted means of their samples and returns the sum of their squared moves.
+// an empty centroid is first moved to the sample farthest from its centroid, whose label is changed
+func (m *KMeans) updateCentroids(X mat.Matrix, sampleWeight []float64, centroids *mat.Dense, labels []int, dist []float64, threads int) float64 {
+	NSamples, NFeatures := X.Dims()
+	CentroidWeight := make([]float64, m.NClusters)
+	for sample, ic := range labels {
+		CentroidWeight[ic] += sampleWeightAt(sampleWeight, sample)
+	}
+	for ic, w := range CentroidWeight {
+		if w > 0 {
+			continue
+		}
+		far := -1
+		for sample, d := range dist {
+			// the centroid of the sample must not become empty
+			if ws := sampleWeightAt(sampleWeight, sample); ws > 0 && CentroidWeight[labels[sample]] > ws && (far < 0 || d > dist[far]) {
+				far = sample
+			}
+		}
+		if far < 0 {
+			continue
 		}
+		w = sampleWeightAt(sampleWeight, far)
+		CentroidWeight[labels[far]] -= w
+		CentroidWeight[ic] = w
+		labels[far], dist[far] = ic, 0
+	}
+	// each thread sums the weighted samples of each centroid
+	sums := make([][]float64, threads)
+	base.Parallelize(threads, NSamples, func(th, start, end int) {
+		sum := make([]float64, m.NClusters*NFeatures)
+		row := make([]float64, NFeatures)
 		for sample := start; sample < end; sample++ {
-			if isrv {
-				row = Xrv.RowView(sample)
-			} else {
-				mat.Row(row.(mat.RawVectorer).RawVector().Data, sample, Xscaled)
+			w := sampleWeightAt(sampleWeight, sample)
+			if w == 0 {
+				continue
+			}
+			matRow(row, sample, X)
+			ic := labels[sample]
+			floats.AddScaled(sum[ic*NFeatures:(ic+1)*NFeatures], w, row)
+		}
+		sums[th] = sum
+	})
+	shift := 0.
+	for ic, w := range CentroidWeight {
+		if w == 0 {
+			continue
+		}
+		c := centroids.RawRowView(ic)
+		previous := append([]float64(nil), c...)
+		for j := range c {
+			c[j] = 0
+		}
+		for _, sum := range sums {
+			if sum != nil {
+				floats.Add(c, sum[ic*NFeatures:(ic+1)*NFeatures])
 			}
-			nearest := m._nearest(row)
-			if nearest != y[sample] {
-				y[sample] = nearest
-				if changed != nil {
-					m1.Lock()
-					*changed = true
-					m1.Unlock()
-				}
+		}
+		floats.Scale(1/w, c)
+		floats.Sub(previous, c)
+		shift += floats.Dot(previous, previous)
+	}
+	return shift
+}
+
+// FitE is Fit returning an error instead of panicking
+func (m *KMeans) FitE(X, Y mat.Matrix) error {
+	return m.FitContext(context.Background(), X, Y)
+}
+
+// GetNOutputs returns output columns number for Y to pass to predict
+func (m *KMeans) GetNOutputs() int { return 1 }
+
+// assign sets labels to the index of the nearest centroid of each sample and dist to its distance if not nil, using threads
+// goroutines. it returns the number of changed labels
+func (m *KMeans) assign(Xscaled mat.Matrix, centroids *mat.Dense, labels []int, dist []float64, threads int) int {
+	NSamples, NFeatures := Xscaled.Dims()
+	changed := make([]int, threads)
+	base.Parallelize(threads, NSamples, func(th, start, end int) {
+		buf := mat.NewVecDense(NFeatures, nil)
+		for sample := start; sample < end; sample++ {
+			nearest, d := m.nearest(centroids, rowVector(buf, sample, Xscaled))
+			if nearest != labels[sample] {
+				labels[sample] = nearest
+				changed[th]++
 			}
-			if CentroidCount != nil {
-				m2.Lock()
-				CentroidCount[nearest]++
-				m2.Unlock()
+			if dist != nil {
+				dist[sample] = d
 			}
 		}
 	})
+	n := 0
+	for _, c := range changed {
+		n += c
+	}
+	return n
 }
 
 // Predict fills y with indices of centroids
 func (m *KMeans) Predict(X mat.Matrix, Ymutable mat.Mutable) *mat.Dense {
+	base.MustBeFitted(m)
 	Y := base.ToDense(Ymutable)
 	nSamples, _ := X.Dims()
 	if Y.IsZero() {
 		*Y = *mat.NewDense(nSamples, m.GetNOutputs(), nil)
 	}
 	y := make([]int, nSamples)
-	m.predict(X, y, nil, nil)
+	m.assign(X, m.Centroids, y, nil, runtime.NumCPU())
 	for i, y1 := range y {
 		Y.Set(i, 0, float64(y1))
 	}
@@ -160,5 +437,85 @@ func (m *KMeans) Predict(X mat.Matrix, Ymutable mat.Mutable) *mat.Dense {
 	return Y
 }
 
+// DecisionFunction for KMeans returns the opposite of the squared distance of samples to each centroid
+func (m *KMeans) DecisionFunction(X mat.Matrix, Ymutable mat.Mutable) *mat.Dense {
+	base.MustBeFitted(m)
+	NSamples, NFeatures := X.Dims()
+	D := mat.NewDense(NSamples, m.NClusters, nil)
+	base.Parallelize(runtime.NumCPU(), NSamples, func(th, start, end int) {
+		row := mat.NewVecDense(NFeatures, nil)
+		for sample := start; sample < end; sample++ {
+			matRow(row.RawVector().Data, sample, X)
+			for ic := 0; ic < m.NClusters; ic++ {
+				d := m.Distance(row, m.Centroids.RowView(ic))
+				D.Set(sample, ic, -d*d)
+			}
+		}
+	})
+	return base.FromDense(Ymutable, D)
+}
+
+// PredictProba for KMeans is a soft assignment to clusters: the softmax of DecisionFunction. see base.ProbaPredicter
+func (m *KMeans) PredictProba(X mat.Matrix, Ymutable mat.Mutable) *mat.Dense {
+	P := m.DecisionFunction(X, nil)
+	base.SoftmaxRows(P)
+	return base.FromDense(Ymutable, P)
+}
+
+// PredictE is Predict returning an error instead of panicking
+func (m *KMeans) PredictE(X mat.Matrix, Y mat.Mutable) (*mat.Dense, error) {
+	if !m.IsFitted() {
+		return nil, base.ErrNotFitted
+	}
+	if err := base.CheckNFeatures(X, m.Centroids.RawMatrix().Cols); err != nil {
+		return nil, err
+	}
+	return base.PredictE(m, X, Y)
+}
+
 // Score for KMeans returns 1
 func (m *KMeans) Score(X, Y mat.Matrix) float64 { return 1 }
+
+// matRow copies row i of X to dst, without converting the whole of a base.General32 X
+func matRow(dst []float64, i int, X mat.Matrix) []float64 {
+	if X32, ok := X.(base.General32); ok {
+		return X32.Row(dst, i)
+	}
+	return mat.Row(dst, i, X)
+}
+
+// rowVector returns row i of X, as a view if X is a mat.RowViewer or copied to buf otherwise
+func rowVector(buf *mat.VecDense, i int, X mat.Matrix) mat.Vector {
+	if Xrv, ok := X.(mat.RowViewer); ok {
+		return Xrv.RowView(i)
+	}
+	matRow(buf.RawVector().Data, i, X)
+	return buf
+}
+
+// meanVariance returns the mean of the variances of the columns of X
+func meanVariance(X mat.Matrix) float64 {
+	NSamples, NFeatures := X.Dims()
+	sum, sum2 := make([]float64, NFeatures), make([]float64, NFeatures)
+	row := make([]float64, NFeatures)
+	for sample := 0; sample < NSamples; sample++ {
+		matRow(row, sample, X)
+		for j, x := range row {
+			sum[j] += x
+			sum2[j] += x * x
+		}
+	}
+	variance := 0.
+	for j := range sum {
+		mean := sum[j] / float64(NSamples)
+		variance += sum2[j]/float64(NSamples) - mean*mean
+	}
+	return variance / float64(NFeatures)
+}
+
+func sampleWeightAt(sampleWeight []float64, i int) float64 {
+	if sampleWeight == nil {
+		return 1
+	}
+	return sampleWeight[i]
+}
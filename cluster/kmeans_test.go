@@ -1,14 +1,21 @@
 package cluster
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"image/color"
+	"math"
 	"os"
 	"os/exec"
+	"reflect"
+	"sync/atomic"
+	"testing"
 	"time"
 
 	"github.com/pa-m/sklearn/base"
 	"github.com/pa-m/sklearn/datasets"
+	"gonum.org/v1/gonum/floats"
 	"gonum.org/v1/gonum/mat"
 	"gonum.org/v1/plot"
 	"gonum.org/v1/plot/plotter"
@@ -17,7 +24,11 @@ import (
 )
 
 var (
-	_ base.Predicter = &KMeans{}
+	_ base.Predicter  = &KMeans{}
+	_ base.PredicterE = &KMeans{}
+
+	_ base.ProbaPredicter     = &KMeans{}
+	_ base.DecisionFunctioner = &KMeans{}
 )
 
 func ExampleKMeans() {
@@ -97,3 +108,206 @@ func ExampleKMeans() {
 	}
 	// Output:
 }
+
+func TestKMeans_FitE(t *testing.T) {
+	X := mat.NewDense(2, 2, []float64{0, 0, 1, 1})
+	m := &KMeans{NClusters: 3}
+	if _, err := m.PredictE(X, nil); !errors.Is(err, base.ErrNotFitted) {
+		t.Errorf("expected ErrNotFitted, got %v", err)
+	}
+	if err := m.FitE(X, nil); !errors.Is(err, base.ErrInvalidParam) {
+		t.Errorf("expected ErrInvalidParam, got %v", err)
+	}
+	m.NClusters = 2
+	if err := m.FitE(X, nil); err != nil {
+		t.Error(err)
+	}
+	if _, err := m.PredictE(mat.NewDense(2, 3, nil), nil); !errors.Is(err, base.ErrShapeMismatch) {
+		t.Errorf("expected ErrShapeMismatch, got %v", err)
+	}
+}
+
+func TestKMeans_PredicterClone(t *testing.T) {
+	X := mat.NewDense(4, 2, []float64{0, 0, 0, 1, 5, 5, 5, 6})
+	m := &KMeans{NClusters: 2}
+	m.Fit(X, nil)
+	if unfitted := m.PredicterClone().(*KMeans); unfitted.Centroids != nil {
+		t.Error("PredicterClone must return an unfitted clone")
+	}
+	fitted := base.FittedClone(m).(*KMeans)
+	m.Centroids.Set(0, 0, 100)
+	if fitted.Centroids.At(0, 0) == 100 {
+		t.Error("fitted clone shares Centroids")
+	}
+}
+
+func TestKMeans_GetParams(t *testing.T) {
+	X := mat.NewDense(4, 2, []float64{0, 0, 0, 1, 5, 5, 5, 6})
+	m := &KMeans{NClusters: 2}
+	m.Fit(X, nil)
+	params := base.GetParams(m)
+	for _, name := range []string{"Centroids", "Labels", "Inertia", "NIter"} {
+		if _, ok := params[name]; ok {
+			t.Errorf("%s is not a parameter", name)
+		}
+	}
+	if m.RandomState != nil {
+		t.Error("Fit must not set RandomState")
+	}
+	if err := base.SetParams(m, map[string]interface{}{"inertia": 0}); !errors.Is(err, base.ErrInvalidParam) {
+		t.Errorf("expected ErrInvalidParam, got %v", err)
+	}
+}
+
+func TestKMeans_FitWeighted(t *testing.T) {
+	X := mat.NewDense(5, 2, []float64{0, 0, 5, 5, 0, 1, 0, 3, 5, 6})
+	m := &KMeans{NClusters: 2}
+	if err := base.FitWeighted(m, X, nil, []float64{1, 1, 2, 1, 3}); err != nil {
+		t.Fatal(err)
+	}
+	for sample, expected := range map[int][]float64{0: {0, 1.25}, 1: {5, 5.75}} {
+		if c := m.Centroids.RawRowView(m.Labels[sample]); !floats.EqualApprox(c, expected, 1e-12) {
+			t.Errorf("expected weighted centroid %g, got %g", expected, c)
+		}
+	}
+}
+
+func TestKMeans_FitContext(t *testing.T) {
+	X := mat.NewDense(4, 2, []float64{0, 0, 0, 1, 5, 5, 5, 6})
+	m := &KMeans{NClusters: 2}
+	errStop := errors.New("stop")
+	epochs := 0
+	ctx := base.WithProgress(context.Background(), func(p base.Progress) error {
+		epochs = p.Epoch
+		return errStop
+	})
+	if err := m.FitContext(ctx, X, nil); !errors.Is(err, errStop) || epochs != 1 {
+		t.Errorf("expected errStop after 1 epoch, got %v after %d", err, epochs)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := m.FitContext(ctx, X, nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestKMeans_PredictProba(t *testing.T) {
+	X := mat.NewDense(6, 2, []float64{0, 0, 0, 1, 1, 0, 5, 5, 5, 6, 6, 5})
+	m := &KMeans{NClusters: 2}
+	m.Fit(X, nil)
+	Ypred := m.Predict(X, nil)
+	P := m.PredictProba(X, nil)
+	D := m.DecisionFunction(X, nil)
+	for i := 0; i < 6; i++ {
+		if s := floats.Sum(P.RawRowView(i)); math.Abs(s-1) > 1e-12 {
+			t.Errorf("row %d probabilities sum to %g", i, s)
+		}
+		if c := float64(floats.MaxIdx(P.RawRowView(i))); c != Ypred.At(i, 0) || float64(floats.MaxIdx(D.RawRowView(i))) != c {
+			t.Errorf("row %d: PredictProba and DecisionFunction disagree with Predict", i)
+		}
+	}
+}
+
+func TestKMeans_Init(t *testing.T) {
+	centers := mat.NewDense(4, 2, []float64{0, 0, 10, 0, 0, 10, 10, 10})
+	X, Y := datasets.MakeBlobs(&datasets.MakeBlobsConfig{NSamples: 300, Centers: centers, ClusterStd: 1, RandomState: base.NewSource(7)})
+	Xreversed := mat.NewDense(300, 2, nil)
+	for i := 0; i < 300; i++ {
+		Xreversed.SetRow(i, X.RawRowView(299-i))
+	}
+	for _, init := range []string{"k-means++", "random"} {
+		m := NewKMeans(4)
+		m.Init, m.RandomState = init, base.NewSource(7)
+		m.Fit(X, nil)
+		if m.NIter < 1 || m.NIter > m.MaxIter {
+			t.Errorf("%s: unexpected NIter %d", init, m.NIter)
+		}
+		// Labels are the predictions of the training samples and Inertia the sum of their squared distances to their centroid
+		Ypred, D := m.Predict(X, nil), m.DecisionFunction(X, nil)
+		inertia := 0.
+		clusterOf := map[float64]int{}
+		for i, label := range m.Labels {
+			if float64(label) != Ypred.At(i, 0) {
+				t.Fatalf("%s: Labels differ from Predict", init)
+			}
+			inertia -= D.At(i, label)
+			if c, ok := clusterOf[Y.At(i, 0)]; ok && c != label {
+				t.Errorf("%s: blob %g is split", init, Y.At(i, 0))
+			}
+			clusterOf[Y.At(i, 0)] = label
+		}
+		if math.Abs(inertia-m.Inertia) > 1e-9*inertia {
+			t.Errorf("%s: expected inertia %g, got %g", init, inertia, m.Inertia)
+		}
+		// results do not depend on the order of samples
+		reversed := NewKMeans(4)
+		reversed.Init, reversed.RandomState = init, base.NewSource(7)
+		reversed.Fit(Xreversed, nil)
+		if math.Abs(reversed.Inertia-m.Inertia) > 1e-9*inertia {
+			t.Errorf("%s: expected inertia %g for reversed samples, got %g", init, m.Inertia, reversed.Inertia)
+		}
+	}
+
+	m := &KMeans{NClusters: 2, Init: "kmeans"}
+	if err := m.FitE(X, nil); !errors.Is(err, base.ErrInvalidParam) {
+		t.Errorf("expected ErrInvalidParam for an unknown Init, got %v", err)
+	}
+}
+
+func TestKMeans_NInit(t *testing.T) {
+	X := datasets.LoadIris().X
+	var inertia []float64
+	for _, nInit := range []int{1, 10} {
+		m := NewKMeans(3)
+		m.Init, m.NInit, m.RandomState = "random", nInit, base.NewSource(2)
+		m.Fit(X, nil)
+		inertia = append(inertia, m.Inertia)
+	}
+	// the first seeding is the same and falls in a local minimum, the best of 10 is kept
+	if inertia[0] < 100 || math.Abs(inertia[1]-78.94) > .01 {
+		t.Errorf("expected the inertias 142.86 and 78.94 with 1 and 10 seedings, got %g and %g", inertia[0], inertia[1])
+	}
+}
+
+func TestKMeans_EmptyCluster(t *testing.T) {
+	X := mat.NewDense(5, 1, []float64{0, 1, 2, 3, 20})
+	m := &KMeans{NClusters: 3, Distance: EuclideanDistance}
+	centroids := mat.NewDense(3, 1, []float64{0, 100, 1000})
+	labels, dist := make([]int, 5), make([]float64, 5)
+	m.assign(X, centroids, labels, dist, 1)
+	// clusters 1 and 2 are empty and get the samples farthest from centroid 0
+	m.updateCentroids(X, nil, centroids, labels, dist, 1)
+	if expected := []int{0, 0, 0, 2, 1}; !reflect.DeepEqual(labels, expected) {
+		t.Errorf("expected labels %v, got %v", expected, labels)
+	}
+	if expected := []float64{1, 20, 3}; !floats.Equal(centroids.RawMatrix().Data, expected) {
+		t.Errorf("expected centroids %g, got %g", expected, centroids.RawMatrix().Data)
+	}
+}
+
+func TestKMeans_Elkan(t *testing.T) {
+	centers := mat.NewDense(5, 3, []float64{0, 0, 0, 10, 0, 0, 0, 10, 0, 0, 0, 10, 10, 10, 10})
+	X, _ := datasets.MakeBlobs(&datasets.MakeBlobsConfig{NSamples: 1000, NFeatures: 3, Centers: centers, ClusterStd: 2, RandomState: base.NewSource(7)})
+	var fits []*KMeans
+	var evaluations []int64
+	for _, algorithm := range []string{"lloyd", "elkan"} {
+		var n int64
+		m := NewKMeans(5)
+		// random seeding computes no distance
+		m.Init, m.Algorithm, m.RandomState, m.Tol = "random", algorithm, base.NewSource(7), 0
+		m.Distance = func(a, b mat.Vector) float64 {
+			atomic.AddInt64(&n, 1)
+			return EuclideanDistance(a, b)
+		}
+		m.Fit(X, nil)
+		fits = append(fits, m)
+		evaluations = append(evaluations, n)
+	}
+	lloyd, elkan := fits[0], fits[1]
+	if !reflect.DeepEqual(lloyd.Labels, elkan.Labels) || lloyd.NIter != elkan.NIter || !mat.EqualApprox(lloyd.Centroids, elkan.Centroids, 1e-12) || math.Abs(lloyd.Inertia-elkan.Inertia) > 1e-9*lloyd.Inertia {
+		t.Errorf("expected the same clusters with elkan, got inertia %g after %d iterations instead of %g after %d", elkan.Inertia, elkan.NIter, lloyd.Inertia, lloyd.NIter)
+	}
+	if evaluations[1] >= evaluations[0]/2 {
+		t.Errorf("expected elkan to compute less than half the distances, got %d instead of %d", evaluations[1], evaluations[0])
+	}
+}
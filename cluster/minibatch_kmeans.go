@@ -0,0 +1,296 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+
+	"golang.org/x/exp/rand"
+
+	"github.com/pa-m/sklearn/base"
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mat"
+)
+
+// MiniBatchKMeans is a KMeans whose centroids are updated with batches of samples: each centroid moves to the mean of its
+// previous position, weighted by Counts, and of the samples of the batch assigned to it. Fit draws BatchSize samples for
+// each batch and PartialFit updates the centroids with the given samples, such as a chunk of data that doesn't fit in memory.
+// MaxIter is the number of passes over the samples, default 100, and Algorithm is not used
+type MiniBatchKMeans struct {
+	KMeans
+	// BatchSize is the number of samples of a batch drawn by Fit, default 1024
+	BatchSize int
+	// MaxNoImprovement stops Fit when the smoothed inertia of batches did not decrease for this number of batches. 0 disables it
+	MaxNoImprovement int
+	// ReassignmentRatio moves the centroids whose count is lower than this ratio of the largest count to samples of a batch
+	ReassignmentRatio float64
+	// Runtime filled members
+	// Counts is the total weight of the samples that updated each centroid
+	Counts []float64 `param:"-"`
+	// NSteps is the number of batches that updated the centroids
+	NSteps int `param:"-"`
+}
+
+// NewMiniBatchKMeans returns a MiniBatchKMeans with k-means++ seeding, BatchSize 1024, MaxIter 100, MaxNoImprovement 10
+// and ReassignmentRatio 0.01
+func NewMiniBatchKMeans(nClusters int) *MiniBatchKMeans {
+	return &MiniBatchKMeans{KMeans: KMeans{NClusters: nClusters, Init: "k-means++", MaxIter: 100}, BatchSize: 1024,
+		MaxNoImprovement: 10, ReassignmentRatio: .01}
+}
+
+// PredicterClone for MiniBatchKMeans
+func (m *MiniBatchKMeans) PredicterClone() base.Predicter {
+	clone := *m
+	clone.KMeans = *m.KMeans.PredicterClone().(*KMeans)
+	clone.Counts, clone.NSteps = nil, 0
+	return &clone
+}
+
+func (m *MiniBatchKMeans) checkParams() error {
+	if err := m.KMeans.checkParams(); err != nil {
+		return err
+	}
+	if m.BatchSize < 0 || m.MaxNoImprovement < 0 || m.ReassignmentRatio < 0 {
+		return fmt.Errorf("%w: BatchSize, MaxNoImprovement and ReassignmentRatio must be >= 0", base.ErrInvalidParam)
+	}
+	return nil
+}
+
+// Fit computes centroids. Y is not used
+func (m *MiniBatchKMeans) Fit(Xmatrix, Ymatrix mat.Matrix) base.Fiter {
+	return m.FitWeighted(Xmatrix, Ymatrix, nil)
+}
+
+// FitWeighted computes centroids, the weight of a sample being added to the count of its centroid
+func (m *MiniBatchKMeans) FitWeighted(Xmatrix, Ymatrix mat.Matrix, sampleWeight []float64) base.Fiter {
+	if err := m.checkParams(); err != nil {
+		panic(err)
+	}
+	if err := m.fit(Xmatrix, sampleWeight, nil); err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// FitE is Fit returning an error instead of panicking
+func (m *MiniBatchKMeans) FitE(X, Y mat.Matrix) error {
+	return m.FitContext(context.Background(), X, Y)
+}
+
+// FitContext is FitE stopping when ctx is done. progress is reported to the base.ProgressFunc of ctx after each batch,
+// with the smoothed inertia per sample of batches as loss
+func (m *MiniBatchKMeans) FitContext(ctx context.Context, X, Y mat.Matrix) (err error) {
+	if err = m.checkParams(); err != nil {
+		return
+	}
+	if err = base.CheckXY(X, Y); err != nil {
+		return
+	}
+	defer base.Recover(&err)
+	return m.fit(X, nil, base.NewMonitor(ctx))
+}
+
+// PartialFit updates the centroids with the samples of X, seeding them with X on the first call.
+// Labels and Inertia are those of the samples of X. Y is not used
+func (m *MiniBatchKMeans) PartialFit(X, Y mat.Matrix) base.Fiter {
+	if err := m.checkParams(); err != nil {
+		panic(err)
+	}
+	NSamples, _ := X.Dims()
+	m.setDefaults()
+	rnd := rand.New(m.randomState())
+	if m.Centroids == nil {
+		if NSamples < m.NClusters {
+			panic(fmt.Errorf("%w: NSamples<m.NClusters %d<%d", base.ErrInvalidParam, NSamples, m.NClusters))
+		}
+		m.Centroids = m.seed(X, nil, rnd)
+		m.Counts, m.NSteps = make([]float64, m.NClusters), 0
+	} else if err := base.CheckNFeatures(X, m.Centroids.RawMatrix().Cols); err != nil {
+		panic(err)
+	}
+	samples := make([]int, NSamples)
+	for i := range samples {
+		samples[i] = i
+	}
+	Xb, _ := batchOf(X, nil, samples)
+	m.Labels = make([]int, NSamples)
+	m.Inertia, _ = m.step(Xb, nil, m.Labels, rnd)
+	return m
+}
+
+func (m *MiniBatchKMeans) fit(X mat.Matrix, sampleWeight []float64, mon *base.Monitor) error {
+	NSamples, _ := X.Dims()
+	if NSamples < m.NClusters {
+		return fmt.Errorf("%w: NSamples<m.NClusters %d<%d", base.ErrInvalidParam, NSamples, m.NClusters)
+	}
+	if err := base.CheckSampleWeight(X, sampleWeight); err != nil {
+		return err
+	}
+	m.setDefaults()
+	rnd := rand.New(m.randomState())
+	batchSize := m.BatchSize
+	if batchSize == 0 {
+		batchSize = 1024
+	}
+	if batchSize > NSamples {
+		batchSize = NSamples
+	}
+	maxIter := m.MaxIter
+	if maxIter == 0 {
+		maxIter = 100
+	}
+	// the centroids are seeded with 3 batches
+	initSize := 3 * batchSize
+	if initSize > NSamples {
+		initSize = NSamples
+	}
+	if initSize < m.NClusters {
+		initSize = m.NClusters
+	}
+	Xinit, winit := batchOf(X, sampleWeight, rnd.Perm(NSamples)[:initSize])
+	m.Centroids = m.seed(Xinit, winit, rnd)
+	m.Counts, m.NSteps = make([]float64, m.NClusters), 0
+
+	tol := m.Tol * meanVariance(X)
+	// the inertia of batches is smoothed by an exponentially weighted average
+	alpha := math.Min(1, 2*float64(batchSize)/float64(NSamples+1))
+	ewa, bestEWA, noImprovement := 0., math.Inf(1), 0
+	batch, labels := make([]int, batchSize), make([]int, batchSize)
+	nSteps := maxIter * ((NSamples + batchSize - 1) / batchSize)
+	for step := 1; step <= nSteps; step++ {
+		for i := range batch {
+			batch[i] = rnd.Intn(NSamples)
+		}
+		Xb, wb := batchOf(X, sampleWeight, batch)
+		inertia, shift := m.step(Xb, wb, labels, rnd)
+		inertia /= float64(batchSize)
+		if step == 1 {
+			ewa = inertia
+		} else {
+			ewa = ewa*(1-alpha) + inertia*alpha
+		}
+		if err := mon.Report(step, ewa); err != nil {
+			return err
+		}
+		if tol > 0 && shift <= tol {
+			break
+		}
+		if ewa < bestEWA {
+			bestEWA, noImprovement = ewa, 0
+		} else if noImprovement++; m.MaxNoImprovement > 0 && noImprovement >= m.MaxNoImprovement {
+			break
+		}
+	}
+	m.NIter = (m.NSteps*batchSize + NSamples - 1) / NSamples
+	m.Labels = make([]int, NSamples)
+	dist := make([]float64, NSamples)
+	m.assign(X, m.Centroids, m.Labels, dist, m.NJobs)
+	m.Inertia = weightedSumOfSquares(dist, sampleWeight)
+	return nil
+}
+
+// seed returns the seeding of NInit with the lowest inertia on X, NInit being 1 for k-means++ and 3 for random by default
+func (m *MiniBatchKMeans) seed(X mat.Matrix, sampleWeight []float64, rnd *rand.Rand) *mat.Dense {
+	nInit := m.NInit
+	if nInit == 0 {
+		nInit = 1
+		if m.Init == "random" {
+			nInit = 3
+		}
+	}
+	NSamples, _ := X.Dims()
+	labels, dist := make([]int, NSamples), make([]float64, NSamples)
+	var best *mat.Dense
+	bestInertia := math.Inf(1)
+	for r := 0; r < nInit; r++ {
+		var centroids *mat.Dense
+		if m.Init == "random" {
+			centroids = m.randomInit(X, rnd)
+		} else {
+			centroids = m.kmeansPlusPlus(X, sampleWeight, rnd)
+		}
+		m.assign(X, centroids, labels, dist, m.NJobs)
+		if inertia := weightedSumOfSquares(dist, sampleWeight); best == nil || inertia < bestInertia {
+			best, bestInertia = centroids, inertia
+		}
+	}
+	return best
+}
+
+// step updates the centroids with the samples of Xb, setting labels to their nearest centroid before the update.
+// it returns the inertia of the samples and the sum of the squared moves of the centroids. every 10 NClusters samples,
+// the centroids with a low count are moved to samples of Xb
+func (m *MiniBatchKMeans) step(Xb *mat.Dense, sampleWeight []float64, labels []int, rnd *rand.Rand) (inertia, shift float64) {
+	NSamples, NFeatures := Xb.Dims()
+	dist := make([]float64, NSamples)
+	m.assign(Xb, m.Centroids, labels, dist, m.NJobs)
+	inertia = weightedSumOfSquares(dist, sampleWeight)
+	sums := mat.NewDense(m.NClusters, NFeatures, nil)
+	weights := make([]float64, m.NClusters)
+	for sample, ic := range labels {
+		w := sampleWeightAt(sampleWeight, sample)
+		weights[ic] += w
+		floats.AddScaled(sums.RawRowView(ic), w, Xb.RawRowView(sample))
+	}
+	previous := make([]float64, NFeatures)
+	for ic, w := range weights {
+		if w == 0 {
+			continue
+		}
+		c := m.Centroids.RawRowView(ic)
+		copy(previous, c)
+		floats.Scale(m.Counts[ic], c)
+		floats.Add(c, sums.RawRowView(ic))
+		m.Counts[ic] += w
+		floats.Scale(1/m.Counts[ic], c)
+		floats.Sub(previous, c)
+		shift += floats.Dot(previous, previous)
+	}
+	m.NSteps++
+	if period := (10*m.NClusters + NSamples - 1) / NSamples; m.NSteps%period == 0 {
+		m.reassign(Xb, rnd)
+	}
+	return
+}
+
+// reassign moves the centroids whose count is lower than ReassignmentRatio times the largest one to samples of Xb drawn
+// at random, setting their count to the lowest count of other centroids. at most half of the samples of Xb are used
+func (m *MiniBatchKMeans) reassign(Xb *mat.Dense, rnd *rand.Rand) {
+	NSamples, _ := Xb.Dims()
+	threshold := m.ReassignmentRatio * floats.Max(m.Counts)
+	var low []int
+	minCount := math.Inf(1)
+	for ic, c := range m.Counts {
+		if c < threshold {
+			low = append(low, ic)
+		} else {
+			minCount = math.Min(minCount, c)
+		}
+	}
+	if len(low) > NSamples/2 {
+		sort.Slice(low, func(i, j int) bool { return m.Counts[low[i]] < m.Counts[low[j]] })
+		low = low[:NSamples/2]
+	}
+	for t, sample := range rnd.Perm(NSamples)[:len(low)] {
+		m.Centroids.SetRow(low[t], Xb.RawRowView(sample))
+		m.Counts[low[t]] = minCount
+	}
+}
+
+// batchOf returns the rows of X and the weights of samples
+func batchOf(X mat.Matrix, sampleWeight []float64, samples []int) (*mat.Dense, []float64) {
+	_, NFeatures := X.Dims()
+	Xb := mat.NewDense(len(samples), NFeatures, nil)
+	var wb []float64
+	if sampleWeight != nil {
+		wb = make([]float64, len(samples))
+	}
+	for i, sample := range samples {
+		matRow(Xb.RawRowView(i), sample, X)
+		if wb != nil {
+			wb[i] = sampleWeight[sample]
+		}
+	}
+	return Xb, wb
+}
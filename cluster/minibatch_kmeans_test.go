@@ -0,0 +1,95 @@
+package cluster
+
+import (
+	"math"
+	"testing"
+
+	"golang.org/x/exp/rand"
+
+	"github.com/pa-m/sklearn/base"
+	"github.com/pa-m/sklearn/datasets"
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mat"
+)
+
+var (
+	_ base.PredicterE         = &MiniBatchKMeans{}
+	_ base.ProbaPredicter     = &MiniBatchKMeans{}
+	_ base.DecisionFunctioner = &MiniBatchKMeans{}
+)
+
+// splitBlob returns true if the samples of a blob of Y have different labels
+func splitBlob(labels []int, Y mat.Matrix) bool {
+	clusterOf := map[float64]int{}
+	for i, label := range labels {
+		if c, ok := clusterOf[Y.At(i, 0)]; ok && c != label {
+			return true
+		}
+		clusterOf[Y.At(i, 0)] = label
+	}
+	return false
+}
+
+func TestMiniBatchKMeans(t *testing.T) {
+	centers := mat.NewDense(4, 2, []float64{0, 0, 10, 0, 0, 10, 10, 10})
+	X, Y := datasets.MakeBlobs(&datasets.MakeBlobsConfig{NSamples: 3000, Centers: centers, ClusterStd: 1, RandomState: base.NewSource(7)})
+	kmeans := NewKMeans(4)
+	kmeans.RandomState = base.NewSource(7)
+	kmeans.Fit(X, nil)
+
+	m := NewMiniBatchKMeans(4)
+	m.BatchSize, m.RandomState = 100, base.NewSource(7)
+	m.Fit(X, nil)
+	if splitBlob(m.Labels, Y) {
+		t.Error("expected a cluster per blob")
+	}
+	if m.Inertia > 1.01*kmeans.Inertia || m.NSteps*m.BatchSize > 3000*m.MaxIter {
+		t.Errorf("expected an inertia close to %g, got %g after %d batches", kmeans.Inertia, m.Inertia, m.NSteps)
+	}
+	if !mat.Equal(m.Predict(X, nil), mat.NewDense(3000, 1, intsToFloats(m.Labels))) {
+		t.Error("expected Labels to be the predictions of the training samples")
+	}
+
+	// PartialFit with chunks of the samples
+	m = NewMiniBatchKMeans(4)
+	m.RandomState = base.NewSource(7)
+	for pass := 0; pass < 2; pass++ {
+		for start := 0; start < 3000; start += 500 {
+			m.PartialFit(X.Slice(start, start+500, 0, 2), nil)
+		}
+	}
+	if m.NSteps != 12 || math.Abs(floats.Sum(m.Counts)-6000) > 1e-9 {
+		t.Errorf("expected 12 batches of 500 samples, got %d batches and counts %g", m.NSteps, m.Counts)
+	}
+	if Ypred := m.Predict(X, nil); splitBlob(intsOf(Ypred), Y) {
+		t.Error("expected a cluster per blob after PartialFit")
+	}
+}
+
+func TestMiniBatchKMeans_Reassign(t *testing.T) {
+	m := &MiniBatchKMeans{KMeans: KMeans{NClusters: 3}, ReassignmentRatio: .01}
+	m.Centroids = mat.NewDense(3, 1, []float64{0, 1, 2})
+	m.Counts = []float64{100, .5, 50}
+	Xb := mat.NewDense(2, 1, []float64{7, 7})
+	m.reassign(Xb, rand.New(base.NewSource(7)))
+	if expected := []float64{0, 7, 2}; !floats.Equal(m.Centroids.RawMatrix().Data, expected) || m.Counts[1] != 50 {
+		t.Errorf("expected the centroid with a low count to move to a sample, got centroids %g and counts %g", m.Centroids.RawMatrix().Data, m.Counts)
+	}
+}
+
+func intsToFloats(a []int) []float64 {
+	f := make([]float64, len(a))
+	for i, v := range a {
+		f[i] = float64(v)
+	}
+	return f
+}
+
+func intsOf(Y mat.Matrix) []int {
+	n, _ := Y.Dims()
+	a := make([]int, n)
+	for i := range a {
+		a[i] = int(Y.At(i, 0))
+	}
+	return a
+}
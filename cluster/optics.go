@@ -0,0 +1,425 @@
+package cluster
+
+import (
+	"fmt"
+	"math"
+	"runtime"
+
+	"github.com/pa-m/sklearn/base"
+	"github.com/pa-m/sklearn/neighbors"
+	"gonum.org/v1/gonum/mat"
+)
+
+// OPTICS orders samples so that the samples of a dense region are consecutive, and computes the reachability distance
+// of each sample from the samples ordered before it. clusters of different densities are the valleys of this
+// reachability plot, extracted by the steepness of their borders (xi) or by cutting the plot at a distance as DBSCAN
+type OPTICS struct {
+	// MinSamples is the number of samples, the sample included, in the neighborhood of a core sample. a value below 1
+	// is a fraction of the number of samples. default 5
+	MinSamples float64
+	// MaxEps is the greatest distance between neighbors, default +Inf. a lower MaxEps speeds up Fit
+	MaxEps float64
+	// ClusterMethod extracts Labels from the reachability plot: "xi" (default) or "dbscan"
+	ClusterMethod string
+	// Eps is the distance at which the dbscan ClusterMethod cuts the reachability plot, default MaxEps
+	Eps float64
+	// Xi is the minimum relative steepness of the borders of the clusters found by xi, default .05
+	Xi float64
+	// PredecessorCorrection removes from the clusters found by xi the samples reached from outside the cluster
+	PredecessorCorrection bool
+	// MinClusterSize is the minimum number of samples of a cluster found by xi. a value below 1 is a fraction of the
+	// number of samples. default MinSamples
+	MinClusterSize float64
+	// Metric, P, Algorithm, LeafSize and NJobs configure the neighbors.NearestNeighbors searching neighborhoods
+	Metric    string
+	P         float64
+	Algorithm string
+	LeafSize  int
+	NJobs     int
+	// Runtime filled members
+	// Labels is the cluster of each sample, -1 for noise
+	Labels []int `param:"-"`
+	// Reachability is the reachability distance of each sample: the greatest of the distance to its predecessor and of
+	// the core distance of its predecessor. it is +Inf for samples without predecessor
+	Reachability []float64 `param:"-"`
+	// Ordering is the order of samples in the reachability plot
+	Ordering []int `param:"-"`
+	// CoreDistances is the distance of each sample to its MinSamples-th nearest neighbor, +Inf beyond MaxEps
+	CoreDistances []float64 `param:"-"`
+	// Predecessor is the sample from which each sample was reached, -1 for none
+	Predecessor []int `param:"-"`
+	// ClusterHierarchy are the clusters found by xi, as the first and last positions of their samples in Ordering.
+	// a cluster comes before the clusters containing it
+	ClusterHierarchy [][2]int `param:"-"`
+}
+
+// NewOPTICS returns an OPTICS with MinSamples 5, the xi ClusterMethod with Xi .05 and PredecessorCorrection
+func NewOPTICS() *OPTICS {
+	return &OPTICS{MinSamples: 5, MaxEps: math.Inf(1), ClusterMethod: "xi", Xi: .05, PredecessorCorrection: true, Metric: "euclidean", P: 2, Algorithm: "auto", LeafSize: 30}
+}
+
+// PredicterClone for OPTICS
+func (m *OPTICS) PredicterClone() base.Predicter {
+	clone := *m
+	clone.Labels, clone.Reachability, clone.Ordering, clone.CoreDistances, clone.Predecessor, clone.ClusterHierarchy = nil, nil, nil, nil, nil, nil
+	return base.DeepCopy(&clone).(*OPTICS)
+}
+
+// IsFitted returns true when Labels have been computed. see base.FittedChecker
+func (m *OPTICS) IsFitted() bool { return m.Labels != nil }
+
+// IsClassifier returns true for OPTICS
+func (m *OPTICS) IsClassifier() bool { return true }
+
+func (m *OPTICS) checkParams() error {
+	if m.MinSamples < 0 || m.MinClusterSize < 0 || m.MaxEps < 0 || m.Eps < 0 {
+		return fmt.Errorf("%w: MinSamples, MinClusterSize, MaxEps and Eps must be >= 0", base.ErrInvalidParam)
+	}
+	if m.Xi < 0 || m.Xi >= 1 {
+		return fmt.Errorf("%w: Xi must be in [0, 1), got %g", base.ErrInvalidParam, m.Xi)
+	}
+	if m.Eps > m.maxEps() {
+		return fmt.Errorf("%w: Eps %g must be <= MaxEps %g", base.ErrInvalidParam, m.Eps, m.MaxEps)
+	}
+	switch m.ClusterMethod {
+	case "", "xi", "dbscan":
+	default:
+		return fmt.Errorf("%w: ClusterMethod must be xi or dbscan, got %q", base.ErrInvalidParam, m.ClusterMethod)
+	}
+	return nil
+}
+
+func (m *OPTICS) maxEps() float64 {
+	if m.MaxEps == 0 {
+		return math.Inf(1)
+	}
+	return m.MaxEps
+}
+
+// sizeParam returns the number of samples of a size parameter v, a value below 1 being a fraction of NSamples
+func sizeParam(v float64, defaultSize, NSamples int) int {
+	switch {
+	case v == 0:
+		return defaultSize
+	case v <= 1:
+		if size := int(v * float64(NSamples)); size > 2 {
+			return size
+		}
+		return 2
+	default:
+		return int(v)
+	}
+}
+
+// newNeighbors returns the neighbors.NearestNeighbors of a density based clustering fitted to X
+func newNeighbors(X *mat.Dense, metric string, p float64, algorithm string, leafSize, nJobs int) *neighbors.NearestNeighbors {
+	nbrs := neighbors.NewNearestNeighbors()
+	if metric != "" {
+		nbrs.Metric = metric
+	}
+	if p > 0 {
+		nbrs.P = p
+	}
+	if algorithm != "" {
+		nbrs.Algorithm = algorithm
+	}
+	if nJobs <= 0 {
+		nJobs = runtime.NumCPU()
+	}
+	nbrs.LeafSize, nbrs.NJobs = leafSize, nJobs
+	nbrs.Fit(X, nil)
+	return nbrs
+}
+
+// coreDistances returns the distance of each sample of X to its minSamples-th nearest neighbor, the sample included
+func coreDistances(nbrs *neighbors.NearestNeighbors, X *mat.Dense, minSamples int) []float64 {
+	distances, _ := nbrs.KNeighbors(X, minSamples)
+	NSamples, _ := X.Dims()
+	core := make([]float64, NSamples)
+	for i := range core {
+		core[i] = distances.At(i, minSamples-1)
+	}
+	return core
+}
+
+// Fit computes the reachability plot of the samples of X and extracts Labels from it. Y is not used
+func (m *OPTICS) Fit(Xmatrix, Ymatrix mat.Matrix) base.Fiter {
+	if err := m.checkParams(); err != nil {
+		panic(err)
+	}
+	X := base.ToDense(Xmatrix)
+	NSamples, NFeatures := X.Dims()
+	minSamples := sizeParam(m.MinSamples, 5, NSamples)
+	if minSamples > NSamples {
+		panic(fmt.Errorf("%w: MinSamples %d > NSamples %d", base.ErrInvalidParam, minSamples, NSamples))
+	}
+	maxEps := m.maxEps()
+	nbrs := newNeighbors(X, m.Metric, m.P, m.Algorithm, m.LeafSize, m.NJobs)
+	m.CoreDistances = coreDistances(nbrs, X, minSamples)
+	for i, d := range m.CoreDistances {
+		if d > maxEps {
+			m.CoreDistances[i] = math.Inf(1)
+		}
+	}
+	m.Reachability, m.Predecessor = make([]float64, NSamples), make([]int, NSamples)
+	for i := range m.Reachability {
+		m.Reachability[i], m.Predecessor[i] = math.Inf(1), -1
+	}
+	m.Ordering = make([]int, 0, NSamples)
+	processed := make([]bool, NSamples)
+	for len(m.Ordering) < NSamples {
+		// the next sample is the unprocessed sample of lowest reachability
+		point := -1
+		for i, done := range processed {
+			if !done && (point < 0 || m.Reachability[i] < m.Reachability[point]) {
+				point = i
+			}
+		}
+		processed[point] = true
+		m.Ordering = append(m.Ordering, point)
+		if math.IsInf(m.CoreDistances[point], 1) {
+			continue
+		}
+		// update the reachability of the unprocessed neighbors of a core sample
+		distances, indices := nbrs.RadiusNeighbors(X.Slice(point, point+1, 0, NFeatures).(*mat.Dense), maxEps)
+		for k, i := range indices[0] {
+			if processed[i] {
+				continue
+			}
+			if r := math.Max(distances[0][k], m.CoreDistances[point]); r < m.Reachability[i] {
+				m.Reachability[i], m.Predecessor[i] = r, point
+			}
+		}
+	}
+	if m.ClusterMethod == "dbscan" {
+		eps := m.Eps
+		if eps == 0 {
+			eps = maxEps
+		}
+		m.Labels, m.ClusterHierarchy = m.extractDBSCAN(eps), nil
+	} else {
+		m.extractXi(minSamples, sizeParam(m.MinClusterSize, minSamples, NSamples))
+	}
+	return m
+}
+
+// FitE is Fit returning an error instead of panicking
+func (m *OPTICS) FitE(X, Y mat.Matrix) error {
+	if err := m.checkParams(); err != nil {
+		return err
+	}
+	return base.FitE(m, X, Y)
+}
+
+// ExtractDBSCAN returns the labels of a DBSCAN with Eps eps and the MinSamples of OPTICS, from the reachability plot.
+// eps must not exceed MaxEps. only border samples may be assigned to other clusters than by DBSCAN
+func (m *OPTICS) ExtractDBSCAN(eps float64) []int {
+	base.MustBeFitted(m)
+	return m.extractDBSCAN(eps)
+}
+
+func (m *OPTICS) extractDBSCAN(eps float64) []int {
+	labels := make([]int, len(m.Ordering))
+	label := -1
+	for _, i := range m.Ordering {
+		switch {
+		case m.Reachability[i] <= eps:
+			labels[i] = label
+		case m.CoreDistances[i] <= eps:
+			label++
+			labels[i] = label
+		default:
+			labels[i] = -1
+		}
+	}
+	return labels
+}
+
+// steepDownArea is a region of the reachability plot going down steeply from start to end. mib is the maximum
+// reachability after it
+type steepDownArea struct {
+	start, end int
+	mib        float64
+}
+
+// extractXi sets ClusterHierarchy to the clusters bounded by a steep down area and a steep up area of the reachability
+// plot, and Labels to the smallest clusters of it
+func (m *OPTICS) extractXi(minSamples, minClusterSize int) {
+	n := len(m.Ordering)
+	// a +Inf at the end of the plot ends the clusters left open
+	r, pred := make([]float64, n+1), make([]int, n)
+	for k, i := range m.Ordering {
+		r[k], pred[k] = m.Reachability[i], m.Predecessor[i]
+	}
+	r[n] = math.Inf(1)
+	xiComplement := 1 - m.Xi
+	steepUp, steepDown, up, down := make([]bool, n), make([]bool, n), make([]bool, n), make([]bool, n)
+	for k := 0; k < n; k++ {
+		ratio := r[k] / r[k+1]
+		steepUp[k], steepDown[k] = ratio <= xiComplement, ratio >= 1/xiComplement
+		down[k], up[k] = ratio > 1, ratio < 1
+	}
+	var sdas []steepDownArea
+	m.ClusterHierarchy = nil
+	index, mib := 0, 0.
+	for steep := 0; steep < n; steep++ {
+		if steep < index || !steepUp[steep] && !steepDown[steep] {
+			continue
+		}
+		for _, v := range r[index : steep+1] {
+			mib = math.Max(mib, v)
+		}
+		sdas = filterSteepDownAreas(sdas, mib, xiComplement, r)
+		if steepDown[steep] {
+			end := extendRegion(steepDown, up, steep, minSamples)
+			sdas = append(sdas, steepDownArea{start: steep, end: end})
+			index = end + 1
+			mib = r[index]
+			continue
+		}
+		uStart, uEnd := steep, extendRegion(steepUp, down, steep, minSamples)
+		index = uEnd + 1
+		mib = r[index]
+		var clusters [][2]int
+		for _, D := range sdas {
+			cStart, cEnd := D.start, uEnd
+			if r[cEnd+1]*xiComplement < D.mib {
+				continue
+			}
+			// move the start or the end of the cluster to the level of the other border
+			dMax := r[D.start]
+			if dMax*xiComplement >= r[cEnd+1] {
+				for r[cStart+1] > r[cEnd+1] && cStart < D.end {
+					cStart++
+				}
+			} else if r[cEnd+1]*xiComplement >= dMax {
+				for cEnd > uStart && r[cEnd-1] > dMax {
+					cEnd--
+				}
+			}
+			if m.PredecessorCorrection {
+				var ok bool
+				if cStart, cEnd, ok = correctPredecessor(r, pred, m.Ordering, cStart, cEnd); !ok {
+					continue
+				}
+			}
+			if cEnd-cStart+1 < minClusterSize || cStart > D.end || cEnd < uStart {
+				continue
+			}
+			clusters = append(clusters, [2]int{cStart, cEnd})
+		}
+		// smaller clusters first
+		for k := len(clusters) - 1; k >= 0; k-- {
+			m.ClusterHierarchy = append(m.ClusterHierarchy, clusters[k])
+		}
+	}
+	// label the samples of the clusters not containing a smaller cluster
+	labels := make([]int, n)
+	for k := range labels {
+		labels[k] = -1
+	}
+	label := 0
+	for _, c := range m.ClusterHierarchy {
+		free := true
+		for k := c[0]; k <= c[1]; k++ {
+			free = free && labels[k] == -1
+		}
+		if free {
+			for k := c[0]; k <= c[1]; k++ {
+				labels[k] = label
+			}
+			label++
+		}
+	}
+	m.Labels = make([]int, n)
+	for k, i := range m.Ordering {
+		m.Labels[i] = labels[k]
+	}
+}
+
+// filterSteepDownAreas removes the steep down areas lower than mib and raises the mib of the others
+func filterSteepDownAreas(sdas []steepDownArea, mib, xiComplement float64, r []float64) []steepDownArea {
+	if math.IsInf(mib, 1) {
+		return nil
+	}
+	kept := sdas[:0]
+	for _, D := range sdas {
+		if mib <= r[D.start]*xiComplement {
+			D.mib = math.Max(D.mib, mib)
+			kept = append(kept, D)
+		}
+	}
+	return kept
+}
+
+// extendRegion returns the end of the steep area starting at start, which may hold up to minSamples consecutive
+// points that are neither steep nor going the other way
+func extendRegion(steep, otherWay []bool, start, minSamples int) int {
+	nonSteep, end := 0, start
+	for k := start; k < len(steep); k++ {
+		switch {
+		case steep[k]:
+			nonSteep, end = 0, k
+		case !otherWay[k]:
+			nonSteep++
+			if nonSteep > minSamples {
+				return end
+			}
+		default:
+			return end
+		}
+	}
+	return end
+}
+
+// correctPredecessor shrinks the end of the cluster from s to e until the predecessor of its last sample is in it
+func correctPredecessor(r []float64, pred, ordering []int, s, e int) (int, int, bool) {
+	for s < e {
+		if r[s] > r[e] {
+			return s, e, true
+		}
+		for k := s; k < e; k++ {
+			if pred[e] == ordering[k] {
+				return s, e, true
+			}
+		}
+		e--
+	}
+	return s, e, false
+}
+
+// GetNOutputs returns output columns number for Y to pass to predict
+func (m *OPTICS) GetNOutputs() int { return 1 }
+
+// Predict for OPTICS returns Labels in Y. X must be the one passed to Fit
+func (m *OPTICS) Predict(X mat.Matrix, Ymutable mat.Mutable) *mat.Dense {
+	base.MustBeFitted(m)
+	return labelsTo(Ymutable, X, m.Labels)
+}
+
+// PredictE is Predict returning an error instead of panicking
+func (m *OPTICS) PredictE(X mat.Matrix, Y mat.Mutable) (*mat.Dense, error) {
+	if !m.IsFitted() {
+		return nil, base.ErrNotFitted
+	}
+	return base.PredictE(m, X, Y)
+}
+
+// Score for OPTICS returns 1
+func (m *OPTICS) Score(X, Y mat.Matrix) float64 { return 1 }
+
+// labelsTo returns the labels of the samples of X, which must be the samples passed to Fit, in a column
+func labelsTo(Ymutable mat.Mutable, X mat.Matrix, labels []int) *mat.Dense {
+	Y := base.ToDense(Ymutable)
+	nSamples, _ := X.Dims()
+	if Y.IsZero() {
+		*Y = *mat.NewDense(nSamples, 1, nil)
+	}
+	if ySamples, yCols := Y.Dims(); nSamples != len(labels) || ySamples != len(labels) || yCols != 1 {
+		panic(fmt.Errorf("%w: X must be the one passed to Fit and Y must have size samples*1", base.ErrShapeMismatch))
+	}
+	for i, label := range labels {
+		Y.Set(i, 0, float64(label))
+	}
+	return base.FromDense(Ymutable, Y)
+}
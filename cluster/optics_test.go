@@ -0,0 +1,190 @@
+package cluster
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"testing"
+
+	"github.com/pa-m/sklearn/base"
+	"github.com/pa-m/sklearn/datasets"
+	"gonum.org/v1/gonum/mat"
+)
+
+var (
+	_ base.PredicterE = &OPTICS{}
+	_ base.PredicterE = &HDBSCAN{}
+)
+
+func ExampleOPTICS() {
+	X := mat.NewDense(6, 2, []float64{1, 2, 2, 5, 3, 6, 8, 7, 8, 8, 7, 3})
+	m := NewOPTICS()
+	m.MinSamples = 2
+	m.Fit(X, nil)
+	fmt.Println(m.Labels, m.Ordering)
+	fmt.Println(m.ExtractDBSCAN(2))
+	// Output:
+	// [0 0 0 1 1 1] [0 1 2 5 3 4]
+	// [-1 0 0 1 1 -1]
+}
+
+// densityBlobs returns 3 blobs of 100 samples of different densities followed by 20 samples of uniform noise. labels
+// are the blob of each sample, -1 for noise
+func densityBlobs() (X *mat.Dense, labels []int) {
+	centers := [][]float64{{0, 0}, {1.5, 0}, {6, 6}}
+	stds := []float64{.15, .15, 1.2}
+	X = mat.NewDense(320, 2, nil)
+	for c := range centers {
+		Xc, _ := datasets.MakeBlobs(&datasets.MakeBlobsConfig{NSamples: 100, Centers: mat.NewDense(1, 2, centers[c]), ClusterStd: stds[c], RandomState: base.NewSource(uint64(c))})
+		X.Slice(100*c, 100*c+100, 0, 2).(*mat.Dense).Copy(Xc)
+		for i := 0; i < 100; i++ {
+			labels = append(labels, c)
+		}
+	}
+	rnd := base.NewSource(7)
+	for i := 300; i < 320; i++ {
+		X.Set(i, 0, -4+14*rnd.Float64())
+		X.Set(i, 1, -4+14*rnd.Float64())
+		labels = append(labels, -1)
+	}
+	return
+}
+
+// checkBlobs returns an error if the samples of each blob of densityBlobs are not mostly in a cluster of their own
+func checkBlobs(labels []int) error {
+	seen := map[int]bool{}
+	for c := 0; c < 3; c++ {
+		count := map[int]int{}
+		for _, l := range labels[100*c : 100*c+100] {
+			count[l]++
+		}
+		best := -1
+		for l, n := range count {
+			if l >= 0 && (best < 0 || n > count[best]) {
+				best = l
+			}
+		}
+		if best < 0 || count[best] < 90 || seen[best] {
+			return fmt.Errorf("blob %d: expected 90 samples in a cluster of its own, got %v", c, count)
+		}
+		seen[best] = true
+	}
+	return nil
+}
+
+func TestOPTICS_Reachability(t *testing.T) {
+	X := datasets.LoadIris().X
+	n, _ := X.Dims()
+	for _, maxEps := range []float64{math.Inf(1), .5} {
+		m := NewOPTICS()
+		m.MaxEps, m.ClusterMethod, m.Eps = maxEps, "dbscan", .4
+		m.Fit(X, nil)
+		distance := func(i, j int) float64 { return EuclideanDistance(X.RowView(i), X.RowView(j)) }
+		for i := 0; i < n; i++ {
+			d := make([]float64, n)
+			for j := range d {
+				d[j] = distance(i, j)
+			}
+			sort.Float64s(d)
+			if core := d[4]; core <= maxEps && math.Abs(core-m.CoreDistances[i]) > 1e-12 || core > maxEps && !math.IsInf(m.CoreDistances[i], 1) {
+				t.Fatalf("MaxEps %g: expected core distance %g for sample %d, got %g", maxEps, core, i, m.CoreDistances[i])
+			}
+		}
+		// the reachability of a sample is its lowest reachability from the core samples before it
+		for k, i := range m.Ordering {
+			expected := math.Inf(1)
+			for _, j := range m.Ordering[:k] {
+				if d := distance(i, j); d <= maxEps && !math.IsInf(m.CoreDistances[j], 1) {
+					expected = math.Min(expected, math.Max(d, m.CoreDistances[j]))
+				}
+			}
+			if math.Abs(expected-m.Reachability[i]) > 1e-12 || math.IsInf(expected, 1) != math.IsInf(m.Reachability[i], 1) {
+				t.Fatalf("MaxEps %g: expected reachability %g for sample %d, got %g", maxEps, expected, i, m.Reachability[i])
+			}
+			if p := m.Predecessor[i]; p >= 0 && math.Max(distance(i, p), m.CoreDistances[p]) != m.Reachability[i] {
+				t.Errorf("MaxEps %g: expected the reachability of sample %d from its predecessor", maxEps, i)
+			}
+			// the next sample has the lowest reachability
+			for _, j := range m.Ordering[k+1:] {
+				if m.Reachability[j] < m.Reachability[i] && k > 0 && !math.IsInf(m.Reachability[j], 1) {
+					var reachedBefore bool
+					for _, l := range m.Ordering[:k] {
+						reachedBefore = reachedBefore || l == m.Predecessor[j]
+					}
+					if reachedBefore {
+						t.Fatalf("MaxEps %g: sample %d of reachability %g ordered before sample %d of reachability %g", maxEps, i, m.Reachability[i], j, m.Reachability[j])
+					}
+				}
+			}
+		}
+		// the core samples of DBSCAN, which counts the neighbors excluding the sample, are clustered the same way
+		db := NewDBSCAN(&DBSCANConfig{Eps: .4, MinSamples: 4})
+		db.Fit(X, nil)
+		var opticsCore, dbscanCore []int
+		for _, i := range db.CoreSampleIndices {
+			opticsCore, dbscanCore = append(opticsCore, m.Labels[i]), append(dbscanCore, db.Labels[i])
+		}
+		nCore := 0
+		for _, d := range m.CoreDistances {
+			if d <= .4 {
+				nCore++
+			}
+		}
+		if nCore != len(db.CoreSampleIndices) || !samePartition(opticsCore, dbscanCore) {
+			t.Errorf("MaxEps %g: expected the clusters of the %d core samples of DBSCAN, got %d core samples", maxEps, len(db.CoreSampleIndices), nCore)
+		}
+	}
+}
+
+func TestOPTICS_Xi(t *testing.T) {
+	X, _ := densityBlobs()
+	m := NewOPTICS()
+	// larger clusters than the fluctuations of density inside the blobs
+	m.MinSamples, m.Xi, m.MinClusterSize = 10, .1, .2
+	m.Fit(X, nil)
+	if err := checkBlobs(m.Labels); err != nil {
+		t.Error(err)
+	}
+	// each cluster is a range of the ordering of the hierarchy
+	for i, label := range m.Labels {
+		if label < 0 {
+			continue
+		}
+		k := 0
+		for m.Ordering[k] != i {
+			k++
+		}
+		found := false
+		for _, c := range m.ClusterHierarchy {
+			found = found || c[0] <= k && k <= c[1]
+		}
+		if !found {
+			t.Fatalf("sample %d at position %d is in no cluster of %v", i, k, m.ClusterHierarchy)
+		}
+	}
+	// a single Eps misses the sparse blob or merges the dense ones
+	for eps := .1; eps < 2; eps += .2 {
+		if err := checkBlobs(m.ExtractDBSCAN(eps)); err == nil {
+			t.Errorf("eps %g: expected a global Eps to fail", eps)
+		}
+	}
+}
+
+func TestOPTICS_Errors(t *testing.T) {
+	X := datasets.LoadIris().X
+	for _, m := range []*OPTICS{
+		{MinSamples: -1},
+		{Xi: 1},
+		{MaxEps: 1, Eps: 2},
+		{ClusterMethod: "leaf"},
+		{MinSamples: 200},
+	} {
+		if err := m.FitE(X, nil); !errors.Is(err, base.ErrInvalidParam) {
+			t.Errorf("%+v: expected ErrInvalidParam, got %v", m, err)
+		}
+	}
+	if _, err := NewOPTICS().PredictE(X, nil); !errors.Is(err, base.ErrNotFitted) {
+		t.Errorf("expected ErrNotFitted, got %v", err)
+	}
+}
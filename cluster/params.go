@@ -0,0 +1,51 @@
+package cluster
+
+import "github.com/pa-m/sklearn/base"
+
+// GetParams returns the parameters of DBSCAN. see base.GetFieldParams
+func (m *DBSCAN) GetParams() map[string]interface{} { return base.GetFieldParams(m) }
+
+// SetParams sets the parameters of DBSCAN. see base.SetFieldParams
+func (m *DBSCAN) SetParams(params map[string]interface{}) error {
+	return base.SetFieldParams(m, params)
+}
+
+// GetParams returns the parameters of KMeans. see base.GetFieldParams
+func (m *KMeans) GetParams() map[string]interface{} { return base.GetFieldParams(m) }
+
+// SetParams sets the parameters of KMeans. see base.SetFieldParams
+func (m *KMeans) SetParams(params map[string]interface{}) error {
+	return base.SetFieldParams(m, params)
+}
+
+// GetParams returns the parameters of MiniBatchKMeans. see base.GetFieldParams
+func (m *MiniBatchKMeans) GetParams() map[string]interface{} { return base.GetFieldParams(m) }
+
+// SetParams sets the parameters of MiniBatchKMeans. see base.SetFieldParams
+func (m *MiniBatchKMeans) SetParams(params map[string]interface{}) error {
+	return base.SetFieldParams(m, params)
+}
+
+// GetParams returns the parameters of AgglomerativeClustering. see base.GetFieldParams
+func (m *AgglomerativeClustering) GetParams() map[string]interface{} { return base.GetFieldParams(m) }
+
+// SetParams sets the parameters of AgglomerativeClustering. see base.SetFieldParams
+func (m *AgglomerativeClustering) SetParams(params map[string]interface{}) error {
+	return base.SetFieldParams(m, params)
+}
+
+// GetParams returns the parameters of OPTICS. see base.GetFieldParams
+func (m *OPTICS) GetParams() map[string]interface{} { return base.GetFieldParams(m) }
+
+// SetParams sets the parameters of OPTICS. see base.SetFieldParams
+func (m *OPTICS) SetParams(params map[string]interface{}) error {
+	return base.SetFieldParams(m, params)
+}
+
+// GetParams returns the parameters of HDBSCAN. see base.GetFieldParams
+func (m *HDBSCAN) GetParams() map[string]interface{} { return base.GetFieldParams(m) }
+
+// SetParams sets the parameters of HDBSCAN. see base.SetFieldParams
+func (m *HDBSCAN) SetParams(params map[string]interface{}) error {
+	return base.SetFieldParams(m, params)
+}
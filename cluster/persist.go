@@ -0,0 +1,100 @@
+package cluster
+
+import (
+	"fmt"
+
+	"github.com/pa-m/sklearn/base"
+)
+
+func init() {
+	base.Register(&KMeans{})
+	base.Register(&MiniBatchKMeans{})
+	base.Register(&DBSCAN{})
+	base.Register(&AgglomerativeClustering{})
+	base.Register(&OPTICS{})
+	base.Register(&HDBSCAN{})
+}
+
+// MarshalState allows KMeans to be saved by base.Save. Distance must be nil or EuclideanDistance, RandomState is not saved
+func (m *KMeans) MarshalState() (*base.State, error) {
+	if err := m.checkPersistable(); err != nil {
+		return nil, err
+	}
+	return base.MarshalFields(m)
+}
+
+// checkPersistable returns base.ErrNotPersistable for a Distance other than EuclideanDistance
+func (m *KMeans) checkPersistable() error {
+	if !isEuclidean(m.Distance) {
+		return fmt.Errorf("%w: KMeans Distance func", base.ErrNotPersistable)
+	}
+	return nil
+}
+
+// UnmarshalState restores a KMeans saved by base.Save
+func (m *KMeans) UnmarshalState(st *base.State) error {
+	if err := base.UnmarshalFields(m, st); err != nil {
+		return err
+	}
+	if m.Centroids != nil {
+		m.Distance = EuclideanDistance
+	}
+	return nil
+}
+
+// MarshalState allows MiniBatchKMeans to be saved by base.Save. Distance must be nil or EuclideanDistance, RandomState is not saved
+func (m *MiniBatchKMeans) MarshalState() (*base.State, error) {
+	if err := m.KMeans.checkPersistable(); err != nil {
+		return nil, err
+	}
+	return base.MarshalFields(m)
+}
+
+// UnmarshalState restores a MiniBatchKMeans saved by base.Save
+func (m *MiniBatchKMeans) UnmarshalState(st *base.State) error {
+	if err := base.UnmarshalFields(m, st); err != nil {
+		return err
+	}
+	if m.Centroids != nil {
+		m.Distance = EuclideanDistance
+	}
+	return nil
+}
+
+// MarshalState allows DBSCAN to be saved by base.Save
+func (m *DBSCAN) MarshalState() (*base.State, error) { return base.MarshalFields(m) }
+
+// UnmarshalState restores a DBSCAN saved by base.Save
+func (m *DBSCAN) UnmarshalState(st *base.State) error { return base.UnmarshalFields(m, st) }
+
+// MarshalState allows AgglomerativeClustering to be saved by base.Save. Distance must be nil or EuclideanDistance,
+// Connectivity is not saved
+func (m *AgglomerativeClustering) MarshalState() (*base.State, error) {
+	if !isEuclidean(m.Distance) {
+		return nil, fmt.Errorf("%w: AgglomerativeClustering Distance func", base.ErrNotPersistable)
+	}
+	return base.MarshalFields(m)
+}
+
+// UnmarshalState restores an AgglomerativeClustering saved by base.Save
+func (m *AgglomerativeClustering) UnmarshalState(st *base.State) error {
+	if err := base.UnmarshalFields(m, st); err != nil {
+		return err
+	}
+	if m.Labels != nil {
+		m.Distance = EuclideanDistance
+	}
+	return nil
+}
+
+// MarshalState allows OPTICS to be saved by base.Save
+func (m *OPTICS) MarshalState() (*base.State, error) { return base.MarshalFields(m) }
+
+// UnmarshalState restores an OPTICS saved by base.Save
+func (m *OPTICS) UnmarshalState(st *base.State) error { return base.UnmarshalFields(m, st) }
+
+// MarshalState allows HDBSCAN to be saved by base.Save
+func (m *HDBSCAN) MarshalState() (*base.State, error) { return base.MarshalFields(m) }
+
+// UnmarshalState restores an HDBSCAN saved by base.Save
+func (m *HDBSCAN) UnmarshalState(st *base.State) error { return base.UnmarshalFields(m, st) }
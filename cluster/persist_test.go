@@ -0,0 +1,33 @@
+package cluster
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/pa-m/sklearn/base"
+	"github.com/pa-m/sklearn/datasets"
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestSaveLoad(t *testing.T) {
+	X := datasets.LoadIris().X
+	for _, m := range []base.Predicter{&KMeans{NClusters: 3}, NewMiniBatchKMeans(3), NewDBSCAN(&DBSCANConfig{Eps: .5, MinSamples: 5}), NewAgglomerativeClustering(3), NewOPTICS(), NewHDBSCAN()} {
+		m.Fit(X, nil)
+		buf := new(bytes.Buffer)
+		if err := base.Save(buf, m.(base.Persister)); err != nil {
+			t.Fatalf("%T: %s", m, err)
+		}
+		loaded, err := base.Load(buf)
+		if err != nil {
+			t.Fatalf("%T: %s", m, err)
+		}
+		if !mat.Equal(m.Predict(X, nil), loaded.Predict(X, nil)) {
+			t.Errorf("%T: loaded model predictions differ", m)
+		}
+	}
+	m := &KMeans{NClusters: 3, Distance: MinkowskiDistance(1)}
+	if err := base.Save(new(bytes.Buffer), m); !errors.Is(err, base.ErrNotPersistable) {
+		t.Errorf("expected ErrNotPersistable for a custom Distance, got %v", err)
+	}
+}
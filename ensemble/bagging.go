@@ -0,0 +1,522 @@
+package ensemble
+
+import (
+	"fmt"
+	"sort"
+
+	"golang.org/x/exp/rand"
+
+	"github.com/pa-m/sklearn/base"
+	"github.com/pa-m/sklearn/metrics"
+	"github.com/pa-m/sklearn/tree"
+	"gonum.org/v1/gonum/mat"
+)
+
+// BaseBagging holds the parameters shared by bagging meta-estimators, which fit clones of Estimator on random subsets of the samples
+// and of the features. NEstimators is the number of clones, 0 means 10.
+// MaxSamples and MaxFeatures are the number (int) or the fraction (float64) of samples and features drawn for each clone, nil meaning all.
+// samples are drawn with replacement if Bootstrap is set, features if BootstrapFeatures is set.
+// estimators which are a base.WeightedFiter are fitted on all the samples, weighted by the number of times they were drawn;
+// for the others, samples are drawn with replacement with probabilities proportional to the sample weights when they are not uniform.
+// if OOBScore is set, the score of each sample is computed from the estimators it was not drawn for.
+// estimators are fitted in parallel by NJobs threads, NJobs <= 0 meaning runtime.NumCPU(). RandomState draws the seed of each estimator,
+// which also sets its RandomState parameter if it has one
+type BaseBagging struct {
+	Estimator         base.Predicter
+	NEstimators       int
+	MaxSamples        interface{}
+	MaxFeatures       interface{}
+	Bootstrap         bool
+	BootstrapFeatures bool
+	OOBScore          bool
+	NJobs             int
+	RandomState       base.RandomState
+	// runtime filled members
+	NFeatures  int              `param:"-"`
+	Estimators []base.Predicter `param:"-"`
+	// EstimatorsFeatures holds the features drawn for each estimator
+	EstimatorsFeatures [][]int `param:"-"`
+	// OOBScoreValue is the accuracy or the R2 score of out-of-bag predictions, when OOBScore is set
+	OOBScoreValue float64 `param:"-"`
+}
+
+func (m *BaseBagging) resetFitted() {
+	m.NFeatures, m.Estimators, m.EstimatorsFeatures, m.OOBScoreValue = 0, nil, nil, 0
+}
+
+// IsFitted returns true when the estimators have been fitted
+func (m *BaseBagging) IsFitted() bool { return m.Estimators != nil }
+
+// drawCount returns the number of items to draw among n given limit, nil meaning n
+func drawCount(name string, limit interface{}, n int) int {
+	var k int
+	switch v := limit.(type) {
+	case nil:
+		k = n
+	case int:
+		k = v
+	case float64:
+		k = int(v * float64(n))
+	default:
+		panic(fmt.Errorf("%w: %s must be an int or a float64, got %T", base.ErrInvalidParam, name, limit))
+	}
+	if k < 1 || k > n {
+		panic(fmt.Errorf("%w: %s must give between 1 and %d items, got %v", base.ErrInvalidParam, name, n, limit))
+	}
+	return k
+}
+
+// draw returns k indices among n, drawn with replacement if replace is set. if weight is not nil, indices are drawn with replacement
+// with probabilities proportional to weight
+func draw(rnd *rand.Rand, n, k int, replace bool, weight []float64) []int {
+	idx := make([]int, k)
+	switch {
+	case weight != nil:
+		cum := make([]float64, n)
+		sum := 0.
+		for i, w := range weight {
+			sum += w
+			cum[i] = sum
+		}
+		for j := range idx {
+			idx[j] = sort.SearchFloat64s(cum, rnd.Float64()*sum)
+			// a zero draw may fall on leading zero weights
+			for weight[idx[j]] == 0 {
+				idx[j]++
+			}
+		}
+	case replace:
+		for j := range idx {
+			idx[j] = rnd.Intn(n)
+		}
+	default:
+		copy(idx, rnd.Perm(n)[:k])
+	}
+	return idx
+}
+
+// subMatrix returns the rows and columns of X in rows and cols, nil meaning all
+func subMatrix(X *mat.Dense, rows, cols []int) *mat.Dense {
+	if rows == nil && cols == nil {
+		return X
+	}
+	r, c := X.Dims()
+	if rows != nil {
+		r = len(rows)
+	}
+	if cols != nil {
+		c = len(cols)
+	}
+	S := mat.NewDense(r, c, nil)
+	for i := 0; i < r; i++ {
+		src := i
+		if rows != nil {
+			src = rows[i]
+		}
+		x := X.RawRowView(src)
+		dst := S.RawRowView(i)
+		if cols == nil {
+			copy(dst, x)
+			continue
+		}
+		for j, f := range cols {
+			dst[j] = x[f]
+		}
+	}
+	return S
+}
+
+// setEstimatorRandomState sets the RandomState parameter of est to src, if it has one
+func setEstimatorRandomState(est base.Predicter, src base.RandomState) {
+	if _, ok := base.GetParams(est)["RandomState"]; ok {
+		if err := base.SetParams(est, map[string]interface{}{"RandomState": src}); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// fitWeightedOrResampled fits est with sampleWeight if it is a base.WeightedFiter. else est is fitted on all the samples
+// if sampleWeight is uniform, or on NSamples samples drawn with replacement with probabilities proportional to sampleWeight
+func fitWeightedOrResampled(est base.Predicter, X, Y *mat.Dense, sampleWeight []float64, rnd *rand.Rand) {
+	if wf, ok := est.(base.WeightedFiter); ok {
+		wf.FitWeighted(X, Y, sampleWeight)
+		return
+	}
+	if base.IsUniformWeight(sampleWeight) {
+		est.Fit(X, Y)
+		return
+	}
+	nSamples, _ := X.Dims()
+	idx := draw(rnd, nSamples, nSamples, true, sampleWeight)
+	est.Fit(subMatrix(X, idx, nil), subMatrix(Y, idx, nil))
+}
+
+// estimatorProba returns the PredictProba of est if it is a base.ProbaPredicter which has a column per class of classes.
+// else it returns the one-hot encoding of the classes predicted by est, as estimators fitted on samples lacking some classes do
+func estimatorProba(est base.Predicter, X *mat.Dense, classes [][]float64) *mat.Dense {
+	nValues := 0
+	for _, cl := range classes {
+		nValues += len(cl)
+	}
+	if pp, ok := est.(base.ProbaPredicter); ok {
+		P := pp.PredictProba(X, nil)
+		if _, c := P.Dims(); c == nValues {
+			return P
+		}
+	}
+	return oneHot(est.Predict(X, nil), classes)
+}
+
+// oneHot returns a column per class of classes, set to 1 for the class in Y of each sample and output
+func oneHot(Y *mat.Dense, classes [][]float64) *mat.Dense {
+	nValues := 0
+	for _, cl := range classes {
+		nValues += len(cl)
+	}
+	nSamples, _ := Y.Dims()
+	P := mat.NewDense(nSamples, nValues, nil)
+	for i := 0; i < nSamples; i++ {
+		p := P.RawRowView(i)
+		for o, cl := range classes {
+			y := Y.At(i, o)
+			if c := sort.SearchFloat64s(cl, y); c < len(cl) && cl[c] == y {
+				p[c] = 1
+			}
+			p = p[len(cl):]
+		}
+	}
+	return P
+}
+
+// uniqueSorted returns the distinct values of a, sorted in place
+func uniqueSorted(a []float64) []float64 {
+	sort.Float64s(a)
+	u := a[:0]
+	for _, v := range a {
+		if len(u) == 0 || v != u[len(u)-1] {
+			u = append(u, v)
+		}
+	}
+	return u
+}
+
+// fit fits a clone of est per estimator. it returns the number of times each sample was drawn for each estimator if OOBScore is set
+func (m *BaseBagging) fit(X, Y *mat.Dense, sampleWeight []float64, est base.Predicter) (inBag [][]int) {
+	if err := base.CheckSampleWeight(X, sampleWeight); err != nil {
+		panic(err)
+	}
+	nEstimators := m.NEstimators
+	if nEstimators == 0 {
+		nEstimators = 10
+	}
+	if nEstimators < 0 {
+		panic(fmt.Errorf("%w: NEstimators must be >= 0, got %d", base.ErrInvalidParam, m.NEstimators))
+	}
+	if m.OOBScore && !m.Bootstrap {
+		panic(fmt.Errorf("%w: OOBScore requires Bootstrap", base.ErrInvalidParam))
+	}
+	nSamples, nFeatures := X.Dims()
+	nDrawnSamples := drawCount("MaxSamples", m.MaxSamples, nSamples)
+	nDrawnFeatures := drawCount("MaxFeatures", m.MaxFeatures, nFeatures)
+	sources := newSources(m.RandomState, nEstimators)
+	estimators := make([]base.Predicter, nEstimators)
+	features := make([][]int, nEstimators)
+	if m.OOBScore {
+		inBag = make([][]int, nEstimators)
+	}
+	_, weighted := est.(base.WeightedFiter)
+	parallelFit(m.NJobs, nEstimators, func(t int) {
+		rnd := rand.New(sources[t])
+		if nDrawnFeatures < nFeatures || m.BootstrapFeatures {
+			features[t] = draw(rnd, nFeatures, nDrawnFeatures, m.BootstrapFeatures, nil)
+			sort.Ints(features[t])
+		}
+		var w []float64
+		if !weighted && !base.IsUniformWeight(sampleWeight) {
+			w = sampleWeight
+		}
+		var rows []int
+		if nDrawnSamples < nSamples || m.Bootstrap || w != nil {
+			rows = draw(rnd, nSamples, nDrawnSamples, m.Bootstrap, w)
+		}
+		estimators[t] = est.PredicterClone()
+		setEstimatorRandomState(estimators[t], base.NewSource(rnd.Uint64()))
+		counts := make([]int, nSamples)
+		if rows == nil {
+			for i := range counts {
+				counts[i] = 1
+			}
+		}
+		for _, i := range rows {
+			counts[i]++
+		}
+		if inBag != nil {
+			inBag[t] = counts
+		}
+		if weighted {
+			w = make([]float64, nSamples)
+			for i, c := range counts {
+				w[i] = float64(c) * sampleWeightAt(sampleWeight, i)
+			}
+			estimators[t].(base.WeightedFiter).FitWeighted(subMatrix(X, nil, features[t]), Y, w)
+			return
+		}
+		estimators[t].Fit(subMatrix(X, rows, features[t]), subMatrix(Y, rows, nil))
+	})
+	m.NFeatures = nFeatures
+	m.Estimators, m.EstimatorsFeatures = estimators, features
+	return
+}
+
+// meanEstimatorsPredictions sets Y to the mean of predict applied to each estimator and the features it was fitted on
+func (m *BaseBagging) meanEstimatorsPredictions(X, Y *mat.Dense, predict func(est base.Predicter, X, dst *mat.Dense)) {
+	meanPredictions(m.NJobs, len(m.Estimators), Y, func(t int, dst *mat.Dense) {
+		predict(m.Estimators[t], subMatrix(X, nil, m.EstimatorsFeatures[t]), dst)
+	})
+}
+
+// oobPredictions returns the mean of the predictions of each sample by the estimators it was not drawn for, and the number of such estimators
+func (m *BaseBagging) oobPredictions(X *mat.Dense, nValues int, inBag [][]int, predict func(est base.Predicter, X *mat.Dense) *mat.Dense) (*mat.Dense, []int) {
+	nSamples, _ := X.Dims()
+	P := mat.NewDense(nSamples, nValues, nil)
+	nOOB := make([]int, nSamples)
+	for t, est := range m.Estimators {
+		var rows []int
+		for i, count := range inBag[t] {
+			if count == 0 {
+				rows = append(rows, i)
+			}
+		}
+		if rows == nil {
+			continue
+		}
+		Pt := predict(est, subMatrix(X, rows, m.EstimatorsFeatures[t]))
+		for j, i := range rows {
+			nOOB[i]++
+			p := P.RawRowView(i)
+			for c, v := range Pt.RawRowView(j) {
+				p[c] += v
+			}
+		}
+	}
+	for i, n := range nOOB {
+		if n > 0 {
+			p := P.RawRowView(i)
+			for c := range p {
+				p[c] /= float64(n)
+			}
+		}
+	}
+	return P, nOOB
+}
+
+// BaggingClassifier is a bagging of classifiers whose predicted probabilities are averaged. estimators which are not a base.ProbaPredicter,
+// or which were fitted on samples lacking some classes, vote for their predicted classes. Estimator defaults to a DecisionTreeClassifier
+type BaggingClassifier struct {
+	BaseBagging
+	// runtime filled members
+	Classes [][]float64 `param:"-"`
+	// OOBDecisionFunction is the out-of-bag PredictProba of each training sample, when OOBScore is set
+	OOBDecisionFunction *mat.Dense `param:"-"`
+}
+
+// NewBaggingClassifier returns a BaggingClassifier of 10 DecisionTreeClassifier with bootstrap
+func NewBaggingClassifier() *BaggingClassifier {
+	return &BaggingClassifier{BaseBagging: BaseBagging{Estimator: tree.NewDecisionTreeClassifier(), NEstimators: 10, Bootstrap: true}}
+}
+
+// PredicterClone returns an unfitted copy of predicter
+func (m *BaggingClassifier) PredicterClone() base.Predicter {
+	clone := *m
+	if m.Estimator != nil {
+		clone.Estimator = m.Estimator.PredicterClone()
+	}
+	clone.resetFitted()
+	clone.Classes, clone.OOBDecisionFunction = nil, nil
+	return base.DeepCopy(&clone).(*BaggingClassifier)
+}
+
+// IsClassifier returns true for BaggingClassifier
+func (*BaggingClassifier) IsClassifier() bool { return true }
+
+// GetNOutputs returns the number of columns of Y
+func (m *BaggingClassifier) GetNOutputs() int { return len(m.Classes) }
+
+// Fit fits the estimators from X and the class labels Y
+func (m *BaggingClassifier) Fit(X, Y mat.Matrix) base.Fiter {
+	return m.FitWeighted(X, Y, nil)
+}
+
+// FitWeighted is Fit with samples weighted by sampleWeight. see BaseBagging
+func (m *BaggingClassifier) FitWeighted(Xmatrix, Ymatrix mat.Matrix, sampleWeight []float64) base.Fiter {
+	X, Y := base.ToDense(Xmatrix), base.ToDense(Ymatrix)
+	est := m.Estimator
+	if est == nil {
+		est = tree.NewDecisionTreeClassifier()
+	}
+	_, nOutputs := Y.Dims()
+	m.Classes = make([][]float64, nOutputs)
+	for o := range m.Classes {
+		m.Classes[o] = uniqueSorted(mat.Col(nil, o, Y))
+	}
+	inBag := m.fit(X, Y, sampleWeight, est)
+	m.OOBDecisionFunction = nil
+	if inBag != nil {
+		nValues := 0
+		for _, cl := range m.Classes {
+			nValues += len(cl)
+		}
+		P, nOOB := m.oobPredictions(X, nValues, inBag, func(est base.Predicter, X *mat.Dense) *mat.Dense {
+			return estimatorProba(est, X, m.Classes)
+		})
+		m.OOBDecisionFunction = P
+		m.OOBScoreValue = metrics.AccuracyScore(oobRows(Y, nOOB), oobRows(probaToClasses(P, m.Classes), nOOB), true, nil)
+	}
+	return m
+}
+
+// PredictProba returns the mean of the class probabilities of the estimators. see base.ProbaPredicter
+func (m *BaggingClassifier) PredictProba(Xmatrix mat.Matrix, Ymutable mat.Mutable) *mat.Dense {
+	base.MustBeFitted(m)
+	X := base.ToDense(Xmatrix)
+	nSamples, _ := X.Dims()
+	nValues := 0
+	for _, cl := range m.Classes {
+		nValues += len(cl)
+	}
+	P := mat.NewDense(nSamples, nValues, nil)
+	m.meanEstimatorsPredictions(X, P, func(est base.Predicter, X, dst *mat.Dense) {
+		dst.Copy(estimatorProba(est, X, m.Classes))
+	})
+	return base.FromDense(Ymutable, P)
+}
+
+// Predict returns the most probable class of each output
+func (m *BaggingClassifier) Predict(X mat.Matrix, Ymutable mat.Mutable) *mat.Dense {
+	Y := base.ToDense(Ymutable)
+	nSamples, _ := X.Dims()
+	if Y.IsZero() {
+		*Y = *mat.NewDense(nSamples, m.GetNOutputs(), nil)
+	}
+	Y.Copy(probaToClasses(m.PredictProba(X, nil), m.Classes))
+	return base.FromDense(Ymutable, Y)
+}
+
+// FitE is Fit returning an error instead of panicking
+func (m *BaggingClassifier) FitE(X, Y mat.Matrix) error {
+	return base.FitE(m, X, Y)
+}
+
+// PredictE is Predict returning an error instead of panicking
+func (m *BaggingClassifier) PredictE(X mat.Matrix, Y mat.Mutable) (*mat.Dense, error) {
+	if !m.IsFitted() {
+		return nil, base.ErrNotFitted
+	}
+	if err := base.CheckNFeatures(X, m.NFeatures); err != nil {
+		return nil, err
+	}
+	return base.PredictE(m, X, Y)
+}
+
+// Score for BaggingClassifier returns the accuracy of Predict
+func (m *BaggingClassifier) Score(X, Y mat.Matrix) float64 {
+	NSamples, NOutputs := Y.Dims()
+	Ypred := mat.NewDense(NSamples, NOutputs, nil)
+	m.Predict(X, Ypred)
+	return metrics.AccuracyScore(Y, Ypred, true, nil)
+}
+
+// BaggingRegressor is a bagging of regressors whose predictions are averaged. Estimator defaults to a DecisionTreeRegressor
+type BaggingRegressor struct {
+	BaseBagging
+	// runtime filled members
+	NOutputs int `param:"-"`
+	// OOBPrediction is the out-of-bag prediction of each training sample, when OOBScore is set
+	OOBPrediction *mat.Dense `param:"-"`
+}
+
+// NewBaggingRegressor returns a BaggingRegressor of 10 DecisionTreeRegressor with bootstrap
+func NewBaggingRegressor() *BaggingRegressor {
+	return &BaggingRegressor{BaseBagging: BaseBagging{Estimator: tree.NewDecisionTreeRegressor(), NEstimators: 10, Bootstrap: true}}
+}
+
+// PredicterClone returns an unfitted copy of predicter
+func (m *BaggingRegressor) PredicterClone() base.Predicter {
+	clone := *m
+	if m.Estimator != nil {
+		clone.Estimator = m.Estimator.PredicterClone()
+	}
+	clone.resetFitted()
+	clone.NOutputs, clone.OOBPrediction = 0, nil
+	return base.DeepCopy(&clone).(*BaggingRegressor)
+}
+
+// IsClassifier returns false for BaggingRegressor
+func (*BaggingRegressor) IsClassifier() bool { return false }
+
+// GetNOutputs returns the number of columns of Y
+func (m *BaggingRegressor) GetNOutputs() int { return m.NOutputs }
+
+// Fit fits the estimators from X and the targets Y
+func (m *BaggingRegressor) Fit(X, Y mat.Matrix) base.Fiter {
+	return m.FitWeighted(X, Y, nil)
+}
+
+// FitWeighted is Fit with samples weighted by sampleWeight. see BaseBagging
+func (m *BaggingRegressor) FitWeighted(Xmatrix, Ymatrix mat.Matrix, sampleWeight []float64) base.Fiter {
+	X, Y := base.ToDense(Xmatrix), base.ToDense(Ymatrix)
+	est := m.Estimator
+	if est == nil {
+		est = tree.NewDecisionTreeRegressor()
+	}
+	_, m.NOutputs = Y.Dims()
+	inBag := m.fit(X, Y, sampleWeight, est)
+	m.OOBPrediction = nil
+	if inBag != nil {
+		P, nOOB := m.oobPredictions(X, m.NOutputs, inBag, func(est base.Predicter, X *mat.Dense) *mat.Dense {
+			return est.Predict(X, nil)
+		})
+		m.OOBPrediction = P
+		m.OOBScoreValue = metrics.R2Score(oobRows(Y, nOOB), oobRows(P, nOOB), nil, "").At(0, 0)
+	}
+	return m
+}
+
+// Predict returns the mean of the predictions of the estimators
+func (m *BaggingRegressor) Predict(Xmatrix mat.Matrix, Ymutable mat.Mutable) *mat.Dense {
+	base.MustBeFitted(m)
+	X := base.ToDense(Xmatrix)
+	Y := base.ToDense(Ymutable)
+	nSamples, _ := X.Dims()
+	if Y.IsZero() {
+		*Y = *mat.NewDense(nSamples, m.GetNOutputs(), nil)
+	}
+	m.meanEstimatorsPredictions(X, Y, func(est base.Predicter, X, dst *mat.Dense) {
+		est.Predict(X, dst)
+	})
+	return base.FromDense(Ymutable, Y)
+}
+
+// FitE is Fit returning an error instead of panicking
+func (m *BaggingRegressor) FitE(X, Y mat.Matrix) error {
+	return base.FitE(m, X, Y)
+}
+
+// PredictE is Predict returning an error instead of panicking
+func (m *BaggingRegressor) PredictE(X mat.Matrix, Y mat.Mutable) (*mat.Dense, error) {
+	if !m.IsFitted() {
+		return nil, base.ErrNotFitted
+	}
+	if err := base.CheckNFeatures(X, m.NFeatures); err != nil {
+		return nil, err
+	}
+	return base.PredictE(m, X, Y)
+}
+
+// Score for BaggingRegressor returns the R2 score of Predict
+func (m *BaggingRegressor) Score(X, Y mat.Matrix) float64 {
+	NSamples, NOutputs := Y.Dims()
+	Ypred := mat.NewDense(NSamples, NOutputs, nil)
+	m.Predict(X, Ypred)
+	return metrics.R2Score(Y, Ypred, nil, "").At(0, 0)
+}
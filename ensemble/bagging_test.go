@@ -0,0 +1,116 @@
+package ensemble
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/pa-m/sklearn/base"
+	"github.com/pa-m/sklearn/datasets"
+	"github.com/pa-m/sklearn/tree"
+	"gonum.org/v1/gonum/mat"
+)
+
+func ExampleBaggingClassifier() {
+	ds := datasets.LoadIris()
+	clf := NewBaggingClassifier()
+	clf.NEstimators = 50
+	clf.OOBScore = true
+	clf.RandomState = base.NewSource(7)
+	clf.Fit(ds.X, ds.Y)
+	fmt.Printf("oob score: %.2f\n", clf.OOBScoreValue)
+	// Output:
+	// oob score: 0.96
+}
+
+func ExampleBaggingRegressor() {
+	ds := datasets.LoadDiabetes()
+	reg := NewBaggingRegressor()
+	reg.NEstimators = 50
+	reg.MaxFeatures = .5
+	reg.OOBScore = true
+	reg.RandomState = base.NewSource(7)
+	reg.Fit(ds.X, ds.Y)
+	fmt.Printf("oob score: %.2f\n", reg.OOBScoreValue)
+	// Output:
+	// oob score: 0.39
+}
+
+func TestBaggingClassifier(t *testing.T) {
+	ds := datasets.LoadIris()
+	var expected *mat.Dense
+	for _, nJobs := range []int{1, 3} {
+		clf := NewBaggingClassifier()
+		clf.MaxFeatures = 2
+		clf.MaxSamples = .5
+		clf.NJobs = nJobs
+		clf.RandomState = base.NewSource(7)
+		clf.Fit(ds.X, ds.Y)
+		for _, features := range clf.EstimatorsFeatures {
+			if len(features) != 2 {
+				t.Fatalf("expected 2 features, got %v", features)
+			}
+		}
+		P := clf.PredictProba(ds.X, nil)
+		for i := 0; i < 150; i++ {
+			if sum := mat.Sum(P.RowView(i)); math.Abs(sum-1) > 1e-12 {
+				t.Fatalf("probas of sample %d sum to %g", i, sum)
+			}
+		}
+		if expected == nil {
+			expected = P
+		} else if !mat.EqualApprox(expected, P, 1e-12) {
+			t.Error("baggings fitted with the same RandomState must not depend on NJobs")
+		}
+	}
+
+	// nested parameters of Estimator
+	clf := NewBaggingClassifier()
+	if err := clf.SetParams(map[string]interface{}{"Estimator__MaxDepth": 1}); err != nil {
+		t.Fatal(err)
+	}
+	clf.Fit(ds.X, ds.Y)
+	for _, est := range clf.Estimators {
+		if d := est.(*tree.DecisionTreeClassifier).GetDepth(); d != 1 {
+			t.Fatalf("expected stumps, got depth %d", d)
+		}
+	}
+
+	for _, params := range []map[string]interface{}{
+		{"NEstimators": -1},
+		{"MaxSamples": 0.},
+		{"MaxFeatures": 5},
+		{"MaxFeatures": "sqrt"},
+		{"OOBScore": true, "Bootstrap": false},
+	} {
+		clf := NewBaggingClassifier()
+		if err := clf.SetParams(params); err != nil {
+			t.Fatal(err)
+		}
+		if err := clf.FitE(ds.X, ds.Y); !errors.Is(err, base.ErrInvalidParam) {
+			t.Errorf("%v: expected ErrInvalidParam, got %v", params, err)
+		}
+	}
+}
+
+func TestBaggingClassifier_FitWeighted(t *testing.T) {
+	ds := datasets.LoadIris()
+	w := make([]float64, 150)
+	for i := 0; i < 100; i++ {
+		w[i] = 1
+	}
+	// zero weighted samples of the third class are never drawn for estimators without sample weights
+	for _, est := range []base.Predicter{newStump(), &unweighted{newStump()}} {
+		clf := NewBaggingClassifier()
+		clf.Estimator = est
+		clf.RandomState = base.NewSource(7)
+		clf.FitWeighted(ds.X, ds.Y, w)
+		Y := clf.Predict(ds.X, nil)
+		for i := 0; i < 150; i++ {
+			if Y.At(i, 0) == 2 {
+				t.Fatalf("%T: unexpected prediction of a zero weighted class", est)
+			}
+		}
+	}
+}
@@ -0,0 +1,5 @@
+// Package ensemble implements ensembles of estimators. it contains RandomForestClassifier, RandomForestRegressor, ExtraTreesClassifier and ExtraTreesRegressor,
+// GradientBoostingClassifier and GradientBoostingRegressor, and their histogram-based variants HistGradientBoostingClassifier and HistGradientBoostingRegressor.
+// AdaBoostClassifier, AdaBoostRegressor, BaggingClassifier and BaggingRegressor are meta-estimators over any base.Predicter.
+// VotingClassifier, VotingRegressor, StackingClassifier and StackingRegressor combine named estimators
+package ensemble
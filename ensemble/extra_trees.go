@@ -0,0 +1,78 @@
+package ensemble
+
+import (
+	"github.com/pa-m/sklearn/base"
+	"gonum.org/v1/gonum/mat"
+)
+
+// ExtraTreesClassifier is a RandomForestClassifier of extremely randomized trees, whose splits use a random threshold per feature.
+// its trees are grown on the whole training set unless Bootstrap is set
+type ExtraTreesClassifier struct {
+	RandomForestClassifier
+}
+
+// NewExtraTreesClassifier returns an ExtraTreesClassifier of 100 trees without bootstrap
+func NewExtraTreesClassifier() *ExtraTreesClassifier {
+	m := &ExtraTreesClassifier{RandomForestClassifier: *NewRandomForestClassifier()}
+	m.Bootstrap = false
+	return m
+}
+
+// PredicterClone returns an unfitted copy of predicter
+func (m *ExtraTreesClassifier) PredicterClone() base.Predicter {
+	clone := *m
+	clone.resetFitted()
+	return base.DeepCopy(&clone).(*ExtraTreesClassifier)
+}
+
+// Fit grows the trees from X and the class labels Y
+func (m *ExtraTreesClassifier) Fit(X, Y mat.Matrix) base.Fiter {
+	return m.FitWeighted(X, Y, nil)
+}
+
+// FitWeighted is Fit with the weights of the trees multiplied by sampleWeight
+func (m *ExtraTreesClassifier) FitWeighted(X, Y mat.Matrix, sampleWeight []float64) base.Fiter {
+	m.fit(X, Y, sampleWeight, "random")
+	return m
+}
+
+// FitE is Fit returning an error instead of panicking
+func (m *ExtraTreesClassifier) FitE(X, Y mat.Matrix) error {
+	return base.FitE(m, X, Y)
+}
+
+// ExtraTreesRegressor is a RandomForestRegressor of extremely randomized trees, whose splits use a random threshold per feature.
+// its trees are grown on the whole training set unless Bootstrap is set
+type ExtraTreesRegressor struct {
+	RandomForestRegressor
+}
+
+// NewExtraTreesRegressor returns an ExtraTreesRegressor of 100 trees without bootstrap
+func NewExtraTreesRegressor() *ExtraTreesRegressor {
+	m := &ExtraTreesRegressor{RandomForestRegressor: *NewRandomForestRegressor()}
+	m.Bootstrap = false
+	return m
+}
+
+// PredicterClone returns an unfitted copy of predicter
+func (m *ExtraTreesRegressor) PredicterClone() base.Predicter {
+	clone := *m
+	clone.resetFitted()
+	return base.DeepCopy(&clone).(*ExtraTreesRegressor)
+}
+
+// Fit grows the trees from X and the targets Y
+func (m *ExtraTreesRegressor) Fit(X, Y mat.Matrix) base.Fiter {
+	return m.FitWeighted(X, Y, nil)
+}
+
+// FitWeighted is Fit with the weights of the trees multiplied by sampleWeight
+func (m *ExtraTreesRegressor) FitWeighted(X, Y mat.Matrix, sampleWeight []float64) base.Fiter {
+	m.fit(X, Y, sampleWeight, "random")
+	return m
+}
+
+// FitE is Fit returning an error instead of panicking
+func (m *ExtraTreesRegressor) FitE(X, Y mat.Matrix) error {
+	return base.FitE(m, X, Y)
+}
@@ -0,0 +1,52 @@
+package ensemble
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/pa-m/sklearn/base"
+	"github.com/pa-m/sklearn/datasets"
+	"gonum.org/v1/gonum/mat"
+)
+
+func ExampleExtraTreesClassifier() {
+	ds := datasets.LoadBreastCancer()
+	clf := NewExtraTreesClassifier()
+	clf.NEstimators = 20
+	clf.RandomState = base.NewSource(7)
+	clf.Fit(ds.X, ds.Y)
+	fmt.Printf("accuracy: %.2f\n", clf.Score(ds.X, ds.Y))
+	// Output:
+	// accuracy: 1.00
+}
+
+func TestExtraTreesRegressor(t *testing.T) {
+	X := mat.NewDense(50, 1, nil)
+	Y := mat.NewDense(50, 1, nil)
+	for i := 0; i < 50; i++ {
+		X.Set(i, 0, float64(i))
+		Y.Set(i, 0, float64(i/10))
+	}
+	reg := NewExtraTreesRegressor()
+	reg.NEstimators = 10
+	reg.RandomState = base.NewSource(7)
+	reg.Fit(X, Y)
+	if score := reg.Score(X, Y); score < .999 {
+		t.Errorf("fully grown extra trees must fit the training set, got %g", score)
+	}
+	// thresholds are drawn at random instead of at mid-points between samples
+	random := false
+	for _, est := range reg.Estimators {
+		th := est.Tree.Threshold[0]
+		if th != float64(int(th))+.5 {
+			random = true
+		}
+	}
+	if !random {
+		t.Error("expected random thresholds")
+	}
+	clone := reg.PredicterClone().(*ExtraTreesRegressor)
+	if clone.IsFitted() || clone.Bootstrap {
+		t.Error("unexpected clone state")
+	}
+}
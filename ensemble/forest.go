@@ -0,0 +1,508 @@
+package ensemble
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"golang.org/x/exp/rand"
+
+	"github.com/pa-m/sklearn/base"
+	"github.com/pa-m/sklearn/metrics"
+	"github.com/pa-m/sklearn/tree"
+	"gonum.org/v1/gonum/mat"
+)
+
+// BaseForest holds the parameters shared by forests of decision trees. tree parameters are those of tree.BaseDecisionTree.
+// NEstimators is the number of trees, 0 means 100.
+// if Bootstrap is set, each tree is grown on a sample of NSamples samples drawn with replacement, and OOBScore computes the score of
+// each sample predicted by the trees it was not drawn for.
+// trees are grown in parallel by NJobs threads, NJobs <= 0 meaning runtime.NumCPU(). RandomState draws the seed of each tree
+type BaseForest struct {
+	NEstimators         int
+	Criterion           string
+	MaxDepth            int
+	MinSamplesSplit     int
+	MinSamplesLeaf      int
+	MaxFeatures         interface{}
+	MinImpurityDecrease float64
+	Bootstrap           bool
+	OOBScore            bool
+	NJobs               int
+	RandomState         base.RandomState
+	// runtime filled members
+	NFeatures          int       `param:"-"`
+	FeatureImportances []float64 `param:"-"`
+	// OOBScoreValue is the accuracy or the R2 score of out-of-bag predictions, when OOBScore is set
+	OOBScoreValue float64 `param:"-"`
+}
+
+// newTree returns the parameters of a tree grown with splitter and src
+func (m *BaseForest) newTree(splitter string, src base.RandomState) tree.BaseDecisionTree {
+	return tree.BaseDecisionTree{
+		Criterion:           m.Criterion,
+		Splitter:            splitter,
+		MaxDepth:            m.MaxDepth,
+		MinSamplesSplit:     m.MinSamplesSplit,
+		MinSamplesLeaf:      m.MinSamplesLeaf,
+		MaxFeatures:         m.MaxFeatures,
+		MinImpurityDecrease: m.MinImpurityDecrease,
+		RandomState:         src,
+	}
+}
+
+// sources returns the RandomState of each tree, seeded from RandomState
+func (m *BaseForest) sources() []base.RandomState {
+	nEstimators := m.NEstimators
+	if nEstimators == 0 {
+		nEstimators = 100
+	}
+	if nEstimators < 0 {
+		panic(fmt.Errorf("%w: NEstimators must be >= 0, got %d", base.ErrInvalidParam, m.NEstimators))
+	}
+	if m.OOBScore && !m.Bootstrap {
+		panic(fmt.Errorf("%w: OOBScore requires Bootstrap", base.ErrInvalidParam))
+	}
+	return newSources(m.RandomState, nEstimators)
+}
+
+// newSources returns n sources seeded from randomState, or from a time-seeded source if it is nil
+func newSources(randomState base.RandomState, n int) []base.RandomState {
+	if randomState == nil {
+		randomState = base.NewLockedSource(uint64(time.Now().UnixNano()))
+	}
+	sources := make([]base.RandomState, n)
+	for i := range sources {
+		sources[i] = base.NewSource(randomState.Uint64())
+	}
+	return sources
+}
+
+// grow calls fit for each tree in parallel. if Bootstrap is set, the weights passed to fit are those of a bootstrap sample drawn from
+// the tree source, multiplied by sampleWeight. it returns the number of times each sample was drawn for each tree if OOBScore is set
+func (m *BaseForest) grow(nSamples int, sampleWeight []float64, sources []base.RandomState, fit func(t int, w []float64)) (inBag [][]int) {
+	if m.OOBScore {
+		inBag = make([][]int, len(sources))
+	}
+	parallelFit(m.NJobs, len(sources), func(t int) {
+		w := sampleWeight
+		if m.Bootstrap {
+			rnd := rand.New(sources[t])
+			counts := make([]int, nSamples)
+			for i := 0; i < nSamples; i++ {
+				counts[rnd.Intn(nSamples)]++
+			}
+			w = make([]float64, nSamples)
+			for i, c := range counts {
+				w[i] = float64(c) * sampleWeightAt(sampleWeight, i)
+			}
+			if inBag != nil {
+				inBag[t] = counts
+			}
+		}
+		fit(t, w)
+	})
+	return
+}
+
+// parallelFit calls fit for each of n estimators in parallel by nJobs threads. a panic in fit is raised again in the calling goroutine
+func parallelFit(nJobs, n int, fit func(t int)) {
+	var (
+		mu       sync.Mutex
+		firstErr error
+	)
+	base.Parallelize(nJobs, n, func(th, start, end int) {
+		var err error
+		defer func() {
+			if err != nil {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = err
+				}
+				mu.Unlock()
+			}
+		}()
+		defer base.Recover(&err)
+		for t := start; t < end; t++ {
+			fit(t)
+		}
+	})
+	if firstErr != nil {
+		panic(firstErr)
+	}
+}
+
+// setFeatureImportances sets FeatureImportances to the mean of the importances of the trees, normalized to sum to 1
+func (m *BaseForest) setFeatureImportances(importances func(t int) []float64, nTrees int) {
+	m.FeatureImportances = make([]float64, m.NFeatures)
+	sum := 0.
+	for t := 0; t < nTrees; t++ {
+		for f, v := range importances(t) {
+			m.FeatureImportances[f] += v
+			sum += v
+		}
+	}
+	if sum > 0 {
+		for f := range m.FeatureImportances {
+			m.FeatureImportances[f] /= sum
+		}
+	}
+}
+
+// sampleWeightAt returns the weight of sample i, or 1 if sampleWeight is nil
+func sampleWeightAt(sampleWeight []float64, i int) float64 {
+	if sampleWeight == nil {
+		return 1
+	}
+	return sampleWeight[i]
+}
+
+// oobRows returns the rows of M of the samples predicted by at least one tree, given nOOB the number of such trees per sample
+func oobRows(M mat.Matrix, nOOB []int) *mat.Dense {
+	_, c := M.Dims()
+	var data []float64
+	for i, n := range nOOB {
+		if n > 0 {
+			data = append(data, mat.Row(nil, i, M)...)
+		}
+	}
+	if data == nil {
+		panic(fmt.Errorf("%w: too few trees for each sample to have out-of-bag predictions", base.ErrInvalidParam))
+	}
+	return mat.NewDense(len(data)/c, c, data)
+}
+
+// RandomForestClassifier is a forest of DecisionTreeClassifier whose predicted probabilities are averaged.
+// it has the default parameters of DecisionTreeClassifier, except MaxFeatures which is "sqrt"
+type RandomForestClassifier struct {
+	BaseForest
+	// runtime filled members
+	Estimators []*tree.DecisionTreeClassifier `param:"-"`
+	Classes    [][]float64                    `param:"-"`
+	// OOBDecisionFunction is the out-of-bag PredictProba of each training sample, when OOBScore is set
+	OOBDecisionFunction *mat.Dense `param:"-"`
+}
+
+// NewRandomForestClassifier returns a RandomForestClassifier of 100 trees with bootstrap
+func NewRandomForestClassifier() *RandomForestClassifier {
+	return &RandomForestClassifier{BaseForest: BaseForest{NEstimators: 100, Criterion: "gini", MinSamplesSplit: 2, MinSamplesLeaf: 1, MaxFeatures: "sqrt", Bootstrap: true}}
+}
+
+// PredicterClone returns an unfitted copy of predicter
+func (m *RandomForestClassifier) PredicterClone() base.Predicter {
+	clone := *m
+	clone.resetFitted()
+	return base.DeepCopy(&clone).(*RandomForestClassifier)
+}
+
+func (m *RandomForestClassifier) resetFitted() {
+	m.NFeatures, m.FeatureImportances, m.OOBScoreValue = 0, nil, 0
+	m.Estimators, m.Classes, m.OOBDecisionFunction = nil, nil, nil
+}
+
+// IsFitted returns true when the trees have been grown
+func (m *RandomForestClassifier) IsFitted() bool { return m.Estimators != nil }
+
+// IsClassifier returns true for RandomForestClassifier
+func (*RandomForestClassifier) IsClassifier() bool { return true }
+
+// GetNOutputs returns the number of columns of Y
+func (m *RandomForestClassifier) GetNOutputs() int { return len(m.Classes) }
+
+// Fit grows the trees from X and the class labels Y
+func (m *RandomForestClassifier) Fit(X, Y mat.Matrix) base.Fiter {
+	return m.FitWeighted(X, Y, nil)
+}
+
+// FitWeighted is Fit with the bootstrap weights of the trees multiplied by sampleWeight
+func (m *RandomForestClassifier) FitWeighted(X, Y mat.Matrix, sampleWeight []float64) base.Fiter {
+	m.fit(X, Y, sampleWeight, "best")
+	return m
+}
+
+func (m *RandomForestClassifier) fit(Xmatrix, Ymatrix mat.Matrix, sampleWeight []float64, splitter string) {
+	X, Y := base.ToDense(Xmatrix), base.ToDense(Ymatrix)
+	if err := base.CheckSampleWeight(X, sampleWeight); err != nil {
+		panic(err)
+	}
+	sources := m.sources()
+	estimators := make([]*tree.DecisionTreeClassifier, len(sources))
+	for t, src := range sources {
+		estimators[t] = &tree.DecisionTreeClassifier{BaseDecisionTree: m.newTree(splitter, src)}
+	}
+	nSamples, nFeatures := X.Dims()
+	inBag := m.grow(nSamples, sampleWeight, sources, func(t int, w []float64) {
+		estimators[t].FitWeighted(X, Y, w)
+	})
+	m.NFeatures = nFeatures
+	m.Estimators, m.Classes = estimators, estimators[0].Classes
+	m.setFeatureImportances(func(t int) []float64 { return estimators[t].FeatureImportances }, len(estimators))
+	m.OOBDecisionFunction = nil
+	if inBag != nil {
+		m.setOOBScore(X, Y, inBag)
+	}
+}
+
+func (m *RandomForestClassifier) setOOBScore(X, Y *mat.Dense, inBag [][]int) {
+	nSamples, _ := X.Dims()
+	nOOB := make([]int, nSamples)
+	var P *mat.Dense
+	for t, est := range m.Estimators {
+		Pt := est.PredictProba(X, nil)
+		if P == nil {
+			_, nValues := Pt.Dims()
+			P = mat.NewDense(nSamples, nValues, nil)
+		}
+		for i, count := range inBag[t] {
+			if count == 0 {
+				nOOB[i]++
+				row := P.RawRowView(i)
+				for c, v := range Pt.RawRowView(i) {
+					row[c] += v
+				}
+			}
+		}
+	}
+	for i, n := range nOOB {
+		if n > 0 {
+			row := P.RawRowView(i)
+			for c := range row {
+				row[c] /= float64(n)
+			}
+		}
+	}
+	m.OOBDecisionFunction = P
+	Ypred := probaToClasses(P, m.Classes)
+	m.OOBScoreValue = metrics.AccuracyScore(oobRows(Y, nOOB), oobRows(Ypred, nOOB), true, nil)
+}
+
+// probaToClasses returns the most probable class of each output, given the classes of each output
+func probaToClasses(P *mat.Dense, classes [][]float64) *mat.Dense {
+	nSamples, _ := P.Dims()
+	Y := mat.NewDense(nSamples, len(classes), nil)
+	for i := 0; i < nSamples; i++ {
+		p := P.RawRowView(i)
+		for o, cl := range classes {
+			best := 0
+			for c := range cl {
+				if p[c] > p[best] {
+					best = c
+				}
+			}
+			Y.Set(i, o, cl[best])
+			p = p[len(cl):]
+		}
+	}
+	return Y
+}
+
+// PredictProba returns the mean of the PredictProba of the trees. see base.ProbaPredicter
+func (m *RandomForestClassifier) PredictProba(Xmatrix mat.Matrix, Ymutable mat.Mutable) *mat.Dense {
+	base.MustBeFitted(m)
+	X := base.ToDense(Xmatrix)
+	nSamples, _ := X.Dims()
+	nValues := 0
+	for _, classes := range m.Classes {
+		nValues += len(classes)
+	}
+	P := mat.NewDense(nSamples, nValues, nil)
+	meanPredictions(m.NJobs, len(m.Estimators), P, func(t int, dst *mat.Dense) {
+		m.Estimators[t].PredictProba(X, dst)
+	})
+	return base.FromDense(Ymutable, P)
+}
+
+// Predict returns the most probable class of each output
+func (m *RandomForestClassifier) Predict(X mat.Matrix, Ymutable mat.Mutable) *mat.Dense {
+	Y := base.ToDense(Ymutable)
+	nSamples, _ := X.Dims()
+	if Y.IsZero() {
+		*Y = *mat.NewDense(nSamples, m.GetNOutputs(), nil)
+	}
+	Y.Copy(probaToClasses(m.PredictProba(X, nil), m.Classes))
+	return base.FromDense(Ymutable, Y)
+}
+
+// FitE is Fit returning an error instead of panicking
+func (m *RandomForestClassifier) FitE(X, Y mat.Matrix) error {
+	return base.FitE(m, X, Y)
+}
+
+// PredictE is Predict returning an error instead of panicking
+func (m *RandomForestClassifier) PredictE(X mat.Matrix, Y mat.Mutable) (*mat.Dense, error) {
+	if !m.IsFitted() {
+		return nil, base.ErrNotFitted
+	}
+	if err := base.CheckNFeatures(X, m.NFeatures); err != nil {
+		return nil, err
+	}
+	return base.PredictE(m, X, Y)
+}
+
+// Score for RandomForestClassifier returns the accuracy of Predict
+func (m *RandomForestClassifier) Score(X, Y mat.Matrix) float64 {
+	NSamples, NOutputs := Y.Dims()
+	Ypred := mat.NewDense(NSamples, NOutputs, nil)
+	m.Predict(X, Ypred)
+	return metrics.AccuracyScore(Y, Ypred, true, nil)
+}
+
+// RandomForestRegressor is a forest of DecisionTreeRegressor whose predictions are averaged.
+// it has the default parameters of DecisionTreeRegressor
+type RandomForestRegressor struct {
+	BaseForest
+	// runtime filled members
+	Estimators []*tree.DecisionTreeRegressor `param:"-"`
+	NOutputs   int                           `param:"-"`
+	// OOBPrediction is the out-of-bag prediction of each training sample, when OOBScore is set
+	OOBPrediction *mat.Dense `param:"-"`
+}
+
+// NewRandomForestRegressor returns a RandomForestRegressor of 100 trees with bootstrap
+func NewRandomForestRegressor() *RandomForestRegressor {
+	return &RandomForestRegressor{BaseForest: BaseForest{NEstimators: 100, Criterion: "mse", MinSamplesSplit: 2, MinSamplesLeaf: 1, Bootstrap: true}}
+}
+
+// PredicterClone returns an unfitted copy of predicter
+func (m *RandomForestRegressor) PredicterClone() base.Predicter {
+	clone := *m
+	clone.resetFitted()
+	return base.DeepCopy(&clone).(*RandomForestRegressor)
+}
+
+func (m *RandomForestRegressor) resetFitted() {
+	m.NFeatures, m.FeatureImportances, m.OOBScoreValue = 0, nil, 0
+	m.Estimators, m.NOutputs, m.OOBPrediction = nil, 0, nil
+}
+
+// IsFitted returns true when the trees have been grown
+func (m *RandomForestRegressor) IsFitted() bool { return m.Estimators != nil }
+
+// IsClassifier returns false for RandomForestRegressor
+func (*RandomForestRegressor) IsClassifier() bool { return false }
+
+// GetNOutputs returns the number of columns of Y
+func (m *RandomForestRegressor) GetNOutputs() int { return m.NOutputs }
+
+// Fit grows the trees from X and the targets Y
+func (m *RandomForestRegressor) Fit(X, Y mat.Matrix) base.Fiter {
+	return m.FitWeighted(X, Y, nil)
+}
+
+// FitWeighted is Fit with the bootstrap weights of the trees multiplied by sampleWeight
+func (m *RandomForestRegressor) FitWeighted(X, Y mat.Matrix, sampleWeight []float64) base.Fiter {
+	m.fit(X, Y, sampleWeight, "best")
+	return m
+}
+
+func (m *RandomForestRegressor) fit(Xmatrix, Ymatrix mat.Matrix, sampleWeight []float64, splitter string) {
+	X, Y := base.ToDense(Xmatrix), base.ToDense(Ymatrix)
+	if err := base.CheckSampleWeight(X, sampleWeight); err != nil {
+		panic(err)
+	}
+	sources := m.sources()
+	estimators := make([]*tree.DecisionTreeRegressor, len(sources))
+	for t, src := range sources {
+		estimators[t] = &tree.DecisionTreeRegressor{BaseDecisionTree: m.newTree(splitter, src)}
+	}
+	nSamples, nFeatures := X.Dims()
+	inBag := m.grow(nSamples, sampleWeight, sources, func(t int, w []float64) {
+		estimators[t].FitWeighted(X, Y, w)
+	})
+	m.NFeatures = nFeatures
+	m.Estimators = estimators
+	_, m.NOutputs = Y.Dims()
+	m.setFeatureImportances(func(t int) []float64 { return estimators[t].FeatureImportances }, len(estimators))
+	m.OOBPrediction = nil
+	if inBag != nil {
+		m.setOOBScore(X, Y, inBag)
+	}
+}
+
+func (m *RandomForestRegressor) setOOBScore(X, Y *mat.Dense, inBag [][]int) {
+	nSamples, _ := X.Dims()
+	nOOB := make([]int, nSamples)
+	P := mat.NewDense(nSamples, m.NOutputs, nil)
+	for t, est := range m.Estimators {
+		Pt := est.Predict(X, nil)
+		for i, count := range inBag[t] {
+			if count == 0 {
+				nOOB[i]++
+				row := P.RawRowView(i)
+				for o, v := range Pt.RawRowView(i) {
+					row[o] += v
+				}
+			}
+		}
+	}
+	for i, n := range nOOB {
+		if n > 0 {
+			row := P.RawRowView(i)
+			for o := range row {
+				row[o] /= float64(n)
+			}
+		}
+	}
+	m.OOBPrediction = P
+	m.OOBScoreValue = metrics.R2Score(oobRows(Y, nOOB), oobRows(P, nOOB), nil, "").At(0, 0)
+}
+
+// Predict returns the mean of the predictions of the trees
+func (m *RandomForestRegressor) Predict(Xmatrix mat.Matrix, Ymutable mat.Mutable) *mat.Dense {
+	base.MustBeFitted(m)
+	X := base.ToDense(Xmatrix)
+	Y := base.ToDense(Ymutable)
+	nSamples, _ := X.Dims()
+	if Y.IsZero() {
+		*Y = *mat.NewDense(nSamples, m.GetNOutputs(), nil)
+	}
+	meanPredictions(m.NJobs, len(m.Estimators), Y, func(t int, dst *mat.Dense) {
+		m.Estimators[t].Predict(X, dst)
+	})
+	return base.FromDense(Ymutable, Y)
+}
+
+// FitE is Fit returning an error instead of panicking
+func (m *RandomForestRegressor) FitE(X, Y mat.Matrix) error {
+	return base.FitE(m, X, Y)
+}
+
+// PredictE is Predict returning an error instead of panicking
+func (m *RandomForestRegressor) PredictE(X mat.Matrix, Y mat.Mutable) (*mat.Dense, error) {
+	if !m.IsFitted() {
+		return nil, base.ErrNotFitted
+	}
+	if err := base.CheckNFeatures(X, m.NFeatures); err != nil {
+		return nil, err
+	}
+	return base.PredictE(m, X, Y)
+}
+
+// Score for RandomForestRegressor returns the R2 score of Predict
+func (m *RandomForestRegressor) Score(X, Y mat.Matrix) float64 {
+	NSamples, NOutputs := Y.Dims()
+	Ypred := mat.NewDense(NSamples, NOutputs, nil)
+	m.Predict(X, Ypred)
+	return metrics.R2Score(Y, Ypred, nil, "").At(0, 0)
+}
+
+// meanPredictions sets Y to the mean of the predictions of nEstimators estimators, computed in parallel by predict
+func meanPredictions(nJobs, nEstimators int, Y *mat.Dense, predict func(t int, dst *mat.Dense)) {
+	r, c := Y.Dims()
+	sums := make([]*mat.Dense, nEstimators)
+	base.Parallelize(nJobs, nEstimators, func(th, start, end int) {
+		sum, tmp := mat.NewDense(r, c, nil), mat.NewDense(r, c, nil)
+		for t := start; t < end; t++ {
+			predict(t, tmp)
+			sum.Add(sum, tmp)
+		}
+		sums[start] = sum
+	})
+	Y.Zero()
+	for _, sum := range sums {
+		if sum != nil {
+			Y.Add(Y, sum)
+		}
+	}
+	Y.Scale(1/float64(nEstimators), Y)
+}
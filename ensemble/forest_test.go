@@ -0,0 +1,149 @@
+package ensemble
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/pa-m/sklearn/base"
+	"github.com/pa-m/sklearn/datasets"
+	"github.com/pa-m/sklearn/metrics"
+	modelselection "github.com/pa-m/sklearn/model_selection"
+	"github.com/pa-m/sklearn/pipeline"
+	"github.com/pa-m/sklearn/preprocessing"
+	"gonum.org/v1/gonum/mat"
+)
+
+var _ = []base.PredicterE{&RandomForestClassifier{}, &RandomForestRegressor{}, &ExtraTreesClassifier{}, &ExtraTreesRegressor{}}
+var _ = []base.ProbaPredicter{&RandomForestClassifier{}, &ExtraTreesClassifier{}}
+var _ = []base.WeightedFiter{&RandomForestClassifier{}, &RandomForestRegressor{}, &ExtraTreesClassifier{}, &ExtraTreesRegressor{}}
+var _ = []base.FittedChecker{&RandomForestClassifier{}, &RandomForestRegressor{}, &ExtraTreesClassifier{}, &ExtraTreesRegressor{}}
+
+func ExampleRandomForestClassifier() {
+	ds := datasets.LoadIris()
+	clf := NewRandomForestClassifier()
+	clf.OOBScore = true
+	clf.RandomState = base.NewSource(7)
+	clf.Fit(ds.X, ds.Y)
+	fmt.Printf("oob score: %.2f\n", clf.OOBScoreValue)
+	fmt.Printf("importances: %.2f\n", clf.FeatureImportances)
+	// Output:
+	// oob score: 0.95
+	// importances: [0.07 0.02 0.47 0.43]
+}
+
+func ExampleRandomForestRegressor() {
+	ds := datasets.LoadDiabetes()
+	reg := NewRandomForestRegressor()
+	reg.NEstimators = 50
+	reg.OOBScore = true
+	reg.RandomState = base.NewSource(7)
+	reg.Fit(ds.X, ds.Y)
+	fmt.Printf("oob score: %.2f\n", reg.OOBScoreValue)
+	// Output:
+	// oob score: 0.39
+}
+
+func TestRandomForestClassifier(t *testing.T) {
+	ds := datasets.LoadIris()
+	var expected *mat.Dense
+	for _, nJobs := range []int{1, 3} {
+		clf := NewRandomForestClassifier()
+		clf.NEstimators = 20
+		clf.NJobs = nJobs
+		clf.RandomState = base.NewSource(7)
+		clf.Fit(ds.X, ds.Y)
+		if score := clf.Score(ds.X, ds.Y); score < .99 {
+			t.Errorf("unexpected training score %g", score)
+		}
+		P := clf.PredictProba(ds.X, nil)
+		for i := 0; i < 150; i++ {
+			if sum := mat.Sum(P.RowView(i)); math.Abs(sum-1) > 1e-12 {
+				t.Fatalf("probas of sample %d sum to %g", i, sum)
+			}
+		}
+		if expected == nil {
+			expected = P
+		} else if !mat.EqualApprox(expected, P, 1e-12) {
+			t.Error("forests grown with the same RandomState must not depend on NJobs")
+		}
+	}
+
+	for _, params := range []map[string]interface{}{
+		{"NEstimators": -1},
+		{"OOBScore": true, "Bootstrap": false},
+		{"MaxFeatures": "cube"},
+	} {
+		clf := NewRandomForestClassifier()
+		clf.NEstimators = 5
+		if err := clf.SetParams(params); err != nil {
+			t.Fatal(err)
+		}
+		if err := clf.FitE(ds.X, ds.Y); !errors.Is(err, base.ErrInvalidParam) {
+			t.Errorf("%v: expected ErrInvalidParam, got %v", params, err)
+		}
+	}
+}
+
+func TestRandomForestClassifier_FitWeighted(t *testing.T) {
+	X := mat.NewDense(8, 1, []float64{0, 1, 2, 3, 4, 5, 6, 7})
+	Y := mat.NewDense(8, 1, []float64{0, 0, 0, 1, 0, 1, 1, 1})
+	clf := NewRandomForestClassifier()
+	clf.NEstimators = 10
+	clf.Bootstrap = false
+	clf.FitWeighted(X, Y, []float64{1, 1, 1, 0, 0, 1, 1, 1})
+	for _, est := range clf.Estimators {
+		if est.GetNLeaves() != 2 {
+			t.Fatal("zero weighted samples must be ignored")
+		}
+	}
+}
+
+func TestRandomForest_GridSearchCV(t *testing.T) {
+	ds := datasets.LoadIris()
+	clf := NewRandomForestClassifier()
+	clf.NEstimators = 10
+	clf.RandomState = base.NewSource(7)
+	pl := pipeline.MakePipeline(preprocessing.NewStandardScaler(), clf)
+	gscv := &modelselection.GridSearchCV{
+		Estimator: pl,
+		ParamGrid: map[string][]interface{}{"randomforestclassifier__MaxDepth": {1, 3}},
+		Scorer:    func(Ytrue, Ypred mat.Matrix) float64 { return metrics.AccuracyScore(Ytrue, Ypred, true, nil) },
+		CV:        &modelselection.KFold{NSplits: 3, Shuffle: true, RandomState: base.NewSource(7)},
+		NJobs:     1,
+	}
+	gscv.Fit(ds.X, ds.Y)
+	if gscv.BestParams["randomforestclassifier__MaxDepth"] != 3 || gscv.BestScore < .9 {
+		t.Errorf("unexpected best params %v score %g", gscv.BestParams, gscv.BestScore)
+	}
+}
+
+func TestRandomForestRegressor(t *testing.T) {
+	X := mat.NewDense(100, 2, nil)
+	Y := mat.NewDense(100, 2, nil)
+	for i := 0; i < 100; i++ {
+		x := float64(i) / 10
+		X.Set(i, 0, x)
+		X.Set(i, 1, float64(i%7))
+		Y.Set(i, 0, math.Sin(x))
+		Y.Set(i, 1, x*x)
+	}
+	reg := NewRandomForestRegressor()
+	reg.NEstimators = 20
+	reg.OOBScore = true
+	reg.RandomState = base.NewSource(7)
+	reg.Fit(X, Y)
+	if score := reg.Score(X, Y); score < .95 {
+		t.Errorf("unexpected training score %g", score)
+	}
+	if reg.OOBScoreValue < .9 {
+		t.Errorf("unexpected oob score %g", reg.OOBScoreValue)
+	}
+	if r, c := reg.OOBPrediction.Dims(); r != 100 || c != 2 {
+		t.Errorf("unexpected OOBPrediction dims %d,%d", r, c)
+	}
+	if reg.FeatureImportances[0] < .9 {
+		t.Errorf("expected the first feature to prevail, got %v", reg.FeatureImportances)
+	}
+}
@@ -0,0 +1,496 @@
+package ensemble
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"golang.org/x/exp/rand"
+
+	"github.com/pa-m/sklearn/base"
+	"github.com/pa-m/sklearn/metrics"
+	"github.com/pa-m/sklearn/tree"
+	"gonum.org/v1/gonum/mat"
+)
+
+// BaseGradientBoosting holds the parameters shared by gradient boosting estimators.
+// each of the NEstimators stages fits a regression tree to the negative gradient of Loss, whose leaf values are then set to minimize Loss.
+// predictions of the trees are shrunk by LearningRate. if Subsample < 1, each stage is fitted on a fraction of the samples drawn without replacement.
+// tree parameters are those of tree.BaseDecisionTree. Alpha is the quantile of the "huber" loss.
+// if EarlyStopping is set, ValidationFraction of the samples are set aside, and boosting stops when the validation loss has not improved by Tol
+// for NIterNoChange stages, keeping the stages up to the best validation loss. RandomState draws subsamples and the validation set
+type BaseGradientBoosting struct {
+	Loss                string
+	LearningRate        float64
+	NEstimators         int
+	Subsample           float64
+	MaxDepth            int
+	MinSamplesSplit     int
+	MinSamplesLeaf      int
+	MaxFeatures         interface{}
+	MinImpurityDecrease float64
+	Alpha               float64
+	EarlyStopping       bool
+	ValidationFraction  float64
+	NIterNoChange       int
+	Tol                 float64
+	RandomState         base.RandomState
+	// runtime filled members
+	NFeatures int `param:"-"`
+	// InitRaw is the initial raw prediction of each column of the decision function
+	InitRaw []float64 `param:"-"`
+	// Estimators holds the trees of each stage, one per column of the decision function
+	Estimators         [][]*tree.DecisionTreeRegressor `param:"-"`
+	TrainLoss          []float64                       `param:"-"`
+	ValidationLoss     []float64                       `param:"-"`
+	FeatureImportances []float64                       `param:"-"`
+}
+
+func (m *BaseGradientBoosting) resetFitted() {
+	m.NFeatures, m.InitRaw, m.Estimators = 0, nil, nil
+	m.TrainLoss, m.ValidationLoss, m.FeatureImportances = nil, nil, nil
+}
+
+// IsFitted returns true when the stages have been fitted
+func (m *BaseGradientBoosting) IsFitted() bool { return m.Estimators != nil }
+
+// validateBoosting checks the parameters shared by BaseGradientBoosting and BaseHistGradientBoosting
+func validateBoosting(learningRate float64, nIter int, earlyStopping bool, validationFraction float64, nIterNoChange int) {
+	if learningRate <= 0 {
+		panic(fmt.Errorf("%w: LearningRate must be > 0, got %g", base.ErrInvalidParam, learningRate))
+	}
+	if nIter <= 0 {
+		panic(fmt.Errorf("%w: the number of iterations must be > 0, got %d", base.ErrInvalidParam, nIter))
+	}
+	if earlyStopping {
+		if validationFraction <= 0 || validationFraction >= 1 {
+			panic(fmt.Errorf("%w: ValidationFraction must be > 0 and < 1, got %g", base.ErrInvalidParam, validationFraction))
+		}
+		if nIterNoChange <= 0 {
+			panic(fmt.Errorf("%w: NIterNoChange must be > 0, got %d", base.ErrInvalidParam, nIterNoChange))
+		}
+	}
+}
+
+// boostingData holds the training and validation parts of X, y and sampleWeight
+type boostingData struct {
+	X, XVal *mat.Dense
+	y, yVal []float64
+	w, wVal []float64
+}
+
+// splitBoostingData sets aside a random validation set of validationFraction samples if earlyStopping is set
+func splitBoostingData(rnd *rand.Rand, X *mat.Dense, y, sampleWeight []float64, earlyStopping bool, validationFraction float64) boostingData {
+	if !earlyStopping {
+		return boostingData{X: X, y: y, w: sampleWeight}
+	}
+	nSamples, nFeatures := X.Dims()
+	nVal := int(math.Ceil(validationFraction * float64(nSamples)))
+	if nVal >= nSamples {
+		panic(fmt.Errorf("%w: too few samples for a validation set of %d samples", base.ErrInvalidParam, nVal))
+	}
+	perm := rnd.Perm(nSamples)
+	rows := func(idx []int) (*mat.Dense, []float64, []float64) {
+		Xs, ys := mat.NewDense(len(idx), nFeatures, nil), make([]float64, len(idx))
+		var ws []float64
+		if sampleWeight != nil {
+			ws = make([]float64, len(idx))
+		}
+		for j, i := range idx {
+			Xs.SetRow(j, X.RawRowView(i))
+			ys[j] = y[i]
+			if ws != nil {
+				ws[j] = sampleWeight[i]
+			}
+		}
+		return Xs, ys, ws
+	}
+	var d boostingData
+	d.XVal, d.yVal, d.wVal = rows(perm[:nVal])
+	d.X, d.y, d.w = rows(perm[nVal:])
+	return d
+}
+
+// earlyStopper counts the iterations without improvement of the validation loss by tol
+type earlyStopper struct {
+	tol                          float64
+	nIterNoChange                int
+	bestLoss                     float64
+	bestIter, noImprovementCount int
+}
+
+// update returns true when boosting must stop after iteration it of validation loss
+func (s *earlyStopper) update(it int, loss float64) bool {
+	if it == 0 || loss > s.bestLoss-s.tol {
+		s.noImprovementCount++
+	} else {
+		s.noImprovementCount = 0
+	}
+	if it == 0 || loss < s.bestLoss {
+		s.bestLoss, s.bestIter = loss, it
+	}
+	return it > 0 && s.noImprovementCount >= s.nIterNoChange
+}
+
+func newRawPrediction(nSamples int, initRaw []float64) *mat.Dense {
+	raw := mat.NewDense(nSamples, len(initRaw), nil)
+	for i := 0; i < nSamples; i++ {
+		copy(raw.RawRowView(i), initRaw)
+	}
+	return raw
+}
+
+// fit boosts trees on X and y, y holding targets or class indices for classification losses
+func (m *BaseGradientBoosting) fit(X *mat.Dense, y, sampleWeight []float64, loss boostingLoss) {
+	if err := base.CheckSampleWeight(X, sampleWeight); err != nil {
+		panic(err)
+	}
+	validateBoosting(m.LearningRate, m.NEstimators, m.EarlyStopping, m.ValidationFraction, m.NIterNoChange)
+	if m.Subsample <= 0 || m.Subsample > 1 {
+		panic(fmt.Errorf("%w: Subsample must be > 0 and <= 1, got %g", base.ErrInvalidParam, m.Subsample))
+	}
+	src := m.RandomState
+	if src == nil {
+		src = base.NewLockedSource(uint64(time.Now().UnixNano()))
+	}
+	rnd := rand.New(src)
+	d := splitBoostingData(rnd, X, y, sampleWeight, m.EarlyStopping, m.ValidationFraction)
+	nSamples, nFeatures := d.X.Dims()
+	m.resetFitted()
+	m.NFeatures = nFeatures
+	m.InitRaw = loss.initRaw(d.y, d.w)
+	K := len(m.InitRaw)
+	raw := newRawPrediction(nSamples, m.InitRaw)
+	var rawVal *mat.Dense
+	if d.XVal != nil {
+		nVal, _ := d.XVal.Dims()
+		rawVal = newRawPrediction(nVal, m.InitRaw)
+	}
+	grad, hess := make([][]float64, K), make([][]float64, K)
+	for k := range grad {
+		grad[k], hess[k] = make([]float64, nSamples), make([]float64, nSamples)
+	}
+	stopper := earlyStopper{tol: m.Tol, nIterNoChange: m.NIterNoChange}
+	m.Estimators = make([][]*tree.DecisionTreeRegressor, 0, m.NEstimators)
+	for it := 0; it < m.NEstimators; it++ {
+		w := d.w
+		if m.Subsample < 1 {
+			w = make([]float64, nSamples)
+			for _, i := range rnd.Perm(nSamples)[:int(math.Max(1, m.Subsample*float64(nSamples)))] {
+				w[i] = sampleWeightAt(d.w, i)
+			}
+		}
+		for k := 0; k < K; k++ {
+			loss.gradients(d.y, raw, k, d.w, grad[k], hess[k])
+		}
+		stage := make([]*tree.DecisionTreeRegressor, K)
+		leaves := make([][]int, K)
+		for k := 0; k < K; k++ {
+			est := &tree.DecisionTreeRegressor{BaseDecisionTree: tree.BaseDecisionTree{
+				Criterion:           "mse",
+				MaxDepth:            m.MaxDepth,
+				MinSamplesSplit:     m.MinSamplesSplit,
+				MinSamplesLeaf:      m.MinSamplesLeaf,
+				MaxFeatures:         m.MaxFeatures,
+				MinImpurityDecrease: m.MinImpurityDecrease,
+				RandomState:         base.NewSource(rnd.Uint64()),
+			}}
+			residual := mat.NewDense(nSamples, 1, nil)
+			for i, g := range grad[k] {
+				residual.Set(i, 0, -g)
+			}
+			est.FitWeighted(d.X, residual, w)
+			// set leaf values from the in-bag samples of each leaf
+			leaves[k] = make([]int, nSamples)
+			samples := make(map[int][]int)
+			for i := range leaves[k] {
+				leaves[k][i] = est.Tree.Apply(d.X.RawRowView(i))
+				if sampleWeightAt(w, i) > 0 {
+					samples[leaves[k][i]] = append(samples[leaves[k][i]], i)
+				}
+			}
+			for leaf, s := range samples {
+				est.Tree.NodeValue(leaf)[0] = loss.leafValue(d.y, raw, k, s, w, grad[k], hess[k])
+			}
+			stage[k] = est
+		}
+		for k, est := range stage {
+			for i, leaf := range leaves[k] {
+				raw.Set(i, k, raw.At(i, k)+m.LearningRate*est.Tree.NodeValue(leaf)[0])
+			}
+		}
+		m.Estimators = append(m.Estimators, stage)
+		m.TrainLoss = append(m.TrainLoss, loss.loss(d.y, raw, d.w))
+		if rawVal != nil {
+			m.addStage(d.XVal, rawVal, stage)
+			m.ValidationLoss = append(m.ValidationLoss, loss.loss(d.yVal, rawVal, d.wVal))
+			if stopper.update(it, m.ValidationLoss[it]) {
+				break
+			}
+		}
+	}
+	if rawVal != nil {
+		m.Estimators = m.Estimators[:stopper.bestIter+1]
+	}
+	m.setFeatureImportances()
+}
+
+// addStage adds the shrunk predictions of the trees of stage to raw
+func (m *BaseGradientBoosting) addStage(X, raw *mat.Dense, stage []*tree.DecisionTreeRegressor) {
+	nSamples, _ := X.Dims()
+	for k, est := range stage {
+		for i := 0; i < nSamples; i++ {
+			raw.Set(i, k, raw.At(i, k)+m.LearningRate*est.Tree.NodeValue(est.Tree.Apply(X.RawRowView(i)))[0])
+		}
+	}
+}
+
+func (m *BaseGradientBoosting) setFeatureImportances() {
+	m.FeatureImportances = make([]float64, m.NFeatures)
+	sum := 0.
+	for _, stage := range m.Estimators {
+		for _, est := range stage {
+			for f, v := range est.FeatureImportances {
+				m.FeatureImportances[f] += v
+				sum += v
+			}
+		}
+	}
+	if sum > 0 {
+		for f := range m.FeatureImportances {
+			m.FeatureImportances[f] /= sum
+		}
+	}
+}
+
+// rawPredict returns the raw predictions of X, with a column per tree of each stage
+func (m *BaseGradientBoosting) rawPredict(Xmatrix mat.Matrix) *mat.Dense {
+	X := base.ToDense(Xmatrix)
+	nSamples, _ := X.Dims()
+	raw := newRawPrediction(nSamples, m.InitRaw)
+	base.Parallelize(-1, nSamples, func(th, start, end int) {
+		Xs, raws := X.Slice(start, end, 0, m.NFeatures).(*mat.Dense), raw.Slice(start, end, 0, len(m.InitRaw)).(*mat.Dense)
+		for _, stage := range m.Estimators {
+			m.addStage(Xs, raws, stage)
+		}
+	})
+	return raw
+}
+
+// GradientBoostingClassifier is a gradient boosting classifier of a single output. Loss is "log_loss" (or "deviance"),
+// which boosts a tree per stage for binary classification and a tree per class and stage otherwise
+type GradientBoostingClassifier struct {
+	BaseGradientBoosting
+	// runtime filled members
+	Classes []float64 `param:"-"`
+}
+
+// NewGradientBoostingClassifier returns a GradientBoostingClassifier of 100 stages of trees of depth 3, with LearningRate .1
+func NewGradientBoostingClassifier() *GradientBoostingClassifier {
+	return &GradientBoostingClassifier{BaseGradientBoosting: BaseGradientBoosting{
+		Loss: "log_loss", LearningRate: .1, NEstimators: 100, Subsample: 1, MaxDepth: 3, MinSamplesSplit: 2, MinSamplesLeaf: 1,
+		Alpha: .9, ValidationFraction: .1, NIterNoChange: 10, Tol: 1e-4,
+	}}
+}
+
+// PredicterClone returns an unfitted copy of predicter
+func (m *GradientBoostingClassifier) PredicterClone() base.Predicter {
+	clone := *m
+	clone.resetFitted()
+	clone.Classes = nil
+	return base.DeepCopy(&clone).(*GradientBoostingClassifier)
+}
+
+// IsClassifier returns true for GradientBoostingClassifier
+func (*GradientBoostingClassifier) IsClassifier() bool { return true }
+
+// GetNOutputs returns 1
+func (*GradientBoostingClassifier) GetNOutputs() int { return 1 }
+
+// Fit boosts trees from X and the class labels Y
+func (m *GradientBoostingClassifier) Fit(X, Y mat.Matrix) base.Fiter {
+	return m.FitWeighted(X, Y, nil)
+}
+
+// FitWeighted is Fit with the gradients, leaf values and losses weighted by sampleWeight
+func (m *GradientBoostingClassifier) FitWeighted(Xmatrix, Ymatrix mat.Matrix, sampleWeight []float64) base.Fiter {
+	classes, y := classIndices(Ymatrix)
+	m.Classes = classes
+	m.fit(base.ToDense(Xmatrix), y, sampleWeight, newBoostingLoss(m.Loss, len(m.Classes), m.Alpha))
+	return m
+}
+
+// DecisionFunction returns the raw predictions of the stages, the log odds of the second class for binary classification,
+// and a column per class otherwise. see base.DecisionFunctioner
+func (m *GradientBoostingClassifier) DecisionFunction(X mat.Matrix, Ymutable mat.Mutable) *mat.Dense {
+	base.MustBeFitted(m)
+	return base.FromDense(Ymutable, m.rawPredict(X))
+}
+
+// PredictProba returns the probability of each class. see base.ProbaPredicter
+func (m *GradientBoostingClassifier) PredictProba(X mat.Matrix, Ymutable mat.Mutable) *mat.Dense {
+	base.MustBeFitted(m)
+	return base.FromDense(Ymutable, rawToProba(m.rawPredict(X)))
+}
+
+// rawToProba returns the sigmoid of a single column of raw predictions, as the probabilities of 2 classes, or the softmax of its rows
+func rawToProba(raw *mat.Dense) *mat.Dense {
+	if _, K := raw.Dims(); K == 1 {
+		P := mat.DenseCopyOf(raw)
+		P.Apply(func(_, _ int, v float64) float64 { return expit(v) }, P)
+		return base.BinaryProba(P)
+	}
+	base.SoftmaxRows(raw)
+	return raw
+}
+
+// rawToClasses returns the class of the highest raw prediction of each sample
+func rawToClasses(raw *mat.Dense, classes []float64, Y *mat.Dense) {
+	nSamples, K := raw.Dims()
+	for i := 0; i < nSamples; i++ {
+		r := raw.RawRowView(i)
+		best := 0
+		if K == 1 {
+			if r[0] > 0 {
+				best = 1
+			}
+		} else {
+			for c := range r {
+				if r[c] > r[best] {
+					best = c
+				}
+			}
+		}
+		Y.Set(i, 0, classes[best])
+	}
+}
+
+// Predict returns the most probable class of each sample
+func (m *GradientBoostingClassifier) Predict(X mat.Matrix, Ymutable mat.Mutable) *mat.Dense {
+	base.MustBeFitted(m)
+	Y := base.ToDense(Ymutable)
+	nSamples, _ := X.Dims()
+	if Y.IsZero() {
+		*Y = *mat.NewDense(nSamples, 1, nil)
+	}
+	rawToClasses(m.rawPredict(X), m.Classes, Y)
+	return base.FromDense(Ymutable, Y)
+}
+
+// FitE is Fit returning an error instead of panicking
+func (m *GradientBoostingClassifier) FitE(X, Y mat.Matrix) error {
+	return base.FitE(m, X, Y)
+}
+
+// PredictE is Predict returning an error instead of panicking
+func (m *GradientBoostingClassifier) PredictE(X mat.Matrix, Y mat.Mutable) (*mat.Dense, error) {
+	if !m.IsFitted() {
+		return nil, base.ErrNotFitted
+	}
+	if err := base.CheckNFeatures(X, m.NFeatures); err != nil {
+		return nil, err
+	}
+	return base.PredictE(m, X, Y)
+}
+
+// Score for GradientBoostingClassifier returns the accuracy of Predict
+func (m *GradientBoostingClassifier) Score(X, Y mat.Matrix) float64 {
+	NSamples, NOutputs := Y.Dims()
+	Ypred := mat.NewDense(NSamples, NOutputs, nil)
+	m.Predict(X, Ypred)
+	return metrics.AccuracyScore(Y, Ypred, true, nil)
+}
+
+// classIndices returns the sorted classes of the single column of Y, and the index of the class of each sample
+func classIndices(Y mat.Matrix) (classes, y []float64) {
+	nSamples, nOutputs := Y.Dims()
+	if nOutputs != 1 {
+		panic(fmt.Errorf("%w: Y must have a single column, got %d", base.ErrShapeMismatch, nOutputs))
+	}
+	classes = uniqueSorted(mat.Col(nil, 0, Y))
+	y = mat.Col(nil, 0, Y)
+	if len(classes) < 2 {
+		panic(fmt.Errorf("%w: at least 2 classes are needed, got %d", base.ErrInvalidParam, len(classes)))
+	}
+	for i := 0; i < nSamples; i++ {
+		y[i] = float64(sort.SearchFloat64s(classes, y[i]))
+	}
+	return
+}
+
+// GradientBoostingRegressor is a gradient boosting regressor of a single output. Loss is "squared_error" (or "ls"),
+// "absolute_error" (or "lad") or "huber"
+type GradientBoostingRegressor struct {
+	BaseGradientBoosting
+}
+
+// NewGradientBoostingRegressor returns a GradientBoostingRegressor of 100 trees of depth 3 with the squared error loss and LearningRate .1
+func NewGradientBoostingRegressor() *GradientBoostingRegressor {
+	return &GradientBoostingRegressor{BaseGradientBoosting: BaseGradientBoosting{
+		Loss: "squared_error", LearningRate: .1, NEstimators: 100, Subsample: 1, MaxDepth: 3, MinSamplesSplit: 2, MinSamplesLeaf: 1,
+		Alpha: .9, ValidationFraction: .1, NIterNoChange: 10, Tol: 1e-4,
+	}}
+}
+
+// PredicterClone returns an unfitted copy of predicter
+func (m *GradientBoostingRegressor) PredicterClone() base.Predicter {
+	clone := *m
+	clone.resetFitted()
+	return base.DeepCopy(&clone).(*GradientBoostingRegressor)
+}
+
+// IsClassifier returns false for GradientBoostingRegressor
+func (*GradientBoostingRegressor) IsClassifier() bool { return false }
+
+// GetNOutputs returns 1
+func (*GradientBoostingRegressor) GetNOutputs() int { return 1 }
+
+// Fit boosts trees from X and the targets Y
+func (m *GradientBoostingRegressor) Fit(X, Y mat.Matrix) base.Fiter {
+	return m.FitWeighted(X, Y, nil)
+}
+
+// FitWeighted is Fit with the gradients, leaf values and losses weighted by sampleWeight
+func (m *GradientBoostingRegressor) FitWeighted(Xmatrix, Ymatrix mat.Matrix, sampleWeight []float64) base.Fiter {
+	m.fit(base.ToDense(Xmatrix), singleTarget(Ymatrix), sampleWeight, newBoostingLoss(m.Loss, 0, m.Alpha))
+	return m
+}
+
+// singleTarget returns the single column of Y
+func singleTarget(Y mat.Matrix) []float64 {
+	if _, nOutputs := Y.Dims(); nOutputs != 1 {
+		panic(fmt.Errorf("%w: Y must have a single column, got %d", base.ErrShapeMismatch, nOutputs))
+	}
+	return mat.Col(nil, 0, Y)
+}
+
+// Predict returns the sum of the initial prediction and of the shrunk predictions of the trees
+func (m *GradientBoostingRegressor) Predict(X mat.Matrix, Ymutable mat.Mutable) *mat.Dense {
+	base.MustBeFitted(m)
+	return base.FromDense(Ymutable, m.rawPredict(X))
+}
+
+// FitE is Fit returning an error instead of panicking
+func (m *GradientBoostingRegressor) FitE(X, Y mat.Matrix) error {
+	return base.FitE(m, X, Y)
+}
+
+// PredictE is Predict returning an error instead of panicking
+func (m *GradientBoostingRegressor) PredictE(X mat.Matrix, Y mat.Mutable) (*mat.Dense, error) {
+	if !m.IsFitted() {
+		return nil, base.ErrNotFitted
+	}
+	if err := base.CheckNFeatures(X, m.NFeatures); err != nil {
+		return nil, err
+	}
+	return base.PredictE(m, X, Y)
+}
+
+// Score for GradientBoostingRegressor returns the R2 score of Predict
+func (m *GradientBoostingRegressor) Score(X, Y mat.Matrix) float64 {
+	NSamples, NOutputs := Y.Dims()
+	Ypred := mat.NewDense(NSamples, NOutputs, nil)
+	m.Predict(X, Ypred)
+	return metrics.R2Score(Y, Ypred, nil, "").At(0, 0)
+}
@@ -0,0 +1,139 @@
+package ensemble
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/pa-m/sklearn/base"
+	"github.com/pa-m/sklearn/datasets"
+	"gonum.org/v1/gonum/mat"
+)
+
+var _ = []base.PredicterE{&GradientBoostingClassifier{}, &GradientBoostingRegressor{}, &HistGradientBoostingClassifier{}, &HistGradientBoostingRegressor{}}
+var _ = []base.ProbaPredicter{&GradientBoostingClassifier{}, &HistGradientBoostingClassifier{}}
+var _ = []base.DecisionFunctioner{&GradientBoostingClassifier{}, &HistGradientBoostingClassifier{}}
+var _ = []base.WeightedFiter{&GradientBoostingClassifier{}, &GradientBoostingRegressor{}, &HistGradientBoostingClassifier{}, &HistGradientBoostingRegressor{}}
+var _ = []base.FittedChecker{&GradientBoostingClassifier{}, &GradientBoostingRegressor{}, &HistGradientBoostingClassifier{}, &HistGradientBoostingRegressor{}}
+
+func ExampleGradientBoostingClassifier() {
+	ds := datasets.LoadIris()
+	clf := NewGradientBoostingClassifier()
+	clf.RandomState = base.NewSource(7)
+	clf.Fit(ds.X, ds.Y)
+	fmt.Printf("accuracy: %.2f\n", clf.Score(ds.X, ds.Y))
+	fmt.Printf("importances: %.2f\n", clf.FeatureImportances)
+	// Output:
+	// accuracy: 1.00
+	// importances: [0.08 0.11 0.49 0.31]
+}
+
+func ExampleGradientBoostingRegressor() {
+	ds := datasets.LoadDiabetes()
+	for _, loss := range []string{"squared_error", "absolute_error", "huber"} {
+		reg := NewGradientBoostingRegressor()
+		reg.Loss = loss
+		reg.RandomState = base.NewSource(7)
+		reg.Fit(ds.X, ds.Y)
+		fmt.Printf("%s: %.2f\n", loss, reg.Score(ds.X, ds.Y))
+	}
+	// Output:
+	// squared_error: 0.80
+	// absolute_error: 0.67
+	// huber: 0.78
+}
+
+func TestGradientBoostingClassifier(t *testing.T) {
+	ds := datasets.LoadBreastCancer()
+	clf := NewGradientBoostingClassifier()
+	clf.NEstimators = 20
+	clf.Subsample = .5
+	clf.RandomState = base.NewSource(7)
+	clf.Fit(ds.X, ds.Y)
+	if score := clf.Score(ds.X, ds.Y); score < .97 {
+		t.Errorf("unexpected training score %g", score)
+	}
+	if _, c := clf.DecisionFunction(ds.X, nil).Dims(); c != 1 {
+		t.Errorf("binary decision function must have a single column, got %d", c)
+	}
+	P := clf.PredictProba(ds.X, nil)
+	for i := 0; i < 10; i++ {
+		if sum := mat.Sum(P.RowView(i)); math.Abs(sum-1) > 1e-12 {
+			t.Fatalf("probas of sample %d sum to %g", i, sum)
+		}
+	}
+	// subsamples are drawn from RandomState
+	clone := clf.PredicterClone().(*GradientBoostingClassifier)
+	clone.RandomState = base.NewSource(7)
+	clone.Fit(ds.X, ds.Y)
+	if !mat.Equal(clf.DecisionFunction(ds.X, nil), clone.DecisionFunction(ds.X, nil)) {
+		t.Error("fits with the same RandomState must be equal")
+	}
+
+	for _, params := range []map[string]interface{}{
+		{"LearningRate": 0.},
+		{"NEstimators": 0},
+		{"Subsample": 1.5},
+		{"Loss": "hinge"},
+		{"EarlyStopping": true, "ValidationFraction": 1.},
+	} {
+		clf := NewGradientBoostingClassifier()
+		if err := clf.SetParams(params); err != nil {
+			t.Fatal(err)
+		}
+		if err := clf.FitE(ds.X, ds.Y); !errors.Is(err, base.ErrInvalidParam) {
+			t.Errorf("%v: expected ErrInvalidParam, got %v", params, err)
+		}
+	}
+	if err := clf.FitE(ds.X, mat.NewDense(569, 2, nil)); !errors.Is(err, base.ErrShapeMismatch) {
+		t.Errorf("expected ErrShapeMismatch, got %v", err)
+	}
+}
+
+func TestGradientBoostingClassifier_EarlyStopping(t *testing.T) {
+	ds := datasets.LoadIris()
+	clf := NewGradientBoostingClassifier()
+	clf.NEstimators = 500
+	clf.EarlyStopping = true
+	clf.RandomState = base.NewSource(7)
+	clf.Fit(ds.X, ds.Y)
+	if len(clf.ValidationLoss) == clf.NEstimators {
+		t.Error("expected boosting to stop early")
+	}
+	if len(clf.Estimators) != len(clf.ValidationLoss)-clf.NIterNoChange {
+		t.Errorf("expected stages up to the best validation loss, got %d of %d", len(clf.Estimators), len(clf.ValidationLoss))
+	}
+	if len(clf.Estimators[0]) != 3 {
+		t.Errorf("expected a tree per class, got %d", len(clf.Estimators[0]))
+	}
+	if score := clf.Score(ds.X, ds.Y); score < .95 {
+		t.Errorf("unexpected training score %g", score)
+	}
+}
+
+func TestGradientBoostingRegressor_FitWeighted(t *testing.T) {
+	ds := datasets.LoadDiabetes()
+	X := ds.X.Slice(0, 100, 0, 10).(*mat.Dense)
+	Y := ds.Y.Slice(0, 100, 0, 1).(*mat.Dense)
+	Xdup, Ydup := mat.NewDense(150, 10, nil), mat.NewDense(150, 1, nil)
+	Xdup.Stack(X, X.Slice(0, 50, 0, 10))
+	Ydup.Stack(Y, Y.Slice(0, 50, 0, 1))
+	w := make([]float64, 100)
+	for i := range w {
+		w[i] = 1
+		if i < 50 {
+			w[i] = 2
+		}
+	}
+	for _, loss := range []string{"squared_error", "absolute_error", "huber"} {
+		reg, dup := NewGradientBoostingRegressor(), NewGradientBoostingRegressor()
+		reg.Loss, dup.Loss = loss, loss
+		reg.NEstimators, dup.NEstimators = 10, 10
+		reg.FitWeighted(X, Y, w)
+		dup.Fit(Xdup, Ydup)
+		if !mat.EqualApprox(reg.Predict(X, nil), dup.Predict(X, nil), 1e-8) {
+			t.Errorf("%s: weights must act as repeated samples", loss)
+		}
+	}
+}
@@ -0,0 +1,583 @@
+package ensemble
+
+import (
+	"container/heap"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"golang.org/x/exp/rand"
+
+	"github.com/pa-m/sklearn/base"
+	"github.com/pa-m/sklearn/metrics"
+	"github.com/pa-m/sklearn/tree"
+	"gonum.org/v1/gonum/mat"
+)
+
+// BaseHistGradientBoosting holds the parameters shared by histogram-based gradient boosting estimators, which are much faster than
+// BaseGradientBoosting on large datasets. features are first discretized in at most MaxBins bins (255 at most) like the quantile
+// strategy of preprocessing.KBinsDiscretizer, from a subsample of 200000 samples.
+// each of the MaxIter iterations grows a tree leaf-wise from the histograms of the gradients of Loss in each bin, splitting the leaf of
+// highest gain until MaxLeafNodes (0 meaning unlimited) leaves are grown. MaxDepth 0 means unlimited depth.
+// leaves have at least MinSamplesLeaf samples, and L2Regularization is added to the hessians. histograms are built by NJobs threads,
+// NJobs <= 0 meaning runtime.NumCPU(). Alpha is the quantile of the "huber" loss.
+// early stopping parameters are those of BaseGradientBoosting
+type BaseHistGradientBoosting struct {
+	Loss               string
+	LearningRate       float64
+	MaxIter            int
+	MaxLeafNodes       int
+	MaxDepth           int
+	MinSamplesLeaf     int
+	L2Regularization   float64
+	MaxBins            int
+	Alpha              float64
+	EarlyStopping      bool
+	ValidationFraction float64
+	NIterNoChange      int
+	Tol                float64
+	NJobs              int
+	RandomState        base.RandomState
+	// runtime filled members
+	NFeatures int `param:"-"`
+	// BinEdges holds the upper bounds of the bins of each feature but the last one
+	BinEdges [][]float64 `param:"-"`
+	// InitRaw is the initial raw prediction of each column of the decision function
+	InitRaw []float64 `param:"-"`
+	// Predictors holds the trees of each iteration, one per column of the decision function. leaf values are shrunk by LearningRate
+	Predictors     [][]*tree.Tree `param:"-"`
+	TrainLoss      []float64      `param:"-"`
+	ValidationLoss []float64      `param:"-"`
+}
+
+func (m *BaseHistGradientBoosting) resetFitted() {
+	m.NFeatures, m.BinEdges, m.InitRaw, m.Predictors = 0, nil, nil, nil
+	m.TrainLoss, m.ValidationLoss = nil, nil
+}
+
+// IsFitted returns true when the trees have been grown
+func (m *BaseHistGradientBoosting) IsFitted() bool { return m.Predictors != nil }
+
+// NIter returns the number of iterations kept after early stopping
+func (m *BaseHistGradientBoosting) NIter() int { return len(m.Predictors) }
+
+// binSubsample is the number of samples used to compute the bins
+const binSubsample = 200000
+
+// binEdges returns the edges of at most maxBins bins of each column of X: the midpoints of distinct values if there are few of them,
+// quantiles otherwise
+func binEdges(rnd *rand.Rand, X *mat.Dense, maxBins int) [][]float64 {
+	nSamples, nFeatures := X.Dims()
+	rows := make([]int, nSamples)
+	for i := range rows {
+		rows[i] = i
+	}
+	if nSamples > binSubsample {
+		rows = rnd.Perm(nSamples)[:binSubsample]
+	}
+	edges := make([][]float64, nFeatures)
+	col := make([]float64, len(rows))
+	for f := range edges {
+		for j, i := range rows {
+			col[j] = X.At(i, f)
+		}
+		sort.Float64s(col)
+		var distinct []float64
+		for _, v := range col {
+			if len(distinct) == 0 || v != distinct[len(distinct)-1] {
+				distinct = append(distinct, v)
+				if len(distinct) > maxBins {
+					break
+				}
+			}
+		}
+		if len(distinct) <= maxBins {
+			edges[f] = make([]float64, len(distinct)-1)
+			for b := range edges[f] {
+				edges[f][b] = (distinct[b] + distinct[b+1]) / 2
+			}
+			continue
+		}
+		e := make([]float64, 0, maxBins-1)
+		for b := 1; b < maxBins; b++ {
+			pos := float64(b) / float64(maxBins) * float64(len(col)-1)
+			lo := int(pos)
+			v := col[lo]
+			if lo+1 < len(col) {
+				v += (col[lo+1] - v) * (pos - float64(lo))
+			}
+			if len(e) == 0 || v > e[len(e)-1] {
+				e = append(e, v)
+			}
+		}
+		edges[f] = e
+	}
+	return edges
+}
+
+// binCodes returns the bin of each sample for each feature. x falls in bin b if edges[b-1] < x <= edges[b]
+func binCodes(X *mat.Dense, edges [][]float64) [][]uint8 {
+	nSamples, nFeatures := X.Dims()
+	codes := make([][]uint8, nFeatures)
+	base.Parallelize(-1, nFeatures, func(th, start, end int) {
+		for f := start; f < end; f++ {
+			codes[f] = make([]uint8, nSamples)
+			for i := range codes[f] {
+				codes[f][i] = uint8(sort.SearchFloat64s(edges[f], X.At(i, f)))
+			}
+		}
+	})
+	return codes
+}
+
+// histBin holds the sums of weighted gradients and hessians and the number of samples of a bin
+type histBin struct {
+	g, h float64
+	n    int
+}
+
+// histNode is a node of a tree being grown, holding samples[start:end]
+type histNode struct {
+	id, start, end, depth int
+	g, h                  float64
+	hist                  [][]histBin
+	// best split
+	gain         float64
+	feature, bin int
+}
+
+// histHeap is a max-heap of nodes by gain
+type histHeap []*histNode
+
+func (h histHeap) Len() int            { return len(h) }
+func (h histHeap) Less(i, j int) bool  { return h[i].gain > h[j].gain }
+func (h histHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *histHeap) Push(x interface{}) { *h = append(*h, x.(*histNode)) }
+func (h *histHeap) Pop() interface{} {
+	old := *h
+	n := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return n
+}
+
+// minHessianToSplit is the minimum sum of hessians of a child
+const minHessianToSplit = 1e-3
+
+// histGrower grows a tree from binned features and weighted gradients
+type histGrower struct {
+	m       *BaseHistGradientBoosting
+	codes   [][]uint8
+	nBins   []int
+	gw, hw  []float64
+	samples []int
+	tree    *tree.Tree
+}
+
+// histogram returns the histograms of the samples of node n
+func (gr *histGrower) histogram(n *histNode) [][]histBin {
+	hist := make([][]histBin, len(gr.codes))
+	base.Parallelize(gr.m.NJobs, len(gr.codes), func(th, start, end int) {
+		for f := start; f < end; f++ {
+			hf := make([]histBin, gr.nBins[f])
+			codes := gr.codes[f]
+			for _, i := range gr.samples[n.start:n.end] {
+				b := &hf[codes[i]]
+				b.g += gr.gw[i]
+				b.h += gr.hw[i]
+				b.n++
+			}
+			hist[f] = hf
+		}
+	})
+	return hist
+}
+
+// findSplit sets the split of n of highest gain, n.gain being 0 if no split is allowed
+func (gr *histGrower) findSplit(n *histNode) {
+	n.gain = 0
+	m := gr.m
+	if m.MaxDepth > 0 && n.depth >= m.MaxDepth || n.end-n.start < 2*m.MinSamplesLeaf {
+		return
+	}
+	lambda := m.L2Regularization
+	parentScore := n.g * n.g / (n.h + lambda)
+	for f, hf := range n.hist {
+		gL, hL, nL := 0., 0., 0
+		for b := 0; b < len(hf)-1; b++ {
+			gL, hL, nL = gL+hf[b].g, hL+hf[b].h, nL+hf[b].n
+			if nL < m.MinSamplesLeaf {
+				continue
+			}
+			if n.end-n.start-nL < m.MinSamplesLeaf {
+				break
+			}
+			gR, hR := n.g-gL, n.h-hL
+			if hL < minHessianToSplit || hR < minHessianToSplit {
+				continue
+			}
+			gain := gL*gL/(hL+lambda) + gR*gR/(hR+lambda) - parentScore
+			if gain > n.gain {
+				n.gain, n.feature, n.bin = gain, f, b
+			}
+		}
+	}
+}
+
+// node adds a node of samples[start:end] to the tree, whose value is the newton step -G/(H+L2Regularization).
+// the hessians are used as weights, so that the weighted impurity decrease of a split is its gain
+func (gr *histGrower) node(start, end, depth int) *histNode {
+	n := &histNode{start: start, end: end, depth: depth}
+	for _, i := range gr.samples[start:end] {
+		n.g += gr.gw[i]
+		n.h += gr.hw[i]
+	}
+	h := n.h + gr.m.L2Regularization
+	n.id = gr.tree.AddNode(-n.g*n.g/h/math.Max(n.h, 1e-150), end-start, n.h, []float64{-n.g / h})
+	return n
+}
+
+// grow grows the tree leaf-wise and returns its leaves
+func (gr *histGrower) grow() []*histNode {
+	m := gr.m
+	root := gr.node(0, len(gr.samples), 0)
+	root.hist = gr.histogram(root)
+	gr.findSplit(root)
+	var leaves []*histNode
+	h := &histHeap{}
+	push := func(n *histNode) {
+		if n.gain > 0 {
+			heap.Push(h, n)
+		} else {
+			n.hist = nil
+			leaves = append(leaves, n)
+		}
+	}
+	push(root)
+	for h.Len() > 0 {
+		if m.MaxLeafNodes > 0 && len(leaves)+h.Len() >= m.MaxLeafNodes {
+			break
+		}
+		n := heap.Pop(h).(*histNode)
+		codes := gr.codes[n.feature]
+		s := gr.samples[n.start:n.end]
+		mid := 0
+		for j, i := range s {
+			if int(codes[i]) <= n.bin {
+				s[j], s[mid] = s[mid], s[j]
+				mid++
+			}
+		}
+		left := gr.node(n.start, n.start+mid, n.depth+1)
+		right := gr.node(n.start+mid, n.end, n.depth+1)
+		gr.tree.Feature[n.id] = n.feature
+		gr.tree.Threshold[n.id] = m.BinEdges[n.feature][n.bin]
+		gr.tree.Left[n.id], gr.tree.Right[n.id] = left.id, right.id
+		if d := n.depth + 1; d > gr.tree.MaxDepth {
+			gr.tree.MaxDepth = d
+		}
+		// histogram of the smaller child, the other one is the difference with the parent
+		small, large := left, right
+		if small.end-small.start > large.end-large.start {
+			small, large = large, small
+		}
+		small.hist = gr.histogram(small)
+		large.hist = n.hist
+		for f, hf := range large.hist {
+			for b := range hf {
+				hf[b].g -= small.hist[f][b].g
+				hf[b].h -= small.hist[f][b].h
+				hf[b].n -= small.hist[f][b].n
+			}
+		}
+		n.hist = nil
+		gr.findSplit(left)
+		gr.findSplit(right)
+		push(left)
+		push(right)
+	}
+	for h.Len() > 0 {
+		n := heap.Pop(h).(*histNode)
+		n.hist = nil
+		leaves = append(leaves, n)
+	}
+	return leaves
+}
+
+// fit boosts trees on X and y, y holding targets or class indices for classification losses
+func (m *BaseHistGradientBoosting) fit(X *mat.Dense, y, sampleWeight []float64, loss boostingLoss) {
+	if err := base.CheckSampleWeight(X, sampleWeight); err != nil {
+		panic(err)
+	}
+	validateBoosting(m.LearningRate, m.MaxIter, m.EarlyStopping, m.ValidationFraction, m.NIterNoChange)
+	if m.MaxBins < 2 || m.MaxBins > 255 {
+		panic(fmt.Errorf("%w: MaxBins must be >= 2 and <= 255, got %d", base.ErrInvalidParam, m.MaxBins))
+	}
+	if m.MaxLeafNodes < 0 || m.MaxLeafNodes == 1 {
+		panic(fmt.Errorf("%w: MaxLeafNodes must be 0 or >= 2, got %d", base.ErrInvalidParam, m.MaxLeafNodes))
+	}
+	if m.MinSamplesLeaf < 1 {
+		panic(fmt.Errorf("%w: MinSamplesLeaf must be >= 1, got %d", base.ErrInvalidParam, m.MinSamplesLeaf))
+	}
+	if m.L2Regularization < 0 {
+		panic(fmt.Errorf("%w: L2Regularization must be >= 0, got %g", base.ErrInvalidParam, m.L2Regularization))
+	}
+	src := m.RandomState
+	if src == nil {
+		src = base.NewLockedSource(uint64(time.Now().UnixNano()))
+	}
+	rnd := rand.New(src)
+	d := splitBoostingData(rnd, X, y, sampleWeight, m.EarlyStopping, m.ValidationFraction)
+	nSamples, nFeatures := d.X.Dims()
+	m.resetFitted()
+	m.NFeatures = nFeatures
+	m.BinEdges = binEdges(rnd, d.X, m.MaxBins)
+	codes := binCodes(d.X, m.BinEdges)
+	nBins := make([]int, nFeatures)
+	for f, e := range m.BinEdges {
+		nBins[f] = len(e) + 1
+	}
+	m.InitRaw = loss.initRaw(d.y, d.w)
+	K := len(m.InitRaw)
+	raw := newRawPrediction(nSamples, m.InitRaw)
+	var rawVal *mat.Dense
+	if d.XVal != nil {
+		nVal, _ := d.XVal.Dims()
+		rawVal = newRawPrediction(nVal, m.InitRaw)
+	}
+	grad, hess := make([][]float64, K), make([][]float64, K)
+	for k := range grad {
+		grad[k], hess[k] = make([]float64, nSamples), make([]float64, nSamples)
+	}
+	gw, hw := make([]float64, nSamples), make([]float64, nSamples)
+	samples := make([]int, nSamples)
+	stopper := earlyStopper{tol: m.Tol, nIterNoChange: m.NIterNoChange}
+	m.Predictors = make([][]*tree.Tree, 0, m.MaxIter)
+	for it := 0; it < m.MaxIter; it++ {
+		for k := 0; k < K; k++ {
+			loss.gradients(d.y, raw, k, d.w, grad[k], hess[k])
+		}
+		predictors := make([]*tree.Tree, K)
+		updates := make([]float64, nSamples*K)
+		for k := 0; k < K; k++ {
+			for i := range samples {
+				samples[i] = i
+				w := sampleWeightAt(d.w, i)
+				gw[i], hw[i] = w*grad[k][i], w*hess[k][i]
+			}
+			gr := &histGrower{m: m, codes: codes, nBins: nBins, gw: gw, hw: hw, samples: samples,
+				tree: &tree.Tree{NFeatures: nFeatures, NOutputs: 1, NValues: 1}}
+			for _, leaf := range gr.grow() {
+				s := samples[leaf.start:leaf.end]
+				value := gr.tree.NodeValue(leaf.id)
+				if !loss.newtonLeaves() {
+					value[0] = loss.leafValue(d.y, raw, k, s, d.w, grad[k], hess[k])
+				}
+				value[0] *= m.LearningRate
+				for _, i := range s {
+					updates[i*K+k] = value[0]
+				}
+			}
+			predictors[k] = gr.tree
+		}
+		for i, u := range updates {
+			raw.RawMatrix().Data[i] += u
+		}
+		m.Predictors = append(m.Predictors, predictors)
+		m.TrainLoss = append(m.TrainLoss, loss.loss(d.y, raw, d.w))
+		if rawVal != nil {
+			addTrees(d.XVal, rawVal, predictors)
+			m.ValidationLoss = append(m.ValidationLoss, loss.loss(d.yVal, rawVal, d.wVal))
+			if stopper.update(it, m.ValidationLoss[it]) {
+				break
+			}
+		}
+	}
+	if rawVal != nil {
+		m.Predictors = m.Predictors[:stopper.bestIter+1]
+	}
+}
+
+// addTrees adds the predictions of trees to raw
+func addTrees(X, raw *mat.Dense, trees []*tree.Tree) {
+	nSamples, _ := X.Dims()
+	for k, t := range trees {
+		for i := 0; i < nSamples; i++ {
+			raw.Set(i, k, raw.At(i, k)+t.NodeValue(t.Apply(X.RawRowView(i)))[0])
+		}
+	}
+}
+
+// rawPredict returns the raw predictions of X, with a column per tree of each iteration
+func (m *BaseHistGradientBoosting) rawPredict(Xmatrix mat.Matrix) *mat.Dense {
+	X := base.ToDense(Xmatrix)
+	nSamples, _ := X.Dims()
+	raw := newRawPrediction(nSamples, m.InitRaw)
+	base.Parallelize(m.NJobs, nSamples, func(th, start, end int) {
+		Xs, raws := X.Slice(start, end, 0, m.NFeatures).(*mat.Dense), raw.Slice(start, end, 0, len(m.InitRaw)).(*mat.Dense)
+		for _, trees := range m.Predictors {
+			addTrees(Xs, raws, trees)
+		}
+	})
+	return raw
+}
+
+// HistGradientBoostingClassifier is a histogram-based gradient boosting classifier of a single output. Loss is "log_loss"
+type HistGradientBoostingClassifier struct {
+	BaseHistGradientBoosting
+	// runtime filled members
+	Classes []float64 `param:"-"`
+}
+
+// NewHistGradientBoostingClassifier returns a HistGradientBoostingClassifier of 100 iterations of trees of 31 leaves, with LearningRate .1
+func NewHistGradientBoostingClassifier() *HistGradientBoostingClassifier {
+	return &HistGradientBoostingClassifier{BaseHistGradientBoosting: BaseHistGradientBoosting{
+		Loss: "log_loss", LearningRate: .1, MaxIter: 100, MaxLeafNodes: 31, MinSamplesLeaf: 20, MaxBins: 255,
+		Alpha: .9, ValidationFraction: .1, NIterNoChange: 10, Tol: 1e-7,
+	}}
+}
+
+// PredicterClone returns an unfitted copy of predicter
+func (m *HistGradientBoostingClassifier) PredicterClone() base.Predicter {
+	clone := *m
+	clone.resetFitted()
+	clone.Classes = nil
+	return base.DeepCopy(&clone).(*HistGradientBoostingClassifier)
+}
+
+// IsClassifier returns true for HistGradientBoostingClassifier
+func (*HistGradientBoostingClassifier) IsClassifier() bool { return true }
+
+// GetNOutputs returns 1
+func (*HistGradientBoostingClassifier) GetNOutputs() int { return 1 }
+
+// Fit boosts trees from X and the class labels Y
+func (m *HistGradientBoostingClassifier) Fit(X, Y mat.Matrix) base.Fiter {
+	return m.FitWeighted(X, Y, nil)
+}
+
+// FitWeighted is Fit with the gradients, leaf values and losses weighted by sampleWeight
+func (m *HistGradientBoostingClassifier) FitWeighted(Xmatrix, Ymatrix mat.Matrix, sampleWeight []float64) base.Fiter {
+	classes, y := classIndices(Ymatrix)
+	m.Classes = classes
+	m.fit(base.ToDense(Xmatrix), y, sampleWeight, newBoostingLoss(m.Loss, len(m.Classes), m.Alpha))
+	return m
+}
+
+// DecisionFunction returns the raw predictions of the trees, the log odds of the second class for binary classification,
+// and a column per class otherwise. see base.DecisionFunctioner
+func (m *HistGradientBoostingClassifier) DecisionFunction(X mat.Matrix, Ymutable mat.Mutable) *mat.Dense {
+	base.MustBeFitted(m)
+	return base.FromDense(Ymutable, m.rawPredict(X))
+}
+
+// PredictProba returns the probability of each class. see base.ProbaPredicter
+func (m *HistGradientBoostingClassifier) PredictProba(X mat.Matrix, Ymutable mat.Mutable) *mat.Dense {
+	base.MustBeFitted(m)
+	return base.FromDense(Ymutable, rawToProba(m.rawPredict(X)))
+}
+
+// Predict returns the most probable class of each sample
+func (m *HistGradientBoostingClassifier) Predict(X mat.Matrix, Ymutable mat.Mutable) *mat.Dense {
+	base.MustBeFitted(m)
+	Y := base.ToDense(Ymutable)
+	nSamples, _ := X.Dims()
+	if Y.IsZero() {
+		*Y = *mat.NewDense(nSamples, 1, nil)
+	}
+	rawToClasses(m.rawPredict(X), m.Classes, Y)
+	return base.FromDense(Ymutable, Y)
+}
+
+// FitE is Fit returning an error instead of panicking
+func (m *HistGradientBoostingClassifier) FitE(X, Y mat.Matrix) error {
+	return base.FitE(m, X, Y)
+}
+
+// PredictE is Predict returning an error instead of panicking
+func (m *HistGradientBoostingClassifier) PredictE(X mat.Matrix, Y mat.Mutable) (*mat.Dense, error) {
+	if !m.IsFitted() {
+		return nil, base.ErrNotFitted
+	}
+	if err := base.CheckNFeatures(X, m.NFeatures); err != nil {
+		return nil, err
+	}
+	return base.PredictE(m, X, Y)
+}
+
+// Score for HistGradientBoostingClassifier returns the accuracy of Predict
+func (m *HistGradientBoostingClassifier) Score(X, Y mat.Matrix) float64 {
+	NSamples, NOutputs := Y.Dims()
+	Ypred := mat.NewDense(NSamples, NOutputs, nil)
+	m.Predict(X, Ypred)
+	return metrics.AccuracyScore(Y, Ypred, true, nil)
+}
+
+// HistGradientBoostingRegressor is a histogram-based gradient boosting regressor of a single output. Loss is "squared_error",
+// "absolute_error" or "huber"
+type HistGradientBoostingRegressor struct {
+	BaseHistGradientBoosting
+}
+
+// NewHistGradientBoostingRegressor returns a HistGradientBoostingRegressor of 100 iterations of trees of 31 leaves with the squared error loss
+// and LearningRate .1
+func NewHistGradientBoostingRegressor() *HistGradientBoostingRegressor {
+	return &HistGradientBoostingRegressor{BaseHistGradientBoosting: BaseHistGradientBoosting{
+		Loss: "squared_error", LearningRate: .1, MaxIter: 100, MaxLeafNodes: 31, MinSamplesLeaf: 20, MaxBins: 255,
+		Alpha: .9, ValidationFraction: .1, NIterNoChange: 10, Tol: 1e-7,
+	}}
+}
+
+// PredicterClone returns an unfitted copy of predicter
+func (m *HistGradientBoostingRegressor) PredicterClone() base.Predicter {
+	clone := *m
+	clone.resetFitted()
+	return base.DeepCopy(&clone).(*HistGradientBoostingRegressor)
+}
+
+// IsClassifier returns false for HistGradientBoostingRegressor
+func (*HistGradientBoostingRegressor) IsClassifier() bool { return false }
+
+// GetNOutputs returns 1
+func (*HistGradientBoostingRegressor) GetNOutputs() int { return 1 }
+
+// Fit boosts trees from X and the targets Y
+func (m *HistGradientBoostingRegressor) Fit(X, Y mat.Matrix) base.Fiter {
+	return m.FitWeighted(X, Y, nil)
+}
+
+// FitWeighted is Fit with the gradients, leaf values and losses weighted by sampleWeight
+func (m *HistGradientBoostingRegressor) FitWeighted(Xmatrix, Ymatrix mat.Matrix, sampleWeight []float64) base.Fiter {
+	m.fit(base.ToDense(Xmatrix), singleTarget(Ymatrix), sampleWeight, newBoostingLoss(m.Loss, 0, m.Alpha))
+	return m
+}
+
+// Predict returns the sum of the initial prediction and of the predictions of the trees
+func (m *HistGradientBoostingRegressor) Predict(X mat.Matrix, Ymutable mat.Mutable) *mat.Dense {
+	base.MustBeFitted(m)
+	return base.FromDense(Ymutable, m.rawPredict(X))
+}
+
+// FitE is Fit returning an error instead of panicking
+func (m *HistGradientBoostingRegressor) FitE(X, Y mat.Matrix) error {
+	return base.FitE(m, X, Y)
+}
+
+// PredictE is Predict returning an error instead of panicking
+func (m *HistGradientBoostingRegressor) PredictE(X mat.Matrix, Y mat.Mutable) (*mat.Dense, error) {
+	if !m.IsFitted() {
+		return nil, base.ErrNotFitted
+	}
+	if err := base.CheckNFeatures(X, m.NFeatures); err != nil {
+		return nil, err
+	}
+	return base.PredictE(m, X, Y)
+}
+
+// Score for HistGradientBoostingRegressor returns the R2 score of Predict
+func (m *HistGradientBoostingRegressor) Score(X, Y mat.Matrix) float64 {
+	NSamples, NOutputs := Y.Dims()
+	Ypred := mat.NewDense(NSamples, NOutputs, nil)
+	m.Predict(X, Ypred)
+	return metrics.R2Score(Y, Ypred, nil, "").At(0, 0)
+}
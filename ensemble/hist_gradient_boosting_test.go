@@ -0,0 +1,140 @@
+package ensemble
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+
+	"golang.org/x/exp/rand"
+
+	"github.com/pa-m/sklearn/base"
+	"github.com/pa-m/sklearn/datasets"
+	"gonum.org/v1/gonum/mat"
+)
+
+func ExampleHistGradientBoostingClassifier() {
+	ds := datasets.LoadBreastCancer()
+	clf := NewHistGradientBoostingClassifier()
+	clf.EarlyStopping = true
+	clf.RandomState = base.NewSource(7)
+	clf.Fit(ds.X, ds.Y)
+	fmt.Printf("accuracy: %.2f\n", clf.Score(ds.X, ds.Y))
+	// Output:
+	// accuracy: 0.99
+}
+
+func ExampleHistGradientBoostingRegressor() {
+	ds := datasets.LoadDiabetes()
+	reg := NewHistGradientBoostingRegressor()
+	reg.RandomState = base.NewSource(7)
+	reg.Fit(ds.X, ds.Y)
+	fmt.Printf("r2: %.2f\n", reg.Score(ds.X, ds.Y))
+	// Output:
+	// r2: 0.93
+}
+
+func TestHistGradientBoosting_binEdges(t *testing.T) {
+	X := mat.NewDense(100, 2, nil)
+	for i := 0; i < 100; i++ {
+		X.Set(i, 0, float64(i%3))
+		X.Set(i, 1, float64(99-i))
+	}
+	edges := binEdges(rand.New(base.NewSource(7)), X, 4)
+	if fmt.Sprint(edges[0]) != "[0.5 1.5]" {
+		t.Errorf("expected midpoints of distinct values, got %v", edges[0])
+	}
+	if fmt.Sprint(edges[1]) != "[24.75 49.5 74.25]" {
+		t.Errorf("expected quartiles, got %v", edges[1])
+	}
+	codes := binCodes(X, edges)
+	for i := 0; i < 100; i++ {
+		x := X.At(i, 1)
+		if b := int(codes[1][i]); b < len(edges[1]) && x > edges[1][b] || b > 0 && x <= edges[1][b-1] {
+			t.Fatalf("%g is not in bin %d", x, b)
+		}
+	}
+}
+
+func TestHistGradientBoostingClassifier(t *testing.T) {
+	ds := datasets.LoadIris()
+	clf := NewHistGradientBoostingClassifier()
+	clf.MaxIter = 20
+	clf.MaxLeafNodes = 4
+	clf.MinSamplesLeaf = 5
+	clf.RandomState = base.NewSource(7)
+	clf.Fit(ds.X, ds.Y)
+	if score := clf.Score(ds.X, ds.Y); score < .97 {
+		t.Errorf("unexpected training score %g", score)
+	}
+	for _, trees := range clf.Predictors {
+		if len(trees) != 3 {
+			t.Fatalf("expected a tree per class, got %d", len(trees))
+		}
+		for _, tr := range trees {
+			if tr.NLeaves() > 4 {
+				t.Fatalf("expected at most 4 leaves, got %d", tr.NLeaves())
+			}
+			for node, n := range tr.NNodeSamples {
+				if tr.IsLeaf(node) && n < 5 {
+					t.Fatalf("expected at least 5 samples per leaf, got %d", n)
+				}
+			}
+		}
+	}
+	P := clf.PredictProba(ds.X, nil)
+	for i := 0; i < 150; i++ {
+		if sum := mat.Sum(P.RowView(i)); math.Abs(sum-1) > 1e-12 {
+			t.Fatalf("probas of sample %d sum to %g", i, sum)
+		}
+	}
+
+	clf.MaxLeafNodes, clf.MaxDepth = 0, 1
+	clf.Fit(ds.X, ds.Y)
+	if d := clf.Predictors[0][0].MaxDepth; d != 1 {
+		t.Errorf("expected depth 1, got %d", d)
+	}
+
+	for _, params := range []map[string]interface{}{
+		{"MaxBins": 256},
+		{"MaxLeafNodes": 1},
+		{"MinSamplesLeaf": 0},
+		{"L2Regularization": -1.},
+		{"EarlyStopping": true, "NIterNoChange": 0},
+	} {
+		clf := NewHistGradientBoostingClassifier()
+		if err := clf.SetParams(params); err != nil {
+			t.Fatal(err)
+		}
+		if err := clf.FitE(ds.X, ds.Y); !errors.Is(err, base.ErrInvalidParam) {
+			t.Errorf("%v: expected ErrInvalidParam, got %v", params, err)
+		}
+	}
+}
+
+func TestHistGradientBoostingRegressor_FitWeighted(t *testing.T) {
+	ds := datasets.LoadDiabetes()
+	X := ds.X.Slice(0, 100, 0, 10).(*mat.Dense)
+	Y := ds.Y.Slice(0, 100, 0, 1).(*mat.Dense)
+	Xdup, Ydup := mat.NewDense(150, 10, nil), mat.NewDense(150, 1, nil)
+	Xdup.Stack(X, X.Slice(0, 50, 0, 10))
+	Ydup.Stack(Y, Y.Slice(0, 50, 0, 1))
+	w := make([]float64, 100)
+	for i := range w {
+		w[i] = 1
+		if i < 50 {
+			w[i] = 2
+		}
+	}
+	for _, loss := range []string{"squared_error", "absolute_error", "huber"} {
+		reg, dup := NewHistGradientBoostingRegressor(), NewHistGradientBoostingRegressor()
+		reg.Loss, dup.Loss = loss, loss
+		reg.MaxIter, dup.MaxIter = 10, 10
+		reg.MinSamplesLeaf, dup.MinSamplesLeaf = 1, 1
+		reg.FitWeighted(X, Y, w)
+		dup.Fit(Xdup, Ydup)
+		if !mat.EqualApprox(reg.Predict(X, nil), dup.Predict(X, nil), 1e-8) {
+			t.Errorf("%s: weights must act as repeated samples", loss)
+		}
+	}
+}
@@ -0,0 +1,320 @@
+package ensemble
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/pa-m/sklearn/base"
+	"gonum.org/v1/gonum/mat"
+)
+
+// boostingLoss is a loss minimized by gradient boosting. raw is the NSamples x K matrix of raw predictions, K being 1 except for
+// multinomial loss. y holds targets, or class indices for classification losses. w may be nil
+type boostingLoss interface {
+	// initRaw returns the constant raw prediction of each column minimizing the loss
+	initRaw(y, w []float64) []float64
+	// loss returns the weighted mean loss
+	loss(y []float64, raw *mat.Dense, w []float64) float64
+	// gradients sets the gradient and the hessian of the loss of each sample wrt raw[:, k]
+	gradients(y []float64, raw *mat.Dense, k int, w, grad, hess []float64)
+	// leafValue returns the update of raw[:, k] minimizing the loss of samples, which fall in the same leaf
+	leafValue(y []float64, raw *mat.Dense, k int, samples []int, w, grad, hess []float64) float64
+	// newtonLeaves returns true if a newton step -sum(grad)/sum(hess) is a good leaf value
+	newtonLeaves() bool
+}
+
+// newBoostingLoss returns the loss called name. nClasses is 0 for regression
+func newBoostingLoss(name string, nClasses int, alpha float64) boostingLoss {
+	if nClasses > 0 {
+		switch name {
+		case "", "log_loss", "deviance":
+			if nClasses <= 2 {
+				return binomialLoss{}
+			}
+			return multinomialLoss{nClasses: nClasses}
+		}
+		panic(fmt.Errorf("%w: unknown classification Loss %q", base.ErrInvalidParam, name))
+	}
+	switch name {
+	case "", "squared_error", "ls":
+		return squaredLoss{}
+	case "absolute_error", "lad":
+		return absoluteLoss{}
+	case "huber":
+		if alpha <= 0 || alpha >= 1 {
+			panic(fmt.Errorf("%w: Alpha must be in (0,1), got %g", base.ErrInvalidParam, alpha))
+		}
+		return &huberLoss{alpha: alpha}
+	}
+	panic(fmt.Errorf("%w: unknown regression Loss %q", base.ErrInvalidParam, name))
+}
+
+// squaredLoss is the least squares loss (y-raw)^2
+type squaredLoss struct{}
+
+func (squaredLoss) initRaw(y, w []float64) []float64 {
+	sum, wsum := 0., 0.
+	for i, v := range y {
+		sum += sampleWeightAt(w, i) * v
+		wsum += sampleWeightAt(w, i)
+	}
+	return []float64{sum / wsum}
+}
+
+func (squaredLoss) loss(y []float64, raw *mat.Dense, w []float64) float64 {
+	sum, wsum := 0., 0.
+	for i, v := range y {
+		d := v - raw.At(i, 0)
+		sum += sampleWeightAt(w, i) * d * d
+		wsum += sampleWeightAt(w, i)
+	}
+	return sum / wsum
+}
+
+func (squaredLoss) gradients(y []float64, raw *mat.Dense, k int, w, grad, hess []float64) {
+	for i, v := range y {
+		grad[i], hess[i] = raw.At(i, 0)-v, 1
+	}
+}
+
+func (squaredLoss) leafValue(y []float64, raw *mat.Dense, k int, samples []int, w, grad, hess []float64) float64 {
+	sum, wsum := 0., 0.
+	for _, i := range samples {
+		sum -= sampleWeightAt(w, i) * grad[i]
+		wsum += sampleWeightAt(w, i)
+	}
+	return sum / wsum
+}
+
+func (squaredLoss) newtonLeaves() bool { return true }
+
+// absoluteLoss is the least absolute deviation loss |y-raw|. leaves predict the median of residuals
+type absoluteLoss struct{}
+
+func (absoluteLoss) initRaw(y, w []float64) []float64 {
+	return []float64{weightedPercentile(y, w, .5)}
+}
+
+func (absoluteLoss) loss(y []float64, raw *mat.Dense, w []float64) float64 {
+	sum, wsum := 0., 0.
+	for i, v := range y {
+		sum += sampleWeightAt(w, i) * math.Abs(v-raw.At(i, 0))
+		wsum += sampleWeightAt(w, i)
+	}
+	return sum / wsum
+}
+
+func (absoluteLoss) gradients(y []float64, raw *mat.Dense, k int, w, grad, hess []float64) {
+	for i, v := range y {
+		grad[i], hess[i] = sign(raw.At(i, 0)-v), 1
+	}
+}
+
+func (absoluteLoss) leafValue(y []float64, raw *mat.Dense, k int, samples []int, w, grad, hess []float64) float64 {
+	diff, ws := make([]float64, len(samples)), make([]float64, len(samples))
+	for j, i := range samples {
+		diff[j], ws[j] = y[i]-raw.At(i, 0), sampleWeightAt(w, i)
+	}
+	return weightedPercentile(diff, ws, .5)
+}
+
+func (absoluteLoss) newtonLeaves() bool { return false }
+
+// huberLoss is quadratic for residuals smaller than gamma, the alpha quantile of absolute residuals, and linear beyond
+type huberLoss struct {
+	alpha, gamma float64
+}
+
+func (*huberLoss) initRaw(y, w []float64) []float64 {
+	return []float64{weightedPercentile(y, w, .5)}
+}
+
+func (l *huberLoss) loss(y []float64, raw *mat.Dense, w []float64) float64 {
+	sum, wsum := 0., 0.
+	for i, v := range y {
+		d := math.Abs(v - raw.At(i, 0))
+		if d <= l.gamma {
+			sum += sampleWeightAt(w, i) * d * d / 2
+		} else {
+			sum += sampleWeightAt(w, i) * l.gamma * (d - l.gamma/2)
+		}
+		wsum += sampleWeightAt(w, i)
+	}
+	return sum / wsum
+}
+
+// gradients also updates gamma from the current residuals
+func (l *huberLoss) gradients(y []float64, raw *mat.Dense, k int, w, grad, hess []float64) {
+	absDiff := make([]float64, len(y))
+	for i, v := range y {
+		absDiff[i] = math.Abs(v - raw.At(i, 0))
+	}
+	l.gamma = weightedPercentile(absDiff, w, l.alpha)
+	for i, v := range y {
+		d := v - raw.At(i, 0)
+		if math.Abs(d) <= l.gamma {
+			grad[i] = -d
+		} else {
+			grad[i] = -l.gamma * sign(d)
+		}
+		hess[i] = 1
+	}
+}
+
+func (l *huberLoss) leafValue(y []float64, raw *mat.Dense, k int, samples []int, w, grad, hess []float64) float64 {
+	diff, ws := make([]float64, len(samples)), make([]float64, len(samples))
+	for j, i := range samples {
+		diff[j], ws[j] = y[i]-raw.At(i, 0), sampleWeightAt(w, i)
+	}
+	median := weightedPercentile(diff, ws, .5)
+	sum, wsum := 0., 0.
+	for j, d := range diff {
+		sum += ws[j] * sign(d-median) * math.Min(l.gamma, math.Abs(d-median))
+		wsum += ws[j]
+	}
+	return median + sum/wsum
+}
+
+func (*huberLoss) newtonLeaves() bool { return false }
+
+// binomialLoss is the logistic loss of a binary classification. raw is the log odds of the second class
+type binomialLoss struct{}
+
+func (binomialLoss) initRaw(y, w []float64) []float64 {
+	p, wsum := 0., 0.
+	for i, v := range y {
+		p += sampleWeightAt(w, i) * v
+		wsum += sampleWeightAt(w, i)
+	}
+	p = math.Min(math.Max(p/wsum, 1e-15), 1-1e-15)
+	return []float64{math.Log(p / (1 - p))}
+}
+
+func (binomialLoss) loss(y []float64, raw *mat.Dense, w []float64) float64 {
+	sum, wsum := 0., 0.
+	for i, v := range y {
+		r := raw.At(i, 0)
+		// log(1+exp(r)) - y*r
+		sum += sampleWeightAt(w, i) * (math.Max(r, 0) + math.Log1p(math.Exp(-math.Abs(r))) - v*r)
+		wsum += sampleWeightAt(w, i)
+	}
+	return sum / wsum
+}
+
+func (binomialLoss) gradients(y []float64, raw *mat.Dense, k int, w, grad, hess []float64) {
+	for i, v := range y {
+		p := expit(raw.At(i, 0))
+		grad[i], hess[i] = p-v, p*(1-p)
+	}
+}
+
+func (binomialLoss) leafValue(y []float64, raw *mat.Dense, k int, samples []int, w, grad, hess []float64) float64 {
+	num, den := 0., 0.
+	for _, i := range samples {
+		num -= sampleWeightAt(w, i) * grad[i]
+		den += sampleWeightAt(w, i) * hess[i]
+	}
+	if math.Abs(den) < 1e-150 {
+		return 0
+	}
+	return num / den
+}
+
+func (binomialLoss) newtonLeaves() bool { return true }
+
+// multinomialLoss is the cross entropy of the softmax of raw, which has a column per class
+type multinomialLoss struct{ nClasses int }
+
+func (l multinomialLoss) initRaw(y, w []float64) []float64 {
+	priors := make([]float64, l.nClasses)
+	wsum := 0.
+	for i, v := range y {
+		priors[int(v)] += sampleWeightAt(w, i)
+		wsum += sampleWeightAt(w, i)
+	}
+	for k, p := range priors {
+		priors[k] = math.Log(math.Max(p/wsum, 1e-15))
+	}
+	return priors
+}
+
+func (l multinomialLoss) loss(y []float64, raw *mat.Dense, w []float64) float64 {
+	sum, wsum := 0., 0.
+	for i, v := range y {
+		row := raw.RawRowView(i)
+		sum += sampleWeightAt(w, i) * (logSumExp(row) - row[int(v)])
+		wsum += sampleWeightAt(w, i)
+	}
+	return sum / wsum
+}
+
+func (l multinomialLoss) gradients(y []float64, raw *mat.Dense, k int, w, grad, hess []float64) {
+	for i, v := range y {
+		row := raw.RawRowView(i)
+		p := math.Exp(row[k] - logSumExp(row))
+		grad[i], hess[i] = p, p*(1-p)
+		if int(v) == k {
+			grad[i]--
+		}
+	}
+}
+
+func (l multinomialLoss) leafValue(y []float64, raw *mat.Dense, k int, samples []int, w, grad, hess []float64) float64 {
+	num, den := 0., 0.
+	for _, i := range samples {
+		num -= sampleWeightAt(w, i) * grad[i]
+		den += sampleWeightAt(w, i) * math.Abs(grad[i]) * (1 - math.Abs(grad[i]))
+	}
+	if math.Abs(den) < 1e-150 {
+		return 0
+	}
+	return float64(l.nClasses-1) / float64(l.nClasses) * num / den
+}
+
+func (multinomialLoss) newtonLeaves() bool { return true }
+
+func sign(x float64) float64 {
+	switch {
+	case x > 0:
+		return 1
+	case x < 0:
+		return -1
+	}
+	return 0
+}
+
+func expit(x float64) float64 { return 1 / (1 + math.Exp(-x)) }
+
+func logSumExp(a []float64) float64 {
+	max := math.Inf(-1)
+	for _, v := range a {
+		max = math.Max(max, v)
+	}
+	sum := 0.
+	for _, v := range a {
+		sum += math.Exp(v - max)
+	}
+	return max + math.Log(sum)
+}
+
+// weightedPercentile returns the smallest value of a such that the weight of the values up to it reaches q times the total weight
+func weightedPercentile(a, w []float64, q float64) float64 {
+	idx := make([]int, len(a))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.Slice(idx, func(i, j int) bool { return a[idx[i]] < a[idx[j]] })
+	wsum := 0.
+	for i := range a {
+		wsum += sampleWeightAt(w, i)
+	}
+	cum := 0.
+	for _, i := range idx {
+		cum += sampleWeightAt(w, i)
+		if cum >= q*wsum {
+			return a[i]
+		}
+	}
+	return a[idx[len(idx)-1]]
+}
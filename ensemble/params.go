@@ -0,0 +1,202 @@
+package ensemble
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pa-m/sklearn/base"
+)
+
+// GetParams returns the parameters of RandomForestClassifier. see base.GetFieldParams
+func (m *RandomForestClassifier) GetParams() map[string]interface{} { return base.GetFieldParams(m) }
+
+// SetParams sets the parameters of RandomForestClassifier. see base.SetFieldParams
+func (m *RandomForestClassifier) SetParams(params map[string]interface{}) error {
+	return base.SetFieldParams(m, params)
+}
+
+// GetParams returns the parameters of RandomForestRegressor. see base.GetFieldParams
+func (m *RandomForestRegressor) GetParams() map[string]interface{} { return base.GetFieldParams(m) }
+
+// SetParams sets the parameters of RandomForestRegressor. see base.SetFieldParams
+func (m *RandomForestRegressor) SetParams(params map[string]interface{}) error {
+	return base.SetFieldParams(m, params)
+}
+
+// GetParams returns the parameters of ExtraTreesClassifier. see base.GetFieldParams
+func (m *ExtraTreesClassifier) GetParams() map[string]interface{} { return base.GetFieldParams(m) }
+
+// SetParams sets the parameters of ExtraTreesClassifier. see base.SetFieldParams
+func (m *ExtraTreesClassifier) SetParams(params map[string]interface{}) error {
+	return base.SetFieldParams(m, params)
+}
+
+// GetParams returns the parameters of ExtraTreesRegressor. see base.GetFieldParams
+func (m *ExtraTreesRegressor) GetParams() map[string]interface{} { return base.GetFieldParams(m) }
+
+// SetParams sets the parameters of ExtraTreesRegressor. see base.SetFieldParams
+func (m *ExtraTreesRegressor) SetParams(params map[string]interface{}) error {
+	return base.SetFieldParams(m, params)
+}
+
+// GetParams returns the parameters of GradientBoostingClassifier. see base.GetFieldParams
+func (m *GradientBoostingClassifier) GetParams() map[string]interface{} {
+	return base.GetFieldParams(m)
+}
+
+// SetParams sets the parameters of GradientBoostingClassifier. see base.SetFieldParams
+func (m *GradientBoostingClassifier) SetParams(params map[string]interface{}) error {
+	return base.SetFieldParams(m, params)
+}
+
+// GetParams returns the parameters of GradientBoostingRegressor. see base.GetFieldParams
+func (m *GradientBoostingRegressor) GetParams() map[string]interface{} { return base.GetFieldParams(m) }
+
+// SetParams sets the parameters of GradientBoostingRegressor. see base.SetFieldParams
+func (m *GradientBoostingRegressor) SetParams(params map[string]interface{}) error {
+	return base.SetFieldParams(m, params)
+}
+
+// GetParams returns the parameters of HistGradientBoostingClassifier. see base.GetFieldParams
+func (m *HistGradientBoostingClassifier) GetParams() map[string]interface{} {
+	return base.GetFieldParams(m)
+}
+
+// SetParams sets the parameters of HistGradientBoostingClassifier. see base.SetFieldParams
+func (m *HistGradientBoostingClassifier) SetParams(params map[string]interface{}) error {
+	return base.SetFieldParams(m, params)
+}
+
+// GetParams returns the parameters of HistGradientBoostingRegressor. see base.GetFieldParams
+func (m *HistGradientBoostingRegressor) GetParams() map[string]interface{} {
+	return base.GetFieldParams(m)
+}
+
+// SetParams sets the parameters of HistGradientBoostingRegressor. see base.SetFieldParams
+func (m *HistGradientBoostingRegressor) SetParams(params map[string]interface{}) error {
+	return base.SetFieldParams(m, params)
+}
+
+// GetParams returns the parameters of AdaBoostClassifier. see base.GetFieldParams
+func (m *AdaBoostClassifier) GetParams() map[string]interface{} { return base.GetFieldParams(m) }
+
+// SetParams sets the parameters of AdaBoostClassifier. see base.SetFieldParams
+func (m *AdaBoostClassifier) SetParams(params map[string]interface{}) error {
+	return base.SetFieldParams(m, params)
+}
+
+// GetParams returns the parameters of AdaBoostRegressor. see base.GetFieldParams
+func (m *AdaBoostRegressor) GetParams() map[string]interface{} { return base.GetFieldParams(m) }
+
+// SetParams sets the parameters of AdaBoostRegressor. see base.SetFieldParams
+func (m *AdaBoostRegressor) SetParams(params map[string]interface{}) error {
+	return base.SetFieldParams(m, params)
+}
+
+// GetParams returns the parameters of BaggingClassifier. see base.GetFieldParams
+func (m *BaggingClassifier) GetParams() map[string]interface{} { return base.GetFieldParams(m) }
+
+// SetParams sets the parameters of BaggingClassifier. see base.SetFieldParams
+func (m *BaggingClassifier) SetParams(params map[string]interface{}) error {
+	return base.SetFieldParams(m, params)
+}
+
+// GetParams returns the parameters of BaggingRegressor. see base.GetFieldParams
+func (m *BaggingRegressor) GetParams() map[string]interface{} { return base.GetFieldParams(m) }
+
+// SetParams sets the parameters of BaggingRegressor. see base.SetFieldParams
+func (m *BaggingRegressor) SetParams(params map[string]interface{}) error {
+	return base.SetFieldParams(m, params)
+}
+
+// GetParams returns the parameters of VotingClassifier, its estimators by name and their params as "name__param"
+func (m *VotingClassifier) GetParams() map[string]interface{} {
+	return namedEstimatorsParams(base.GetFieldParams(m), m.Estimators)
+}
+
+// SetParams sets the parameters of VotingClassifier. a key which is an estimator name replaces the estimator, and "name__param" sets its param
+func (m *VotingClassifier) SetParams(params map[string]interface{}) error {
+	return setNamedEstimatorsParams(m, m.Estimators, params)
+}
+
+// GetParams returns the parameters of VotingRegressor, its estimators by name and their params as "name__param"
+func (m *VotingRegressor) GetParams() map[string]interface{} {
+	return namedEstimatorsParams(base.GetFieldParams(m), m.Estimators)
+}
+
+// SetParams sets the parameters of VotingRegressor. a key which is an estimator name replaces the estimator, and "name__param" sets its param
+func (m *VotingRegressor) SetParams(params map[string]interface{}) error {
+	return setNamedEstimatorsParams(m, m.Estimators, params)
+}
+
+// GetParams returns the parameters of StackingClassifier, its estimators by name and their params as "name__param"
+func (m *StackingClassifier) GetParams() map[string]interface{} {
+	return namedEstimatorsParams(base.GetFieldParams(m), m.Estimators)
+}
+
+// SetParams sets the parameters of StackingClassifier. a key which is an estimator name replaces the estimator, and "name__param" sets its param
+func (m *StackingClassifier) SetParams(params map[string]interface{}) error {
+	return setNamedEstimatorsParams(m, m.Estimators, params)
+}
+
+// GetParams returns the parameters of StackingRegressor, its estimators by name and their params as "name__param"
+func (m *StackingRegressor) GetParams() map[string]interface{} {
+	return namedEstimatorsParams(base.GetFieldParams(m), m.Estimators)
+}
+
+// SetParams sets the parameters of StackingRegressor. a key which is an estimator name replaces the estimator, and "name__param" sets its param
+func (m *StackingRegressor) SetParams(params map[string]interface{}) error {
+	return setNamedEstimatorsParams(m, m.Estimators, params)
+}
+
+// namedEstimatorsParams adds to params the estimators by name and their params as "name__param", like pipeline steps
+func namedEstimatorsParams(params map[string]interface{}, estimators []NamedEstimator) map[string]interface{} {
+	for _, est := range estimators {
+		params[est.Name] = est.Predicter
+		for k, v := range base.GetParams(est.Predicter) {
+			params[est.Name+base.ParamSeparator+k] = v
+		}
+	}
+	return params
+}
+
+// setNamedEstimatorsParams sets the params of the estimators named by their keys, and the other params with base.SetFieldParams
+func setNamedEstimatorsParams(m interface{}, estimators []NamedEstimator, params map[string]interface{}) error {
+	fieldParams := make(map[string]interface{})
+	for k, v := range params {
+		name := k
+		if i := strings.Index(k, base.ParamSeparator); i >= 0 {
+			name = k[:i]
+		}
+		t := namedEstimatorIndex(estimators, name)
+		switch {
+		case t < 0:
+			fieldParams[k] = v
+		case name == k:
+			est, ok := v.(base.Predicter)
+			if !ok {
+				return fmt.Errorf("%w: estimator %s: %T is not a Predicter", base.ErrInvalidParam, name, v)
+			}
+			estimators[t].Predicter = est
+		default:
+			if err := base.SetParams(estimators[t].Predicter, map[string]interface{}{k[len(name)+len(base.ParamSeparator):]: v}); err != nil {
+				return fmt.Errorf("estimator %s: %w", name, err)
+			}
+		}
+	}
+	return base.SetFieldParams(m, fieldParams)
+}
+
+func namedEstimatorIndex(estimators []NamedEstimator, name string) int {
+	for t, est := range estimators {
+		if est.Name == name {
+			return t
+		}
+	}
+	for t, est := range estimators {
+		if base.ParamNameEqual(est.Name, name) {
+			return t
+		}
+	}
+	return -1
+}
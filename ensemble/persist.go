@@ -0,0 +1,156 @@
+package ensemble
+
+import "github.com/pa-m/sklearn/base"
+
+func init() {
+	base.Register(&RandomForestClassifier{})
+	base.Register(&RandomForestRegressor{})
+	base.Register(&ExtraTreesClassifier{})
+	base.Register(&ExtraTreesRegressor{})
+	base.Register(&GradientBoostingClassifier{})
+	base.Register(&GradientBoostingRegressor{})
+	base.Register(&HistGradientBoostingClassifier{})
+	base.Register(&HistGradientBoostingRegressor{})
+	base.Register(&AdaBoostClassifier{})
+	base.Register(&AdaBoostRegressor{})
+	base.Register(&BaggingClassifier{})
+	base.Register(&BaggingRegressor{})
+	base.Register(&VotingClassifier{})
+	base.Register(&VotingRegressor{})
+	base.Register(&StackingClassifier{})
+	base.Register(&StackingRegressor{})
+}
+
+// MarshalState allows RandomForestClassifier to be saved by base.Save
+func (m *RandomForestClassifier) MarshalState() (*base.State, error) { return base.MarshalFields(m) }
+
+// UnmarshalState restores a RandomForestClassifier saved by base.Save
+func (m *RandomForestClassifier) UnmarshalState(st *base.State) error {
+	return base.UnmarshalFields(m, st)
+}
+
+// MarshalState allows RandomForestRegressor to be saved by base.Save
+func (m *RandomForestRegressor) MarshalState() (*base.State, error) { return base.MarshalFields(m) }
+
+// UnmarshalState restores a RandomForestRegressor saved by base.Save
+func (m *RandomForestRegressor) UnmarshalState(st *base.State) error {
+	return base.UnmarshalFields(m, st)
+}
+
+// MarshalState allows ExtraTreesClassifier to be saved by base.Save
+func (m *ExtraTreesClassifier) MarshalState() (*base.State, error) { return base.MarshalFields(m) }
+
+// UnmarshalState restores a ExtraTreesClassifier saved by base.Save
+func (m *ExtraTreesClassifier) UnmarshalState(st *base.State) error {
+	return base.UnmarshalFields(m, st)
+}
+
+// MarshalState allows ExtraTreesRegressor to be saved by base.Save
+func (m *ExtraTreesRegressor) MarshalState() (*base.State, error) { return base.MarshalFields(m) }
+
+// UnmarshalState restores a ExtraTreesRegressor saved by base.Save
+func (m *ExtraTreesRegressor) UnmarshalState(st *base.State) error {
+	return base.UnmarshalFields(m, st)
+}
+
+// MarshalState allows GradientBoostingClassifier to be saved by base.Save
+func (m *GradientBoostingClassifier) MarshalState() (*base.State, error) {
+	return base.MarshalFields(m)
+}
+
+// UnmarshalState restores a GradientBoostingClassifier saved by base.Save
+func (m *GradientBoostingClassifier) UnmarshalState(st *base.State) error {
+	return base.UnmarshalFields(m, st)
+}
+
+// MarshalState allows GradientBoostingRegressor to be saved by base.Save
+func (m *GradientBoostingRegressor) MarshalState() (*base.State, error) { return base.MarshalFields(m) }
+
+// UnmarshalState restores a GradientBoostingRegressor saved by base.Save
+func (m *GradientBoostingRegressor) UnmarshalState(st *base.State) error {
+	return base.UnmarshalFields(m, st)
+}
+
+// MarshalState allows HistGradientBoostingClassifier to be saved by base.Save
+func (m *HistGradientBoostingClassifier) MarshalState() (*base.State, error) {
+	return base.MarshalFields(m)
+}
+
+// UnmarshalState restores a HistGradientBoostingClassifier saved by base.Save
+func (m *HistGradientBoostingClassifier) UnmarshalState(st *base.State) error {
+	return base.UnmarshalFields(m, st)
+}
+
+// MarshalState allows HistGradientBoostingRegressor to be saved by base.Save
+func (m *HistGradientBoostingRegressor) MarshalState() (*base.State, error) {
+	return base.MarshalFields(m)
+}
+
+// UnmarshalState restores a HistGradientBoostingRegressor saved by base.Save
+func (m *HistGradientBoostingRegressor) UnmarshalState(st *base.State) error {
+	return base.UnmarshalFields(m, st)
+}
+
+// MarshalState allows AdaBoostClassifier to be saved by base.Save
+func (m *AdaBoostClassifier) MarshalState() (*base.State, error) { return base.MarshalFields(m) }
+
+// UnmarshalState restores a AdaBoostClassifier saved by base.Save
+func (m *AdaBoostClassifier) UnmarshalState(st *base.State) error {
+	return base.UnmarshalFields(m, st)
+}
+
+// MarshalState allows AdaBoostRegressor to be saved by base.Save
+func (m *AdaBoostRegressor) MarshalState() (*base.State, error) { return base.MarshalFields(m) }
+
+// UnmarshalState restores a AdaBoostRegressor saved by base.Save
+func (m *AdaBoostRegressor) UnmarshalState(st *base.State) error {
+	return base.UnmarshalFields(m, st)
+}
+
+// MarshalState allows BaggingClassifier to be saved by base.Save
+func (m *BaggingClassifier) MarshalState() (*base.State, error) { return base.MarshalFields(m) }
+
+// UnmarshalState restores a BaggingClassifier saved by base.Save
+func (m *BaggingClassifier) UnmarshalState(st *base.State) error {
+	return base.UnmarshalFields(m, st)
+}
+
+// MarshalState allows BaggingRegressor to be saved by base.Save
+func (m *BaggingRegressor) MarshalState() (*base.State, error) { return base.MarshalFields(m) }
+
+// UnmarshalState restores a BaggingRegressor saved by base.Save
+func (m *BaggingRegressor) UnmarshalState(st *base.State) error {
+	return base.UnmarshalFields(m, st)
+}
+
+// MarshalState allows VotingClassifier to be saved by base.Save
+func (m *VotingClassifier) MarshalState() (*base.State, error) { return base.MarshalFields(m) }
+
+// UnmarshalState restores a VotingClassifier saved by base.Save
+func (m *VotingClassifier) UnmarshalState(st *base.State) error {
+	return base.UnmarshalFields(m, st)
+}
+
+// MarshalState allows VotingRegressor to be saved by base.Save
+func (m *VotingRegressor) MarshalState() (*base.State, error) { return base.MarshalFields(m) }
+
+// UnmarshalState restores a VotingRegressor saved by base.Save
+func (m *VotingRegressor) UnmarshalState(st *base.State) error {
+	return base.UnmarshalFields(m, st)
+}
+
+// MarshalState allows StackingClassifier to be saved by base.Save. CV and RandomState are not saved
+func (m *StackingClassifier) MarshalState() (*base.State, error) { return base.MarshalFields(m) }
+
+// UnmarshalState restores a StackingClassifier saved by base.Save
+func (m *StackingClassifier) UnmarshalState(st *base.State) error {
+	return base.UnmarshalFields(m, st)
+}
+
+// MarshalState allows StackingRegressor to be saved by base.Save. CV and RandomState are not saved
+func (m *StackingRegressor) MarshalState() (*base.State, error) { return base.MarshalFields(m) }
+
+// UnmarshalState restores a StackingRegressor saved by base.Save
+func (m *StackingRegressor) UnmarshalState(st *base.State) error {
+	return base.UnmarshalFields(m, st)
+}
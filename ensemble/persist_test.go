@@ -0,0 +1,65 @@
+package ensemble
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/pa-m/sklearn/base"
+	"github.com/pa-m/sklearn/datasets"
+	linearmodel "github.com/pa-m/sklearn/linear_model"
+	"github.com/pa-m/sklearn/tree"
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestSaveLoad(t *testing.T) {
+	ds := datasets.LoadIris()
+	for _, m := range []base.Predicter{
+		NewRandomForestClassifier(), NewRandomForestRegressor(), NewExtraTreesClassifier(), NewExtraTreesRegressor(),
+		NewGradientBoostingClassifier(), NewGradientBoostingRegressor(), NewHistGradientBoostingClassifier(), NewHistGradientBoostingRegressor(),
+		NewAdaBoostClassifier(), NewAdaBoostRegressor(), NewBaggingClassifier(), NewBaggingRegressor(),
+		NewVotingClassifier(NamedEstimator{"dt", tree.NewDecisionTreeClassifier()}, NamedEstimator{"lr", linearmodel.NewLogisticRegression()}),
+		NewVotingRegressor(NamedEstimator{"dt", tree.NewDecisionTreeRegressor()}, NamedEstimator{"lr", linearmodel.NewLinearRegression()}),
+		NewStackingClassifier(NamedEstimator{"dt", tree.NewDecisionTreeClassifier()}, NamedEstimator{"lr", linearmodel.NewLogisticRegression()}),
+		NewStackingRegressor(NamedEstimator{"dt", tree.NewDecisionTreeRegressor()}, NamedEstimator{"lr", linearmodel.NewLinearRegression()}),
+	} {
+		params := map[string]interface{}{}
+		for _, nIter := range []string{"MaxIter", "NEstimators"} {
+			if _, ok := base.GetParams(m)[nIter]; ok {
+				params[nIter] = 5
+				break
+			}
+		}
+		if _, ok := base.GetParams(m)["RandomState"]; ok {
+			params["RandomState"] = base.NewSource(7)
+		}
+		if err := base.SetParams(m, params); err != nil {
+			t.Fatal(err)
+		}
+		m.Fit(ds.X, ds.Y)
+		buf := new(bytes.Buffer)
+		if err := base.Save(buf, m.(base.Persister)); err != nil {
+			t.Fatalf("%T: %s", m, err)
+		}
+		loaded, err := base.Load(buf)
+		if err != nil {
+			t.Fatalf("%T: %s", m, err)
+		}
+		if reflect.TypeOf(loaded) != reflect.TypeOf(m) || !mat.Equal(m.Predict(ds.X, nil), loaded.Predict(ds.X, nil)) {
+			t.Errorf("%T: loaded model predictions differ", m)
+		}
+	}
+	m := NewRandomForestClassifier()
+	m.NEstimators, m.MaxFeatures = 5, .5
+	buf := new(bytes.Buffer)
+	if err := base.Save(buf, m.Fit(ds.X, ds.Y).(*RandomForestClassifier)); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := base.Load(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mf := loaded.(*RandomForestClassifier).MaxFeatures; mf != .5 {
+		t.Errorf("MaxFeatures not restored: %#v", mf)
+	}
+}
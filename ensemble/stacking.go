@@ -0,0 +1,293 @@
+package ensemble
+
+import (
+	"fmt"
+
+	"github.com/pa-m/sklearn/base"
+	linearmodel "github.com/pa-m/sklearn/linear_model"
+	"github.com/pa-m/sklearn/metrics"
+	modelselection "github.com/pa-m/sklearn/model_selection"
+	"gonum.org/v1/gonum/mat"
+)
+
+// BaseStacking holds the parameters shared by stacking meta-estimators, which fit FinalEstimator on the out-of-fold predictions
+// of Estimators, computed by modelselection.CrossValPredict on the folds of CV. the test folds of CV must be a partition
+// of the samples, nil meaning a 5-fold KFold shuffled by RandomState. all estimators are cross-validated on the same folds,
+// then fitted on the whole training set to compute the features of FinalEstimator at prediction time.
+// if Passthrough is set, FinalEstimator is also given the original features.
+// estimators are fitted in parallel by NJobs threads, NJobs <= 0 meaning runtime.NumCPU()
+type BaseStacking struct {
+	Estimators     []NamedEstimator
+	FinalEstimator base.Predicter
+	CV             modelselection.Splitter `persist:"-"`
+	Passthrough    bool
+	NJobs          int
+	RandomState    base.RandomState
+
+	// runtime filled members
+	NFeatures            int              `param:"-"`
+	FittedEstimators     []base.Predicter `param:"-"`
+	FittedFinalEstimator base.Predicter   `param:"-"`
+}
+
+func (m *BaseStacking) resetFitted() {
+	m.NFeatures, m.FittedEstimators, m.FittedFinalEstimator = 0, nil, nil
+}
+
+func (m *BaseStacking) clone() BaseStacking {
+	clone := *m
+	clone.Estimators = cloneNamedEstimators(m.Estimators)
+	if m.FinalEstimator != nil {
+		clone.FinalEstimator = m.FinalEstimator.PredicterClone()
+	}
+	if m.CV != nil {
+		clone.CV = m.CV.SplitterClone()
+	}
+	clone.resetFitted()
+	return clone
+}
+
+// IsFitted returns true when the final estimator has been fitted
+func (m *BaseStacking) IsFitted() bool { return m.FittedFinalEstimator != nil }
+
+// fixedSplitter replays the splits it holds, so that all estimators are cross-validated on the same folds
+type fixedSplitter []modelselection.Split
+
+func (s fixedSplitter) Split(X, Y *mat.Dense) chan modelselection.Split {
+	ch := make(chan modelselection.Split, len(s))
+	for _, split := range s {
+		ch <- split
+	}
+	close(ch)
+	return ch
+}
+
+func (s fixedSplitter) GetNSplits(X, Y *mat.Dense) int { return len(s) }
+
+func (s fixedSplitter) SplitterClone() modelselection.Splitter { return s }
+
+// fit fits the estimators and a clone of the final estimator, which defaults to final.
+// features returns the features of the final estimator given by a fitted estimator
+func (m *BaseStacking) fit(X, Y *mat.Dense, sampleWeight []float64, final base.Predicter, features func(est base.Predicter, X *mat.Dense) *mat.Dense) {
+	if err := base.CheckSampleWeight(X, sampleWeight); err != nil {
+		panic(err)
+	}
+	if m.FinalEstimator != nil {
+		final = m.FinalEstimator
+	}
+	cv := m.CV
+	if cv == nil {
+		cv = &modelselection.KFold{NSplits: 5, Shuffle: true, RandomState: m.RandomState}
+	}
+	var splits fixedSplitter
+	for split := range cv.SplitterClone().Split(X, Y) {
+		splits = append(splits, split)
+	}
+	fitted := fitNamedEstimators(m.Estimators, X, Y, sampleWeight, m.NJobs)
+	oof := make([]*mat.Dense, len(m.Estimators))
+	for t, est := range m.Estimators {
+		P, err := modelselection.CrossValPredict(est.Predicter, X, Y, sampleWeight, splits, m.NJobs, features)
+		if err != nil {
+			panic(fmt.Errorf("estimator %q: %w", est.Name, err))
+		}
+		oof[t] = P
+	}
+	finalEst := final.PredicterClone()
+	if err := base.FitWeighted(finalEst, m.stack(X, oof), Y, sampleWeight); err != nil {
+		panic(fmt.Errorf("final estimator: %w", err))
+	}
+	_, m.NFeatures = X.Dims()
+	m.FittedEstimators, m.FittedFinalEstimator = fitted, finalEst
+}
+
+// stack returns the columns of the features given by each estimator, followed by X if Passthrough is set
+func (m *BaseStacking) stack(X *mat.Dense, features []*mat.Dense) *mat.Dense {
+	if m.Passthrough {
+		features = append(features, X)
+	}
+	nSamples, _ := X.Dims()
+	nCols := 0
+	for _, F := range features {
+		_, c := F.Dims()
+		nCols += c
+	}
+	S := mat.NewDense(nSamples, nCols, nil)
+	col := 0
+	for _, F := range features {
+		_, c := F.Dims()
+		S.Slice(0, nSamples, col, col+c).(*mat.Dense).Copy(F)
+		col += c
+	}
+	return S
+}
+
+// transform returns the features of the final estimator for X, given by the fitted estimators
+func (m *BaseStacking) transform(X *mat.Dense, features func(est base.Predicter, X *mat.Dense) *mat.Dense) *mat.Dense {
+	F := make([]*mat.Dense, len(m.FittedEstimators))
+	for t, est := range m.FittedEstimators {
+		F[t] = features(est, X)
+	}
+	return m.stack(X, F)
+}
+
+// StackingClassifier is a stacking of classifiers of a single output. the features of FinalEstimator are the class probabilities
+// given by the estimators which are a base.ProbaPredicter, without the first class for binary classification,
+// and the classes predicted by the others. see BaseStacking. FinalEstimator defaults to a LogisticRegression
+type StackingClassifier struct {
+	BaseStacking
+	// runtime filled members
+	Classes []float64 `param:"-"`
+}
+
+// NewStackingClassifier returns a StackingClassifier of estimators, whose predictions are combined by a LogisticRegression
+func NewStackingClassifier(estimators ...NamedEstimator) *StackingClassifier {
+	return &StackingClassifier{BaseStacking: BaseStacking{Estimators: estimators, FinalEstimator: linearmodel.NewLogisticRegression()}}
+}
+
+// PredicterClone returns an unfitted copy of predicter
+func (m *StackingClassifier) PredicterClone() base.Predicter {
+	clone := StackingClassifier{BaseStacking: m.BaseStacking.clone()}
+	return base.DeepCopy(&clone).(*StackingClassifier)
+}
+
+// IsClassifier returns true for StackingClassifier
+func (*StackingClassifier) IsClassifier() bool { return true }
+
+// GetNOutputs returns the number of columns of Y
+func (m *StackingClassifier) GetNOutputs() int { return 1 }
+
+// features returns the class probabilities given by est, or its predicted classes if it is not a base.ProbaPredicter
+func (m *StackingClassifier) features(est base.Predicter, X *mat.Dense) *mat.Dense {
+	if _, ok := est.(base.ProbaPredicter); !ok {
+		return est.Predict(X, nil)
+	}
+	P := estimatorProba(est, X, [][]float64{m.Classes})
+	if len(m.Classes) == 2 {
+		nSamples, _ := P.Dims()
+		return mat.DenseCopyOf(P.Slice(0, nSamples, 1, 2))
+	}
+	return P
+}
+
+// Fit fits the estimators and the final estimator from X and the class labels Y
+func (m *StackingClassifier) Fit(X, Y mat.Matrix) base.Fiter {
+	return m.FitWeighted(X, Y, nil)
+}
+
+// FitWeighted is Fit with samples weighted by sampleWeight. estimators which are not a base.WeightedFiter only accept uniform weights
+func (m *StackingClassifier) FitWeighted(Xmatrix, Ymatrix mat.Matrix, sampleWeight []float64) base.Fiter {
+	X, Y := base.ToDense(Xmatrix), base.ToDense(Ymatrix)
+	m.Classes, _ = classIndices(Y)
+	m.fit(X, Y, sampleWeight, linearmodel.NewLogisticRegression(), m.features)
+	return m
+}
+
+// PredictProba returns the class probabilities given by the final estimator, or the one-hot encoding of its predicted classes
+// if it has no PredictProba. see base.ProbaPredicter
+func (m *StackingClassifier) PredictProba(X mat.Matrix, Ymutable mat.Mutable) *mat.Dense {
+	base.MustBeFitted(m)
+	P := estimatorProba(m.FittedFinalEstimator, m.transform(base.ToDense(X), m.features), [][]float64{m.Classes})
+	return base.FromDense(Ymutable, P)
+}
+
+// Predict returns the classes predicted by the final estimator
+func (m *StackingClassifier) Predict(X mat.Matrix, Ymutable mat.Mutable) *mat.Dense {
+	base.MustBeFitted(m)
+	return m.FittedFinalEstimator.Predict(m.transform(base.ToDense(X), m.features), Ymutable)
+}
+
+// FitE is Fit returning an error instead of panicking
+func (m *StackingClassifier) FitE(X, Y mat.Matrix) error {
+	return base.FitE(m, X, Y)
+}
+
+// PredictE is Predict returning an error instead of panicking
+func (m *StackingClassifier) PredictE(X mat.Matrix, Y mat.Mutable) (*mat.Dense, error) {
+	if !m.IsFitted() {
+		return nil, base.ErrNotFitted
+	}
+	if err := base.CheckNFeatures(X, m.NFeatures); err != nil {
+		return nil, err
+	}
+	return base.PredictE(m, X, Y)
+}
+
+// Score for StackingClassifier returns the accuracy of Predict
+func (m *StackingClassifier) Score(X, Y mat.Matrix) float64 {
+	NSamples, NOutputs := Y.Dims()
+	Ypred := mat.NewDense(NSamples, NOutputs, nil)
+	m.Predict(X, Ypred)
+	return metrics.AccuracyScore(Y, Ypred, true, nil)
+}
+
+// StackingRegressor is a stacking of regressors. the features of FinalEstimator are the predictions of the estimators.
+// see BaseStacking. FinalEstimator defaults to a Ridge
+type StackingRegressor struct {
+	BaseStacking
+	// runtime filled members
+	NOutputs int `param:"-"`
+}
+
+// NewStackingRegressor returns a StackingRegressor of estimators, whose predictions are combined by a Ridge
+func NewStackingRegressor(estimators ...NamedEstimator) *StackingRegressor {
+	return &StackingRegressor{BaseStacking: BaseStacking{Estimators: estimators, FinalEstimator: linearmodel.NewRidge()}}
+}
+
+// PredicterClone returns an unfitted copy of predicter
+func (m *StackingRegressor) PredicterClone() base.Predicter {
+	clone := StackingRegressor{BaseStacking: m.BaseStacking.clone()}
+	return base.DeepCopy(&clone).(*StackingRegressor)
+}
+
+// IsClassifier returns false for StackingRegressor
+func (*StackingRegressor) IsClassifier() bool { return false }
+
+// GetNOutputs returns the number of columns of Y
+func (m *StackingRegressor) GetNOutputs() int { return m.NOutputs }
+
+func (m *StackingRegressor) features(est base.Predicter, X *mat.Dense) *mat.Dense {
+	return est.Predict(X, nil)
+}
+
+// Fit fits the estimators and the final estimator from X and the targets Y
+func (m *StackingRegressor) Fit(X, Y mat.Matrix) base.Fiter {
+	return m.FitWeighted(X, Y, nil)
+}
+
+// FitWeighted is Fit with samples weighted by sampleWeight. estimators which are not a base.WeightedFiter only accept uniform weights
+func (m *StackingRegressor) FitWeighted(Xmatrix, Ymatrix mat.Matrix, sampleWeight []float64) base.Fiter {
+	X, Y := base.ToDense(Xmatrix), base.ToDense(Ymatrix)
+	_, m.NOutputs = Y.Dims()
+	m.fit(X, Y, sampleWeight, linearmodel.NewRidge(), m.features)
+	return m
+}
+
+// Predict returns the predictions of the final estimator
+func (m *StackingRegressor) Predict(X mat.Matrix, Ymutable mat.Mutable) *mat.Dense {
+	base.MustBeFitted(m)
+	return m.FittedFinalEstimator.Predict(m.transform(base.ToDense(X), m.features), Ymutable)
+}
+
+// FitE is Fit returning an error instead of panicking
+func (m *StackingRegressor) FitE(X, Y mat.Matrix) error {
+	return base.FitE(m, X, Y)
+}
+
+// PredictE is Predict returning an error instead of panicking
+func (m *StackingRegressor) PredictE(X mat.Matrix, Y mat.Mutable) (*mat.Dense, error) {
+	if !m.IsFitted() {
+		return nil, base.ErrNotFitted
+	}
+	if err := base.CheckNFeatures(X, m.NFeatures); err != nil {
+		return nil, err
+	}
+	return base.PredictE(m, X, Y)
+}
+
+// Score for StackingRegressor returns the R2 score of Predict
+func (m *StackingRegressor) Score(X, Y mat.Matrix) float64 {
+	NSamples, NOutputs := Y.Dims()
+	Ypred := mat.NewDense(NSamples, NOutputs, nil)
+	m.Predict(X, Ypred)
+	return metrics.R2Score(Y, Ypred, nil, "").At(0, 0)
+}
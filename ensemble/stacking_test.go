@@ -0,0 +1,119 @@
+package ensemble
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/pa-m/sklearn/base"
+	"github.com/pa-m/sklearn/datasets"
+	linearmodel "github.com/pa-m/sklearn/linear_model"
+	modelselection "github.com/pa-m/sklearn/model_selection"
+	"github.com/pa-m/sklearn/tree"
+	"gonum.org/v1/gonum/mat"
+)
+
+func ExampleStackingClassifier() {
+	ds := datasets.LoadIris()
+	X, Y := ds.X, ds.Y
+	dt := tree.NewDecisionTreeClassifier()
+	dt.MaxDepth = 2
+	rf := NewRandomForestClassifier()
+	rf.NEstimators = 10
+	rf.RandomState = base.NewSource(7)
+	clf := NewStackingClassifier(NamedEstimator{"dt", dt}, NamedEstimator{"rf", rf})
+	clf.RandomState = base.NewSource(7)
+	clf.Fit(X, Y)
+	fmt.Printf("accuracy: %.2f\n", clf.Score(X, Y))
+	// Output:
+	// accuracy: 0.99
+}
+
+func ExampleStackingRegressor() {
+	ds := datasets.LoadDiabetes()
+	dt := tree.NewDecisionTreeRegressor()
+	dt.MaxDepth = 3
+	reg := NewStackingRegressor(NamedEstimator{"dt", dt}, NamedEstimator{"lr", linearmodel.NewLinearRegression()})
+	reg.RandomState = base.NewSource(7)
+	reg.Fit(ds.X, ds.Y)
+	fmt.Printf("R2: %.2f\n", reg.Score(ds.X, ds.Y))
+	// Output:
+	// R2: 0.52
+}
+
+func TestStackingRegressor(t *testing.T) {
+	ds := datasets.LoadDiabetes()
+	dt := tree.NewDecisionTreeRegressor()
+	dt.MaxDepth = 3
+	estimators := []NamedEstimator{{"dt", dt}, {"lr", linearmodel.NewLinearRegression()}}
+	for _, passthrough := range []bool{false, true} {
+		reg := NewStackingRegressor(estimators...)
+		reg.FinalEstimator = linearmodel.NewLinearRegression()
+		reg.CV = &modelselection.KFold{NSplits: 3, Shuffle: true, RandomState: base.NewSource(7)}
+		reg.Passthrough = passthrough
+		reg.Fit(ds.X, ds.Y)
+
+		// the final estimator is fitted on the out-of-fold predictions of the estimators
+		cv := &modelselection.KFold{NSplits: 3, Shuffle: true, RandomState: base.NewSource(7)}
+		var oof []*mat.Dense
+		for _, est := range estimators {
+			P, err := modelselection.CrossValPredict(est.Predicter, ds.X, ds.Y, nil, cv.SplitterClone(), 1, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			oof = append(oof, P)
+		}
+		final := linearmodel.NewLinearRegression()
+		final.Fit(reg.stack(ds.X, oof), ds.Y)
+		if !mat.EqualApprox(final.Coef, reg.FittedFinalEstimator.(*linearmodel.LinearRegression).Coef, 1e-9) {
+			t.Errorf("passthrough %v: expected final coefficients %v, got %v", passthrough, mat.Formatted(final.Coef.T()), mat.Formatted(reg.FittedFinalEstimator.(*linearmodel.LinearRegression).Coef.T()))
+		}
+		expectedFeatures := 2
+		if passthrough {
+			expectedFeatures += 10
+		}
+		if r, _ := final.Coef.Dims(); r != expectedFeatures {
+			t.Errorf("passthrough %v: expected %d features, got %d", passthrough, expectedFeatures, r)
+		}
+	}
+
+	reg := NewStackingRegressor(estimators...)
+	reg.CV = &modelselection.KFold{NSplits: 1}
+	if err := reg.FitE(ds.X, ds.Y); !errors.Is(err, base.ErrInvalidParam) {
+		t.Errorf("expected ErrInvalidParam for a CV without train samples, got %v", err)
+	}
+}
+
+func TestStackingClassifier(t *testing.T) {
+	ds := datasets.LoadBreastCancer()
+	dt := tree.NewDecisionTreeClassifier()
+	dt.MaxDepth = 3
+	estimators := []NamedEstimator{{"dt", dt}, {"stump", &unweighted{newStump()}}}
+	clf := NewStackingClassifier(estimators...)
+	clf.FinalEstimator = newStump()
+	clf.CV = &modelselection.KFold{NSplits: 3, Shuffle: true, RandomState: base.NewSource(7)}
+	clf.Fit(ds.X, ds.Y)
+	// the probability of the second class for dt, and the classes predicted by stump
+	F := clf.transform(ds.X, clf.features)
+	if _, c := F.Dims(); c != 2 {
+		t.Fatalf("expected 2 features for the final estimator, got %d", c)
+	}
+	if !mat.Equal(F.ColView(1), clf.FittedEstimators[1].Predict(ds.X, nil).ColView(0)) {
+		t.Error("expected the classes predicted by stump as second feature")
+	}
+	if score := clf.Score(ds.X, ds.Y); score < .95 {
+		t.Errorf("expected accuracy >= .95, got %g", score)
+	}
+	P := clf.PredictProba(ds.X, nil)
+	if _, c := P.Dims(); c != 2 {
+		t.Errorf("expected 2 probability columns, got %d", c)
+	}
+
+	if err := clf.SetParams(map[string]interface{}{"dt__MaxDepth": 1, "FinalEstimator__MaxDepth": 2}); err != nil {
+		t.Fatal(err)
+	}
+	clone := clf.PredicterClone().(*StackingClassifier)
+	if clone.IsFitted() || clone.Estimators[0].Predicter.(*tree.DecisionTreeClassifier).MaxDepth != 1 || clone.FinalEstimator.(*tree.DecisionTreeClassifier).MaxDepth != 2 {
+		t.Errorf("expected an unfitted clone with the same parameters, got %+v", clone)
+	}
+}
@@ -0,0 +1,306 @@
+package ensemble
+
+import (
+	"fmt"
+
+	"github.com/pa-m/sklearn/base"
+	"github.com/pa-m/sklearn/metrics"
+	"gonum.org/v1/gonum/mat"
+)
+
+// NamedEstimator is a named estimator of a voting or stacking ensemble
+type NamedEstimator struct {
+	Name string
+	base.Predicter
+}
+
+// fitNamedEstimators returns clones of estimators fitted with base.FitWeighted in parallel by nJobs threads.
+// names must be distinct and not empty. sampleWeight may be nil
+func fitNamedEstimators(estimators []NamedEstimator, X, Y *mat.Dense, sampleWeight []float64, nJobs int) []base.Predicter {
+	if len(estimators) == 0 {
+		panic(fmt.Errorf("%w: Estimators is empty", base.ErrInvalidParam))
+	}
+	names := make(map[string]bool)
+	for _, est := range estimators {
+		if est.Name == "" || names[est.Name] {
+			panic(fmt.Errorf("%w: estimator names must be distinct and not empty, got %q", base.ErrInvalidParam, est.Name))
+		}
+		if est.Predicter == nil {
+			panic(fmt.Errorf("%w: estimator %q is nil", base.ErrInvalidParam, est.Name))
+		}
+		names[est.Name] = true
+	}
+	fitted := make([]base.Predicter, len(estimators))
+	parallelFit(nJobs, len(estimators), func(t int) {
+		est := estimators[t].PredicterClone()
+		if err := base.FitWeighted(est, X, Y, sampleWeight); err != nil {
+			panic(fmt.Errorf("estimator %q: %w", estimators[t].Name, err))
+		}
+		fitted[t] = est
+	})
+	return fitted
+}
+
+// cloneNamedEstimators returns unfitted clones of estimators
+func cloneNamedEstimators(estimators []NamedEstimator) []NamedEstimator {
+	if estimators == nil {
+		return nil
+	}
+	clones := make([]NamedEstimator, len(estimators))
+	for i, est := range estimators {
+		clones[i].Name = est.Name
+		if est.Predicter != nil {
+			clones[i].Predicter = est.PredicterClone()
+		}
+	}
+	return clones
+}
+
+// votingWeights checks weights, which is nil or has a non negative weight per estimator, and returns them normalized
+func votingWeights(weights []float64, nEstimators int) []float64 {
+	w := make([]float64, nEstimators)
+	for t := range w {
+		w[t] = 1
+	}
+	if weights != nil {
+		if len(weights) != nEstimators {
+			panic(fmt.Errorf("%w: %d Weights for %d estimators", base.ErrInvalidParam, len(weights), nEstimators))
+		}
+		copy(w, weights)
+	}
+	sum := 0.
+	for _, v := range w {
+		if v < 0 {
+			panic(fmt.Errorf("%w: Weights must be non negative, got %g", base.ErrInvalidParam, v))
+		}
+		sum += v
+	}
+	if sum == 0 {
+		panic(fmt.Errorf("%w: all Weights are zero", base.ErrInvalidParam))
+	}
+	for t := range w {
+		w[t] /= sum
+	}
+	return w
+}
+
+// weightedMean returns the mean of predict applied to each estimator, weighted by weights
+func weightedMean(estimators []base.Predicter, weights []float64, nSamples, nValues int, predict func(est base.Predicter) *mat.Dense) *mat.Dense {
+	w := votingWeights(weights, len(estimators))
+	Y := mat.NewDense(nSamples, nValues, nil)
+	for t, est := range estimators {
+		if w[t] > 0 {
+			P := predict(est)
+			Y.Apply(func(i, j int, v float64) float64 { return v + w[t]*P.At(i, j) }, Y)
+		}
+	}
+	return Y
+}
+
+// VotingClassifier predicts the classes chosen by its estimators, which are fitted on the whole training set.
+// Voting is "hard" (the default) to predict the class predicted by the most estimators, or "soft" to predict the class of highest
+// mean probability, for which estimators must be base.ProbaPredicter. Weights weight the votes or probabilities of the estimators,
+// nil meaning uniform weights. estimators are fitted in parallel by NJobs threads, NJobs <= 0 meaning runtime.NumCPU()
+type VotingClassifier struct {
+	Estimators []NamedEstimator
+	Voting     string
+	Weights    []float64
+	NJobs      int
+
+	// runtime filled members
+	NFeatures        int              `param:"-"`
+	Classes          [][]float64      `param:"-"`
+	FittedEstimators []base.Predicter `param:"-"`
+}
+
+// NewVotingClassifier returns a hard VotingClassifier of estimators
+func NewVotingClassifier(estimators ...NamedEstimator) *VotingClassifier {
+	return &VotingClassifier{Estimators: estimators, Voting: "hard"}
+}
+
+// PredicterClone returns an unfitted copy of predicter
+func (m *VotingClassifier) PredicterClone() base.Predicter {
+	clone := *m
+	clone.Estimators = cloneNamedEstimators(m.Estimators)
+	clone.NFeatures, clone.Classes, clone.FittedEstimators = 0, nil, nil
+	return base.DeepCopy(&clone).(*VotingClassifier)
+}
+
+// IsClassifier returns true for VotingClassifier
+func (*VotingClassifier) IsClassifier() bool { return true }
+
+// IsFitted returns true when the estimators have been fitted
+func (m *VotingClassifier) IsFitted() bool { return m.FittedEstimators != nil }
+
+// GetNOutputs returns the number of columns of Y
+func (m *VotingClassifier) GetNOutputs() int { return len(m.Classes) }
+
+// Fit fits clones of the estimators from X and the class labels Y
+func (m *VotingClassifier) Fit(X, Y mat.Matrix) base.Fiter {
+	return m.FitWeighted(X, Y, nil)
+}
+
+// FitWeighted is Fit with samples weighted by sampleWeight. estimators which are not a base.WeightedFiter only accept uniform weights
+func (m *VotingClassifier) FitWeighted(Xmatrix, Ymatrix mat.Matrix, sampleWeight []float64) base.Fiter {
+	X, Y := base.ToDense(Xmatrix), base.ToDense(Ymatrix)
+	switch m.Voting {
+	case "", "hard":
+	case "soft":
+		for _, est := range m.Estimators {
+			if _, ok := est.Predicter.(base.ProbaPredicter); !ok {
+				panic(fmt.Errorf("%w: soft Voting needs PredictProba, which %q lacks", base.ErrInvalidParam, est.Name))
+			}
+		}
+	default:
+		panic(fmt.Errorf("%w: Voting must be \"hard\" or \"soft\", got %q", base.ErrInvalidParam, m.Voting))
+	}
+	votingWeights(m.Weights, len(m.Estimators))
+	_, nOutputs := Y.Dims()
+	classes := make([][]float64, nOutputs)
+	for o := range classes {
+		classes[o] = uniqueSorted(mat.Col(nil, o, Y))
+	}
+	m.FittedEstimators = fitNamedEstimators(m.Estimators, X, Y, sampleWeight, m.NJobs)
+	_, m.NFeatures = X.Dims()
+	m.Classes = classes
+	return m
+}
+
+// PredictProba returns the weighted mean of the class probabilities of the estimators for soft Voting,
+// and the weighted fraction of the votes for each class for hard Voting. see base.ProbaPredicter
+func (m *VotingClassifier) PredictProba(Xmatrix mat.Matrix, Ymutable mat.Mutable) *mat.Dense {
+	base.MustBeFitted(m)
+	X := base.ToDense(Xmatrix)
+	nSamples, _ := X.Dims()
+	nValues := 0
+	for _, cl := range m.Classes {
+		nValues += len(cl)
+	}
+	P := weightedMean(m.FittedEstimators, m.Weights, nSamples, nValues, func(est base.Predicter) *mat.Dense {
+		if m.Voting == "soft" {
+			return estimatorProba(est, X, m.Classes)
+		}
+		return oneHot(est.Predict(X, nil), m.Classes)
+	})
+	return base.FromDense(Ymutable, P)
+}
+
+// Predict returns the class of each output with the most votes, or the highest mean probability for soft Voting
+func (m *VotingClassifier) Predict(X mat.Matrix, Ymutable mat.Mutable) *mat.Dense {
+	Y := base.ToDense(Ymutable)
+	nSamples, _ := X.Dims()
+	if Y.IsZero() {
+		*Y = *mat.NewDense(nSamples, m.GetNOutputs(), nil)
+	}
+	Y.Copy(probaToClasses(m.PredictProba(X, nil), m.Classes))
+	return base.FromDense(Ymutable, Y)
+}
+
+// FitE is Fit returning an error instead of panicking
+func (m *VotingClassifier) FitE(X, Y mat.Matrix) error {
+	return base.FitE(m, X, Y)
+}
+
+// PredictE is Predict returning an error instead of panicking
+func (m *VotingClassifier) PredictE(X mat.Matrix, Y mat.Mutable) (*mat.Dense, error) {
+	if !m.IsFitted() {
+		return nil, base.ErrNotFitted
+	}
+	if err := base.CheckNFeatures(X, m.NFeatures); err != nil {
+		return nil, err
+	}
+	return base.PredictE(m, X, Y)
+}
+
+// Score for VotingClassifier returns the accuracy of Predict
+func (m *VotingClassifier) Score(X, Y mat.Matrix) float64 {
+	NSamples, NOutputs := Y.Dims()
+	Ypred := mat.NewDense(NSamples, NOutputs, nil)
+	m.Predict(X, Ypred)
+	return metrics.AccuracyScore(Y, Ypred, true, nil)
+}
+
+// VotingRegressor predicts the mean of the predictions of its estimators, which are fitted on the whole training set.
+// Weights weight the predictions of the estimators, nil meaning uniform weights.
+// estimators are fitted in parallel by NJobs threads, NJobs <= 0 meaning runtime.NumCPU()
+type VotingRegressor struct {
+	Estimators []NamedEstimator
+	Weights    []float64
+	NJobs      int
+
+	// runtime filled members
+	NFeatures, NOutputs int              `param:"-"`
+	FittedEstimators    []base.Predicter `param:"-"`
+}
+
+// NewVotingRegressor returns a VotingRegressor of estimators
+func NewVotingRegressor(estimators ...NamedEstimator) *VotingRegressor {
+	return &VotingRegressor{Estimators: estimators}
+}
+
+// PredicterClone returns an unfitted copy of predicter
+func (m *VotingRegressor) PredicterClone() base.Predicter {
+	clone := *m
+	clone.Estimators = cloneNamedEstimators(m.Estimators)
+	clone.NFeatures, clone.NOutputs, clone.FittedEstimators = 0, 0, nil
+	return base.DeepCopy(&clone).(*VotingRegressor)
+}
+
+// IsClassifier returns false for VotingRegressor
+func (*VotingRegressor) IsClassifier() bool { return false }
+
+// IsFitted returns true when the estimators have been fitted
+func (m *VotingRegressor) IsFitted() bool { return m.FittedEstimators != nil }
+
+// GetNOutputs returns the number of columns of Y
+func (m *VotingRegressor) GetNOutputs() int { return m.NOutputs }
+
+// Fit fits clones of the estimators from X and the targets Y
+func (m *VotingRegressor) Fit(X, Y mat.Matrix) base.Fiter {
+	return m.FitWeighted(X, Y, nil)
+}
+
+// FitWeighted is Fit with samples weighted by sampleWeight. estimators which are not a base.WeightedFiter only accept uniform weights
+func (m *VotingRegressor) FitWeighted(Xmatrix, Ymatrix mat.Matrix, sampleWeight []float64) base.Fiter {
+	X, Y := base.ToDense(Xmatrix), base.ToDense(Ymatrix)
+	votingWeights(m.Weights, len(m.Estimators))
+	m.FittedEstimators = fitNamedEstimators(m.Estimators, X, Y, sampleWeight, m.NJobs)
+	_, m.NFeatures = X.Dims()
+	_, m.NOutputs = Y.Dims()
+	return m
+}
+
+// Predict returns the weighted mean of the predictions of the estimators
+func (m *VotingRegressor) Predict(Xmatrix mat.Matrix, Ymutable mat.Mutable) *mat.Dense {
+	base.MustBeFitted(m)
+	X := base.ToDense(Xmatrix)
+	nSamples, _ := X.Dims()
+	Y := weightedMean(m.FittedEstimators, m.Weights, nSamples, m.NOutputs, func(est base.Predicter) *mat.Dense {
+		return est.Predict(X, nil)
+	})
+	return base.FromDense(Ymutable, Y)
+}
+
+// FitE is Fit returning an error instead of panicking
+func (m *VotingRegressor) FitE(X, Y mat.Matrix) error {
+	return base.FitE(m, X, Y)
+}
+
+// PredictE is Predict returning an error instead of panicking
+func (m *VotingRegressor) PredictE(X mat.Matrix, Y mat.Mutable) (*mat.Dense, error) {
+	if !m.IsFitted() {
+		return nil, base.ErrNotFitted
+	}
+	if err := base.CheckNFeatures(X, m.NFeatures); err != nil {
+		return nil, err
+	}
+	return base.PredictE(m, X, Y)
+}
+
+// Score for VotingRegressor returns the R2 score of Predict
+func (m *VotingRegressor) Score(X, Y mat.Matrix) float64 {
+	NSamples, NOutputs := Y.Dims()
+	Ypred := mat.NewDense(NSamples, NOutputs, nil)
+	m.Predict(X, Ypred)
+	return metrics.R2Score(Y, Ypred, nil, "").At(0, 0)
+}
@@ -0,0 +1,127 @@
+package ensemble
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/pa-m/sklearn/base"
+	"github.com/pa-m/sklearn/datasets"
+	linearmodel "github.com/pa-m/sklearn/linear_model"
+	"github.com/pa-m/sklearn/tree"
+	"gonum.org/v1/gonum/mat"
+)
+
+var _ = []base.PredicterE{&VotingClassifier{}, &VotingRegressor{}, &StackingClassifier{}, &StackingRegressor{}}
+var _ = []base.ProbaPredicter{&VotingClassifier{}, &StackingClassifier{}}
+var _ = []base.WeightedFiter{&VotingClassifier{}, &VotingRegressor{}, &StackingClassifier{}, &StackingRegressor{}}
+var _ = []base.FittedChecker{&VotingClassifier{}, &VotingRegressor{}, &StackingClassifier{}, &StackingRegressor{}}
+
+func ExampleVotingClassifier() {
+	ds := datasets.LoadIris()
+	dt := tree.NewDecisionTreeClassifier()
+	dt.MaxDepth = 2
+	rf := NewRandomForestClassifier()
+	rf.NEstimators = 10
+	rf.RandomState = base.NewSource(7)
+	for _, voting := range []string{"hard", "soft"} {
+		clf := NewVotingClassifier(NamedEstimator{"dt", dt}, NamedEstimator{"rf", rf}, NamedEstimator{"lr", linearmodel.NewLogisticRegression()})
+		clf.Voting = voting
+		clf.Fit(ds.X, ds.Y)
+		fmt.Printf("%s voting accuracy: %.2f\n", voting, clf.Score(ds.X, ds.Y))
+	}
+	// Output:
+	// hard voting accuracy: 0.99
+	// soft voting accuracy: 0.97
+}
+
+func ExampleVotingRegressor() {
+	ds := datasets.LoadDiabetes()
+	dt := tree.NewDecisionTreeRegressor()
+	dt.MaxDepth = 3
+	reg := NewVotingRegressor(NamedEstimator{"dt", dt}, NamedEstimator{"lr", linearmodel.NewLinearRegression()})
+	reg.Fit(ds.X, ds.Y)
+	fmt.Printf("R2: %.2f\n", reg.Score(ds.X, ds.Y))
+	// Output:
+	// R2: 0.55
+}
+
+func TestVotingClassifier(t *testing.T) {
+	ds := datasets.LoadIris()
+	stump := tree.NewDecisionTreeClassifier()
+	stump.MaxDepth = 1
+	estimators := []NamedEstimator{{"stump", stump}, {"dt", tree.NewDecisionTreeClassifier()}, {"lr", linearmodel.NewLogisticRegression()}}
+
+	// hard voting predicts the class predicted by most estimators, the smallest one on ties
+	clf := NewVotingClassifier(estimators...)
+	clf.Fit(ds.X, ds.Y)
+	Ypred := clf.Predict(ds.X, nil)
+	for i := 0; i < 150; i++ {
+		votes := make(map[float64]int)
+		for _, est := range clf.FittedEstimators {
+			votes[est.Predict(ds.X.Slice(i, i+1, 0, 4), nil).At(0, 0)]++
+		}
+		best := -1.
+		for c := 2.; c >= 0; c-- {
+			if best < 0 || votes[c] >= votes[best] {
+				best = c
+			}
+		}
+		if Ypred.At(i, 0) != best {
+			t.Fatalf("sample %d: votes %v, expected class %g, got %g", i, votes, best, Ypred.At(i, 0))
+		}
+	}
+
+	// an estimator with all the weight decides alone
+	clf.Weights = []float64{1, 0, 0}
+	clf.Voting = "soft"
+	clf.Fit(ds.X, ds.Y)
+	if !mat.Equal(clf.Predict(ds.X, nil), clf.FittedEstimators[0].Predict(ds.X, nil)) {
+		t.Error("expected the predictions of the only weighted estimator")
+	}
+
+	// nested parameters of estimators
+	clf = NewVotingClassifier(estimators...)
+	if err := clf.SetParams(map[string]interface{}{"dt__MaxDepth": 1, "Voting": "soft"}); err != nil {
+		t.Fatal(err)
+	}
+	if d := clf.GetParams()["dt__MaxDepth"]; d != 1 || clf.Voting != "soft" {
+		t.Errorf("expected dt__MaxDepth 1 and soft Voting, got %v and %s", d, clf.Voting)
+	}
+	if err := clf.SetParams(map[string]interface{}{"lr": 1}); !errors.Is(err, base.ErrInvalidParam) {
+		t.Errorf("expected ErrInvalidParam for an estimator which is not a Predicter, got %v", err)
+	}
+
+	for _, m := range []*VotingClassifier{
+		{Estimators: estimators, Voting: "majority"},
+		{Estimators: estimators, Weights: []float64{1, 2}},
+		{Estimators: estimators, Weights: []float64{1, -1, 1}},
+		{Estimators: []NamedEstimator{{"dt", stump}, {"dt", stump}}},
+		{Estimators: []NamedEstimator{{"unweighted", &unweighted{tree.NewDecisionTreeClassifier()}}}, Voting: "soft"},
+		{},
+	} {
+		if err := m.FitE(ds.X, ds.Y); !errors.Is(err, base.ErrInvalidParam) {
+			t.Errorf("%+v: expected ErrInvalidParam, got %v", m, err)
+		}
+	}
+}
+
+func TestVotingRegressor(t *testing.T) {
+	ds := datasets.LoadDiabetes()
+	dt := tree.NewDecisionTreeRegressor()
+	dt.MaxDepth = 3
+	reg := NewVotingRegressor(NamedEstimator{"dt", dt}, NamedEstimator{"lr", linearmodel.NewLinearRegression()})
+	reg.Weights = []float64{1, 3}
+	reg.Fit(ds.X, ds.Y)
+	expected := &mat.Dense{}
+	expected.Scale(.25, reg.FittedEstimators[0].Predict(ds.X, nil))
+	lr := &mat.Dense{}
+	lr.Scale(.75, reg.FittedEstimators[1].Predict(ds.X, nil))
+	expected.Add(expected, lr)
+	if !mat.EqualApprox(expected, reg.Predict(ds.X, nil), 1e-9) {
+		t.Error("expected the weighted mean of the predictions of the estimators")
+	}
+	if err := base.CheckFitted(dt); !errors.Is(err, base.ErrNotFitted) {
+		t.Errorf("VotingRegressor must fit clones of its estimators, got %v", err)
+	}
+}
@@ -0,0 +1,491 @@
+package ensemble
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"golang.org/x/exp/rand"
+
+	"github.com/pa-m/sklearn/base"
+	"github.com/pa-m/sklearn/metrics"
+	"github.com/pa-m/sklearn/tree"
+	"gonum.org/v1/gonum/mat"
+)
+
+// validateAdaBoost checks the parameters shared by AdaBoostClassifier and AdaBoostRegressor
+func validateAdaBoost(nEstimators int, learningRate float64) {
+	if nEstimators <= 0 {
+		panic(fmt.Errorf("%w: NEstimators must be > 0, got %d", base.ErrInvalidParam, nEstimators))
+	}
+	if learningRate <= 0 {
+		panic(fmt.Errorf("%w: LearningRate must be > 0, got %g", base.ErrInvalidParam, learningRate))
+	}
+}
+
+// normalizedWeights returns a copy of sampleWeight summing to 1, uniform if sampleWeight is nil
+func normalizedWeights(sampleWeight []float64, nSamples int) []float64 {
+	w := make([]float64, nSamples)
+	for i := range w {
+		w[i] = sampleWeightAt(sampleWeight, i)
+	}
+	normalize(w)
+	return w
+}
+
+// normalize scales w to sum to 1 and returns its previous sum
+func normalize(w []float64) float64 {
+	sum := 0.
+	for _, v := range w {
+		sum += v
+	}
+	if sum > 0 {
+		for i := range w {
+			w[i] /= sum
+		}
+	}
+	return sum
+}
+
+// AdaBoostClassifier fits a sequence of clones of Estimator, each on the samples weighted to emphasize the errors of the previous ones.
+// Algorithm is "SAMME.R", which boosts the class probabilities of a base.ProbaPredicter, or "SAMME", which boosts the predicted classes.
+// estimators which are not a base.WeightedFiter are fitted on samples drawn with probabilities proportional to the weights.
+// LearningRate shrinks the contribution of each estimator. Estimator defaults to a DecisionTreeClassifier of depth 1.
+// RandomState draws resampled samples and sets the RandomState parameter of each estimator if it has one
+type AdaBoostClassifier struct {
+	Estimator    base.Predicter
+	NEstimators  int
+	LearningRate float64
+	Algorithm    string
+	RandomState  base.RandomState
+	// runtime filled members
+	NFeatures        int              `param:"-"`
+	Classes          []float64        `param:"-"`
+	Estimators       []base.Predicter `param:"-"`
+	EstimatorWeights []float64        `param:"-"`
+	// EstimatorErrors is the weighted classification error of each estimator
+	EstimatorErrors []float64 `param:"-"`
+}
+
+// NewAdaBoostClassifier returns an AdaBoostClassifier of 50 decision stumps with the SAMME.R algorithm
+func NewAdaBoostClassifier() *AdaBoostClassifier {
+	return &AdaBoostClassifier{Estimator: newStump(), NEstimators: 50, LearningRate: 1, Algorithm: "SAMME.R"}
+}
+
+func newStump() *tree.DecisionTreeClassifier {
+	stump := tree.NewDecisionTreeClassifier()
+	stump.MaxDepth = 1
+	return stump
+}
+
+// PredicterClone returns an unfitted copy of predicter
+func (m *AdaBoostClassifier) PredicterClone() base.Predicter {
+	clone := *m
+	if m.Estimator != nil {
+		clone.Estimator = m.Estimator.PredicterClone()
+	}
+	clone.resetFitted()
+	return base.DeepCopy(&clone).(*AdaBoostClassifier)
+}
+
+func (m *AdaBoostClassifier) resetFitted() {
+	m.NFeatures, m.Classes, m.Estimators, m.EstimatorWeights, m.EstimatorErrors = 0, nil, nil, nil, nil
+}
+
+// IsFitted returns true when the estimators have been fitted
+func (m *AdaBoostClassifier) IsFitted() bool { return m.Estimators != nil }
+
+// IsClassifier returns true for AdaBoostClassifier
+func (*AdaBoostClassifier) IsClassifier() bool { return true }
+
+// GetNOutputs returns 1
+func (*AdaBoostClassifier) GetNOutputs() int { return 1 }
+
+// Fit boosts estimators from X and the class labels Y
+func (m *AdaBoostClassifier) Fit(X, Y mat.Matrix) base.Fiter {
+	return m.FitWeighted(X, Y, nil)
+}
+
+// FitWeighted is Fit with the initial weights of the samples set to sampleWeight
+func (m *AdaBoostClassifier) FitWeighted(Xmatrix, Ymatrix mat.Matrix, sampleWeight []float64) base.Fiter {
+	X, Y := base.ToDense(Xmatrix), base.ToDense(Ymatrix)
+	if err := base.CheckSampleWeight(X, sampleWeight); err != nil {
+		panic(err)
+	}
+	classes, y := classIndices(Y)
+	validateAdaBoost(m.NEstimators, m.LearningRate)
+	est := m.Estimator
+	if est == nil {
+		est = newStump()
+	}
+	sammeR := false
+	switch m.Algorithm {
+	case "SAMME.R":
+		if _, ok := est.(base.ProbaPredicter); !ok {
+			panic(fmt.Errorf("%w: SAMME.R requires an Estimator with PredictProba, got %T", base.ErrInvalidParam, est))
+		}
+		sammeR = true
+	case "SAMME":
+	default:
+		panic(fmt.Errorf("%w: unknown Algorithm %q", base.ErrInvalidParam, m.Algorithm))
+	}
+	src := m.RandomState
+	if src == nil {
+		src = base.NewLockedSource(uint64(time.Now().UnixNano()))
+	}
+	rnd := rand.New(src)
+	nSamples, nFeatures := X.Dims()
+	K := float64(len(classes))
+	w := normalizedWeights(sampleWeight, nSamples)
+	m.resetFitted()
+	m.NFeatures, m.Classes = nFeatures, classes
+	m.Estimators = make([]base.Predicter, 0, m.NEstimators)
+	for it := 0; it < m.NEstimators; it++ {
+		e := est.PredicterClone()
+		setEstimatorRandomState(e, base.NewSource(rnd.Uint64()))
+		fitWeightedOrResampled(e, X, Y, w, rnd)
+		var P *mat.Dense
+		if sammeR {
+			P = estimatorProba(e, X, [][]float64{classes})
+		} else {
+			P = estimatorProba(&predictOnly{e}, X, [][]float64{classes})
+		}
+		incorrect := make([]bool, nSamples)
+		estErr := 0.
+		for i := range incorrect {
+			p := P.RawRowView(i)
+			best := 0
+			for c := range p {
+				if p[c] > p[best] {
+					best = c
+				}
+			}
+			if incorrect[i] = best != int(y[i]); incorrect[i] {
+				estErr += w[i]
+			}
+		}
+		if estErr <= 0 {
+			m.Estimators = append(m.Estimators, e)
+			m.EstimatorWeights = append(m.EstimatorWeights, 1)
+			m.EstimatorErrors = append(m.EstimatorErrors, 0)
+			break
+		}
+		estWeight := 1.
+		if sammeR {
+			// the weight of each sample is multiplied by exp(-LearningRate (K-1)/K y.log(p)), y being coded as 1 for the class of the sample
+			// and -1/(K-1) for the others
+			for i := range w {
+				p := P.RawRowView(i)
+				sum := 0.
+				for c, pc := range p {
+					coding := -1 / (K - 1)
+					if c == int(y[i]) {
+						coding = 1
+					}
+					sum += coding * math.Log(math.Max(pc, 1e-15))
+				}
+				if ew := -m.LearningRate * (K - 1) / K * sum; w[i] > 0 || ew < 0 {
+					w[i] *= math.Exp(ew)
+				}
+			}
+		} else {
+			if estErr >= 1-1/K {
+				if it == 0 {
+					panic(fmt.Errorf("%w: the first estimator is worse than random, the ensemble can not be fitted", base.ErrInvalidParam))
+				}
+				break
+			}
+			estWeight = m.LearningRate * (math.Log((1-estErr)/estErr) + math.Log(K-1))
+			for i := range w {
+				if incorrect[i] && w[i] > 0 {
+					w[i] *= math.Exp(estWeight)
+				}
+			}
+		}
+		m.Estimators = append(m.Estimators, e)
+		m.EstimatorWeights = append(m.EstimatorWeights, estWeight)
+		m.EstimatorErrors = append(m.EstimatorErrors, estErr)
+		if normalize(w) <= 0 {
+			break
+		}
+	}
+	return m
+}
+
+// predictOnly hides the PredictProba of a Predicter
+type predictOnly struct{ base.Predicter }
+
+// decision returns the weighted sum of the contributions of the estimators to each class, divided by the sum of their weights.
+// the contribution of an estimator is (K-1)(log(p)-mean(log(p))) for SAMME.R, and its weight for the predicted class for SAMME
+func (m *AdaBoostClassifier) decision(Xmatrix mat.Matrix) *mat.Dense {
+	X := base.ToDense(Xmatrix)
+	nSamples, _ := X.Dims()
+	K := len(m.Classes)
+	D := mat.NewDense(nSamples, K, nil)
+	sumWeights := 0.
+	for t, e := range m.Estimators {
+		sumWeights += m.EstimatorWeights[t]
+		if m.Algorithm == "SAMME.R" {
+			P := estimatorProba(e, X, [][]float64{m.Classes})
+			for i := 0; i < nSamples; i++ {
+				p, d := P.RawRowView(i), D.RawRowView(i)
+				mean := 0.
+				for c := range p {
+					p[c] = math.Log(math.Max(p[c], 1e-15))
+					mean += p[c] / float64(K)
+				}
+				for c := range p {
+					d[c] += float64(K-1) * (p[c] - mean)
+				}
+			}
+			continue
+		}
+		P := estimatorProba(&predictOnly{e}, X, [][]float64{m.Classes})
+		P.Scale(m.EstimatorWeights[t], P)
+		D.Add(D, P)
+	}
+	D.Scale(1/sumWeights, D)
+	return D
+}
+
+// DecisionFunction returns the decision of the ensemble for each class, a single column for binary classification, higher meaning
+// the second class. see base.DecisionFunctioner
+func (m *AdaBoostClassifier) DecisionFunction(X mat.Matrix, Ymutable mat.Mutable) *mat.Dense {
+	base.MustBeFitted(m)
+	D := m.decision(X)
+	if nSamples, K := D.Dims(); K == 2 {
+		d := mat.NewDense(nSamples, 1, nil)
+		for i := 0; i < nSamples; i++ {
+			d.Set(i, 0, D.At(i, 1)-D.At(i, 0))
+		}
+		D = d
+	}
+	return base.FromDense(Ymutable, D)
+}
+
+// PredictProba returns the softmax of the decision of each class divided by K-1. see base.ProbaPredicter
+func (m *AdaBoostClassifier) PredictProba(X mat.Matrix, Ymutable mat.Mutable) *mat.Dense {
+	base.MustBeFitted(m)
+	P := m.decision(X)
+	P.Scale(1/float64(len(m.Classes)-1), P)
+	base.SoftmaxRows(P)
+	return base.FromDense(Ymutable, P)
+}
+
+// Predict returns the class of highest decision of each sample
+func (m *AdaBoostClassifier) Predict(X mat.Matrix, Ymutable mat.Mutable) *mat.Dense {
+	base.MustBeFitted(m)
+	Y := base.ToDense(Ymutable)
+	nSamples, _ := X.Dims()
+	if Y.IsZero() {
+		*Y = *mat.NewDense(nSamples, 1, nil)
+	}
+	Y.Copy(probaToClasses(m.decision(X), [][]float64{m.Classes}))
+	return base.FromDense(Ymutable, Y)
+}
+
+// FitE is Fit returning an error instead of panicking
+func (m *AdaBoostClassifier) FitE(X, Y mat.Matrix) error {
+	return base.FitE(m, X, Y)
+}
+
+// PredictE is Predict returning an error instead of panicking
+func (m *AdaBoostClassifier) PredictE(X mat.Matrix, Y mat.Mutable) (*mat.Dense, error) {
+	if !m.IsFitted() {
+		return nil, base.ErrNotFitted
+	}
+	if err := base.CheckNFeatures(X, m.NFeatures); err != nil {
+		return nil, err
+	}
+	return base.PredictE(m, X, Y)
+}
+
+// Score for AdaBoostClassifier returns the accuracy of Predict
+func (m *AdaBoostClassifier) Score(X, Y mat.Matrix) float64 {
+	NSamples, NOutputs := Y.Dims()
+	Ypred := mat.NewDense(NSamples, NOutputs, nil)
+	m.Predict(X, Ypred)
+	return metrics.AccuracyScore(Y, Ypred, true, nil)
+}
+
+// AdaBoostRegressor fits a sequence of clones of Estimator with the AdaBoost.R2 algorithm, each on the samples weighted to emphasize the
+// errors of the previous ones. errors are relative to the largest one, and Loss is "linear", "square" or "exponential".
+// predictions are the weighted median of the predictions of the estimators. other parameters are those of AdaBoostClassifier.
+// Estimator defaults to a DecisionTreeRegressor of depth 3
+type AdaBoostRegressor struct {
+	Estimator    base.Predicter
+	NEstimators  int
+	LearningRate float64
+	Loss         string
+	RandomState  base.RandomState
+	// runtime filled members
+	NFeatures        int              `param:"-"`
+	Estimators       []base.Predicter `param:"-"`
+	EstimatorWeights []float64        `param:"-"`
+	// EstimatorErrors is the weighted loss of each estimator
+	EstimatorErrors []float64 `param:"-"`
+}
+
+// NewAdaBoostRegressor returns an AdaBoostRegressor of 50 decision trees of depth 3 with the linear loss
+func NewAdaBoostRegressor() *AdaBoostRegressor {
+	return &AdaBoostRegressor{Estimator: newDepth3Regressor(), NEstimators: 50, LearningRate: 1, Loss: "linear"}
+}
+
+func newDepth3Regressor() *tree.DecisionTreeRegressor {
+	reg := tree.NewDecisionTreeRegressor()
+	reg.MaxDepth = 3
+	return reg
+}
+
+// PredicterClone returns an unfitted copy of predicter
+func (m *AdaBoostRegressor) PredicterClone() base.Predicter {
+	clone := *m
+	if m.Estimator != nil {
+		clone.Estimator = m.Estimator.PredicterClone()
+	}
+	clone.resetFitted()
+	return base.DeepCopy(&clone).(*AdaBoostRegressor)
+}
+
+func (m *AdaBoostRegressor) resetFitted() {
+	m.NFeatures, m.Estimators, m.EstimatorWeights, m.EstimatorErrors = 0, nil, nil, nil
+}
+
+// IsFitted returns true when the estimators have been fitted
+func (m *AdaBoostRegressor) IsFitted() bool { return m.Estimators != nil }
+
+// IsClassifier returns false for AdaBoostRegressor
+func (*AdaBoostRegressor) IsClassifier() bool { return false }
+
+// GetNOutputs returns 1
+func (*AdaBoostRegressor) GetNOutputs() int { return 1 }
+
+// Fit boosts estimators from X and the targets Y
+func (m *AdaBoostRegressor) Fit(X, Y mat.Matrix) base.Fiter {
+	return m.FitWeighted(X, Y, nil)
+}
+
+// FitWeighted is Fit with the initial weights of the samples set to sampleWeight
+func (m *AdaBoostRegressor) FitWeighted(Xmatrix, Ymatrix mat.Matrix, sampleWeight []float64) base.Fiter {
+	X, Y := base.ToDense(Xmatrix), base.ToDense(Ymatrix)
+	if err := base.CheckSampleWeight(X, sampleWeight); err != nil {
+		panic(err)
+	}
+	y := singleTarget(Y)
+	validateAdaBoost(m.NEstimators, m.LearningRate)
+	var loss func(e float64) float64
+	switch m.Loss {
+	case "linear":
+		loss = func(e float64) float64 { return e }
+	case "square":
+		loss = func(e float64) float64 { return e * e }
+	case "exponential":
+		loss = func(e float64) float64 { return 1 - math.Exp(-e) }
+	default:
+		panic(fmt.Errorf("%w: unknown Loss %q", base.ErrInvalidParam, m.Loss))
+	}
+	est := m.Estimator
+	if est == nil {
+		est = newDepth3Regressor()
+	}
+	src := m.RandomState
+	if src == nil {
+		src = base.NewLockedSource(uint64(time.Now().UnixNano()))
+	}
+	rnd := rand.New(src)
+	nSamples, nFeatures := X.Dims()
+	w := normalizedWeights(sampleWeight, nSamples)
+	m.resetFitted()
+	m.NFeatures = nFeatures
+	m.Estimators = make([]base.Predicter, 0, m.NEstimators)
+	errs := make([]float64, nSamples)
+	for it := 0; it < m.NEstimators; it++ {
+		e := est.PredicterClone()
+		setEstimatorRandomState(e, base.NewSource(rnd.Uint64()))
+		fitWeightedOrResampled(e, X, Y, w, rnd)
+		pred := e.Predict(X, nil)
+		maxErr := 0.
+		for i := range errs {
+			errs[i] = math.Abs(pred.At(i, 0) - y[i])
+			if w[i] > 0 {
+				maxErr = math.Max(maxErr, errs[i])
+			}
+		}
+		estErr := 0.
+		for i := range errs {
+			if maxErr > 0 {
+				errs[i] = loss(errs[i] / maxErr)
+			}
+			estErr += w[i] * errs[i]
+		}
+		if estErr <= 0 {
+			m.Estimators = append(m.Estimators, e)
+			m.EstimatorWeights = append(m.EstimatorWeights, 1)
+			m.EstimatorErrors = append(m.EstimatorErrors, 0)
+			break
+		}
+		if estErr >= .5 {
+			if it == 0 {
+				m.Estimators = append(m.Estimators, e)
+				m.EstimatorWeights = append(m.EstimatorWeights, 1)
+				m.EstimatorErrors = append(m.EstimatorErrors, estErr)
+			}
+			break
+		}
+		beta := estErr / (1 - estErr)
+		m.Estimators = append(m.Estimators, e)
+		m.EstimatorWeights = append(m.EstimatorWeights, m.LearningRate*math.Log(1/beta))
+		m.EstimatorErrors = append(m.EstimatorErrors, estErr)
+		for i := range w {
+			w[i] *= math.Pow(beta, (1-errs[i])*m.LearningRate)
+		}
+		if normalize(w) <= 0 {
+			break
+		}
+	}
+	return m
+}
+
+// Predict returns the median of the predictions of the estimators weighted by EstimatorWeights
+func (m *AdaBoostRegressor) Predict(Xmatrix mat.Matrix, Ymutable mat.Mutable) *mat.Dense {
+	base.MustBeFitted(m)
+	X := base.ToDense(Xmatrix)
+	nSamples, _ := X.Dims()
+	preds := make([]*mat.Dense, len(m.Estimators))
+	for t, e := range m.Estimators {
+		preds[t] = e.Predict(X, nil)
+	}
+	Y := mat.NewDense(nSamples, 1, nil)
+	p := make([]float64, len(preds))
+	for i := 0; i < nSamples; i++ {
+		for t, P := range preds {
+			p[t] = P.At(i, 0)
+		}
+		Y.Set(i, 0, weightedPercentile(p, m.EstimatorWeights, .5))
+	}
+	return base.FromDense(Ymutable, Y)
+}
+
+// FitE is Fit returning an error instead of panicking
+func (m *AdaBoostRegressor) FitE(X, Y mat.Matrix) error {
+	return base.FitE(m, X, Y)
+}
+
+// PredictE is Predict returning an error instead of panicking
+func (m *AdaBoostRegressor) PredictE(X mat.Matrix, Y mat.Mutable) (*mat.Dense, error) {
+	if !m.IsFitted() {
+		return nil, base.ErrNotFitted
+	}
+	if err := base.CheckNFeatures(X, m.NFeatures); err != nil {
+		return nil, err
+	}
+	return base.PredictE(m, X, Y)
+}
+
+// Score for AdaBoostRegressor returns the R2 score of Predict
+func (m *AdaBoostRegressor) Score(X, Y mat.Matrix) float64 {
+	NSamples, NOutputs := Y.Dims()
+	Ypred := mat.NewDense(NSamples, NOutputs, nil)
+	m.Predict(X, Ypred)
+	return metrics.R2Score(Y, Ypred, nil, "").At(0, 0)
+}
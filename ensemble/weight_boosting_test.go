@@ -0,0 +1,134 @@
+package ensemble
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/pa-m/sklearn/base"
+	"github.com/pa-m/sklearn/datasets"
+	"gonum.org/v1/gonum/mat"
+)
+
+var _ = []base.PredicterE{&AdaBoostClassifier{}, &AdaBoostRegressor{}, &BaggingClassifier{}, &BaggingRegressor{}}
+var _ = []base.ProbaPredicter{&AdaBoostClassifier{}, &BaggingClassifier{}}
+var _ = []base.WeightedFiter{&AdaBoostClassifier{}, &AdaBoostRegressor{}, &BaggingClassifier{}, &BaggingRegressor{}}
+var _ = []base.FittedChecker{&AdaBoostClassifier{}, &AdaBoostRegressor{}, &BaggingClassifier{}, &BaggingRegressor{}}
+
+// unweighted hides the FitWeighted and PredictProba methods of a Predicter
+type unweighted struct{ base.Predicter }
+
+func (m *unweighted) PredicterClone() base.Predicter {
+	return &unweighted{m.Predicter.PredicterClone()}
+}
+
+func ExampleAdaBoostClassifier() {
+	ds := datasets.LoadIris()
+	for _, algorithm := range []string{"SAMME.R", "SAMME"} {
+		clf := NewAdaBoostClassifier()
+		clf.Algorithm = algorithm
+		clf.RandomState = base.NewSource(7)
+		clf.Fit(ds.X, ds.Y)
+		fmt.Printf("%s accuracy: %.2f\n", algorithm, clf.Score(ds.X, ds.Y))
+	}
+	// Output:
+	// SAMME.R accuracy: 0.96
+	// SAMME accuracy: 0.98
+}
+
+func ExampleAdaBoostRegressor() {
+	ds := datasets.LoadDiabetes()
+	reg := NewAdaBoostRegressor()
+	reg.RandomState = base.NewSource(7)
+	reg.Fit(ds.X, ds.Y)
+	fmt.Printf("r2: %.2f\n", reg.Score(ds.X, ds.Y))
+	// Output:
+	// r2: 0.57
+}
+
+func TestAdaBoostClassifier(t *testing.T) {
+	ds := datasets.LoadBreastCancer()
+	clf := NewAdaBoostClassifier()
+	clf.NEstimators = 20
+	clf.Fit(ds.X, ds.Y)
+	if score := clf.Score(ds.X, ds.Y); score < .97 {
+		t.Errorf("unexpected training score %g", score)
+	}
+	D := clf.DecisionFunction(ds.X, nil)
+	if _, c := D.Dims(); c != 1 {
+		t.Errorf("binary decision function must have a single column, got %d", c)
+	}
+	P := clf.PredictProba(ds.X, nil)
+	for i := 0; i < 569; i++ {
+		if sum := mat.Sum(P.RowView(i)); math.Abs(sum-1) > 1e-12 {
+			t.Fatalf("probas of sample %d sum to %g", i, sum)
+		}
+		if (D.At(i, 0) > 0) != (P.At(i, 1) > .5) {
+			t.Fatalf("decision and proba of sample %d disagree", i)
+		}
+	}
+
+	// estimators without sample weights are fitted on weighted samples
+	clf.Algorithm = "SAMME"
+	clf.Estimator = &unweighted{newStump()}
+	clf.RandomState = base.NewSource(7)
+	clf.Fit(ds.X, ds.Y)
+	if score := clf.Score(ds.X, ds.Y); score < .95 {
+		t.Errorf("unexpected training score %g", score)
+	}
+
+	for _, params := range []map[string]interface{}{
+		{"NEstimators": 0},
+		{"LearningRate": 0.},
+		{"Algorithm": "SAMME.X"},
+		{"Algorithm": "SAMME.R", "Estimator": &unweighted{newStump()}},
+	} {
+		clf := NewAdaBoostClassifier()
+		if err := clf.SetParams(params); err != nil {
+			t.Fatal(err)
+		}
+		if err := clf.FitE(ds.X, ds.Y); !errors.Is(err, base.ErrInvalidParam) {
+			t.Errorf("%v: expected ErrInvalidParam, got %v", params, err)
+		}
+	}
+}
+
+func TestAdaBoostRegressor(t *testing.T) {
+	ds := datasets.LoadDiabetes()
+	X := ds.X.Slice(0, 100, 0, 10).(*mat.Dense)
+	Y := ds.Y.Slice(0, 100, 0, 1).(*mat.Dense)
+	Xdup, Ydup := mat.NewDense(150, 10, nil), mat.NewDense(150, 1, nil)
+	Xdup.Stack(X, X.Slice(0, 50, 0, 10))
+	Ydup.Stack(Y, Y.Slice(0, 50, 0, 1))
+	w := make([]float64, 100)
+	for i := range w {
+		w[i] = 1
+		if i < 50 {
+			w[i] = 2
+		}
+	}
+	for _, loss := range []string{"linear", "square", "exponential"} {
+		reg, dup := NewAdaBoostRegressor(), NewAdaBoostRegressor()
+		reg.Loss, dup.Loss = loss, loss
+		reg.NEstimators, dup.NEstimators = 10, 10
+		reg.FitWeighted(X, Y, w)
+		dup.Fit(Xdup, Ydup)
+		if !mat.EqualApprox(reg.Predict(X, nil), dup.Predict(X, nil), 1e-8) {
+			t.Errorf("%s: weights must act as repeated samples", loss)
+		}
+		if score := reg.Score(X, Y); score < .5 {
+			t.Errorf("%s: unexpected training score %g", loss, score)
+		}
+	}
+	reg := NewAdaBoostRegressor()
+	reg.Loss = "hinge"
+	if err := reg.FitE(X, Y); !errors.Is(err, base.ErrInvalidParam) {
+		t.Errorf("expected ErrInvalidParam, got %v", err)
+	}
+	reg = &AdaBoostRegressor{NEstimators: 3, LearningRate: 1, Loss: "linear", Estimator: &unweighted{newDepth3Regressor()}, RandomState: base.NewSource(7)}
+	reg.Fit(X, Y)
+	if len(reg.Estimators) != 3 {
+		t.Errorf("expected 3 estimators, got %d", len(reg.Estimators))
+	}
+}
@@ -21,11 +21,11 @@ type Regressor struct {
 	NormalizeY         bool
 	// copy_X_train is always true
 	base.RandomState
-	Xtrain                     *mat.Dense
-	Ytrain                     *mat.Dense
-	KernelOpt                  kernels.Kernel
-	L                          *mat.Cholesky
-	LogMarginalLikelihoodValue float64
+	Xtrain                     *mat.Dense     `param:"-"`
+	Ytrain                     *mat.Dense     `param:"-"`
+	KernelOpt                  kernels.Kernel `param:"-"`
+	L                          *mat.Cholesky  `param:"-"`
+	LogMarginalLikelihoodValue float64        `param:"-"`
 }
 
 // NewRegressor ...
@@ -41,27 +41,17 @@ func NewRegressor(kernel kernels.Kernel) *Regressor {
 // IsClassifier returns false
 func (m *Regressor) IsClassifier() bool { return false }
 
-// PredicterClone clones Predicter (for KFold...)
+// PredicterClone returns an unfitted clone of the Predicter (for KFold...)
 func (m *Regressor) PredicterClone() base.Predicter {
 	clone := *m
-	if cloner, ok := m.RandomState.(base.SourceCloner); ok {
-		clone.RandomState = cloner.Clone()
-	}
-	if m.Xtrain != nil {
-		clone.Xtrain = mat.DenseCopyOf(m.Xtrain)
-
-		clone.Ytrain = mat.DenseCopyOf(m.Ytrain)
-	}
-	clone.Kernel = m.Kernel.CloneWithTheta(m.Kernel.Theta())
-	if m.KernelOpt != kernels.Kernel(nil) {
-		clone.KernelOpt = m.KernelOpt.CloneWithTheta(m.KernelOpt.Theta())
-	}
-	if m.L != nil {
-		clone.L.Clone(m.L)
-	}
-	return &clone
+	clone.Xtrain, clone.Ytrain, clone.L, clone.LogMarginalLikelihoodValue = nil, nil, nil, 0
+	clone.KernelOpt = clone.Kernel
+	return base.DeepCopy(&clone).(*Regressor)
 }
 
+// IsFitted returns true when training data have been set by Fit. see base.FittedChecker
+func (m *Regressor) IsFitted() bool { return m.Xtrain != nil }
+
 // GetNOutputs returns Y columns count
 func (m *Regressor) GetNOutputs() int {
 	return m.Ytrain.RawMatrix().Cols
@@ -73,13 +63,22 @@ func (m *Regressor) Fit(X, Y mat.Matrix) base.Fiter {
 	m.Xtrain = mat.DenseCopyOf(X)
 	m.Ytrain = mat.DenseCopyOf(Y)
 	if len(m.Alpha) != 1 && len(m.Alpha) != ry {
-		panic(fmt.Errorf("alpha must be a scalar or an array with same number of entries as y.(%d != %d)", len(m.Alpha), ry))
+		panic(fmt.Errorf("%w: alpha must be a scalar or an array with same number of entries as y.(%d != %d)", base.ErrInvalidParam, len(m.Alpha), ry))
 	}
 	return m
 }
 
+// FitE is Fit returning an error instead of panicking
+func (m *Regressor) FitE(X, Y mat.Matrix) error {
+	if m.Kernel == kernels.Kernel(nil) {
+		return fmt.Errorf("%w: Kernel is nil", base.ErrInvalidParam)
+	}
+	return base.FitE(m, X, Y)
+}
+
 // Predict using the Gaussian process regression model
 func (m *Regressor) Predict(X mat.Matrix, Y mat.Mutable) *mat.Dense {
+	base.MustBeFitted(m)
 	NSamples, _ := X.Dims()
 	var Yd *mat.Dense
 	if _, ok := Y.(*mat.Dense); ok {
@@ -90,6 +89,17 @@ func (m *Regressor) Predict(X mat.Matrix, Y mat.Mutable) *mat.Dense {
 	return base.FromDense(Y, Yd)
 }
 
+// PredictE is Predict returning an error instead of panicking
+func (m *Regressor) PredictE(X mat.Matrix, Y mat.Mutable) (*mat.Dense, error) {
+	if !m.IsFitted() {
+		return nil, base.ErrNotFitted
+	}
+	if err := base.CheckNFeatures(X, m.Xtrain.RawMatrix().Cols); err != nil {
+		return nil, err
+	}
+	return base.PredictE(m, X, Y)
+}
+
 // Score returns R2 score
 func (m *Regressor) Score(X, Y mat.Matrix) float64 {
 	m.Fit(X, Y)
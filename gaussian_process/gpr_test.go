@@ -1,6 +1,8 @@
 package gaussianprocess
 
 import (
+	"errors"
+
 	"github.com/pa-m/sklearn/base"
 	"github.com/pa-m/sklearn/gaussian_process/kernels"
 	"gonum.org/v1/gonum/floats"
@@ -9,7 +11,7 @@ import (
 	"testing"
 )
 
-var _ base.Predicter = &Regressor{}
+var _ base.PredicterE = &Regressor{}
 
 func TestRegressor_LogMarginalLikelihood(t *testing.T) {
 	//from plot_gpr_noisy.ipynb
@@ -81,3 +83,15 @@ func TestRegressor_LogMarginalLikelihood(t *testing.T) {
 		t.Errorf("expected grad %g, got %g", expectedGrad, grad)
 	}
 }
+
+func TestRegressor_FitE(t *testing.T) {
+	gp := NewRegressor(&kernels.RBF{LengthScale: []float64{1}, LengthScaleBounds: [][2]float64{{1e-2, 1e2}}})
+	gp.Alpha = []float64{1e-10, 1e-10, 1e-10}
+	X, Y := mat.NewDense(4, 1, []float64{1, 2, 3, 4}), mat.NewDense(4, 1, []float64{1, 4, 9, 16})
+	if err := gp.FitE(X, Y); !errors.Is(err, base.ErrInvalidParam) {
+		t.Errorf("expected ErrInvalidParam, got %v", err)
+	}
+	if err := gp.FitE(X, mat.NewDense(3, 1, nil)); !errors.Is(err, base.ErrShapeMismatch) {
+		t.Errorf("expected ErrShapeMismatch, got %v", err)
+	}
+}
@@ -0,0 +1,57 @@
+package kernels
+
+import (
+	"github.com/pa-m/sklearn/base"
+)
+
+func init() {
+	base.Register(&Sum{})
+	base.Register(&Product{})
+	base.Register(&Exponentiation{})
+	base.Register(&ConstantKernel{})
+	base.Register(&WhiteKernel{})
+	base.Register(&RBF{})
+	base.Register(&DotProduct{})
+}
+
+// MarshalState allows Sum to be saved by base.Save
+func (k *Sum) MarshalState() (*base.State, error) { return base.MarshalFields(k) }
+
+// UnmarshalState restores a Sum saved by base.Save
+func (k *Sum) UnmarshalState(st *base.State) error { return base.UnmarshalFields(k, st) }
+
+// MarshalState allows Product to be saved by base.Save
+func (k *Product) MarshalState() (*base.State, error) { return base.MarshalFields(k) }
+
+// UnmarshalState restores a Product saved by base.Save
+func (k *Product) UnmarshalState(st *base.State) error { return base.UnmarshalFields(k, st) }
+
+// MarshalState allows Exponentiation to be saved by base.Save
+func (k *Exponentiation) MarshalState() (*base.State, error) { return base.MarshalFields(k) }
+
+// UnmarshalState restores an Exponentiation saved by base.Save
+func (k *Exponentiation) UnmarshalState(st *base.State) error { return base.UnmarshalFields(k, st) }
+
+// MarshalState allows ConstantKernel to be saved by base.Save
+func (k *ConstantKernel) MarshalState() (*base.State, error) { return base.MarshalFields(k) }
+
+// UnmarshalState restores a ConstantKernel saved by base.Save
+func (k *ConstantKernel) UnmarshalState(st *base.State) error { return base.UnmarshalFields(k, st) }
+
+// MarshalState allows WhiteKernel to be saved by base.Save
+func (k *WhiteKernel) MarshalState() (*base.State, error) { return base.MarshalFields(k) }
+
+// UnmarshalState restores a WhiteKernel saved by base.Save
+func (k *WhiteKernel) UnmarshalState(st *base.State) error { return base.UnmarshalFields(k, st) }
+
+// MarshalState allows RBF to be saved by base.Save
+func (k *RBF) MarshalState() (*base.State, error) { return base.MarshalFields(k) }
+
+// UnmarshalState restores a RBF saved by base.Save
+func (k *RBF) UnmarshalState(st *base.State) error { return base.UnmarshalFields(k, st) }
+
+// MarshalState allows DotProduct to be saved by base.Save
+func (k *DotProduct) MarshalState() (*base.State, error) { return base.MarshalFields(k) }
+
+// UnmarshalState restores a DotProduct saved by base.Save
+func (k *DotProduct) UnmarshalState(st *base.State) error { return base.UnmarshalFields(k, st) }
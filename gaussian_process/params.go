@@ -0,0 +1,11 @@
+package gaussianprocess
+
+import "github.com/pa-m/sklearn/base"
+
+// GetParams returns the parameters of Regressor. see base.GetFieldParams
+func (m *Regressor) GetParams() map[string]interface{} { return base.GetFieldParams(m) }
+
+// SetParams sets the parameters of Regressor. see base.SetFieldParams
+func (m *Regressor) SetParams(params map[string]interface{}) error {
+	return base.SetFieldParams(m, params)
+}
@@ -0,0 +1,15 @@
+package gaussianprocess
+
+import (
+	"github.com/pa-m/sklearn/base"
+)
+
+func init() {
+	base.Register(&Regressor{})
+}
+
+// MarshalState allows Regressor to be saved by base.Save. kernels are saved as children, RandomState is not saved
+func (m *Regressor) MarshalState() (*base.State, error) { return base.MarshalFields(m) }
+
+// UnmarshalState restores a Regressor saved by base.Save
+func (m *Regressor) UnmarshalState(st *base.State) error { return base.UnmarshalFields(m, st) }
@@ -0,0 +1,44 @@
+package gaussianprocess
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/pa-m/sklearn/base"
+	"github.com/pa-m/sklearn/gaussian_process/kernels"
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestRegressor_SaveLoad(t *testing.T) {
+	kernel := &kernels.Sum{KernelOperator: kernels.KernelOperator{
+		K1: &kernels.Product{KernelOperator: kernels.KernelOperator{
+			K1: &kernels.ConstantKernel{ConstantValue: 1, ConstantValueBounds: [2]float64{1e-3, 1e3}},
+			K2: &kernels.RBF{LengthScale: []float64{10}, LengthScaleBounds: [][2]float64{{1e-2, 1e2}}},
+		}},
+		K2: &kernels.WhiteKernel{NoiseLevel: .1, NoiseLevelBounds: [2]float64{1e-5, 1}},
+	}}
+	gp := NewRegressor(kernel)
+	X := mat.NewDense(5, 1, []float64{1, 2, 3, 4, 5})
+	Y := mat.NewDense(5, 1, []float64{1, 4, 9, 16, 25})
+	gp.Fit(X, Y)
+	buf := new(bytes.Buffer)
+	if err := base.Save(buf, gp); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := base.Load(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gp2 := loaded.(*Regressor)
+	if gp2.Kernel.String() != gp.Kernel.String() || gp2.KernelOpt.String() != gp.KernelOpt.String() {
+		t.Errorf("kernel not restored: %s", gp2.Kernel)
+	}
+	if !mat.Equal(gp2.Xtrain, gp.Xtrain) || !mat.Equal(gp2.Ytrain, gp.Ytrain) || len(gp2.Alpha) != 1 {
+		t.Errorf("training data not restored")
+	}
+	K1, _ := gp.Kernel.Eval(X, nil, false)
+	K2, _ := gp2.Kernel.Eval(X, nil, false)
+	if !mat.EqualApprox(K1, K2, 1e-12) {
+		t.Errorf("restored kernel evaluation differs")
+	}
+}
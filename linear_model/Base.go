@@ -1,6 +1,7 @@
 package linearmodel
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"time"
@@ -60,10 +61,10 @@ type LinearRegression struct {
 type RegularizedRegression struct {
 	LinearRegression
 	Solver              string
-	SolverConfigure     func(base.Optimizer)
+	SolverConfigure     func(base.Optimizer) `persist:"-"`
 	Tol, Alpha, L1Ratio float64
-	LossFunction        Loss
-	ActivationFunction  Activation
+	LossFunction        Loss       `persist:"-"`
+	ActivationFunction  Activation `persist:"-"`
 	Options             LinFitOptions
 }
 
@@ -82,14 +83,21 @@ func (*LinearRegression) IsClassifier() bool { return false }
 // PredicterClone for LinearRegression
 func (regr *LinearRegression) PredicterClone() base.Predicter {
 	clone := *regr
-	return &clone
+	clone.resetFitted()
+	return base.DeepCopy(&clone).(*LinearRegression)
 }
 
 // Fit fits Coef for a LinearRegression
 func (regr *LinearRegression) Fit(Xmatrix, Ymatrix mat.Matrix) base.Fiter {
+	return regr.FitWeighted(Xmatrix, Ymatrix, nil)
+}
+
+// FitWeighted fits Coef minimizing the squared errors weighted by sampleWeight. see base.WeightedFiter
+func (regr *LinearRegression) FitWeighted(Xmatrix, Ymatrix mat.Matrix, sampleWeight []float64) base.Fiter {
 	X0, Y0 := base.ToDense(Xmatrix), base.ToDense(Ymatrix)
 	var X, Y, YOffset *mat.Dense
-	X, Y, regr.XOffset, YOffset, regr.XScale = PreprocessData(X0, Y0, regr.FitIntercept, regr.Normalize, nil)
+	X, Y, regr.XOffset, YOffset, regr.XScale = PreprocessData(X0, Y0, regr.FitIntercept, regr.Normalize, weightVec(sampleWeight))
+	X, Y = rescaleData(X, Y, sampleWeight)
 	// use least squares
 	regr.Coef = &mat.Dense{}
 	regr.Coef.Solve(X, Y)
@@ -97,6 +105,44 @@ func (regr *LinearRegression) Fit(Xmatrix, Ymatrix mat.Matrix) base.Fiter {
 	return regr
 }
 
+// FitE is Fit returning an error instead of panicking
+func (regr *LinearRegression) FitE(X, Y mat.Matrix) error {
+	return base.FitE(regr, X, Y)
+}
+
+// PredictE is Predict returning an error instead of panicking
+func (regr *LinearRegression) PredictE(X mat.Matrix, Y mat.Mutable) (*mat.Dense, error) {
+	if err := regr.checkFitted(X); err != nil {
+		return nil, err
+	}
+	return base.PredictE(regr, X, Y)
+}
+
+// IsFitted returns true when Coef has been fitted. see base.FittedChecker
+func (regr *LinearModel) IsFitted() bool { return regr.Coef != nil && !regr.Coef.IsZero() }
+
+// resetFitted clears the fitted coefficients
+func (regr *LinearModel) resetFitted() {
+	regr.XOffset, regr.XScale, regr.Coef, regr.Intercept = nil, nil, nil, nil
+}
+
+func (regr *LinearModel) checkFitted(X mat.Matrix) error {
+	if !regr.IsFitted() {
+		return base.ErrNotFitted
+	}
+	return base.CheckNFeatures(X, regr.Coef.RawMatrix().Rows)
+}
+
+func checkRegularization(Alpha, L1Ratio float64) error {
+	if Alpha < 0 {
+		return fmt.Errorf("%w: Alpha must be >= 0, got %g", base.ErrInvalidParam, Alpha)
+	}
+	if L1Ratio < 0 || L1Ratio > 1 {
+		return fmt.Errorf("%w: L1Ratio must be in [0,1], got %g", base.ErrInvalidParam, L1Ratio)
+	}
+	return nil
+}
+
 // GetNOutputs returns output columns number for Y to pass to predict
 func (regr *LinearModel) GetNOutputs() int {
 	_, nOutputs := regr.Coef.Dims()
@@ -105,9 +151,52 @@ func (regr *LinearModel) GetNOutputs() int {
 
 // Fit fits Coef for a LinearRegression
 func (regr *RegularizedRegression) Fit(Xmatrix, Ymatrix mat.Matrix) base.Fiter {
+	return regr.FitWeighted(Xmatrix, Ymatrix, nil)
+}
+
+// FitWeighted fits Coef minimizing the regularized squared errors weighted by sampleWeight.
+// a sparse or float32 X is densified when sampleWeight is not nil. see base.WeightedFiter
+func (regr *RegularizedRegression) FitWeighted(Xmatrix, Ymatrix mat.Matrix, sampleWeight []float64) base.Fiter {
+	if err := regr.fit(nil, Xmatrix, Ymatrix, sampleWeight); err != nil {
+		panic(err)
+	}
+	return regr
+}
+
+// FitContext is FitE stopping when ctx is done. progress is reported to the base.ProgressFunc of ctx
+func (regr *RegularizedRegression) FitContext(ctx context.Context, X, Y mat.Matrix) (err error) {
+	if err = checkRegularization(regr.Alpha, regr.L1Ratio); err != nil {
+		return
+	}
+	if err = base.CheckXY(X, Y); err != nil {
+		return
+	}
+	defer base.Recover(&err)
+	return regr.fit(ctx, X, Y, nil)
+}
+
+func (regr *RegularizedRegression) fit(ctx context.Context, Xmatrix, Ymatrix mat.Matrix, sampleWeight []float64) error {
+	if base.IsSparse(Xmatrix) && sampleWeight == nil {
+		return regr.fitSparse(ctx, base.ToCSR(Xmatrix), base.ToDense(Ymatrix))
+	}
+	if X32, ok := Xmatrix.(base.General32); ok && sampleWeight == nil {
+		return regr.fit32(ctx, X32, base.ToDense(Ymatrix))
+	}
 	X0, Y0 := base.ToDense(Xmatrix), base.ToDense(Ymatrix)
 	var X, Y, YOffset *mat.Dense
-	X, Y, regr.XOffset, YOffset, regr.XScale = PreprocessData(X0, Y0, regr.FitIntercept, regr.Normalize, nil)
+	X, Y, regr.XOffset, YOffset, regr.XScale = PreprocessData(X0, Y0, regr.FitIntercept, regr.Normalize, weightVec(sampleWeight))
+	X, Y = rescaleData(X, Y, sampleWeight)
+	opt := regr.linFitOptions(ctx)
+	res := LinFit(X, Y, &opt)
+	if res.Err != nil {
+		return res.Err
+	}
+	regr.Coef = res.Theta
+	regr.LinearModel.setIntercept(regr.XOffset, YOffset, regr.XScale)
+	return nil
+}
+
+func (regr *RegularizedRegression) linFitOptions(ctx context.Context) LinFitOptions {
 	opt := regr.Options
 	opt.Tol = regr.Tol
 	opt.Solver = regr.Solver
@@ -116,14 +205,20 @@ func (regr *RegularizedRegression) Fit(Xmatrix, Ymatrix mat.Matrix) base.Fiter {
 	opt.Activation = regr.ActivationFunction
 	opt.Alpha = regr.Alpha
 	opt.L1Ratio = regr.L1Ratio
-	res := LinFit(X, Y, &opt)
-	regr.Coef = res.Theta
-	regr.LinearModel.setIntercept(regr.XOffset, YOffset, regr.XScale)
-	return regr
+	if ctx != nil {
+		opt.Context = ctx
+	}
+	return opt
+}
+
+// FitE is Fit returning an error instead of panicking
+func (regr *RegularizedRegression) FitE(X, Y mat.Matrix) error {
+	return regr.FitContext(context.Background(), X, Y)
 }
 
 // Predict predicts y for X using Coef
 func (regr *LinearRegression) Predict(X mat.Matrix, Ymutable mat.Mutable) *mat.Dense {
+	base.MustBeFitted(regr)
 	Y := base.ToDense(Ymutable)
 	nSamples, _ := X.Dims()
 	if Y.IsZero() {
@@ -140,7 +235,7 @@ type SGDRegressor struct {
 	LinearModel
 	Tol, Alpha, L1Ratio float
 	NJobs               int
-	Method              optimize.Method
+	Method              optimize.Method `persist:"-"`
 }
 
 // NewSGDRegressor creates a *SGDRegressor with defaults
@@ -157,7 +252,8 @@ func (*SGDRegressor) IsClassifier() bool { return false }
 // PredicterClone for SGDRegressor
 func (regr *SGDRegressor) PredicterClone() base.Predicter {
 	clone := *regr
-	return &clone
+	clone.resetFitted()
+	return base.DeepCopy(&clone).(*SGDRegressor)
 }
 
 // Fit learns Coef
@@ -277,6 +373,7 @@ func (regr *SGDRegressor) Fit(Xmatrix, Ymatrix mat.Matrix) base.Fiter {
 
 // Predict predicts y from X using Coef
 func (regr *SGDRegressor) Predict(X mat.Matrix, Ymutable mat.Mutable) *mat.Dense {
+	base.MustBeFitted(regr)
 	Y := base.ToDense(Ymutable)
 	nSamples, _ := X.Dims()
 	if Y.IsZero() {
@@ -286,6 +383,19 @@ func (regr *SGDRegressor) Predict(X mat.Matrix, Ymutable mat.Mutable) *mat.Dense
 	return base.FromDense(Ymutable, Y)
 }
 
+// FitE is Fit returning an error instead of panicking
+func (regr *SGDRegressor) FitE(X, Y mat.Matrix) error {
+	return base.FitE(regr, X, Y)
+}
+
+// PredictE is Predict returning an error instead of panicking
+func (regr *SGDRegressor) PredictE(X mat.Matrix, Y mat.Mutable) (*mat.Dense, error) {
+	if err := regr.checkFitted(X); err != nil {
+		return nil, err
+	}
+	return base.PredictE(regr, X, Y)
+}
+
 func unused(...interface{}) {}
 
 // LinFitOptions are options for LinFit
@@ -305,6 +415,8 @@ type LinFitOptions struct {
 	Recorder                            optimize.Recorder
 	PerOutputFit                        bool
 	DisableRegularizationOfFirstFeature bool
+	// Context allows to cancel the fit and to receive its progress with base.WithProgress. may be nil
+	Context context.Context
 }
 
 // LinFitResult is the result or LinFit
@@ -313,6 +425,8 @@ type LinFitResult struct {
 	RMSE, J   float64
 	Epoch     int
 	Theta     *mat.Dense
+	// Err is set if the fit was stopped by Context or by a base.ProgressFunc
+	Err error
 }
 
 func initRecorder(recorder optimize.Recorder) (err error) {
@@ -325,8 +439,40 @@ func initRecorder(recorder optimize.Recorder) (err error) {
 	return recorder.Init()
 }
 
-// LinFit is an internal helper to fit linear regressions
-func LinFit(X, Ytrue *mat.Dense, opts *LinFitOptions) *LinFitResult {
+// monitorRecorder reports major iterations of gonum/optimize to a base.Monitor and stops the optimization when required
+type monitorRecorder struct {
+	optimize.Recorder
+	mon *base.Monitor
+}
+
+func (r *monitorRecorder) Init() error {
+	if r.Recorder != nil {
+		return r.Recorder.Init()
+	}
+	return nil
+}
+
+func (r *monitorRecorder) Record(loc *optimize.Location, op optimize.Operation, stats *optimize.Stats) error {
+	if r.Recorder != nil {
+		if err := r.Recorder.Record(loc, op, stats); err != nil {
+			return err
+		}
+	}
+	if op == optimize.MajorIteration {
+		return r.mon.Report(stats.MajorIterations, loc.F)
+	}
+	return r.mon.Err()
+}
+
+func linFitMonitor(opts *LinFitOptions) *base.Monitor {
+	if opts.Context == nil {
+		return nil
+	}
+	return base.NewMonitor(opts.Context)
+}
+
+// LinFit is an internal helper to fit linear regressions. X may be a *base.CSR or a base.General32
+func LinFit(X mat.Matrix, Ytrue *mat.Dense, opts *LinFitOptions) *LinFitResult {
 	nSamples, nFeatures := X.Dims()
 	_, nOutputs := Ytrue.Dims()
 	if opts.GOMethodCreator == nil && opts.Solver == "" {
@@ -389,6 +535,8 @@ func LinFit(X, Ytrue *mat.Dense, opts *LinFitOptions) *LinFitResult {
 		opts.Epochs = 1e6 / nSamples
 	}
 	var epoch int
+	var stopErr error
+	mon := linFitMonitor(opts)
 	var hasRecorder = initRecorder(opts.Recorder) == nil
 	if hasRecorder {
 		opts.Recorder.Record(
@@ -397,8 +545,7 @@ func LinFit(X, Ytrue *mat.Dense, opts *LinFitOptions) *LinFitResult {
 			&optimize.Stats{MajorIterations: epoch, FuncEvaluations: epoch, GradEvaluations: epoch, Runtime: time.Since(start)})
 	}
 	for epoch = 1; epoch <= opts.Epochs && !converged; epoch++ {
-		shuffler := preprocessing.NewShuffler()
-		Xs, Ys := shuffler.FitTransform(X, Ytrue)
+		Xs, Ys := shuffleRows(X, Ytrue)
 		for miniBatch := 0; miniBatch*miniBatchSize < nSamples; miniBatch++ {
 			miniBatchStart = miniBatch * miniBatchSize
 			miniBatchEnd := miniBatchStart + miniBatchSize
@@ -409,7 +556,7 @@ func LinFit(X, Ytrue *mat.Dense, opts *LinFitOptions) *LinFitResult {
 
 			opts.Loss(
 				Ys.Slice(miniBatchStart, miniBatchEnd, 0, nOutputs),
-				Xs.Slice(miniBatchStart, miniBatchEnd, 0, nFeatures),
+				rowSlice(Xs, miniBatchStart, miniBatchEnd),
 				Theta,
 				YpredMini.Slice(0, miniBatchRows, 0, nOutputs).(*mat.Dense),
 				YdiffMini.Slice(0, miniBatchRows, 0, nOutputs).(*mat.Dense),
@@ -439,14 +586,46 @@ func LinFit(X, Ytrue *mat.Dense, opts *LinFitOptions) *LinFitResult {
 				optimize.InitIteration,
 				&optimize.Stats{MajorIterations: epoch, FuncEvaluations: epoch, GradEvaluations: epoch, Runtime: time.Since(start)})
 		}
+		if stopErr = mon.Report(epoch, J); stopErr != nil {
+			break
+		}
 	}
 	J = JBest
 	Theta = mat.NewDense(nFeatures, nOutputs, thetaSliceBest)
-	return &LinFitResult{Converged: converged, RMSE: rmse, J: J, Epoch: epoch, Theta: Theta}
+	return &LinFitResult{Converged: converged, RMSE: rmse, J: J, Epoch: epoch, Theta: Theta, Err: stopErr}
+}
+
+// shuffleRows returns X and Y with their rows shuffled the same way. a *base.CSR or a base.General32 X is not densified
+func shuffleRows(X mat.Matrix, Y *mat.Dense) (mat.Matrix, *mat.Dense) {
+	if !base.IsSparse(X) && !base.IsFloat32(X) {
+		return preprocessing.NewShuffler().FitTransform(X, Y)
+	}
+	nSamples, nOutputs := Y.Dims()
+	perm := rand.Perm(nSamples)
+	Ys := mat.NewDense(nSamples, nOutputs, nil)
+	for i, p := range perm {
+		Ys.SetRow(i, Y.RawRowView(p))
+	}
+	if X32, ok := X.(base.General32); ok {
+		return X32.SelectRows(perm), Ys
+	}
+	return base.ToCSR(X).SelectRows(perm), Ys
+}
+
+// rowSlice returns a view of rows i to k-1 of X, which is a *mat.Dense, a *base.CSR or a base.General32
+func rowSlice(X mat.Matrix, i, k int) mat.Matrix {
+	switch Xt := X.(type) {
+	case *base.CSR:
+		return Xt.RowSlice(i, k)
+	case base.General32:
+		return Xt.RowSlice(i, k)
+	}
+	_, nFeatures := X.Dims()
+	return X.(*mat.Dense).Slice(i, k, 0, nFeatures)
 }
 
-// LinFitGOM fits a regression with a gonum/optimizer Method
-func LinFitGOM(X, Ytrue *mat.Dense, opts *LinFitOptions) *LinFitResult {
+// LinFitGOM fits a regression with a gonum/optimizer Method. X may be a *base.CSR or a base.General32
+func LinFitGOM(X mat.Matrix, Ytrue *mat.Dense, opts *LinFitOptions) *LinFitResult {
 	nSamples, nFeatures := X.Dims()
 	_, nOutputs := Ytrue.Dims()
 
@@ -461,9 +640,13 @@ func LinFitGOM(X, Ytrue *mat.Dense, opts *LinFitOptions) *LinFitResult {
 	if opts.Epochs <= 0 {
 		opts.Epochs = 4e6 / nSamples
 	}
+	mon := linFitMonitor(opts)
 	fSettings := func() *optimize.Settings {
 		settings := &optimize.Settings{}
 		settings.Recorder = opts.Recorder
+		if mon != nil {
+			settings.Recorder = &monitorRecorder{Recorder: opts.Recorder, mon: mon}
+		}
 		settings.GradientThreshold = 1e-12
 		settings.FuncEvaluations = opts.Epochs
 		settings.Concurrent = runtime.NumCPU()
@@ -553,7 +736,7 @@ func LinFitGOM(X, Ytrue *mat.Dense, opts *LinFitOptions) *LinFitResult {
 	}
 	//fmt.Printf("ret:%#v\nstatus:%s\n", ret, ret.Status)
 	converged = err == nil
-	return &LinFitResult{Converged: converged, RMSE: rmse, Epoch: epoch, Theta: thetaM}
+	return &LinFitResult{Converged: converged, RMSE: rmse, Epoch: epoch, Theta: thetaM, Err: mon.Err()}
 }
 
 func (regr *LinearModel) setIntercept(XOffset, YOffset, XScale mat.Matrix) {
@@ -570,14 +753,14 @@ func (regr *LinearModel) setIntercept(XOffset, YOffset, XScale mat.Matrix) {
 }
 
 // DecisionFunction fills Y with X dot Coef+Intercept
-func (regr *LinearModel) DecisionFunction(X mat.Matrix, Ymutable mat.Mutable) {
+func (regr *LinearModel) DecisionFunction(X mat.Matrix, Ymutable mat.Mutable) *mat.Dense {
 	Y := base.ToDense(Ymutable)
-	Y.Mul(X, regr.Coef)
+	base.MatMul(Y, X, regr.Coef)
 	Y.Apply(func(j int, o int, v float64) float64 {
 
 		return v + regr.Intercept.At(0, o)
 	}, Y)
-	base.FromDense(Ymutable, Y)
+	return base.FromDense(Ymutable, Y)
 }
 
 // Score returns R2Score between Y and X dot Coef+Intercept
@@ -588,7 +771,7 @@ func (regr *LinearModel) Score(X, Y mat.Matrix) float64 {
 	return metrics.R2Score(Yd, Ypred, nil, "").At(0, 0)
 }
 
-// PreprocessData center and normalize data
+// PreprocessData center and normalize data. offsets are weighted by SampleWeight if it is not nil
 func PreprocessData(X, Y *mat.Dense, FitIntercept, Normalize bool, SampleWeight *mat.VecDense) (Xout, Yout, XOffset, YOffset, XScale *mat.Dense) {
 	Xmat := X.RawMatrix()
 	Ymat := Y.RawMatrix()
@@ -607,6 +790,12 @@ func PreprocessData(X, Y *mat.Dense, FitIntercept, Normalize bool, SampleWeight
 	}
 	Xout = mat.NewDense(Xmat.Rows, Xmat.Cols, nil)
 	Xoutmat := Xout.RawMatrix()
+	colMean := func(col mat.Vector) float64 {
+		if SampleWeight == nil {
+			return mat.Sum(col) / float64(col.Len())
+		}
+		return mat.Dot(col, SampleWeight) / mat.Sum(SampleWeight)
+	}
 
 	Yout = Y
 	base.Parallelize(-1, Xmat.Cols, func(th, start, end int) {
@@ -614,7 +803,7 @@ func PreprocessData(X, Y *mat.Dense, FitIntercept, Normalize bool, SampleWeight
 			xcol := X.ColView(feature)
 			mean := 0.
 			if FitIntercept {
-				mean = mat.Sum(xcol) / float64(Xmat.Rows)
+				mean = colMean(xcol)
 
 				for jX, jXout := 0, 0; jX < Xmat.Rows*Xmat.Stride; jX, jXout = jX+Xmat.Stride, jXout+Xoutmat.Stride {
 					Xoutmat.Data[jXout+feature] = Xmat.Data[jX+feature] - mean
@@ -642,7 +831,7 @@ func PreprocessData(X, Y *mat.Dense, FitIntercept, Normalize bool, SampleWeight
 		base.Parallelize(-1, Ymat.Cols, func(th, start, end int) {
 			for output := start; output < end; output++ {
 				ycol := Y.ColView(output)
-				mean := mat.Sum(ycol) / float64(Ymat.Rows)
+				mean := colMean(ycol)
 				YOffsetmat.Data[output] = mean
 				Youtmat := Yout.RawMatrix()
 				for jY, jYout := 0, 0; jY < Ymat.Rows*Ymat.Stride; jY, jYout = jY+Ymat.Stride, jYout+Youtmat.Stride {
@@ -1,6 +1,8 @@
 package linearmodel
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"image/color"
 	"math"
@@ -21,6 +23,8 @@ import (
 	"gonum.org/v1/plot/vg"
 )
 
+var _ = []base.PredicterE{&LinearRegression{}, &Ridge{}, &SGDRegressor{}, &ElasticNet{}, &BayesianRidge{}}
+
 type Problem struct {
 	X, Y          *mat.Dense
 	MiniBatchSize int
@@ -380,3 +384,25 @@ func _ExampleSetIntercept() {
 	// [10.00  10.00]
 
 }
+
+func TestRegularizedRegression_FitContext(t *testing.T) {
+	p := NewRandomLinearProblem(100, 2, 1)
+	regr := NewRidge()
+	var losses []float64
+	ctx := base.WithProgress(context.Background(), func(p base.Progress) error {
+		losses = append(losses, p.Loss)
+		if p.Epoch >= 3 {
+			return context.DeadlineExceeded
+		}
+		return nil
+	})
+	if err := regr.FitContext(ctx, p.X, p.Y); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected DeadlineExceeded, got %v", err)
+	}
+	if len(losses) != 3 {
+		t.Errorf("expected 3 progress reports, got %d", len(losses))
+	}
+	if err := regr.FitContext(context.Background(), p.X, p.Y); err != nil {
+		t.Error(err)
+	}
+}
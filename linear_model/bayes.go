@@ -15,9 +15,9 @@ type BayesianRidge struct {
 	NIter                                 int
 	Tol, Alpha1, Alpha2, Lambda1, Lambda2 float
 	ComputeScore, Verbose                 bool
-	Alpha, Lambda                         float
-	Sigma                                 *mat.Dense
-	Scores                                []float
+	Alpha, Lambda                         float      `param:"-"`
+	Sigma                                 *mat.Dense `param:"-"`
+	Scores                                []float    `param:"-"`
 }
 
 // NewBayesianRidge creates a *BayesianRidge with defaults
@@ -35,7 +35,9 @@ func (*BayesianRidge) IsClassifier() bool { return false }
 // PredicterClone for BayesianRidge
 func (regr *BayesianRidge) PredicterClone() base.Predicter {
 	clone := *regr
-	return &clone
+	clone.resetFitted()
+	clone.Alpha, clone.Lambda, clone.Sigma, clone.Scores = 0, 0, nil, nil
+	return base.DeepCopy(&clone).(*BayesianRidge)
 }
 
 // Fit the model
@@ -200,6 +202,19 @@ func (regr *BayesianRidge) Fit(Xmatrix, Ymatrix mat.Matrix) base.Fiter {
 	return regr
 }
 
+// FitE is Fit returning an error instead of panicking
+func (regr *BayesianRidge) FitE(X, Y mat.Matrix) error {
+	return base.FitE(regr, X, Y)
+}
+
+// PredictE is Predict returning an error instead of panicking
+func (regr *BayesianRidge) PredictE(X mat.Matrix, Y mat.Mutable) (*mat.Dense, error) {
+	if err := regr.checkFitted(X); err != nil {
+		return nil, err
+	}
+	return base.PredictE(regr, X, Y)
+}
+
 // GetNOutputs returns output columns number for Y to pass to predict
 func (regr *BayesianRidge) GetNOutputs() int {
 	_, nOutputs := regr.Coef.Dims()
@@ -221,6 +236,7 @@ func (regr *BayesianRidge) GetNOutputs() int {
 func (regr *BayesianRidge) Predict(X mat.Matrix, Ymutable mat.Mutable) *mat.Dense {
 	// d := func(X mat.Matrix) string { r, c := X.Dims(); return fmt.Sprintf("%d,%d", r, c) }
 	// fmt.Println("Predict", d(X), d(regr.Coef))
+	base.MustBeFitted(regr)
 	Y := base.ToDense(Ymutable)
 	nSamples, _ := X.Dims()
 	if Y.IsZero() {
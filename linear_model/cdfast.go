@@ -7,6 +7,7 @@ import (
 	"golang.org/x/exp/rand"
 
 	"gonum.org/v1/gonum/blas/blas64"
+	"gonum.org/v1/gonum/floats"
 	"gonum.org/v1/gonum/mat"
 )
 
@@ -331,3 +332,124 @@ func enetCoordinateDescentMultiTask(w *mat.Dense, l1reg, l2reg float64, X *mat.D
 	}
 	return &CDResult{Gap: gap, Eps: tol, NIter: nIter + 1}
 }
+
+// sparseEnetCoordinateDescent is enetCoordinateDescent for a sparse X.
+// X is implicitly centered by XMean if it is not nil
+// v sparse_enet_coordinate_descent in https://github.com/scikit-learn/scikit-learn/blob/a24c8b464d094d2c468a16ea9f8bf8d42d949f84/sklearn/linear_model/cd_fast.pyx
+func sparseEnetCoordinateDescent(w *mat.VecDense, alpha, beta float64, X *base.CSC, XMean []float64, Y *mat.VecDense, maxIter int, tol float64, rng base.Intner, random, positive bool) *CDResult {
+	gap := tol + 1.
+	dwtol := tol
+
+	NSamples, NFeatures := X.Dims()
+	center := XMean != nil
+	xmean := func(j int) float64 {
+		if center {
+			return XMean[j]
+		}
+		return 0
+	}
+	// R -= mu * X[:,j] for the centered column j
+	addScaledCol := func(R []float64, j int, mu float64) {
+		X.Col(j).AddScaledTo(R, mu)
+		if center {
+			floats.AddConst(-mu*XMean[j], R)
+		}
+	}
+	y := make([]float64, NSamples)
+	mat.Col(y, 0, Y)
+	// # R = Y - np.dot(X, W.T)
+	R := append([]float64{}, y...)
+	normColsX := make([]float64, NFeatures)
+	for j := 0; j < NFeatures; j++ {
+		col, mean := X.Col(j), xmean(j)
+		for _, v := range col.Data {
+			normColsX[j] += (v - mean) * (v - mean)
+		}
+		normColsX[j] += float64(NSamples-len(col.Data)) * mean * mean
+		if wj := w.AtVec(j); wj != 0 {
+			addScaledCol(R, j, -wj)
+		}
+	}
+	// # tol = tol * linalg.norm(Y, ord='fro') ** 2
+	tol *= floats.Dot(y, y)
+
+	fsign := func(x float64) float64 {
+		if x > 0 {
+			return 1.
+		} else if x == 0. {
+			return 0.
+		}
+		return -1
+	}
+	var nIter int
+	XtA := make([]float64, NFeatures)
+	for nIter = 0; nIter < maxIter; nIter++ {
+		wmax, dwmax := 0., 0.
+		var ii int
+		for fIter := 0; fIter < NFeatures; fIter++ {
+			if random {
+				if rng != nil {
+					ii = rng.Intn(NFeatures)
+				} else {
+					ii = rand.Intn(NFeatures)
+				}
+			} else {
+				ii = fIter
+			}
+			if normColsX[ii] == 0. {
+				continue
+			}
+			wii := w.AtVec(ii)
+			if wii != 0. {
+				addScaledCol(R, ii, wii)
+			}
+			tmp := X.Col(ii).DotDense(R)
+			if center {
+				tmp -= xmean(ii) * floats.Sum(R)
+			}
+			if positive && tmp < 0. {
+				w.SetVec(ii, 0)
+			} else {
+				w.SetVec(ii, (fsign(tmp)*math.Max(math.Abs(tmp)-alpha, 0))/(normColsX[ii]+beta))
+			}
+			if wnew := w.AtVec(ii); wnew != 0. {
+				addScaledCol(R, ii, -wnew)
+			}
+			if dwii := math.Abs(w.AtVec(ii) - wii); dwii > dwmax {
+				dwmax = dwii
+			}
+			if math.Abs(w.AtVec(ii)) > wmax {
+				wmax = math.Abs(w.AtVec(ii))
+			}
+		}
+		if wmax == 0. || dwmax/wmax < dwtol || nIter == maxIter-1 {
+			// # XtA = np.dot(X.T, R) - beta * w
+			sumR := floats.Sum(R)
+			for j := range XtA {
+				XtA[j] = X.Col(j).DotDense(R) - xmean(j)*sumR - beta*w.AtVec(j)
+			}
+			dualNormXtA := math.Inf(-1)
+			for _, v := range XtA {
+				if !positive {
+					v = math.Abs(v)
+				}
+				dualNormXtA = math.Max(dualNormXtA, v)
+			}
+			RNorm2, wNorm2 := floats.Dot(R, R), mat.Dot(w, w)
+			cons := 1.
+			if dualNormXtA > alpha {
+				cons = alpha / dualNormXtA
+				gap = .5 * (RNorm2 + RNorm2*cons*cons)
+			} else {
+				gap = RNorm2
+			}
+			l1norm := blas64.Asum(w.RawVector())
+			gap += (alpha*l1norm - cons*floats.Dot(R, y)) + .5*beta*(1.+cons*cons)*wNorm2
+			if gap < tol {
+				// # return if we reached desired tolerance
+				break
+			}
+		}
+	}
+	return &CDResult{Gap: gap, Eps: tol, NIter: nIter + 1}
+}
@@ -41,7 +41,9 @@ func (*ElasticNet) IsClassifier() bool { return false }
 // PredicterClone for ElasticNet
 func (regr *ElasticNet) PredicterClone() base.Predicter {
 	clone := *regr
-	return &clone
+	clone.resetFitted()
+	clone.CDResult = CDResult{}
+	return base.DeepCopy(&clone).(*ElasticNet)
 }
 
 // GetNOutputs returns output columns number for Y to pass to predict
@@ -52,9 +54,21 @@ func (regr *ElasticNet) GetNOutputs() int {
 
 // Fit ElasticNetRegression with coordinate descent
 func (regr *ElasticNet) Fit(Xmatrix, Ymatrix mat.Matrix) base.Fiter {
+	return regr.FitWeighted(Xmatrix, Ymatrix, nil)
+}
+
+// FitWeighted is Fit with the squared error of each sample weighted by sampleWeight.
+// a sparse X is densified when sampleWeight is not nil. see base.WeightedFiter
+func (regr *ElasticNet) FitWeighted(Xmatrix, Ymatrix mat.Matrix, sampleWeight []float64) base.Fiter {
+	if base.IsSparse(Xmatrix) && sampleWeight == nil {
+		regr.fitSparse(base.ToCSC(Xmatrix), base.ToDense(Ymatrix))
+		return regr
+	}
 	X0, Y0 := base.ToDense(Xmatrix), base.ToDense(Ymatrix)
 	var X, Y, YOffset *mat.Dense
-	X, Y, regr.XOffset, YOffset, regr.XScale = PreprocessData(X0, Y0, regr.FitIntercept, regr.Normalize, nil)
+	X, Y, regr.XOffset, YOffset, regr.XScale = PreprocessData(X0, Y0, regr.FitIntercept, regr.Normalize, weightVec(sampleWeight))
+	// the penalty is scaled by NSamples, so weights are normalized for them to count as many samples
+	X, Y = rescaleData(X, Y, normalizeWeights(sampleWeight))
 	NSamples, NFeatures := X.Dims()
 	_, NOutputs := Y.Dims()
 
@@ -79,6 +93,14 @@ func (regr *ElasticNet) Fit(Xmatrix, Ymatrix mat.Matrix) base.Fiter {
 	return regr
 }
 
+// FitE is Fit returning an error instead of panicking
+func (regr *ElasticNet) FitE(X, Y mat.Matrix) error {
+	if err := checkRegularization(regr.Alpha, regr.L1Ratio); err != nil {
+		return err
+	}
+	return base.FitE(regr, X, Y)
+}
+
 // NewElasticNet creates a *ElasticNet with Alpha=1 and L1Ratio=0.5
 func NewElasticNet() *ElasticNet {
 	return NewMultiTaskElasticNet()
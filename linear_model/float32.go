@@ -0,0 +1,79 @@
+package linearmodel
+
+import (
+	"context"
+	"math"
+
+	"github.com/pa-m/sklearn/base"
+	"gonum.org/v1/gonum/mat"
+)
+
+// fit32 fits a RegularizedRegression on a float32 X, which is centered and normalized as a float32 copy
+func (regr *RegularizedRegression) fit32(ctx context.Context, X0 base.General32, Y0 *mat.Dense) error {
+	var (
+		X          base.General32
+		Y, YOffset *mat.Dense
+	)
+	X, Y, regr.XOffset, YOffset, regr.XScale = preprocessData32(X0, Y0, regr.FitIntercept, regr.Normalize)
+	opt := regr.linFitOptions(ctx)
+	res := LinFit(X, Y, &opt)
+	if res.Err != nil {
+		return res.Err
+	}
+	regr.Coef = res.Theta
+	regr.LinearModel.setIntercept(regr.XOffset, YOffset, regr.XScale)
+	return nil
+}
+
+// preprocessData32 is PreprocessData for a float32 X. the offsets and scales are accumulated as float64
+func preprocessData32(X base.General32, Y *mat.Dense, FitIntercept, Normalize bool) (Xout base.General32, Yout, XOffset, YOffset, XScale *mat.Dense) {
+	_, Yout, _, YOffset, _ = PreprocessData(mat.NewDense(1, 1, nil), Y, FitIntercept, false, nil)
+	nSamples, nFeatures := X.Dims()
+	XOffset, XScale = mat.NewDense(1, nFeatures, nil), mat.NewDense(1, nFeatures, nil)
+	offset, scale := XOffset.RawRowView(0), XScale.RawRowView(0)
+	for j := range scale {
+		scale[j] = 1
+	}
+	if !FitIntercept && !Normalize {
+		return X, Yout, XOffset, YOffset, XScale
+	}
+	if FitIntercept {
+		for i := 0; i < nSamples; i++ {
+			for j, v := range X.RawRowView(i) {
+				offset[j] += float64(v)
+			}
+		}
+		for j := range offset {
+			offset[j] /= float64(nSamples)
+		}
+	}
+	Xout = base.NewGeneral32(nSamples, nFeatures, nil)
+	for i := 0; i < nSamples; i++ {
+		row := Xout.RawRowView(i)
+		for j, v := range X.RawRowView(i) {
+			row[j] = float32(float64(v) - offset[j])
+		}
+	}
+	if Normalize {
+		for j := range scale {
+			scale[j] = 0
+		}
+		for i := 0; i < nSamples; i++ {
+			for j, v := range Xout.RawRowView(i) {
+				scale[j] += float64(v) * float64(v)
+			}
+		}
+		for j, s := range scale {
+			scale[j] = math.Sqrt(s)
+		}
+		for i := 0; i < nSamples; i++ {
+			row := Xout.RawRowView(i)
+			for j := range row {
+				if scale[j] != 0 {
+					row[j] = float32(float64(row[j]) / scale[j])
+				}
+			}
+		}
+	}
+	return
+}
@@ -0,0 +1,47 @@
+package linearmodel
+
+import (
+	"math"
+	"testing"
+
+	"github.com/pa-m/sklearn/base"
+	"github.com/pa-m/sklearn/datasets"
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestRidge_Float32(t *testing.T) {
+	rand.Seed(7)
+	X := mat.NewDense(200, 4, nil)
+	X.Apply(func(i, j int, v float64) float64 { return rand.NormFloat64()*10 + 1 }, X)
+	X = base.General32Of(X).ToDense()
+	Y := &mat.Dense{}
+	Y.Mul(X, mat.NewDense(4, 1, []float64{-2, 0, 3, .5}))
+	for _, normalize := range []bool{false, true} {
+		dense, f32 := NewRidge(), NewRidge()
+		dense.Alpha, f32.Alpha = 1e-3, 1e-3
+		dense.Normalize, f32.Normalize = normalize, normalize
+		dense.Fit(X, Y)
+		if err := f32.FitE(base.General32Of(X), Y); err != nil {
+			t.Fatal(err)
+		}
+		if !mat.EqualApprox(dense.Coef, f32.Coef, 1e-3) || !mat.EqualApprox(dense.Intercept, f32.Intercept, 1e-3) {
+			t.Errorf("normalize %v: dense and float32 fits differ\n%g %g\n%g %g", normalize, mat.Formatted(dense.Coef.T()), mat.Formatted(dense.Intercept),
+				mat.Formatted(f32.Coef.T()), mat.Formatted(f32.Intercept))
+		}
+	}
+}
+
+func TestLogisticRegression_Float32(t *testing.T) {
+	ds := datasets.LoadIris()
+	dense, f32 := NewLogisticRegression(), NewLogisticRegression()
+	dense.Fit(ds.X, ds.Y)
+	X32 := base.General32Of(ds.X)
+	f32.Fit(X32, ds.Y)
+	if !mat.EqualApprox(dense.PredictProba(ds.X, nil), f32.PredictProba(X32, nil), 1e-2) {
+		t.Error("dense and float32 probabilities differ")
+	}
+	if ad, a32 := dense.Score(ds.X, ds.Y), f32.Score(X32, ds.Y); math.Abs(ad-a32) > .02 {
+		t.Errorf("dense accuracy %g, float32 accuracy %g", ad, a32)
+	}
+}
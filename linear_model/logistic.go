@@ -1,6 +1,7 @@
 package linearmodel
 
 import (
+	"fmt"
 	"log"
 	"math"
 	"strings"
@@ -38,26 +39,30 @@ type LogisticRegression struct {
 	NIterNoChange int              `json:"n_iter_no_change"`
 
 	// Outputs
-	NLayers       int
-	NIter         int
-	NOutputs      int
-	Intercept     []float64      `json:"intercepts_"`
-	Coef          blas64.General `json:"coefs_"`
-	OutActivation string         `json:"out_activation_"`
-	Loss          float64
+	NLayers       int            `param:"-"`
+	NIter         int            `param:"-"`
+	NOutputs      int            `param:"-"`
+	Intercept     []float64      `json:"intercepts_" param:"-"`
+	Coef          blas64.General `json:"coefs_" param:"-"`
+	OutActivation string         `json:"out_activation_" param:"-"`
+	Loss          float64        `param:"-"`
 
 	// internal
 	t                  int
-	LossCurve          []float64
-	BestLoss           float64
-	NoImprovementCount int
-	InterceptsGrads    []float64
-	CoefsGrads         blas64.General
+	LossCurve          []float64      `param:"-"`
+	BestLoss           float64        `param:"-"`
+	NoImprovementCount int            `param:"-"`
+	InterceptsGrads    []float64      `param:"-"`
+	CoefsGrads         blas64.General `param:"-"`
 	packedParameters   []float64
 	packedGrads        []float64
 	// bestParameters     []float64
 	lb             *preprocessing.LabelBinarizer
 	beforeMinimize func(optimize.Problem, []float64)
+	// xMatrix is X during Fit when it is sparse or float32, and is not converted to a blas64.General
+	xMatrix mat.Matrix
+	// sampleWeight is set during FitWeighted
+	sampleWeight []float64
 }
 
 // logregActivation is a map containing the inplace_activation functions
@@ -93,12 +98,13 @@ var logregActivation = map[string]func(z blas64.General){
 	},
 }
 
-// logregLossFunctions is a map for loss functions
-var logregLossFunctions = map[string]func(y, h blas64.General) float64{
-	"log_loss": func(y, h blas64.General) float64 {
+// logregLossFunctions is a map for loss functions. the loss of each sample is weighted by w if it is not nil
+var logregLossFunctions = map[string]func(y, h blas64.General, w []float64) float64{
+	"log_loss": func(y, h blas64.General, w []float64) float64 {
 		sum := float64(0)
 		hmin, hmax := math.Nextafter(0, 1), math.Nextafter(1, 0)
 		for row, hpos, ypos := 0, 0, 0; row < y.Rows; row, hpos, ypos = row+1, hpos+h.Stride, ypos+y.Stride {
+			rowSum := float64(0)
 			for col := 0; col < y.Cols; col++ {
 				hval := h.Data[hpos+col]
 				if hval < hmin {
@@ -107,16 +113,18 @@ var logregLossFunctions = map[string]func(y, h blas64.General) float64{
 					hval = hmax
 				}
 				if y.Data[ypos+col] != 0 {
-					sum += -y.Data[ypos+col] * math.Log(hval)
+					rowSum += -y.Data[ypos+col] * math.Log(hval)
 				}
 			}
+			sum += sampleWeightAt(w, row) * rowSum
 		}
 		return sum / float64(h.Rows)
 	},
-	"binary_log_loss": func(y, h blas64.General) float64 {
+	"binary_log_loss": func(y, h blas64.General, w []float64) float64 {
 		sum := float64(0)
 		hmin, hmax := math.Nextafter(0, 1), math.Nextafter(1, 0)
 		for row, hpos, ypos := 0, 0, 0; row < y.Rows; row, hpos, ypos = row+1, hpos+h.Stride, ypos+y.Stride {
+			rowSum := float64(0)
 			for col := 0; col < y.Cols; col++ {
 				hval := h.Data[hpos+col]
 				if hval < hmin {
@@ -124,8 +132,9 @@ var logregLossFunctions = map[string]func(y, h blas64.General) float64{
 				} else if hval > hmax {
 					hval = hmax
 				}
-				sum += -y.Data[ypos+col]*math.Log(hval) - (1-y.Data[ypos+col])*math.Log1p(-hval)
+				rowSum += -y.Data[ypos+col]*math.Log(hval) - (1-y.Data[ypos+col])*math.Log1p(-hval)
 			}
+			sum += sampleWeightAt(w, row) * rowSum
 		}
 		return sum / float64(h.Rows)
 	},
@@ -168,13 +177,14 @@ func NewLogisticRegression() *LogisticRegression {
 
 // PredicterClone ...
 func (m *LogisticRegression) PredicterClone() base.Predicter {
-	clone := *m
-	if sc, ok := m.RandomState.(base.SourceCloner); ok {
-		clone.RandomState = sc.Clone()
-	}
-	return &clone
+	clone := &LogisticRegression{Alpha: m.Alpha, MaxIter: m.MaxIter, LossFuncName: m.LossFuncName, RandomState: m.RandomState,
+		Tol: m.Tol, Verbose: m.Verbose, NIterNoChange: m.NIterNoChange, beforeMinimize: m.beforeMinimize}
+	return base.DeepCopy(clone).(*LogisticRegression)
 }
 
+// IsFitted returns true when Coef has been fitted. see base.FittedChecker
+func (m *LogisticRegression) IsFitted() bool { return m.Coef.Data != nil }
+
 // forwardPass Perform a forward pass on the network by computing the values
 // of the neurons the output layer.
 //        activations : []blas64.General, length = nLayers - 1
@@ -182,7 +192,11 @@ func (m *LogisticRegression) forwardPass(activations []blas64.General) {
 	hiddenActivation := logregActivation["logistic"]
 	var i int
 	for i = 0; i < m.NLayers-1; i++ {
-		blas64.Gemm(blas.NoTrans, blas.NoTrans, 1, activations[i], m.Coef, 0, activations[i+1])
+		if i == 0 && m.xMatrix != nil {
+			base.MatMul(generalDense(activations[1]), m.xMatrix, generalDense(m.Coef))
+		} else {
+			blas64.Gemm(blas.NoTrans, blas.NoTrans, 1, activations[i], m.Coef, 0, activations[i+1])
+		}
 		addIntercepts64(activations[i+1], m.Intercept)
 		// For the hidden layers
 		if (i + 1) != (m.NLayers - 1) {
@@ -211,7 +225,12 @@ func (m *LogisticRegression) computeLossGrad(layer, NSamples int, activations []
 	// coefGrads[layer] = safeSparseDot(activations[layer].T, deltas[layer])
 	// coefGrads[layer] += (self.alpha * self.coefs_[layer])
 	// coefGrads[layer] /= nSamples
-	blas64.Gemm(blas.Trans, blas.NoTrans, 1/float64(NSamples), activations[layer], deltas, 0, coefGrads)
+	if layer == 0 && m.xMatrix != nil {
+		base.MatMul(generalDense(coefGrads), m.xMatrix.T(), generalDense(deltas))
+		floats.Scale(1/float64(NSamples), coefGrads.Data)
+	} else {
+		blas64.Gemm(blas.Trans, blas.NoTrans, 1/float64(NSamples), activations[layer], deltas, 0, coefGrads)
+	}
 	blas64.Axpy(m.Alpha/float64(NSamples), blas64.Vector{N: len(m.Coef.Data), Data: m.Coef.Data, Inc: 1}, blas64.Vector{N: len(coefGrads.Data), Data: coefGrads.Data, Inc: 1})
 	// interceptGrads[layer] = np.mean(deltas[layer], 0)
 	matRowMean64(deltas, interceptGrads)
@@ -234,7 +253,7 @@ func (m *LogisticRegression) backprop(X, y blas64.General, activations []blas64.
 		lossFuncName = "binary_log_loss"
 	}
 	// y may have less rows than activations il last batch
-	loss := logregLossFunctions[lossFuncName](y, activations[len(activations)-1])
+	loss := logregLossFunctions[lossFuncName](y, activations[len(activations)-1], m.sampleWeight)
 	// # Add L2 regularization term to loss
 	loss += (0.5 * m.Alpha) * m.sumCoefSquares() / float64(nSamples)
 
@@ -252,8 +271,9 @@ func (m *LogisticRegression) backprop(X, y blas64.General, activations []blas64.
 		H := activations[len(activations)-1]
 		D := deltas
 		for r, pos := 0, 0; r < y.Rows; r, pos = r+1, pos+y.Stride {
+			w := sampleWeightAt(m.sampleWeight, r)
 			for o, posc := 0, pos; o < y.Cols; o, posc = o+1, posc+1 {
-				D.Data[posc] = H.Data[posc] - y.Data[posc]
+				D.Data[posc] = w * (H.Data[posc] - y.Data[posc])
 			}
 		}
 	}
@@ -334,26 +354,39 @@ func (m *LogisticRegression) IsClassifier() bool {
 	return true
 }
 
-// Fit compute Coef and Intercept
+// Fit compute Coef and Intercept. X may be a *base.CSR or a *base.CSC, which is not densified
 func (m *LogisticRegression) Fit(X, Y mat.Matrix) base.Fiter {
-	var xb, yb *mat.Dense
-	if xg, ok := X.(*mat.Dense); ok {
-		if yg, ok := Y.(*mat.Dense); ok {
-			xb, yb = xg, yg
-		}
+	return m.FitWeighted(X, Y, nil)
+}
+
+// FitWeighted is Fit with the loss of each sample weighted by sampleWeight. see base.WeightedFiter
+func (m *LogisticRegression) FitWeighted(X, Y mat.Matrix, sampleWeight []float64) base.Fiter {
+	m.sampleWeight = sampleWeight
+	defer func() { m.sampleWeight = nil }()
+	var x blas64.General
+	switch {
+	case base.IsSparse(X):
+		m.xMatrix = base.ToCSR(X)
+	case base.IsFloat32(X):
+		m.xMatrix = X
+	}
+	if m.xMatrix != nil {
+		defer func() { m.xMatrix = nil }()
+		x.Rows, x.Cols = X.Dims()
 	} else {
-		xb = mat.DenseCopyOf(X)
-		yb = mat.DenseCopyOf(Y)
+		x = base.ToDense(X).RawMatrix()
 	}
+	yb := base.ToDense(Y)
 	if m.IsClassifier() && !isBinarized(yb) {
 		m.lb = preprocessing.NewLabelBinarizer(0, 1)
-		xbin, ybin := m.lb.FitTransform(X, Y)
-		xb, yb = xbin, ybin
+		_, yb = m.lb.FitTransform(nil, Y)
 	}
 	// # Validate input parameters.
-	m.validateHyperparameters()
+	if err := m.validateHyperparameters(); err != nil {
+		panic(err)
+	}
 
-	x, y := xb.RawMatrix(), yb.RawMatrix()
+	y := yb.RawMatrix()
 	nSamples, nFeatures := x.Rows, x.Cols
 
 	m.NOutputs = y.Cols
@@ -386,16 +419,25 @@ func (m *LogisticRegression) GetNOutputs() int {
 	return m.NOutputs
 }
 
-func (m *LogisticRegression) validateHyperparameters() {
+// FitE is Fit returning an error instead of panicking
+func (m *LogisticRegression) FitE(X, Y mat.Matrix) error {
+	if err := m.validateHyperparameters(); err != nil {
+		return err
+	}
+	return base.FitE(m, X, Y)
+}
+
+func (m *LogisticRegression) validateHyperparameters() error {
 	if m.MaxIter <= 0 {
-		log.Panicf("maxIter must be > 0, got %d.", m.MaxIter)
+		return fmt.Errorf("%w: maxIter must be > 0, got %d", base.ErrInvalidParam, m.MaxIter)
 	}
 	if m.Alpha < 0.0 {
-		log.Panicf("alpha must be >= 0, got %g.", m.Alpha)
+		return fmt.Errorf("%w: alpha must be >= 0, got %g", base.ErrInvalidParam, m.Alpha)
 	}
 	if m.NIterNoChange <= 0 {
-		log.Panicf("nIterNoChange must be > 0, got %d.", m.NIterNoChange)
+		return fmt.Errorf("%w: nIterNoChange must be > 0, got %d", base.ErrInvalidParam, m.NIterNoChange)
 	}
+	return nil
 }
 
 func (m *LogisticRegression) fitLbfgs(X, y blas64.General, activations []blas64.General, deltas, coefGrads blas64.General,
@@ -452,6 +494,11 @@ func (m *LogisticRegression) fitLbfgs(X, y blas64.General, activations []blas64.
 // PredictProbas return probability estimates.
 // The returned estimates for all classes are ordered by the label of classes.
 func (m *LogisticRegression) PredictProbas(Xmatrix mat.Matrix, Ymutable mat.Mutable) *mat.Dense {
+	if base.IsSparse(Xmatrix) || base.IsFloat32(Xmatrix) {
+		P := m.DecisionFunction(Xmatrix, nil)
+		logregActivation[m.OutActivation](P.RawMatrix())
+		return base.FromDense(Ymutable, P)
+	}
 	X, Y := base.ToDense(Xmatrix).RawMatrix(), base.ToDense(Ymutable)
 	if Y.IsZero() {
 		fanOut := 0
@@ -472,8 +519,30 @@ func (m *LogisticRegression) PredictProbas(Xmatrix mat.Matrix, Ymutable mat.Muta
 	return base.FromDense(Ymutable, Y)
 }
 
+// PredictProba returns a probability column per class. see base.ProbaPredicter
+func (m *LogisticRegression) PredictProba(X mat.Matrix, Y mat.Mutable) *mat.Dense {
+	P := m.PredictProbas(X, nil)
+	if m.lb == nil && m.OutActivation == "logistic" {
+		P = base.BinaryProba(P)
+	}
+	return base.FromDense(Y, P)
+}
+
+// DecisionFunction returns X dot Coef+Intercept, the input of the output activation
+func (m *LogisticRegression) DecisionFunction(X mat.Matrix, Y mat.Mutable) *mat.Dense {
+	nSamples, _ := X.Dims()
+	D := mat.NewDense(nSamples, m.Coef.Cols, nil)
+	base.MatMul(D, X, generalDense(m.Coef))
+	d := D.RawMatrix()
+	for i, off := 0, 0; i < d.Rows; i, off = i+1, off+d.Stride {
+		floats.Add(d.Data[off:off+d.Cols], m.Intercept)
+	}
+	return base.FromDense(Y, D)
+}
+
 // Predict do forward pass and fills Y (Y must be mat.Mutable)
 func (m *LogisticRegression) Predict(X mat.Matrix, Y mat.Mutable) *mat.Dense {
+	base.MustBeFitted(m)
 	ybin := m.PredictProbas(X, nil)
 	var Yclasses *mat.Dense
 	if m.lb != nil {
@@ -486,15 +555,33 @@ func (m *LogisticRegression) Predict(X mat.Matrix, Y mat.Mutable) *mat.Dense {
 	return base.FromDense(Y, Yclasses)
 }
 
+// PredictE is Predict returning an error instead of panicking
+func (m *LogisticRegression) PredictE(X mat.Matrix, Y mat.Mutable) (*mat.Dense, error) {
+	if !m.IsFitted() {
+		return nil, base.ErrNotFitted
+	}
+	if err := base.CheckNFeatures(X, m.Coef.Rows); err != nil {
+		return nil, err
+	}
+	return base.PredictE(m, X, Y)
+}
+
 // Score for LogisticRegression is accuracy
-func (m *LogisticRegression) Score(Xmatrix, Ymatrix mat.Matrix) float64 {
-	X, Y := base.ToDense(Xmatrix), base.ToDense(Ymatrix)
-	nSamples, nOutputs := X.RawMatrix().Rows, m.GetNOutputs()
-	Ypred := mat.NewDense(nSamples, nOutputs, nil)
+func (m *LogisticRegression) Score(X, Ymatrix mat.Matrix) float64 {
+	Y := base.ToDense(Ymatrix)
+	nSamples, _ := X.Dims()
+	Ypred := mat.NewDense(nSamples, m.GetNOutputs(), nil)
 	m.Predict(X, Ypred)
 	return metrics.AccuracyScore(Y, Ypred, true, nil)
 }
 
+// generalDense returns a *mat.Dense view of g
+func generalDense(g blas64.General) *mat.Dense {
+	d := &mat.Dense{}
+	d.SetRawMatrix(g)
+	return d
+}
+
 func toLogits(ym blas64.General) {
 	for i, ypos := 0, 0; i < ym.Rows; i, ypos = i+1, ypos+ym.Stride {
 		if ym.Cols == 1 {
@@ -8,11 +8,13 @@ import (
 	"math"
 	"os"
 	"os/exec"
+	"testing"
 	"time"
 
 	"github.com/pa-m/sklearn/base"
 	"github.com/pa-m/sklearn/datasets"
 	"gonum.org/v1/gonum/diff/fd"
+	"gonum.org/v1/gonum/floats"
 	"gonum.org/v1/gonum/mat"
 	"gonum.org/v1/gonum/optimize"
 	"gonum.org/v1/plot"
@@ -21,7 +23,9 @@ import (
 	"gonum.org/v1/plot/vg/draw"
 )
 
-var _ base.Predicter = &LogisticRegression{}
+var _ base.PredicterE = &LogisticRegression{}
+var _ base.ProbaPredicter = &LogisticRegression{}
+var _ base.DecisionFunctioner = &LogisticRegression{}
 var visualDebug = flag.Bool("visual", false, "output images for benchmarks and test data")
 
 func ExampleLogisticRegression() {
@@ -144,3 +148,25 @@ func ExampleLogisticRegression() {
 	// Output:
 	// ok
 }
+
+func TestLogisticRegression_PredictProba(t *testing.T) {
+	ds := datasets.LoadIris()
+	m := NewLogisticRegression()
+	m.RandomState = base.NewSource(7)
+	m.Fit(ds.X, ds.Y)
+	P := m.PredictProba(ds.X, nil)
+	D := m.DecisionFunction(ds.X, nil)
+	Ypred := m.Predict(ds.X, nil)
+	nSamples, _ := ds.X.Dims()
+	if _, c := P.Dims(); c != 3 {
+		t.Fatalf("expected 3 columns, got %d", c)
+	}
+	for i := 0; i < nSamples; i++ {
+		if s := floats.Sum(P.RawRowView(i)); math.Abs(s-1) > 1e-6 {
+			t.Errorf("row %d probabilities sum to %g", i, s)
+		}
+		if c := float64(floats.MaxIdx(P.RawRowView(i))); c != Ypred.At(i, 0) || float64(floats.MaxIdx(D.RawRowView(i))) != c {
+			t.Errorf("row %d: PredictProba and DecisionFunction disagree with Predict", i)
+		}
+	}
+}
@@ -31,7 +31,7 @@ var LossFunctions = map[string]Loss{"square": SquareLoss, "log": LogLoss, "cross
 // grad:  hprime*(h-y)
 //
 func SquareLoss(Ytrue, X mat.Matrix, Theta, Ypred, Ydiff, grad *mat.Dense, Alpha, L1Ratio float64, nSamples int, activation Activation, disableRegularizationOfFirstFeature bool) (J float64) {
-	Ypred.Mul(X, Theta)
+	base.MatMul(Ypred, X, Theta)
 	Ypred.Apply(func(i, o int, xtheta float64) float64 { return activation.F(xtheta) }, Ypred)
 	Ydiff.Sub(Ypred, Ytrue)
 	J = 0.
@@ -42,7 +42,7 @@ func SquareLoss(Ytrue, X mat.Matrix, Theta, Ypred, Ydiff, grad *mat.Dense, Alpha
 	// put into grad
 	if grad != nil {
 		if _, ok := activation.(base.Identity); ok {
-			grad.Mul(X.T(), Ydiff) //<- for identity only
+			base.MatMul(grad, X.T(), Ydiff) //<- for identity only
 
 		} else {
 			grad.Apply(func(j, o int, theta float64) float64 {
@@ -67,7 +67,7 @@ func SquareLoss(Ytrue, X mat.Matrix, Theta, Ypred, Ydiff, grad *mat.Dense, Alpha
 
 // LogLoss for one versus rest classifiers
 func LogLoss(Ytrue, X mat.Matrix, Theta, Ypred, Ydiff, grad *mat.Dense, Alpha, L1Ratio float64, nSamples int, activation Activation, disableRegularizationOfFirstFeature bool) (J float64) {
-	Ypred.Mul(X, Theta)
+	base.MatMul(Ypred, X, Theta)
 	Ypred.Apply(func(i, o int, xtheta float64) float64 { return activation.F(xtheta) }, Ypred)
 	Ydiff.Sub(Ypred, Ytrue)
 	J = 0.
@@ -120,7 +120,7 @@ func LogLoss(Ytrue, X mat.Matrix, Theta, Ypred, Ydiff, grad *mat.Dense, Alpha, L
 // grad:  hprime*(-y/h + (1-y)/(1-h))
 //
 func CrossEntropyLoss(Ytrue, X mat.Matrix, Theta, Ypred, Ydiff, grad *mat.Dense, Alpha, L1Ratio float64, nSamples int, activation Activation, disableRegularizationOfFirstFeature bool) (J float64) {
-	Ypred.Mul(X, Theta)
+	base.MatMul(Ypred, X, Theta)
 	Ypred.Apply(func(i, o int, xtheta float64) float64 { return panicIfNaN(activation.F(xtheta)) }, Ypred)
 	Ydiff.Sub(Ypred, Ytrue)
 	J = 0.
@@ -145,7 +145,7 @@ func CrossEntropyLoss(Ytrue, X mat.Matrix, Theta, Ypred, Ydiff, grad *mat.Dense,
 	}, Ypred)
 	if grad != nil {
 		if _, ok := activation.(base.Logistic); ok {
-			grad.Mul(X.T(), Ydiff)
+			base.MatMul(grad, X.T(), Ydiff)
 		} else {
 			// // for Logistic activation only
 			grad.Apply(func(j, o int, theta float64) float64 {
@@ -0,0 +1,53 @@
+package linearmodel
+
+import "github.com/pa-m/sklearn/base"
+
+// GetParams returns the parameters of LinearRegression. see base.GetFieldParams
+func (regr *LinearRegression) GetParams() map[string]interface{} { return base.GetFieldParams(regr) }
+
+// SetParams sets the parameters of LinearRegression. see base.SetFieldParams
+func (regr *LinearRegression) SetParams(params map[string]interface{}) error {
+	return base.SetFieldParams(regr, params)
+}
+
+// GetParams returns the parameters of RegularizedRegression. see base.GetFieldParams
+func (regr *RegularizedRegression) GetParams() map[string]interface{} {
+	return base.GetFieldParams(regr)
+}
+
+// SetParams sets the parameters of RegularizedRegression. see base.SetFieldParams
+func (regr *RegularizedRegression) SetParams(params map[string]interface{}) error {
+	return base.SetFieldParams(regr, params)
+}
+
+// GetParams returns the parameters of SGDRegressor. see base.GetFieldParams
+func (regr *SGDRegressor) GetParams() map[string]interface{} { return base.GetFieldParams(regr) }
+
+// SetParams sets the parameters of SGDRegressor. see base.SetFieldParams
+func (regr *SGDRegressor) SetParams(params map[string]interface{}) error {
+	return base.SetFieldParams(regr, params)
+}
+
+// GetParams returns the parameters of BayesianRidge. see base.GetFieldParams
+func (regr *BayesianRidge) GetParams() map[string]interface{} { return base.GetFieldParams(regr) }
+
+// SetParams sets the parameters of BayesianRidge. see base.SetFieldParams
+func (regr *BayesianRidge) SetParams(params map[string]interface{}) error {
+	return base.SetFieldParams(regr, params)
+}
+
+// GetParams returns the parameters of ElasticNet. see base.GetFieldParams
+func (regr *ElasticNet) GetParams() map[string]interface{} { return base.GetFieldParams(regr) }
+
+// SetParams sets the parameters of ElasticNet. see base.SetFieldParams
+func (regr *ElasticNet) SetParams(params map[string]interface{}) error {
+	return base.SetFieldParams(regr, params)
+}
+
+// GetParams returns the parameters of LogisticRegression. see base.GetFieldParams
+func (m *LogisticRegression) GetParams() map[string]interface{} { return base.GetFieldParams(m) }
+
+// SetParams sets the parameters of LogisticRegression. see base.SetFieldParams
+func (m *LogisticRegression) SetParams(params map[string]interface{}) error {
+	return base.SetFieldParams(m, params)
+}
@@ -0,0 +1,85 @@
+package linearmodel
+
+import (
+	"github.com/pa-m/sklearn/base"
+	"github.com/pa-m/sklearn/preprocessing"
+)
+
+func init() {
+	base.Register(&LinearRegression{})
+	base.Register(&RegularizedRegression{})
+	base.Register(&SGDRegressor{})
+	base.Register(&ElasticNet{})
+	base.Register(&BayesianRidge{})
+	base.Register(&LogisticRegression{})
+}
+
+// MarshalState allows LinearRegression to be saved by base.Save
+func (regr *LinearRegression) MarshalState() (*base.State, error) { return base.MarshalFields(regr) }
+
+// UnmarshalState restores a LinearRegression saved by base.Save
+func (regr *LinearRegression) UnmarshalState(st *base.State) error {
+	*regr = *NewLinearRegression()
+	return base.UnmarshalFields(regr, st)
+}
+
+// MarshalState allows RegularizedRegression to be saved by base.Save. SolverConfigure, LossFunction and ActivationFunction
+// are not saved
+func (regr *RegularizedRegression) MarshalState() (*base.State, error) {
+	return base.MarshalFields(regr)
+}
+
+// UnmarshalState restores a RegularizedRegression saved by base.Save
+func (regr *RegularizedRegression) UnmarshalState(st *base.State) error {
+	*regr = RegularizedRegression{}
+	return base.UnmarshalFields(regr, st)
+}
+
+// MarshalState allows SGDRegressor to be saved by base.Save. Method is not saved
+func (regr *SGDRegressor) MarshalState() (*base.State, error) { return base.MarshalFields(regr) }
+
+// UnmarshalState restores a SGDRegressor saved by base.Save
+func (regr *SGDRegressor) UnmarshalState(st *base.State) error {
+	*regr = *NewSGDRegressor()
+	return base.UnmarshalFields(regr, st)
+}
+
+// MarshalState allows ElasticNet to be saved by base.Save
+func (regr *ElasticNet) MarshalState() (*base.State, error) { return base.MarshalFields(regr) }
+
+// UnmarshalState restores an ElasticNet saved by base.Save
+func (regr *ElasticNet) UnmarshalState(st *base.State) error {
+	*regr = *NewElasticNet()
+	return base.UnmarshalFields(regr, st)
+}
+
+// MarshalState allows BayesianRidge to be saved by base.Save
+func (regr *BayesianRidge) MarshalState() (*base.State, error) { return base.MarshalFields(regr) }
+
+// UnmarshalState restores a BayesianRidge saved by base.Save
+func (regr *BayesianRidge) UnmarshalState(st *base.State) error {
+	*regr = *NewBayesianRidge()
+	return base.UnmarshalFields(regr, st)
+}
+
+// MarshalState allows LogisticRegression to be saved by base.Save. RandomState is not saved
+func (m *LogisticRegression) MarshalState() (*base.State, error) {
+	st, err := base.MarshalFields(m)
+	if err == nil && m.lb != nil {
+		err = st.SetChild("lb", m.lb)
+	}
+	return st, err
+}
+
+// UnmarshalState restores a LogisticRegression saved by base.Save
+func (m *LogisticRegression) UnmarshalState(st *base.State) error {
+	*m = *NewLogisticRegression()
+	if err := base.UnmarshalFields(m, st); err != nil {
+		return err
+	}
+	p, err := st.GetChild("lb")
+	if lb, ok := p.(*preprocessing.LabelBinarizer); ok {
+		m.lb = lb
+	}
+	return err
+}
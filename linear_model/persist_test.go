@@ -0,0 +1,41 @@
+package linearmodel
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/pa-m/sklearn/base"
+	"github.com/pa-m/sklearn/datasets"
+	"gonum.org/v1/gonum/mat"
+)
+
+var _ = []base.Persister{&LinearRegression{}, &Ridge{}, &SGDRegressor{}, &ElasticNet{}, &BayesianRidge{}, &LogisticRegression{}}
+
+func TestSaveLoad(t *testing.T) {
+	diabetes := datasets.LoadDiabetes()
+	iris := datasets.LoadIris()
+	for _, test := range []struct {
+		m    base.Predicter
+		X, Y *mat.Dense
+	}{
+		{NewLinearRegression(), diabetes.X, diabetes.Y},
+		{NewRidge(), diabetes.X, diabetes.Y},
+		{NewElasticNet(), diabetes.X, diabetes.Y},
+		{NewBayesianRidge(), diabetes.X, diabetes.Y},
+		{NewLogisticRegression(), iris.X, iris.Y},
+	} {
+		test.m.Fit(test.X, test.Y)
+		buf := new(bytes.Buffer)
+		if err := base.Save(buf, test.m.(base.Persister)); err != nil {
+			t.Fatalf("%T: %s", test.m, err)
+		}
+		loaded, err := base.Load(buf)
+		if err != nil {
+			t.Fatalf("%T: %s", test.m, err)
+		}
+		expected, actual := test.m.Predict(test.X, nil), loaded.Predict(test.X, nil)
+		if !mat.EqualApprox(expected, actual, 1e-12) {
+			t.Errorf("%T: loaded model predictions differ", test.m)
+		}
+	}
+}
@@ -19,5 +19,6 @@ func NewRidge() *RegularizedRegression {
 // PredicterClone for Ridge
 func (m *Ridge) PredicterClone() base.Predicter {
 	clone := *m
-	return &clone
+	clone.resetFitted()
+	return base.DeepCopy(&clone).(*Ridge)
 }
@@ -0,0 +1,105 @@
+package linearmodel
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pa-m/sklearn/base"
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mat"
+)
+
+// fitSparse fits a RegularizedRegression on a sparse X, which is not centered.
+// the intercept is fitted as the coefficient of a prepended column of ones, which is not regularized
+func (regr *RegularizedRegression) fitSparse(ctx context.Context, X *base.CSR, Y *mat.Dense) error {
+	if regr.Normalize {
+		return fmt.Errorf("%w: Normalize is not supported with a sparse X", base.ErrInvalidParam)
+	}
+	_, nFeatures := X.Dims()
+	_, nOutputs := Y.Dims()
+	opt := regr.linFitOptions(ctx)
+	Xfit := X
+	if regr.FitIntercept {
+		Xfit = csrOnesPrepended(X)
+		opt.DisableRegularizationOfFirstFeature = true
+	}
+	res := LinFit(Xfit, Y, &opt)
+	if res.Err != nil {
+		return res.Err
+	}
+	regr.XOffset, regr.XScale = sparseOffsetScale(nFeatures)
+	regr.Intercept = mat.NewDense(1, nOutputs, nil)
+	if regr.FitIntercept {
+		regr.Intercept.Copy(res.Theta.Slice(0, 1, 0, nOutputs))
+		regr.Coef = mat.DenseCopyOf(res.Theta.Slice(1, nFeatures+1, 0, nOutputs))
+	} else {
+		regr.Coef = res.Theta
+	}
+	return nil
+}
+
+// fitSparse fits an ElasticNet on a sparse X with a single output. X is implicitly centered when FitIntercept is set
+func (regr *ElasticNet) fitSparse(X *base.CSC, Y *mat.Dense) {
+	NSamples, NFeatures := X.Dims()
+	_, NOutputs := Y.Dims()
+	if regr.Normalize {
+		panic(fmt.Errorf("%w: Normalize is not supported with a sparse X", base.ErrInvalidParam))
+	}
+	if NOutputs != 1 {
+		panic(fmt.Errorf("%w: sparse X is supported for a single output, got %d", base.ErrInvalidParam, NOutputs))
+	}
+	regr.XOffset, regr.XScale = sparseOffsetScale(NFeatures)
+	YOffset := mat.NewDense(1, 1, nil)
+	y := mat.NewVecDense(NSamples, nil)
+	mat.Col(y.RawVector().Data, 0, Y)
+	var XMean []float64
+	if regr.FitIntercept {
+		XMean = regr.XOffset.RawRowView(0)
+		for j := range XMean {
+			XMean[j] = floats.Sum(X.Col(j).Data) / float64(NSamples)
+		}
+		ymean := mat.Sum(y) / float64(NSamples)
+		YOffset.Set(0, 0, ymean)
+		for i := range y.RawVector().Data {
+			y.SetVec(i, y.AtVec(i)-ymean)
+		}
+	}
+	l1reg := regr.Alpha * regr.L1Ratio * float64(NSamples)
+	l2reg := regr.Alpha * (1. - regr.L1Ratio) * float64(NSamples)
+	if !regr.WarmStart || regr.Coef == nil {
+		regr.Coef = mat.NewDense(NFeatures, NOutputs, nil)
+	}
+	w := &mat.VecDense{}
+	w.ColViewOf(regr.Coef, 0)
+	random := strings.EqualFold("random", regr.Selection)
+	regr.CDResult = *sparseEnetCoordinateDescent(w, l1reg, l2reg, X, XMean, y, regr.MaxIter, regr.Tol, nil, random, regr.Positive)
+	regr.LinearModel.setIntercept(regr.XOffset, YOffset, regr.XScale)
+}
+
+// csrOnesPrepended returns X with a first column of ones
+func csrOnesPrepended(X *base.CSR) *base.CSR {
+	r, c := X.Dims()
+	nnz := X.NNZ() + r
+	Xout := base.NewCSR(r, c+1, make([]int, r+1), make([]int, 0, nnz), make([]float64, 0, nnz))
+	for i := 0; i < r; i++ {
+		row := X.Row(i)
+		Xout.Indices = append(Xout.Indices, 0)
+		Xout.Data = append(Xout.Data, 1)
+		for _, j := range row.Indices {
+			Xout.Indices = append(Xout.Indices, j+1)
+		}
+		Xout.Data = append(Xout.Data, row.Data...)
+		Xout.Indptr[i+1] = len(Xout.Data)
+	}
+	return Xout
+}
+
+// sparseOffsetScale returns a zero XOffset and a unit XScale
+func sparseOffsetScale(nFeatures int) (XOffset, XScale *mat.Dense) {
+	XOffset, XScale = mat.NewDense(1, nFeatures, nil), mat.NewDense(1, nFeatures, nil)
+	for j := 0; j < nFeatures; j++ {
+		XScale.Set(0, j, 1)
+	}
+	return
+}
@@ -0,0 +1,81 @@
+package linearmodel
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/pa-m/sklearn/base"
+	"github.com/pa-m/sklearn/datasets"
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/mat"
+)
+
+func sparsify(X *mat.Dense, density float64) *mat.Dense {
+	Xs := mat.DenseCopyOf(X)
+	Xs.Apply(func(i, j int, v float64) float64 {
+		if rand.Float64() < density {
+			return v
+		}
+		return 0
+	}, Xs)
+	return Xs
+}
+
+func TestElasticNet_Sparse(t *testing.T) {
+	rand.Seed(7)
+	p := NewRandomLinearProblem(100, 8, 1)
+	X := sparsify(p.X, .3)
+	Y := &mat.Dense{}
+	Y.Mul(X, mat.NewDense(8, 1, []float64{1, 0, 3, -2, 0, 0, 5, 1}))
+	dense, sparse := NewLasso(), NewLasso()
+	dense.Alpha, sparse.Alpha = .1, .1
+	dense.Normalize, sparse.Normalize = false, false
+	dense.Fit(X, Y)
+	sparse.Fit(base.ToCSR(X), Y)
+	if !mat.EqualApprox(dense.Coef, sparse.Coef, 1e-4) || !mat.EqualApprox(dense.Intercept, sparse.Intercept, 1e-4) {
+		t.Errorf("dense and sparse fits differ\n%g %g\n%g %g", mat.Formatted(dense.Coef.T()), mat.Formatted(dense.Intercept),
+			mat.Formatted(sparse.Coef.T()), mat.Formatted(sparse.Intercept))
+	}
+	if !mat.EqualApprox(dense.Predict(X, nil), sparse.Predict(base.ToCSC(X), nil), 1e-3) {
+		t.Error("dense and sparse predictions differ")
+	}
+}
+
+func TestLogisticRegression_Sparse(t *testing.T) {
+	ds := datasets.LoadIris()
+	X := sparsify(ds.X, .7)
+	dense, sparse := NewLogisticRegression(), NewLogisticRegression()
+	dense.Fit(X, ds.Y)
+	sparse.Fit(base.ToCSR(X), ds.Y)
+	if !mat.EqualApprox(generalDense(dense.Coef), generalDense(sparse.Coef), 1e-2) {
+		t.Errorf("dense and sparse coefs differ\n%g\n%g", mat.Formatted(generalDense(dense.Coef)), mat.Formatted(generalDense(sparse.Coef)))
+	}
+	if !mat.EqualApprox(dense.PredictProba(X, nil), sparse.PredictProba(base.ToCSR(X), nil), 1e-2) {
+		t.Error("dense and sparse probabilities differ")
+	}
+	if as, ad := sparse.Score(base.ToCSR(X), ds.Y), dense.Score(X, ds.Y); math.Abs(as-ad) > .02 {
+		t.Errorf("dense accuracy %g, sparse accuracy %g", ad, as)
+	}
+}
+
+func TestRidge_Sparse(t *testing.T) {
+	rand.Seed(7)
+	X := sparsify(NewRandomLinearProblem(200, 5, 1).X, .5)
+	Y := &mat.Dense{}
+	Y.Mul(X, mat.NewDense(5, 1, []float64{1, -2, 0, 3, .5}))
+	Y.Apply(func(i, j int, v float64) float64 { return v + 3 }, Y)
+	regr := NewRidge()
+	regr.Alpha = 1e-6
+	regr.Normalize = false
+	if err := regr.FitE(base.ToCSR(X), Y); err != nil {
+		t.Fatal(err)
+	}
+	if s := regr.Score(base.ToCSR(X), Y); s < .99 {
+		t.Errorf("unexpected score %g", s)
+	}
+	regr.Normalize = true
+	if err := regr.FitE(base.ToCSR(X), Y); !errors.Is(err, base.ErrInvalidParam) {
+		t.Errorf("expected ErrInvalidParam with Normalize, got %v", err)
+	}
+}
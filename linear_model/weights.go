@@ -0,0 +1,49 @@
+package linearmodel
+
+import (
+	"math"
+
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mat"
+)
+
+// weightVec returns sampleWeight as a *mat.VecDense for PreprocessData, or nil
+func weightVec(sampleWeight []float64) *mat.VecDense {
+	if sampleWeight == nil {
+		return nil
+	}
+	return mat.NewVecDense(len(sampleWeight), sampleWeight)
+}
+
+// sampleWeightAt returns the weight of sample i, or 1 if sampleWeight is nil
+func sampleWeightAt(sampleWeight []float64, i int) float64 {
+	if sampleWeight == nil {
+		return 1
+	}
+	return sampleWeight[i]
+}
+
+// normalizeWeights returns sampleWeight scaled to a mean of 1, or nil if sampleWeight is nil
+func normalizeWeights(sampleWeight []float64) []float64 {
+	if sampleWeight == nil {
+		return nil
+	}
+	w := make([]float64, len(sampleWeight))
+	floats.ScaleTo(w, float64(len(sampleWeight))/floats.Sum(sampleWeight), sampleWeight)
+	return w
+}
+
+// rescaleData returns copies of X and Y whose rows are multiplied by the square root of sampleWeight,
+// so that a least squares fit on them minimizes the weighted squared errors. X and Y are returned as is if sampleWeight is nil
+func rescaleData(X, Y *mat.Dense, sampleWeight []float64) (Xw, Yw *mat.Dense) {
+	if sampleWeight == nil {
+		return X, Y
+	}
+	Xw, Yw = mat.DenseCopyOf(X), mat.DenseCopyOf(Y)
+	for i, w := range sampleWeight {
+		sw := math.Sqrt(w)
+		floats.Scale(sw, Xw.RawRowView(i))
+		floats.Scale(sw, Yw.RawRowView(i))
+	}
+	return
+}
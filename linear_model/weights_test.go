@@ -0,0 +1,68 @@
+package linearmodel
+
+import (
+	"testing"
+
+	"github.com/pa-m/sklearn/base"
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestFitWeighted(t *testing.T) {
+	// integer sample weights must be equivalent to duplicated samples
+	rnd := rand.New(base.NewSource(7))
+	nSamples, nFeatures := 20, 3
+	X, Y, Yc := mat.NewDense(nSamples, nFeatures, nil), mat.NewDense(nSamples, 1, nil), mat.NewDense(nSamples, 1, nil)
+	sampleWeight := make([]float64, nSamples)
+	var xdup, ydup, ycdup []float64
+	for i := 0; i < nSamples; i++ {
+		y := 1.
+		for j := 0; j < nFeatures; j++ {
+			X.Set(i, j, rnd.NormFloat64())
+			y += float64(j+1) * X.At(i, j)
+		}
+		Y.Set(i, 0, y+rnd.NormFloat64())
+		if Y.At(i, 0) > 1 {
+			Yc.Set(i, 0, 1)
+		}
+		sampleWeight[i] = float64(rnd.Intn(3))
+		for k := 0; k < int(sampleWeight[i]); k++ {
+			xdup = append(xdup, X.RawRowView(i)...)
+			ydup = append(ydup, Y.At(i, 0))
+			ycdup = append(ycdup, Yc.At(i, 0))
+		}
+	}
+	Xdup, Ydup, Ycdup := mat.NewDense(len(ydup), nFeatures, xdup), mat.NewDense(len(ydup), 1, ydup), mat.NewDense(len(ydup), 1, ycdup)
+	for _, newRegressor := range []func() base.WeightedFiter{
+		func() base.WeightedFiter { return NewLinearRegression() },
+		func() base.WeightedFiter { return NewRidge() },
+		func() base.WeightedFiter { m := NewLasso(); m.Alpha = .1; return m },
+	} {
+		weighted, duplicated := newRegressor(), newRegressor()
+		weighted.FitWeighted(X, Y, sampleWeight)
+		duplicated.Fit(Xdup, Ydup)
+		if !floats.EqualApprox(weightedCoef(weighted), weightedCoef(duplicated), 1e-6) {
+			t.Errorf("%T: expected %g, got %g", weighted, weightedCoef(duplicated), weightedCoef(weighted))
+		}
+	}
+	weighted, duplicated := NewLogisticRegression(), NewLogisticRegression()
+	weighted.RandomState, duplicated.RandomState = base.NewSource(1), base.NewSource(1)
+	weighted.FitWeighted(X, Yc, sampleWeight)
+	duplicated.Fit(Xdup, Ycdup)
+	if !floats.EqualApprox(weighted.Coef.Data, duplicated.Coef.Data, 1e-4) {
+		t.Errorf("LogisticRegression: expected %g, got %g", duplicated.Coef.Data, weighted.Coef.Data)
+	}
+}
+
+func weightedCoef(m base.WeightedFiter) []float64 {
+	switch m := m.(type) {
+	case *LinearRegression:
+		return append(m.Coef.RawMatrix().Data, m.Intercept.RawMatrix().Data...)
+	case *RegularizedRegression:
+		return append(m.Coef.RawMatrix().Data, m.Intercept.RawMatrix().Data...)
+	case *ElasticNet:
+		return append(m.Coef.RawMatrix().Data, m.Intercept.RawMatrix().Data...)
+	}
+	return nil
+}
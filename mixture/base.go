@@ -0,0 +1,303 @@
+package mixture
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"golang.org/x/exp/rand"
+
+	"github.com/pa-m/sklearn/base"
+	"github.com/pa-m/sklearn/cluster"
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mat"
+)
+
+// BaseMixture holds the parameters and the fitted components shared by GaussianMixture and BayesianGaussianMixture.
+// Covariances and PrecisionsCholesky, the upper triangular Cholesky factors of the inverses of Covariances, are stored
+// according to CovarianceType:
+//
+//	"full" (default): the NFeatures x NFeatures matrix of component c in rows c*NFeatures to (c+1)*NFeatures
+//	"tied": a NFeatures x NFeatures matrix shared by all components
+//	"diag": a NComponents x NFeatures matrix of the variances of each component
+//	"spherical": a NComponents x 1 matrix of the variance of each component
+type BaseMixture struct {
+	NComponents    int
+	CovarianceType string
+	// Tol is the threshold on the change of the lower bound of the likelihood below which EM stops
+	Tol float64
+	// RegCovar is added to the diagonal of covariances
+	RegCovar float64
+	// MaxIter is the maximum number of EM iterations of an initialization, default 100
+	MaxIter int
+	// NInit is the number of initializations, keeping the one with the highest lower bound, default 1
+	NInit int
+	// InitParams initializes the responsibilities of components for samples: "kmeans" (default) by the labels of a
+	// cluster.KMeans, "random" at random, "random_from_data" with a sample drawn at random per component
+	InitParams  string
+	RandomState base.RandomState
+	// Runtime filled members
+	Weights            []float64  `param:"-"`
+	Means              *mat.Dense `param:"-"`
+	Covariances        *mat.Dense `param:"-"`
+	PrecisionsCholesky *mat.Dense `param:"-"`
+	Converged          bool       `param:"-"`
+	NIter              int        `param:"-"`
+	// LowerBound is the lower bound of the log-likelihood of the kept initialization
+	LowerBound float64 `param:"-"`
+}
+
+// mixtureModel is implemented by the mixtures fitted by fitMixture
+type mixtureModel interface {
+	mixture() *BaseMixture
+	// initialize sets the parameters from the responsibilities of components for samples
+	initialize(X, resp *mat.Dense) error
+	// mStep sets the parameters from the log of the responsibilities
+	mStep(X, logResp *mat.Dense) error
+	estimateLogProb(X *mat.Dense) *mat.Dense
+	estimateLogWeights() []float64
+	computeLowerBound(logResp *mat.Dense, logProbNorm []float64) float64
+	getParameters() interface{}
+	setParameters(interface{})
+}
+
+// IsFitted returns true when Means have been computed. see base.FittedChecker
+func (m *BaseMixture) IsFitted() bool { return m.Means != nil }
+
+// IsClassifier returns true: Predict returns the most likely component of samples
+func (m *BaseMixture) IsClassifier() bool { return true }
+
+// GetNOutputs returns output columns number for Y to pass to predict
+func (m *BaseMixture) GetNOutputs() int { return 1 }
+
+func (m *BaseMixture) checkParams() error {
+	if m.NComponents <= 0 {
+		return fmt.Errorf("%w: NComponents must be positive, got %d", base.ErrInvalidParam, m.NComponents)
+	}
+	switch m.CovarianceType {
+	case "", "full", "tied", "diag", "spherical":
+	default:
+		return fmt.Errorf("%w: CovarianceType must be full, tied, diag or spherical, got %q", base.ErrInvalidParam, m.CovarianceType)
+	}
+	switch m.InitParams {
+	case "", "kmeans", "random", "random_from_data":
+	default:
+		return fmt.Errorf("%w: InitParams must be kmeans, random or random_from_data, got %q", base.ErrInvalidParam, m.InitParams)
+	}
+	if m.Tol < 0 || m.RegCovar < 0 || m.MaxIter < 0 || m.NInit < 0 {
+		return fmt.Errorf("%w: Tol, RegCovar, MaxIter and NInit must be >= 0", base.ErrInvalidParam)
+	}
+	return nil
+}
+
+func (m *BaseMixture) covarianceType() string {
+	if m.CovarianceType == "" {
+		return "full"
+	}
+	return m.CovarianceType
+}
+
+// initResp returns the initial responsibilities of components for the samples of X
+func (m *BaseMixture) initResp(X *mat.Dense, rnd *rand.Rand) *mat.Dense {
+	NSamples, _ := X.Dims()
+	resp := mat.NewDense(NSamples, m.NComponents, nil)
+	switch m.InitParams {
+	case "random":
+		for i := 0; i < NSamples; i++ {
+			row := resp.RawRowView(i)
+			for c := range row {
+				row[c] = rnd.Float64()
+			}
+			floats.Scale(1/floats.Sum(row), row)
+		}
+	case "random_from_data":
+		for c, i := range rnd.Perm(NSamples)[:m.NComponents] {
+			resp.Set(i, c, 1)
+		}
+	default:
+		kmeans := cluster.NewKMeans(m.NComponents)
+		kmeans.NInit, kmeans.RandomState = 1, base.NewSource(rnd.Uint64())
+		kmeans.Fit(X, nil)
+		for i, c := range kmeans.Labels {
+			resp.Set(i, c, 1)
+		}
+	}
+	return resp
+}
+
+// fitMixture runs EM from NInit initializations and keeps the parameters with the highest lower bound
+func fitMixture(mm mixtureModel, X *mat.Dense, mon *base.Monitor) error {
+	m := mm.mixture()
+	NSamples, _ := X.Dims()
+	if NSamples < m.NComponents {
+		return fmt.Errorf("%w: NSamples<NComponents %d<%d", base.ErrInvalidParam, NSamples, m.NComponents)
+	}
+	nInit, maxIter := m.NInit, m.MaxIter
+	if nInit == 0 {
+		nInit = 1
+	}
+	if maxIter == 0 {
+		maxIter = 100
+	}
+	src := m.RandomState
+	if src == nil {
+		src = base.NewLockedSource(uint64(time.Now().UnixNano()))
+	}
+	rnd := rand.New(src)
+	var best interface{}
+	bestLowerBound, bestNIter, bestConverged := math.Inf(-1), 0, false
+	epoch := 0
+	for init := 0; init < nInit; init++ {
+		if err := mm.initialize(X, m.initResp(X, rnd)); err != nil {
+			return err
+		}
+		lowerBound, converged, iter := math.Inf(-1), false, 0
+		for iter < maxIter {
+			iter++
+			epoch++
+			logProbNorm, logResp := eStep(mm, X)
+			if err := mm.mStep(X, logResp); err != nil {
+				return err
+			}
+			previous := lowerBound
+			lowerBound = mm.computeLowerBound(logResp, logProbNorm)
+			if err := mon.Report(epoch, -lowerBound); err != nil {
+				return err
+			}
+			if math.Abs(lowerBound-previous) < m.Tol {
+				converged = true
+				break
+			}
+		}
+		if best == nil || lowerBound > bestLowerBound {
+			best, bestLowerBound, bestNIter, bestConverged = mm.getParameters(), lowerBound, iter, converged
+		}
+	}
+	mm.setParameters(best)
+	m.LowerBound, m.NIter, m.Converged = bestLowerBound, bestNIter, bestConverged
+	return nil
+}
+
+// estimateWeightedLogProb returns the log of the weighted probability of each component for each sample
+func estimateWeightedLogProb(mm mixtureModel, X *mat.Dense) *mat.Dense {
+	logProb := mm.estimateLogProb(X)
+	logWeights := mm.estimateLogWeights()
+	NSamples, _ := logProb.Dims()
+	for i := 0; i < NSamples; i++ {
+		floats.Add(logProb.RawRowView(i), logWeights)
+	}
+	return logProb
+}
+
+// eStep returns the log-likelihood of each sample and the log of the responsibilities of components for samples
+func eStep(mm mixtureModel, X *mat.Dense) (logProbNorm []float64, logResp *mat.Dense) {
+	logResp = estimateWeightedLogProb(mm, X)
+	NSamples, _ := logResp.Dims()
+	logProbNorm = make([]float64, NSamples)
+	for i := range logProbNorm {
+		row := logResp.RawRowView(i)
+		logProbNorm[i] = floats.LogSumExp(row)
+		floats.AddConst(-logProbNorm[i], row)
+	}
+	return
+}
+
+// predictMixture returns the most likely component of each sample
+func predictMixture(mm mixtureModel, X mat.Matrix, Ymutable mat.Mutable) *mat.Dense {
+	base.MustBeFitted(mm.(base.Predicter))
+	logProb := estimateWeightedLogProb(mm, base.ToDense(X))
+	NSamples, _ := logProb.Dims()
+	Y := mat.NewDense(NSamples, 1, nil)
+	for i := 0; i < NSamples; i++ {
+		Y.Set(i, 0, float64(floats.MaxIdx(logProb.RawRowView(i))))
+	}
+	return base.FromDense(Ymutable, Y)
+}
+
+// predictProbaMixture returns the responsibilities of components for samples
+func predictProbaMixture(mm mixtureModel, X mat.Matrix, Ymutable mat.Mutable) *mat.Dense {
+	base.MustBeFitted(mm.(base.Predicter))
+	_, logResp := eStep(mm, base.ToDense(X))
+	for i, v := range logResp.RawMatrix().Data {
+		logResp.RawMatrix().Data[i] = math.Exp(v)
+	}
+	return base.FromDense(Ymutable, logResp)
+}
+
+// scoreSamplesMixture returns the log-likelihood of each sample
+func scoreSamplesMixture(mm mixtureModel, X mat.Matrix, Ymutable mat.Mutable) *mat.Dense {
+	base.MustBeFitted(mm.(base.Predicter))
+	logProbNorm, _ := eStep(mm, base.ToDense(X))
+	return base.FromDense(Ymutable, mat.NewDense(len(logProbNorm), 1, logProbNorm))
+}
+
+// predictEMixture is PredictE for mixtures
+func predictEMixture(mm mixtureModel, X mat.Matrix, Y mat.Mutable) (*mat.Dense, error) {
+	m := mm.mixture()
+	if !m.IsFitted() {
+		return nil, base.ErrNotFitted
+	}
+	if err := base.CheckNFeatures(X, m.Means.RawMatrix().Cols); err != nil {
+		return nil, err
+	}
+	return base.PredictE(mm.(base.Predicter), X, Y)
+}
+
+// Sample draws NSamples samples from the fitted mixture. Y holds the component of each sample, samples being ordered by component
+func (m *BaseMixture) Sample(NSamples int) (X, Y *mat.Dense) {
+	base.MustBeFitted(m)
+	src := m.RandomState
+	if src == nil {
+		src = base.NewLockedSource(uint64(time.Now().UnixNano()))
+	}
+	rnd := rand.New(src)
+	_, NFeatures := m.Means.Dims()
+	cum := make([]float64, m.NComponents)
+	floats.CumSum(cum, m.Weights)
+	counts := make([]int, m.NComponents)
+	for i := 0; i < NSamples; i++ {
+		u := rnd.Float64() * cum[m.NComponents-1]
+		c := 0
+		for c < m.NComponents-1 && cum[c] <= u {
+			c++
+		}
+		counts[c]++
+	}
+	X, Y = mat.NewDense(NSamples, NFeatures, nil), mat.NewDense(NSamples, 1, nil)
+	z := mat.NewVecDense(NFeatures, nil)
+	i := 0
+	for c, count := range counts {
+		var L mat.TriDense
+		if ct := m.covarianceType(); ct == "full" || ct == "tied" {
+			var chol mat.Cholesky
+			chol.Factorize(covarianceBlock(m.Covariances, ct, c, NFeatures))
+			chol.LTo(&L)
+		}
+		for ; count > 0; count-- {
+			for j := 0; j < NFeatures; j++ {
+				z.SetVec(j, rnd.NormFloat64())
+			}
+			x := X.RowView(i).(*mat.VecDense)
+			switch m.covarianceType() {
+			case "full", "tied":
+				x.MulVec(&L, z)
+			case "diag":
+				x.MulElemVec(z, mat.NewVecDense(NFeatures, sqrts(m.Covariances.RawRowView(c))))
+			default:
+				x.ScaleVec(math.Sqrt(m.Covariances.At(c, 0)), z)
+			}
+			x.AddVec(x, m.Means.RowView(c))
+			Y.Set(i, 0, float64(c))
+			i++
+		}
+	}
+	return
+}
+
+func sqrts(a []float64) []float64 {
+	s := make([]float64, len(a))
+	for i, v := range a {
+		s[i] = math.Sqrt(v)
+	}
+	return s
+}
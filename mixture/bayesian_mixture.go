@@ -0,0 +1,437 @@
+package mixture
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/pa-m/sklearn/base"
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/mathext"
+)
+
+// BayesianGaussianMixture is a mixture of gaussian distributions fitted by variational inference.
+// with a dirichlet process prior on the weights, the weights of unneeded components go to zero, so NComponents is an
+// upper bound on the number of components
+type BayesianGaussianMixture struct {
+	BaseMixture
+	// WeightConcentrationPriorType is "dirichlet_process" (default, stick-breaking) or "dirichlet_distribution"
+	WeightConcentrationPriorType string
+	// WeightConcentrationPrior is the concentration of the prior on the weights, default 1/NComponents. lower values
+	// put the mass on fewer components
+	WeightConcentrationPrior float64
+	// MeanPrecisionPrior is the precision of the prior on the means, default 1
+	MeanPrecisionPrior float64
+	// MeanPrior is the prior on the means, default the mean of X
+	MeanPrior []float64
+	// DegreesOfFreedomPrior is the prior on the degrees of freedom of the Wishart distributions, default NFeatures
+	DegreesOfFreedomPrior float64
+	// CovariancePrior is the prior on the covariances, default the empirical covariance of X. it is a NFeatures x
+	// NFeatures matrix for full and tied covariances, a 1 x NFeatures matrix for diag and a 1 x 1 matrix for spherical
+	CovariancePrior *mat.Dense
+	// Runtime filled members
+	// WeightConcentration holds the parameters of the Beta distributions of the dirichlet process in 2 rows, or the
+	// concentrations of the dirichlet distribution in a row
+	WeightConcentration *mat.Dense `param:"-"`
+	MeanPrecision       []float64  `param:"-"`
+	DegreesOfFreedom    []float64  `param:"-"`
+
+	meanPrior       []float64
+	covariancePrior *mat.Dense
+}
+
+// NewBayesianGaussianMixture returns a BayesianGaussianMixture of at most nComponents with full covariances and a
+// dirichlet process prior, initialized by KMeans
+func NewBayesianGaussianMixture(nComponents int) *BayesianGaussianMixture {
+	return &BayesianGaussianMixture{BaseMixture: NewGaussianMixture(nComponents).BaseMixture, WeightConcentrationPriorType: "dirichlet_process"}
+}
+
+// PredicterClone for BayesianGaussianMixture
+func (m *BayesianGaussianMixture) PredicterClone() base.Predicter {
+	clone := *m
+	clone.Weights, clone.Means, clone.Covariances, clone.PrecisionsCholesky = nil, nil, nil, nil
+	clone.WeightConcentration, clone.MeanPrecision, clone.DegreesOfFreedom = nil, nil, nil
+	return base.DeepCopy(&clone).(*BayesianGaussianMixture)
+}
+
+func (m *BayesianGaussianMixture) checkParams() error {
+	if err := m.BaseMixture.checkParams(); err != nil {
+		return err
+	}
+	switch m.WeightConcentrationPriorType {
+	case "", "dirichlet_process", "dirichlet_distribution":
+	default:
+		return fmt.Errorf("%w: WeightConcentrationPriorType must be dirichlet_process or dirichlet_distribution, got %q", base.ErrInvalidParam, m.WeightConcentrationPriorType)
+	}
+	if m.WeightConcentrationPrior < 0 || m.MeanPrecisionPrior < 0 || m.DegreesOfFreedomPrior < 0 {
+		return fmt.Errorf("%w: WeightConcentrationPrior, MeanPrecisionPrior and DegreesOfFreedomPrior must be >= 0", base.ErrInvalidParam)
+	}
+	return nil
+}
+
+// checkPriors returns an error if the priors do not match the features of X
+func (m *BayesianGaussianMixture) checkPriors(X mat.Matrix) error {
+	_, NFeatures := X.Dims()
+	if m.MeanPrior != nil && len(m.MeanPrior) != NFeatures {
+		return fmt.Errorf("%w: MeanPrior has %d features, expected %d", base.ErrShapeMismatch, len(m.MeanPrior), NFeatures)
+	}
+	if m.DegreesOfFreedomPrior != 0 && m.DegreesOfFreedomPrior <= float64(NFeatures-1) {
+		return fmt.Errorf("%w: DegreesOfFreedomPrior must be > NFeatures-1 %d, got %g", base.ErrInvalidParam, NFeatures-1, m.DegreesOfFreedomPrior)
+	}
+	if m.CovariancePrior != nil {
+		r, c := m.CovariancePrior.Dims()
+		er, ec := NFeatures, NFeatures
+		switch m.covarianceType() {
+		case "diag":
+			er = 1
+		case "spherical":
+			er, ec = 1, 1
+		}
+		if r != er || c != ec {
+			return fmt.Errorf("%w: CovariancePrior is %dx%d, expected %dx%d", base.ErrShapeMismatch, r, c, er, ec)
+		}
+	}
+	return nil
+}
+
+// Fit estimates the parameters of the mixture. Y is unused, pass nil
+func (m *BayesianGaussianMixture) Fit(Xmatrix, Ymatrix mat.Matrix) base.Fiter {
+	if err := m.checkParams(); err != nil {
+		panic(err)
+	}
+	if err := m.checkPriors(Xmatrix); err != nil {
+		panic(err)
+	}
+	if err := fitMixture(m, base.ToDense(Xmatrix), nil); err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// FitE is Fit returning an error instead of panicking
+func (m *BayesianGaussianMixture) FitE(X, Y mat.Matrix) error {
+	return m.FitContext(context.Background(), X, Y)
+}
+
+// FitContext is FitE stopping when ctx is done. progress is reported to the base.ProgressFunc of ctx after each
+// iteration of each initialization, with the opposite of the lower bound of the log-likelihood as loss
+func (m *BayesianGaussianMixture) FitContext(ctx context.Context, X, Y mat.Matrix) (err error) {
+	if err = m.checkParams(); err != nil {
+		return
+	}
+	if err = base.CheckXY(X, Y); err != nil {
+		return
+	}
+	if err = m.checkPriors(X); err != nil {
+		return
+	}
+	defer base.Recover(&err)
+	return fitMixture(m, base.ToDense(X), base.NewMonitor(ctx))
+}
+
+// Predict returns the most likely component of samples
+func (m *BayesianGaussianMixture) Predict(X mat.Matrix, Ymutable mat.Mutable) *mat.Dense {
+	return predictMixture(m, X, Ymutable)
+}
+
+// PredictE is Predict returning an error instead of panicking
+func (m *BayesianGaussianMixture) PredictE(X mat.Matrix, Y mat.Mutable) (*mat.Dense, error) {
+	return predictEMixture(m, X, Y)
+}
+
+// PredictProba returns the posterior probability of each component for samples. see base.ProbaPredicter
+func (m *BayesianGaussianMixture) PredictProba(X mat.Matrix, Ymutable mat.Mutable) *mat.Dense {
+	return predictProbaMixture(m, X, Ymutable)
+}
+
+// ScoreSamples returns the log-likelihood of each sample
+func (m *BayesianGaussianMixture) ScoreSamples(X mat.Matrix, Ymutable mat.Mutable) *mat.Dense {
+	return scoreSamplesMixture(m, X, Ymutable)
+}
+
+// Score returns the mean log-likelihood of samples. Y is unused
+func (m *BayesianGaussianMixture) Score(X, Y mat.Matrix) float64 {
+	return average(m.ScoreSamples(X, nil).RawMatrix().Data)
+}
+
+func (m *BayesianGaussianMixture) mixture() *BaseMixture { return &m.BaseMixture }
+
+func (m *BayesianGaussianMixture) isDirichletProcess() bool {
+	return m.WeightConcentrationPriorType != "dirichlet_distribution"
+}
+
+// initialize sets the default priors from X and the parameters from the responsibilities resp
+func (m *BayesianGaussianMixture) initialize(X, resp *mat.Dense) error {
+	NSamples, NFeatures := X.Dims()
+	m.meanPrior = m.MeanPrior
+	if m.meanPrior == nil {
+		m.meanPrior = make([]float64, NFeatures)
+		for j := range m.meanPrior {
+			m.meanPrior[j] = mat.Sum(X.ColView(j)) / float64(NSamples)
+		}
+	}
+	m.covariancePrior = m.CovariancePrior
+	if m.covariancePrior == nil {
+		centered := mat.NewDense(NSamples, NFeatures, nil)
+		for i := 0; i < NSamples; i++ {
+			floats.SubTo(centered.RawRowView(i), X.RawRowView(i), m.meanPrior)
+		}
+		cov := &mat.Dense{}
+		cov.Mul(centered.T(), centered)
+		cov.Scale(1/float64(NSamples-1), cov)
+		switch m.covarianceType() {
+		case "diag", "spherical":
+			variances := make([]float64, NFeatures)
+			for j := range variances {
+				variances[j] = cov.At(j, j)
+			}
+			if m.covarianceType() == "diag" {
+				cov = mat.NewDense(1, NFeatures, variances)
+			} else {
+				cov = mat.NewDense(1, 1, []float64{average(variances)})
+			}
+		}
+		m.covariancePrior = cov
+	}
+	return m.setParametersFrom(X, resp)
+}
+
+func (m *BayesianGaussianMixture) mStep(X, logResp *mat.Dense) error {
+	resp := &mat.Dense{}
+	resp.Apply(func(i, j int, v float64) float64 { return math.Exp(v) }, logResp)
+	return m.setParametersFrom(X, resp)
+}
+
+func (m *BayesianGaussianMixture) weightConcentrationPrior() float64 {
+	if m.WeightConcentrationPrior == 0 {
+		return 1 / float64(m.NComponents)
+	}
+	return m.WeightConcentrationPrior
+}
+
+func (m *BayesianGaussianMixture) meanPrecisionPrior() float64 {
+	if m.MeanPrecisionPrior == 0 {
+		return 1
+	}
+	return m.MeanPrecisionPrior
+}
+
+func (m *BayesianGaussianMixture) degreesOfFreedomPrior(NFeatures int) float64 {
+	if m.DegreesOfFreedomPrior == 0 {
+		return float64(NFeatures)
+	}
+	return m.DegreesOfFreedomPrior
+}
+
+// setParametersFrom sets the variational parameters of weights, means and precisions from the responsibilities resp
+func (m *BayesianGaussianMixture) setParametersFrom(X, resp *mat.Dense) error {
+	_, NFeatures := X.Dims()
+	k := m.NComponents
+	nk, xk, sk := estimateGaussianParameters(X, resp, m.RegCovar, m.covarianceType())
+
+	// weights
+	if m.isDirichletProcess() {
+		m.WeightConcentration = mat.NewDense(2, k, nil)
+		tail := 0.
+		for c := k - 1; c >= 0; c-- {
+			m.WeightConcentration.Set(0, c, 1+nk[c])
+			m.WeightConcentration.Set(1, c, m.weightConcentrationPrior()+tail)
+			tail += nk[c]
+		}
+	} else {
+		m.WeightConcentration = mat.NewDense(1, k, nil)
+		addConstTo(m.WeightConcentration.RawRowView(0), m.weightConcentrationPrior(), nk)
+	}
+
+	// means
+	meanPrecisionPrior := m.meanPrecisionPrior()
+	m.MeanPrecision = make([]float64, k)
+	addConstTo(m.MeanPrecision, meanPrecisionPrior, nk)
+	m.Means = mat.NewDense(k, NFeatures, nil)
+	for c := 0; c < k; c++ {
+		mean := m.Means.RawRowView(c)
+		floats.AddScaledTo(mean, floats.ScaleTo(mean, meanPrecisionPrior, m.meanPrior), nk[c], xk.RawRowView(c))
+		floats.Scale(1/m.MeanPrecision[c], mean)
+	}
+
+	// precisions, as the parameters of Wishart distributions
+	dofPrior := m.degreesOfFreedomPrior(NFeatures)
+	m.DegreesOfFreedom = make([]float64, k)
+	diff := mat.NewDense(k, NFeatures, nil)
+	for c := 0; c < k; c++ {
+		floats.SubTo(diff.RawRowView(c), xk.RawRowView(c), m.meanPrior)
+	}
+	switch m.covarianceType() {
+	case "full":
+		addConstTo(m.DegreesOfFreedom, dofPrior, nk)
+		m.Covariances = mat.NewDense(k*NFeatures, NFeatures, nil)
+		for c := 0; c < k; c++ {
+			cov := m.Covariances.Slice(c*NFeatures, (c+1)*NFeatures, 0, NFeatures).(*mat.Dense)
+			cov.Scale(nk[c], sk.Slice(c*NFeatures, (c+1)*NFeatures, 0, NFeatures))
+			cov.Add(cov, m.covariancePrior)
+			d := diff.RowView(c)
+			cov.RankOne(cov, nk[c]*meanPrecisionPrior/m.MeanPrecision[c], d, d)
+			cov.Scale(1/m.DegreesOfFreedom[c], cov)
+		}
+	case "tied":
+		sumNk := floats.Sum(nk)
+		for c := range m.DegreesOfFreedom {
+			m.DegreesOfFreedom[c] = dofPrior + sumNk/float64(k)
+		}
+		m.Covariances = mat.NewDense(NFeatures, NFeatures, nil)
+		m.Covariances.Scale(sumNk/float64(k), sk)
+		m.Covariances.Add(m.Covariances, m.covariancePrior)
+		for c := 0; c < k; c++ {
+			d := diff.RowView(c)
+			m.Covariances.RankOne(m.Covariances, meanPrecisionPrior/float64(k)*nk[c]/m.MeanPrecision[c], d, d)
+		}
+		m.Covariances.Scale(1/m.DegreesOfFreedom[0], m.Covariances)
+	case "diag":
+		addConstTo(m.DegreesOfFreedom, dofPrior, nk)
+		m.Covariances = mat.NewDense(k, NFeatures, nil)
+		for c := 0; c < k; c++ {
+			row, prior := m.Covariances.RawRowView(c), m.covariancePrior.RawRowView(0)
+			for j, dj := range diff.RawRowView(c) {
+				row[j] = (prior[j] + nk[c]*(sk.At(c, j)+meanPrecisionPrior/m.MeanPrecision[c]*dj*dj)) / m.DegreesOfFreedom[c]
+			}
+		}
+	default:
+		addConstTo(m.DegreesOfFreedom, dofPrior, nk)
+		m.Covariances = mat.NewDense(k, 1, nil)
+		for c := 0; c < k; c++ {
+			d := diff.RawRowView(c)
+			meanSq := floats.Dot(d, d) / float64(NFeatures)
+			m.Covariances.Set(c, 0, (m.covariancePrior.At(0, 0)+nk[c]*(sk.At(c, 0)+meanPrecisionPrior/m.MeanPrecision[c]*meanSq))/m.DegreesOfFreedom[c])
+		}
+	}
+	var err error
+	m.PrecisionsCholesky, err = computePrecisionCholesky(m.Covariances, m.covarianceType(), k)
+	return err
+}
+
+func (m *BayesianGaussianMixture) estimateLogWeights() []float64 {
+	k := m.NComponents
+	logWeights := make([]float64, k)
+	if m.isDirichletProcess() {
+		cum := 0.
+		for c := 0; c < k; c++ {
+			a, b := m.WeightConcentration.At(0, c), m.WeightConcentration.At(1, c)
+			digammaSum := mathext.Digamma(a + b)
+			logWeights[c] = mathext.Digamma(a) - digammaSum + cum
+			cum += mathext.Digamma(b) - digammaSum
+		}
+		return logWeights
+	}
+	concentration := m.WeightConcentration.RawRowView(0)
+	digammaSum := mathext.Digamma(floats.Sum(concentration))
+	for c, a := range concentration {
+		logWeights[c] = mathext.Digamma(a) - digammaSum
+	}
+	return logWeights
+}
+
+func (m *BayesianGaussianMixture) estimateLogProb(X *mat.Dense) *mat.Dense {
+	_, NFeatures := X.Dims()
+	d := float64(NFeatures)
+	logProb := estimateLogGaussianProb(X, m.Means, m.PrecisionsCholesky, m.covarianceType())
+	offsets := make([]float64, m.NComponents)
+	for c, dof := range m.DegreesOfFreedom {
+		logLambda := d * math.Ln2
+		for i := 0; i < NFeatures; i++ {
+			logLambda += mathext.Digamma(.5 * (dof - float64(i)))
+		}
+		offsets[c] = -.5*d*math.Log(dof) + .5*(logLambda-d/m.MeanPrecision[c])
+	}
+	r, _ := logProb.Dims()
+	for i := 0; i < r; i++ {
+		floats.Add(logProb.RawRowView(i), offsets)
+	}
+	return logProb
+}
+
+// logWishartNorm returns the log of the normalization of a Wishart distribution
+func logWishartNorm(dof, logDetPrecisionCholesky float64, NFeatures int) float64 {
+	norm := dof*logDetPrecisionCholesky + dof*float64(NFeatures)*.5*math.Ln2
+	for i := 0; i < NFeatures; i++ {
+		lg, _ := math.Lgamma(.5 * (dof - float64(i)))
+		norm += lg
+	}
+	return -norm
+}
+
+// computeLowerBound returns the lower bound of the likelihood of the variational model, up to a constant
+func (m *BayesianGaussianMixture) computeLowerBound(logResp *mat.Dense, logProbNorm []float64) float64 {
+	k := m.NComponents
+	_, NFeatures := m.Means.Dims()
+	d := float64(NFeatures)
+	logDet := computeLogDetCholesky(m.PrecisionsCholesky, m.covarianceType(), k, NFeatures)
+	logWishart := 0.
+	if m.covarianceType() == "tied" {
+		logWishart = float64(k) * logWishartNorm(m.DegreesOfFreedom[0], logDet[0]-.5*d*math.Log(m.DegreesOfFreedom[0]), NFeatures)
+	} else {
+		for c, dof := range m.DegreesOfFreedom {
+			logWishart += logWishartNorm(dof, logDet[c]-.5*d*math.Log(dof), NFeatures)
+		}
+	}
+	logNormWeight := 0.
+	if m.isDirichletProcess() {
+		for c := 0; c < k; c++ {
+			logNormWeight -= mathext.Lbeta(m.WeightConcentration.At(0, c), m.WeightConcentration.At(1, c))
+		}
+	} else {
+		concentration := m.WeightConcentration.RawRowView(0)
+		logNormWeight, _ = math.Lgamma(floats.Sum(concentration))
+		for _, a := range concentration {
+			lg, _ := math.Lgamma(a)
+			logNormWeight -= lg
+		}
+	}
+	entropy := 0.
+	for _, v := range logResp.RawMatrix().Data {
+		entropy -= math.Exp(v) * v
+	}
+	logMeanPrecision := 0.
+	for _, v := range m.MeanPrecision {
+		logMeanPrecision += math.Log(v)
+	}
+	return entropy - logWishart - logNormWeight - .5*d*logMeanPrecision
+}
+
+type bayesianParameters struct {
+	gaussianParameters
+	weightConcentration             *mat.Dense
+	meanPrecision, degreesOfFreedom []float64
+}
+
+func (m *BayesianGaussianMixture) getParameters() interface{} {
+	return bayesianParameters{gaussianParameters{nil, m.Means, m.Covariances, m.PrecisionsCholesky}, m.WeightConcentration, m.MeanPrecision, m.DegreesOfFreedom}
+}
+
+// setParameters sets the variational parameters and the expected Weights
+func (m *BayesianGaussianMixture) setParameters(p interface{}) {
+	bp := p.(bayesianParameters)
+	m.Means, m.Covariances, m.PrecisionsCholesky = bp.means, bp.covariances, bp.precisionsCholesky
+	m.WeightConcentration, m.MeanPrecision, m.DegreesOfFreedom = bp.weightConcentration, bp.meanPrecision, bp.degreesOfFreedom
+	k := m.NComponents
+	m.Weights = make([]float64, k)
+	if m.isDirichletProcess() {
+		// stick-breaking: the expected fraction of the remaining stick kept by each component
+		remaining := 1.
+		for c := 0; c < k; c++ {
+			a, b := m.WeightConcentration.At(0, c), m.WeightConcentration.At(1, c)
+			m.Weights[c] = a / (a + b) * remaining
+			remaining *= b / (a + b)
+		}
+	} else {
+		copy(m.Weights, m.WeightConcentration.RawRowView(0))
+	}
+	floats.Scale(1/floats.Sum(m.Weights), m.Weights)
+}
+
+// addConstTo sets dst to c plus the elements of s
+func addConstTo(dst []float64, c float64, s []float64) {
+	for i, v := range s {
+		dst[i] = c + v
+	}
+}
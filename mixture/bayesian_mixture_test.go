@@ -0,0 +1,115 @@
+package mixture
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"testing"
+
+	"github.com/pa-m/sklearn/base"
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mat"
+)
+
+func ExampleBayesianGaussianMixture() {
+	X, _ := trueMixture("full").Sample(1000)
+	// at most 8 components, the dirichlet process prior keeps 3 of them
+	m := NewBayesianGaussianMixture(8)
+	m.WeightConcentrationPrior, m.MaxIter, m.RandomState = .01, 500, base.NewSource(7)
+	m.Fit(X, nil)
+	weights := append([]float64(nil), m.Weights...)
+	sort.Sort(sort.Reverse(sort.Float64Slice(weights)))
+	fmt.Printf("weights %.2f\n", weights)
+	// Output:
+	// weights [0.48 0.29 0.22 0.00 0.00 0.00 0.00 0.00]
+}
+
+func TestBayesianGaussianMixture(t *testing.T) {
+	for _, covarianceType := range []string{"full", "tied", "diag", "spherical"} {
+		truth := trueMixture(covarianceType)
+		X, _ := truth.Sample(1000)
+		for _, priorType := range []string{"dirichlet_process", "dirichlet_distribution"} {
+			m := NewBayesianGaussianMixture(6)
+			m.CovarianceType, m.WeightConcentrationPriorType, m.WeightConcentrationPrior = covarianceType, priorType, 1e-3
+			m.RandomState, m.MaxIter, m.Tol = base.NewSource(7), 1000, 1e-6
+			var lowerBounds []float64
+			ctx := base.WithProgress(context.Background(), func(p base.Progress) error {
+				lowerBounds = append(lowerBounds, -p.Loss)
+				return nil
+			})
+			if err := m.FitContext(ctx, X, nil); err != nil {
+				t.Fatal(err)
+			}
+			if !m.Converged {
+				t.Errorf("%s %s: expected convergence", covarianceType, priorType)
+			}
+			// variational inference increases the lower bound
+			for i := 1; i < len(lowerBounds); i++ {
+				if lowerBounds[i] < lowerBounds[i-1]-1e-6 {
+					t.Errorf("%s %s: lower bound decreased from %g to %g at iteration %d", covarianceType, priorType, lowerBounds[i-1], lowerBounds[i], i)
+					break
+				}
+			}
+			if math.Abs(floats.Sum(m.Weights)-1) > 1e-9 {
+				t.Errorf("%s %s: expected weights summing to 1, got %v", covarianceType, priorType, m.Weights)
+			}
+			// the components with a weight are the ones of truth, the others are unused
+			match := matchComponents(&truth.BaseMixture, &m.BaseMixture)
+			used := map[int]bool{}
+			for c, f := range match {
+				used[f] = true
+				if math.Abs(truth.Weights[c]-m.Weights[f]) > .03 {
+					t.Errorf("%s %s: expected weight %g, got %g", covarianceType, priorType, truth.Weights[c], m.Weights[f])
+				}
+				if dist := floats.Distance(truth.Means.RawRowView(c), m.Means.RawRowView(f), 2); dist > .3 {
+					t.Errorf("%s %s: expected mean %v, got %v", covarianceType, priorType, truth.Means.RawRowView(c), m.Means.RawRowView(f))
+				}
+			}
+			for f, w := range m.Weights {
+				if !used[f] && w > .01 {
+					t.Errorf("%s %s: expected a weight near 0 for extra component %d, got %v", covarianceType, priorType, f, m.Weights)
+				}
+			}
+			P := m.PredictProba(X, nil)
+			Y := m.Predict(X, nil)
+			for i := 0; i < 10; i++ {
+				if sum := floats.Sum(P.RawRowView(i)); math.Abs(sum-1) > 1e-9 {
+					t.Errorf("%s %s: expected probabilities summing to 1, got %g", covarianceType, priorType, sum)
+				}
+				if int(Y.At(i, 0)) != floats.MaxIdx(P.RawRowView(i)) {
+					t.Errorf("%s %s: expected the most probable component", covarianceType, priorType)
+				}
+			}
+		}
+	}
+}
+
+func TestBayesianGaussianMixture_Priors(t *testing.T) {
+	X, _ := trueMixture("full").Sample(1000)
+	// a strong prior on the means pulls them to MeanPrior
+	m := NewBayesianGaussianMixture(1)
+	m.MeanPrior, m.MeanPrecisionPrior = []float64{100, 100}, 1e6
+	m.Fit(X, nil)
+	if mean := m.Means.RawRowView(0); floats.Distance(mean, []float64{100, 100}, 2) > 1 {
+		t.Errorf("expected a mean near the prior, got %v", mean)
+	}
+	for _, m := range []*BayesianGaussianMixture{
+		{BaseMixture: BaseMixture{NComponents: 2}, WeightConcentrationPriorType: "dirichlet"},
+		{BaseMixture: BaseMixture{NComponents: 2}, MeanPrecisionPrior: -1},
+		{BaseMixture: BaseMixture{NComponents: 2}, DegreesOfFreedomPrior: .5},
+	} {
+		if err := m.FitE(X, nil); !errors.Is(err, base.ErrInvalidParam) {
+			t.Errorf("%+v: expected ErrInvalidParam, got %v", m, err)
+		}
+	}
+	for _, m := range []*BayesianGaussianMixture{
+		{BaseMixture: BaseMixture{NComponents: 2}, MeanPrior: []float64{0}},
+		{BaseMixture: BaseMixture{NComponents: 2, CovarianceType: "diag"}, CovariancePrior: mat.NewDense(2, 2, nil)},
+	} {
+		if err := m.FitE(X, nil); !errors.Is(err, base.ErrShapeMismatch) {
+			t.Errorf("%+v: expected ErrShapeMismatch, got %v", m, err)
+		}
+	}
+}
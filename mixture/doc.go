@@ -0,0 +1,2 @@
+// Package mixture contains GaussianMixture and BayesianGaussianMixture density models fitted by expectation-maximization
+package mixture
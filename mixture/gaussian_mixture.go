@@ -0,0 +1,359 @@
+package mixture
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/pa-m/sklearn/base"
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mat"
+)
+
+// GaussianMixture is a mixture of NComponents gaussian distributions fitted by expectation-maximization
+type GaussianMixture struct {
+	BaseMixture
+}
+
+// NewGaussianMixture returns a GaussianMixture of nComponents with full covariances, initialized by KMeans
+func NewGaussianMixture(nComponents int) *GaussianMixture {
+	return &GaussianMixture{BaseMixture: BaseMixture{
+		NComponents:    nComponents,
+		CovarianceType: "full",
+		Tol:            1e-3,
+		RegCovar:       1e-6,
+		MaxIter:        100,
+		NInit:          1,
+		InitParams:     "kmeans",
+	}}
+}
+
+// PredicterClone for GaussianMixture
+func (m *GaussianMixture) PredicterClone() base.Predicter {
+	clone := *m
+	clone.Weights, clone.Means, clone.Covariances, clone.PrecisionsCholesky = nil, nil, nil, nil
+	return base.DeepCopy(&clone).(*GaussianMixture)
+}
+
+// Fit estimates the parameters of the mixture. Y is unused, pass nil
+func (m *GaussianMixture) Fit(Xmatrix, Ymatrix mat.Matrix) base.Fiter {
+	if err := m.checkParams(); err != nil {
+		panic(err)
+	}
+	if err := fitMixture(m, base.ToDense(Xmatrix), nil); err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// FitE is Fit returning an error instead of panicking
+func (m *GaussianMixture) FitE(X, Y mat.Matrix) error {
+	return m.FitContext(context.Background(), X, Y)
+}
+
+// FitContext is FitE stopping when ctx is done. progress is reported to the base.ProgressFunc of ctx after each EM
+// iteration of each initialization, with the opposite of the lower bound of the log-likelihood as loss
+func (m *GaussianMixture) FitContext(ctx context.Context, X, Y mat.Matrix) (err error) {
+	if err = m.checkParams(); err != nil {
+		return
+	}
+	if err = base.CheckXY(X, Y); err != nil {
+		return
+	}
+	defer base.Recover(&err)
+	return fitMixture(m, base.ToDense(X), base.NewMonitor(ctx))
+}
+
+// Predict returns the most likely component of samples
+func (m *GaussianMixture) Predict(X mat.Matrix, Ymutable mat.Mutable) *mat.Dense {
+	return predictMixture(m, X, Ymutable)
+}
+
+// PredictE is Predict returning an error instead of panicking
+func (m *GaussianMixture) PredictE(X mat.Matrix, Y mat.Mutable) (*mat.Dense, error) {
+	return predictEMixture(m, X, Y)
+}
+
+// PredictProba returns the posterior probability of each component for samples. see base.ProbaPredicter
+func (m *GaussianMixture) PredictProba(X mat.Matrix, Ymutable mat.Mutable) *mat.Dense {
+	return predictProbaMixture(m, X, Ymutable)
+}
+
+// ScoreSamples returns the log-likelihood of each sample
+func (m *GaussianMixture) ScoreSamples(X mat.Matrix, Ymutable mat.Mutable) *mat.Dense {
+	return scoreSamplesMixture(m, X, Ymutable)
+}
+
+// Score returns the mean log-likelihood of samples. Y is unused
+func (m *GaussianMixture) Score(X, Y mat.Matrix) float64 {
+	return average(m.ScoreSamples(X, nil).RawMatrix().Data)
+}
+
+// BIC returns the bayesian information criterion of the model for X. the lower the better
+func (m *GaussianMixture) BIC(X mat.Matrix) float64 {
+	NSamples, _ := X.Dims()
+	return -2*m.Score(X, nil)*float64(NSamples) + float64(m.nParameters())*math.Log(float64(NSamples))
+}
+
+// AIC returns the Akaike information criterion of the model for X. the lower the better
+func (m *GaussianMixture) AIC(X mat.Matrix) float64 {
+	NSamples, _ := X.Dims()
+	return -2*m.Score(X, nil)*float64(NSamples) + 2*float64(m.nParameters())
+}
+
+// nParameters returns the number of free parameters of the model
+func (m *GaussianMixture) nParameters() int {
+	base.MustBeFitted(m)
+	k := m.NComponents
+	_, d := m.Means.Dims()
+	var covParams int
+	switch m.covarianceType() {
+	case "full":
+		covParams = k * d * (d + 1) / 2
+	case "tied":
+		covParams = d * (d + 1) / 2
+	case "diag":
+		covParams = k * d
+	default:
+		covParams = k
+	}
+	return covParams + k*d + k - 1
+}
+
+func (m *GaussianMixture) mixture() *BaseMixture { return &m.BaseMixture }
+
+func (m *GaussianMixture) initialize(X, resp *mat.Dense) error {
+	return m.setGaussianParameters(X, resp)
+}
+
+func (m *GaussianMixture) mStep(X, logResp *mat.Dense) error {
+	resp := &mat.Dense{}
+	resp.Apply(func(i, j int, v float64) float64 { return math.Exp(v) }, logResp)
+	return m.setGaussianParameters(X, resp)
+}
+
+// setGaussianParameters sets Weights, Means, Covariances and PrecisionsCholesky from the responsibilities of components
+func (m *GaussianMixture) setGaussianParameters(X, resp *mat.Dense) (err error) {
+	NSamples, _ := X.Dims()
+	var nk []float64
+	nk, m.Means, m.Covariances = estimateGaussianParameters(X, resp, m.RegCovar, m.covarianceType())
+	m.Weights = nk
+	floats.Scale(1/float64(NSamples), m.Weights)
+	m.PrecisionsCholesky, err = computePrecisionCholesky(m.Covariances, m.covarianceType(), m.NComponents)
+	return
+}
+
+func (m *GaussianMixture) estimateLogProb(X *mat.Dense) *mat.Dense {
+	return estimateLogGaussianProb(X, m.Means, m.PrecisionsCholesky, m.covarianceType())
+}
+
+func (m *GaussianMixture) estimateLogWeights() []float64 {
+	logWeights := make([]float64, len(m.Weights))
+	for c, w := range m.Weights {
+		logWeights[c] = math.Log(w)
+	}
+	return logWeights
+}
+
+func (m *GaussianMixture) computeLowerBound(logResp *mat.Dense, logProbNorm []float64) float64 {
+	return average(logProbNorm)
+}
+
+type gaussianParameters struct {
+	weights                                []float64
+	means, covariances, precisionsCholesky *mat.Dense
+}
+
+func (m *GaussianMixture) getParameters() interface{} {
+	return gaussianParameters{m.Weights, m.Means, m.Covariances, m.PrecisionsCholesky}
+}
+
+func (m *GaussianMixture) setParameters(p interface{}) {
+	gp := p.(gaussianParameters)
+	m.Weights, m.Means, m.Covariances, m.PrecisionsCholesky = gp.weights, gp.means, gp.covariances, gp.precisionsCholesky
+}
+
+// average returns the mean of a
+func average(a []float64) float64 { return floats.Sum(a) / float64(len(a)) }
+
+// estimateGaussianParameters returns the weighted number of samples, the means and the covariances of each component
+// given the responsibilities resp of components for the samples of X
+func estimateGaussianParameters(X, resp *mat.Dense, regCovar float64, covarianceType string) (nk []float64, means, covariances *mat.Dense) {
+	NSamples, NFeatures := X.Dims()
+	_, NComponents := resp.Dims()
+	nk = make([]float64, NComponents)
+	for c := range nk {
+		nk[c] = mat.Sum(resp.ColView(c)) + 10*eps
+	}
+	means = &mat.Dense{}
+	means.Mul(resp.T(), X)
+	for c := range nk {
+		floats.Scale(1/nk[c], means.RawRowView(c))
+	}
+	switch covarianceType {
+	case "full":
+		covariances = mat.NewDense(NComponents*NFeatures, NFeatures, nil)
+		diff := mat.NewDense(NSamples, NFeatures, nil)
+		weighted := mat.NewDense(NSamples, NFeatures, nil)
+		for c := range nk {
+			for i := 0; i < NSamples; i++ {
+				floats.SubTo(diff.RawRowView(i), X.RawRowView(i), means.RawRowView(c))
+				floats.ScaleTo(weighted.RawRowView(i), resp.At(i, c)/nk[c], diff.RawRowView(i))
+			}
+			cov := covariances.Slice(c*NFeatures, (c+1)*NFeatures, 0, NFeatures).(*mat.Dense)
+			cov.Mul(weighted.T(), diff)
+			addDiagonal(cov, regCovar)
+		}
+	case "tied":
+		// samples are weighted by the sum of their responsibilities, which is less than 1 for random_from_data
+		weighted := mat.NewDense(NSamples, NFeatures, nil)
+		for i := 0; i < NSamples; i++ {
+			floats.ScaleTo(weighted.RawRowView(i), floats.Sum(resp.RawRowView(i)), X.RawRowView(i))
+		}
+		covariances = &mat.Dense{}
+		covariances.Mul(weighted.T(), X)
+		avgMeans2 := mat.NewDense(NFeatures, NFeatures, nil)
+		for c := range nk {
+			mean := means.RowView(c)
+			avgMeans2.RankOne(avgMeans2, -nk[c], mean, mean)
+		}
+		covariances.Add(covariances, avgMeans2)
+		covariances.Scale(1/floats.Sum(nk), covariances)
+		addDiagonal(covariances, regCovar)
+	default:
+		X2 := &mat.Dense{}
+		X2.MulElem(X, X)
+		covariances = &mat.Dense{}
+		covariances.Mul(resp.T(), X2)
+		for c := range nk {
+			row, mean := covariances.RawRowView(c), means.RawRowView(c)
+			for j := range row {
+				row[j] = row[j]/nk[c] - mean[j]*mean[j] + regCovar
+			}
+		}
+		if covarianceType == "spherical" {
+			variances := make([]float64, NComponents)
+			for c := range variances {
+				variances[c] = average(covariances.RawRowView(c))
+			}
+			covariances = mat.NewDense(NComponents, 1, variances)
+		}
+	}
+	return
+}
+
+const eps = 2.220446049250313e-16
+
+func addDiagonal(a *mat.Dense, v float64) {
+	r, _ := a.Dims()
+	for i := 0; i < r; i++ {
+		a.Set(i, i, a.At(i, i)+v)
+	}
+}
+
+// covarianceBlock returns the full or tied covariance matrix of component c
+func covarianceBlock(covariances *mat.Dense, covarianceType string, c, NFeatures int) *mat.SymDense {
+	if covarianceType == "tied" {
+		c = 0
+	}
+	block := covariances.Slice(c*NFeatures, (c+1)*NFeatures, 0, NFeatures)
+	sym := mat.NewSymDense(NFeatures, nil)
+	for i := 0; i < NFeatures; i++ {
+		for j := i; j < NFeatures; j++ {
+			sym.SetSym(i, j, block.At(i, j))
+		}
+	}
+	return sym
+}
+
+// computePrecisionCholesky returns the Cholesky factors of the precisions of covariances, laid out as covariances.
+// for full and tied covariances, the factor is the inverse of the upper triangular Cholesky factor of the covariance
+func computePrecisionCholesky(covariances *mat.Dense, covarianceType string, NComponents int) (*mat.Dense, error) {
+	errIllDefined := fmt.Errorf("%w: ill-defined empirical covariance, try to decrease the number of components or to increase RegCovar", base.ErrInvalidParam)
+	r, NFeatures := covariances.Dims()
+	precisionsCholesky := mat.NewDense(r, NFeatures, nil)
+	switch covarianceType {
+	case "full", "tied":
+		for b := 0; b < r/NFeatures; b++ {
+			var chol mat.Cholesky
+			if !chol.Factorize(covarianceBlock(covariances, covarianceType, b, NFeatures)) {
+				return nil, errIllDefined
+			}
+			var U mat.TriDense
+			chol.UTo(&U)
+			if err := U.InverseTri(&U); err != nil {
+				return nil, errIllDefined
+			}
+			precisionsCholesky.Slice(b*NFeatures, (b+1)*NFeatures, 0, NFeatures).(*mat.Dense).Copy(&U)
+		}
+	default:
+		for i, v := range covariances.RawMatrix().Data {
+			if v <= 0 {
+				return nil, errIllDefined
+			}
+			precisionsCholesky.RawMatrix().Data[i] = 1 / math.Sqrt(v)
+		}
+	}
+	return precisionsCholesky, nil
+}
+
+// computeLogDetCholesky returns the log-determinant of the Cholesky factor of the precision of each component
+func computeLogDetCholesky(precisionsCholesky *mat.Dense, covarianceType string, NComponents, NFeatures int) []float64 {
+	logDet := make([]float64, NComponents)
+	for c := range logDet {
+		switch covarianceType {
+		case "full", "tied":
+			b := c
+			if covarianceType == "tied" {
+				b = 0
+			}
+			for j := 0; j < NFeatures; j++ {
+				logDet[c] += math.Log(precisionsCholesky.At(b*NFeatures+j, j))
+			}
+		case "diag":
+			for _, v := range precisionsCholesky.RawRowView(c) {
+				logDet[c] += math.Log(v)
+			}
+		default:
+			logDet[c] = float64(NFeatures) * math.Log(precisionsCholesky.At(c, 0))
+		}
+	}
+	return logDet
+}
+
+// estimateLogGaussianProb returns the log of the density of each component at each sample
+func estimateLogGaussianProb(X, means, precisionsCholesky *mat.Dense, covarianceType string) *mat.Dense {
+	NSamples, NFeatures := X.Dims()
+	NComponents, _ := means.Dims()
+	logDet := computeLogDetCholesky(precisionsCholesky, covarianceType, NComponents, NFeatures)
+	logProb := mat.NewDense(NSamples, NComponents, nil)
+	diff := make([]float64, NFeatures)
+	y := mat.NewVecDense(NFeatures, nil)
+	for c := 0; c < NComponents; c++ {
+		mean := means.RawRowView(c)
+		var precChol mat.Matrix
+		if covarianceType == "full" {
+			precChol = precisionsCholesky.Slice(c*NFeatures, (c+1)*NFeatures, 0, NFeatures)
+		} else if covarianceType == "tied" {
+			precChol = precisionsCholesky
+		}
+		for i := 0; i < NSamples; i++ {
+			floats.SubTo(diff, X.RawRowView(i), mean)
+			var sumSq float64
+			switch covarianceType {
+			case "full", "tied":
+				y.MulVec(precChol.T(), mat.NewVecDense(NFeatures, diff))
+				sumSq = mat.Dot(y, y)
+			case "diag":
+				for j, p := range precisionsCholesky.RawRowView(c) {
+					sumSq += diff[j] * diff[j] * p * p
+				}
+			default:
+				p := precisionsCholesky.At(c, 0)
+				sumSq = floats.Dot(diff, diff) * p * p
+			}
+			logProb.Set(i, c, -.5*(float64(NFeatures)*math.Log(2*math.Pi)+sumSq)+logDet[c])
+		}
+	}
+	return logProb
+}
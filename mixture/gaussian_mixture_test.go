@@ -0,0 +1,236 @@
+package mixture
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"testing"
+
+	"github.com/pa-m/sklearn/base"
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/stat"
+)
+
+var _ = []base.PredicterE{&GaussianMixture{}, &BayesianGaussianMixture{}}
+var _ = []base.ProbaPredicter{&GaussianMixture{}, &BayesianGaussianMixture{}}
+
+// trueMixture returns a fitted GaussianMixture of 3 components in 2 dimensions with covariances of covarianceType
+func trueMixture(covarianceType string) *GaussianMixture {
+	m := NewGaussianMixture(3)
+	m.CovarianceType = covarianceType
+	m.Weights = []float64{.5, .3, .2}
+	m.Means = mat.NewDense(3, 2, []float64{0, 0, 8, 2, -4, 9})
+	switch covarianceType {
+	case "full":
+		m.Covariances = mat.NewDense(6, 2, []float64{2, .8, .8, 1, 1, -.5, -.5, 2, .5, 0, 0, 3})
+	case "tied":
+		m.Covariances = mat.NewDense(2, 2, []float64{2, .8, .8, 1})
+	case "diag":
+		m.Covariances = mat.NewDense(3, 2, []float64{2, 1, .5, 3, 1, 1})
+	default:
+		m.Covariances = mat.NewDense(3, 1, []float64{1, 2, .5})
+	}
+	m.PrecisionsCholesky, _ = computePrecisionCholesky(m.Covariances, covarianceType, 3)
+	m.RandomState = base.NewSource(7)
+	return m
+}
+
+func ExampleGaussianMixture() {
+	X, _ := trueMixture("full").Sample(1000)
+	m := NewGaussianMixture(3)
+	m.RandomState = base.NewSource(7)
+	m.Fit(X, nil)
+	for c := range m.Weights {
+		fmt.Printf("weight %.1f mean %.1f\n", m.Weights[c], mat.Formatted(m.Means.RowView(c).T()))
+	}
+	// Unordered output:
+	// weight 0.3 mean [8.0  2.2]
+	// weight 0.5 mean [ 0.0  -0.1]
+	// weight 0.2 mean [-4.0   8.9]
+}
+
+// matchComponents returns the fitted component closest to each component of truth
+func matchComponents(truth, m *BaseMixture) []int {
+	match := make([]int, truth.NComponents)
+	for c := range match {
+		for f := 0; f < m.NComponents; f++ {
+			if floats.Distance(truth.Means.RawRowView(c), m.Means.RawRowView(f), 2) < floats.Distance(truth.Means.RawRowView(c), m.Means.RawRowView(match[c]), 2) {
+				match[c] = f
+			}
+		}
+	}
+	return match
+}
+
+func TestGaussianMixture(t *testing.T) {
+	for _, covarianceType := range []string{"full", "tied", "diag", "spherical"} {
+		truth := trueMixture(covarianceType)
+		X, _ := truth.Sample(2000)
+		for _, initParams := range []string{"kmeans", "random", "random_from_data"} {
+			m := NewGaussianMixture(3)
+			// random initializations start close to the mean of X, where the lower bound first changes slowly
+			m.CovarianceType, m.InitParams, m.RandomState, m.NInit, m.Tol, m.MaxIter = covarianceType, initParams, base.NewSource(7), 1, 1e-9, 1000
+			if err := m.FitE(X, nil); err != nil {
+				t.Fatal(err)
+			}
+			if !m.Converged {
+				t.Errorf("%s %s: expected convergence", covarianceType, initParams)
+			}
+			match := matchComponents(&truth.BaseMixture, &m.BaseMixture)
+			_, d := X.Dims()
+			for c, f := range match {
+				if math.Abs(truth.Weights[c]-m.Weights[f]) > .03 {
+					t.Errorf("%s %s: expected weight %g, got %g", covarianceType, initParams, truth.Weights[c], m.Weights[f])
+				}
+				if dist := floats.Distance(truth.Means.RawRowView(c), m.Means.RawRowView(f), 2); dist > .2 {
+					t.Errorf("%s %s: expected mean %v, got %v", covarianceType, initParams, truth.Means.RawRowView(c), m.Means.RawRowView(f))
+				}
+				var expected, actual mat.Matrix
+				switch covarianceType {
+				case "full":
+					expected, actual = truth.Covariances.Slice(c*d, c*d+d, 0, d), m.Covariances.Slice(f*d, f*d+d, 0, d)
+				case "tied":
+					expected, actual = truth.Covariances, m.Covariances
+				default:
+					expected, actual = truth.Covariances.RowView(c), m.Covariances.RowView(f)
+				}
+				if !mat.EqualApprox(expected, actual, .3) {
+					t.Errorf("%s %s: expected covariance %v, got %v", covarianceType, initParams, mat.Formatted(expected), mat.Formatted(actual))
+				}
+			}
+			// the log-likelihood is the log of the sum of the weighted densities of components
+			P, S := m.PredictProba(X, nil), m.ScoreSamples(X, nil)
+			logProb := estimateLogGaussianProb(X, m.Means, m.PrecisionsCholesky, m.covarianceType())
+			for i := 0; i < 10; i++ {
+				if sum := floats.Sum(P.RawRowView(i)); math.Abs(sum-1) > 1e-9 {
+					t.Errorf("%s %s: expected probabilities summing to 1, got %g", covarianceType, initParams, sum)
+				}
+				likelihood := 0.
+				for c, w := range m.Weights {
+					likelihood += w * math.Exp(logProb.At(i, c))
+				}
+				if math.Abs(math.Log(likelihood)-S.At(i, 0)) > 1e-9 {
+					t.Errorf("%s %s: expected log-likelihood %g, got %g", covarianceType, initParams, math.Log(likelihood), S.At(i, 0))
+				}
+			}
+			if math.Abs(m.LowerBound-m.Score(X, nil)) > 1e-2 {
+				t.Errorf("%s %s: expected the mean log-likelihood %g as lower bound, got %g", covarianceType, initParams, m.Score(X, nil), m.LowerBound)
+			}
+		}
+	}
+}
+
+func TestGaussianMixture_FullCovariance(t *testing.T) {
+	// a full covariance model of a single component is the empirical mean and covariance
+	X, _ := trueMixture("full").Sample(500)
+	m := NewGaussianMixture(1)
+	m.RegCovar = 0
+	m.Fit(X, nil)
+	cov := mat.NewSymDense(2, nil)
+	stat.CovarianceMatrix(cov, X, nil)
+	cov.ScaleSym(499./500, cov)
+	if !mat.EqualApprox(cov, m.Covariances, 1e-9) {
+		t.Errorf("expected covariance %v, got %v", mat.Formatted(cov), mat.Formatted(m.Covariances))
+	}
+	precisions := &mat.Dense{}
+	precisions.Mul(m.PrecisionsCholesky, m.PrecisionsCholesky.T())
+	identity := &mat.Dense{}
+	identity.Mul(precisions, cov)
+	if !mat.EqualApprox(identity, mat.NewDiagDense(2, []float64{1, 1}), 1e-9) {
+		t.Errorf("expected the precision as inverse of covariance, got product %v", mat.Formatted(identity))
+	}
+}
+
+func TestGaussianMixture_BIC(t *testing.T) {
+	X, _ := trueMixture("full").Sample(1000)
+	var bic, aic []float64
+	for k := 1; k <= 5; k++ {
+		m := NewGaussianMixture(k)
+		m.RandomState, m.NInit = base.NewSource(7), 2
+		m.Fit(X, nil)
+		bic, aic = append(bic, m.BIC(X)), append(aic, m.AIC(X))
+	}
+	if floats.MinIdx(bic) != 2 {
+		t.Errorf("expected the lowest BIC for 3 components, got %v", bic)
+	}
+	if aic[2] >= aic[0] || aic[2] >= aic[1] {
+		t.Errorf("expected AIC to decrease up to 3 components, got %v", aic)
+	}
+}
+
+func TestGaussianMixture_Sample(t *testing.T) {
+	for _, covarianceType := range []string{"full", "tied", "diag", "spherical"} {
+		truth := trueMixture(covarianceType)
+		X, Y := truth.Sample(20000)
+		if !sort.Float64sAreSorted(Y.RawMatrix().Data) {
+			t.Errorf("%s: expected samples ordered by component", covarianceType)
+		}
+		for c := 0; c < 3; c++ {
+			var rows []float64
+			for i := 0; i < 20000; i++ {
+				if int(Y.At(i, 0)) == c {
+					rows = append(rows, X.RawRowView(i)...)
+				}
+			}
+			n := len(rows) / 2
+			Xc := mat.NewDense(n, 2, rows)
+			if w := float64(n) / 20000; math.Abs(w-truth.Weights[c]) > .02 {
+				t.Errorf("%s: expected weight %g, got %g", covarianceType, truth.Weights[c], w)
+			}
+			mean := []float64{mat.Sum(Xc.ColView(0)) / float64(n), mat.Sum(Xc.ColView(1)) / float64(n)}
+			if floats.Distance(mean, truth.Means.RawRowView(c), 2) > .1 {
+				t.Errorf("%s: expected mean %v, got %v", covarianceType, truth.Means.RawRowView(c), mean)
+			}
+			cov := mat.NewSymDense(2, nil)
+			stat.CovarianceMatrix(cov, Xc, nil)
+			if expected := covarianceBlock(truthFull(truth), "full", c, 2); !mat.EqualApprox(cov, expected, .15) {
+				t.Errorf("%s: expected covariance %v, got %v", covarianceType, mat.Formatted(expected), mat.Formatted(cov))
+			}
+		}
+	}
+}
+
+// truthFull returns the covariances of m as full covariances
+func truthFull(m *GaussianMixture) *mat.Dense {
+	full := mat.NewDense(6, 2, nil)
+	for c := 0; c < 3; c++ {
+		for j := 0; j < 2; j++ {
+			switch m.CovarianceType {
+			case "full":
+				full.SetRow(2*c+j, m.Covariances.RawRowView(2*c+j))
+			case "tied":
+				full.SetRow(2*c+j, m.Covariances.RawRowView(j))
+			case "diag":
+				full.Set(2*c+j, j, m.Covariances.At(c, j))
+			default:
+				full.Set(2*c+j, j, m.Covariances.At(c, 0))
+			}
+		}
+	}
+	return full
+}
+
+func TestGaussianMixture_Errors(t *testing.T) {
+	X, _ := trueMixture("full").Sample(100)
+	for _, m := range []*GaussianMixture{
+		{BaseMixture: BaseMixture{NComponents: 0}},
+		{BaseMixture: BaseMixture{NComponents: 2, CovarianceType: "block"}},
+		{BaseMixture: BaseMixture{NComponents: 2, InitParams: "k-means++"}},
+		{BaseMixture: BaseMixture{NComponents: 200}},
+	} {
+		if err := m.FitE(X, nil); !errors.Is(err, base.ErrInvalidParam) {
+			t.Errorf("%+v: expected ErrInvalidParam, got %v", m.BaseMixture, err)
+		}
+	}
+	// identical samples have a singular covariance
+	m := NewGaussianMixture(1)
+	m.RegCovar = 0
+	if err := m.FitE(mat.NewDense(10, 2, nil), nil); !errors.Is(err, base.ErrInvalidParam) {
+		t.Errorf("expected ErrInvalidParam for a singular covariance, got %v", err)
+	}
+	if _, err := NewGaussianMixture(2).PredictE(X, nil); !errors.Is(err, base.ErrNotFitted) {
+		t.Errorf("expected ErrNotFitted, got %v", err)
+	}
+}
@@ -0,0 +1,19 @@
+package mixture
+
+import "github.com/pa-m/sklearn/base"
+
+// GetParams returns the parameters of GaussianMixture. see base.GetFieldParams
+func (m *GaussianMixture) GetParams() map[string]interface{} { return base.GetFieldParams(m) }
+
+// SetParams sets the parameters of GaussianMixture. see base.SetFieldParams
+func (m *GaussianMixture) SetParams(params map[string]interface{}) error {
+	return base.SetFieldParams(m, params)
+}
+
+// GetParams returns the parameters of BayesianGaussianMixture. see base.GetFieldParams
+func (m *BayesianGaussianMixture) GetParams() map[string]interface{} { return base.GetFieldParams(m) }
+
+// SetParams sets the parameters of BayesianGaussianMixture. see base.SetFieldParams
+func (m *BayesianGaussianMixture) SetParams(params map[string]interface{}) error {
+	return base.SetFieldParams(m, params)
+}
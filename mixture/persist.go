@@ -0,0 +1,22 @@
+package mixture
+
+import "github.com/pa-m/sklearn/base"
+
+func init() {
+	base.Register(&GaussianMixture{})
+	base.Register(&BayesianGaussianMixture{})
+}
+
+// MarshalState allows GaussianMixture to be saved by base.Save. RandomState is not saved
+func (m *GaussianMixture) MarshalState() (*base.State, error) { return base.MarshalFields(m) }
+
+// UnmarshalState restores a GaussianMixture saved by base.Save
+func (m *GaussianMixture) UnmarshalState(st *base.State) error { return base.UnmarshalFields(m, st) }
+
+// MarshalState allows BayesianGaussianMixture to be saved by base.Save. RandomState is not saved
+func (m *BayesianGaussianMixture) MarshalState() (*base.State, error) { return base.MarshalFields(m) }
+
+// UnmarshalState restores a BayesianGaussianMixture saved by base.Save
+func (m *BayesianGaussianMixture) UnmarshalState(st *base.State) error {
+	return base.UnmarshalFields(m, st)
+}
@@ -0,0 +1,28 @@
+package mixture
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/pa-m/sklearn/base"
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestSaveLoad(t *testing.T) {
+	X, _ := trueMixture("full").Sample(300)
+	for _, m := range []base.Predicter{NewGaussianMixture(3), NewBayesianGaussianMixture(3)} {
+		m.(base.Params).SetParams(map[string]interface{}{"RandomState": base.NewSource(7)})
+		m.Fit(X, nil)
+		buf := new(bytes.Buffer)
+		if err := base.Save(buf, m.(base.Persister)); err != nil {
+			t.Fatalf("%T: %s", m, err)
+		}
+		loaded, err := base.Load(buf)
+		if err != nil {
+			t.Fatalf("%T: %s", m, err)
+		}
+		if !mat.Equal(m.(base.ProbaPredicter).PredictProba(X, nil), loaded.(base.ProbaPredicter).PredictProba(X, nil)) {
+			t.Errorf("%T: loaded model probabilities differ", m)
+		}
+	}
+}
@@ -1,2 +1,2 @@
-// Package modelselection contains KFold, GridSearchCV, CrossValidate
+// Package modelselection contains KFold, GridSearchCV, CrossValidate, CrossValPredict
 package modelselection
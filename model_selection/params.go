@@ -0,0 +1,11 @@
+package modelselection
+
+import "github.com/pa-m/sklearn/base"
+
+// GetParams returns the parameters of GridSearchCV. see base.GetFieldParams
+func (gscv *GridSearchCV) GetParams() map[string]interface{} { return base.GetFieldParams(gscv) }
+
+// SetParams sets the parameters of GridSearchCV. see base.SetFieldParams
+func (gscv *GridSearchCV) SetParams(params map[string]interface{}) error {
+	return base.SetFieldParams(gscv, params)
+}
@@ -1,9 +1,8 @@
 package modelselection
 
 import (
+	"context"
 	"fmt"
-	"reflect"
-	"strings"
 
 	"github.com/pa-m/sklearn/base"
 	"golang.org/x/exp/rand"
@@ -55,26 +54,30 @@ type GridSearchCV struct {
 	UseChannels        bool
 	RandomState        rand.Source
 
-	CVResults     map[string][]interface{}
-	BestEstimator base.Predicter
-	BestScore     float64
-	BestParams    map[string]interface{}
-	BestIndex     int
-	NOutputs      int
+	CVResults     map[string][]interface{} `param:"-"`
+	BestEstimator base.Predicter           `param:"-"`
+	BestScore     float64                  `param:"-"`
+	BestParams    map[string]interface{}   `param:"-"`
+	BestIndex     int                      `param:"-"`
+	NOutputs      int                      `param:"-"`
 }
 
-// PredicterClone ...
+// PredicterClone returns an unfitted GridSearchCV with an unfitted clone of Estimator
 func (gscv *GridSearchCV) PredicterClone() base.Predicter {
 	if gscv == nil {
 		return nil
 	}
 	clone := *gscv
-	if sourceCloner, ok := clone.RandomState.(base.SourceCloner); ok && sourceCloner != base.SourceCloner(nil) {
-		clone.RandomState = sourceCloner.Clone()
+	if gscv.Estimator != nil {
+		clone.Estimator = gscv.Estimator.PredicterClone()
 	}
-	return &clone
+	clone.CVResults, clone.BestEstimator, clone.BestScore, clone.BestParams, clone.BestIndex, clone.NOutputs = nil, nil, 0, nil, 0, 0
+	return base.DeepCopy(&clone).(*GridSearchCV)
 }
 
+// IsFitted returns true when BestEstimator has been set by Fit. see base.FittedChecker
+func (gscv *GridSearchCV) IsFitted() bool { return gscv.BestEstimator != nil }
+
 // IsClassifier returns underlaying estimater IsClassifier
 func (gscv *GridSearchCV) IsClassifier() bool {
 	if maybeClf, ok := gscv.Estimator.(base.Predicter); ok {
@@ -85,6 +88,13 @@ func (gscv *GridSearchCV) IsClassifier() bool {
 
 // Fit ...
 func (gscv *GridSearchCV) Fit(Xmatrix, Ymatrix mat.Matrix) base.Fiter {
+	if err := gscv.fit(context.Background(), Xmatrix, Ymatrix); err != nil {
+		panic(err)
+	}
+	return gscv
+}
+
+func (gscv *GridSearchCV) fit(ctx context.Context, Xmatrix, Ymatrix mat.Matrix) error {
 	X, Y := base.ToDense(Xmatrix), base.ToDense(Ymatrix)
 	gscv.NOutputs = Y.RawMatrix().Cols
 	isBetter := func(score, refscore float64) bool {
@@ -126,9 +136,14 @@ func (gscv *GridSearchCV) Fit(Xmatrix, Ymatrix mat.Matrix) base.Fiter {
 		estimator base.Predicter
 		cv        Splitter
 		score     float64
+		err       error
 	}
 	dowork := func(sin *structIn) {
-		cvres := CrossValidate(sin.estimator, X, Y, nil, gscv.Scorer, sin.cv, gscv.NJobs)
+		cvres, err := CrossValidateContext(ctx, sin.estimator, X, Y, nil, gscv.Scorer, sin.cv, gscv.NJobs)
+		if err != nil {
+			sin.err = err
+			return
+		}
 		sin.score = floats.Sum(cvres.TestScore) / float64(len(cvres.TestScore))
 		bestFold := bestIdx(cvres.TestScore)
 		sin.estimator = cvres.Estimator[bestFold]
@@ -139,19 +154,26 @@ func (gscv *GridSearchCV) Fit(Xmatrix, Ymatrix mat.Matrix) base.Fiter {
 		sin := make([]structIn, len(paramArray))
 		for i, params := range paramArray {
 			sin[i] = structIn{index: i, params: params, estimator: estCloner.PredicterClone(), cv: gscv.CV.SplitterClone()}
-			for k, v := range sin[i].params {
-				setParam(sin[i].estimator, k, v)
+			if err := base.SetParams(sin[i].estimator, params); err != nil {
+				return err
 			}
 		}
-		base.Parallelize(gscv.NJobs, len(paramArray), func(th, start, end int) {
-			for i := start; i < end; i++ {
+		if err := base.ParallelizeContext(ctx, gscv.NJobs, len(paramArray), func(th, start, end int) {
+			for i := start; i < end && ctx.Err() == nil; i++ {
 				dowork(&sin[i])
 				for k, v := range paramArray[i] {
 					gscv.CVResults[k][i] = v
 				}
 				gscv.CVResults["score"][i] = sin[i].score
 			}
-		})
+		}); err != nil {
+			return err
+		}
+		for _, sout := range sin {
+			if sout.err != nil {
+				return sout.err
+			}
+		}
 		for i, sout := range sin {
 			if gscv.BestIndex == -1 || isBetter(sout.score, gscv.CVResults["score"][gscv.BestIndex].(float64)) {
 				gscv.BestIndex = i
@@ -162,7 +184,25 @@ func (gscv *GridSearchCV) Fit(Xmatrix, Ymatrix mat.Matrix) base.Fiter {
 		}
 	}
 
-	return gscv
+	return nil
+}
+
+// FitE is Fit returning an error instead of panicking
+func (gscv *GridSearchCV) FitE(X, Y mat.Matrix) error {
+	return gscv.FitContext(context.Background(), X, Y)
+}
+
+// FitContext is FitE stopping when ctx is done. candidate estimators are fitted with base.FitContext,
+// so their progress is reported to the base.ProgressFunc of ctx
+func (gscv *GridSearchCV) FitContext(ctx context.Context, X, Y mat.Matrix) (err error) {
+	if gscv.Estimator == nil {
+		return fmt.Errorf("%w: Estimator is nil", base.ErrInvalidParam)
+	}
+	if err = base.CheckXY(X, Y); err != nil {
+		return
+	}
+	defer base.Recover(&err)
+	return gscv.fit(ctx, X, Y)
 }
 
 // Score for gridSearchCV returns best estimator score
@@ -177,55 +217,17 @@ func (gscv *GridSearchCV) GetNOutputs() int {
 
 // Predict ...
 func (gscv *GridSearchCV) Predict(X mat.Matrix, Y mat.Mutable) *mat.Dense {
+	base.MustBeFitted(gscv)
 	return gscv.BestEstimator.(base.Predicter).Predict(X, Y)
 }
 
-func getParam(estimator interface{}, k string) (v interface{}, ok bool) {
-	est := reflect.ValueOf(estimator)
-	est = reflect.Indirect(est)
-	if est.Kind().String() != "struct" {
-		panic(est.Kind().String())
+// PredictE is Predict returning an error instead of panicking
+func (gscv *GridSearchCV) PredictE(X mat.Matrix, Y mat.Mutable) (*mat.Dense, error) {
+	if !gscv.IsFitted() {
+		return nil, base.ErrNotFitted
 	}
-	field := est.FieldByNameFunc(func(name string) bool { return strings.EqualFold(name, k) })
-	if ok = field.Kind() != 0; ok {
-		v = field.Interface()
-
+	if est, ok := gscv.BestEstimator.(base.PredicterE); ok {
+		return est.PredictE(X, Y)
 	}
-	return
-}
-
-func setParam(estimator base.Predicter, k string, v interface{}) {
-	est := reflect.ValueOf(estimator)
-	est = reflect.Indirect(est)
-	if est.Kind().String() != "struct" {
-		panic(est.Kind().String())
-	}
-	field := est.FieldByNameFunc(func(name string) bool { return strings.EqualFold(name, k) })
-	switch field.Kind() {
-	case 0:
-		panic(fmt.Errorf("no field %s in %T", k, estimator))
-	case reflect.String:
-		field.SetString(v.(string))
-	case reflect.Float64:
-		switch vv := v.(type) {
-		case int:
-			field.SetFloat(float64(vv))
-		case float32:
-			field.SetFloat(float64(vv))
-		case float64:
-			field.SetFloat(float64(vv))
-		default:
-			panic(fmt.Errorf("failed to set %s %s to %v", k, field.Type().String(), v))
-		}
-	case reflect.Int:
-		field.Set(reflect.ValueOf(v))
-
-	case reflect.Interface:
-		field.Set(reflect.ValueOf(v))
-	default:
-		field.Set(reflect.ValueOf(v))
-		//panic(fmt.Errorf("failed to set %s %s to %v", k, field.Type().String(), v))
-
-	}
-
+	return base.PredictE(gscv, X, Y)
 }
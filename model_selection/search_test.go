@@ -1,6 +1,8 @@
 package modelselection
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"math"
 	"sort"
@@ -10,6 +12,7 @@ import (
 	"github.com/pa-m/sklearn/datasets"
 	"github.com/pa-m/sklearn/metrics"
 	neuralnetwork "github.com/pa-m/sklearn/neural_network"
+	"github.com/pa-m/sklearn/pipeline"
 	"github.com/pa-m/sklearn/preprocessing"
 	"golang.org/x/exp/rand"
 	"gonum.org/v1/gonum/mat"
@@ -30,11 +33,14 @@ func TestGridSearchCV_PredicterClone(t *testing.T) {
 		CV:                 &KFold{NSplits: 3, RandomState: RandomState, Shuffle: true},
 		Verbose:            true,
 		NJobs:              -1}
-	clone := m.PredicterClone()
-	expected, actual := fmt.Sprintf("%+v", m), fmt.Sprintf("%+v", clone)
-	if m == clone {
-		t.Fail()
+	clone := m.PredicterClone().(*GridSearchCV)
+	if m == clone || clone.Estimator == m.Estimator || clone.CV == m.CV {
+		t.Error("clone must not share estimator or splitter")
 	}
+	if cv := clone.CV.(*KFold); cv.NSplits != 3 || !cv.Shuffle || cv.RandomState == RandomState {
+		t.Errorf("unexpected splitter clone %+v", cv)
+	}
+	expected, actual := fmt.Sprintf("%+v", m.Estimator), fmt.Sprintf("%+v", clone.Estimator)
 	if actual != expected {
 		t.Errorf("\nexpected: %s\ngot     : %s", expected, actual)
 	}
@@ -119,27 +125,74 @@ func ExampleGridSearchCV() {
 	// fmt.Println(gscv.CVResults["score"])
 
 	// Output:
-	// Alpha 0.0002
-	// WeightDecay 0
+	// Alpha 0.0005
+	// WeightDecay 0.0001
 
 }
 
-func Test_setParam(t *testing.T) {
+func TestGridSearchCV_SetParams(t *testing.T) {
 	mlp := neuralnetwork.NewMLPRegressor([]int{20}, "relu", "adam", 1e-4)
-	setParam(mlp, "activation", "logistic")
-	setParam(mlp, "Alpha", 1)
-	str, ok := getParam(mlp, "activation")
-	if !ok {
-		t.Fail()
+	if err := base.SetParams(mlp, map[string]interface{}{"activation": "logistic", "Alpha": 1}); err != nil {
+		t.Fatal(err)
+	}
+	params := mlp.GetParams()
+	if params["Activation"].(string) != "logistic" {
+		t.Errorf("expected logistic, got %v", params["Activation"])
+	}
+	if params["Alpha"].(float64) != 1 {
+		t.Errorf("expected 1, got %v", params["Alpha"])
+	}
+
+	// nested params of the estimator of a pipeline tuned by a GridSearchCV
+	pl := pipeline.MakePipeline(preprocessing.NewStandardScaler(), mlp)
+	gscv := &GridSearchCV{Estimator: pl}
+	if err := gscv.SetParams(map[string]interface{}{"estimator__mlpregressor__max_iter": 50.}); err != nil {
+		t.Fatal(err)
 	}
-	if str.(string) != "logistic" {
-		t.Fail()
+	if mlp.MaxIter != 50 {
+		t.Errorf("expected MaxIter 50, got %d", mlp.MaxIter)
+	}
+	if v := gscv.GetParams()["Estimator__mlpregressor__MaxIter"]; v != 50 {
+		t.Errorf("expected 50, got %v", v)
+	}
+	if err := gscv.SetParams(map[string]interface{}{"estimator__svc__C": 1.}); !errors.Is(err, base.ErrInvalidParam) {
+		t.Errorf("expected ErrInvalidParam, got %v", err)
+	}
+}
+
+func TestGridSearchCV_FitE(t *testing.T) {
+	ds := datasets.LoadIris()
+	mlp := neuralnetwork.NewMLPClassifier([]int{}, "relu", "adam", 1e-4)
+	m := &GridSearchCV{
+		Estimator: mlp,
+		ParamGrid: map[string][]interface{}{"NoSuchParam": {1, 2}},
+	}
+	if err := m.FitE(ds.X, ds.Y); !errors.Is(err, base.ErrInvalidParam) {
+		t.Errorf("expected ErrInvalidParam, got %v", err)
+	}
+	m.ParamGrid = map[string][]interface{}{"Alpha": {"a string"}}
+	if err := m.FitE(ds.X, ds.Y); !errors.Is(err, base.ErrInvalidParam) {
+		t.Errorf("expected ErrInvalidParam, got %v", err)
+	}
+	if _, err := m.PredictE(ds.X, nil); !errors.Is(err, base.ErrNotFitted) {
+		t.Errorf("expected ErrNotFitted, got %v", err)
+	}
+}
+
+func TestGridSearchCV_FitContext(t *testing.T) {
+	ds := datasets.LoadIris()
+	m := &GridSearchCV{
+		Estimator: neuralnetwork.NewMLPClassifier([]int{}, "relu", "adam", 1e-4),
+		ParamGrid: map[string][]interface{}{"Alpha": {1e-4, 1e-3}},
 	}
-	alpha, ok2 := getParam(mlp, "alpha")
-	if !ok2 {
-		t.Fail()
+	errStop := errors.New("stop")
+	ctx := base.WithProgress(context.Background(), func(base.Progress) error { return errStop })
+	if err := m.FitContext(ctx, ds.X, ds.Y); !errors.Is(err, errStop) {
+		t.Errorf("expected errStop, got %v", err)
 	}
-	if alpha.(float64) != 1 {
-		t.Fail()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := m.FitContext(ctx, ds.X, ds.Y); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
 	}
 }
@@ -11,7 +11,9 @@ import (
 // RandomState is to init a new random source for reproducibility
 type RandomState = rand.Rand
 
-// KFold ...
+// KFold splits the samples into NSplits consecutive folds, each fold being the test set of a split once. the test folds
+// are a partition of the samples: without Shuffle, the first fold holds the first samples. with Shuffle, the samples are
+// shuffled once with RandomState before being split
 type KFold struct {
 	NSplits     int
 	Shuffle     bool
@@ -44,7 +46,7 @@ func (splitter *KFold) SplitterClone() Splitter {
 	return &clone
 }
 
-// Split generate Split structs
+// Split generate Split structs. the first NSamples%NSplits test folds have one more sample than the others
 func (splitter *KFold) Split(X, Y *mat.Dense) (ch chan Split) {
 	if splitter.NSplits <= 0 {
 		splitter.NSplits = 3
@@ -54,9 +56,7 @@ func (splitter *KFold) Split(X, Y *mat.Dense) (ch chan Split) {
 	type Shuffler interface {
 		Shuffle(n int, swap func(i, j int))
 	}
-	type Intner interface{ Intn(int) int }
 	var rndShuffle = rand.Shuffle
-	var rndIntn = rand.Intn
 
 	if splitter.RandomState != base.Source(nil) {
 		if shuffler, ok := splitter.RandomState.(Shuffler); ok {
@@ -64,39 +64,30 @@ func (splitter *KFold) Split(X, Y *mat.Dense) (ch chan Split) {
 		} else {
 			rndShuffle = rand.New(splitter.RandomState).Shuffle
 		}
-		if intner, ok := splitter.RandomState.(Intner); ok {
-			rndIntn = intner.Intn
-		} else {
-			rndIntn = rand.New(splitter.RandomState).Intn
-		}
+	}
+	a := make([]int, NSamples)
+	for i := range a {
+		a[i] = i
+	}
+	if splitter.Shuffle {
+		rndShuffle(len(a), func(i, j int) { a[i], a[j] = a[j], a[i] })
 	}
 
 	ch = make(chan Split)
 	go func() {
+		start := 0
 		for isplit := 0; isplit < splitter.NSplits; isplit++ {
 			NTest := NSamples / splitter.NSplits
 			// The first n_samples % n_splits folds have size n_samples // n_splits + 1, other folds have size n_samples // n_splits, where n_samples is the number of samples.
 			if isplit < NSamples%splitter.NSplits {
 				NTest++
 			}
-			a := make([]int, NSamples)
-
-			for i := range a {
-				a[i] = i
-			}
-			aSwap := func(i, j int) { a[i], a[j] = a[j], a[i] }
-			if splitter.Shuffle {
-				rndShuffle(len(a), aSwap)
-			} else {
-				start := rndIntn(NSamples)
-				for i := 0; i < NTest; i++ {
-					aSwap((start+i)%NSamples, NSamples-NTest+i)
-				}
-			}
 			sp := Split{
-				TrainIndex: a[:NSamples-NTest],
-				TestIndex:  a[NSamples-NTest:],
+				TrainIndex: make([]int, 0, NSamples-NTest),
+				TestIndex:  append([]int(nil), a[start:start+NTest]...),
 			}
+			sp.TrainIndex = append(append(sp.TrainIndex, a[:start]...), a[start+NTest:]...)
+			start += NTest
 
 			ch <- sp
 		}
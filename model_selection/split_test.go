@@ -2,6 +2,7 @@ package modelselection
 
 import (
 	"fmt"
+	"testing"
 
 	"github.com/pa-m/sklearn/base"
 	"golang.org/x/exp/rand"
@@ -23,11 +24,32 @@ func ExampleKFold() {
 	subtest(true)
 	// Output:
 	// shuffle false
+	// modelselection.Split{TrainIndex:[]int{2, 3, 4, 5}, TestIndex:[]int{0, 1}}
+	// modelselection.Split{TrainIndex:[]int{0, 1, 4, 5}, TestIndex:[]int{2, 3}}
 	// modelselection.Split{TrainIndex:[]int{0, 1, 2, 3}, TestIndex:[]int{4, 5}}
-	// modelselection.Split{TrainIndex:[]int{4, 5, 2, 3}, TestIndex:[]int{0, 1}}
-	// modelselection.Split{TrainIndex:[]int{0, 4, 5, 3}, TestIndex:[]int{1, 2}}
 	// shuffle true
-	// modelselection.Split{TrainIndex:[]int{5, 0, 2, 3}, TestIndex:[]int{4, 1}}
-	// modelselection.Split{TrainIndex:[]int{5, 3, 2, 0}, TestIndex:[]int{1, 4}}
-	// modelselection.Split{TrainIndex:[]int{2, 4, 1, 0}, TestIndex:[]int{5, 3}}
+	// modelselection.Split{TrainIndex:[]int{1, 3, 2, 4}, TestIndex:[]int{0, 5}}
+	// modelselection.Split{TrainIndex:[]int{0, 5, 2, 4}, TestIndex:[]int{1, 3}}
+	// modelselection.Split{TrainIndex:[]int{0, 5, 1, 3}, TestIndex:[]int{2, 4}}
+}
+
+func TestKFoldPartition(t *testing.T) {
+	X := mat.NewDense(11, 1, nil)
+	for _, shuffle := range []bool{false, true} {
+		inTest := make([]int, 11)
+		kf := &KFold{NSplits: 3, Shuffle: shuffle, RandomState: base.NewSource(7)}
+		for sp := range kf.Split(X, nil) {
+			if len(sp.TrainIndex)+len(sp.TestIndex) != 11 {
+				t.Errorf("shuffle %v: %d train and %d test samples, expected 11 samples", shuffle, len(sp.TrainIndex), len(sp.TestIndex))
+			}
+			for _, i := range sp.TestIndex {
+				inTest[i]++
+			}
+		}
+		for i, n := range inTest {
+			if n != 1 {
+				t.Errorf("shuffle %v: sample %d is in %d test folds, expected 1", shuffle, i, n)
+			}
+		}
+	}
 }
@@ -1,7 +1,8 @@
 package modelselection
 
 import (
-	// "fmt"
+	"context"
+	"fmt"
 	"runtime"
 	"time"
 
@@ -35,6 +36,27 @@ func (r CrossValidateResult) Swap(i, j int) {
 // only mean_squared_error for now
 // NJobs is the number of goroutines. if <=0, runtime.NumCPU is used
 func CrossValidate(estimator base.Predicter, X, Y *mat.Dense, groups []int, scorer func(Ytrue, Ypred mat.Matrix) float64, cv Splitter, NJobs int) (res CrossValidateResult) {
+	res, _ = crossValidate(nil, estimator, X, Y, nil, groups, scorer, cv, NJobs)
+	return
+}
+
+// CrossValidateContext is CrossValidate stopping when ctx is done. estimators are fitted with base.FitContext
+// and the first fit error is returned
+func CrossValidateContext(ctx context.Context, estimator base.Predicter, X, Y *mat.Dense, groups []int, scorer func(Ytrue, Ypred mat.Matrix) float64, cv Splitter, NJobs int) (res CrossValidateResult, err error) {
+	return crossValidate(ctx, estimator, X, Y, nil, groups, scorer, cv, NJobs)
+}
+
+// CrossValidateWeighted is CrossValidate fitting each estimator with base.FitWeighted on the weights of its train samples.
+// test scores are not weighted. the first fit error is returned
+func CrossValidateWeighted(estimator base.Predicter, X, Y *mat.Dense, sampleWeight []float64, groups []int, scorer func(Ytrue, Ypred mat.Matrix) float64, cv Splitter, NJobs int) (res CrossValidateResult, err error) {
+	if err = base.CheckSampleWeight(X, sampleWeight); err != nil {
+		return
+	}
+	return crossValidate(nil, estimator, X, Y, sampleWeight, groups, scorer, cv, NJobs)
+}
+
+// crossValidate panics on fit errors if ctx and sampleWeight are nil
+func crossValidate(ctx context.Context, estimator base.Predicter, X, Y *mat.Dense, sampleWeight []float64, groups []int, scorer func(Ytrue, Ypred mat.Matrix) float64, cv Splitter, NJobs int) (res CrossValidateResult, err error) {
 
 	if NJobs <= 0 {
 		NJobs = runtime.NumCPU()
@@ -57,6 +79,7 @@ func CrossValidate(estimator base.Predicter, X, Y *mat.Dense, groups []int, scor
 	type structOut struct {
 		iSplit int
 		score  float64
+		err    error
 	}
 	NSamples, NFeatures := X.Dims()
 	_, NOutputs := Y.Dims()
@@ -79,7 +102,15 @@ func CrossValidate(estimator base.Predicter, X, Y *mat.Dense, groups []int, scor
 
 		res.Estimator[sin.iSplit] = estimator.PredicterClone()
 		t0 := time.Now()
-		res.Estimator[sin.iSplit].Fit(Xtrain, Ytrain)
+		if sampleWeight != nil {
+			if err := base.FitWeighted(res.Estimator[sin.iSplit], Xtrain, Ytrain, base.SelectWeights(sampleWeight, sin.Split.TrainIndex)); err != nil {
+				return structOut{iSplit: sin.iSplit, err: err}
+			}
+		} else if ctx == nil {
+			res.Estimator[sin.iSplit].Fit(Xtrain, Ytrain)
+		} else if err := base.FitContext(ctx, res.Estimator[sin.iSplit], Xtrain, Ytrain); err != nil {
+			return structOut{iSplit: sin.iSplit, err: err}
+		}
 		res.FitTime[sin.iSplit] = time.Since(t0)
 		t0 = time.Now()
 		Ypred := mat.NewDense(Xtest.RawMatrix().Rows, res.Estimator[sin.iSplit].GetNOutputs(), nil)
@@ -87,7 +118,7 @@ func CrossValidate(estimator base.Predicter, X, Y *mat.Dense, groups []int, scor
 		score := scorer(Ytest, Ypred)
 		res.ScoreTime[sin.iSplit] = time.Since(t0)
 		//fmt.Printf("score for split %d is %g\n", sin.iSplit, score)
-		return structOut{sin.iSplit, score}
+		return structOut{iSplit: sin.iSplit, score: score}
 
 	}
 	if NJobs > 1 {
@@ -95,21 +126,110 @@ func CrossValidate(estimator base.Predicter, X, Y *mat.Dense, groups []int, scor
 		for split := range cv.Split(X, Y) {
 			sin = append(sin, structIn{iSplit: len(sin), Split: split})
 		}
+		errs := make([]error, NSplits)
 		base.Parallelize(NJobs, NSplits, func(th, start, end int) {
 			var Xjob, Yjob = mat.NewDense(NSamples, NFeatures, nil), mat.NewDense(NSamples, NOutputs, nil)
 			for i := start; i < end; i++ {
 				sout := processSplit(th, Xjob, Yjob, sin[i])
-				res.TestScore[sout.iSplit] = sout.score
+				res.TestScore[sout.iSplit], errs[sout.iSplit] = sout.score, sout.err
 			}
 		})
+		for _, e := range errs {
+			if e != nil && err == nil {
+				err = e
+			}
+		}
 	} else { // NJobs==1
 		var Xjob, Yjob = mat.NewDense(NSamples, NFeatures, nil), mat.NewDense(NSamples, NOutputs, nil)
 		var isplit int
 		for split := range cv.Split(X, Y) {
-			sout := processSplit(0, Xjob, Yjob, structIn{iSplit: isplit, Split: split})
-			res.TestScore[sout.iSplit] = sout.score
+			// the channel is drained even after an error so that the splitter goroutine ends
+			if err == nil {
+				sout := processSplit(0, Xjob, Yjob, structIn{iSplit: isplit, Split: split})
+				res.TestScore[sout.iSplit], err = sout.score, sout.err
+			}
 			isplit++
 		}
 	}
 	return
 }
+
+// CrossValPredict returns the out-of-fold predictions of estimator: the row of each sample is predicted by the clone of estimator
+// fitted on the train samples of the split whose test samples contain it. predict returns the predictions of a fitted clone,
+// nil meaning its Predict method. clones are fitted with base.FitWeighted on the weights of their train samples, sampleWeight may be nil.
+// each sample must be in exactly one test fold of cv, which defaults to a 3-fold KFold.
+// NJobs is the number of goroutines. if <=0, runtime.NumCPU is used. the first error is returned
+func CrossValPredict(estimator base.Predicter, X, Y *mat.Dense, sampleWeight []float64, cv Splitter, NJobs int, predict func(estimator base.Predicter, X *mat.Dense) *mat.Dense) (Ypred *mat.Dense, err error) {
+	if err = base.CheckSampleWeight(X, sampleWeight); err != nil {
+		return
+	}
+	if cv == Splitter(nil) {
+		cv = &KFold{NSplits: 3}
+	}
+	if predict == nil {
+		predict = func(estimator base.Predicter, X *mat.Dense) *mat.Dense { return estimator.Predict(X, nil) }
+	}
+	NSamples, _ := X.Dims()
+	var splits []Split
+	inTest := make([]int, NSamples)
+	for split := range cv.Split(X, Y) {
+		if len(split.TestIndex) > 0 {
+			if len(split.TrainIndex) == 0 {
+				err = fmt.Errorf("%w: split without train samples", base.ErrInvalidParam)
+			}
+			splits = append(splits, split)
+		}
+		for _, i := range split.TestIndex {
+			inTest[i]++
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	for i, n := range inTest {
+		if n != 1 {
+			return nil, fmt.Errorf("%w: sample %d is in %d test folds, expected 1", base.ErrInvalidParam, i, n)
+		}
+	}
+	preds := make([]*mat.Dense, len(splits))
+	errs := make([]error, len(splits))
+	base.Parallelize(NJobs, len(splits), func(th, start, end int) {
+		for s := start; s < end; s++ {
+			preds[s], errs[s] = crossValPredictSplit(estimator, X, Y, sampleWeight, splits[s], predict)
+		}
+	})
+	for _, e := range errs {
+		if e != nil {
+			return nil, e
+		}
+	}
+	for s, split := range splits {
+		if Ypred == nil {
+			_, c := preds[s].Dims()
+			Ypred = mat.NewDense(NSamples, c, nil)
+		}
+		for i0, i1 := range split.TestIndex {
+			Ypred.SetRow(i1, preds[s].RawRowView(i0))
+		}
+	}
+	return
+}
+
+// crossValPredictSplit fits a clone of estimator on the train samples of split and returns the predictions of its test samples
+func crossValPredictSplit(estimator base.Predicter, X, Y *mat.Dense, sampleWeight []float64, split Split, predict func(estimator base.Predicter, X *mat.Dense) *mat.Dense) (Ypred *mat.Dense, err error) {
+	rows := func(M *mat.Dense, index []int) *mat.Dense {
+		_, c := M.Dims()
+		sub := mat.NewDense(len(index), c, nil)
+		for i0, i1 := range index {
+			sub.SetRow(i0, M.RawRowView(i1))
+		}
+		return sub
+	}
+	clone := estimator.PredicterClone()
+	if err = base.FitWeighted(clone, rows(X, split.TrainIndex), rows(Y, split.TrainIndex), base.SelectWeights(sampleWeight, split.TrainIndex)); err != nil {
+		return
+	}
+	defer base.Recover(&err)
+	Ypred = predict(clone, rows(X, split.TestIndex))
+	return
+}
@@ -1,8 +1,11 @@
 package modelselection
 
 import (
+	"errors"
 	"fmt"
+	"math"
 	"sort"
+	"testing"
 
 	"github.com/pa-m/sklearn/base"
 	"github.com/pa-m/sklearn/datasets"
@@ -28,7 +31,98 @@ func ExampleCrossValidate() {
 		fmt.Printf("%.8f\n", cvresults.TestScore)
 	}
 	// Output:
-	// [0.29391770 0.25681807 0.24695688]
-	// [0.29391770 0.25681807 0.24695688]
+	// [0.31062345 0.30186153 0.25681807]
+	// [0.31062345 0.30186153 0.25681807]
 
 }
+
+func TestCrossValidate_Clones(t *testing.T) {
+	diabetes := datasets.LoadDiabetes()
+	X, y := diabetes.X.Slice(0, 150, 0, diabetes.X.RawMatrix().Cols).(*mat.Dense), diabetes.Y.Slice(0, 150, 0, 1).(*mat.Dense)
+	lasso := linearModel.NewLasso()
+	scorer := func(Y, Ypred mat.Matrix) float64 { return metrics.R2Score(Y, Ypred, nil, "").At(0, 0) }
+	cvresults := CrossValidate(lasso, X, y, nil, scorer, &KFold{NSplits: 3}, 3)
+	if err := base.CheckFitted(lasso); !errors.Is(err, base.ErrNotFitted) {
+		t.Errorf("CrossValidate must not fit its estimator, got %v", err)
+	}
+	est0, est1 := cvresults.Estimator[0].(*linearModel.Lasso), cvresults.Estimator[1].(*linearModel.Lasso)
+	if est0.Coef == est1.Coef || mat.Equal(est0.Coef, est1.Coef) {
+		t.Error("fold estimators must not share their coefficients")
+	}
+}
+
+func TestCrossValidateWeighted(t *testing.T) {
+	diabetes := datasets.LoadDiabetes()
+	X, y := diabetes.X.Slice(0, 150, 0, diabetes.X.RawMatrix().Cols).(*mat.Dense), diabetes.Y.Slice(0, 150, 0, 1).(*mat.Dense)
+	scorer := func(Y, Ypred mat.Matrix) float64 { return metrics.R2Score(Y, Ypred, nil, "").At(0, 0) }
+	sampleWeight := make([]float64, 150)
+	for i := range sampleWeight {
+		sampleWeight[i] = 1
+	}
+	expected := CrossValidate(linearModel.NewLinearRegression(), X, y, nil, scorer, &KFold{NSplits: 3, RandomState: base.NewSource(7)}, 1)
+	actual, err := CrossValidateWeighted(linearModel.NewLinearRegression(), X, y, sampleWeight, nil, scorer, &KFold{NSplits: 3, RandomState: base.NewSource(7)}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range expected.TestScore {
+		if math.Abs(expected.TestScore[i]-actual.TestScore[i]) > 1e-9 {
+			t.Errorf("split %d: uniform weights must not change the score. expected %g, got %g", i, expected.TestScore[i], actual.TestScore[i])
+		}
+	}
+	sampleWeight[0] = 2
+	if _, err := CrossValidateWeighted(linearModel.NewBayesianRidge(), X, y, sampleWeight, nil, scorer, &KFold{NSplits: 3}, 3); !errors.Is(err, base.ErrInvalidParam) {
+		t.Errorf("expected ErrInvalidParam for an estimator without sample weights, got %v", err)
+	}
+}
+
+// repeatedSplitter returns twice the same split, whose test samples are the first half of the samples
+type repeatedSplitter struct{}
+
+func (repeatedSplitter) Split(X, Y *mat.Dense) chan Split {
+	NSamples, _ := X.Dims()
+	ch := make(chan Split)
+	go func() {
+		for s := 0; s < 2; s++ {
+			sp := Split{}
+			for i := 0; i < NSamples; i++ {
+				if i < NSamples/2 {
+					sp.TestIndex = append(sp.TestIndex, i)
+				} else {
+					sp.TrainIndex = append(sp.TrainIndex, i)
+				}
+			}
+			ch <- sp
+		}
+		close(ch)
+	}()
+	return ch
+}
+func (repeatedSplitter) GetNSplits(X, Y *mat.Dense) int { return 2 }
+func (s repeatedSplitter) SplitterClone() Splitter      { return s }
+
+func TestCrossValPredict(t *testing.T) {
+	diabetes := datasets.LoadDiabetes()
+	X, y := diabetes.X.Slice(0, 150, 0, diabetes.X.RawMatrix().Cols).(*mat.Dense), diabetes.Y.Slice(0, 150, 0, 1).(*mat.Dense)
+	Ypred, err := CrossValPredict(linearModel.NewLinearRegression(), X, y, nil, &KFold{NSplits: 3, Shuffle: true, RandomState: base.NewSource(7)}, 3, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for split := range (&KFold{NSplits: 3, Shuffle: true, RandomState: base.NewSource(7)}).Split(X, y) {
+		Xtrain, Ytrain := mat.NewDense(len(split.TrainIndex), 10, nil), mat.NewDense(len(split.TrainIndex), 1, nil)
+		for i0, i1 := range split.TrainIndex {
+			Xtrain.SetRow(i0, X.RawRowView(i1))
+			Ytrain.SetRow(i0, y.RawRowView(i1))
+		}
+		regr := linearModel.NewLinearRegression()
+		regr.Fit(Xtrain, Ytrain)
+		for _, i := range split.TestIndex {
+			expected := regr.Predict(X.Slice(i, i+1, 0, 10), nil).At(0, 0)
+			if math.Abs(expected-Ypred.At(i, 0)) > 1e-9 {
+				t.Fatalf("sample %d: expected out-of-fold prediction %g, got %g", i, expected, Ypred.At(i, 0))
+			}
+		}
+	}
+	if _, err := CrossValPredict(linearModel.NewLinearRegression(), X, y, nil, repeatedSplitter{}, 1, nil); !errors.Is(err, base.ErrInvalidParam) {
+		t.Errorf("expected ErrInvalidParam for test folds which are not a partition, got %v", err)
+	}
+}
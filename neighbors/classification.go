@@ -15,16 +15,16 @@ import (
 // The target is predicted by local interpolation of the targets
 // associated of the nearest neighbors in the training set.
 type KNeighborsClassifier struct {
-	base.Predicter
 	NearestNeighbors
 	K        int
 	Weight   string
 	Scale    bool
-	Distance Distance
+	Distance Distance `persist:"-"`
 	// Runtime members
-	Xscaled, Y *mat.Dense
-	Classes    [][]float64
-	nOutputs   int
+	Xscaled, Y   *mat.Dense  `param:"-"`
+	Classes      [][]float64 `param:"-"`
+	SampleWeight []float64   `param:"-"`
+	nOutputs     int
 }
 
 // NewKNeighborsClassifier returns an initialized *KNeighborsClassifier
@@ -32,17 +32,37 @@ func NewKNeighborsClassifier(K int, Weights string) *KNeighborsClassifier {
 	return &KNeighborsClassifier{NearestNeighbors: *NewNearestNeighbors(), K: K, Weight: Weights}
 }
 
+// PredicterClone returns an unfitted copy of predicter
+func (m *KNeighborsClassifier) PredicterClone() base.Predicter {
+	clone := *m
+	clone.NearestNeighbors.resetFitted()
+	clone.Xscaled, clone.Y, clone.Classes, clone.SampleWeight, clone.nOutputs = nil, nil, nil, nil, 0
+	return base.DeepCopy(&clone).(*KNeighborsClassifier)
+}
+
+// IsClassifier returns true for KNeighborsClassifier
+func (*KNeighborsClassifier) IsClassifier() bool { return true }
+
 // Fit ...
-func (m *KNeighborsClassifier) Fit(Xmatrix, Ymatrix mat.Matrix) base.Fiter {
-	X, Y := base.ToDense(Xmatrix), base.ToDense(Ymatrix)
-	m.Xscaled = mat.DenseCopyOf(X)
+func (m *KNeighborsClassifier) Fit(X, Ymatrix mat.Matrix) base.Fiter {
+	return m.FitWeighted(X, Ymatrix, nil)
+}
+
+// FitWeighted is Fit with the votes of each neighbor multiplied by its sample weight
+func (m *KNeighborsClassifier) FitWeighted(X, Ymatrix mat.Matrix, sampleWeight []float64) base.Fiter {
+	Y := base.ToDense(Ymatrix)
+	m.SampleWeight = sampleWeight
+	m.Xscaled = nil
+	if !base.IsSparse(X) && !base.IsFloat32(X) {
+		m.Xscaled = mat.DenseCopyOf(X)
+	}
 	m.Y = Y
 	m.nOutputs = Y.RawMatrix().Cols
 	if m.Distance == nil {
 		m.Distance = EuclideanDistance
 	}
 	if m.K <= 0 {
-		panic(fmt.Errorf("K<=0"))
+		panic(fmt.Errorf("%w: K<=0", base.ErrInvalidParam))
 	}
 	m.NearestNeighbors.Fit(X, Y)
 	m.Classes, _ = getClasses(Y)
@@ -56,34 +76,36 @@ func (m *KNeighborsClassifier) GetNOutputs() int {
 
 // Predict  for KNeighborsClassifier
 func (m *KNeighborsClassifier) Predict(X mat.Matrix, Ymutable mat.Mutable) *mat.Dense {
+	base.MustBeFitted(m)
 	Y := base.ToDense(Ymutable)
 	nSamples, _ := X.Dims()
 	if Y.IsZero() {
 		*Y = *mat.NewDense(nSamples, m.GetNOutputs(), nil)
 	}
 
-	m._predict(base.ToDense(X), Y, false)
+	m._predict(X, Y, false)
 	return base.FromDense(Ymutable, Y)
 }
 
-// PredictProba for KNeighborsClassifier
-func (m *KNeighborsClassifier) PredictProba(X, Y *mat.Dense) *KNeighborsClassifier {
-	return m._predict(X, Y, true)
+// PredictProba for KNeighborsClassifier returns the weighted fraction of neighbors of each class. see base.ProbaPredicter
+func (m *KNeighborsClassifier) PredictProba(X mat.Matrix, Ymutable mat.Mutable) *mat.Dense {
+	base.MustBeFitted(m)
+	nSamples, _ := X.Dims()
+	nClasses := 0
+	for _, classes := range m.Classes {
+		nClasses += len(classes)
+	}
+	P := mat.NewDense(nSamples, nClasses, nil)
+	m._predict(X, P, true)
+	return base.FromDense(Ymutable, P)
 }
 
-func (m *KNeighborsClassifier) _predict(X, Y *mat.Dense, wantProba bool) *KNeighborsClassifier {
+func (m *KNeighborsClassifier) _predict(X mat.Matrix, Y *mat.Dense, wantProba bool) *KNeighborsClassifier {
 	_, outputs := m.Y.Dims()
-	if wantProba {
-		if outputs > 1 {
-			panic("PredictProba is undefined for multioutput classification")
-		}
-		if Y == nil {
-			panic("Y is unallocated")
-		}
-		_, Ycols := Y.Dims()
-		if Ycols != len(m.Classes[0]) {
-			panic(fmt.Errorf("PredictProba theres %d classes but Y has %d columns", len(m.Classes[0]), Ycols))
-		}
+	// classOffset is the first PredictProba column of each output
+	classOffset := make([]int, outputs)
+	for o := 1; o < outputs; o++ {
+		classOffset[o] = classOffset[o-1] + len(m.Classes[o-1])
 	}
 	NX, _ := X.Dims()
 
@@ -96,28 +118,24 @@ func (m *KNeighborsClassifier) _predict(X, Y *mat.Dense, wantProba bool) *KNeigh
 		weights := make([]float64, m.K)
 		sumweights := 0.
 		ys := make([]float64, m.K)
-		if !isWeightDistance {
-			for ik := range weights {
-				weights[ik] = 1.
-				sumweights += 1.
-			}
-		}
 		for sample := start; sample < end; sample++ {
 			// set Y(sample,output) to weighted average of K nearest
 
 			for o := 0; o < outputs; o++ {
 				classw := make(map[float64]float64)
-				if isWeightDistance {
-					sumweights = 0.
-				}
+				sumweights = 0.
 				for ik := range ys {
-					cl := m.Y.At(int(indices.At(sample, ik)), o)
+					neighbor := int(indices.At(sample, ik))
+					cl := m.Y.At(neighbor, o)
+					weights[ik] = 1.
 					if isWeightDistance {
 						dist := distances.At(sample, ik)
 						weights[ik] = 1. / (epsilon + dist)
-						sumweights += weights[ik]
-
 					}
+					if m.SampleWeight != nil {
+						weights[ik] *= m.SampleWeight[neighbor]
+					}
+					sumweights += weights[ik]
 					if clw, present := classw[cl]; present {
 						classw[cl] = clw + weights[ik]
 					} else {
@@ -132,9 +150,9 @@ func (m *KNeighborsClassifier) _predict(X, Y *mat.Dense, wantProba bool) *KNeigh
 					}
 				}
 				if wantProba {
-					for icl, cl := range m.Classes[0] {
+					for icl, cl := range m.Classes[o] {
 						if clw, found := classw[cl]; found {
-							Y.Set(sample, icl, clw/sumweights)
+							Y.Set(sample, classOffset[o]+icl, clw/sumweights)
 						}
 					}
 				} else {
@@ -147,6 +165,25 @@ func (m *KNeighborsClassifier) _predict(X, Y *mat.Dense, wantProba bool) *KNeigh
 	return m
 }
 
+// FitE is Fit returning an error instead of panicking
+func (m *KNeighborsClassifier) FitE(X, Y mat.Matrix) error {
+	if m.K <= 0 {
+		return fmt.Errorf("%w: K must be positive, got %d", base.ErrInvalidParam, m.K)
+	}
+	if n, _ := X.Dims(); m.K > n {
+		return fmt.Errorf("%w: K %d > NSamples %d", base.ErrInvalidParam, m.K, n)
+	}
+	return base.FitE(m, X, Y)
+}
+
+// PredictE is Predict returning an error instead of panicking
+func (m *KNeighborsClassifier) PredictE(X mat.Matrix, Y mat.Mutable) (*mat.Dense, error) {
+	if err := m.NearestNeighbors.checkFitted(X); err != nil {
+		return nil, err
+	}
+	return base.PredictE(m, X, Y)
+}
+
 // Score for KNeighborsClassifier
 func (m *KNeighborsClassifier) Score(X, Y mat.Matrix) float64 {
 	NSamples, NOutputs := Y.Dims()
@@ -1,11 +1,20 @@
 package neighbors
 
 import (
+	"errors"
 	"fmt"
+	"math"
+	"testing"
 
+	"github.com/pa-m/sklearn/base"
 	"gonum.org/v1/gonum/mat"
 )
 
+var _ = []base.PredicterE{&KNeighborsClassifier{}, &KNeighborsRegressor{}, &NearestCentroid{}}
+var _ = []base.ProbaPredicter{&KNeighborsClassifier{}, &NearestCentroid{}}
+var _ base.DecisionFunctioner = &NearestCentroid{}
+var _ = []base.FittedChecker{&KNeighborsClassifier{}, &KNeighborsRegressor{}, &NearestCentroid{}}
+
 func ExampleKNeighborsClassifier() {
 	X := mat.NewDense(4, 1, []float64{0, 1, 2, 3})
 	Y := mat.NewDense(4, 1, []float64{0, 0, 1, 1})
@@ -25,3 +34,40 @@ func ExampleKNeighborsClassifier() {
 	// [0]
 	// [0.66666667  0.33333333]
 }
+
+func TestKNeighborsClassifier_PredicterClone(t *testing.T) {
+	X := mat.NewDense(6, 2, []float64{-1, -1, -2, -1, -3, -2, 1, 1, 2, 1, 3, 2})
+	Y := mat.NewDense(6, 1, []float64{1, 1, 1, 2, 2, 2})
+	for _, m := range []base.Predicter{NewKNeighborsClassifier(3, "uniform"), NewNearestCentroid("euclidean", 0)} {
+		if !m.IsClassifier() {
+			t.Errorf("%T must be a classifier", m)
+		}
+		m.Fit(X, Y)
+		unfitted := m.PredicterClone()
+		if _, err := unfitted.(base.PredicterE).PredictE(X, nil); !errors.Is(err, base.ErrNotFitted) {
+			t.Errorf("%T: expected ErrNotFitted, got %v", m, err)
+		}
+		fitted := base.FittedClone(m)
+		expected := m.Predict(X, nil)
+		m.Fit(X, mat.NewDense(6, 1, []float64{2, 2, 2, 1, 1, 1}))
+		if actual := fitted.Predict(X, nil); !mat.Equal(expected, actual) {
+			t.Errorf("%T: refitting the original changed the clone predictions", m)
+		}
+	}
+}
+
+func TestKNeighborsClassifier_FitWeighted(t *testing.T) {
+	X := mat.NewDense(4, 1, []float64{0, 1, 2, 3})
+	Y := mat.NewDense(4, 1, []float64{0, 0, 1, 1})
+	Xtest := mat.NewDense(1, 1, []float64{0.9})
+	clf := NewKNeighborsClassifier(3, "uniform")
+	clf.FitWeighted(X, Y, []float64{1, 1, 4, 1})
+	if P := clf.PredictProba(Xtest, nil); math.Abs(P.At(0, 1)-4./6) > 1e-12 {
+		t.Errorf("expected weighted proba %g, got %g", 4./6, P.At(0, 1))
+	}
+	reg := NewKNeighborsRegressor(3, "uniform").(*KNeighborsRegressor)
+	reg.FitWeighted(X, Y, []float64{1, 1, 4, 1})
+	if Ypred := reg.Predict(Xtest, nil); math.Abs(Ypred.At(0, 0)-4./6) > 1e-12 {
+		t.Errorf("expected weighted mean %g, got %g", 4./6, Ypred.At(0, 0))
+	}
+}
@@ -0,0 +1,44 @@
+package neighbors
+
+import (
+	"math"
+
+	"github.com/pa-m/sklearn/base"
+	"gonum.org/v1/gonum/mat"
+)
+
+// float32KNeighbors is the brute force KNeighbors for a float32 fitted X. X is converted to float32 if needed
+func (m *NearestNeighbors) float32KNeighbors(Xmatrix mat.Matrix, NNeighbors int) (distances, indices *mat.Dense) {
+	X, ok := Xmatrix.(base.General32)
+	if !ok {
+		X = base.General32Of(Xmatrix)
+	}
+	return m.bruteKNeighbors(X.Rows, NNeighbors, func(sample, ifs int) float64 {
+		return Float32MinkowskiDistance(X.RawRowView(sample), m.X32.RawRowView(ifs), m.P)
+	})
+}
+
+// Float32MinkowskiDistance returns the minkowski distance of power p between float32 vectors a and b
+func Float32MinkowskiDistance(a, b []float32, p float64) float64 {
+	var d float64
+	for i := range a {
+		v := math.Abs(float64(a[i]) - float64(b[i]))
+		switch {
+		case math.IsInf(p, 1):
+			d = math.Max(d, v)
+		case p == 1:
+			d += v
+		case p == 2:
+			d += v * v
+		default:
+			d += math.Pow(v, p)
+		}
+	}
+	switch {
+	case math.IsInf(p, 1) || p == 1:
+		return d
+	case p == 2:
+		return math.Sqrt(d)
+	}
+	return math.Pow(d, 1/p)
+}
@@ -0,0 +1,35 @@
+package neighbors
+
+import (
+	"math"
+	"testing"
+
+	"github.com/pa-m/sklearn/base"
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestNearestNeighbors_Float32(t *testing.T) {
+	X := mat.NewDense(30, 4, nil)
+	X.Apply(func(i, j int, v float64) float64 { return float64(i) + rand.Float64() }, X)
+	X = base.General32Of(X).ToDense()
+	for _, p := range []float64{1, 2, 3, math.Inf(1)} {
+		dense, f32 := NewNearestNeighbors(), NewNearestNeighbors()
+		dense.Algorithm, dense.P, f32.P = "brute", p, p
+		dense.Fit(X, nil)
+		f32.Fit(base.General32Of(X), nil)
+		dd, di := dense.KNeighbors(X, 3)
+		fd, fi := f32.KNeighbors(base.General32Of(X), 3)
+		if !mat.EqualApprox(dd, fd, 1e-10) || !mat.Equal(di, fi) {
+			t.Errorf("p=%g: dense and float32 neighbors differ", p)
+		}
+	}
+	Y := mat.NewDense(30, 1, nil)
+	Y.Apply(func(i, j int, v float64) float64 { return float64(i / 10) }, Y)
+	dense, f32 := NewKNeighborsRegressor(3, "uniform"), NewKNeighborsRegressor(3, "uniform")
+	dense.Fit(X, Y)
+	f32.Fit(base.General32Of(X), Y)
+	if !mat.EqualApprox(dense.Predict(X, nil), f32.Predict(base.General32Of(X), nil), 1e-10) {
+		t.Error("dense and float32 predictions differ")
+	}
+}
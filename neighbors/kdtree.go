@@ -145,8 +145,8 @@ func (r *Rectangle) MaxDistanceRectangle(other *Rectangle, p float64) float64 {
 type KDTree struct {
 	Data        *mat.Dense
 	LeafSize    int
-	Maxes, Mins []float64
-	Tree        Node
+	Maxes, Mins []float64 `param:"-"`
+	Tree        Node      `persist:"-" param:"-"`
 }
 
 // NewKDTree ...
@@ -1,6 +1,7 @@
 package neighbors
 
 import (
+	"fmt"
 	"runtime"
 
 	"github.com/pa-m/sklearn/base"
@@ -14,14 +15,13 @@ import (
 // The target is predicted by local interpolation of the targets
 // associated of the nearest neighbors in the training set.
 type NearestCentroid struct {
-	base.Predicter
 	Metric          string
 	ShrinkThreshold float64
 	// runtime filled members
 	NearestNeighbors
-	Classes    [][]float64
-	ClassCount [][]int
-	Centroids  *mat.Dense
+	Classes    [][]float64 `param:"-"`
+	ClassCount [][]int     `param:"-"`
+	Centroids  *mat.Dense  `param:"-"`
 }
 
 // NewNearestCentroid ...
@@ -30,13 +30,27 @@ func NewNearestCentroid(metric string, shrinkThreshold float64) *NearestCentroid
 	return &NearestCentroid{Metric: metric, ShrinkThreshold: shrinkThreshold}
 }
 
+// PredicterClone returns an unfitted copy of predicter
+func (m *NearestCentroid) PredicterClone() base.Predicter {
+	clone := *m
+	clone.NearestNeighbors.resetFitted()
+	clone.Classes, clone.ClassCount, clone.Centroids = nil, nil, nil
+	return base.DeepCopy(&clone).(*NearestCentroid)
+}
+
+// IsClassifier returns true for NearestCentroid
+func (*NearestCentroid) IsClassifier() bool { return true }
+
+// GetNOutputs returns 1 for NearestCentroid
+func (*NearestCentroid) GetNOutputs() int { return 1 }
+
 // Fit ...
 func (m *NearestCentroid) Fit(Xmatrix, Ymatrix mat.Matrix) base.Fiter {
 	X, Y := base.ToDense(Xmatrix), base.ToDense(Ymatrix)
 	NSamples, NFeatures := X.Dims()
 	_, NOutputs := Y.Dims()
 	if NOutputs != 1 {
-		panic("NearestCentroid can't handle output Dim != 1")
+		panic(fmt.Errorf("%w: NearestCentroid can't handle output Dim != 1", base.ErrShapeMismatch))
 	}
 	m.Classes, m.ClassCount = getClasses(Y)
 	NClasses := len(m.Classes[0])
@@ -65,31 +79,48 @@ func (m *NearestCentroid) Fit(Xmatrix, Ymatrix mat.Matrix) base.Fiter {
 			Centroids.Set(icl, feature, centroidXfeat)
 		}
 	})
+	m.Centroids = Centroids
 	m.NearestNeighbors.Fit(Centroids, mat.Matrix(nil))
 	return m
 }
 
 // Predict  for NearestCentroid
 func (m *NearestCentroid) Predict(X mat.Matrix, Ymutable mat.Mutable) *mat.Dense {
+	base.MustBeFitted(m)
 	Y := base.ToDense(Ymutable)
 	nSamples, _ := X.Dims()
 	if Y.IsZero() {
 		*Y = *mat.NewDense(nSamples, m.GetNOutputs(), nil)
 	}
 
-	m._predict(base.ToDense(X), Y, false)
+	m._predict(base.ToDense(X), Y)
 	return base.FromDense(Ymutable, Y)
 }
 
-// PredictProba for NearestCentroid
-func (m *NearestCentroid) PredictProba(X mat.Matrix, Y *mat.Dense) *NearestCentroid {
-	return m._predict(X, Y, true)
+// DecisionFunction for NearestCentroid returns the opposite of the squared distance of samples to each class centroid
+func (m *NearestCentroid) DecisionFunction(X mat.Matrix, Ymutable mat.Mutable) *mat.Dense {
+	base.MustBeFitted(m)
+	NSamples, _ := X.Dims()
+	NClasses := len(m.Classes[0])
+	distances, indices := m.KNeighbors(X, NClasses)
+	D := mat.NewDense(NSamples, NClasses, nil)
+	for sample := 0; sample < NSamples; sample++ {
+		for k := 0; k < NClasses; k++ {
+			d := distances.At(sample, k)
+			D.Set(sample, int(indices.At(sample, k)), -d*d)
+		}
+	}
+	return base.FromDense(Ymutable, D)
+}
+
+// PredictProba for NearestCentroid is the softmax of DecisionFunction. see base.ProbaPredicter
+func (m *NearestCentroid) PredictProba(X mat.Matrix, Ymutable mat.Mutable) *mat.Dense {
+	P := m.DecisionFunction(X, nil)
+	base.SoftmaxRows(P)
+	return base.FromDense(Ymutable, P)
 }
 
-func (m *NearestCentroid) _predict(X mat.Matrix, Y *mat.Dense, wantProba bool) *NearestCentroid {
-	if wantProba {
-		panic("PredictProba is undefined for NearestCentroid")
-	}
+func (m *NearestCentroid) _predict(X mat.Matrix, Y *mat.Dense) *NearestCentroid {
 	NSamples, _ := X.Dims()
 	_, indices := m.KNeighbors(X, 1)
 	for sample := 0; sample < NSamples; sample++ {
@@ -100,6 +131,22 @@ func (m *NearestCentroid) _predict(X mat.Matrix, Y *mat.Dense, wantProba bool) *
 	return m
 }
 
+// FitE is Fit returning an error instead of panicking
+func (m *NearestCentroid) FitE(X, Y mat.Matrix) error {
+	return base.FitE(m, X, Y)
+}
+
+// PredictE is Predict returning an error instead of panicking
+func (m *NearestCentroid) PredictE(X mat.Matrix, Y mat.Mutable) (*mat.Dense, error) {
+	if m.X == nil {
+		return nil, base.ErrNotFitted
+	}
+	if err := base.CheckNFeatures(X, m.X.RawMatrix().Cols); err != nil {
+		return nil, err
+	}
+	return base.PredictE(m, X, Y)
+}
+
 // Score for NearestCentroid
 func (m *NearestCentroid) Score(X, Y mat.Matrix) float64 {
 	NSamples, NOutputs := Y.Dims()
@@ -2,7 +2,10 @@ package neighbors
 
 import (
 	"fmt"
+	"math"
+	"testing"
 
+	"gonum.org/v1/gonum/floats"
 	"gonum.org/v1/gonum/mat"
 )
 
@@ -20,3 +23,21 @@ func ExampleNearestCentroid() {
 	// Output:
 	// [1]
 }
+
+func TestNearestCentroid_PredictProba(t *testing.T) {
+	X := mat.NewDense(6, 2, []float64{-1, -1, -2, -1, -3, -2, 1, 1, 2, 1, 3, 2})
+	Y := mat.NewDense(6, 1, []float64{1, 1, 1, 2, 2, 2})
+	clf := NewNearestCentroid("euclidean", 0.)
+	clf.Fit(X, Y)
+	Ypred := mat.NewDense(6, 1, nil)
+	clf.Predict(X, Ypred)
+	P := clf.PredictProba(X, nil)
+	for i := 0; i < 6; i++ {
+		if s := floats.Sum(P.RawRowView(i)); math.Abs(s-1) > 1e-12 {
+			t.Errorf("row %d probabilities sum to %g", i, s)
+		}
+		if c := clf.Classes[0][floats.MaxIdx(P.RawRowView(i))]; c != Ypred.At(i, 0) {
+			t.Errorf("row %d: expected class %g, got %g", i, Ypred.At(i, 0), c)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package neighbors
+
+import "github.com/pa-m/sklearn/base"
+
+// GetParams returns the parameters of KNeighborsClassifier. see base.GetFieldParams
+func (m *KNeighborsClassifier) GetParams() map[string]interface{} { return base.GetFieldParams(m) }
+
+// SetParams sets the parameters of KNeighborsClassifier. see base.SetFieldParams
+func (m *KNeighborsClassifier) SetParams(params map[string]interface{}) error {
+	return base.SetFieldParams(m, params)
+}
+
+// GetParams returns the parameters of KNeighborsRegressor. see base.GetFieldParams
+func (m *KNeighborsRegressor) GetParams() map[string]interface{} { return base.GetFieldParams(m) }
+
+// SetParams sets the parameters of KNeighborsRegressor. see base.SetFieldParams
+func (m *KNeighborsRegressor) SetParams(params map[string]interface{}) error {
+	return base.SetFieldParams(m, params)
+}
+
+// GetParams returns the parameters of NearestCentroid. see base.GetFieldParams
+func (m *NearestCentroid) GetParams() map[string]interface{} { return base.GetFieldParams(m) }
+
+// SetParams sets the parameters of NearestCentroid. see base.SetFieldParams
+func (m *NearestCentroid) SetParams(params map[string]interface{}) error {
+	return base.SetFieldParams(m, params)
+}
+
+// GetParams returns the parameters of NearestNeighbors. see base.GetFieldParams
+func (m *NearestNeighbors) GetParams() map[string]interface{} { return base.GetFieldParams(m) }
+
+// SetParams sets the parameters of NearestNeighbors. see base.SetFieldParams
+func (m *NearestNeighbors) SetParams(params map[string]interface{}) error {
+	return base.SetFieldParams(m, params)
+}
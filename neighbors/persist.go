@@ -0,0 +1,114 @@
+package neighbors
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/pa-m/sklearn/base"
+)
+
+func init() {
+	base.Register(&NearestNeighbors{})
+	base.Register(&KDTree{})
+	base.Register(&KNeighborsClassifier{})
+	base.Register(&KNeighborsRegressor{})
+	base.Register(&NearestCentroid{})
+}
+
+// MarshalState allows NearestNeighbors to be saved by base.Save
+func (m *NearestNeighbors) MarshalState() (*base.State, error) { return base.MarshalFields(m) }
+
+// UnmarshalState restores a NearestNeighbors saved by base.Save. Distance is set from Metric and P
+func (m *NearestNeighbors) UnmarshalState(st *base.State) error {
+	if err := base.UnmarshalFields(m, st); err != nil {
+		return err
+	}
+	m.restoreDistance()
+	return nil
+}
+
+// restoreDistance sets Distance of a loaded fitted NearestNeighbors
+func (m *NearestNeighbors) restoreDistance() {
+	if m.X != nil || m.SparseX != nil || m.X32.Data != nil {
+		m.setDistance()
+	}
+}
+
+// MarshalState allows KDTree to be saved by base.Save. the tree itself is rebuilt on load
+func (tr *KDTree) MarshalState() (*base.State, error) { return base.MarshalFields(tr) }
+
+// UnmarshalState restores a KDTree saved by base.Save
+func (tr *KDTree) UnmarshalState(st *base.State) error {
+	if err := base.UnmarshalFields(tr, st); err != nil {
+		return err
+	}
+	if tr.Data != nil && !tr.Data.IsZero() {
+		*tr = *NewKDTree(tr.Data, tr.LeafSize)
+	}
+	return nil
+}
+
+// checkPersistable returns base.ErrNotPersistable for a Distance other than EuclideanDistance, which is restored on load
+func checkPersistable(distance Distance) error {
+	if distance != nil && reflect.ValueOf(distance).Pointer() != reflect.ValueOf(EuclideanDistance).Pointer() {
+		return fmt.Errorf("%w: Distance func", base.ErrNotPersistable)
+	}
+	return nil
+}
+
+// MarshalState allows KNeighborsClassifier to be saved by base.Save. Distance must be nil or EuclideanDistance
+func (m *KNeighborsClassifier) MarshalState() (*base.State, error) {
+	if err := checkPersistable(m.Distance); err != nil {
+		return nil, err
+	}
+	st, err := base.MarshalFields(m)
+	if err == nil {
+		err = st.Set("nOutputs", m.nOutputs)
+	}
+	return st, err
+}
+
+// UnmarshalState restores a KNeighborsClassifier saved by base.Save
+func (m *KNeighborsClassifier) UnmarshalState(st *base.State) error {
+	if err := base.UnmarshalFields(m, st); err != nil {
+		return err
+	}
+	if m.Xscaled != nil || m.SparseX != nil || m.X32.Data != nil {
+		m.Distance = EuclideanDistance
+	}
+	m.NearestNeighbors.restoreDistance()
+	_, err := st.Get("nOutputs", &m.nOutputs)
+	return err
+}
+
+// MarshalState allows KNeighborsRegressor to be saved by base.Save. Distance must be nil or EuclideanDistance
+func (m *KNeighborsRegressor) MarshalState() (*base.State, error) {
+	if err := checkPersistable(m.Distance); err != nil {
+		return nil, err
+	}
+	return base.MarshalFields(m)
+}
+
+// UnmarshalState restores a KNeighborsRegressor saved by base.Save
+func (m *KNeighborsRegressor) UnmarshalState(st *base.State) error {
+	if err := base.UnmarshalFields(m, st); err != nil {
+		return err
+	}
+	if m.Xscaled != nil || m.SparseX != nil || m.X32.Data != nil {
+		m.Distance = EuclideanDistance
+	}
+	m.NearestNeighbors.restoreDistance()
+	return nil
+}
+
+// MarshalState allows NearestCentroid to be saved by base.Save
+func (m *NearestCentroid) MarshalState() (*base.State, error) { return base.MarshalFields(m) }
+
+// UnmarshalState restores a NearestCentroid saved by base.Save
+func (m *NearestCentroid) UnmarshalState(st *base.State) error {
+	if err := base.UnmarshalFields(m, st); err != nil {
+		return err
+	}
+	m.NearestNeighbors.restoreDistance()
+	return nil
+}
@@ -0,0 +1,52 @@
+package neighbors
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/pa-m/sklearn/base"
+	"github.com/pa-m/sklearn/datasets"
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestSaveLoad(t *testing.T) {
+	ds := datasets.LoadIris()
+	knn := NewKNeighborsClassifier(3, "distance")
+	knn.Algorithm = "kd_tree"
+	for _, m := range []base.Predicter{knn, NewKNeighborsRegressor(3, "uniform"), NewNearestCentroid("", 0)} {
+		m.Fit(ds.X, ds.Y)
+		buf := new(bytes.Buffer)
+		if err := base.Save(buf, m.(base.Persister)); err != nil {
+			t.Fatalf("%T: %s", m, err)
+		}
+		loaded, err := base.Load(buf)
+		if err != nil {
+			t.Fatalf("%T: %s", m, err)
+		}
+		nSamples, _ := ds.X.Dims()
+		expected, actual := mat.NewDense(nSamples, 1, nil), mat.NewDense(nSamples, 1, nil)
+		m.Predict(ds.X, expected)
+		loaded.Predict(ds.X, actual)
+		if !mat.EqualApprox(expected, actual, 1e-12) {
+			t.Errorf("%T: loaded model predictions differ", m)
+		}
+	}
+}
+
+func TestKDTree_UnmarshalState(t *testing.T) {
+	X := datasets.LoadIris().X
+	tr := NewKDTree(X, 10)
+	st, err := tr.MarshalState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr2 := &KDTree{}
+	if err := tr2.UnmarshalState(st); err != nil {
+		t.Fatal(err)
+	}
+	d1, i1 := tr.Query(X, 3, 1e-15, 2, 1e300)
+	d2, i2 := tr2.Query(X, 3, 1e-15, 2, 1e300)
+	if !mat.Equal(d1, d2) || !mat.Equal(i1, i2) {
+		t.Error("rebuilt KDTree query differs")
+	}
+}
@@ -20,9 +20,10 @@ type KNeighborsRegressor struct {
 	K        int
 	Weight   string
 	Scale    bool
-	Distance Distance
+	Distance Distance `persist:"-"`
 	// Runtime members
-	Xscaled, Y *mat.Dense
+	Xscaled, Y   *mat.Dense `param:"-"`
+	SampleWeight []float64  `param:"-"`
 }
 
 // NewKNeighborsRegressor returns an initialized *KNeighborsRegressor
@@ -30,25 +31,35 @@ func NewKNeighborsRegressor(K int, Weights string) base.Predicter {
 	return &KNeighborsRegressor{NearestNeighbors: *NewNearestNeighbors(), K: K, Weight: Weights}
 }
 
-// PredicterClone return a (possibly unfitted) copy of predicter
+// PredicterClone returns an unfitted copy of predicter
 func (m *KNeighborsRegressor) PredicterClone() base.Predicter {
 	clone := *m
-	return &clone
+	clone.NearestNeighbors.resetFitted()
+	clone.Xscaled, clone.Y, clone.SampleWeight = nil, nil, nil
+	return base.DeepCopy(&clone).(*KNeighborsRegressor)
 }
 
 // IsClassifier returns false for KNeighborsRegressor
 func (*KNeighborsRegressor) IsClassifier() bool { return false }
 
 // Fit ...
-func (m *KNeighborsRegressor) Fit(Xmatrix, Ymatrix mat.Matrix) base.Fiter {
-	X, Y := base.ToDense(Xmatrix), base.ToDense(Ymatrix)
-	m.Xscaled = mat.DenseCopyOf(X)
+func (m *KNeighborsRegressor) Fit(X, Y mat.Matrix) base.Fiter {
+	return m.FitWeighted(X, Y, nil)
+}
+
+// FitWeighted is Fit with the targets of each neighbor weighted by its sample weight
+func (m *KNeighborsRegressor) FitWeighted(X, Y mat.Matrix, sampleWeight []float64) base.Fiter {
+	m.SampleWeight = sampleWeight
+	m.Xscaled = nil
+	if !base.IsSparse(X) && !base.IsFloat32(X) {
+		m.Xscaled = mat.DenseCopyOf(X)
+	}
 	m.Y = mat.DenseCopyOf(Y)
 	if m.Distance == nil {
 		m.Distance = EuclideanDistance
 	}
 	if m.K <= 0 {
-		panic(fmt.Errorf("K<=0"))
+		panic(fmt.Errorf("%w: K<=0", base.ErrInvalidParam))
 	}
 	m.NearestNeighbors.Fit(X, Y)
 	return m
@@ -59,13 +70,14 @@ func (m *KNeighborsRegressor) GetNOutputs() int { return m.Y.RawMatrix().Cols }
 
 // Predict ...
 func (m *KNeighborsRegressor) Predict(X mat.Matrix, Ymutable mat.Mutable) *mat.Dense {
+	base.MustBeFitted(m)
 	Y := base.ToDense(Ymutable)
 	nSamples, _ := X.Dims()
 	if Y.IsZero() {
 		*Y = *mat.NewDense(nSamples, m.GetNOutputs(), nil)
 	}
 
-	NFitSamples, _ := m.Xscaled.Dims()
+	NFitSamples := m.nFitSamples()
 	NX, _ := X.Dims()
 	_, outputs := m.Y.Dims()
 
@@ -79,19 +91,21 @@ func (m *KNeighborsRegressor) Predict(X mat.Matrix, Ymutable mat.Mutable) *mat.D
 		weights := make([]float64, m.K)
 		ys := make([]float64, m.K)
 		epsilon := 1e-15
-		for ik := range weights {
-			weights[ik] = 1.
-		}
 		for sample := start; sample < end; sample++ {
 			// sort idx to get first K nearest
 			sort.Slice(idx, func(i, j int) bool { return d2[idx[i]] < d2[idx[j]] })
 			// set Y(sample,output) to weighted average of K nearest
 			for o := 0; o < outputs; o++ {
 				for ik := range ys {
-					ys[ik] = m.Y.At(int(indices.At(sample, ik)), o)
+					neighbor := int(indices.At(sample, ik))
+					ys[ik] = m.Y.At(neighbor, o)
+					weights[ik] = 1.
 					if isWeightDistance {
 						weights[ik] = 1. / (epsilon + distances.At(sample, ik))
 					}
+					if m.SampleWeight != nil {
+						weights[ik] *= m.SampleWeight[neighbor]
+					}
 				}
 				Y.Set(sample, o, stat.Mean(ys, weights))
 			}
@@ -101,6 +115,25 @@ func (m *KNeighborsRegressor) Predict(X mat.Matrix, Ymutable mat.Mutable) *mat.D
 	return base.FromDense(Ymutable, Y)
 }
 
+// FitE is Fit returning an error instead of panicking
+func (m *KNeighborsRegressor) FitE(X, Y mat.Matrix) error {
+	if m.K <= 0 {
+		return fmt.Errorf("%w: K must be positive, got %d", base.ErrInvalidParam, m.K)
+	}
+	if n, _ := X.Dims(); m.K > n {
+		return fmt.Errorf("%w: K %d > NSamples %d", base.ErrInvalidParam, m.K, n)
+	}
+	return base.FitE(m, X, Y)
+}
+
+// PredictE is Predict returning an error instead of panicking
+func (m *KNeighborsRegressor) PredictE(X mat.Matrix, Y mat.Mutable) (*mat.Dense, error) {
+	if err := m.NearestNeighbors.checkFitted(X); err != nil {
+		return nil, err
+	}
+	return base.PredictE(m, X, Y)
+}
+
 // Score for KNeighborsRegressor
 func (m *KNeighborsRegressor) Score(X, Y mat.Matrix) float64 {
 	NSamples, NOutputs := Y.Dims()
@@ -0,0 +1,113 @@
+package neighbors
+
+import (
+	"math"
+	"sort"
+
+	"github.com/pa-m/sklearn/base"
+	"gonum.org/v1/gonum/mat"
+)
+
+// nFitSamples returns the number of fitted samples
+func (m *NearestNeighbors) nFitSamples() int {
+	if m.SparseX != nil {
+		return m.SparseX.Rows
+	}
+	if m.X32.Data != nil {
+		return m.X32.Rows
+	}
+	r, _ := m.X.Dims()
+	return r
+}
+
+// IsFitted returns true when X has been fitted. see base.FittedChecker
+func (m *NearestNeighbors) IsFitted() bool {
+	return m.X != nil || m.SparseX != nil || m.X32.Data != nil
+}
+
+// resetFitted clears the fitted samples
+func (m *NearestNeighbors) resetFitted() {
+	m.X, m.Y, m.Tree, m.SparseX, m.X32 = nil, nil, nil, nil, base.General32{}
+}
+
+// checkFitted returns base.ErrNotFitted if m is not fitted, or base.ErrShapeMismatch if X has not the fitted features number
+func (m *NearestNeighbors) checkFitted(X mat.Matrix) error {
+	switch {
+	case m.SparseX != nil:
+		return base.CheckNFeatures(X, m.SparseX.Cols)
+	case m.X32.Data != nil:
+		return base.CheckNFeatures(X, m.X32.Cols)
+	case m.X != nil:
+		return base.CheckNFeatures(X, m.X.RawMatrix().Cols)
+	}
+	return base.ErrNotFitted
+}
+
+// sparseKNeighbors is the brute force KNeighbors for a sparse fitted X. X is not densified
+func (m *NearestNeighbors) sparseKNeighbors(Xmatrix mat.Matrix, NNeighbors int) (distances, indices *mat.Dense) {
+	X := base.ToCSR(Xmatrix)
+	return m.bruteKNeighbors(X.Rows, NNeighbors, func(sample, ifs int) float64 {
+		return SparseMinkowskiDistance(X.Row(sample), m.SparseX.Row(ifs), m.P)
+	})
+}
+
+// bruteKNeighbors returns the distances and indices of the NNeighbors nearest fitted samples of each of NSamples samples
+func (m *NearestNeighbors) bruteKNeighbors(NSamples, NNeighbors int, distance func(sample, ifs int) float64) (distances, indices *mat.Dense) {
+	NFitSamples := m.nFitSamples()
+	distances = mat.NewDense(NSamples, NNeighbors, nil)
+	indices = mat.NewDense(NSamples, NNeighbors, nil)
+	base.Parallelize(m.NJobs, NSamples, func(th, start, end int) {
+		idx := make([]int, NFitSamples)
+		sampleDistance := make([]float64, NFitSamples)
+		for sample := start; sample < end; sample++ {
+			for ifs := range idx {
+				sampleDistance[ifs] = distance(sample, ifs)
+				idx[ifs] = ifs
+			}
+			sort.Slice(idx, func(i, j int) bool { return sampleDistance[idx[i]] < sampleDistance[idx[j]] })
+			for ik := 0; ik < NNeighbors; ik++ {
+				indices.Set(sample, ik, float64(idx[ik]))
+				distances.Set(sample, ik, sampleDistance[idx[ik]])
+			}
+		}
+	})
+	return
+}
+
+// SparseMinkowskiDistance returns the minkowski distance of power p between sparse vectors a and b
+func SparseMinkowskiDistance(a, b base.SparseVector, p float64) float64 {
+	var d float64
+	for pa, pb := 0, 0; pa < len(a.Indices) || pb < len(b.Indices); {
+		var v float64
+		switch {
+		case pb == len(b.Indices) || (pa < len(a.Indices) && a.Indices[pa] < b.Indices[pb]):
+			v = a.Data[pa]
+			pa++
+		case pa == len(a.Indices) || a.Indices[pa] > b.Indices[pb]:
+			v = b.Data[pb]
+			pb++
+		default:
+			v = a.Data[pa] - b.Data[pb]
+			pa++
+			pb++
+		}
+		v = math.Abs(v)
+		switch {
+		case math.IsInf(p, 1):
+			d = math.Max(d, v)
+		case p == 1:
+			d += v
+		case p == 2:
+			d += v * v
+		default:
+			d += math.Pow(v, p)
+		}
+	}
+	switch {
+	case math.IsInf(p, 1) || p == 1:
+		return d
+	case p == 2:
+		return math.Sqrt(d)
+	}
+	return math.Pow(d, 1/p)
+}
@@ -0,0 +1,46 @@
+package neighbors
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/pa-m/sklearn/base"
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestNearestNeighbors_Sparse(t *testing.T) {
+	X := mat.NewDense(20, 6, nil)
+	X.Apply(func(i, j int, v float64) float64 {
+		if j == i%6 {
+			return float64(i + 1)
+		}
+		if rand.Float64() < .4 {
+			return rand.NormFloat64()
+		}
+		return 0
+	}, X)
+	for _, p := range []float64{1, 2, 3, math.Inf(1)} {
+		dense, sparse := NewNearestNeighbors(), NewNearestNeighbors()
+		dense.Algorithm, dense.P, sparse.P = "brute", p, p
+		dense.Fit(X, nil)
+		sparse.Fit(base.ToCSR(X), nil)
+		dd, di := dense.KNeighbors(X, 3)
+		sd, si := sparse.KNeighbors(base.ToCSR(X), 3)
+		if !mat.EqualApprox(dd, sd, 1e-12) || !mat.Equal(di, si) {
+			t.Errorf("p=%g: dense and sparse neighbors differ", p)
+		}
+	}
+	Y := mat.NewDense(20, 1, nil)
+	Y.Apply(func(i, j int, v float64) float64 { return float64(i % 3) }, Y)
+	dense, sparse := NewKNeighborsClassifier(3, "distance"), NewKNeighborsClassifier(3, "distance")
+	dense.Fit(X, Y)
+	sparse.Fit(base.ToCSR(X), Y)
+	if !mat.Equal(dense.PredictProba(X, nil), sparse.PredictProba(base.ToCSR(X), nil)) {
+		t.Error("dense and sparse probabilities differ")
+	}
+	if _, err := sparse.PredictE(mat.NewDense(1, 5, nil), nil); !errors.Is(err, base.ErrShapeMismatch) {
+		t.Error("expected ErrShapeMismatch for a wrong features number")
+	}
+}
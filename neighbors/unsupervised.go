@@ -1,6 +1,7 @@
 package neighbors
 
 import (
+	"fmt"
 	"math"
 	"runtime"
 	"sort"
@@ -24,9 +25,13 @@ type NearestNeighbors struct {
 	NJobs     int
 	LeafSize  int
 	// Runtime filled members
-	Distance func(a, b mat.Vector) float64
-	X, Y     *mat.Dense
-	Tree     *KDTree
+	Distance func(a, b mat.Vector) float64 `persist:"-" param:"-"`
+	X, Y     *mat.Dense                    `param:"-"`
+	Tree     *KDTree                       `param:"-"`
+	// SparseX is the fitted X instead of X when Fit was called with a *base.CSR or a *base.CSC. it is searched by brute force
+	SparseX *base.CSR `param:"-"`
+	// X32 is the fitted X instead of X when Fit was called with a base.General32. it is searched by brute force
+	X32 base.General32 `param:"-"`
 }
 
 // NewNearestNeighbors returns an *NearestNeighbors
@@ -37,19 +42,19 @@ func NewNearestNeighbors() *NearestNeighbors {
 // Fit for NearestNeighbors. Y is unused
 func (m *NearestNeighbors) Fit(X, Y mat.Matrix) {
 	r, c := X.Dims()
-	switch m.Metric {
-	case "manhattan", "cityblock":
-		m.P = 1
-		m.Distance = MinkowskiDistance(m.P)
-	case "euclidean":
-		m.P = 2
-		m.Distance = MinkowskiDistance(m.P)
-	default:
-		m.Distance = MinkowskiDistance(m.P)
-	}
+	m.setDistance()
 	if m.NJobs < 0 {
 		m.NJobs = runtime.NumCPU()
 	}
+	m.X, m.SparseX, m.X32, m.Tree = nil, nil, base.General32{}, nil
+	if base.IsSparse(X) {
+		m.SparseX = base.ToCSR(X)
+		return
+	}
+	if base.IsFloat32(X) {
+		m.X32 = base.General32Of(X)
+		return
+	}
 	m.X = mat.DenseCopyOf(X)
 	useKDTree := strings.Contains(strings.ToLower(m.Algorithm), "tree") || (m.Algorithm == "auto" && r*c > 1000)
 	if useKDTree {
@@ -60,9 +65,42 @@ func (m *NearestNeighbors) Fit(X, Y mat.Matrix) {
 	}
 }
 
+// setDistance sets P and Distance according to Metric
+func (m *NearestNeighbors) setDistance() {
+	switch m.Metric {
+	case "manhattan", "cityblock":
+		m.P = 1
+	case "euclidean":
+		m.P = 2
+	}
+	m.Distance = MinkowskiDistance(m.P)
+}
+
+// FitE is Fit returning an error instead of panicking
+func (m *NearestNeighbors) FitE(X, Y mat.Matrix) (err error) {
+	if err = base.CheckXY(X, Y); err != nil {
+		return
+	}
+	defer base.Recover(&err)
+	m.Fit(X, Y)
+	return
+}
+
 // KNeighbors returns distances and indices of first NNeighbors
 func (m *NearestNeighbors) KNeighbors(X mat.Matrix, NNeighbors int) (distances, indices *mat.Dense) {
 	NSamples, NFeatures := X.Dims()
+	if m.checkFitted(X) == base.ErrNotFitted {
+		panic(base.ErrNotFitted)
+	}
+	if NFitSamples := m.nFitSamples(); NNeighbors > NFitSamples {
+		panic(fmt.Errorf("%w: NNeighbors %d > NSamples %d", base.ErrInvalidParam, NNeighbors, NFitSamples))
+	}
+	if m.SparseX != nil {
+		return m.sparseKNeighbors(X, NNeighbors)
+	}
+	if m.X32.Data != nil {
+		return m.float32KNeighbors(X, NNeighbors)
+	}
 	if m.Tree != nil {
 		return m.Tree.Query(X, NNeighbors, 1e-15, m.P, math.Inf(1))
 	}
@@ -101,7 +139,7 @@ func (m *NearestNeighbors) KNeighbors(X mat.Matrix, NNeighbors int) (distances,
 //     n_samples_fit is the number of samples in the fitted data A[i, j] is assigned the weight of edge that connects i to j.
 func (m *NearestNeighbors) KNeighborsGraph(X *mat.Dense, NNeighbors int, mode string, includeSelf bool) (graph *mat.Dense) {
 	NSamples, _ := X.Dims()
-	NSamplesFit, _ := m.X.Dims()
+	NSamplesFit := m.nFitSamples()
 	distances, indices := m.KNeighbors(X, NNeighbors)
 	graph = mat.NewDense(NSamples, NSamplesFit, nil)
 	var source *mat.Dense
@@ -145,7 +183,7 @@ func (m *NearestNeighbors) RadiusNeighbors(X *mat.Dense, radius float64) (distan
 	NSamples, _ := X.Dims()
 	distances = make([][]float64, NSamples)
 	indices = make([][]int, NSamples)
-	NFitSamples, _ := m.X.Dims()
+	NFitSamples := m.nFitSamples()
 	if m.Tree == nil {
 		Mdistances, Mindices := m.KNeighbors(X, NFitSamples)
 		base.Parallelize(m.NJobs, NSamples, func(th, start, end int) {
@@ -4,7 +4,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
-	"reflect"
 	"runtime"
 	"sort"
 	"strings"
@@ -50,21 +49,21 @@ type BaseMultilayerPerceptron64 struct {
 	NIterNoChange      int              `json:"n_iter_no_change"`
 
 	// Outputs
-	NLayers       int
-	NIter         int
-	NOutputs      int
-	Intercepts    [][]float64     `json:"intercepts_"`
-	Coefs         []blas64General `json:"coefs_"`
-	OutActivation string          `json:"out_activation_"`
-	Loss          float64
+	NLayers       int             `param:"-"`
+	NIter         int             `param:"-"`
+	NOutputs      int             `param:"-"`
+	Intercepts    [][]float64     `json:"intercepts_" param:"-"`
+	Coefs         []blas64General `json:"coefs_" param:"-"`
+	OutActivation string          `json:"out_activation_" param:"-"`
+	Loss          float64         `param:"-"`
 
 	// internal
 	t                   int
-	LossCurve           []float64
-	ValidationScores    []float64
-	BestValidationScore float64
-	BestLoss            float64
-	NoImprovementCount  int
+	LossCurve           []float64 `param:"-"`
+	ValidationScores    []float64 `param:"-"`
+	BestValidationScore float64   `param:"-"`
+	BestLoss            float64   `param:"-"`
+	NoImprovementCount  int       `param:"-"`
 	optimizer           Optimizer64
 	packedParameters    []float64
 	packedGrads         []float64 // packedGrads allow tests to check gradients
@@ -73,6 +72,10 @@ type BaseMultilayerPerceptron64 struct {
 	lb                  *LabelBinarizer64
 	// beforeMinimize allow test to set weights
 	beforeMinimize func(optimize.Problem, []float64)
+	// mon is set during FitContext
+	mon *base.Monitor
+	// sampleWeight is set during FitWeighted
+	sampleWeight []float64
 }
 
 // Activations64 is a map containing the inplace_activation functions
@@ -195,6 +198,20 @@ var LossFunctions64 = map[string]func(y, h blas64General) float64{
 	},
 }
 
+// weightedLoss64 returns the mean of the losses of the rows of y and h weighted by sampleWeight
+func weightedLoss64(lossFunc func(y, h blas64General) float64, y, h blas64General, sampleWeight []float64) float64 {
+	sum := float64(0)
+	for row := 0; row < y.Rows; row++ {
+		if sampleWeight[row] == 0 {
+			continue
+		}
+		yrow := blas64General{Rows: 1, Cols: y.Cols, Stride: y.Stride, Data: y.Data[row*y.Stride : row*y.Stride+y.Cols]}
+		hrow := blas64General{Rows: 1, Cols: h.Cols, Stride: h.Stride, Data: h.Data[row*h.Stride : row*h.Stride+h.Cols]}
+		sum += sampleWeight[row] * lossFunc(yrow, hrow)
+	}
+	return sum / float64(y.Rows)
+}
+
 // Optimizer64 is an interface for stochastic optimizers
 type Optimizer64 interface {
 	iterationEnds(timeStep float64)
@@ -337,8 +354,9 @@ func (mlp *BaseMultilayerPerceptron64) computeLossGrad(layer, NSamples int, acti
 // deltas : []blas64General, length=NLayers-1
 // coefGrads : []blas64General, length=NLayers-1
 // interceptGrads : [][]float64, length=NLayers-1
+// sampleWeight : []float64, length=nSamples or nil
 
-func (mlp *BaseMultilayerPerceptron64) backprop(X, y blas64General, activations, deltas, coefGrads []blas64General, interceptGrads [][]float64) float64 {
+func (mlp *BaseMultilayerPerceptron64) backprop(X, y blas64General, activations, deltas, coefGrads []blas64General, interceptGrads [][]float64, sampleWeight []float64) float64 {
 	nSamples := X.Rows
 	if mlp.WeightDecay > 0 {
 		for iw := range mlp.packedParameters {
@@ -357,7 +375,12 @@ func (mlp *BaseMultilayerPerceptron64) backprop(X, y blas64General, activations,
 		lossFuncName = "binary_log_loss"
 	}
 	// y may have less rows than activations il last batch
-	loss := LossFunctions64[lossFuncName](y, activations[len(activations)-1])
+	var loss float64
+	if sampleWeight == nil {
+		loss = LossFunctions64[lossFuncName](y, activations[len(activations)-1])
+	} else {
+		loss = weightedLoss64(LossFunctions64[lossFuncName], y, activations[len(activations)-1], sampleWeight)
+	}
 	// # Add L2 regularization term to loss
 	loss += (0.5 * mlp.Alpha) * mlp.sumCoefSquares() / float64(nSamples)
 
@@ -375,8 +398,12 @@ func (mlp *BaseMultilayerPerceptron64) backprop(X, y blas64General, activations,
 		H := activations[len(activations)-1]
 		D := deltas[last]
 		for r, pos := 0, 0; r < y.Rows; r, pos = r+1, pos+y.Stride {
+			w := float64(1)
+			if sampleWeight != nil {
+				w = sampleWeight[r]
+			}
 			for o, posc := 0, pos; o < y.Cols; o, posc = o+1, posc+1 {
-				D.Data[posc] = H.Data[posc] - y.Data[posc]
+				D.Data[posc] = w * (H.Data[posc] - y.Data[posc])
 			}
 		}
 	}
@@ -484,11 +511,8 @@ func (mlp *BaseMultilayerPerceptron64) initialize(yCols int, layerUnits []int, i
 
 func (mlp *BaseMultilayerPerceptron64) fit(X, y blas64General, incremental bool) {
 	// # Validate input parameters.
-	mlp.validateHyperparameters()
-	for _, s := range mlp.HiddenLayerSizes {
-		if s < 0 {
-			log.Panicf("hiddenLayerSizes must be > 0, got %v.", mlp.HiddenLayerSizes)
-		}
+	if err := mlp.validateHyperparameters(); err != nil {
+		panic(err)
 	}
 	X, y = mlp.validateInput(X, y, incremental)
 	nSamples, nFeatures := X.Rows, X.Cols
@@ -599,6 +623,18 @@ func (mlp *BaseMultilayerPerceptron64) Fit(X, Y Matrix) {
 	mlp.fit(xb, yb, false)
 }
 
+// setSampleWeight sets the sample weights used by the next fit. nil unsets them
+func (mlp *BaseMultilayerPerceptron64) setSampleWeight(sampleWeight []float64) {
+	mlp.sampleWeight = nil
+	if sampleWeight == nil {
+		return
+	}
+	mlp.sampleWeight = make([]float64, len(sampleWeight))
+	for i, w := range sampleWeight {
+		mlp.sampleWeight[i] = float64(w)
+	}
+}
+
 // GetNOutputs returns output columns number for Y to pass to predict
 func (mlp *BaseMultilayerPerceptron64) GetNOutputs() int {
 	if mlp.lb != nil {
@@ -607,8 +643,22 @@ func (mlp *BaseMultilayerPerceptron64) GetNOutputs() int {
 	return mlp.NOutputs
 }
 
+// IsFitted returns true when Coefs have been fitted. see base.FittedChecker
+func (mlp *BaseMultilayerPerceptron64) IsFitted() bool { return len(mlp.Coefs) > 0 }
+
+// unfittedClone returns a deep copy of mlp without its fitted and internal state
+func (mlp *BaseMultilayerPerceptron64) unfittedClone() *BaseMultilayerPerceptron64 {
+	clone := *mlp
+	clone.NLayers, clone.NIter, clone.NOutputs, clone.Intercepts, clone.Coefs, clone.OutActivation, clone.Loss = 0, 0, 0, nil, nil, "", 0
+	clone.t, clone.LossCurve, clone.ValidationScores, clone.BestValidationScore, clone.BestLoss, clone.NoImprovementCount = 0, nil, nil, 0, 0, 0
+	clone.optimizer, clone.packedParameters, clone.packedGrads, clone.bestParameters, clone.batchNorm, clone.lb = nil, nil, nil, nil, nil, nil
+	clone.mon = nil
+	return base.DeepCopy(&clone).(*BaseMultilayerPerceptron64)
+}
+
 // Predict do forward pass and fills Y (Y must be Mutable)
 func (mlp *BaseMultilayerPerceptron64) Predict(X mat.Matrix, Y Mutable) {
+	base.MustBeFitted(mlp)
 	var xb, yb General64
 	if xg, ok := X.(RawMatrixer64); ok {
 		if yg, ok := Y.(RawMatrixer64); ok {
@@ -623,33 +673,38 @@ func (mlp *BaseMultilayerPerceptron64) Predict(X mat.Matrix, Y Mutable) {
 	FromDense64(Y, yb)
 }
 
-func (mlp *BaseMultilayerPerceptron64) validateHyperparameters() {
+func (mlp *BaseMultilayerPerceptron64) validateHyperparameters() error {
 	if mlp.MaxIter <= 0 {
-		log.Panicf("maxIter must be > 0, got %d.", mlp.MaxIter)
+		return fmt.Errorf("%w: maxIter must be > 0, got %d", base.ErrInvalidParam, mlp.MaxIter)
 	}
 	if mlp.Alpha < 0.0 {
-		log.Panicf("alpha must be >= 0, got %g.", mlp.Alpha)
+		return fmt.Errorf("%w: alpha must be >= 0, got %g", base.ErrInvalidParam, mlp.Alpha)
 	}
 	if mlp.LearningRateInit <= 0.0 {
-		log.Panicf("learningRateInit must be > 0, got %g.", mlp.LearningRateInit)
+		return fmt.Errorf("%w: learningRateInit must be > 0, got %g", base.ErrInvalidParam, mlp.LearningRateInit)
 	}
 	if mlp.Momentum > 1 || mlp.Momentum < 0 {
-		log.Panicf("momentum must be >= 0 and <= 1, got %g", mlp.Momentum)
+		return fmt.Errorf("%w: momentum must be >= 0 and <= 1, got %g", base.ErrInvalidParam, mlp.Momentum)
 	}
 	if mlp.ValidationFraction < 0 || mlp.ValidationFraction >= 1 {
-		log.Panicf("validationFraction must be >= 0 and < 1, got %g", mlp.ValidationFraction)
+		return fmt.Errorf("%w: validationFraction must be >= 0 and < 1, got %g", base.ErrInvalidParam, mlp.ValidationFraction)
 	}
 	if mlp.Beta1 < 0 || mlp.Beta1 >= 1 {
-		log.Panicf("beta_1 must be >= 0 and < 1, got %g", mlp.Beta1)
+		return fmt.Errorf("%w: beta_1 must be >= 0 and < 1, got %g", base.ErrInvalidParam, mlp.Beta1)
 	}
 	if mlp.Beta2 < 0 || mlp.Beta2 >= 1 {
-		log.Panicf("beta_2 must be >= 0 and < 1, got %g", mlp.Beta2)
+		return fmt.Errorf("%w: beta_2 must be >= 0 and < 1, got %g", base.ErrInvalidParam, mlp.Beta2)
 	}
 	if mlp.Epsilon <= 0.0 {
-		log.Panicf("epsilon must be > 0, got %g.", mlp.Epsilon)
+		return fmt.Errorf("%w: epsilon must be > 0, got %g", base.ErrInvalidParam, mlp.Epsilon)
 	}
 	if mlp.NIterNoChange <= 0 {
-		log.Panicf("nIterNoChange must be > 0, got %d.", mlp.NIterNoChange)
+		return fmt.Errorf("%w: nIterNoChange must be > 0, got %d", base.ErrInvalidParam, mlp.NIterNoChange)
+	}
+	for _, s := range mlp.HiddenLayerSizes {
+		if s < 0 {
+			return fmt.Errorf("%w: hiddenLayerSizes must be > 0, got %v", base.ErrInvalidParam, mlp.HiddenLayerSizes)
+		}
 	}
 	//# raise ValueError if not registered
 
@@ -659,18 +714,19 @@ func (mlp *BaseMultilayerPerceptron64) validateHyperparameters() {
 	}
 
 	if _, ok := Activations64[mlp.Activation]; !ok {
-		log.Panicf("The activation \"%s\" is not supported. Supported activations are %s.", mlp.Activation, supportedActivations)
+		return fmt.Errorf("%w: the activation \"%s\" is not supported. Supported activations are %s", base.ErrInvalidParam, mlp.Activation, supportedActivations)
 	}
 	switch mlp.LearningRate {
 	case "constant", "invscaling", "adaptive":
 	default:
-		log.Panicf("learning rate %s is not supported.", mlp.LearningRate)
+		return fmt.Errorf("%w: learning rate %s is not supported", base.ErrInvalidParam, mlp.LearningRate)
 	}
 	switch mlp.Solver {
 	case "sgd", "adam", "lbfgs":
 	default:
-		log.Panicf("The solver %s is not supported.", mlp.Solver)
+		return fmt.Errorf("%w: The solver %s is not supported", base.ErrInvalidParam, mlp.Solver)
 	}
+	return nil
 }
 
 func (mlp *BaseMultilayerPerceptron64) fitLbfgs(X, y blas64General, activations, deltas, coefGrads []blas64General,
@@ -684,6 +740,9 @@ func (mlp *BaseMultilayerPerceptron64) fitLbfgs(X, y blas64General, activations,
 		},
 		Concurrent: runtime.GOMAXPROCS(0),
 	}
+	if mlp.mon != nil {
+		settings.Recorder = mlpRecorder64{mlp.mon}
+	}
 
 	var mu sync.Mutex // sync access to mlp.Loss on LossCurve
 	problem := optimize.Problem{
@@ -691,7 +750,7 @@ func (mlp *BaseMultilayerPerceptron64) fitLbfgs(X, y blas64General, activations,
 			for i := range w {
 				mlp.packedParameters[i] = float64(w[i])
 			}
-			loss := float64(mlp.backprop(X, y, activations, deltas, coefGrads, interceptGrads))
+			loss := float64(mlp.backprop(X, y, activations, deltas, coefGrads, interceptGrads, mlp.sampleWeight))
 			mu.Lock()
 			mlp.Loss = float64(loss)
 			mlp.LossCurve = append(mlp.LossCurve, mlp.Loss)
@@ -719,6 +778,9 @@ func (mlp *BaseMultilayerPerceptron64) fitLbfgs(X, y blas64General, activations,
 		mlp.beforeMinimize(problem, w)
 	}
 	res, err := optimize.Minimize(problem, w, settings, method)
+	if err != nil && err == mlp.mon.Err() {
+		return
+	}
 	if err != nil {
 		log.Panic(err)
 	}
@@ -727,6 +789,18 @@ func (mlp *BaseMultilayerPerceptron64) fitLbfgs(X, y blas64General, activations,
 	}
 }
 
+// mlpRecorder64 reports lbfgs major iterations to a base.Monitor and stops the optimization when it says so
+type mlpRecorder64 struct{ mon *base.Monitor }
+
+func (r mlpRecorder64) Init() error { return nil }
+
+func (r mlpRecorder64) Record(loc *optimize.Location, op optimize.Operation, stats *optimize.Stats) error {
+	if op == optimize.MajorIteration {
+		return r.mon.Report(stats.MajorIterations, loc.F)
+	}
+	return r.mon.Err()
+}
+
 func (mlp *BaseMultilayerPerceptron64) fitStochastic(X, y blas64General, activations, deltas, coefGrads []blas64General,
 	interceptGrads [][]float64, packedGrads []float64, layerUnits []int, incremental bool) {
 	if !incremental || mlp.optimizer == Optimizer64(nil) {
@@ -778,6 +852,10 @@ func (mlp *BaseMultilayerPerceptron64) fitStochastic(X, y blas64General, activat
 	} else {
 		rndShuffle = rand.New(mlp.RandomState).Shuffle
 	}
+	shuffled := indexedXY{idx: sort.IntSlice(idx), X: general64FastSwap(X), Y: general64FastSwap(y)}
+	if mlp.sampleWeight != nil {
+		shuffled.W = Float64Slice(mlp.sampleWeight)
+	}
 	func() {
 		if r := recover(); r != nil {
 			// ...
@@ -785,7 +863,7 @@ func (mlp *BaseMultilayerPerceptron64) fitStochastic(X, y blas64General, activat
 		}
 		for it := 0; it < mlp.MaxIter; it++ {
 			if mlp.Shuffle {
-				rndShuffle(nSamples, indexedXY{idx: sort.IntSlice(idx), X: general64FastSwap(X), Y: general64FastSwap(y)}.Swap)
+				rndShuffle(nSamples, shuffled.Swap)
 			}
 			accumulatedLoss := float64(0.0)
 			for batch := [2]int{0, batchSize}; batch[0] < nSamples-testSize; batch = [2]int{batch[1], batch[1] + batchSize} {
@@ -795,6 +873,10 @@ func (mlp *BaseMultilayerPerceptron64) fitStochastic(X, y blas64General, activat
 				// activations[0] = X[batchSlice]
 				Xbatch := blas64General(General64(X).RowSlice(batch[0], batch[1]))
 				Ybatch := blas64General(General64(y).RowSlice(batch[0], batch[1]))
+				var Wbatch []float64
+				if mlp.sampleWeight != nil {
+					Wbatch = mlp.sampleWeight[batch[0]:batch[1]]
+				}
 
 				activations[0] = Xbatch
 				for _, a := range activations {
@@ -802,7 +884,7 @@ func (mlp *BaseMultilayerPerceptron64) fitStochastic(X, y blas64General, activat
 				}
 
 				//X, y blas64General, activations, deltas, coefGrads []blas64General, interceptGrads
-				batchLoss := mlp.backprop(Xbatch, Ybatch, activations, deltas, coefGrads, interceptGrads)
+				batchLoss := mlp.backprop(Xbatch, Ybatch, activations, deltas, coefGrads, interceptGrads, Wbatch)
 				accumulatedLoss += batchLoss * float64(batch[1]-batch[0])
 
 				//# update weights
@@ -839,6 +921,9 @@ func (mlp *BaseMultilayerPerceptron64) fitStochastic(X, y blas64General, activat
 				mlp.NoImprovementCount = 0
 			}
 
+			if mlp.mon.Report(mlp.NIter, float64(mlp.Loss)) != nil {
+				break
+			}
 			if incremental {
 				break
 			}
@@ -852,7 +937,7 @@ func (mlp *BaseMultilayerPerceptron64) fitStochastic(X, y blas64General, activat
 		copy(mlp.packedParameters, mlp.bestParameters)
 	}
 	if mlp.Shuffle {
-		sort.Sort(indexedXY{idx: sort.IntSlice(idx), X: general64FastSwap(X), Y: general64FastSwap(y)})
+		sort.Sort(shuffled)
 	}
 }
 
@@ -1136,16 +1221,20 @@ func accuracyScore64(Y, H blas64General) float64 {
 
 }
 
-// SetParams allow settings params from a map. (used by Unmarshal)
-func (mlp *BaseMultilayerPerceptron64) SetParams(params map[string]interface{}) {
-	r := reflect.Indirect(reflect.ValueOf(mlp))
+// GetParams returns the parameters of BaseMultilayerPerceptron64. see base.GetFieldParams
+func (mlp *BaseMultilayerPerceptron64) GetParams() map[string]interface{} {
+	return base.GetFieldParams(mlp)
+}
+
+// SetParams sets the parameters of BaseMultilayerPerceptron64. see base.SetFieldParams
+func (mlp *BaseMultilayerPerceptron64) SetParams(params map[string]interface{}) error {
+	return base.SetFieldParams(mlp, params)
+}
+
+// setJSONParams sets the params read by Unmarshal, ignoring unknown ones and those which can't be converted
+func (mlp *BaseMultilayerPerceptron64) setJSONParams(params map[string]interface{}) {
 	for k, v := range params {
-		field := r.FieldByNameFunc(func(s string) bool {
-			return strings.EqualFold(s, k)
-		})
-		if field.Kind() != 0 {
-			field.Set(reflect.ValueOf(v))
-		}
+		_ = base.SetFieldParams(mlp, map[string]interface{}{k: v})
 	}
 }
 
@@ -1159,10 +1248,10 @@ func (mlp *BaseMultilayerPerceptron64) Unmarshal(buf []byte) error {
 	}
 	if params, ok := mp["params"]; ok {
 		if pmap, ok := params.(Map); ok {
-			mlp.SetParams(pmap)
+			mlp.setJSONParams(pmap)
 		}
 	} else {
-		mlp.SetParams(mp)
+		mlp.setJSONParams(mp)
 	}
 	if coefs, ok := mp["coefs_"]; ok {
 		intercepts, ok := mp["intercepts_"]
@@ -1212,6 +1301,27 @@ func (mlp *BaseMultilayerPerceptron64) Unmarshal(buf []byte) error {
 	return err
 }
 
+// MarshalState allows the perceptron to be saved by base.Save. RandomState and optimizer state are not saved
+func (mlp *BaseMultilayerPerceptron64) MarshalState() (*base.State, error) {
+	st, err := base.MarshalFields(mlp)
+	if err == nil && mlp.lb != nil {
+		err = st.SetChild("lb", mlp.lb)
+	}
+	return st, err
+}
+
+// UnmarshalState restores a perceptron saved by base.Save
+func (mlp *BaseMultilayerPerceptron64) UnmarshalState(st *base.State) error {
+	if err := base.UnmarshalFields(mlp, st); err != nil {
+		return err
+	}
+	p, err := st.GetChild("lb")
+	if lb, ok := p.(*LabelBinarizer64); ok {
+		mlp.lb = lb
+	}
+	return err
+}
+
 // ToDense64 returns w view of m if m is a RawMatrixer, et returns a dense copy of m
 func ToDense64(m Matrix) General64 {
 	if d, ok := m.(General64); ok {
@@ -1260,11 +1370,21 @@ type LabelBinarizer64 struct {
 	Classes            [][]float64
 }
 
+func init() {
+	base.Register(&LabelBinarizer64{})
+}
+
 // NewLabelBinarizer64 ...
 func NewLabelBinarizer64(NegLabel, PosLabel float64) *LabelBinarizer64 {
 	return &LabelBinarizer64{NegLabel: NegLabel, PosLabel: PosLabel}
 }
 
+// MarshalState allows LabelBinarizer64 to be saved by base.Save
+func (m *LabelBinarizer64) MarshalState() (*base.State, error) { return base.MarshalFields(m) }
+
+// UnmarshalState restores a LabelBinarizer64 saved by base.Save
+func (m *LabelBinarizer64) UnmarshalState(st *base.State) error { return base.UnmarshalFields(m, st) }
+
 // TransformerClone ...
 func (m *LabelBinarizer64) TransformerClone() *LabelBinarizer64 {
 	clone := *m
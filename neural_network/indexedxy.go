@@ -2,11 +2,18 @@ package neuralnetwork
 
 import "sort"
 
-// indexedXY implements sort.Slice to be used in Shuffle and sort.Sort
+// indexedXY implements sort.Slice to be used in Shuffle and sort.Sort. W is swapped too if not nil
 type indexedXY struct {
-	idx, X, Y sort.Interface
+	idx, X, Y, W sort.Interface
 }
 
 func (s indexedXY) Len() int           { return s.idx.Len() }
 func (s indexedXY) Less(i, j int) bool { return s.idx.Less(i, j) }
-func (s indexedXY) Swap(i, j int)      { s.idx.Swap(i, j); s.X.Swap(i, j); s.Y.Swap(i, j) }
+func (s indexedXY) Swap(i, j int) {
+	s.idx.Swap(i, j)
+	s.X.Swap(i, j)
+	s.Y.Swap(i, j)
+	if s.W != nil {
+		s.W.Swap(i, j)
+	}
+}
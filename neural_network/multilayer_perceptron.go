@@ -1,11 +1,19 @@
 package neuralnetwork
 
 import (
+	"context"
+	"fmt"
+
 	"github.com/pa-m/sklearn/base"
 
 	"gonum.org/v1/gonum/mat"
 )
 
+func init() {
+	base.Register(&MLPRegressor{})
+	base.Register(&MLPClassifier{})
+}
+
 // MLPRegressor ...
 type MLPRegressor struct{ BaseMultilayerPerceptron64 }
 
@@ -35,11 +43,7 @@ func (mlp *MLPRegressor) PredicterClone() base.Predicter {
 	if mlp == nil {
 		return nil
 	}
-	clone := *mlp
-	if sourceCloner, ok := clone.RandomState.(base.SourceCloner); ok && sourceCloner != base.SourceCloner(nil) {
-		clone.RandomState = sourceCloner.Clone()
-	}
-	return &clone
+	return &MLPRegressor{BaseMultilayerPerceptron64: *mlp.unfittedClone()}
 }
 
 // Fit ...
@@ -49,8 +53,16 @@ func (mlp *MLPRegressor) Fit(Xmatrix, Ymatrix mat.Matrix) base.Fiter {
 	return mlp
 }
 
+// FitWeighted is Fit with the loss of each sample multiplied by its weight
+func (mlp *MLPRegressor) FitWeighted(X, Y mat.Matrix, sampleWeight []float64) base.Fiter {
+	mlp.setSampleWeight(sampleWeight)
+	defer mlp.setSampleWeight(nil)
+	return mlp.Fit(X, Y)
+}
+
 // Predict return the forward result
 func (mlp *MLPRegressor) Predict(X mat.Matrix, Ymutable mat.Mutable) *mat.Dense {
+	base.MustBeFitted(mlp)
 	Y := base.ToDense(Ymutable)
 	nSamples, _ := X.Dims()
 	if Y.IsZero() {
@@ -61,6 +73,38 @@ func (mlp *MLPRegressor) Predict(X mat.Matrix, Ymutable mat.Mutable) *mat.Dense
 	return base.FromDense(Ymutable, Y)
 }
 
+// FitE is Fit returning an error instead of panicking
+func (mlp *MLPRegressor) FitE(X, Y mat.Matrix) error {
+	return mlp.FitContext(context.Background(), X, Y)
+}
+
+// FitContext is FitE stopping when ctx is done. progress is reported to the base.ProgressFunc of ctx after each epoch
+func (mlp *MLPRegressor) FitContext(ctx context.Context, X, Y mat.Matrix) (err error) {
+	if err = mlp.validateHyperparameters(); err != nil {
+		return
+	}
+	if err = base.CheckXY(X, Y); err != nil {
+		return
+	}
+	defer base.Recover(&err)
+	mon := base.NewMonitor(ctx)
+	mlp.mon = mon
+	defer func() { mlp.mon = nil }()
+	mlp.Fit(X, Y)
+	return mon.Err()
+}
+
+// PredictE is Predict returning an error instead of panicking
+func (mlp *MLPRegressor) PredictE(X mat.Matrix, Y mat.Mutable) (*mat.Dense, error) {
+	if !mlp.IsFitted() {
+		return nil, base.ErrNotFitted
+	}
+	if err := base.CheckNFeatures(X, mlp.Coefs[0].Rows); err != nil {
+		return nil, err
+	}
+	return base.PredictE(mlp, X, Y)
+}
+
 // Score for MLPRegressor returns R2Score
 func (mlp *MLPRegressor) Score(X, Y mat.Matrix) float64 {
 	nSamples, _ := X.Dims()
@@ -89,10 +133,9 @@ func NewMLPClassifier(hiddenLayerSizes []int, activation string, solver string,
 	return mlp
 }
 
-// PredicterClone returns an (possibly unfitted) copy of predicter
+// PredicterClone returns an unfitted copy of predicter
 func (mlp *MLPClassifier) PredicterClone() base.Predicter {
-	clone := *mlp
-	return &clone
+	return &MLPClassifier{BaseMultilayerPerceptron64: *mlp.unfittedClone()}
 }
 
 // IsClassifier returns true for MLPClassifier
@@ -105,6 +148,13 @@ func (mlp *MLPClassifier) Fit(Xmatrix, Ymatrix mat.Matrix) base.Fiter {
 	return mlp
 }
 
+// FitWeighted is Fit with the loss of each sample multiplied by its weight
+func (mlp *MLPClassifier) FitWeighted(X, Y mat.Matrix, sampleWeight []float64) base.Fiter {
+	mlp.setSampleWeight(sampleWeight)
+	defer mlp.setSampleWeight(nil)
+	return mlp.Fit(X, Y)
+}
+
 // Predict return the forward result for MLPClassifier
 func (mlp *MLPClassifier) Predict(X mat.Matrix, Ymutable mat.Mutable) *mat.Dense {
 	Y := base.ToDense(Ymutable)
@@ -118,12 +168,57 @@ func (mlp *MLPClassifier) Predict(X mat.Matrix, Ymutable mat.Mutable) *mat.Dense
 	}
 	yr, _ := Y.Dims()
 	if yr == 0 {
-		panic("Y must be preallocated")
+		panic(fmt.Errorf("%w: Y must be preallocated", base.ErrShapeMismatch))
 	}
 	mlp.BaseMultilayerPerceptron64.Predict(X, Y)
 	return base.FromDense(Ymutable, Y)
 }
 
+// PredictProba returns a probability column per class. see base.ProbaPredicter
+func (mlp *MLPClassifier) PredictProba(Xmatrix mat.Matrix, Y mat.Mutable) *mat.Dense {
+	base.MustBeFitted(mlp)
+	X := base.ToDense(Xmatrix)
+	nSamples, _ := X.Dims()
+	P := mat.NewDense(nSamples, mlp.NOutputs, nil)
+	mlp.predictProbas(X.RawMatrix(), P.RawMatrix())
+	if mlp.lb == nil && mlp.OutActivation == "logistic" {
+		P = base.BinaryProba(P)
+	}
+	return base.FromDense(Y, P)
+}
+
+// FitE is Fit returning an error instead of panicking
+func (mlp *MLPClassifier) FitE(X, Y mat.Matrix) error {
+	return mlp.FitContext(context.Background(), X, Y)
+}
+
+// FitContext is FitE stopping when ctx is done. progress is reported to the base.ProgressFunc of ctx after each epoch
+func (mlp *MLPClassifier) FitContext(ctx context.Context, X, Y mat.Matrix) (err error) {
+	if err = mlp.validateHyperparameters(); err != nil {
+		return
+	}
+	if err = base.CheckXY(X, Y); err != nil {
+		return
+	}
+	defer base.Recover(&err)
+	mon := base.NewMonitor(ctx)
+	mlp.mon = mon
+	defer func() { mlp.mon = nil }()
+	mlp.Fit(X, Y)
+	return mon.Err()
+}
+
+// PredictE is Predict returning an error instead of panicking
+func (mlp *MLPClassifier) PredictE(X mat.Matrix, Y mat.Mutable) (*mat.Dense, error) {
+	if !mlp.IsFitted() {
+		return nil, base.ErrNotFitted
+	}
+	if err := base.CheckNFeatures(X, mlp.Coefs[0].Rows); err != nil {
+		return nil, err
+	}
+	return base.PredictE(mlp, X, Y)
+}
+
 // Score for MLPClassifier computes accuracy score
 func (mlp *MLPClassifier) Score(Xmatrix, Ymatrix mat.Matrix) float64 {
 	X, Y := base.ToDense(Xmatrix), base.ToDense(Ymatrix)
@@ -1,6 +1,9 @@
 package neuralnetwork
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"image/color"
@@ -31,7 +34,8 @@ import (
 	"gonum.org/v1/plot/vg/draw"
 )
 
-var _ = []base.Predicter{&MLPRegressor{}, &MLPClassifier{}}
+var _ = []base.PredicterE{&MLPRegressor{}, &MLPClassifier{}}
+var _ base.ProbaPredicter = &MLPClassifier{}
 
 var visualDebug = flag.Bool("visual", false, "show plots")
 
@@ -442,6 +446,33 @@ func TestMLPRegressor(t *testing.T) {
 
 }
 
+func TestMLP_SaveLoad(t *testing.T) {
+	ds := datasets.LoadIris()
+	clf := NewMLPClassifier([]int{4}, "relu", "adam", 0)
+	clf.RandomState = base.NewSource(7)
+	clf.MaxIter = 20
+	regr := NewMLPRegressor([]int{}, "relu", "adam", 0)
+	regr.RandomState = base.NewSource(7)
+	regr.MaxIter = 20
+	for _, m := range []base.Predicter{clf, regr} {
+		m.Fit(ds.X, ds.Y)
+		buf := new(bytes.Buffer)
+		if err := base.Save(buf, m.(base.Persister)); err != nil {
+			t.Fatalf("%T: %s", m, err)
+		}
+		loaded, err := base.Load(buf)
+		if err != nil {
+			t.Fatalf("%T: %s", m, err)
+		}
+		if loaded.GetNOutputs() != m.GetNOutputs() {
+			t.Errorf("%T: expected %d outputs, got %d", m, m.GetNOutputs(), loaded.GetNOutputs())
+		}
+		if !mat.Equal(m.Predict(ds.X, nil), loaded.Predict(ds.X, nil)) {
+			t.Errorf("%T: loaded model predictions differ", m)
+		}
+	}
+}
+
 func ExampleMLPClassifier_Fit_iris() {
 
 	// adapted from http://scikit-learn.org/stable/_downloads/plot_iris_logistic.ipynb
@@ -548,3 +579,98 @@ func ExampleMLPClassifier_Fit_iris() {
 	// Output:
 	// ok
 }
+
+func TestMLP_FitContext(t *testing.T) {
+	X, Y, _ := datasets.MakeRegression(map[string]interface{}{"n_samples": 100, "n_features": 2})
+	for _, solver := range []string{"adam", "lbfgs"} {
+		mlp := NewMLPRegressor([]int{}, "relu", solver, 0)
+		mlp.RandomState = base.NewSource(7)
+		epochs := 0
+		ctx, cancel := context.WithCancel(context.Background())
+		ctx = base.WithProgress(ctx, func(p base.Progress) error {
+			epochs++
+			if epochs == 5 {
+				cancel()
+			}
+			return nil
+		})
+		if err := mlp.FitContext(ctx, X, Y); !errors.Is(err, context.Canceled) {
+			t.Errorf("%s: expected context.Canceled, got %v", solver, err)
+		}
+		if epochs != 5 {
+			t.Errorf("%s: expected 5 epochs, got %d", solver, epochs)
+		}
+		cancel()
+	}
+}
+
+func TestMLPClassifier_PredictProba(t *testing.T) {
+	ds := datasets.LoadIris()
+	for _, Y := range []*mat.Dense{ds.Y, ds.Y.Slice(0, 100, 0, 1).(*mat.Dense)} {
+		nSamples, _ := Y.Dims()
+		X := ds.X.Slice(0, nSamples, 0, 4)
+		mlp := NewMLPClassifier([]int{}, "relu", "lbfgs", 0)
+		mlp.RandomState = base.NewSource(7)
+		mlp.Fit(X, Y)
+		Ypred := mlp.Predict(X, nil)
+		P := mlp.PredictProba(X, nil)
+		if _, c := P.Dims(); c < 2 {
+			t.Fatalf("expected at least 2 columns, got %d", c)
+		}
+		for i := 0; i < nSamples; i++ {
+			if s := floats.Sum(P.RawRowView(i)); math.Abs(s-1) > 1e-5 {
+				t.Errorf("row %d probabilities sum to %g", i, s)
+			}
+			if c := float64(floats.MaxIdx(P.RawRowView(i))); c != Ypred.At(i, 0) {
+				t.Errorf("row %d: expected class %g, got %g", i, Ypred.At(i, 0), c)
+			}
+		}
+	}
+}
+
+func TestMLPRegressor_FitWeighted(t *testing.T) {
+	X, Y, _ := datasets.MakeRegression(map[string]interface{}{"n_samples": 39, "n_features": 2, "random_state": rand.New(base.NewSource(7))})
+	nSamples, _ := X.Dims()
+	// with full batches, integer weights summing to nSamples must be equivalent to duplicated samples
+	sampleWeight := make([]float64, nSamples)
+	var xdup, ydup []float64
+	for i := range sampleWeight {
+		sampleWeight[i] = float64(i % 3)
+		Y.Set(i, 0, Y.At(i, 0)+float64(i%5)/10)
+		for k := 0; k < i%3; k++ {
+			xdup = append(xdup, X.RawRowView(i)...)
+			ydup = append(ydup, Y.At(i, 0))
+		}
+	}
+	newMLP := func() *MLPRegressor {
+		mlp := NewMLPRegressor([]int{}, "identity", "adam", 0)
+		mlp.RandomState = base.NewSource(7)
+		mlp.Shuffle = false
+		mlp.BatchSize = nSamples
+		return mlp
+	}
+	weighted, duplicated := newMLP(), newMLP()
+	if err := base.FitWeighted(weighted, X, Y, sampleWeight); err != nil {
+		t.Fatal(err)
+	}
+	duplicated.Fit(mat.NewDense(len(ydup), 2, xdup), mat.NewDense(len(ydup), 1, ydup))
+	if expected, actual := duplicated.Predict(X, nil), weighted.Predict(X, nil); !mat.EqualApprox(expected, actual, 1e-6) {
+		t.Errorf("expected %g, got %g", mat.Formatted(expected.T()), mat.Formatted(actual.T()))
+	}
+	// zero weighted samples with wrong targets must be ignored, samples being shuffled with their weights
+	Ywrong := mat.DenseCopyOf(Y)
+	for i := range sampleWeight {
+		sampleWeight[i] = float64(i % 2)
+		if i%2 == 0 {
+			Ywrong.Set(i, 0, -Y.At(i, 0))
+		}
+	}
+	mlp := NewMLPRegressor([]int{}, "identity", "adam", 0)
+	mlp.RandomState = base.NewSource(7)
+	mlp.BatchSize = 10
+	mlp.LearningRateInit = .1
+	mlp.FitWeighted(X, Ywrong, sampleWeight)
+	if score := mlp.Score(X, Y); score < .9 {
+		t.Errorf("expected a score >= 0.9, got %g", score)
+	}
+}
@@ -0,0 +1,24 @@
+package pipeline
+
+import (
+	"fmt"
+
+	"github.com/pa-m/sklearn/base"
+)
+
+func init() {
+	base.Register(&Pipeline{})
+}
+
+// MarshalState allows Pipeline to be saved by base.Save. all steps must be base.Persister
+func (p *Pipeline) MarshalState() (*base.State, error) {
+	for _, step := range p.NamedSteps {
+		if _, ok := step.Fiter.(base.Persister); !ok {
+			return nil, fmt.Errorf("%w: pipeline step %s (%T)", base.ErrNotPersistable, step.Name, step.Fiter)
+		}
+	}
+	return base.MarshalFields(p)
+}
+
+// UnmarshalState restores a Pipeline saved by base.Save
+func (p *Pipeline) UnmarshalState(st *base.State) error { return base.UnmarshalFields(p, st) }
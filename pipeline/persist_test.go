@@ -0,0 +1,44 @@
+package pipeline
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/pa-m/sklearn/base"
+	"github.com/pa-m/sklearn/datasets"
+	nn "github.com/pa-m/sklearn/neural_network"
+	"github.com/pa-m/sklearn/preprocessing"
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestPipeline_SaveLoad(t *testing.T) {
+	ds := datasets.LoadBreastCancer()
+	pca := preprocessing.NewPCA()
+	pca.MinVarianceRatio = 0.995
+	m := nn.NewMLPClassifier([]int{}, "relu", "adam", 0)
+	m.RandomState = base.NewSource(7)
+	m.MaxIter = 20
+	pl := NewPipeline(NamedStep{"scaler", preprocessing.NewStandardScaler()}, NamedStep{"pca", pca}, NamedStep{"mlp", m})
+	pl.Fit(ds.X, ds.Y)
+	buf := new(bytes.Buffer)
+	if err := base.Save(buf, pl); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := base.Load(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pl2 := loaded.(*Pipeline)
+	if len(pl2.NamedSteps) != 3 || pl2.NamedSteps[1].Name != "pca" || pl2.NOutputs != pl.NOutputs {
+		t.Fatalf("pipeline steps not restored: %#v", pl2.NamedSteps)
+	}
+	if !mat.Equal(pl.Predict(ds.X, nil), pl2.Predict(ds.X, nil)) {
+		t.Error("loaded pipeline predictions differ")
+	}
+
+	pl = NewPipeline(NamedStep{"f", preprocessing.NewFunctionTransformer(nil, nil)}, NamedStep{"other", &struct{ base.Predicter }{}})
+	if err := base.Save(new(bytes.Buffer), pl); !errors.Is(err, base.ErrNotPersistable) {
+		t.Errorf("expected ErrNotPersistable, got %v", err)
+	}
+}
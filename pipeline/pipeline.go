@@ -1,9 +1,11 @@
 package pipeline
 
 import (
+	"context"
 	"fmt"
 
 	// "log"
+	"reflect"
 	"strings"
 
 	"github.com/pa-m/sklearn/base"
@@ -32,9 +34,10 @@ func NewPipeline(steps ...NamedStep) *Pipeline {
 	return p
 }
 
-// PredicterClone for pipeline relies on children clone method is child is Transformer
+// PredicterClone for pipeline returns an unfitted pipeline of the unfitted clones of its steps
 func (p *Pipeline) PredicterClone() base.Predicter {
 	clone := *p
+	clone.NOutputs = 0
 	clone.NamedSteps = make([]NamedStep, len(p.NamedSteps))
 	for i, step := range p.NamedSteps {
 		if cloner, ok := step.Fiter.(base.Transformer); ok {
@@ -48,6 +51,61 @@ func (p *Pipeline) PredicterClone() base.Predicter {
 	return &clone
 }
 
+// IsFitted returns true when the pipeline has been fitted. see base.FittedChecker
+func (p *Pipeline) IsFitted() bool { return p.NOutputs != 0 }
+
+// GetParams returns the steps by name and their params as "step__param"
+func (p *Pipeline) GetParams() map[string]interface{} {
+	params := make(map[string]interface{})
+	for _, step := range p.NamedSteps {
+		params[step.Name] = step.Fiter
+		for k, v := range base.GetParams(step.Fiter) {
+			params[step.Name+base.ParamSeparator+k] = v
+		}
+	}
+	return params
+}
+
+// SetParams replaces a step when a key is a step name and sets a step param for "step__param" keys
+func (p *Pipeline) SetParams(params map[string]interface{}) error {
+	own, nested := base.SplitParams(params)
+	for name, v := range own {
+		istep := p.stepIndex(name)
+		if istep < 0 {
+			return fmt.Errorf("%w: no step %s in pipeline", base.ErrInvalidParam, name)
+		}
+		step, ok := v.(base.Fiter)
+		if !ok {
+			return fmt.Errorf("%w: step %s: %T is not a Fiter", base.ErrInvalidParam, name, v)
+		}
+		p.NamedSteps[istep].Fiter = step
+	}
+	for name, stepParams := range nested {
+		istep := p.stepIndex(name)
+		if istep < 0 {
+			return fmt.Errorf("%w: no step %s in pipeline", base.ErrInvalidParam, name)
+		}
+		if err := base.SetParams(p.NamedSteps[istep].Fiter, stepParams); err != nil {
+			return fmt.Errorf("step %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func (p *Pipeline) stepIndex(name string) int {
+	for istep, step := range p.NamedSteps {
+		if step.Name == name {
+			return istep
+		}
+	}
+	for istep, step := range p.NamedSteps {
+		if base.ParamNameEqual(step.Name, name) {
+			return istep
+		}
+	}
+	return -1
+}
+
 // IsClassifier for pipeline returns last step IsClassifier if any
 func (p *Pipeline) IsClassifier() bool {
 	nSteps := len(p.NamedSteps)
@@ -83,12 +141,26 @@ func (p *Pipeline) transformStep(istep int, Xtmp, Ytmp **mat.Dense) {
 
 // Fit for Pipeline
 func (p *Pipeline) Fit(Xmatrix, Ymatrix mat.Matrix) base.Fiter {
+	return p.FitWeighted(Xmatrix, Ymatrix, nil)
+}
+
+// FitWeighted fits the steps, passing sampleWeight to those which are base.WeightedFiter.
+// it panics if sampleWeight is not uniform and the last step does not support sample weights
+func (p *Pipeline) FitWeighted(Xmatrix, Ymatrix mat.Matrix, sampleWeight []float64) base.Fiter {
 	X, Y := base.ToDense(Xmatrix), base.ToDense(Ymatrix)
 	_, p.NOutputs = Y.Dims()
 	Xtmp, Ytmp := X, Y
 	steps := len(p.NamedSteps)
 	for istep, step := range p.NamedSteps {
-		step.Fit(Xtmp, Ytmp)
+		wf, weighted := step.Fiter.(base.WeightedFiter)
+		switch {
+		case weighted:
+			wf.FitWeighted(Xtmp, Ytmp, sampleWeight)
+		case istep == steps-1 && !base.IsUniformWeight(sampleWeight):
+			panic(fmt.Errorf("%w: %T does not support sample weights", base.ErrInvalidParam, step.Fiter))
+		default:
+			step.Fit(Xtmp, Ytmp)
+		}
 		if istep < steps-1 {
 			p.transformStep(istep, &Xtmp, &Ytmp)
 		}
@@ -96,6 +168,47 @@ func (p *Pipeline) Fit(Xmatrix, Ymatrix mat.Matrix) base.Fiter {
 	return p
 }
 
+// FitE is Fit returning an error instead of panicking
+func (p *Pipeline) FitE(X, Y mat.Matrix) error {
+	return p.FitContext(context.Background(), X, Y)
+}
+
+// FitContext is FitE stopping when ctx is done. steps are fitted with base.FitContext
+func (p *Pipeline) FitContext(ctx context.Context, Xmatrix, Ymatrix mat.Matrix) (err error) {
+	if err = p.checkSteps(); err != nil {
+		return
+	}
+	if err = base.CheckXY(Xmatrix, Ymatrix); err != nil {
+		return
+	}
+	defer base.Recover(&err)
+	X, Y := base.ToDense(Xmatrix), base.ToDense(Ymatrix)
+	_, p.NOutputs = Y.Dims()
+	Xtmp, Ytmp := X, Y
+	steps := len(p.NamedSteps)
+	for istep, step := range p.NamedSteps {
+		if err = base.FitContext(ctx, step.Fiter, Xtmp, Ytmp); err != nil {
+			return
+		}
+		if istep < steps-1 {
+			p.transformStep(istep, &Xtmp, &Ytmp)
+		}
+	}
+	return
+}
+
+func (p *Pipeline) checkSteps() error {
+	if len(p.NamedSteps) == 0 {
+		return fmt.Errorf("%w: pipeline has no step", base.ErrInvalidParam)
+	}
+	for istep, step := range p.NamedSteps[:len(p.NamedSteps)-1] {
+		if _, ok := step.Fiter.(base.Transformer); !ok {
+			return fmt.Errorf("%w: pipeline step %d (%s) is not a Transformer", base.ErrInvalidParam, istep, step.Name)
+		}
+	}
+	return nil
+}
+
 // Score for pipeline
 func (p *Pipeline) Score(X, Y mat.Matrix) float64 {
 	Xtmp, Ytmp := base.ToDense(X), base.ToDense(Y)
@@ -133,6 +246,62 @@ func (p *Pipeline) Predict(X mat.Matrix, Y mat.Mutable) *mat.Dense {
 	return base.FromDense(Y, base.ToDense(Ytmp))
 }
 
+// transformX returns X transformed by all steps but the last
+func (p *Pipeline) transformX(X mat.Matrix) *mat.Dense {
+	Xtmp, Ytmp := base.ToDense(X), &mat.Dense{}
+	for istep := 0; istep < len(p.NamedSteps)-1; istep++ {
+		p.transformStep(istep, &Xtmp, &Ytmp)
+	}
+	return Xtmp
+}
+
+// PredictProba returns PredictProba of the last step, which must be a base.ProbaPredicter
+func (p *Pipeline) PredictProba(X mat.Matrix, Y mat.Mutable) *mat.Dense {
+	last := p.NamedSteps[len(p.NamedSteps)-1]
+	m, ok := last.Fiter.(base.ProbaPredicter)
+	if !ok {
+		panic(fmt.Errorf("%w: pipeline step %s (%T) is not a ProbaPredicter", base.ErrInvalidParam, last.Name, last.Fiter))
+	}
+	return m.PredictProba(p.transformX(X), Y)
+}
+
+// DecisionFunction returns DecisionFunction of the last step, which must be a base.DecisionFunctioner
+func (p *Pipeline) DecisionFunction(X mat.Matrix, Y mat.Mutable) *mat.Dense {
+	last := p.NamedSteps[len(p.NamedSteps)-1]
+	m, ok := last.Fiter.(base.DecisionFunctioner)
+	if !ok {
+		panic(fmt.Errorf("%w: pipeline step %s (%T) is not a DecisionFunctioner", base.ErrInvalidParam, last.Name, last.Fiter))
+	}
+	return m.DecisionFunction(p.transformX(X), Y)
+}
+
+// PredictE is Predict returning an error instead of panicking
+func (p *Pipeline) PredictE(X mat.Matrix, Y mat.Mutable) (*mat.Dense, error) {
+	if err := p.checkSteps(); err != nil {
+		return nil, err
+	}
+	if !p.IsFitted() {
+		return nil, base.ErrNotFitted
+	}
+	return base.PredictE(p, X, Y)
+}
+
+// TransformE is Transform returning an error instead of panicking
+func (p *Pipeline) TransformE(X, Y mat.Matrix) (Xout, Yout *mat.Dense, err error) {
+	if err = p.checkSteps(); err != nil {
+		return
+	}
+	if !p.IsFitted() {
+		return nil, nil, base.ErrNotFitted
+	}
+	if err = base.CheckXY(X, Y); err != nil {
+		return
+	}
+	defer base.Recover(&err)
+	Xout, Yout = p.Transform(X, Y)
+	return
+}
+
 // Transform for pipeline
 func (p *Pipeline) Transform(X, Y mat.Matrix) (Xout, Yout *mat.Dense) {
 	nSamples, _ := X.Dims()
@@ -148,11 +317,25 @@ func (p *Pipeline) FitTransform(X, Y mat.Matrix) (Xout, Yout *mat.Dense) {
 	return p.Transform(X, Y)
 }
 
-// MakePipeline returns a Pipeline from unnamed steps
+// MakePipeline returns a Pipeline from unnamed steps. steps are named after their lowercased type, as "svc" for a *svm.SVC,
+// so that their params can be set with "svc__C"
 func MakePipeline(steps ...base.Fiter) *Pipeline {
 	p := &Pipeline{}
-	for _, step := range steps {
-		p.NamedSteps = append(p.NamedSteps, NamedStep{Name: strings.ToLower(fmt.Sprintf("%T", step)), Fiter: step})
+	names := make([]string, len(steps))
+	count := make(map[string]int)
+	for i, step := range steps {
+		names[i] = strings.ToLower(reflect.Indirect(reflect.ValueOf(step)).Type().Name())
+		count[names[i]]++
+	}
+	// like sklearn make_pipeline, duplicated names get a -1, -2... suffix
+	seen := make(map[string]int)
+	for i, step := range steps {
+		name := names[i]
+		if count[name] > 1 {
+			seen[name]++
+			name = fmt.Sprintf("%s-%d", name, seen[name])
+		}
+		p.NamedSteps = append(p.NamedSteps, NamedStep{Name: name, Fiter: step})
 	}
 	return p
 }
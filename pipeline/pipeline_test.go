@@ -1,7 +1,9 @@
 package pipeline
 
 import (
+	"errors"
 	"fmt"
+	"testing"
 
 	"github.com/pa-m/sklearn/base"
 
@@ -12,6 +14,10 @@ import (
 	"gonum.org/v1/gonum/mat"
 )
 
+var _ base.PredicterE = &Pipeline{}
+var _ base.ProbaPredicter = &Pipeline{}
+var _ base.WeightedFiter = &Pipeline{}
+
 func ExamplePipeline() {
 	randomState := rand.New(base.NewLockedSource(7))
 
@@ -59,3 +65,72 @@ func ExamplePipeline() {
 	// accuracy>0.999 ? true
 
 }
+
+func TestPipeline_SetParams(t *testing.T) {
+	m := nn.NewMLPClassifier([]int{}, "relu", "adam", 0)
+	pl := MakePipeline(preprocessing.NewStandardScaler(), preprocessing.NewStandardScaler(), m)
+	if pl.NamedSteps[1].Name != "standardscaler-2" || pl.NamedSteps[2].Name != "mlpclassifier" {
+		t.Fatalf("unexpected step names %s %s", pl.NamedSteps[1].Name, pl.NamedSteps[2].Name)
+	}
+	if err := pl.SetParams(map[string]interface{}{"mlpclassifier__alpha": 1e-3, "standardscaler-1__with_mean": false}); err != nil {
+		t.Fatal(err)
+	}
+	if m.Alpha != 1e-3 || pl.NamedSteps[0].Fiter.(*preprocessing.StandardScaler).WithMean {
+		t.Error("params not set")
+	}
+	if v := pl.GetParams()["mlpclassifier__Alpha"]; v != 1e-3 {
+		t.Errorf("expected 1e-3, got %v", v)
+	}
+	pca := preprocessing.NewPCA()
+	if err := pl.SetParams(map[string]interface{}{"standardscaler-2": pca}); err != nil || pl.NamedSteps[1].Fiter != pca {
+		t.Errorf("step not replaced: %v", err)
+	}
+	for _, params := range []map[string]interface{}{{"nostep__alpha": 1}, {"mlpclassifier__nosuchparam": 1}, {"mlpclassifier": 1}} {
+		if err := pl.SetParams(params); !errors.Is(err, base.ErrInvalidParam) {
+			t.Errorf("%v: expected ErrInvalidParam, got %v", params, err)
+		}
+	}
+}
+
+func TestPipeline_PredictProba(t *testing.T) {
+	ds := datasets.LoadIris()
+	scaler := preprocessing.NewStandardScaler()
+	m := nn.NewMLPClassifier([]int{}, "relu", "lbfgs", 0)
+	m.RandomState = base.NewSource(7)
+	pl := MakePipeline(scaler, m)
+	pl.Fit(ds.X, ds.Y)
+	Xt, _ := scaler.Transform(ds.X, nil)
+	if !mat.Equal(pl.PredictProba(ds.X, nil), m.PredictProba(Xt, nil)) {
+		t.Error("pipeline PredictProba differs from last step PredictProba")
+	}
+	func() {
+		defer func() {
+			if err, _ := recover().(error); !errors.Is(err, base.ErrInvalidParam) {
+				t.Errorf("expected ErrInvalidParam, got %v", err)
+			}
+		}()
+		pl.DecisionFunction(ds.X, nil)
+	}()
+}
+
+func TestPipeline_FitWeighted(t *testing.T) {
+	X, Y, _ := datasets.MakeRegression(map[string]interface{}{"n_samples": 40, "n_features": 2})
+	sampleWeight := make([]float64, 40)
+	for i := range sampleWeight {
+		sampleWeight[i] = float64(i % 3)
+		Y.Set(i, 0, Y.At(i, 0)+float64(i%5))
+	}
+	m := nn.NewMLPRegressor([]int{}, "identity", "lbfgs", 0)
+	m.RandomState = base.NewSource(7)
+	pl := MakePipeline(preprocessing.NewStandardScaler(), m)
+	if err := base.FitWeighted(pl, X, Y, sampleWeight); err != nil {
+		t.Fatal(err)
+	}
+	expected := nn.NewMLPRegressor([]int{}, "identity", "lbfgs", 0)
+	expected.RandomState = base.NewSource(7)
+	Xt, _ := preprocessing.NewStandardScaler().FitTransform(X, nil)
+	expected.FitWeighted(Xt, Y, sampleWeight)
+	if !mat.EqualApprox(pl.Predict(X, nil), expected.Predict(Xt, nil), 1e-9) {
+		t.Error("sample weights must be passed to the last step")
+	}
+}
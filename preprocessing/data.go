@@ -29,8 +29,8 @@ type InverseTransformer interface {
 // MinMaxScaler rescale data between FeatureRange
 type MinMaxScaler struct {
 	FeatureRange                            []float
-	Scale, Min, DataMin, DataMax, DataRange *mat.Dense
-	NSamplesSeen                            int
+	Scale, Min, DataMin, DataMax, DataRange *mat.Dense `param:"-"`
+	NSamplesSeen                            int        `param:"-"`
 }
 
 // NewMinMaxScaler creates an *MinMaxScaler with FeatureRange 0..1
@@ -40,8 +40,9 @@ func NewMinMaxScaler(featureRange []float) *MinMaxScaler {
 
 // TransformerClone ...
 func (scaler *MinMaxScaler) TransformerClone() base.Transformer {
-	var clone = *scaler
-	return &clone
+	clone := *scaler
+	clone.Scale, clone.Min, clone.DataMin, clone.DataMax, clone.DataRange, clone.NSamplesSeen = nil, nil, nil, nil, nil, 0
+	return base.DeepCopy(&clone).(*MinMaxScaler)
 }
 
 // Reset resets scaler to its initial state
@@ -57,8 +58,7 @@ func (scaler *MinMaxScaler) Fit(X, Y mat.Matrix) base.Fiter {
 }
 
 // PartialFit updates Scale and Min with partial data
-func (scaler *MinMaxScaler) PartialFit(Xmatrix, Ymatrix mat.Matrix) Transformer {
-	X := base.ToDense(Xmatrix)
+func (scaler *MinMaxScaler) PartialFit(X, Ymatrix mat.Matrix) Transformer {
 	nSamples, nFeatures := X.Dims()
 	if nSamples == 0 {
 		return scaler
@@ -70,14 +70,17 @@ func (scaler *MinMaxScaler) PartialFit(Xmatrix, Ymatrix mat.Matrix) Transformer
 		scaler.Min = mat.NewDense(1, nFeatures, nil)
 		scaler.Scale = mat.NewDense(1, nFeatures, nil)
 
-		scaler.DataMin.CloneFrom(X.RowView(0).T())
-		scaler.DataMax.CloneFrom(X.RowView(0).T())
+		matRow(scaler.DataMin.RawRowView(0), 0, X)
+		matRow(scaler.DataMax.RawRowView(0), 0, X)
+	}
+	dataMin, dataMax := scaler.DataMin.RawRowView(0), scaler.DataMax.RawRowView(0)
+	row := make([]float64, nFeatures)
+	for i := 0; i < nSamples; i++ {
+		for j, x := range matRow(row, i, X) {
+			dataMin[j] = math.Min(dataMin[j], x)
+			dataMax[j] = math.Max(dataMax[j], x)
+		}
 	}
-	X.Apply(func(i int, j int, x float64) float64 {
-		scaler.DataMin.Set(0, j, math.Min(scaler.DataMin.At(0, j), x))
-		scaler.DataMax.Set(0, j, math.Max(scaler.DataMax.At(0, j), x))
-		return x
-	}, X)
 	scaler.NSamplesSeen += nSamples
 	// dataRange = dataMax - dataMin
 	scaler.DataRange.Sub(scaler.DataMax, scaler.DataMin)
@@ -101,6 +104,7 @@ func (scaler *MinMaxScaler) PartialFit(Xmatrix, Ymatrix mat.Matrix) Transformer
 
 // Transform applies scaling to X
 func (scaler *MinMaxScaler) Transform(X, Y mat.Matrix) (Xout, Yout *mat.Dense) {
+	base.MustBeFitted(scaler)
 	nSamples, nFeatures := X.Dims()
 	Xout = mat.NewDense(nSamples, nFeatures, nil)
 	Xout.Apply(func(i int, j int, x float64) float64 {
@@ -131,8 +135,8 @@ func (scaler *MinMaxScaler) InverseTransform(X, Y *mat.Dense) (Xout, Yout *mat.D
 // StandardScaler scales data by removing Mean and dividing by stddev
 type StandardScaler struct {
 	WithMean, WithStd bool
-	Scale, Mean, Var  *mat.Dense
-	NSamplesSeen      int
+	Scale, Mean, Var  *mat.Dense `param:"-"`
+	NSamplesSeen      int        `param:"-"`
 }
 
 // NewStandardScaler creates a *StandardScaler
@@ -142,8 +146,9 @@ func NewStandardScaler() *StandardScaler {
 
 // TransformerClone ...
 func (scaler *StandardScaler) TransformerClone() base.Transformer {
-	var clone = *scaler
-	return &clone
+	clone := *scaler
+	clone.Scale, clone.Mean, clone.Var, clone.NSamplesSeen = nil, nil, nil, 0
+	return base.DeepCopy(&clone).(*StandardScaler)
 }
 
 // Reset ...
@@ -154,6 +159,9 @@ func (scaler *StandardScaler) Reset() *StandardScaler {
 
 // Fit computes Mean snd Std
 func (scaler *StandardScaler) Fit(Xmatrix, Ymatrix mat.Matrix) base.Fiter {
+	if X32, ok := Xmatrix.(base.General32); ok {
+		return scaler.fit32(X32)
+	}
 	X, Y := base.ToDense(Xmatrix), base.ToDense(Ymatrix)
 	scaler.Reset()
 	return scaler.PartialFit(X, Y)
@@ -183,6 +191,7 @@ func (scaler *StandardScaler) PartialFit(X, Y *mat.Dense) Transformer {
 
 // Transform scales data
 func (scaler *StandardScaler) Transform(X, Y mat.Matrix) (Xout, Yout *mat.Dense) {
+	base.MustBeFitted(scaler)
 	Xmat := base.ToDense(X).RawMatrix()
 	Xout = mat.NewDense(Xmat.Rows, Xmat.Cols, nil)
 	Xoutmat := Xout.RawMatrix()
@@ -309,10 +318,10 @@ func (m NumpyLike) Std(X mat.Matrix) *mat.Dense {
 type RobustScaler struct {
 	Center          bool
 	Scale           bool
-	Quantiles       *QuantilePair
-	Median          *mat.Dense
-	Tmp             *mat.Dense
-	QuantileDivider *mat.Dense
+	Quantiles       *QuantilePair `param:"-"`
+	Median          *mat.Dense    `param:"-"`
+	Tmp             *mat.Dense    `param:"-"`
+	QuantileDivider *mat.Dense    `param:"-"`
 }
 
 // QuantilePair represents bounds of quantile
@@ -328,8 +337,9 @@ func NewDefaultRobustScaler() *RobustScaler {
 
 // TransformerClone ...
 func (scaler *RobustScaler) TransformerClone() base.Transformer {
-	var clone = *scaler
-	return &clone
+	clone := *scaler
+	clone.Median, clone.Tmp, clone.QuantileDivider = nil, nil, nil
+	return base.DeepCopy(&clone).(*RobustScaler)
 }
 
 // NewRobustScaler creates a *RobustScaler
@@ -400,6 +410,7 @@ func (scaler *RobustScaler) PartialFit(Xmatrix, Ymatrix mat.Matrix) Transformer
 
 // Transform scales data
 func (scaler *RobustScaler) Transform(X, Y mat.Matrix) (Xout, Yout *mat.Dense) {
+	base.MustBeFitted(scaler)
 	Xout = mat.DenseCopyOf(X)
 	Xout.Apply(func(i int, j int, x float64) float64 {
 		res := x
@@ -553,8 +564,9 @@ func NewPolynomialFeatures(degree int) *PolynomialFeatures {
 
 // TransformerClone ...
 func (poly *PolynomialFeatures) TransformerClone() base.Transformer {
-	var clone = *poly
-	return &clone
+	clone := *poly
+	clone.Powers = nil
+	return base.DeepCopy(&clone).(*PolynomialFeatures)
 }
 
 // Fit precompute Powers
@@ -581,6 +593,7 @@ func (poly *PolynomialFeatures) Fit(Xmatrix, Ymatrix mat.Matrix) base.Fiter {
 
 // Transform returns data with polynomial features added
 func (poly *PolynomialFeatures) Transform(X, Y mat.Matrix) (Xout, Yout *mat.Dense) {
+	base.MustBeFitted(poly)
 	nSamples, _ := X.Dims()
 	Xout = mat.NewDense(nSamples, len(poly.Powers), nil)
 	xi, xo := base.ToDense(X).RawMatrix(), Xout.RawMatrix()
@@ -680,8 +693,8 @@ func AddDummyFeature(X *mat.Dense) {
 
 // OneHotEncoder Encode categorical integer features using a one-hot aka one-of-K scheme.
 type OneHotEncoder struct {
-	NValues, FeatureIndices []int
-	Values                  [][]float64
+	NValues, FeatureIndices []int       `param:"-"`
+	Values                  [][]float64 `param:"-"`
 }
 
 // NewOneHotEncoder creates a *OneHotEncoder
@@ -691,8 +704,9 @@ func NewOneHotEncoder() *OneHotEncoder {
 
 // TransformerClone ...
 func (m *OneHotEncoder) TransformerClone() base.Transformer {
-	var clone = *m
-	return &clone
+	clone := *m
+	clone.NValues, clone.FeatureIndices, clone.Values = nil, nil, nil
+	return base.DeepCopy(&clone).(*OneHotEncoder)
 }
 
 // Fit ...
@@ -724,6 +738,7 @@ func (m *OneHotEncoder) Fit(Xmatrix, Ymatrix mat.Matrix) base.Fiter {
 
 // Transform transform Y labels to one hot encoded format
 func (m *OneHotEncoder) Transform(X, Y mat.Matrix) (Xout, Yout *mat.Dense) {
+	base.MustBeFitted(m)
 	NSamples, nfeatures := X.Dims()
 	Yout = base.ToDense(Y)
 	columns := 0
@@ -787,7 +802,7 @@ func (m *OneHotEncoder) InverseTransform(X, Y *mat.Dense) (Xout, Yout *mat.Dense
 
 // Shuffler shuffles rows of X and Y
 type Shuffler struct {
-	Perm        []int
+	Perm        []int `param:"-"`
 	RandomState base.Source
 }
 
@@ -796,8 +811,9 @@ func NewShuffler() *Shuffler { return &Shuffler{} }
 
 // TransformerClone ...
 func (m *Shuffler) TransformerClone() base.Transformer {
-	var clone = *m
-	return &clone
+	clone := *m
+	clone.Perm = nil
+	return base.DeepCopy(&clone).(*Shuffler)
 }
 
 // Fit for Shuffler
@@ -813,6 +829,7 @@ func (m *Shuffler) Fit(Xmatrix, Ymatrix mat.Matrix) base.Fiter {
 
 // Transform for Shuffler
 func (m *Shuffler) Transform(X, Y mat.Matrix) (Xout, Yout *mat.Dense) {
+	base.MustBeFitted(m)
 	Xout, Yout = mat.DenseCopyOf(X), mat.DenseCopyOf(Y)
 	xmat, ymat := Xout.RawMatrix(), Yout.RawMatrix()
 	var dstxpos, dstypos, j int
@@ -875,8 +892,8 @@ func NewBinarizer() *Binarizer { return &Binarizer{} }
 
 // TransformerClone ...
 func (m *Binarizer) TransformerClone() base.Transformer {
-	var clone = *m
-	return &clone
+	clone := *m
+	return base.DeepCopy(&clone).(*Binarizer)
 }
 
 // Fit for binarizer does nothing
@@ -911,8 +928,8 @@ func (m *Binarizer) FitTransform(X, Y mat.Matrix) (Xout, Yout *mat.Dense) {
 
 // MaxAbsScaler ...
 type MaxAbsScaler struct {
-	Scale, MaxAbs []float64
-	NSamplesSeen  int
+	Scale, MaxAbs []float64 `param:"-"`
+	NSamplesSeen  int       `param:"-"`
 }
 
 // NewMaxAbsScaler ...
@@ -920,12 +937,19 @@ func NewMaxAbsScaler() *MaxAbsScaler { return &MaxAbsScaler{} }
 
 // TransformerClone ...
 func (m *MaxAbsScaler) TransformerClone() base.Transformer {
-	var clone = *m
-	return &clone
+	clone := *m
+	clone.Scale, clone.MaxAbs, clone.NSamplesSeen = nil, nil, 0
+	return base.DeepCopy(&clone).(*MaxAbsScaler)
 }
 
 // Fit for MaxAbsScaler ...
 func (m *MaxAbsScaler) Fit(Xmatrix, Ymatrix mat.Matrix) base.Fiter {
+	if base.IsSparse(Xmatrix) {
+		return m.fitSparse(base.ToCSR(Xmatrix))
+	}
+	if X32, ok := Xmatrix.(base.General32); ok {
+		return m.fit32(X32)
+	}
 	X, Y := base.ToDense(Xmatrix), base.ToDense(Ymatrix)
 	Xmat := X.RawMatrix()
 	m.MaxAbs = make([]float64, Xmat.Cols)
@@ -959,6 +983,10 @@ func (m *MaxAbsScaler) PartialFit(X, Y *mat.Dense) base.Transformer {
 
 // Transform for MaxAbsScaler ...
 func (m *MaxAbsScaler) Transform(X, Y mat.Matrix) (Xout, Yout *mat.Dense) {
+	base.MustBeFitted(m)
+	if base.IsSparse(X) {
+		return m.TransformSparse(base.ToCSR(X)).ToDense(), base.ToDense(Y)
+	}
 	Xmat := base.ToDense(X).RawMatrix()
 	Xout = mat.NewDense(Xmat.Rows, Xmat.Cols, nil)
 	Xoutmat := Xout.RawMatrix()
@@ -1004,8 +1032,9 @@ func NewNormalizer() *Normalizer { return &Normalizer{Norm: "l2", Axis: 1} }
 
 // TransformerClone ...
 func (m *Normalizer) TransformerClone() base.Transformer {
-	var clone = *m
-	return &clone
+	clone := *m
+	clone.nrmValues = nil
+	return base.DeepCopy(&clone).(*Normalizer)
 }
 
 // Fit for Normalizer ...
@@ -1013,6 +1042,9 @@ func (m *Normalizer) Fit(X, Y mat.Matrix) base.Fiter { return m }
 
 // Transform for Normalizer ...
 func (m *Normalizer) Transform(Xmatrix, Y mat.Matrix) (Xout, Yout *mat.Dense) {
+	if base.IsSparse(Xmatrix) {
+		return m.TransformSparse(base.ToCSR(Xmatrix)).ToDense(), base.ToDense(Y)
+	}
 	X := base.ToDense(Xmatrix)
 	NSamples, NFeatures := X.Dims()
 
@@ -1041,7 +1073,7 @@ func (m *Normalizer) Transform(Xmatrix, Y mat.Matrix) (Xout, Yout *mat.Dense) {
 		m.nrmValues = make([]float64, NSamples)
 		for i := 0; i < NSamples; i++ {
 			mat.Row(tmp, i, X)
-			nrm := mat.Norm(mat.NewVecDense(NSamples, tmp), norm)
+			nrm := mat.Norm(mat.NewVecDense(NFeatures, tmp), norm)
 			m.nrmValues[i] = nrm
 			if nrm != 0 {
 				floats.Scale(1/nrm, tmp)
@@ -1084,8 +1116,8 @@ func (m *Normalizer) InverseTransform(X, Y *mat.Dense) (Xout, Yout *mat.Dense) {
 
 // KernelCenterer Center a kernel matrix
 type KernelCenterer struct {
-	KFitAll  float64
-	KFitRows []float64
+	KFitAll  float64   `param:"-"`
+	KFitRows []float64 `param:"-"`
 }
 
 // NewKernelCenterer ...
@@ -1093,8 +1125,9 @@ func NewKernelCenterer() *KernelCenterer { return &KernelCenterer{} }
 
 // TransformerClone ...
 func (m *KernelCenterer) TransformerClone() base.Transformer {
-	var clone = *m
-	return &clone
+	clone := *m
+	clone.KFitAll, clone.KFitRows = 0, nil
+	return base.DeepCopy(&clone).(*KernelCenterer)
 }
 
 // Fit for KernelCenterer ...
@@ -1118,6 +1151,7 @@ func (m *KernelCenterer) Fit(Xmatrix, Ymatrix mat.Matrix) base.Fiter {
 
 // Transform for KernelCenterer ...
 func (m *KernelCenterer) Transform(Xmatrix, Y mat.Matrix) (Xout, Yout *mat.Dense) {
+	base.MustBeFitted(m)
 	X := base.ToDense(Xmatrix)
 	r, _ := X.Dims()
 	KPredCols := make([]float64, r)
@@ -1151,7 +1185,7 @@ type QuantileTransformer struct {
 	OutputDistribution string
 	RandomState        rand.Source
 	references         []float64
-	Quantiles          mat.Matrix
+	Quantiles          mat.Matrix `persist:"-" param:"-"`
 }
 
 // NewQuantileTransformer returns a new QuantileTransformer
@@ -1188,6 +1222,7 @@ func (m *QuantileTransformer) Fit(Xmatrix, Ymatrix mat.Matrix) base.Fiter {
 
 // Transform for QuantileTransformer returns Quantiles of X in Xout
 func (m *QuantileTransformer) Transform(Xmatrix, Ymatrix mat.Matrix) (Xout, Yout *mat.Dense) {
+	base.MustBeFitted(m)
 	X, Y := base.ToDense(Xmatrix), base.ToDense(Ymatrix)
 	nSamples, nFeatures := X.Dims()
 	eps := 1e-7
@@ -1215,7 +1250,8 @@ func (m *QuantileTransformer) FitTransform(Xmatrix, Ymatrix mat.Matrix) (Xout, Y
 // TransformerClone ...
 func (m *QuantileTransformer) TransformerClone() Transformer {
 	clone := *m
-	return &clone
+	clone.references, clone.Quantiles = nil, nil
+	return base.DeepCopy(&clone).(*QuantileTransformer)
 }
 
 // PowerTransformer apply a power transform featurewise to make data more Gaussian-like
@@ -1224,8 +1260,8 @@ type PowerTransformer struct {
 	Method      string
 	Standardize bool
 
-	Lambdas []float64
-	Scaler  *StandardScaler
+	Lambdas []float64       `param:"-"`
+	Scaler  *StandardScaler `param:"-"`
 }
 
 // NewPowerTransformer returns a PowerTransformer with method yeo-johnson and standardize=true
@@ -1236,7 +1272,8 @@ func NewPowerTransformer() *PowerTransformer {
 // TransformerClone allow duplication
 func (m *PowerTransformer) TransformerClone() base.Transformer {
 	clone := *m
-	return &clone
+	clone.Lambdas, clone.Scaler = nil, nil
+	return base.DeepCopy(&clone).(*PowerTransformer)
 }
 
 // Fit Estimate the optimal parameter lambda for each feature. The optimal lambda parameter for minimizing skewness is estimated on each feature independently using maximum likelihood.
@@ -1289,6 +1326,7 @@ func (m *PowerTransformer) fit(X, Y mat.Matrix, forceTransform bool) (Xout *mat.
 
 // Transform apply the power transform to each feature using the fitted lambdas
 func (m *PowerTransformer) Transform(X, Y mat.Matrix) (Xout, Yout *mat.Dense) {
+	base.MustBeFitted(m)
 	nSamples, nFeatures := X.Dims()
 	Xout = base.ToDense(X)
 	Yout = base.ToDense(Y)
@@ -17,6 +17,8 @@ import (
 
 var _ = []Transformer{&MinMaxScaler{}, &StandardScaler{}, &RobustScaler{}, &PolynomialFeatures{}, &OneHotEncoder{}, &Shuffler{}, &Binarizer{}, &MaxAbsScaler{}, &Normalizer{}, &KernelCenterer{}, &QuantileTransformer{}}
 
+var _ = []base.TransformerE{&MinMaxScaler{}, &StandardScaler{}, &RobustScaler{}, &PolynomialFeatures{}, &OneHotEncoder{}, &Shuffler{}, &Binarizer{}, &MaxAbsScaler{}, &Normalizer{}, &KernelCenterer{}, &QuantileTransformer{}, &PowerTransformer{}, &KBinsDiscretizer{}, &FunctionTransformer{}, &Imputer{}, &PCA{}, &LabelBinarizer{}, &MultiLabelBinarizer{}, &LabelEncoder{}}
+
 func ExampleMinMaxScaler() {
 	// adapted from http://scikit-learn.org/stable/modules/generated/sklearn.preprocessing.MinMaxScaler.html#sklearn.preprocessing.MinMaxScaler
 	data := mat.NewDense(4, 2, []float64{-1., 2, -.5, 6, 0, 10, 1, 18})
@@ -17,7 +17,7 @@ type KBinsDiscretizer struct {
 	NBins    int
 	Encode   string
 	Strategy string
-	BinEdges [][]float64
+	BinEdges [][]float64 `param:"-"`
 }
 
 // NewKBinsDiscretizer returns a discretizer with Encode="onehot-dense" ans strategy="quantile"
@@ -28,7 +28,8 @@ func NewKBinsDiscretizer(NBins int) *KBinsDiscretizer {
 // TransformerClone ...
 func (m *KBinsDiscretizer) TransformerClone() Transformer {
 	clone := *m
-	return &clone
+	clone.BinEdges = nil
+	return base.DeepCopy(&clone).(*KBinsDiscretizer)
 }
 
 // Fit fits the transformer
@@ -59,6 +60,7 @@ func (m *KBinsDiscretizer) Fit(X, Y mat.Matrix) base.Fiter {
 
 // Transform discretizes the Data
 func (m *KBinsDiscretizer) Transform(X, Y mat.Matrix) (Xout, Yout *mat.Dense) {
+	base.MustBeFitted(m)
 	NSamples, NFeatures := X.Dims()
 	switch m.Encode {
 	case "ordinal":
@@ -0,0 +1,219 @@
+package preprocessing
+
+import (
+	"fmt"
+
+	"github.com/pa-m/sklearn/base"
+	"gonum.org/v1/gonum/mat"
+)
+
+// transformE returns ErrNotFitted if fitted is false, checks X has nFeatures columns if nFeatures>0 and calls base.TransformE
+func transformE(m base.Transformer, fitted bool, nFeatures int, X, Y mat.Matrix) (Xout, Yout *mat.Dense, err error) {
+	if !fitted {
+		return nil, nil, base.ErrNotFitted
+	}
+	if nFeatures > 0 {
+		if err = base.CheckNFeatures(X, nFeatures); err != nil {
+			return
+		}
+	}
+	return base.TransformE(m, X, Y)
+}
+
+// labelFitE and labelTransformE are used by label transformers which work on Y only
+func labelFitE(m base.Fiter, X, Y mat.Matrix) (err error) {
+	defer base.Recover(&err)
+	m.Fit(X, Y)
+	return
+}
+
+func labelTransformE(m base.Transformer, fitted bool, X, Y mat.Matrix) (Xout, Yout *mat.Dense, err error) {
+	if !fitted {
+		return nil, nil, base.ErrNotFitted
+	}
+	defer base.Recover(&err)
+	Xout, Yout = m.Transform(X, Y)
+	return
+}
+
+// FitE is Fit returning an error instead of panicking
+func (scaler *MinMaxScaler) FitE(X, Y mat.Matrix) error { return base.FitE(scaler, X, Y) }
+
+// TransformE is Transform returning an error instead of panicking
+func (scaler *MinMaxScaler) TransformE(X, Y mat.Matrix) (Xout, Yout *mat.Dense, err error) {
+	fitted := scaler.IsFitted()
+	nFeatures := 0
+	if fitted {
+		nFeatures = scaler.Scale.RawMatrix().Cols
+	}
+	return transformE(scaler, fitted, nFeatures, X, Y)
+}
+
+// FitE is Fit returning an error instead of panicking
+func (scaler *StandardScaler) FitE(X, Y mat.Matrix) error { return base.FitE(scaler, X, Y) }
+
+// TransformE is Transform returning an error instead of panicking
+func (scaler *StandardScaler) TransformE(X, Y mat.Matrix) (Xout, Yout *mat.Dense, err error) {
+	fitted := scaler.IsFitted()
+	nFeatures := 0
+	if fitted {
+		nFeatures = scaler.Scale.RawMatrix().Cols
+	}
+	return transformE(scaler, fitted, nFeatures, X, Y)
+}
+
+// FitE is Fit returning an error instead of panicking
+func (scaler *RobustScaler) FitE(X, Y mat.Matrix) error { return base.FitE(scaler, X, Y) }
+
+// TransformE is Transform returning an error instead of panicking
+func (scaler *RobustScaler) TransformE(X, Y mat.Matrix) (Xout, Yout *mat.Dense, err error) {
+	nFeatures := 0
+	if scaler.Median != nil {
+		nFeatures = scaler.Median.RawMatrix().Cols
+	} else if scaler.QuantileDivider != nil {
+		nFeatures = scaler.QuantileDivider.RawMatrix().Cols
+	}
+	return transformE(scaler, scaler.IsFitted(), nFeatures, X, Y)
+}
+
+// FitE is Fit returning an error instead of panicking
+func (poly *PolynomialFeatures) FitE(X, Y mat.Matrix) error { return base.FitE(poly, X, Y) }
+
+// TransformE is Transform returning an error instead of panicking
+func (poly *PolynomialFeatures) TransformE(X, Y mat.Matrix) (Xout, Yout *mat.Dense, err error) {
+	nFeatures := 0
+	if len(poly.Powers) > 0 {
+		nFeatures = len(poly.Powers[0])
+	}
+	return transformE(poly, poly.IsFitted(), nFeatures, X, Y)
+}
+
+// FitE is Fit returning an error instead of panicking
+func (m *OneHotEncoder) FitE(X, Y mat.Matrix) error { return base.FitE(m, X, Y) }
+
+// TransformE is Transform returning an error instead of panicking
+func (m *OneHotEncoder) TransformE(X, Y mat.Matrix) (Xout, Yout *mat.Dense, err error) {
+	return transformE(m, m.IsFitted(), len(m.NValues), X, Y)
+}
+
+// FitE is Fit returning an error instead of panicking
+func (m *Shuffler) FitE(X, Y mat.Matrix) error { return base.FitE(m, X, Y) }
+
+// TransformE is Transform returning an error instead of panicking
+func (m *Shuffler) TransformE(X, Y mat.Matrix) (Xout, Yout *mat.Dense, err error) {
+	if m.Perm != nil && X != nil {
+		if r, _ := X.Dims(); r != len(m.Perm) {
+			return nil, nil, fmt.Errorf("%w: X has %d rows, fitted on %d", base.ErrShapeMismatch, r, len(m.Perm))
+		}
+	}
+	return transformE(m, m.IsFitted(), 0, X, Y)
+}
+
+// FitE is Fit returning an error instead of panicking
+func (m *Binarizer) FitE(X, Y mat.Matrix) error { return base.FitE(m, X, Y) }
+
+// TransformE is Transform returning an error instead of panicking
+func (m *Binarizer) TransformE(X, Y mat.Matrix) (Xout, Yout *mat.Dense, err error) {
+	return transformE(m, true, 0, X, Y)
+}
+
+// FitE is Fit returning an error instead of panicking
+func (m *MaxAbsScaler) FitE(X, Y mat.Matrix) error { return base.FitE(m, X, Y) }
+
+// TransformE is Transform returning an error instead of panicking
+func (m *MaxAbsScaler) TransformE(X, Y mat.Matrix) (Xout, Yout *mat.Dense, err error) {
+	return transformE(m, m.IsFitted(), len(m.Scale), X, Y)
+}
+
+// FitE is Fit returning an error instead of panicking
+func (m *Normalizer) FitE(X, Y mat.Matrix) error { return base.FitE(m, X, Y) }
+
+// TransformE is Transform returning an error instead of panicking
+func (m *Normalizer) TransformE(X, Y mat.Matrix) (Xout, Yout *mat.Dense, err error) {
+	return transformE(m, true, 0, X, Y)
+}
+
+// FitE is Fit returning an error instead of panicking
+func (m *KernelCenterer) FitE(X, Y mat.Matrix) error { return base.FitE(m, X, Y) }
+
+// TransformE is Transform returning an error instead of panicking
+func (m *KernelCenterer) TransformE(X, Y mat.Matrix) (Xout, Yout *mat.Dense, err error) {
+	return transformE(m, m.IsFitted(), len(m.KFitRows), X, Y)
+}
+
+// FitE is Fit returning an error instead of panicking
+func (m *QuantileTransformer) FitE(X, Y mat.Matrix) error { return base.FitE(m, X, Y) }
+
+// TransformE is Transform returning an error instead of panicking
+func (m *QuantileTransformer) TransformE(X, Y mat.Matrix) (Xout, Yout *mat.Dense, err error) {
+	fitted := m.IsFitted()
+	nFeatures := 0
+	if fitted {
+		_, nFeatures = m.Quantiles.Dims()
+	}
+	return transformE(m, fitted, nFeatures, X, Y)
+}
+
+// FitE is Fit returning an error instead of panicking
+func (m *PowerTransformer) FitE(X, Y mat.Matrix) error { return base.FitE(m, X, Y) }
+
+// TransformE is Transform returning an error instead of panicking
+func (m *PowerTransformer) TransformE(X, Y mat.Matrix) (Xout, Yout *mat.Dense, err error) {
+	return transformE(m, m.IsFitted(), len(m.Lambdas), X, Y)
+}
+
+// FitE is Fit returning an error instead of panicking
+func (m *KBinsDiscretizer) FitE(X, Y mat.Matrix) error { return base.FitE(m, X, Y) }
+
+// TransformE is Transform returning an error instead of panicking
+func (m *KBinsDiscretizer) TransformE(X, Y mat.Matrix) (Xout, Yout *mat.Dense, err error) {
+	return transformE(m, m.IsFitted(), len(m.BinEdges), X, Y)
+}
+
+// FitE is Fit returning an error instead of panicking
+func (m *FunctionTransformer) FitE(X, Y mat.Matrix) error { return base.FitE(m, X, Y) }
+
+// TransformE is Transform returning an error instead of panicking
+func (m *FunctionTransformer) TransformE(X, Y mat.Matrix) (Xout, Yout *mat.Dense, err error) {
+	return transformE(m, true, 0, X, Y)
+}
+
+// FitE is Fit returning an error instead of panicking
+func (m *Imputer) FitE(X, Y mat.Matrix) error { return base.FitE(m, X, Y) }
+
+// TransformE is Transform returning an error instead of panicking
+func (m *Imputer) TransformE(X, Y mat.Matrix) (Xout, Yout *mat.Dense, err error) {
+	return transformE(m, m.IsFitted(), len(m.MissingValues), X, Y)
+}
+
+// FitE is Fit returning an error instead of panicking
+func (m *PCA) FitE(X, Y mat.Matrix) error { return base.FitE(m, X, Y) }
+
+// TransformE is Transform returning an error instead of panicking
+func (m *PCA) TransformE(X, Y mat.Matrix) (Xout, Yout *mat.Dense, err error) {
+	return transformE(m, m.IsFitted(), len(m.SingularValues), X, Y)
+}
+
+// FitE is Fit returning an error instead of panicking. X is ignored
+func (m *LabelBinarizer) FitE(X, Y mat.Matrix) error { return labelFitE(m, X, Y) }
+
+// TransformE is Transform returning an error instead of panicking
+func (m *LabelBinarizer) TransformE(X, Y mat.Matrix) (Xout, Yout *mat.Dense, err error) {
+	return labelTransformE(m, m.IsFitted(), X, Y)
+}
+
+// FitE is Fit returning an error instead of panicking. X is ignored
+func (m *MultiLabelBinarizer) FitE(X, Y mat.Matrix) error { return labelFitE(m, X, Y) }
+
+// TransformE is Transform returning an error instead of panicking
+func (m *MultiLabelBinarizer) TransformE(X, Y mat.Matrix) (Xout, Yout *mat.Dense, err error) {
+	return labelTransformE(m, m.IsFitted(), X, Y)
+}
+
+// FitE is Fit returning an error instead of panicking. X is ignored
+func (m *LabelEncoder) FitE(X, Y mat.Matrix) error { return labelFitE(m, X, Y) }
+
+// TransformE is Transform returning an error instead of panicking
+func (m *LabelEncoder) TransformE(X, Y mat.Matrix) (Xout, Yout *mat.Dense, err error) {
+	return labelTransformE(m, m.IsFitted(), X, Y)
+}
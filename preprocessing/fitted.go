@@ -0,0 +1,60 @@
+package preprocessing
+
+// IsFitted methods implement base.FittedChecker. stateless transformers are always fitted
+
+// IsFitted returns true when MinMaxScaler has been fitted
+func (scaler *MinMaxScaler) IsFitted() bool { return scaler.Scale != nil }
+
+// IsFitted returns true when StandardScaler has been fitted
+func (scaler *StandardScaler) IsFitted() bool { return scaler.Scale != nil }
+
+// IsFitted returns true when RobustScaler has been fitted
+func (scaler *RobustScaler) IsFitted() bool { return scaler.Tmp != nil }
+
+// IsFitted returns true when PolynomialFeatures has been fitted
+func (poly *PolynomialFeatures) IsFitted() bool { return poly.Powers != nil }
+
+// IsFitted returns true when OneHotEncoder has been fitted
+func (m *OneHotEncoder) IsFitted() bool { return m.NValues != nil }
+
+// IsFitted returns true when Shuffler has been fitted
+func (m *Shuffler) IsFitted() bool { return m.Perm != nil }
+
+// IsFitted returns true for Binarizer
+func (m *Binarizer) IsFitted() bool { return true }
+
+// IsFitted returns true when MaxAbsScaler has been fitted
+func (m *MaxAbsScaler) IsFitted() bool { return m.Scale != nil }
+
+// IsFitted returns true for Normalizer
+func (m *Normalizer) IsFitted() bool { return true }
+
+// IsFitted returns true when KernelCenterer has been fitted
+func (m *KernelCenterer) IsFitted() bool { return m.KFitRows != nil }
+
+// IsFitted returns true when QuantileTransformer has been fitted
+func (m *QuantileTransformer) IsFitted() bool { return m.Quantiles != nil }
+
+// IsFitted returns true when PowerTransformer has been fitted
+func (m *PowerTransformer) IsFitted() bool { return m.Lambdas != nil }
+
+// IsFitted returns true when KBinsDiscretizer has been fitted
+func (m *KBinsDiscretizer) IsFitted() bool { return m.BinEdges != nil }
+
+// IsFitted returns true for FunctionTransformer
+func (m *FunctionTransformer) IsFitted() bool { return true }
+
+// IsFitted returns true when Imputer has been fitted
+func (m *Imputer) IsFitted() bool { return m.MissingValues != nil }
+
+// IsFitted returns true when PCA has been fitted
+func (m *PCA) IsFitted() bool { return m.SingularValues != nil }
+
+// IsFitted returns true when LabelBinarizer has been fitted
+func (m *LabelBinarizer) IsFitted() bool { return m.Classes != nil }
+
+// IsFitted returns true when MultiLabelBinarizer has been fitted
+func (m *MultiLabelBinarizer) IsFitted() bool { return m.Classes != nil }
+
+// IsFitted returns true when LabelEncoder has been fitted
+func (m *LabelEncoder) IsFitted() bool { return m.Classes != nil }
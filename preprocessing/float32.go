@@ -0,0 +1,116 @@
+package preprocessing
+
+import (
+	"math"
+
+	"github.com/pa-m/sklearn/base"
+	"gonum.org/v1/gonum/mat"
+)
+
+// matRow copies row i of X to dst, without converting the whole of a base.General32 X
+func matRow(dst []float64, i int, X mat.Matrix) []float64 {
+	if X32, ok := X.(base.General32); ok {
+		return X32.Row(dst, i)
+	}
+	return mat.Row(dst, i, X)
+}
+
+// transform32 returns a float32 copy of X with each element v of column j replaced by (v-offset[j])/scale[j]
+func transform32(X base.General32, offset, scale []float64) base.General32 {
+	Xout := base.NewGeneral32(X.Rows, X.Cols, nil)
+	for i := 0; i < X.Rows; i++ {
+		out := Xout.RawRowView(i)
+		for j, v := range X.RawRowView(i) {
+			out[j] = float32((float64(v) - offset[j]) / scale[j])
+		}
+	}
+	return Xout
+}
+
+// fit32 computes Mean and Var of a float32 X, accumulating them as float64
+func (scaler *StandardScaler) fit32(X base.General32) *StandardScaler {
+	scaler.Reset()
+	nSamples, nFeatures := X.Dims()
+	scaler.Mean, scaler.Var, scaler.Scale = mat.NewDense(1, nFeatures, nil), mat.NewDense(1, nFeatures, nil), mat.NewDense(1, nFeatures, nil)
+	if nSamples == 0 {
+		return scaler
+	}
+	mean, variance, scale := scaler.Mean.RawRowView(0), scaler.Var.RawRowView(0), scaler.Scale.RawRowView(0)
+	for i := 0; i < nSamples; i++ {
+		for j, v := range X.RawRowView(i) {
+			mean[j] += float64(v)
+		}
+	}
+	for j := range mean {
+		mean[j] /= float64(nSamples)
+	}
+	for i := 0; i < nSamples; i++ {
+		for j, v := range X.RawRowView(i) {
+			d := float64(v) - mean[j]
+			variance[j] += d * d
+		}
+	}
+	for j, v := range variance {
+		variance[j] = v / float64(nSamples)
+		if variance[j] == 0 {
+			scale[j] = 1
+		} else {
+			scale[j] = math.Sqrt(variance[j])
+		}
+	}
+	scaler.NSamplesSeen = nSamples
+	return scaler
+}
+
+// Transform32 scales a float32 X to a float32 copy
+func (scaler *StandardScaler) Transform32(X base.General32) base.General32 {
+	offset, scale := make([]float64, X.Cols), make([]float64, X.Cols)
+	for j := range offset {
+		offset[j], scale[j] = 0, 1
+		if scaler.WithMean {
+			offset[j] = scaler.Mean.At(0, j)
+		}
+		if scaler.WithStd {
+			scale[j] = scaler.Scale.At(0, j)
+		}
+	}
+	return transform32(X, offset, scale)
+}
+
+// Transform32 scales a float32 X to a float32 copy
+func (scaler *MinMaxScaler) Transform32(X base.General32) base.General32 {
+	Xout := base.NewGeneral32(X.Rows, X.Cols, nil)
+	min, scale := scaler.Min.RawRowView(0), scaler.Scale.RawRowView(0)
+	for i := 0; i < X.Rows; i++ {
+		out := Xout.RawRowView(i)
+		for j, v := range X.RawRowView(i) {
+			out[j] = float32(min[j] + float64(v)*scale[j])
+		}
+	}
+	return Xout
+}
+
+// fit32 computes MaxAbs of a float32 X
+func (m *MaxAbsScaler) fit32(X base.General32) *MaxAbsScaler {
+	m.MaxAbs = make([]float64, X.Cols)
+	m.Scale = make([]float64, X.Cols)
+	for i := 0; i < X.Rows; i++ {
+		for j, v := range X.RawRowView(i) {
+			m.MaxAbs[j] = math.Max(m.MaxAbs[j], math.Abs(float64(v)))
+		}
+	}
+	for i, v := range m.MaxAbs {
+		if v > 0. {
+			m.Scale[i] = v
+		} else {
+			m.Scale[i] = 1.
+		}
+	}
+	m.NSamplesSeen += X.Rows
+	return m
+}
+
+// Transform32 scales a float32 X to a float32 copy
+func (m *MaxAbsScaler) Transform32(X base.General32) base.General32 {
+	return transform32(X, make([]float64, X.Cols), m.Scale)
+}
@@ -0,0 +1,37 @@
+package preprocessing
+
+import (
+	"testing"
+
+	"github.com/pa-m/sklearn/base"
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestScalers_Float32(t *testing.T) {
+	X := mat.NewDense(50, 4, nil)
+	X.Apply(func(i, j int, v float64) float64 { return rand.NormFloat64()*float64(j+1) + float64(j) }, X)
+	X = base.General32Of(X).ToDense()
+	X32 := base.General32Of(X)
+
+	ss, ss32 := NewStandardScaler(), NewStandardScaler()
+	want, _ := ss.FitTransform(X, nil)
+	ss32.Fit(X32, nil)
+	if !mat.EqualApprox(ss.Mean, ss32.Mean, 1e-10) || !mat.EqualApprox(ss.Scale, ss32.Scale, 1e-10) || !mat.EqualApprox(want, ss32.Transform32(X32), 1e-5) {
+		t.Error("StandardScaler float32 differs")
+	}
+
+	mm, mm32 := NewMinMaxScaler([]float64{0, 1}), NewMinMaxScaler([]float64{0, 1})
+	want, _ = mm.FitTransform(X, nil)
+	mm32.Fit(X32, nil)
+	if !mat.EqualApprox(mm.Scale, mm32.Scale, 1e-10) || !mat.EqualApprox(want, mm32.Transform32(X32), 1e-5) {
+		t.Error("MinMaxScaler float32 differs")
+	}
+
+	ma, ma32 := NewMaxAbsScaler(), NewMaxAbsScaler()
+	want, _ = ma.FitTransform(X, nil)
+	ma32.Fit(X32, nil)
+	if !mat.EqualApprox(want, ma32.Transform32(X32), 1e-5) {
+		t.Error("MaxAbsScaler float32 differs")
+	}
+}
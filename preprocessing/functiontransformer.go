@@ -20,8 +20,8 @@ func NewFunctionTransformer(f, invf func(X, Y *mat.Dense) (X1, Y1 *mat.Dense)) *
 
 // TransformerClone ...
 func (m *FunctionTransformer) TransformerClone() base.Transformer {
-	var clone = *m
-	return &clone
+	clone := *m
+	return base.DeepCopy(&clone).(*FunctionTransformer)
 }
 
 // Fit ...
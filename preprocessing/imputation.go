@@ -22,7 +22,8 @@ func NewImputer() *Imputer { return &Imputer{} }
 // TransformerClone ...
 func (m *Imputer) TransformerClone() base.Transformer {
 	clone := *m
-	return &clone
+	clone.MissingValues = nil
+	return base.DeepCopy(&clone).(*Imputer)
 }
 
 // Fit for Imputer ...
@@ -60,6 +61,7 @@ func (m *Imputer) Fit(Xmatrix, Ymatrix mat.Matrix) base.Fiter {
 
 // Transform for Imputer ...
 func (m *Imputer) Transform(Xmatrix, Ymatrix mat.Matrix) (Xout, Yout *mat.Dense) {
+	base.MustBeFitted(m)
 	X, Y := base.ToDense(Xmatrix), base.ToDense(Ymatrix)
 	Xmat := X.RawMatrix()
 	Xout, Yout = mat.NewDense(Xmat.Rows, Xmat.Cols, nil), Y
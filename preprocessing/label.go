@@ -23,7 +23,8 @@ func NewLabelBinarizer(NegLabel, PosLabel float64) *LabelBinarizer {
 // TransformerClone ...
 func (m *LabelBinarizer) TransformerClone() base.Transformer {
 	clone := *m
-	return &clone
+	clone.Classes = nil
+	return base.DeepCopy(&clone).(*LabelBinarizer)
 }
 
 // Fit for binarizer register classes
@@ -51,6 +52,7 @@ func (m *LabelBinarizer) Fit(Xmatrix, Ymatrix mat.Matrix) base.Fiter {
 
 // Transform for LabelBinarizer
 func (m *LabelBinarizer) Transform(X, Y mat.Matrix) (Xout, Yout *mat.Dense) {
+	base.MustBeFitted(m)
 	Xout = base.ToDense(X)
 	NSamples, _ := Y.Dims()
 	NOutputs := 0
@@ -117,7 +119,8 @@ func NewMultiLabelBinarizer() *MultiLabelBinarizer { return &MultiLabelBinarizer
 // TransformerClone ...
 func (m *MultiLabelBinarizer) TransformerClone() base.Transformer {
 	clone := *m
-	return &clone
+	clone.Classes = nil
+	return base.DeepCopy(&clone).(*MultiLabelBinarizer)
 }
 
 // Fit for MultiLabelBinarizer ...
@@ -168,6 +171,7 @@ func (m *MultiLabelBinarizer) Fit2(X mat.Matrix, Y interface{}) *MultiLabelBinar
 // Transform for MultiLabelBinarizer ...
 // Y type must be the same passed int Fit
 func (m *MultiLabelBinarizer) Transform(X, Y mat.Matrix) (Xout, Yout *mat.Dense) {
+	base.MustBeFitted(m)
 	return m.Transform2(X, Y)
 }
 
@@ -281,7 +285,8 @@ func NewLabelEncoder() *LabelEncoder { return &LabelEncoder{} }
 // TransformerClone ...
 func (m *LabelEncoder) TransformerClone() base.Transformer {
 	clone := *m
-	return &clone
+	clone.Classes, clone.Support = nil, nil
+	return base.DeepCopy(&clone).(*LabelEncoder)
 }
 
 // Fit for LabelEncoder ...
@@ -322,6 +327,7 @@ func (m *LabelEncoder) PartialFit(X, Y *mat.Dense) base.Transformer {
 
 // Transform for LabelEncoder ...
 func (m *LabelEncoder) Transform(X, Y mat.Matrix) (Xout, Yout *mat.Dense) {
+	base.MustBeFitted(m)
 	Ymat := base.ToDense(Y).RawMatrix()
 	Yout = mat.NewDense(Ymat.Rows, Ymat.Cols, nil)
 	Youtmat := Yout.RawMatrix()
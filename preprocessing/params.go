@@ -0,0 +1,155 @@
+package preprocessing
+
+import "github.com/pa-m/sklearn/base"
+
+// GetParams returns the parameters of MinMaxScaler. see base.GetFieldParams
+func (scaler *MinMaxScaler) GetParams() map[string]interface{} { return base.GetFieldParams(scaler) }
+
+// SetParams sets the parameters of MinMaxScaler. see base.SetFieldParams
+func (scaler *MinMaxScaler) SetParams(params map[string]interface{}) error {
+	return base.SetFieldParams(scaler, params)
+}
+
+// GetParams returns the parameters of StandardScaler. see base.GetFieldParams
+func (scaler *StandardScaler) GetParams() map[string]interface{} { return base.GetFieldParams(scaler) }
+
+// SetParams sets the parameters of StandardScaler. see base.SetFieldParams
+func (scaler *StandardScaler) SetParams(params map[string]interface{}) error {
+	return base.SetFieldParams(scaler, params)
+}
+
+// GetParams returns the parameters of RobustScaler. see base.GetFieldParams
+func (scaler *RobustScaler) GetParams() map[string]interface{} { return base.GetFieldParams(scaler) }
+
+// SetParams sets the parameters of RobustScaler. see base.SetFieldParams
+func (scaler *RobustScaler) SetParams(params map[string]interface{}) error {
+	return base.SetFieldParams(scaler, params)
+}
+
+// GetParams returns the parameters of PolynomialFeatures. see base.GetFieldParams
+func (poly *PolynomialFeatures) GetParams() map[string]interface{} { return base.GetFieldParams(poly) }
+
+// SetParams sets the parameters of PolynomialFeatures. see base.SetFieldParams
+func (poly *PolynomialFeatures) SetParams(params map[string]interface{}) error {
+	return base.SetFieldParams(poly, params)
+}
+
+// GetParams returns the parameters of OneHotEncoder. see base.GetFieldParams
+func (m *OneHotEncoder) GetParams() map[string]interface{} { return base.GetFieldParams(m) }
+
+// SetParams sets the parameters of OneHotEncoder. see base.SetFieldParams
+func (m *OneHotEncoder) SetParams(params map[string]interface{}) error {
+	return base.SetFieldParams(m, params)
+}
+
+// GetParams returns the parameters of Shuffler. see base.GetFieldParams
+func (m *Shuffler) GetParams() map[string]interface{} { return base.GetFieldParams(m) }
+
+// SetParams sets the parameters of Shuffler. see base.SetFieldParams
+func (m *Shuffler) SetParams(params map[string]interface{}) error {
+	return base.SetFieldParams(m, params)
+}
+
+// GetParams returns the parameters of Binarizer. see base.GetFieldParams
+func (m *Binarizer) GetParams() map[string]interface{} { return base.GetFieldParams(m) }
+
+// SetParams sets the parameters of Binarizer. see base.SetFieldParams
+func (m *Binarizer) SetParams(params map[string]interface{}) error {
+	return base.SetFieldParams(m, params)
+}
+
+// GetParams returns the parameters of MaxAbsScaler. see base.GetFieldParams
+func (m *MaxAbsScaler) GetParams() map[string]interface{} { return base.GetFieldParams(m) }
+
+// SetParams sets the parameters of MaxAbsScaler. see base.SetFieldParams
+func (m *MaxAbsScaler) SetParams(params map[string]interface{}) error {
+	return base.SetFieldParams(m, params)
+}
+
+// GetParams returns the parameters of Normalizer. see base.GetFieldParams
+func (m *Normalizer) GetParams() map[string]interface{} { return base.GetFieldParams(m) }
+
+// SetParams sets the parameters of Normalizer. see base.SetFieldParams
+func (m *Normalizer) SetParams(params map[string]interface{}) error {
+	return base.SetFieldParams(m, params)
+}
+
+// GetParams returns the parameters of KernelCenterer. see base.GetFieldParams
+func (m *KernelCenterer) GetParams() map[string]interface{} { return base.GetFieldParams(m) }
+
+// SetParams sets the parameters of KernelCenterer. see base.SetFieldParams
+func (m *KernelCenterer) SetParams(params map[string]interface{}) error {
+	return base.SetFieldParams(m, params)
+}
+
+// GetParams returns the parameters of QuantileTransformer. see base.GetFieldParams
+func (m *QuantileTransformer) GetParams() map[string]interface{} { return base.GetFieldParams(m) }
+
+// SetParams sets the parameters of QuantileTransformer. see base.SetFieldParams
+func (m *QuantileTransformer) SetParams(params map[string]interface{}) error {
+	return base.SetFieldParams(m, params)
+}
+
+// GetParams returns the parameters of PowerTransformer. see base.GetFieldParams
+func (m *PowerTransformer) GetParams() map[string]interface{} { return base.GetFieldParams(m) }
+
+// SetParams sets the parameters of PowerTransformer. see base.SetFieldParams
+func (m *PowerTransformer) SetParams(params map[string]interface{}) error {
+	return base.SetFieldParams(m, params)
+}
+
+// GetParams returns the parameters of KBinsDiscretizer. see base.GetFieldParams
+func (m *KBinsDiscretizer) GetParams() map[string]interface{} { return base.GetFieldParams(m) }
+
+// SetParams sets the parameters of KBinsDiscretizer. see base.SetFieldParams
+func (m *KBinsDiscretizer) SetParams(params map[string]interface{}) error {
+	return base.SetFieldParams(m, params)
+}
+
+// GetParams returns the parameters of FunctionTransformer. see base.GetFieldParams
+func (m *FunctionTransformer) GetParams() map[string]interface{} { return base.GetFieldParams(m) }
+
+// SetParams sets the parameters of FunctionTransformer. see base.SetFieldParams
+func (m *FunctionTransformer) SetParams(params map[string]interface{}) error {
+	return base.SetFieldParams(m, params)
+}
+
+// GetParams returns the parameters of Imputer. see base.GetFieldParams
+func (m *Imputer) GetParams() map[string]interface{} { return base.GetFieldParams(m) }
+
+// SetParams sets the parameters of Imputer. see base.SetFieldParams
+func (m *Imputer) SetParams(params map[string]interface{}) error {
+	return base.SetFieldParams(m, params)
+}
+
+// GetParams returns the parameters of PCA. see base.GetFieldParams
+func (m *PCA) GetParams() map[string]interface{} { return base.GetFieldParams(m) }
+
+// SetParams sets the parameters of PCA. see base.SetFieldParams
+func (m *PCA) SetParams(params map[string]interface{}) error {
+	return base.SetFieldParams(m, params)
+}
+
+// GetParams returns the parameters of LabelBinarizer. see base.GetFieldParams
+func (m *LabelBinarizer) GetParams() map[string]interface{} { return base.GetFieldParams(m) }
+
+// SetParams sets the parameters of LabelBinarizer. see base.SetFieldParams
+func (m *LabelBinarizer) SetParams(params map[string]interface{}) error {
+	return base.SetFieldParams(m, params)
+}
+
+// GetParams returns the parameters of MultiLabelBinarizer. see base.GetFieldParams
+func (m *MultiLabelBinarizer) GetParams() map[string]interface{} { return base.GetFieldParams(m) }
+
+// SetParams sets the parameters of MultiLabelBinarizer. see base.SetFieldParams
+func (m *MultiLabelBinarizer) SetParams(params map[string]interface{}) error {
+	return base.SetFieldParams(m, params)
+}
+
+// GetParams returns the parameters of LabelEncoder. see base.GetFieldParams
+func (m *LabelEncoder) GetParams() map[string]interface{} { return base.GetFieldParams(m) }
+
+// SetParams sets the parameters of LabelEncoder. see base.SetFieldParams
+func (m *LabelEncoder) SetParams(params map[string]interface{}) error {
+	return base.SetFieldParams(m, params)
+}
@@ -12,7 +12,9 @@ type PCA struct {
 	mat.SVD
 	MinVarianceRatio                       float64
 	NComponents                            int
-	SingularValues, ExplainedVarianceRatio []float64
+	SingularValues, ExplainedVarianceRatio []float64 `param:"-"`
+	// v is the right singular vectors matrix
+	v *mat.Dense
 }
 
 // NewPCA returns a *PCA
@@ -21,7 +23,8 @@ func NewPCA() *PCA { return &PCA{} }
 // TransformerClone ...
 func (m *PCA) TransformerClone() base.Transformer {
 	clone := *m
-	return &clone
+	clone.SVD, clone.SingularValues, clone.ExplainedVarianceRatio, clone.v = mat.SVD{}, nil, nil, nil
+	return base.DeepCopy(&clone).(*PCA)
 }
 
 // Fit computes the svd of X
@@ -32,6 +35,8 @@ func (m *PCA) Fit(Xmatrix, Ymatrix mat.Matrix) base.Fiter {
 	m.SingularValues = make([]float64, c)
 	m.ExplainedVarianceRatio = make([]float64, c)
 	m.SVD.Values(m.SingularValues)
+	m.v = new(mat.Dense)
+	m.SVD.VTo(m.v)
 	floats.MulTo(m.ExplainedVarianceRatio, m.SingularValues, m.SingularValues)
 	floats.Scale(1./floats.Sum(m.ExplainedVarianceRatio), m.ExplainedVarianceRatio)
 
@@ -52,8 +57,8 @@ func (m *PCA) Fit(Xmatrix, Ymatrix mat.Matrix) base.Fiter {
 
 // Transform Transforms X
 func (m *PCA) Transform(X, Y mat.Matrix) (Xout, Yout *mat.Dense) {
-	var v = new(mat.Dense)
-	m.SVD.VTo(v)
+	base.MustBeFitted(m)
+	v := m.v
 	nSamples, _ := X.Dims()
 	vRows, _ := v.Dims()
 	Xout = mat.NewDense(nSamples, m.NComponents, nil)
@@ -75,8 +80,7 @@ func (m *PCA) InverseTransform(X, Y *mat.Dense) (Xout, Yout *mat.Dense) {
 		return X, Y
 	}
 
-	var v = new(mat.Dense)
-	m.SVD.VTo(v)
+	v := m.v
 	nSamples, _ := X.Dims()
 	_, vCols := v.Dims()
 	Xout = mat.NewDense(nSamples, vCols, nil)
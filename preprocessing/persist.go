@@ -0,0 +1,188 @@
+package preprocessing
+
+import (
+	"fmt"
+
+	"github.com/pa-m/sklearn/base"
+	"gonum.org/v1/gonum/mat"
+)
+
+func init() {
+	base.Register(&MinMaxScaler{})
+	base.Register(&StandardScaler{})
+	base.Register(&RobustScaler{})
+	base.Register(&PolynomialFeatures{})
+	base.Register(&OneHotEncoder{})
+	base.Register(&Shuffler{})
+	base.Register(&Binarizer{})
+	base.Register(&MaxAbsScaler{})
+	base.Register(&Normalizer{})
+	base.Register(&KernelCenterer{})
+	base.Register(&QuantileTransformer{})
+	base.Register(&PowerTransformer{})
+	base.Register(&KBinsDiscretizer{})
+	base.Register(&FunctionTransformer{})
+	base.Register(&Imputer{})
+	base.Register(&PCA{})
+	base.Register(&LabelBinarizer{})
+	base.Register(&MultiLabelBinarizer{})
+	base.Register(&LabelEncoder{})
+}
+
+// MarshalState allows MinMaxScaler to be saved by base.Save
+func (scaler *MinMaxScaler) MarshalState() (*base.State, error) { return base.MarshalFields(scaler) }
+
+// UnmarshalState restores a MinMaxScaler saved by base.Save
+func (scaler *MinMaxScaler) UnmarshalState(st *base.State) error {
+	return base.UnmarshalFields(scaler, st)
+}
+
+// MarshalState allows StandardScaler to be saved by base.Save
+func (scaler *StandardScaler) MarshalState() (*base.State, error) { return base.MarshalFields(scaler) }
+
+// UnmarshalState restores a StandardScaler saved by base.Save
+func (scaler *StandardScaler) UnmarshalState(st *base.State) error {
+	return base.UnmarshalFields(scaler, st)
+}
+
+// MarshalState allows RobustScaler to be saved by base.Save
+func (scaler *RobustScaler) MarshalState() (*base.State, error) { return base.MarshalFields(scaler) }
+
+// UnmarshalState restores a RobustScaler saved by base.Save
+func (scaler *RobustScaler) UnmarshalState(st *base.State) error {
+	return base.UnmarshalFields(scaler, st)
+}
+
+// MarshalState allows PolynomialFeatures to be saved by base.Save
+func (poly *PolynomialFeatures) MarshalState() (*base.State, error) { return base.MarshalFields(poly) }
+
+// UnmarshalState restores a PolynomialFeatures saved by base.Save
+func (poly *PolynomialFeatures) UnmarshalState(st *base.State) error {
+	return base.UnmarshalFields(poly, st)
+}
+
+// MarshalState allows OneHotEncoder to be saved by base.Save
+func (m *OneHotEncoder) MarshalState() (*base.State, error) { return base.MarshalFields(m) }
+
+// UnmarshalState restores a OneHotEncoder saved by base.Save
+func (m *OneHotEncoder) UnmarshalState(st *base.State) error { return base.UnmarshalFields(m, st) }
+
+// MarshalState allows Shuffler to be saved by base.Save. RandomState is not saved
+func (m *Shuffler) MarshalState() (*base.State, error) { return base.MarshalFields(m) }
+
+// UnmarshalState restores a Shuffler saved by base.Save
+func (m *Shuffler) UnmarshalState(st *base.State) error { return base.UnmarshalFields(m, st) }
+
+// MarshalState allows Binarizer to be saved by base.Save
+func (m *Binarizer) MarshalState() (*base.State, error) { return base.MarshalFields(m) }
+
+// UnmarshalState restores a Binarizer saved by base.Save
+func (m *Binarizer) UnmarshalState(st *base.State) error { return base.UnmarshalFields(m, st) }
+
+// MarshalState allows MaxAbsScaler to be saved by base.Save
+func (m *MaxAbsScaler) MarshalState() (*base.State, error) { return base.MarshalFields(m) }
+
+// UnmarshalState restores a MaxAbsScaler saved by base.Save
+func (m *MaxAbsScaler) UnmarshalState(st *base.State) error { return base.UnmarshalFields(m, st) }
+
+// MarshalState allows Normalizer to be saved by base.Save
+func (m *Normalizer) MarshalState() (*base.State, error) { return base.MarshalFields(m) }
+
+// UnmarshalState restores a Normalizer saved by base.Save
+func (m *Normalizer) UnmarshalState(st *base.State) error { return base.UnmarshalFields(m, st) }
+
+// MarshalState allows KernelCenterer to be saved by base.Save
+func (m *KernelCenterer) MarshalState() (*base.State, error) { return base.MarshalFields(m) }
+
+// UnmarshalState restores a KernelCenterer saved by base.Save
+func (m *KernelCenterer) UnmarshalState(st *base.State) error { return base.UnmarshalFields(m, st) }
+
+// MarshalState allows QuantileTransformer to be saved by base.Save. RandomState is not saved
+func (m *QuantileTransformer) MarshalState() (*base.State, error) {
+	st, err := base.MarshalFields(m)
+	if err == nil && m.Quantiles != nil {
+		st.Matrices["Quantiles"] = mat.DenseCopyOf(m.Quantiles)
+	}
+	return st, err
+}
+
+// UnmarshalState restores a QuantileTransformer saved by base.Save
+func (m *QuantileTransformer) UnmarshalState(st *base.State) error {
+	if err := base.UnmarshalFields(m, st); err != nil {
+		return err
+	}
+	if Q, ok := st.Matrices["Quantiles"]; ok {
+		m.Quantiles = Q
+		m.references = make([]float64, m.NQuantiles)
+		for i := range m.references {
+			m.references[i] = float64(i) / float64(m.NQuantiles-1)
+		}
+	}
+	return nil
+}
+
+// MarshalState allows PowerTransformer to be saved by base.Save
+func (m *PowerTransformer) MarshalState() (*base.State, error) { return base.MarshalFields(m) }
+
+// UnmarshalState restores a PowerTransformer saved by base.Save
+func (m *PowerTransformer) UnmarshalState(st *base.State) error { return base.UnmarshalFields(m, st) }
+
+// MarshalState allows KBinsDiscretizer to be saved by base.Save
+func (m *KBinsDiscretizer) MarshalState() (*base.State, error) { return base.MarshalFields(m) }
+
+// UnmarshalState restores a KBinsDiscretizer saved by base.Save
+func (m *KBinsDiscretizer) UnmarshalState(st *base.State) error { return base.UnmarshalFields(m, st) }
+
+// MarshalState returns an error for FunctionTransformer as funcs can't be saved
+func (m *FunctionTransformer) MarshalState() (*base.State, error) {
+	if m.Func != nil || m.InverseFunc != nil {
+		return nil, fmt.Errorf("%w: FunctionTransformer funcs", base.ErrNotPersistable)
+	}
+	return base.MarshalFields(m)
+}
+
+// UnmarshalState restores a FunctionTransformer saved by base.Save
+func (m *FunctionTransformer) UnmarshalState(st *base.State) error {
+	return base.UnmarshalFields(m, st)
+}
+
+// MarshalState allows Imputer to be saved by base.Save
+func (m *Imputer) MarshalState() (*base.State, error) { return base.MarshalFields(m) }
+
+// UnmarshalState restores an Imputer saved by base.Save
+func (m *Imputer) UnmarshalState(st *base.State) error { return base.UnmarshalFields(m, st) }
+
+// MarshalState allows PCA to be saved by base.Save. the SVD itself is not saved, only its right singular vectors
+func (m *PCA) MarshalState() (*base.State, error) {
+	st, err := base.MarshalFields(m)
+	if err == nil && m.v != nil {
+		st.Matrices["V"] = m.v
+	}
+	return st, err
+}
+
+// UnmarshalState restores a PCA saved by base.Save
+func (m *PCA) UnmarshalState(st *base.State) error {
+	m.v = st.Matrices["V"]
+	return base.UnmarshalFields(m, st)
+}
+
+// MarshalState allows LabelBinarizer to be saved by base.Save
+func (m *LabelBinarizer) MarshalState() (*base.State, error) { return base.MarshalFields(m) }
+
+// UnmarshalState restores a LabelBinarizer saved by base.Save
+func (m *LabelBinarizer) UnmarshalState(st *base.State) error { return base.UnmarshalFields(m, st) }
+
+// MarshalState allows MultiLabelBinarizer to be saved by base.Save. Less must be nil
+func (m *MultiLabelBinarizer) MarshalState() (*base.State, error) { return base.MarshalFields(m) }
+
+// UnmarshalState restores a MultiLabelBinarizer saved by base.Save
+func (m *MultiLabelBinarizer) UnmarshalState(st *base.State) error {
+	return base.UnmarshalFields(m, st)
+}
+
+// MarshalState allows LabelEncoder to be saved by base.Save
+func (m *LabelEncoder) MarshalState() (*base.State, error) { return base.MarshalFields(m) }
+
+// UnmarshalState restores a LabelEncoder saved by base.Save
+func (m *LabelEncoder) UnmarshalState(st *base.State) error { return base.UnmarshalFields(m, st) }
@@ -0,0 +1,45 @@
+package preprocessing
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/pa-m/sklearn/base"
+	"gonum.org/v1/gonum/mat"
+)
+
+var _ = []base.Persister{&MinMaxScaler{}, &StandardScaler{}, &RobustScaler{}, &PolynomialFeatures{}, &OneHotEncoder{}, &Shuffler{}, &Binarizer{}, &MaxAbsScaler{}, &Normalizer{}, &KernelCenterer{}, &QuantileTransformer{}, &PowerTransformer{}, &KBinsDiscretizer{}, &FunctionTransformer{}, &Imputer{}, &PCA{}, &LabelBinarizer{}, &MultiLabelBinarizer{}, &LabelEncoder{}}
+
+func TestSaveLoad(t *testing.T) {
+	X := mat.NewDense(20, 3, nil)
+	X.Apply(func(i, j int, _ float64) float64 { return float64((i*7+j*3)%11) + float64(j) }, X)
+	kbins := NewKBinsDiscretizer(3)
+	kbins.Strategy = "uniform"
+	for _, m := range []base.Transformer{
+		NewMinMaxScaler([]float64{0, 1}), NewStandardScaler(), NewDefaultRobustScaler(), NewPolynomialFeatures(2),
+		NewMaxAbsScaler(), NewQuantileTransformer(10, "uniform", nil), NewPowerTransformer(), kbins, NewPCA(),
+	} {
+		m.Fit(X, nil)
+		buf := new(bytes.Buffer)
+		if err := base.Save(buf, m.(base.Persister)); err != nil {
+			t.Fatalf("%T: %s", m, err)
+		}
+		loaded, err := base.LoadTransformer(buf)
+		if err != nil {
+			t.Fatalf("%T: %s", m, err)
+		}
+		expected, _ := m.Transform(mat.DenseCopyOf(X), nil)
+		actual, _ := loaded.Transform(mat.DenseCopyOf(X), nil)
+		if !mat.EqualApprox(expected, actual, 1e-12) {
+			t.Errorf("%T: loaded transformer output differs", m)
+		}
+	}
+	if err := base.Save(new(bytes.Buffer), NewFunctionTransformer(nil, nil)); err != nil {
+		t.Errorf("FunctionTransformer without funcs should be saved, got %v", err)
+	}
+	f := func(X, Y *mat.Dense) (*mat.Dense, *mat.Dense) { return X, Y }
+	if err := base.Save(new(bytes.Buffer), NewFunctionTransformer(f, f)); !errors.Is(err, base.ErrNotPersistable) {
+		t.Errorf("expected ErrNotPersistable for FunctionTransformer, got %v", err)
+	}
+}
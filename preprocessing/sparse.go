@@ -0,0 +1,85 @@
+package preprocessing
+
+import (
+	"math"
+
+	"github.com/pa-m/sklearn/base"
+)
+
+// fitSparse fits MaxAbsScaler on the non-zero values of X
+func (m *MaxAbsScaler) fitSparse(X *base.CSR) *MaxAbsScaler {
+	m.MaxAbs = make([]float64, X.Cols)
+	m.Scale = make([]float64, X.Cols)
+	X.DoNonZero(func(_, j int, v float64) {
+		m.MaxAbs[j] = math.Max(m.MaxAbs[j], math.Abs(v))
+	})
+	for i, v := range m.MaxAbs {
+		if v > 0. {
+			m.Scale[i] = v
+		} else {
+			m.Scale[i] = 1.
+		}
+	}
+	m.NSamplesSeen += X.Rows
+	return m
+}
+
+// TransformSparse scales X keeping it sparse
+func (m *MaxAbsScaler) TransformSparse(X *base.CSR) *base.CSR {
+	Xout := copyCSR(X)
+	for p, j := range Xout.Indices {
+		Xout.Data[p] /= m.Scale[j]
+	}
+	return Xout
+}
+
+// TransformSparse normalizes X keeping it sparse
+func (m *Normalizer) TransformSparse(X *base.CSR) *base.CSR {
+	Xout := copyCSR(X)
+	var nrm func(acc, v float64) float64
+	switch m.Norm {
+	case "l1":
+		nrm = func(acc, v float64) float64 { return acc + math.Abs(v) }
+	case "max":
+		nrm = func(acc, v float64) float64 { return math.Max(acc, math.Abs(v)) }
+	default:
+		nrm = func(acc, v float64) float64 { return acc + v*v }
+	}
+	if m.Axis == 0 {
+		m.nrmValues = make([]float64, X.Cols)
+	} else {
+		m.nrmValues = make([]float64, X.Rows)
+	}
+	index := func(i, p int) int {
+		if m.Axis == 0 {
+			return Xout.Indices[p]
+		}
+		return i
+	}
+	for i := 0; i < Xout.Rows; i++ {
+		for p := Xout.Indptr[i]; p < Xout.Indptr[i+1]; p++ {
+			k := index(i, p)
+			m.nrmValues[k] = nrm(m.nrmValues[k], Xout.Data[p])
+		}
+	}
+	if m.Norm != "l1" && m.Norm != "max" {
+		for k, v := range m.nrmValues {
+			m.nrmValues[k] = math.Sqrt(v)
+		}
+	}
+	for i := 0; i < Xout.Rows; i++ {
+		for p := Xout.Indptr[i]; p < Xout.Indptr[i+1]; p++ {
+			if v := m.nrmValues[index(i, p)]; v != 0 {
+				Xout.Data[p] /= v
+			}
+		}
+	}
+	return Xout
+}
+
+// copyCSR returns a copy of X with its own Data
+func copyCSR(X *base.CSR) *base.CSR {
+	Xout := *X
+	Xout.Data = append([]float64(nil), X.Data...)
+	return &Xout
+}
@@ -0,0 +1,30 @@
+package preprocessing
+
+import (
+	"testing"
+
+	"github.com/pa-m/sklearn/base"
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestSparseScalers(t *testing.T) {
+	X := mat.NewDense(4, 3, []float64{1, 0, -4, 0, 0, 2, 3, 0, 0, -6, 0, 1})
+	for _, norm := range []string{"l1", "l2", "max"} {
+		for _, axis := range []int{0, 1} {
+			dense, sparse := &Normalizer{Norm: norm, Axis: axis}, &Normalizer{Norm: norm, Axis: axis}
+			want, _ := dense.FitTransform(X, nil)
+			if got := sparse.TransformSparse(base.ToCSR(X)); !mat.EqualApprox(want, got, 1e-12) {
+				t.Errorf("Normalizer %s axis %d: expected\n%g\ngot\n%g", norm, axis, mat.Formatted(want), mat.Formatted(got))
+			}
+		}
+	}
+	dense, sparse := NewMaxAbsScaler(), NewMaxAbsScaler()
+	want, _ := dense.FitTransform(X, nil)
+	sparse.Fit(base.ToCSC(X), nil)
+	if got := sparse.TransformSparse(base.ToCSR(X)); !mat.EqualApprox(want, got, 1e-12) {
+		t.Errorf("MaxAbsScaler: expected\n%g\ngot\n%g", mat.Formatted(want), mat.Formatted(got))
+	}
+	if got, _ := sparse.Transform(base.ToCSR(X), nil); !mat.EqualApprox(want, got, 1e-12) {
+		t.Error("MaxAbsScaler Transform of a sparse X differs")
+	}
+}
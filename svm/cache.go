@@ -1,59 +1,76 @@
 package svm
 
-import (
-	"unsafe"
+// CacheStats counts the lookups of the rows of the kernel matrix during the training of a model.
+// a miss computes a row, whose cost is a kernel evaluation per sample
+type CacheStats struct {
+	Hits, Misses int
+}
 
-	"gonum.org/v1/gonum/mat"
-)
+// kernelCache is a least recently used cache of the rows of the kernel matrix of samples, bounded by a size in MB.
+// row u is the kernel between samples[u] and each sample
+type kernelCache struct {
+	K       func(i, j int) float64
+	samples []int
+	rows    [][]float64
+	// prev and next link the cached rows from the most to the least recently used, index len(rows) being the head
+	prev, next    []int
+	size, maxRows int
+	stats         CacheStats
+}
 
-func cachedKernel(X *mat.Dense, CacheSize uint, KernelFunction func(X1, X2 []float64) float64) func(i, j int) float64 {
-	m, _ := X.Dims()
-	type DiagEntry struct {
-		bool    //presence in cache
-		float64 // cached value
+// newKernelCache returns a cache of the kernel K between samples holding at least 2 rows
+func newKernelCache(K func(i, j int) float64, samples []int, cacheSize uint) *kernelCache {
+	l := len(samples)
+	maxRows := 2
+	if l > 0 && int(uint64(cacheSize)<<20/uint64(8*l)) > maxRows {
+		maxRows = int(uint64(cacheSize) << 20 / uint64(8*l))
 	}
-	type KcacheEntry struct {
-		uint64  // time last use
-		float64 // cached value
-	}
-	KcacheCap := (CacheSize << 20) / uint(unsafe.Sizeof(KcacheEntry{}))
-	KcacheDiag := make([]DiagEntry, m)
-	KcacheNDiag := map[[2]int]*KcacheEntry{}
-	Ktime := uint64(0)
+	c := &kernelCache{K: K, samples: samples, rows: make([][]float64, l), prev: make([]int, l+1), next: make([]int, l+1), maxRows: maxRows}
+	c.prev[l], c.next[l] = l, l
+	return c
+}
 
-	return func(i, j int) float64 {
-		if i == j {
-			e := &KcacheDiag[i]
-			if !e.bool {
-				e.float64 = KernelFunction(X.RawRowView(i), X.RawRowView(i))
-				e.bool = true
-			}
-			return e.float64
-		}
-		if i > j {
-			i, j = j, i
-		}
-		if e, ok := KcacheNDiag[[2]int{i, j}]; ok {
-			Ktime++
-			e.uint64 = Ktime
-			return e.float64
-		}
+func (c *kernelCache) unlink(u int) {
+	c.next[c.prev[u]] = c.next[u]
+	c.prev[c.next[u]] = c.prev[u]
+}
+
+func (c *kernelCache) pushFront(u int) {
+	head := len(c.rows)
+	c.prev[u], c.next[u] = head, c.next[head]
+	c.prev[c.next[head]] = u
+	c.next[head] = u
+}
 
-		if uint(len(KcacheNDiag)) == KcacheCap {
-			var oldt = Ktime
-			var old [2]int
-			for ij, e := range KcacheNDiag {
-				if e.uint64 < oldt {
-					oldt = e.uint64
-					old = ij
-				}
-			}
-			delete(KcacheNDiag, old)
+// row returns the row u of the kernel matrix, computing it and evicting the least recently used row if it is not cached.
+// the returned slice must not be modified and is only valid until the next call
+func (c *kernelCache) row(u int) []float64 {
+	if r := c.rows[u]; r != nil {
+		c.stats.Hits++
+		c.unlink(u)
+		c.pushFront(u)
+		return r
+	}
+	c.stats.Misses++
+	var r []float64
+	if c.size == c.maxRows {
+		lru := c.prev[len(c.rows)]
+		c.unlink(lru)
+		r, c.rows[lru] = c.rows[lru], nil
+	} else {
+		r = make([]float64, len(c.samples))
+		c.size++
+	}
+	i := c.samples[u]
+	for v, j := range c.samples {
+		// the kernel matrix is symmetric
+		if rv := c.rows[v]; rv != nil {
+			r[v] = rv[u]
+		} else {
+			r[v] = c.K(i, j)
 		}
-		Ktime++
-		e := &KcacheEntry{Ktime, KernelFunction(X.RawRowView(i), X.RawRowView(j))}
-		KcacheNDiag[[2]int{i, j}] = e
-		return e.float64
 	}
-
+	c.rows[u] = r
+	c.pushFront(u)
+	return r
 }
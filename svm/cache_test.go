@@ -0,0 +1,60 @@
+package svm
+
+import (
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestKernelCache(t *testing.T) {
+	evaluations := 0
+	K := func(i, j int) float64 {
+		evaluations++
+		return float64(10*i + j)
+	}
+	// rows of 4 samples of X, holding 2 rows
+	samples := []int{3, 5, 7, 9}
+	c := newKernelCache(K, samples, 0)
+	for _, step := range []struct {
+		row                  int
+		hits, misses, nEvals int
+	}{
+		{0, 0, 1, 4},
+		{1, 0, 2, 7}, // K(5,3) is read from row 0
+		{0, 1, 2, 7},
+		{2, 1, 3, 10}, // evicts row 1
+		{1, 1, 4, 13}, // evicts row 0
+		{0, 1, 5, 16},
+	} {
+		r := c.row(step.row)
+		i := samples[step.row]
+		for v, j := range samples {
+			if expected := float64(10*i + j); r[v] != expected && float64(10*j+i) != r[v] {
+				t.Errorf("row %d: expected K(%d,%d), got %g", step.row, i, j, r[v])
+			}
+		}
+		if c.stats.Hits != step.hits || c.stats.Misses != step.misses || evaluations != step.nEvals {
+			t.Errorf("row %d: expected %d hits, %d misses and %d evaluations, got %+v and %d", step.row, step.hits, step.misses, step.nEvals, c.stats, evaluations)
+		}
+	}
+}
+
+func TestSVC_CacheSize(t *testing.T) {
+	X, Y := breastCancer(300)
+	var decisions []*mat.Dense
+	var stats []CacheStats
+	for _, cacheSize := range []uint{0, 200} {
+		clf := NewSVC()
+		clf.CacheSize = cacheSize
+		clf.Fit(X, Y)
+		decisions = append(decisions, clf.DecisionFunction(X, nil))
+		stats = append(stats, clf.CacheStats())
+	}
+	if !mat.EqualApprox(decisions[0], decisions[1], 1e-9) {
+		t.Error("expected the same decisions for any cache size")
+	}
+	// 200MB hold the whole kernel matrix
+	if stats[1].Misses > 300 || stats[0].Misses <= stats[1].Misses || stats[0].Hits+stats[0].Misses != stats[1].Hits+stats[1].Misses {
+		t.Errorf("expected more misses with a smaller cache, and at most a miss per row, got %+v and %+v", stats[0], stats[1])
+	}
+}
@@ -1,2 +1,4 @@
 // Package svm includes Support Vector Machine algorithms.
+// SVC, NuSVC, SVR, NuSVR and OneClassSVM use the solver of libsvm.
+// LinearSVC and LinearSVR use the coordinate descent solvers of liblinear and scale to large sparse datasets.
 package svm
@@ -0,0 +1,31 @@
+package svm
+
+import (
+	"testing"
+
+	"github.com/pa-m/sklearn/base"
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestSVC_Float32(t *testing.T) {
+	X := base.General32Of(mat.NewDense(16, 2, []float64{0.4, -0.7, -1.5, -1., -1.4, -0.9, -1.3, -1.2, -1.1, -0.2, -1.2,
+		-0.4, -0.5, 1.2, -1.5, 2.1, 1., 1., 1.3, 0.8, 1.2, 0.5,
+		0.2, -2., 0.5, -2.4, 0.2, -2.3, 0., -2.7, 1.3, 2.1})).ToDense()
+	Y := mat.NewDense(16, 1, []float64{-1, -1, -1, -1, -1, -1, -1, -1, 1, 1, 1, 1, 1, 1, 1, 1})
+	for _, kernel := range []string{"linear", "poly", "rbf", "sigmoid"} {
+		dense, f32 := NewSVC(), NewSVC()
+		for _, m := range []*SVC{dense, f32} {
+			m.Kernel = kernel
+			m.Gamma = 2
+			m.RandomState = base.NewLockedSource(7)
+		}
+		dense.Fit(X, Y)
+		f32.Fit(base.General32Of(X), Y)
+		if f32.Model[0].X != nil || f32.Model[0].X32.Data == nil {
+			t.Errorf("%s: float32 model must keep float32 support vectors", kernel)
+		}
+		if !mat.EqualApprox(dense.DecisionFunction(X, nil), f32.DecisionFunction(base.General32Of(X), nil), 1e-4) {
+			t.Errorf("%s: dense and float32 decision functions differ", kernel)
+		}
+	}
+}
@@ -1,9 +1,13 @@
 package svm
 
 import (
+	"fmt"
 	"math"
 
+	"github.com/pa-m/sklearn/base"
+	"github.com/pa-m/sklearn/gaussian_process/kernels"
 	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mat"
 )
 
 // Kernel is the interface for kernels
@@ -11,6 +15,40 @@ type Kernel interface {
 	Func(a, b []float64) float64
 }
 
+// SparseKernel is implemented by kernels which are computed on sparse rows without densifying them
+type SparseKernel interface {
+	SparseFunc(a, b base.SparseVector) float64
+}
+
+// Float32Kernel is implemented by kernels which are computed on float32 rows without converting them
+type Float32Kernel interface {
+	Float32Func(a, b []float32) float64
+}
+
+// funcKernel is a Kernel for a func(a, b []float64) float64
+type funcKernel func(a, b []float64) float64
+
+// Func for funcKernel
+func (f funcKernel) Func(a, b []float64) float64 { return f(a, b) }
+
+// precomputedKernel marks a X which is the kernel matrix between samples and the training samples
+type precomputedKernel struct{}
+
+// Func for precomputedKernel panics, as kernel values are read from X
+func (precomputedKernel) Func(a, b []float64) float64 {
+	panic(fmt.Errorf("%w: a precomputed kernel has no function of samples", base.ErrInvalidParam))
+}
+
+// GPKernel is a Kernel for a gaussian_process/kernels.Kernel, such as RBF, DotProduct or their Sum and Product.
+// the hyperparameters Theta and the gradient of Eval remain available through the embedded kernel
+type GPKernel struct{ kernels.Kernel }
+
+// Func for GPKernel evaluates the kernel between a and b
+func (k GPKernel) Func(a, b []float64) float64 {
+	K, _ := k.Eval(mat.NewDense(1, len(a), a), mat.NewDense(1, len(b), b), false)
+	return K.At(0, 0)
+}
+
 // LinearKernel is dot product
 type LinearKernel struct{}
 
@@ -23,6 +61,12 @@ func (LinearKernel) Func(a, b []float64) (sumprod float64) {
 	return
 }
 
+// SparseFunc for LinearKernel
+func (LinearKernel) SparseFunc(a, b base.SparseVector) float64 { return a.Dot(b) }
+
+// Float32Func for LinearKernel
+func (LinearKernel) Float32Func(a, b []float32) float64 { return base.Dot32(a, b) }
+
 // PolynomialKernel ...
 type PolynomialKernel struct{ gamma, coef0, degree float64 }
 
@@ -31,6 +75,16 @@ func (kdata PolynomialKernel) Func(a, b []float64) (sumprod float64) {
 	return math.Pow(kdata.gamma*floats.Dot(a, b)+kdata.coef0, kdata.degree)
 }
 
+// SparseFunc for PolynomialKernel
+func (kdata PolynomialKernel) SparseFunc(a, b base.SparseVector) float64 {
+	return math.Pow(kdata.gamma*a.Dot(b)+kdata.coef0, kdata.degree)
+}
+
+// Float32Func for PolynomialKernel
+func (kdata PolynomialKernel) Float32Func(a, b []float32) float64 {
+	return math.Pow(kdata.gamma*base.Dot32(a, b)+kdata.coef0, kdata.degree)
+}
+
 // RBFKernel ...
 type RBFKernel struct{ gamma float64 }
 
@@ -44,6 +98,38 @@ func (kdata RBFKernel) Func(a, b []float64) float64 {
 	return math.Exp(-kdata.gamma * L2)
 }
 
+// SparseFunc for RBFKernel
+func (kdata RBFKernel) SparseFunc(a, b base.SparseVector) float64 {
+	L2 := 0.
+	for p, q := 0, 0; p < len(a.Indices) || q < len(b.Indices); {
+		var v float64
+		switch {
+		case q == len(b.Indices) || (p < len(a.Indices) && a.Indices[p] < b.Indices[q]):
+			v = a.Data[p]
+			p++
+		case p == len(a.Indices) || a.Indices[p] > b.Indices[q]:
+			v = b.Data[q]
+			q++
+		default:
+			v = a.Data[p] - b.Data[q]
+			p++
+			q++
+		}
+		L2 += v * v
+	}
+	return math.Exp(-kdata.gamma * L2)
+}
+
+// Float32Func for RBFKernel
+func (kdata RBFKernel) Float32Func(a, b []float32) float64 {
+	L2 := 0.
+	for i := range a {
+		v := float64(a[i]) - float64(b[i])
+		L2 += v * v
+	}
+	return math.Exp(-kdata.gamma * L2)
+}
+
 // SigmoidKernel ...
 type SigmoidKernel struct{ gamma, coef0 float64 }
 
@@ -51,3 +137,13 @@ type SigmoidKernel struct{ gamma, coef0 float64 }
 func (kdata SigmoidKernel) Func(a, b []float64) (sumprod float64) {
 	return math.Tanh(kdata.gamma*floats.Dot(a, b) + kdata.coef0)
 }
+
+// SparseFunc for SigmoidKernel
+func (kdata SigmoidKernel) SparseFunc(a, b base.SparseVector) float64 {
+	return math.Tanh(kdata.gamma*a.Dot(b) + kdata.coef0)
+}
+
+// Float32Func for SigmoidKernel
+func (kdata SigmoidKernel) Float32Func(a, b []float32) float64 {
+	return math.Tanh(kdata.gamma*base.Dot32(a, b) + kdata.coef0)
+}
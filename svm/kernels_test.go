@@ -0,0 +1,88 @@
+package svm
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/pa-m/sklearn/base"
+	"github.com/pa-m/sklearn/gaussian_process/kernels"
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestPrecomputedKernel(t *testing.T) {
+	X, Y := breastCancer(250)
+	Xtrain, Ytrain, Xtest := X.Slice(0, 200, 0, 30), Y.Slice(0, 200, 0, 1), X.Slice(200, 250, 0, 30)
+	gram, gramTest := &mat.Dense{}, &mat.Dense{}
+	gram.Mul(Xtrain, Xtrain.T())
+	gramTest.Mul(Xtest, Xtrain.T())
+
+	for _, m := range []struct{ linear, precomputed base.Predicter }{
+		{NewSVC(), NewSVC()},
+		{NewSVR(), NewSVR()},
+		{NewOneClassSVM(), NewOneClassSVM()},
+	} {
+		m.linear.(base.Params).SetParams(map[string]interface{}{"Kernel": "linear"})
+		m.precomputed.(base.Params).SetParams(map[string]interface{}{"Kernel": "precomputed"})
+		m.linear.Fit(Xtrain, Ytrain)
+		m.precomputed.Fit(gram, Ytrain)
+		if !mat.EqualApprox(m.linear.Predict(Xtest, nil), m.precomputed.Predict(gramTest, nil), 1e-6) {
+			t.Errorf("%T: expected the predictions of the linear kernel", m.linear)
+		}
+		if _, err := m.precomputed.(base.PredicterE).PredictE(Xtest, nil); !errors.Is(err, base.ErrShapeMismatch) {
+			t.Errorf("%T: expected ErrShapeMismatch for samples instead of kernel values, got %v", m.linear, err)
+		}
+	}
+
+	clf := NewSVC()
+	clf.Kernel = "precomputed"
+	if err := clf.FitE(Xtrain, Ytrain); !errors.Is(err, base.ErrShapeMismatch) {
+		t.Errorf("expected ErrShapeMismatch for a non square kernel matrix, got %v", err)
+	}
+}
+
+func TestGPKernel(t *testing.T) {
+	X, Y := breastCancer(200)
+	// RBF(length_scale) is exp(-gamma |a-b|^2) with gamma=1/(2 length_scale^2), DotProduct(0) is linear
+	lengthScale := 4.
+	rbf := &kernels.RBF{LengthScale: []float64{lengthScale}, LengthScaleBounds: [][2]float64{{1e-5, 1e5}}}
+	dot := &kernels.DotProduct{Sigma0: 0, Sigma0Bounds: [2]float64{1e-5, 1e5}}
+	gamma := 1 / (2 * lengthScale * lengthScale)
+	for _, test := range []struct {
+		gp       kernels.Kernel
+		expected interface{}
+	}{
+		{rbf, "rbf"},
+		{dot, "linear"},
+		{&kernels.Sum{KernelOperator: kernels.KernelOperator{K1: rbf, K2: dot}}, func(a, b []float64) float64 {
+			return RBFKernel{gamma: gamma}.Func(a, b) + LinearKernel{}.Func(a, b)
+		}},
+	} {
+		clf, expected := NewSVC(), NewSVC()
+		clf.Kernel = test.gp
+		expected.Kernel, expected.Gamma = test.expected, gamma
+		clf.Fit(X, Y)
+		expected.Fit(X, Y)
+		if !mat.EqualApprox(clf.DecisionFunction(X, nil), expected.DecisionFunction(X, nil), 1e-6) {
+			t.Errorf("%s: expected the decisions of %v", test.gp, test.expected)
+		}
+	}
+
+	// gaussian_process kernels are saved with the model
+	clf := NewSVC()
+	clf.Kernel = rbf
+	clf.Fit(X, Y)
+	buf := new(bytes.Buffer)
+	if err := base.Save(buf, clf); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := base.Load(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	D, loadedD := clf.DecisionFunction(X, nil), loaded.(*SVC).DecisionFunction(X, nil)
+	if math.Abs(D.At(0, 0)-loadedD.At(0, 0)) > 1e-12 {
+		t.Errorf("expected the decisions of the saved model, got %g instead of %g", loadedD.At(0, 0), D.At(0, 0))
+	}
+}
@@ -0,0 +1,565 @@
+package svm
+
+import (
+	"math"
+	"sort"
+
+	"golang.org/x/exp/rand"
+
+	"github.com/pa-m/sklearn/base"
+)
+
+// solvers of liblinear: https://www.csie.ntu.edu.tw/~cjlin/liblinear/
+// C.-J. Hsieh et al., A dual coordinate descent method for large-scale linear SVM, ICML 2008
+// K.-W. Chang et al., Coordinate descent method for large-scale L2-loss linear SVM, JMLR 2008
+// S. S. Keerthi et al., A sequential dual method for large scale multi-class linear SVMs, KDD 2008
+
+// linearProblem holds the samples of a liblinear solver: the rows of X, followed by a feature of value bias if bias > 0,
+// the target or the sign of the class of each sample, and the C of each sample, which is 0 for ignored samples
+type linearProblem struct {
+	X    *base.CSR
+	bias float64
+	y    []float64
+	C    []float64
+}
+
+// nFeatures returns the number of weights, including the bias
+func (p *linearProblem) nFeatures() int {
+	if p.bias > 0 {
+		return p.X.Cols + 1
+	}
+	return p.X.Cols
+}
+
+func (p *linearProblem) dot(i int, w []float64) float64 {
+	s := p.X.Row(i).DotDense(w)
+	if p.bias > 0 {
+		s += p.bias * w[p.X.Cols]
+	}
+	return s
+}
+
+// addScaled adds a times sample i to w
+func (p *linearProblem) addScaled(i int, a float64, w []float64) {
+	p.X.Row(i).AddScaledTo(w, a)
+	if p.bias > 0 {
+		w[p.X.Cols] += a * p.bias
+	}
+}
+
+func (p *linearProblem) sqNorm(i int) float64 {
+	s := p.bias * p.bias
+	for _, v := range p.X.Row(i).Data {
+		s += v * v
+	}
+	return s
+}
+
+// activeSamples returns the indices of the samples whose C is not 0
+func (p *linearProblem) activeSamples() []int {
+	index := make([]int, 0, len(p.C))
+	for i, c := range p.C {
+		if c > 0 {
+			index = append(index, i)
+		}
+	}
+	return index
+}
+
+// solveL2RL1L2SVC solves the dual of the L2-regularized hinge (l2Loss false) or squared hinge (l2Loss true) loss SVC,
+// y being -1 or 1, by coordinate descent with shrinking. it returns w and the number of iterations
+func solveL2RL1L2SVC(p *linearProblem, l2Loss bool, eps float64, maxIter int, rnd *rand.Rand) ([]float64, int) {
+	w := make([]float64, p.nFeatures())
+	alpha := make([]float64, len(p.y))
+	diag, upper := make([]float64, len(p.y)), make([]float64, len(p.y))
+	QD := make([]float64, len(p.y))
+	for i, c := range p.C {
+		if l2Loss {
+			diag[i], upper[i] = .5/c, math.Inf(1)
+		} else {
+			upper[i] = c
+		}
+		QD[i] = diag[i] + p.sqNorm(i)
+	}
+	index := p.activeSamples()
+	l := len(index)
+	activeSize := l
+	PGmaxOld, PGminOld := math.Inf(1), math.Inf(-1)
+	iter := 0
+	for iter < maxIter {
+		PGmaxNew, PGminNew := math.Inf(-1), math.Inf(1)
+		rnd.Shuffle(activeSize, func(i, j int) { index[i], index[j] = index[j], index[i] })
+		for s := 0; s < activeSize; s++ {
+			i := index[s]
+			yi := p.y[i]
+			G := yi*p.dot(i, w) - 1 + alpha[i]*diag[i]
+			PG := 0.
+			switch {
+			case alpha[i] == 0:
+				if G > PGmaxOld {
+					activeSize--
+					index[s], index[activeSize] = index[activeSize], index[s]
+					s--
+					continue
+				} else if G < 0 {
+					PG = G
+				}
+			case alpha[i] == upper[i]:
+				if G < PGminOld {
+					activeSize--
+					index[s], index[activeSize] = index[activeSize], index[s]
+					s--
+					continue
+				} else if G > 0 {
+					PG = G
+				}
+			default:
+				PG = G
+			}
+			PGmaxNew, PGminNew = math.Max(PGmaxNew, PG), math.Min(PGminNew, PG)
+			if math.Abs(PG) > 1e-12 {
+				alphaOld := alpha[i]
+				alpha[i] = math.Min(math.Max(alpha[i]-G/QD[i], 0), upper[i])
+				p.addScaled(i, (alpha[i]-alphaOld)*yi, w)
+			}
+		}
+		iter++
+		if PGmaxNew-PGminNew <= eps {
+			if activeSize == l {
+				break
+			}
+			activeSize = l
+			PGmaxOld, PGminOld = math.Inf(1), math.Inf(-1)
+			continue
+		}
+		PGmaxOld, PGminOld = PGmaxNew, PGminNew
+		if PGmaxOld <= 0 {
+			PGmaxOld = math.Inf(1)
+		}
+		if PGminOld >= 0 {
+			PGminOld = math.Inf(-1)
+		}
+	}
+	return w, iter
+}
+
+// solveL2RL1L2SVR solves the dual of the L2-regularized epsilon-insensitive (l2Loss false) or squared epsilon-insensitive
+// (l2Loss true) loss SVR by coordinate descent with shrinking. it returns w and the number of iterations
+func solveL2RL1L2SVR(p *linearProblem, l2Loss bool, epsilon, eps float64, maxIter int, rnd *rand.Rand) ([]float64, int) {
+	w := make([]float64, p.nFeatures())
+	beta := make([]float64, len(p.y))
+	lambda, upper := make([]float64, len(p.y)), make([]float64, len(p.y))
+	QD := make([]float64, len(p.y))
+	for i, c := range p.C {
+		if l2Loss {
+			lambda[i], upper[i] = .5/c, math.Inf(1)
+		} else {
+			upper[i] = c
+		}
+		QD[i] = p.sqNorm(i)
+	}
+	index := p.activeSamples()
+	l := len(index)
+	activeSize := l
+	GmaxOld, Gnorm1Init := math.Inf(1), -1.
+	shrink := func(s *int) {
+		activeSize--
+		index[*s], index[activeSize] = index[activeSize], index[*s]
+		*s--
+	}
+	iter := 0
+	for iter < maxIter {
+		GmaxNew, Gnorm1New := 0., 0.
+		rnd.Shuffle(activeSize, func(i, j int) { index[i], index[j] = index[j], index[i] })
+		for s := 0; s < activeSize; s++ {
+			i := index[s]
+			G := -p.y[i] + lambda[i]*beta[i] + p.dot(i, w)
+			H := QD[i] + lambda[i]
+			Gp, Gn := G+epsilon, G-epsilon
+			violation := 0.
+			switch {
+			case beta[i] == 0:
+				if Gp < 0 {
+					violation = -Gp
+				} else if Gn > 0 {
+					violation = Gn
+				} else if Gp > GmaxOld && Gn < -GmaxOld {
+					shrink(&s)
+					continue
+				}
+			case beta[i] >= upper[i]:
+				if Gp > 0 {
+					violation = Gp
+				} else if Gp < -GmaxOld {
+					shrink(&s)
+					continue
+				}
+			case beta[i] <= -upper[i]:
+				if Gn < 0 {
+					violation = -Gn
+				} else if Gn > GmaxOld {
+					shrink(&s)
+					continue
+				}
+			case beta[i] > 0:
+				violation = math.Abs(Gp)
+			default:
+				violation = math.Abs(Gn)
+			}
+			GmaxNew = math.Max(GmaxNew, violation)
+			Gnorm1New += violation
+
+			// newton direction
+			var d float64
+			switch {
+			case Gp < H*beta[i]:
+				d = -Gp / H
+			case Gn > H*beta[i]:
+				d = -Gn / H
+			default:
+				d = -beta[i]
+			}
+			if math.Abs(d) < 1e-12 {
+				continue
+			}
+			betaOld := beta[i]
+			beta[i] = math.Min(math.Max(beta[i]+d, -upper[i]), upper[i])
+			if d = beta[i] - betaOld; d != 0 {
+				p.addScaled(i, d, w)
+			}
+		}
+		if iter == 0 {
+			Gnorm1Init = Gnorm1New
+		}
+		iter++
+		if Gnorm1New <= eps*Gnorm1Init {
+			if activeSize == l {
+				break
+			}
+			activeSize = l
+			GmaxOld = math.Inf(1)
+			continue
+		}
+		GmaxOld = GmaxNew
+	}
+	return w, iter
+}
+
+// solveMCSVMCS solves the dual of the Crammer-Singer multi-class SVM by the sequential dual method with shrinking.
+// y holds class indices. it returns the weights of feature j for class k at w[j*nClasses+k], and the number of iterations
+func solveMCSVMCS(p *linearProblem, nClasses int, eps float64, maxIter int, rnd *rand.Rand) ([]float64, int) {
+	K := nClasses
+	nFeatures := p.nFeatures()
+	w := make([]float64, nFeatures*K)
+	alpha := make([]float64, len(p.y)*K)
+	alphaIndex := make([]int, len(p.y)*K)
+	QD := make([]float64, len(p.y))
+	activeSizeI, yIndex := make([]int, len(p.y)), make([]int, len(p.y))
+	for i := range p.y {
+		QD[i] = p.sqNorm(i)
+		for m := 0; m < K; m++ {
+			alphaIndex[i*K+m] = m
+		}
+		activeSizeI[i], yIndex[i] = K, int(p.y[i])
+	}
+	index := p.activeSamples()
+	l := len(index)
+	activeSize := l
+	G, B, alphaNew, D := make([]float64, K), make([]float64, K), make([]float64, K), make([]float64, K)
+	dInd, dVal := make([]int, K), make([]float64, K)
+	epsShrink := math.Max(10*eps, 1)
+	startFromAll := true
+
+	// forEachFeature calls fn for the non zero features of sample i, the bias being feature p.X.Cols
+	forEachFeature := func(i int, fn func(j int, v float64)) {
+		row := p.X.Row(i)
+		for q, j := range row.Indices {
+			fn(j, row.Data[q])
+		}
+		if p.bias > 0 {
+			fn(p.X.Cols, p.bias)
+		}
+	}
+	beShrunk := func(i, m, yi int, alphaI, minG float64) bool {
+		bound := 0.
+		if m == yIndex[i] {
+			bound = p.C[i]
+		}
+		return alphaI == bound && G[m] < minG
+	}
+	solveSubProblem := func(Ai float64, yi int, Cyi float64, activeI int) {
+		copy(D, B[:activeI])
+		if yi < activeI {
+			D[yi] += Ai * Cyi
+		}
+		sort.Sort(sort.Reverse(sort.Float64Slice(D[:activeI])))
+		beta := D[0] - Ai*Cyi
+		r := 1
+		for ; r < activeI && beta < float64(r)*D[r]; r++ {
+			beta += D[r]
+		}
+		beta /= float64(r)
+		for r := 0; r < activeI; r++ {
+			if r == yi {
+				alphaNew[r] = math.Min(Cyi, (beta-B[r])/Ai)
+			} else {
+				alphaNew[r] = math.Min(0, (beta-B[r])/Ai)
+			}
+		}
+	}
+
+	iter := 0
+	for iter < maxIter {
+		stopping := math.Inf(-1)
+		rnd.Shuffle(activeSize, func(i, j int) { index[i], index[j] = index[j], index[i] })
+		for s := 0; s < activeSize; s++ {
+			i := index[s]
+			Ai := QD[i]
+			alphaI, alphaIndexI := alpha[i*K:(i+1)*K], alphaIndex[i*K:(i+1)*K]
+			if Ai <= 0 {
+				continue
+			}
+			for m := 0; m < activeSizeI[i]; m++ {
+				G[m] = 1
+			}
+			if yIndex[i] < activeSizeI[i] {
+				G[yIndex[i]] = 0
+			}
+			forEachFeature(i, func(j int, v float64) {
+				wj := w[j*K : (j+1)*K]
+				for m := 0; m < activeSizeI[i]; m++ {
+					G[m] += wj[alphaIndexI[m]] * v
+				}
+			})
+			minG, maxG := math.Inf(1), math.Inf(-1)
+			for m := 0; m < activeSizeI[i]; m++ {
+				if alphaI[alphaIndexI[m]] < 0 && G[m] < minG {
+					minG = G[m]
+				}
+				if G[m] > maxG {
+					maxG = G[m]
+				}
+			}
+			yi := int(p.y[i])
+			if yIndex[i] < activeSizeI[i] && alphaI[yi] < p.C[i] && G[yIndex[i]] < minG {
+				minG = G[yIndex[i]]
+			}
+			for m := 0; m < activeSizeI[i]; m++ {
+				if !beShrunk(i, m, yi, alphaI[alphaIndexI[m]], minG) {
+					continue
+				}
+				activeSizeI[i]--
+				for activeSizeI[i] > m {
+					last := activeSizeI[i]
+					if !beShrunk(i, last, yi, alphaI[alphaIndexI[last]], minG) {
+						alphaIndexI[m], alphaIndexI[last] = alphaIndexI[last], alphaIndexI[m]
+						G[m], G[last] = G[last], G[m]
+						if yIndex[i] == last {
+							yIndex[i] = m
+						} else if yIndex[i] == m {
+							yIndex[i] = last
+						}
+						break
+					}
+					activeSizeI[i]--
+				}
+			}
+			if activeSizeI[i] <= 1 {
+				activeSize--
+				index[s], index[activeSize] = index[activeSize], index[s]
+				s--
+				continue
+			}
+			if maxG-minG <= 1e-12 {
+				continue
+			}
+			stopping = math.Max(maxG-minG, stopping)
+			for m := 0; m < activeSizeI[i]; m++ {
+				B[m] = G[m] - Ai*alphaI[alphaIndexI[m]]
+			}
+			solveSubProblem(Ai, yIndex[i], p.C[i], activeSizeI[i])
+			nzD := 0
+			for m := 0; m < activeSizeI[i]; m++ {
+				d := alphaNew[m] - alphaI[alphaIndexI[m]]
+				alphaI[alphaIndexI[m]] = alphaNew[m]
+				if math.Abs(d) >= 1e-12 {
+					dInd[nzD], dVal[nzD] = alphaIndexI[m], d
+					nzD++
+				}
+			}
+			forEachFeature(i, func(j int, v float64) {
+				wj := w[j*K : (j+1)*K]
+				for k := 0; k < nzD; k++ {
+					wj[dInd[k]] += dVal[k] * v
+				}
+			})
+		}
+		iter++
+		if stopping < epsShrink {
+			if stopping < eps && startFromAll {
+				break
+			}
+			activeSize = l
+			for i := range activeSizeI {
+				activeSizeI[i] = K
+			}
+			epsShrink = math.Max(epsShrink/2, eps)
+			startFromAll = true
+		} else {
+			startFromAll = false
+		}
+	}
+	return w, iter
+}
+
+// primalLoss is the loss of a sample i given its decision value v, minimized by solvePrimalCD
+type primalLoss interface {
+	loss(i int, v float64) float64
+	// derivatives returns the first and second derivatives of loss wrt v
+	derivatives(i int, v float64) (float64, float64)
+}
+
+// squaredHingeLoss is max(0, 1-y*v)^2
+type squaredHingeLoss []float64
+
+func (y squaredHingeLoss) loss(i int, v float64) float64 {
+	b := math.Max(0, 1-y[i]*v)
+	return b * b
+}
+
+func (y squaredHingeLoss) derivatives(i int, v float64) (float64, float64) {
+	if b := 1 - y[i]*v; b > 0 {
+		return -2 * y[i] * b, 2
+	}
+	return 0, 0
+}
+
+// squaredEpsilonInsensitiveLoss is max(0, |v-y|-epsilon)^2
+type squaredEpsilonInsensitiveLoss struct {
+	y       []float64
+	epsilon float64
+}
+
+func (l squaredEpsilonInsensitiveLoss) loss(i int, v float64) float64 {
+	b := math.Max(0, math.Abs(v-l.y[i])-l.epsilon)
+	return b * b
+}
+
+func (l squaredEpsilonInsensitiveLoss) derivatives(i int, v float64) (float64, float64) {
+	r := v - l.y[i]
+	if b := math.Abs(r) - l.epsilon; b > 0 {
+		if r < 0 {
+			return -2 * b, 2
+		}
+		return 2 * b, 2
+	}
+	return 0, 0
+}
+
+// solvePrimalCD minimizes |w|_1 (l1Penalty true) or |w|^2/2 (l1Penalty false) plus the sum of C[i]*loss(i, w.x_i)
+// by coordinate descent, with newton directions and an armijo line search. it returns w and the number of iterations
+func solvePrimalCD(p *linearProblem, loss primalLoss, l1Penalty bool, eps float64, maxIter int, rnd *rand.Rand) ([]float64, int) {
+	const (
+		sigma = .01
+		beta  = .5
+	)
+	nFeatures := p.nFeatures()
+	w := make([]float64, nFeatures)
+	v := make([]float64, len(p.y))
+	csc := p.X.ToCSC()
+	samples := p.activeSamples()
+	// forEachSample calls fn for the samples whose feature j is not 0
+	forEachSample := func(j int, fn func(i int, x float64)) {
+		if j == p.X.Cols {
+			for _, i := range samples {
+				fn(i, p.bias)
+			}
+			return
+		}
+		col := csc.Col(j)
+		for q, i := range col.Indices {
+			if p.C[i] > 0 {
+				fn(i, col.Data[q])
+			}
+		}
+	}
+	penalty := func(wj float64) float64 {
+		if l1Penalty {
+			return math.Abs(wj)
+		}
+		return wj * wj / 2
+	}
+	index := make([]int, nFeatures)
+	for j := range index {
+		index[j] = j
+	}
+	Gnorm1Init := -1.
+	iter := 0
+	for iter < maxIter {
+		Gnorm1New := 0.
+		rnd.Shuffle(nFeatures, func(i, j int) { index[i], index[j] = index[j], index[i] })
+		for _, j := range index {
+			g, h := 0., 1e-12
+			forEachSample(j, func(i int, x float64) {
+				d1, d2 := loss.derivatives(i, v[i])
+				g += p.C[i] * d1 * x
+				h += p.C[i] * d2 * x * x
+			})
+			var d, violation, delta float64
+			if l1Penalty {
+				Gp, Gn := g+1, g-1
+				switch {
+				case w[j] > 0:
+					violation = math.Abs(Gp)
+				case w[j] < 0:
+					violation = math.Abs(Gn)
+				case Gp < 0:
+					violation = -Gp
+				case Gn > 0:
+					violation = Gn
+				}
+				switch {
+				case Gp < h*w[j]:
+					d = -Gp / h
+				case Gn > h*w[j]:
+					d = -Gn / h
+				default:
+					d = -w[j]
+				}
+				delta = g*d + math.Abs(w[j]+d) - math.Abs(w[j])
+			} else {
+				g += w[j]
+				h++
+				violation, d = math.Abs(g), -g/h
+				delta = g * d
+			}
+			Gnorm1New += violation
+			if math.Abs(d) < 1e-12 {
+				continue
+			}
+			f0 := penalty(w[j])
+			forEachSample(j, func(i int, x float64) { f0 += p.C[i] * loss.loss(i, v[i]) })
+			step, accepted := 1., false
+			for k := 0; k < 20 && !accepted; k++ {
+				f := penalty(w[j] + step*d)
+				forEachSample(j, func(i int, x float64) { f += p.C[i] * loss.loss(i, v[i]+step*d*x) })
+				if accepted = f-f0 <= sigma*step*delta; !accepted {
+					step *= beta
+				}
+			}
+			if !accepted {
+				continue
+			}
+			w[j] += step * d
+			forEachSample(j, func(i int, x float64) { v[i] += step * d * x })
+		}
+		if iter == 0 {
+			Gnorm1Init = Gnorm1New
+		}
+		iter++
+		if Gnorm1New <= eps*Gnorm1Init {
+			break
+		}
+	}
+	return w, iter
+}
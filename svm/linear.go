@@ -0,0 +1,468 @@
+package svm
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"golang.org/x/exp/rand"
+
+	"github.com/pa-m/sklearn/base"
+	"github.com/pa-m/sklearn/metrics"
+	"gonum.org/v1/gonum/mat"
+)
+
+// LinearSVC is a linear support vector classifier fitted by the liblinear solvers, which scale to large numbers of
+// samples. X may be a *mat.Dense, a *base.CSR, a *base.CSC or a base.General32. Y has a single column of class labels.
+// Penalty is "l2" or "l1", Loss is "squared_hinge" or "hinge". the l1 penalty requires the squared hinge loss and
+// Dual false, and the hinge loss requires Dual true.
+// MultiClass is "ovr" (one binary problem per class vs the rest) or "crammer_singer", which ignores Penalty, Loss and Dual.
+// the intercept is fitted as the weight of an additional feature of value InterceptScaling, so it is regularized too
+type LinearSVC struct {
+	Penalty          string
+	Loss             string
+	Dual             bool
+	Tol              float64
+	C                float64
+	MultiClass       string
+	FitIntercept     bool
+	InterceptScaling float64
+	MaxIter          int
+	RandomState      base.RandomState
+
+	// Coef has a column per class, or a single column for 2 classes
+	Coef      *mat.Dense `param:"-"`
+	Intercept []float64  `param:"-"`
+	Classes   []float64  `param:"-"`
+	NFeatures int        `param:"-"`
+	NIter     int        `param:"-"`
+}
+
+// NewLinearSVC returns a LinearSVC with sklearn defaults
+func NewLinearSVC() *LinearSVC {
+	return &LinearSVC{Penalty: "l2", Loss: "squared_hinge", Dual: true, Tol: 1e-4, C: 1, MultiClass: "ovr", FitIntercept: true, InterceptScaling: 1, MaxIter: 1000}
+}
+
+// LinearSVR is a linear support vector regressor fitted by the liblinear solvers.
+// Loss is "epsilon_insensitive" or "squared_epsilon_insensitive". the epsilon insensitive loss requires Dual true.
+// a model is fitted per column of Y
+type LinearSVR struct {
+	Epsilon          float64
+	Tol              float64
+	C                float64
+	Loss             string
+	FitIntercept     bool
+	InterceptScaling float64
+	Dual             bool
+	MaxIter          int
+	RandomState      base.RandomState
+
+	// Coef has a column per output
+	Coef      *mat.Dense `param:"-"`
+	Intercept []float64  `param:"-"`
+	NFeatures int        `param:"-"`
+	NIter     int        `param:"-"`
+}
+
+// NewLinearSVR returns a LinearSVR with sklearn defaults
+func NewLinearSVR() *LinearSVR {
+	return &LinearSVR{Tol: 1e-4, C: 1, Loss: "epsilon_insensitive", FitIntercept: true, InterceptScaling: 1, Dual: true, MaxIter: 1000}
+}
+
+// linearFit holds the parameters shared by LinearSVC and LinearSVR
+type linearFit struct {
+	C, InterceptScaling float64
+	FitIntercept        bool
+	MaxIter             int
+	RandomState         base.RandomState
+}
+
+func (lf linearFit) checkParams() error {
+	if lf.C <= 0 {
+		return fmt.Errorf("%w: C must be positive, got %g", base.ErrInvalidParam, lf.C)
+	}
+	if lf.FitIntercept && lf.InterceptScaling <= 0 {
+		return fmt.Errorf("%w: InterceptScaling must be positive, got %g", base.ErrInvalidParam, lf.InterceptScaling)
+	}
+	return nil
+}
+
+// problem returns the liblinear problem for X, with C scaled by sampleWeight. y is left to be set by the caller
+func (lf linearFit) problem(X mat.Matrix, sampleWeight []float64) *linearProblem {
+	nSamples, _ := X.Dims()
+	p := &linearProblem{X: base.ToCSR(X), C: make([]float64, nSamples)}
+	if lf.FitIntercept {
+		p.bias = lf.InterceptScaling
+	}
+	for i := range p.C {
+		p.C[i] = lf.C
+		if sampleWeight != nil {
+			p.C[i] *= sampleWeight[i]
+		}
+	}
+	return p
+}
+
+// newRands returns a *rand.Rand per problem, seeded from src or from the time if src is nil,
+// so that problems solved in parallel are reproducible
+func newRands(src base.RandomState, nProblems int) []*rand.Rand {
+	if src == nil {
+		src = base.NewSource(uint64(time.Now().UnixNano()))
+	}
+	rnds := make([]*rand.Rand, nProblems)
+	for i := range rnds {
+		rnds[i] = rand.New(base.NewSource(src.Uint64()))
+	}
+	return rnds
+}
+
+// coef returns the coefficients and intercepts of the solutions w of each problem
+func (lf linearFit) coef(ws [][]float64, nFeatures int) (*mat.Dense, []float64) {
+	Coef := mat.NewDense(nFeatures, len(ws), nil)
+	Intercept := make([]float64, len(ws))
+	for k, w := range ws {
+		for j := 0; j < nFeatures; j++ {
+			Coef.Set(j, k, w[j])
+		}
+		if lf.FitIntercept {
+			Intercept[k] = lf.InterceptScaling * w[nFeatures]
+		}
+	}
+	return Coef, Intercept
+}
+
+func warnMaxIter(nIter, maxIter int) {
+	if nIter >= maxIter {
+		log.Printf("liblinear failed to converge in %d iterations, increase MaxIter\n", maxIter)
+	}
+}
+
+// decisionFunction returns X.Coef+Intercept
+func decisionFunction(X mat.Matrix, Coef *mat.Dense, Intercept []float64) *mat.Dense {
+	D := base.MatMul(&mat.Dense{}, X, Coef)
+	D.Apply(func(i, k int, v float64) float64 { return v + Intercept[k] }, D)
+	return D
+}
+
+// IsClassifier returns true for LinearSVC
+func (*LinearSVC) IsClassifier() bool { return true }
+
+// IsFitted returns true when Coef has been set. see base.FittedChecker
+func (m *LinearSVC) IsFitted() bool { return m.Coef != nil }
+
+// GetNOutputs returns 1
+func (m *LinearSVC) GetNOutputs() int { return 1 }
+
+// PredicterClone for LinearSVC
+func (m *LinearSVC) PredicterClone() base.Predicter {
+	if m == nil {
+		return nil
+	}
+	clone := *m
+	clone.Coef, clone.Intercept, clone.Classes, clone.NFeatures, clone.NIter = nil, nil, nil, 0, 0
+	return base.DeepCopy(&clone).(*LinearSVC)
+}
+
+func (m *LinearSVC) linearFit() linearFit {
+	return linearFit{C: m.C, InterceptScaling: m.InterceptScaling, FitIntercept: m.FitIntercept, MaxIter: m.MaxIter, RandomState: m.RandomState}
+}
+
+func (m *LinearSVC) checkParams() error {
+	if err := m.linearFit().checkParams(); err != nil {
+		return err
+	}
+	switch m.MultiClass {
+	case "ovr":
+	case "crammer_singer":
+		return nil
+	default:
+		return fmt.Errorf("%w: MultiClass must be \"ovr\" or \"crammer_singer\", got %q", base.ErrInvalidParam, m.MultiClass)
+	}
+	switch {
+	case m.Penalty != "l1" && m.Penalty != "l2":
+		return fmt.Errorf("%w: Penalty must be \"l1\" or \"l2\", got %q", base.ErrInvalidParam, m.Penalty)
+	case m.Loss != "hinge" && m.Loss != "squared_hinge":
+		return fmt.Errorf("%w: Loss must be \"hinge\" or \"squared_hinge\", got %q", base.ErrInvalidParam, m.Loss)
+	case m.Penalty == "l1" && m.Loss == "hinge":
+		return fmt.Errorf("%w: the combination of l1 penalty and hinge loss is not supported", base.ErrInvalidParam)
+	case m.Penalty == "l1" && m.Dual:
+		return fmt.Errorf("%w: the l1 penalty is only supported with Dual false", base.ErrInvalidParam)
+	case m.Loss == "hinge" && !m.Dual:
+		return fmt.Errorf("%w: the hinge loss is only supported with Dual true", base.ErrInvalidParam)
+	}
+	return nil
+}
+
+// Fit for LinearSVC
+func (m *LinearSVC) Fit(X, Y mat.Matrix) base.Fiter {
+	return m.FitWeighted(X, Y, nil)
+}
+
+// FitWeighted for LinearSVC scales C by the weight of each sample
+func (m *LinearSVC) FitWeighted(X, Y mat.Matrix, sampleWeight []float64) base.Fiter {
+	if err := m.checkParams(); err != nil {
+		panic(err)
+	}
+	if err := base.CheckSampleWeight(X, sampleWeight); err != nil {
+		panic(err)
+	}
+	if _, nOutputs := Y.Dims(); nOutputs != 1 {
+		panic(fmt.Errorf("%w: LinearSVC expects a single column of labels, got %d", base.ErrShapeMismatch, nOutputs))
+	}
+	lf := m.linearFit()
+	p := lf.problem(X, sampleWeight)
+	nSamples, nFeatures := X.Dims()
+	y := mat.Col(nil, 0, Y)
+	m.Classes = uniqueSorted(y)
+	K := len(m.Classes)
+	if K < 2 {
+		panic(fmt.Errorf("%w: LinearSVC needs at least 2 classes, got %d", base.ErrInvalidParam, K))
+	}
+	classIndex := make([]int, nSamples)
+	for i, v := range y {
+		classIndex[i] = sort.SearchFloat64s(m.Classes, v)
+	}
+
+	var ws [][]float64
+	if m.MultiClass == "crammer_singer" {
+		p.y = make([]float64, nSamples)
+		for i, c := range classIndex {
+			p.y[i] = float64(c)
+		}
+		w, nIter := solveMCSVMCS(p, K, m.Tol, m.MaxIter, newRands(lf.RandomState, 1)[0])
+		m.NIter = nIter
+		ws = make([][]float64, K)
+		for k := range ws {
+			ws[k] = make([]float64, p.nFeatures())
+			for j := range ws[k] {
+				ws[k][j] = w[j*K+k]
+			}
+		}
+	} else {
+		// a single problem for the second class vs the first one if there are 2 classes
+		nProblems, first := K, 0
+		if K == 2 {
+			nProblems, first = 1, 1
+		}
+		ws = make([][]float64, nProblems)
+		nIters := make([]int, nProblems)
+		rnds := newRands(lf.RandomState, nProblems)
+		base.Parallelize(-1, nProblems, func(th, start, end int) {
+			for k := start; k < end; k++ {
+				pk := *p
+				pk.y = make([]float64, nSamples)
+				for i, c := range classIndex {
+					pk.y[i] = -1
+					if c == first+k {
+						pk.y[i] = 1
+					}
+				}
+				switch {
+				case m.Penalty == "l2" && m.Dual:
+					ws[k], nIters[k] = solveL2RL1L2SVC(&pk, m.Loss == "squared_hinge", m.Tol, m.MaxIter, rnds[k])
+				default:
+					ws[k], nIters[k] = solvePrimalCD(&pk, squaredHingeLoss(pk.y), m.Penalty == "l1", m.Tol, m.MaxIter, rnds[k])
+				}
+			}
+		})
+		m.NIter = 0
+		for _, nIter := range nIters {
+			if nIter > m.NIter {
+				m.NIter = nIter
+			}
+		}
+	}
+	warnMaxIter(m.NIter, m.MaxIter)
+	m.NFeatures = nFeatures
+	m.Coef, m.Intercept = lf.coef(ws, nFeatures)
+	return m
+}
+
+// FitE is Fit returning an error instead of panicking
+func (m *LinearSVC) FitE(X, Y mat.Matrix) error {
+	if err := m.checkParams(); err != nil {
+		return err
+	}
+	return base.FitE(m, X, Y)
+}
+
+// DecisionFunction returns the signed distance of samples to the hyperplane of each class, or of the second class for 2 classes
+func (m *LinearSVC) DecisionFunction(X mat.Matrix, Y mat.Mutable) *mat.Dense {
+	base.MustBeFitted(m)
+	return base.FromDense(Y, decisionFunction(X, m.Coef, m.Intercept))
+}
+
+// Predict for LinearSVC returns the class with the highest decision function
+func (m *LinearSVC) Predict(X mat.Matrix, Ymutable mat.Mutable) *mat.Dense {
+	D := m.DecisionFunction(X, nil)
+	nSamples, K := D.Dims()
+	Y := base.ToDense(Ymutable)
+	if Y.IsZero() {
+		*Y = *mat.NewDense(nSamples, 1, nil)
+	}
+	for i := 0; i < nSamples; i++ {
+		if K == 1 {
+			c := 0
+			if D.At(i, 0) > 0 {
+				c = 1
+			}
+			Y.Set(i, 0, m.Classes[c])
+			continue
+		}
+		best := 0
+		for k := 1; k < K; k++ {
+			if D.At(i, k) > D.At(i, best) {
+				best = k
+			}
+		}
+		Y.Set(i, 0, m.Classes[best])
+	}
+	return base.FromDense(Ymutable, Y)
+}
+
+// PredictE is Predict returning an error instead of panicking
+func (m *LinearSVC) PredictE(X mat.Matrix, Y mat.Mutable) (*mat.Dense, error) {
+	if m.IsFitted() {
+		if err := base.CheckNFeatures(X, m.NFeatures); err != nil {
+			return nil, err
+		}
+	}
+	return base.PredictE(m, X, Y)
+}
+
+// Score for LinearSVC returns accuracy
+func (m *LinearSVC) Score(X, Y mat.Matrix) float64 {
+	Ypred := m.Predict(X, nil)
+	return metrics.AccuracyScore(Y, Ypred, true, nil)
+}
+
+// IsClassifier returns false for LinearSVR
+func (*LinearSVR) IsClassifier() bool { return false }
+
+// IsFitted returns true when Coef has been set. see base.FittedChecker
+func (m *LinearSVR) IsFitted() bool { return m.Coef != nil }
+
+// GetNOutputs returns the number of columns of Coef
+func (m *LinearSVR) GetNOutputs() int {
+	if m.Coef == nil {
+		return 0
+	}
+	_, nOutputs := m.Coef.Dims()
+	return nOutputs
+}
+
+// PredicterClone for LinearSVR
+func (m *LinearSVR) PredicterClone() base.Predicter {
+	if m == nil {
+		return nil
+	}
+	clone := *m
+	clone.Coef, clone.Intercept, clone.NFeatures, clone.NIter = nil, nil, 0, 0
+	return base.DeepCopy(&clone).(*LinearSVR)
+}
+
+func (m *LinearSVR) linearFit() linearFit {
+	return linearFit{C: m.C, InterceptScaling: m.InterceptScaling, FitIntercept: m.FitIntercept, MaxIter: m.MaxIter, RandomState: m.RandomState}
+}
+
+func (m *LinearSVR) checkParams() error {
+	if err := m.linearFit().checkParams(); err != nil {
+		return err
+	}
+	switch {
+	case m.Epsilon < 0:
+		return fmt.Errorf("%w: Epsilon must be non-negative, got %g", base.ErrInvalidParam, m.Epsilon)
+	case m.Loss != "epsilon_insensitive" && m.Loss != "squared_epsilon_insensitive":
+		return fmt.Errorf("%w: Loss must be \"epsilon_insensitive\" or \"squared_epsilon_insensitive\", got %q", base.ErrInvalidParam, m.Loss)
+	case m.Loss == "epsilon_insensitive" && !m.Dual:
+		return fmt.Errorf("%w: the epsilon insensitive loss is only supported with Dual true", base.ErrInvalidParam)
+	}
+	return nil
+}
+
+// Fit for LinearSVR
+func (m *LinearSVR) Fit(X, Y mat.Matrix) base.Fiter {
+	return m.FitWeighted(X, Y, nil)
+}
+
+// FitWeighted for LinearSVR scales C by the weight of each sample
+func (m *LinearSVR) FitWeighted(X, Y mat.Matrix, sampleWeight []float64) base.Fiter {
+	if err := m.checkParams(); err != nil {
+		panic(err)
+	}
+	if err := base.CheckSampleWeight(X, sampleWeight); err != nil {
+		panic(err)
+	}
+	lf := m.linearFit()
+	p := lf.problem(X, sampleWeight)
+	_, nFeatures := X.Dims()
+	_, nOutputs := Y.Dims()
+	ws := make([][]float64, nOutputs)
+	nIters := make([]int, nOutputs)
+	rnds := newRands(lf.RandomState, nOutputs)
+	base.Parallelize(-1, nOutputs, func(th, start, end int) {
+		for o := start; o < end; o++ {
+			po := *p
+			po.y = mat.Col(nil, o, Y)
+			if m.Dual {
+				ws[o], nIters[o] = solveL2RL1L2SVR(&po, m.Loss == "squared_epsilon_insensitive", m.Epsilon, m.Tol, m.MaxIter, rnds[o])
+			} else {
+				ws[o], nIters[o] = solvePrimalCD(&po, squaredEpsilonInsensitiveLoss{y: po.y, epsilon: m.Epsilon}, false, m.Tol, m.MaxIter, rnds[o])
+			}
+		}
+	})
+	m.NIter = 0
+	for _, nIter := range nIters {
+		if nIter > m.NIter {
+			m.NIter = nIter
+		}
+	}
+	warnMaxIter(m.NIter, m.MaxIter)
+	m.NFeatures = nFeatures
+	m.Coef, m.Intercept = lf.coef(ws, nFeatures)
+	return m
+}
+
+// FitE is Fit returning an error instead of panicking
+func (m *LinearSVR) FitE(X, Y mat.Matrix) error {
+	if err := m.checkParams(); err != nil {
+		return err
+	}
+	return base.FitE(m, X, Y)
+}
+
+// Predict for LinearSVR returns X.Coef+Intercept
+func (m *LinearSVR) Predict(X mat.Matrix, Y mat.Mutable) *mat.Dense {
+	base.MustBeFitted(m)
+	return base.FromDense(Y, decisionFunction(X, m.Coef, m.Intercept))
+}
+
+// PredictE is Predict returning an error instead of panicking
+func (m *LinearSVR) PredictE(X mat.Matrix, Y mat.Mutable) (*mat.Dense, error) {
+	if m.IsFitted() {
+		if err := base.CheckNFeatures(X, m.NFeatures); err != nil {
+			return nil, err
+		}
+	}
+	return base.PredictE(m, X, Y)
+}
+
+// Score for LinearSVR returns R2Score
+func (m *LinearSVR) Score(X, Y mat.Matrix) float64 {
+	Ypred := m.Predict(X, nil)
+	return metrics.R2Score(Y, Ypred, nil, "").At(0, 0)
+}
+
+// uniqueSorted returns the sorted distinct values of a
+func uniqueSorted(a []float64) []float64 {
+	s := append([]float64(nil), a...)
+	sort.Float64s(s)
+	u := s[:0]
+	for _, v := range s {
+		if len(u) == 0 || v != u[len(u)-1] {
+			u = append(u, v)
+		}
+	}
+	return u
+}
@@ -0,0 +1,212 @@
+package svm
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+
+	"golang.org/x/exp/rand"
+
+	"github.com/pa-m/sklearn/base"
+	"github.com/pa-m/sklearn/datasets"
+	"github.com/pa-m/sklearn/preprocessing"
+	"gonum.org/v1/gonum/mat"
+)
+
+var _ = []base.PredicterE{&LinearSVC{}, &LinearSVR{}}
+var _ = []base.WeightedFiter{&LinearSVC{}, &LinearSVR{}}
+var _ = []base.FittedChecker{&LinearSVC{}, &LinearSVR{}}
+var _ base.DecisionFunctioner = &LinearSVC{}
+
+func ExampleLinearSVC() {
+	ds := datasets.LoadIris()
+	X, _ := preprocessing.NewStandardScaler().FitTransform(ds.X, nil)
+	for _, multiClass := range []string{"ovr", "crammer_singer"} {
+		clf := NewLinearSVC()
+		clf.MultiClass = multiClass
+		clf.MaxIter = 10000
+		clf.RandomState = base.NewSource(7)
+		clf.Fit(X, ds.Y)
+		fmt.Printf("%s accuracy: %.2f\n", multiClass, clf.Score(X, ds.Y))
+	}
+	// Output:
+	// ovr accuracy: 0.95
+	// crammer_singer accuracy: 0.97
+}
+
+func ExampleLinearSVR() {
+	ds := datasets.LoadDiabetes()
+	reg := NewLinearSVR()
+	reg.C = 100
+	reg.RandomState = base.NewSource(7)
+	reg.MaxIter = 10000
+	reg.Fit(ds.X, ds.Y)
+	fmt.Printf("R2: %.2f\n", reg.Score(ds.X, ds.Y))
+	// Output:
+	// R2: 0.48
+}
+
+func TestLinearSVC(t *testing.T) {
+	ds := datasets.LoadBreastCancer()
+	X, _ := preprocessing.NewStandardScaler().FitTransform(ds.X, nil)
+	newLinearSVC := func(params map[string]interface{}) *LinearSVC {
+		clf := NewLinearSVC()
+		clf.RandomState = base.NewSource(7)
+		clf.MaxIter = 10000
+		if err := clf.SetParams(params); err != nil {
+			t.Fatal(err)
+		}
+		return clf
+	}
+	fit := func(params map[string]interface{}, X mat.Matrix) *LinearSVC {
+		clf := newLinearSVC(params)
+		if err := clf.FitE(X, ds.Y); err != nil {
+			t.Fatalf("%v: %s", params, err)
+		}
+		return clf
+	}
+	for _, params := range []map[string]interface{}{
+		{"Loss": "hinge"},
+		{},
+		{"Dual": false},
+		{"Penalty": "l1", "Dual": false},
+		{"MultiClass": "crammer_singer"},
+	} {
+		clf := fit(params, X)
+		if score := clf.Score(X, ds.Y); score < .97 {
+			t.Errorf("%v: expected accuracy >= .97, got %g", params, score)
+		}
+		// sparse input gives the same model
+		sparse := fit(params, base.ToCSR(X))
+		if !mat.EqualApprox(clf.Coef, sparse.Coef, 1e-12) {
+			t.Errorf("%v: expected the same coefficients for sparse input", params)
+		}
+	}
+
+	// the dual and primal solvers minimize the same objective for the l2 penalty and squared hinge loss
+	dual, primal := fit(map[string]interface{}{"Tol": 1e-6}, X), fit(map[string]interface{}{"Tol": 1e-6, "Dual": false}, X)
+	if !mat.EqualApprox(dual.Coef, primal.Coef, 1e-3) || math.Abs(dual.Intercept[0]-primal.Intercept[0]) > 1e-3 {
+		t.Errorf("expected the same solution for the dual and primal solvers, got %v and %v", mat.Formatted(dual.Coef.T()), mat.Formatted(primal.Coef.T()))
+	}
+
+	// the l1 penalty gives sparse coefficients
+	l1 := fit(map[string]interface{}{"Penalty": "l1", "Dual": false, "C": .05}, X)
+	zeros := 0
+	for _, v := range l1.Coef.RawMatrix().Data {
+		if v == 0 {
+			zeros++
+		}
+	}
+	if zeros < 10 {
+		t.Errorf("expected at least 10 zero coefficients with the l1 penalty, got %d", zeros)
+	}
+
+	// a sample weight of 2 is a duplicated sample
+	rnd := rand.New(base.NewSource(7))
+	w := make([]float64, 569)
+	var rows []int
+	for i := range w {
+		w[i] = float64(rnd.Intn(3))
+		for k := 0; k < int(w[i]); k++ {
+			rows = append(rows, i)
+		}
+	}
+	weighted := newLinearSVC(map[string]interface{}{"Tol": 1e-6, "Dual": false})
+	weighted.FitWeighted(X, ds.Y, w)
+	duplicated := newLinearSVC(map[string]interface{}{"Tol": 1e-6, "Dual": false})
+	duplicated.Fit(base.ToCSR(X).SelectRows(rows), base.ToCSR(ds.Y).SelectRows(rows).ToDense())
+	if !mat.EqualApprox(weighted.Coef, duplicated.Coef, 1e-3) {
+		t.Errorf("expected weights to duplicate samples, got %v and %v", mat.Formatted(weighted.Coef.T()), mat.Formatted(duplicated.Coef.T()))
+	}
+
+	for _, params := range []map[string]interface{}{
+		{"Penalty": "l1", "Loss": "hinge", "Dual": false},
+		{"Penalty": "l1"},
+		{"Loss": "hinge", "Dual": false},
+		{"MultiClass": "ovo"},
+		{"C": 0.},
+	} {
+		clf := NewLinearSVC()
+		clf.SetParams(params)
+		if err := clf.FitE(X, ds.Y); !errors.Is(err, base.ErrInvalidParam) {
+			t.Errorf("%v: expected ErrInvalidParam, got %v", params, err)
+		}
+	}
+}
+
+func TestLinearSVCMulticlass(t *testing.T) {
+	ds := datasets.LoadIris()
+	X, _ := preprocessing.NewStandardScaler().FitTransform(ds.X, nil)
+	clf := NewLinearSVC()
+	clf.RandomState = base.NewSource(7)
+	clf.Fit(X, ds.Y)
+	if r, c := clf.Coef.Dims(); r != 4 || c != 3 || len(clf.Intercept) != 3 {
+		t.Fatalf("expected 4x3 coefficients and 3 intercepts, got %dx%d and %d", r, c, len(clf.Intercept))
+	}
+	// each column is the binary problem of its class vs the rest
+	for k, class := range clf.Classes {
+		Yk := &mat.Dense{}
+		Yk.Apply(func(i, j int, v float64) float64 {
+			if v == class {
+				return 1
+			}
+			return 0
+		}, ds.Y)
+		binary := NewLinearSVC()
+		binary.Tol = 1e-8
+		binary.Fit(X, Yk)
+		if !mat.EqualApprox(binary.Coef, clf.Coef.ColView(k), 1e-2) {
+			t.Errorf("class %g: expected %v, got %v", class, mat.Formatted(binary.Coef.T()), mat.Formatted(clf.Coef.ColView(k).T()))
+		}
+	}
+	if _, err := clf.PredictE(mat.NewDense(1, 3, nil), nil); !errors.Is(err, base.ErrShapeMismatch) {
+		t.Errorf("expected ErrShapeMismatch, got %v", err)
+	}
+}
+
+func TestLinearSVR(t *testing.T) {
+	// y = x.w + 3, with a large number of sparse samples
+	rnd := rand.New(base.NewSource(7))
+	nSamples, nFeatures := 20000, 100
+	coef := make([]float64, nFeatures)
+	for j := range coef {
+		coef[j] = rnd.NormFloat64()
+	}
+	X := &base.CSR{Rows: nSamples, Cols: nFeatures, Indptr: make([]int, nSamples+1)}
+	Y := mat.NewDense(nSamples, 1, nil)
+	for i := 0; i < nSamples; i++ {
+		y := 3.
+		for j := 0; j < nFeatures; j++ {
+			if rnd.Float64() < .05 {
+				v := rnd.NormFloat64()
+				X.Indices = append(X.Indices, j)
+				X.Data = append(X.Data, v)
+				y += v * coef[j]
+			}
+		}
+		X.Indptr[i+1] = len(X.Data)
+		Y.Set(i, 0, y+.01*rnd.NormFloat64())
+	}
+	for _, params := range []map[string]interface{}{
+		// the dual of the epsilon insensitive loss converges slowly
+		{"Epsilon": .01, "Tol": .05},
+		{"Loss": "squared_epsilon_insensitive"},
+		{"Loss": "squared_epsilon_insensitive", "Dual": false},
+	} {
+		reg := NewLinearSVR()
+		reg.RandomState = base.NewSource(7)
+		reg.SetParams(params)
+		if err := reg.FitE(X, Y); err != nil {
+			t.Fatalf("%v: %s", params, err)
+		}
+		if !mat.EqualApprox(reg.Coef, mat.NewDense(nFeatures, 1, coef), 1e-2) || math.Abs(reg.Intercept[0]-3) > 1e-2 {
+			t.Errorf("%v: expected the generating coefficients, got intercept %g", params, reg.Intercept[0])
+		}
+	}
+	reg := NewLinearSVR()
+	reg.Dual = false
+	if err := reg.FitE(X, Y); !errors.Is(err, base.ErrInvalidParam) {
+		t.Errorf("expected ErrInvalidParam for the epsilon insensitive loss with Dual false, got %v", err)
+	}
+}
@@ -0,0 +1,227 @@
+package svm
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/pa-m/sklearn/base"
+	"gonum.org/v1/gonum/mat"
+)
+
+// NuSVC is a Nu-Support Vector Classifier. Nu is an upper bound on the fraction of margin errors and a lower bound
+// on the fraction of support vectors. C is not used
+type NuSVC struct {
+	SVC
+	Nu float64
+}
+
+// NewNuSVC returns a NuSVC with Nu 0.5 and the defaults of NewSVC
+func NewNuSVC() *NuSVC {
+	m := &NuSVC{SVC: *NewSVC(), Nu: .5}
+	return m
+}
+
+// PredicterClone for NuSVC
+func (m *NuSVC) PredicterClone() base.Predicter {
+	if m == nil {
+		return nil
+	}
+	clone := *m
+	clone.SVC = *m.SVC.PredicterClone().(*SVC)
+	return &clone
+}
+
+// checkNu returns an error if nu is not in (0, 1]
+func checkNu(nu float64) error {
+	if nu <= 0 || nu > 1 {
+		return fmt.Errorf("%w: Nu must be in (0, 1], got %g", base.ErrInvalidParam, nu)
+	}
+	return nil
+}
+
+func (m *NuSVC) checkParams() error {
+	if err := m.SVC.checkParams(); err != nil {
+		return err
+	}
+	return checkNu(m.Nu)
+}
+
+// Fit for NuSVC. Y is a column of class labels
+func (m *NuSVC) Fit(Xmatrix, Ymatrix mat.Matrix) base.Fiter {
+	return m.FitWeighted(Xmatrix, Ymatrix, nil)
+}
+
+// FitWeighted for NuSVC bounds the dual coefficient of each sample by its weight
+func (m *NuSVC) FitWeighted(Xmatrix, Ymatrix mat.Matrix, sampleWeight []float64) base.Fiter {
+	if err := m.checkParams(); err != nil {
+		panic(err)
+	}
+	if err := m.fit(Xmatrix, base.ToDense(Ymatrix), sampleWeight, nil); err != nil {
+		panic(err)
+	}
+	return m
+}
+
+func (m *NuSVC) fit(X mat.Matrix, Y *mat.Dense, sampleWeight []float64, mon *base.Monitor) error {
+	ys, Cs, err := m.pairProblems(Y, sampleWeight)
+	if err != nil {
+		return err
+	}
+	for k := range ys {
+		var sumPos, sumNeg float64
+		for s, c := range Cs[k] {
+			if ys[k][s] > 0 {
+				sumPos += c
+			} else {
+				sumNeg += c
+			}
+		}
+		if m.Nu*(sumPos+sumNeg)/2 > math.Min(sumPos, sumNeg) {
+			return fmt.Errorf("%w: Nu %g is infeasible for the pair of classes %d", base.ErrInvalidParam, m.Nu, k)
+		}
+	}
+	return m.fitPairs(X, ys, Cs, nuSVCTrain(m.Nu), mon)
+}
+
+// FitE is Fit returning an error instead of panicking
+func (m *NuSVC) FitE(X, Y mat.Matrix) error {
+	return m.FitContext(context.Background(), X, Y)
+}
+
+// FitContext is FitE stopping when ctx is done. progress is reported to the base.ProgressFunc of ctx after each SMO pass
+func (m *NuSVC) FitContext(ctx context.Context, X, Y mat.Matrix) (err error) {
+	if err = m.checkParams(); err != nil {
+		return
+	}
+	if err = base.CheckXY(X, Y); err != nil {
+		return
+	}
+	defer base.Recover(&err)
+	return m.fit(X, base.ToDense(Y), nil, base.NewMonitor(ctx))
+}
+
+// nuSVCTrain returns the trainer of a nu-SVC classifying y>0 against y<=0. the dual coefficients of each class sum to
+// nu/2 times the sum of C, which bounds each coefficient. they are scaled by 1/r to give the decision of a C-SVC
+func nuSVCTrain(nu float64) trainer {
+	return func(X mat.Matrix, Y, C []float64, kernel Kernel, tp trainParams, mon *base.Monitor) *Model {
+		samples := weightedSamples(C)
+		l := len(samples)
+		Q, QD, y := svcQ(X, Y, samples, kernel, tp)
+		Cs, alpha := make([]float64, l), make([]float64, l)
+		sum := 0.
+		for t, i := range samples {
+			Cs[t] = C[i]
+			sum += C[i]
+		}
+		sumPos, sumNeg := nu*sum/2, nu*sum/2
+		for t := range samples {
+			if y[t] > 0 {
+				alpha[t] = math.Min(Cs[t], sumPos)
+				sumPos -= alpha[t]
+			} else {
+				alpha[t] = math.Min(Cs[t], sumNeg)
+				sumNeg -= alpha[t]
+			}
+		}
+		s := newNuSolver(Q, QD, make([]float64, l), append([]float64(nil), y...), Cs, alpha, tp.Tol, tp.Shrinking, tp.MaxIter, mon)
+		alpha, rho, _ := s.solve()
+		for t := range alpha {
+			alpha[t] /= s.r
+		}
+		return newModel(X, kernel, Q, samples, alpha, y, -rho/s.r)
+	}
+}
+
+// NuSVR is a Nu-Support Vector Regressor. Nu is an upper bound on the fraction of samples outside the epsilon tube
+// and a lower bound on the fraction of support vectors. Epsilon is not used, the width of the tube is optimized
+type NuSVR struct {
+	SVR
+	Nu float64
+}
+
+// NewNuSVR returns a NuSVR with Nu 0.5 and the defaults of NewSVR
+func NewNuSVR() *NuSVR {
+	m := &NuSVR{SVR: *NewSVR(), Nu: .5}
+	return m
+}
+
+// PredicterClone for NuSVR
+func (m *NuSVR) PredicterClone() base.Predicter {
+	if m == nil {
+		return nil
+	}
+	clone := *m
+	clone.SVR = *m.SVR.PredicterClone().(*SVR)
+	return &clone
+}
+
+func (m *NuSVR) checkParams() error {
+	if err := m.BaseLibSVM.checkParams(); err != nil {
+		return err
+	}
+	return checkNu(m.Nu)
+}
+
+// Fit for NuSVR
+func (m *NuSVR) Fit(Xmatrix, Ymatrix mat.Matrix) base.Fiter {
+	return m.FitWeighted(Xmatrix, Ymatrix, nil)
+}
+
+// FitWeighted for NuSVR scales C by the weight of each sample
+func (m *NuSVR) FitWeighted(Xmatrix, Ymatrix mat.Matrix, sampleWeight []float64) base.Fiter {
+	if err := m.checkParams(); err != nil {
+		panic(err)
+	}
+	_, m.nOutputs = Ymatrix.Dims()
+	if err := m.BaseLibSVM.fitOutputs(Xmatrix, base.ToDense(Ymatrix), sampleWeight, nuSVRTrain(m.Nu), nil); err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// FitE is Fit returning an error instead of panicking
+func (m *NuSVR) FitE(X, Y mat.Matrix) error {
+	return m.FitContext(context.Background(), X, Y)
+}
+
+// FitContext is FitE stopping when ctx is done. progress is reported to the base.ProgressFunc of ctx after each SMO pass
+func (m *NuSVR) FitContext(ctx context.Context, X, Y mat.Matrix) (err error) {
+	if err = m.checkParams(); err != nil {
+		return
+	}
+	if err = base.CheckXY(X, Y); err != nil {
+		return
+	}
+	defer base.Recover(&err)
+	_, m.nOutputs = Y.Dims()
+	return m.BaseLibSVM.fitOutputs(X, base.ToDense(Y), nil, nuSVRTrain(m.Nu), base.NewMonitor(ctx))
+}
+
+// nuSVRTrain returns the trainer of a nu-SVR. the coefficients of the samples above and below the tube each sum to
+// nu/2 times the sum of C
+func nuSVRTrain(nu float64) trainer {
+	return func(X mat.Matrix, Y, C []float64, kernel Kernel, tp trainParams, mon *base.Monitor) *Model {
+		samples := weightedSamples(C)
+		l := len(samples)
+		Q, QD, y := svrQ(X, samples, kernel, tp)
+		p, Cs, alpha := make([]float64, 2*l), make([]float64, 2*l), make([]float64, 2*l)
+		sum := 0.
+		for _, i := range samples {
+			sum += C[i]
+		}
+		sum *= nu / 2
+		for t, i := range samples {
+			alpha[t] = math.Min(sum, C[i])
+			alpha[t+l] = alpha[t]
+			sum -= alpha[t]
+			p[t], p[t+l] = -Y[i], Y[i]
+			Cs[t], Cs[t+l] = C[i], C[i]
+		}
+		alpha, rho, _ := newNuSolver(Q, QD, p, y, Cs, alpha, tp.Tol, tp.Shrinking, tp.MaxIter, mon).solve()
+		for t := range samples {
+			alpha[t] -= alpha[t+l]
+		}
+		return newModel(X, kernel, Q, samples, alpha[:l], nil, -rho)
+	}
+}
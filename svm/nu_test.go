@@ -0,0 +1,103 @@
+package svm
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/pa-m/sklearn/base"
+	"github.com/pa-m/sklearn/datasets"
+	"github.com/pa-m/sklearn/preprocessing"
+	"gonum.org/v1/gonum/mat"
+)
+
+var _ = []base.PredicterE{&NuSVC{}, &NuSVR{}, &OneClassSVM{}}
+var _ = []base.DecisionFunctioner{&NuSVC{}, &OneClassSVM{}}
+
+func ExampleNuSVC() {
+	ds := datasets.LoadIris()
+	X, _ := preprocessing.NewStandardScaler().FitTransform(ds.X, nil)
+	clf := NewNuSVC()
+	clf.Nu = .1
+	clf.Fit(X, ds.Y)
+	fmt.Printf("accuracy: %.2f\n", clf.Score(X, ds.Y))
+	// Output:
+	// accuracy: 0.99
+}
+
+func TestNuSVC(t *testing.T) {
+	X, Y := breastCancer(200)
+	for _, nu := range []float64{.1, .3, .6} {
+		clf := NewNuSVC()
+		clf.Nu = nu
+		clf.Fit(X, Y)
+		model := clf.Model[0]
+		D := clf.DecisionFunction(X, nil)
+		// nu bounds the fractions of margin errors and of support vectors
+		marginErrors := 0
+		for i := 0; i < 200; i++ {
+			if Y.At(i, 0)*D.At(i, 0) < 1-1e-3 {
+				marginErrors++
+			}
+		}
+		if frac := float64(marginErrors) / 200; frac > nu+1e-2 {
+			t.Errorf("nu %g: expected at most a fraction nu of margin errors, got %g", nu, frac)
+		}
+		if frac := float64(len(model.Support)) / 200; frac < nu-1e-2 {
+			t.Errorf("nu %g: expected at least a fraction nu of support vectors, got %g", nu, frac)
+		}
+		// the solution is the one of a C-SVC whose C is the upper bound of the dual coefficients
+		C := 0.
+		for _, a := range model.Alphas {
+			C = math.Max(C, a)
+		}
+		svc := NewSVC()
+		svc.C = C
+		svc.Tol = 1e-6
+		svc.Fit(X, Y)
+		if !mat.EqualApprox(D, svc.DecisionFunction(X, nil), 1e-2) {
+			t.Errorf("nu %g: expected the decisions of a SVC with C %g", nu, C)
+		}
+	}
+
+	// 10 positive samples out of 100 cannot have half of the samples as support vectors
+	clf := NewNuSVC()
+	if err := clf.FitE(X.Slice(0, 100, 0, 30), mat.NewDense(100, 1, append(make([]float64, 90), 1, 1, 1, 1, 1, 1, 1, 1, 1, 1))); !errors.Is(err, base.ErrInvalidParam) {
+		t.Errorf("expected ErrInvalidParam for an infeasible Nu, got %v", err)
+	}
+	clf.Nu = 1.5
+	if err := clf.FitE(X, Y); !errors.Is(err, base.ErrInvalidParam) {
+		t.Errorf("expected ErrInvalidParam for Nu > 1, got %v", err)
+	}
+}
+
+func TestNuSVR(t *testing.T) {
+	X, _ := breastCancer(200)
+	// regress the first feature on the others
+	Y := mat.DenseCopyOf(X.ColView(0))
+	X = mat.DenseCopyOf(X.Slice(0, 200, 1, 30))
+	for _, nu := range []float64{.2, .5, .8} {
+		reg := NewNuSVR()
+		reg.Nu = nu
+		reg.C = 10
+		reg.Fit(X, Y)
+		model := reg.Model[0]
+		if score := reg.Score(X, Y); score < .9 {
+			t.Errorf("nu %g: expected R2 >= .9, got %g", nu, score)
+		}
+		// nu bounds the fractions of samples outside the tube, whose coefficient is C, and of support vectors
+		outside := 0
+		for _, a := range model.Alphas {
+			if math.Abs(a) >= reg.C-1e-9 {
+				outside++
+			}
+		}
+		if frac := float64(outside) / 200; frac > nu+1e-2 {
+			t.Errorf("nu %g: expected at most a fraction nu of samples outside the tube, got %g", nu, frac)
+		}
+		if frac := float64(len(model.Support)) / 200; frac < nu-1e-2 {
+			t.Errorf("nu %g: expected at least a fraction nu of support vectors, got %g", nu, frac)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package svm
+
+import (
+	"context"
+	"math"
+
+	"github.com/pa-m/sklearn/base"
+	"gonum.org/v1/gonum/mat"
+)
+
+// OneClassSVM is an unsupervised novelty detector estimating the support of the distribution of the training samples.
+// Nu is an upper bound on the fraction of training samples outside the support, and a lower bound on the fraction
+// of support vectors. C and Epsilon are not used
+type OneClassSVM struct {
+	BaseLibSVM
+	Nu float64
+	// Offset is subtracted from ScoreSamples to give DecisionFunction, set by Fit
+	Offset float64 `param:"-"`
+}
+
+// NewOneClassSVM returns a OneClassSVM with Nu 0.5 and a rbf kernel
+func NewOneClassSVM() *OneClassSVM {
+	m := &OneClassSVM{
+		BaseLibSVM: BaseLibSVM{C: 1., Kernel: "rbf", Degree: 3., Gamma: 0., Coef0: 0., Shrinking: true, Tol: 1e-3, CacheSize: 200},
+		Nu:         .5,
+	}
+	return m
+}
+
+// PredicterClone for OneClassSVM
+func (m *OneClassSVM) PredicterClone() base.Predicter {
+	if m == nil {
+		return nil
+	}
+	clone := *m
+	clone.BaseLibSVM.resetFitted()
+	clone.Offset = 0
+	return base.DeepCopy(&clone).(*OneClassSVM)
+}
+
+// IsClassifier returns false for OneClassSVM
+func (*OneClassSVM) IsClassifier() bool { return false }
+
+func (m *OneClassSVM) checkParams() error {
+	if err := m.BaseLibSVM.checkParams(); err != nil {
+		return err
+	}
+	return checkNu(m.Nu)
+}
+
+// Fit for OneClassSVM. Y is not used
+func (m *OneClassSVM) Fit(Xmatrix, Ymatrix mat.Matrix) base.Fiter {
+	return m.FitWeighted(Xmatrix, Ymatrix, nil)
+}
+
+// FitWeighted for OneClassSVM bounds the dual coefficient of each sample by its weight
+func (m *OneClassSVM) FitWeighted(Xmatrix, Ymatrix mat.Matrix, sampleWeight []float64) base.Fiter {
+	if err := m.checkParams(); err != nil {
+		panic(err)
+	}
+	if err := m.fit(Xmatrix, sampleWeight, nil); err != nil {
+		panic(err)
+	}
+	return m
+}
+
+func (m *OneClassSVM) fit(X mat.Matrix, sampleWeight []float64, mon *base.Monitor) error {
+	nSamples, _ := X.Dims()
+	y, C := make([]float64, nSamples), make([]float64, nSamples)
+	for i := range y {
+		y[i], C[i] = 1, 1
+		if sampleWeight != nil {
+			C[i] = sampleWeight[i]
+		}
+	}
+	if err := m.BaseLibSVM.fit(X, [][]float64{y}, [][]float64{C}, oneClassTrain(m.Nu), mon); err != nil {
+		return err
+	}
+	m.Offset = -m.Model[0].B
+	return nil
+}
+
+// FitE is Fit returning an error instead of panicking
+func (m *OneClassSVM) FitE(X, Y mat.Matrix) error {
+	return m.FitContext(context.Background(), X, Y)
+}
+
+// FitContext is FitE stopping when ctx is done. progress is reported to the base.ProgressFunc of ctx after each SMO pass
+func (m *OneClassSVM) FitContext(ctx context.Context, X, Y mat.Matrix) (err error) {
+	if err = m.checkParams(); err != nil {
+		return
+	}
+	if err = base.CheckXY(X, Y); err != nil {
+		return
+	}
+	defer base.Recover(&err)
+	return m.fit(X, nil, base.NewMonitor(ctx))
+}
+
+// oneClassTrain returns the trainer of a one-class SVM, separating the samples from the origin in feature space.
+// the dual coefficients sum to nu times the sum of C, which bounds each coefficient
+func oneClassTrain(nu float64) trainer {
+	return func(X mat.Matrix, Y, C []float64, kernel Kernel, tp trainParams, mon *base.Monitor) *Model {
+		samples := weightedSamples(C)
+		l := len(samples)
+		Q, QD, y := svcQ(X, Y, samples, kernel, tp)
+		Cs, alpha := make([]float64, l), make([]float64, l)
+		sum := 0.
+		for t, i := range samples {
+			Cs[t] = C[i]
+			sum += nu * C[i]
+		}
+		for t := 0; t < l && sum > 0; t++ {
+			alpha[t] = math.Min(Cs[t], sum)
+			sum -= alpha[t]
+		}
+		alpha, rho, _ := newSolver(Q, QD, make([]float64, l), append([]float64(nil), y...), Cs, alpha, tp.Tol, tp.Shrinking, tp.MaxIter, mon).solve()
+		return newModel(X, kernel, Q, samples, alpha, y, -rho)
+	}
+}
+
+// GetNOutputs returns 1 for OneClassSVM
+func (m *OneClassSVM) GetNOutputs() int { return 1 }
+
+// DecisionFunction returns the signed distance of samples to the boundary of the support, positive for inliers
+func (m *OneClassSVM) DecisionFunction(X mat.Matrix, Y mat.Mutable) *mat.Dense {
+	base.MustBeFitted(m)
+	nSamples, _ := X.Dims()
+	D := mat.NewDense(nSamples, 1, nil)
+	svmPredict(m.Model[0], X, D, 0, false)
+	return base.FromDense(Y, D)
+}
+
+// ScoreSamples returns the unshifted decision of samples, lower for abnormal samples
+func (m *OneClassSVM) ScoreSamples(X mat.Matrix, Y mat.Mutable) *mat.Dense {
+	D := m.DecisionFunction(X, nil)
+	D.Apply(func(i, j int, v float64) float64 { return v + m.Offset }, D)
+	return base.FromDense(Y, D)
+}
+
+// Predict for OneClassSVM returns 1 for inliers and -1 for outliers
+func (m *OneClassSVM) Predict(X mat.Matrix, Ymutable mat.Mutable) *mat.Dense {
+	Y := m.DecisionFunction(X, nil)
+	Y.Apply(func(i, j int, v float64) float64 {
+		if v > 0 {
+			return 1
+		}
+		return -1
+	}, Y)
+	return base.FromDense(Ymutable, Y)
+}
+
+// PredictE is Predict returning an error instead of panicking
+func (m *OneClassSVM) PredictE(X mat.Matrix, Y mat.Mutable) (*mat.Dense, error) {
+	if err := m.BaseLibSVM.checkFitted(X); err != nil {
+		return nil, err
+	}
+	return base.PredictE(m, X, Y)
+}
+
+// Score for OneClassSVM returns the mean of ScoreSamples. Y is not used
+func (m *OneClassSVM) Score(X, Y mat.Matrix) float64 {
+	S := m.ScoreSamples(X, nil)
+	nSamples, _ := S.Dims()
+	return mat.Sum(S) / float64(nSamples)
+}
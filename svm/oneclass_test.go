@@ -0,0 +1,72 @@
+package svm
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"golang.org/x/exp/rand"
+
+	"github.com/pa-m/sklearn/base"
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestOneClassSVM(t *testing.T) {
+	// a gaussian blob for training, and new samples around it and far from it
+	rnd := rand.New(base.NewSource(7))
+	X := mat.NewDense(200, 2, nil)
+	X.Apply(func(i, j int, v float64) float64 { return rnd.NormFloat64() }, X)
+	Xnew := mat.NewDense(4, 2, []float64{0, 0, .5, -.5, 6, 6, -5, 4})
+
+	for _, nu := range []float64{.05, .2, .5} {
+		m := NewOneClassSVM()
+		m.Nu = nu
+		m.Gamma = .1
+		if err := m.FitE(X, nil); err != nil {
+			t.Fatal(err)
+		}
+		// nu bounds the fractions of training outliers and of support vectors
+		outliers := 0
+		for _, y := range m.Predict(X, nil).RawMatrix().Data {
+			if y < 0 {
+				outliers++
+			}
+		}
+		if frac := float64(outliers) / 200; frac > nu+1e-2 {
+			t.Errorf("nu %g: expected at most a fraction nu of outliers, got %g", nu, frac)
+		}
+		if frac := float64(len(m.Support[0])) / 200; frac < nu-1e-2 {
+			t.Errorf("nu %g: expected at least a fraction nu of support vectors, got %g", nu, frac)
+		}
+		if Ypred := m.Predict(Xnew, nil); !mat.Equal(Ypred, mat.NewDense(4, 1, []float64{1, 1, -1, -1})) {
+			t.Errorf("nu %g: expected the new samples to be inlier, inlier, outlier, outlier, got %v", nu, mat.Formatted(Ypred.T()))
+		}
+		D, S := m.DecisionFunction(Xnew, nil), m.ScoreSamples(Xnew, nil)
+		for i := 0; i < 4; i++ {
+			if math.Abs(S.At(i, 0)-m.Offset-D.At(i, 0)) > 1e-12 {
+				t.Errorf("nu %g: expected ScoreSamples to be DecisionFunction plus Offset", nu)
+			}
+		}
+	}
+
+	// a zero weight sample is ignored
+	w := make([]float64, 201)
+	for i := range w[:200] {
+		w[i] = 1
+	}
+	Xw := mat.NewDense(201, 2, nil)
+	Xw.Slice(0, 200, 0, 2).(*mat.Dense).Copy(X)
+	Xw.SetRow(200, []float64{6, 6})
+	weighted, unweighted := NewOneClassSVM(), NewOneClassSVM()
+	weighted.FitWeighted(Xw, nil, w)
+	unweighted.Fit(X, nil)
+	if !mat.EqualApprox(weighted.DecisionFunction(Xnew, nil), unweighted.DecisionFunction(Xnew, nil), 1e-6) {
+		t.Error("expected a zero weight sample to be ignored")
+	}
+
+	m := NewOneClassSVM()
+	m.Nu = 0
+	if err := m.FitE(X, nil); !errors.Is(err, base.ErrInvalidParam) {
+		t.Errorf("expected ErrInvalidParam for Nu 0, got %v", err)
+	}
+}
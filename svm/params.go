@@ -0,0 +1,59 @@
+package svm
+
+import "github.com/pa-m/sklearn/base"
+
+// GetParams returns the parameters of SVC. see base.GetFieldParams
+func (m *SVC) GetParams() map[string]interface{} { return base.GetFieldParams(m) }
+
+// SetParams sets the parameters of SVC. see base.SetFieldParams
+func (m *SVC) SetParams(params map[string]interface{}) error {
+	return base.SetFieldParams(m, params)
+}
+
+// GetParams returns the parameters of SVR. see base.GetFieldParams
+func (m *SVR) GetParams() map[string]interface{} { return base.GetFieldParams(m) }
+
+// SetParams sets the parameters of SVR. see base.SetFieldParams
+func (m *SVR) SetParams(params map[string]interface{}) error {
+	return base.SetFieldParams(m, params)
+}
+
+// GetParams returns the parameters of LinearSVC. see base.GetFieldParams
+func (m *LinearSVC) GetParams() map[string]interface{} { return base.GetFieldParams(m) }
+
+// SetParams sets the parameters of LinearSVC. see base.SetFieldParams
+func (m *LinearSVC) SetParams(params map[string]interface{}) error {
+	return base.SetFieldParams(m, params)
+}
+
+// GetParams returns the parameters of LinearSVR. see base.GetFieldParams
+func (m *LinearSVR) GetParams() map[string]interface{} { return base.GetFieldParams(m) }
+
+// SetParams sets the parameters of LinearSVR. see base.SetFieldParams
+func (m *LinearSVR) SetParams(params map[string]interface{}) error {
+	return base.SetFieldParams(m, params)
+}
+
+// GetParams returns the parameters of NuSVC. see base.GetFieldParams
+func (m *NuSVC) GetParams() map[string]interface{} { return base.GetFieldParams(m) }
+
+// SetParams sets the parameters of NuSVC. see base.SetFieldParams
+func (m *NuSVC) SetParams(params map[string]interface{}) error {
+	return base.SetFieldParams(m, params)
+}
+
+// GetParams returns the parameters of NuSVR. see base.GetFieldParams
+func (m *NuSVR) GetParams() map[string]interface{} { return base.GetFieldParams(m) }
+
+// SetParams sets the parameters of NuSVR. see base.SetFieldParams
+func (m *NuSVR) SetParams(params map[string]interface{}) error {
+	return base.SetFieldParams(m, params)
+}
+
+// GetParams returns the parameters of OneClassSVM. see base.GetFieldParams
+func (m *OneClassSVM) GetParams() map[string]interface{} { return base.GetFieldParams(m) }
+
+// SetParams sets the parameters of OneClassSVM. see base.SetFieldParams
+func (m *OneClassSVM) SetParams(params map[string]interface{}) error {
+	return base.SetFieldParams(m, params)
+}
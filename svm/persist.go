@@ -0,0 +1,161 @@
+package svm
+
+import (
+	"fmt"
+
+	"github.com/pa-m/sklearn/base"
+)
+
+func init() {
+	base.Register(&SVC{})
+	base.Register(&SVR{})
+	base.Register(&LinearSVC{})
+	base.Register(&LinearSVR{})
+	base.Register(&NuSVC{})
+	base.Register(&NuSVR{})
+	base.Register(&OneClassSVM{})
+}
+
+// checkPersistable returns an error if Kernel is a func or a Kernel which is not a base.Persister
+func (m *BaseLibSVM) checkPersistable() error {
+	switch m.Kernel.(type) {
+	case nil, string, base.Persister:
+		return nil
+	}
+	return fmt.Errorf("%w: kernel %T", base.ErrNotPersistable, m.Kernel)
+}
+
+// restoreKernel sets the KernelFunction of loaded models
+func (m *BaseLibSVM) restoreKernel() {
+	if len(m.Model) == 0 {
+		return
+	}
+	K := m.kernel()
+	for _, model := range m.Model {
+		if model != nil {
+			model.setKernel(K)
+		}
+	}
+}
+
+// MarshalState allows SVC to be saved by base.Save. Kernel must be a string or a base.Persister
+func (m *SVC) MarshalState() (*base.State, error) {
+	if err := m.BaseLibSVM.checkPersistable(); err != nil {
+		return nil, err
+	}
+	return base.MarshalFields(m)
+}
+
+// UnmarshalState restores a SVC saved by base.Save
+func (m *SVC) UnmarshalState(st *base.State) error {
+	*m = *NewSVC()
+	if err := base.UnmarshalFields(m, st); err != nil {
+		return err
+	}
+	m.BaseLibSVM.restoreKernel()
+	return nil
+}
+
+// MarshalState allows SVR to be saved by base.Save. Kernel must be a string or a base.Persister
+func (m *SVR) MarshalState() (*base.State, error) {
+	if err := m.BaseLibSVM.checkPersistable(); err != nil {
+		return nil, err
+	}
+	st, err := base.MarshalFields(m)
+	if err == nil {
+		err = st.Set("nOutputs", m.nOutputs)
+	}
+	return st, err
+}
+
+// UnmarshalState restores a SVR saved by base.Save
+func (m *SVR) UnmarshalState(st *base.State) error {
+	*m = *NewSVR()
+	if err := base.UnmarshalFields(m, st); err != nil {
+		return err
+	}
+	if _, err := st.Get("nOutputs", &m.nOutputs); err != nil {
+		return err
+	}
+	m.BaseLibSVM.restoreKernel()
+	return nil
+}
+
+// MarshalState allows LinearSVC to be saved by base.Save
+func (m *LinearSVC) MarshalState() (*base.State, error) { return base.MarshalFields(m) }
+
+// UnmarshalState restores a LinearSVC saved by base.Save
+func (m *LinearSVC) UnmarshalState(st *base.State) error {
+	*m = *NewLinearSVC()
+	return base.UnmarshalFields(m, st)
+}
+
+// MarshalState allows LinearSVR to be saved by base.Save
+func (m *LinearSVR) MarshalState() (*base.State, error) { return base.MarshalFields(m) }
+
+// UnmarshalState restores a LinearSVR saved by base.Save
+func (m *LinearSVR) UnmarshalState(st *base.State) error {
+	*m = *NewLinearSVR()
+	return base.UnmarshalFields(m, st)
+}
+
+// MarshalState allows NuSVC to be saved by base.Save. Kernel must be a string or a base.Persister
+func (m *NuSVC) MarshalState() (*base.State, error) {
+	if err := m.BaseLibSVM.checkPersistable(); err != nil {
+		return nil, err
+	}
+	return base.MarshalFields(m)
+}
+
+// UnmarshalState restores a NuSVC saved by base.Save
+func (m *NuSVC) UnmarshalState(st *base.State) error {
+	*m = *NewNuSVC()
+	if err := base.UnmarshalFields(m, st); err != nil {
+		return err
+	}
+	m.BaseLibSVM.restoreKernel()
+	return nil
+}
+
+// MarshalState allows NuSVR to be saved by base.Save. Kernel must be a string or a base.Persister
+func (m *NuSVR) MarshalState() (*base.State, error) {
+	if err := m.BaseLibSVM.checkPersistable(); err != nil {
+		return nil, err
+	}
+	st, err := base.MarshalFields(m)
+	if err == nil {
+		err = st.Set("nOutputs", m.nOutputs)
+	}
+	return st, err
+}
+
+// UnmarshalState restores a NuSVR saved by base.Save
+func (m *NuSVR) UnmarshalState(st *base.State) error {
+	*m = *NewNuSVR()
+	if err := base.UnmarshalFields(m, st); err != nil {
+		return err
+	}
+	if _, err := st.Get("nOutputs", &m.nOutputs); err != nil {
+		return err
+	}
+	m.BaseLibSVM.restoreKernel()
+	return nil
+}
+
+// MarshalState allows OneClassSVM to be saved by base.Save. Kernel must be a string or a base.Persister
+func (m *OneClassSVM) MarshalState() (*base.State, error) {
+	if err := m.BaseLibSVM.checkPersistable(); err != nil {
+		return nil, err
+	}
+	return base.MarshalFields(m)
+}
+
+// UnmarshalState restores a OneClassSVM saved by base.Save
+func (m *OneClassSVM) UnmarshalState(st *base.State) error {
+	*m = *NewOneClassSVM()
+	if err := base.UnmarshalFields(m, st); err != nil {
+		return err
+	}
+	m.BaseLibSVM.restoreKernel()
+	return nil
+}
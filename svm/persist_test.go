@@ -0,0 +1,53 @@
+package svm
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/pa-m/sklearn/base"
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestSaveLoad(t *testing.T) {
+	X := mat.NewDense(8, 2, []float64{0.4, -0.7, -1.5, -1., -1.4, -0.9, -1.3, -1.2, 1., 1., 1.3, 0.8, 1.2, 0.5, 1.3, 2.1})
+	Y := mat.NewDense(8, 1, []float64{-1, -1, -1, -1, 1, 1, 1, 1})
+	svc, svr := NewSVC(), NewSVR()
+	svc.Kernel, svr.Kernel = "poly", "linear"
+	for _, m := range []base.Predicter{svc, svr, NewLinearSVC(), NewLinearSVR(), NewNuSVC(), NewNuSVR(), NewOneClassSVM()} {
+		m.Fit(X, Y)
+		buf := new(bytes.Buffer)
+		if err := base.Save(buf, m.(base.Persister)); err != nil {
+			t.Fatalf("%T: %s", m, err)
+		}
+		loaded, err := base.Load(buf)
+		if err != nil {
+			t.Fatalf("%T: %s", m, err)
+		}
+		if loaded.GetNOutputs() != 1 {
+			t.Errorf("%T: expected 1 output, got %d", m, loaded.GetNOutputs())
+		}
+		if !mat.EqualApprox(m.Predict(X, nil), loaded.Predict(X, nil), 1e-12) {
+			t.Errorf("%T: loaded model predictions differ", m)
+		}
+	}
+	svc = NewSVC()
+	svc.ClassWeight = map[float64]float64{-1: 2}
+	buf := new(bytes.Buffer)
+	if err := base.Save(buf, svc.Fit(X, Y).(*SVC)); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := base.Load(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(loaded.(*SVC).ClassWeight, svc.ClassWeight) {
+		t.Errorf("ClassWeight not restored: %#v", loaded.(*SVC).ClassWeight)
+	}
+	svc = NewSVC()
+	svc.Kernel = func(a, b []float64) float64 { return 1 }
+	if err := base.Save(new(bytes.Buffer), svc); !errors.Is(err, base.ErrNotPersistable) {
+		t.Errorf("expected ErrNotPersistable for a func kernel, got %v", err)
+	}
+}
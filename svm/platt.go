@@ -0,0 +1,202 @@
+package svm
+
+import (
+	"math"
+
+	"golang.org/x/exp/rand"
+
+	"github.com/pa-m/sklearn/base"
+	"gonum.org/v1/gonum/mat"
+)
+
+// plattTrain fits the A,B parameters of the sigmoid 1/(1+exp(A*f+B)) mapping decision values dec to the probability of y>0.
+// it is the sigmoid_train of libsvm (Lin, Lin and Weng, A note on Platt's probabilistic outputs for support vector machines)
+func plattTrain(dec, y []float64) (A, B float64) {
+	var prior0, prior1 float64
+	for _, yi := range y {
+		if yi > 0 {
+			prior1++
+		} else {
+			prior0++
+		}
+	}
+	const (
+		maxIter = 100
+		minStep = 1e-10
+		sigma   = 1e-12
+		eps     = 1e-5
+	)
+	hiTarget, loTarget := (prior1+1)/(prior1+2), 1/(prior0+2)
+	t := make([]float64, len(y))
+	for i, yi := range y {
+		if yi > 0 {
+			t[i] = hiTarget
+		} else {
+			t[i] = loTarget
+		}
+	}
+	objective := func(A, B float64) (f float64) {
+		for i := range dec {
+			fApB := dec[i]*A + B
+			if fApB >= 0 {
+				f += t[i]*fApB + math.Log1p(math.Exp(-fApB))
+			} else {
+				f += (t[i]-1)*fApB + math.Log1p(math.Exp(fApB))
+			}
+		}
+		return
+	}
+	A, B = 0, math.Log((prior0+1)/(prior1+1))
+	fval := objective(A, B)
+	for iter := 0; iter < maxIter; iter++ {
+		// gradient and Hessian, with H' = H + sigma I
+		h11, h22, h21, g1, g2 := sigma, sigma, 0., 0., 0.
+		for i := range dec {
+			fApB := dec[i]*A + B
+			var p, q float64
+			if fApB >= 0 {
+				p = math.Exp(-fApB) / (1 + math.Exp(-fApB))
+				q = 1 / (1 + math.Exp(-fApB))
+			} else {
+				p = 1 / (1 + math.Exp(fApB))
+				q = math.Exp(fApB) / (1 + math.Exp(fApB))
+			}
+			d2 := p * q
+			h11 += dec[i] * dec[i] * d2
+			h22 += d2
+			h21 += dec[i] * d2
+			d1 := t[i] - p
+			g1 += dec[i] * d1
+			g2 += d1
+		}
+		if math.Abs(g1) < eps && math.Abs(g2) < eps {
+			break
+		}
+		// Newton direction -inv(H') g
+		det := h11*h22 - h21*h21
+		dA := -(h22*g1 - h21*g2) / det
+		dB := -(-h21*g1 + h11*g2) / det
+		gd := g1*dA + g2*dB
+		// line search
+		stepSize := 1.
+		for stepSize >= minStep {
+			newA, newB := A+stepSize*dA, B+stepSize*dB
+			if newf := objective(newA, newB); newf < fval+0.0001*stepSize*gd {
+				A, B, fval = newA, newB, newf
+				break
+			}
+			stepSize /= 2
+		}
+		if stepSize < minStep {
+			break
+		}
+	}
+	return
+}
+
+// plattPredict returns the probability of the positive class for the decision value dec
+func plattPredict(dec, A, B float64) float64 {
+	fApB := dec*A + B
+	if fApB >= 0 {
+		return math.Exp(-fApB) / (1 + math.Exp(-fApB))
+	}
+	return 1 / (1 + math.Exp(fApB))
+}
+
+// plattCV fits the Platt scaling parameters of the classifier of y trained by train on decision values of a 5-fold
+// cross validation, so that they are not biased by the training set. it is the svm_binary_svc_probability of libsvm
+func plattCV(X mat.Matrix, y, C []float64, kernel Kernel, tp trainParams, train trainer, mon *base.Monitor, rnd *rand.Rand) (A, B float64) {
+	const nFolds = 5
+	samples := weightedSamples(C)
+	l := len(samples)
+	rnd.Shuffle(l, func(i, j int) { samples[i], samples[j] = samples[j], samples[i] })
+	K := rowsKernel(X, kernel)
+	dec, labels := make([]float64, l), make([]float64, l)
+	Cfold := make([]float64, len(C))
+	for fold := 0; fold < nFolds; fold++ {
+		begin, end := fold*l/nFolds, (fold+1)*l/nFolds
+		copy(Cfold, C)
+		var nPos, nNeg int
+		for t, i := range samples {
+			switch {
+			case t >= begin && t < end:
+				Cfold[i] = 0
+			case y[i] > 0:
+				nPos++
+			default:
+				nNeg++
+			}
+		}
+		var model *Model
+		if nPos > 0 && nNeg > 0 {
+			model = train(X, y, Cfold, kernel, tp, mon)
+		}
+		for t := begin; t < end; t++ {
+			switch {
+			case model != nil:
+				dec[t] = model.B
+				for s, sv := range model.Support {
+					dec[t] += model.Alphas[s] * model.Y[s] * K(samples[t], sv)
+				}
+			case nPos > 0:
+				dec[t] = 1
+			case nNeg > 0:
+				dec[t] = -1
+			}
+		}
+	}
+	for t, i := range samples {
+		labels[t] = y[i]
+	}
+	return plattTrain(dec, labels)
+}
+
+// coupleProbabilities sets p to the class probabilities given the probabilities r[i][j] of class i against class j.
+// it is the pairwise coupling method 2 of Wu, Lin and Weng, as multiclass_probability of libsvm
+func coupleProbabilities(r [][]float64, p []float64) {
+	k := len(p)
+	maxIter := 100
+	if k > maxIter {
+		maxIter = k
+	}
+	eps := .005 / float64(k)
+	Q := make([][]float64, k)
+	Qp := make([]float64, k)
+	for t := range Q {
+		Q[t] = make([]float64, k)
+		p[t] = 1 / float64(k)
+		for j := range Q[t] {
+			if j == t {
+				continue
+			}
+			Q[t][t] += r[j][t] * r[j][t]
+			Q[t][j] = -r[j][t] * r[t][j]
+		}
+	}
+	for iter := 0; iter < maxIter; iter++ {
+		pQp := 0.
+		for t := range Q {
+			Qp[t] = 0
+			for j := range Q[t] {
+				Qp[t] += Q[t][j] * p[j]
+			}
+			pQp += p[t] * Qp[t]
+		}
+		maxError := 0.
+		for t := range Qp {
+			maxError = math.Max(maxError, math.Abs(Qp[t]-pQp))
+		}
+		if maxError < eps {
+			break
+		}
+		for t := range Q {
+			diff := (-Qp[t] + pQp) / Q[t][t]
+			p[t] += diff
+			pQp = (pQp + diff*(diff*Q[t][t]+2*Qp[t])) / (1 + diff) / (1 + diff)
+			for j := range Qp {
+				Qp[j] = (Qp[j] + diff*Q[t][j]) / (1 + diff)
+				p[j] /= 1 + diff
+			}
+		}
+	}
+}
@@ -0,0 +1,547 @@
+package svm
+
+import (
+	"log"
+	"math"
+
+	"github.com/pa-m/sklearn/base"
+)
+
+// solver of libsvm: https://www.csie.ntu.edu.tw/~cjlin/libsvm/
+// R.-E. Fan, P.-H. Chen and C.-J. Lin, Working set selection using second order information for training SVM, JMLR 2005
+
+const tau = 1e-12
+
+// qMatrix is the matrix Q of the quadratic term of a svm dual problem. its indices are permuted by swap
+type qMatrix interface {
+	// row returns the elements [0,n) of row i
+	row(i, n int) []float64
+	swap(i, j int)
+}
+
+// kernelQ is Q[i,j]=sign[i]*sign[j]*K(pos[i],pos[j]) where K is the kernel matrix of the samples held by cache
+type kernelQ struct {
+	cache *kernelCache
+	pos   []int
+	sign  []float64
+}
+
+func (q *kernelQ) row(i, n int) []float64 {
+	Ki := q.cache.row(q.pos[i])
+	r := make([]float64, n)
+	for j := range r {
+		r[j] = q.sign[i] * q.sign[j] * Ki[q.pos[j]]
+	}
+	return r
+}
+
+func (q *kernelQ) swap(i, j int) {
+	q.pos[i], q.pos[j] = q.pos[j], q.pos[i]
+	q.sign[i], q.sign[j] = q.sign[j], q.sign[i]
+}
+
+const (
+	lowerBound int8 = iota
+	upperBound
+	free
+)
+
+// solver solves min 0.5 a'Qa + p'a subject to y'a=0 and 0<=a[i]<=C[i] by SMO with second order working set selection
+// and shrinking. y[i] is -1 or 1. the nu solver also keeps e'a constant by working on pairs of variables of the same y
+type solver struct {
+	Q           qMatrix
+	QD          []float64
+	p, y, C     []float64
+	alpha       []float64
+	alphaStatus []int8
+	G, Gbar     []float64
+	activeSet   []int
+	activeSize  int
+	l           int
+	eps         float64
+	shrinking   bool
+	unshrink    bool
+	maxIter     int
+	mon         *base.Monitor
+	nu          bool
+	// r is the multiplier of the constraint e'a=constant, set by solve for the nu solver
+	r float64
+}
+
+// newSolver returns a solver starting at alpha
+func newSolver(Q qMatrix, QD, p, y, C, alpha []float64, eps float64, shrinking bool, maxIter int, mon *base.Monitor) *solver {
+	l := len(p)
+	s := &solver{Q: Q, QD: QD, p: p, y: y, C: C, alpha: alpha, eps: eps, shrinking: shrinking, maxIter: maxIter, mon: mon, l: l, activeSize: l}
+	s.alphaStatus = make([]int8, l)
+	s.activeSet = make([]int, l)
+	for i := range s.activeSet {
+		s.activeSet[i] = i
+		s.updateAlphaStatus(i)
+	}
+	return s
+}
+
+// newNuSolver returns a nu solver starting at alpha
+func newNuSolver(Q qMatrix, QD, p, y, C, alpha []float64, eps float64, shrinking bool, maxIter int, mon *base.Monitor) *solver {
+	s := newSolver(Q, QD, p, y, C, alpha, eps, shrinking, maxIter, mon)
+	s.nu = true
+	return s
+}
+
+func (s *solver) updateAlphaStatus(i int) {
+	switch {
+	case s.alpha[i] >= s.C[i]:
+		s.alphaStatus[i] = upperBound
+	case s.alpha[i] <= 0:
+		s.alphaStatus[i] = lowerBound
+	default:
+		s.alphaStatus[i] = free
+	}
+}
+
+func (s *solver) isUpperBound(i int) bool { return s.alphaStatus[i] == upperBound }
+func (s *solver) isLowerBound(i int) bool { return s.alphaStatus[i] == lowerBound }
+func (s *solver) isFree(i int) bool       { return s.alphaStatus[i] == free }
+
+func (s *solver) swapIndex(i, j int) {
+	s.Q.swap(i, j)
+	s.QD[i], s.QD[j] = s.QD[j], s.QD[i]
+	s.y[i], s.y[j] = s.y[j], s.y[i]
+	s.G[i], s.G[j] = s.G[j], s.G[i]
+	s.alphaStatus[i], s.alphaStatus[j] = s.alphaStatus[j], s.alphaStatus[i]
+	s.alpha[i], s.alpha[j] = s.alpha[j], s.alpha[i]
+	s.p[i], s.p[j] = s.p[j], s.p[i]
+	s.C[i], s.C[j] = s.C[j], s.C[i]
+	s.activeSet[i], s.activeSet[j] = s.activeSet[j], s.activeSet[i]
+	s.Gbar[i], s.Gbar[j] = s.Gbar[j], s.Gbar[i]
+}
+
+// reconstructGradient computes the gradient of the inactive variables from Gbar and the free variables
+func (s *solver) reconstructGradient() {
+	if s.activeSize == s.l {
+		return
+	}
+	for j := s.activeSize; j < s.l; j++ {
+		s.G[j] = s.Gbar[j] + s.p[j]
+	}
+	nFree := 0
+	for j := 0; j < s.activeSize; j++ {
+		if s.isFree(j) {
+			nFree++
+		}
+	}
+	if nFree*s.l > 2*s.activeSize*(s.l-s.activeSize) {
+		for i := s.activeSize; i < s.l; i++ {
+			Qi := s.Q.row(i, s.activeSize)
+			for j := 0; j < s.activeSize; j++ {
+				if s.isFree(j) {
+					s.G[i] += s.alpha[j] * Qi[j]
+				}
+			}
+		}
+	} else {
+		for i := 0; i < s.activeSize; i++ {
+			if s.isFree(i) {
+				Qi := s.Q.row(i, s.l)
+				for j := s.activeSize; j < s.l; j++ {
+					s.G[j] += s.alpha[i] * Qi[j]
+				}
+			}
+		}
+	}
+}
+
+// solve returns alpha in the original order of the variables, rho, and the number of iterations
+func (s *solver) solve() ([]float64, float64, int) {
+	l := s.l
+	s.G, s.Gbar = make([]float64, l), make([]float64, l)
+	copy(s.G, s.p)
+	for i := 0; i < l; i++ {
+		if s.isLowerBound(i) {
+			continue
+		}
+		Qi := s.Q.row(i, l)
+		for j := 0; j < l; j++ {
+			s.G[j] += s.alpha[i] * Qi[j]
+		}
+		if s.isUpperBound(i) {
+			for j := 0; j < l; j++ {
+				s.Gbar[j] += s.C[i] * Qi[j]
+			}
+		}
+	}
+
+	iter, pass := 0, 0
+	period := l
+	if period > 1000 {
+		period = 1000
+	}
+	counter := period + 1
+	for iter < s.maxIter {
+		if counter--; counter == 0 {
+			counter = period
+			if s.shrinking {
+				s.doShrinking()
+			}
+			if pass++; s.mon.Report(pass, math.NaN()) != nil {
+				break
+			}
+		}
+		i, j, optimal := s.selectWorkingSet()
+		if optimal {
+			// check the optimality of the whole problem
+			s.reconstructGradient()
+			s.activeSize = l
+			if i, j, optimal = s.selectWorkingSet(); optimal {
+				break
+			}
+			// shrink at the next iteration
+			counter = 1
+		}
+		iter++
+		s.update(i, j)
+	}
+	if iter >= s.maxIter {
+		if s.activeSize < l {
+			s.reconstructGradient()
+			s.activeSize = l
+		}
+		log.Printf("libsvm reached the maximum number of iterations %d\n", s.maxIter)
+	}
+	rho := s.calculateRho()
+	alpha := make([]float64, l)
+	for i := 0; i < l; i++ {
+		alpha[s.activeSet[i]] = s.alpha[i]
+	}
+	return alpha, rho, iter
+}
+
+// update optimizes the working set i, j analytically
+func (s *solver) update(i, j int) {
+	Qi, Qj := s.Q.row(i, s.activeSize), s.Q.row(j, s.activeSize)
+	Ci, Cj := s.C[i], s.C[j]
+	alpha := s.alpha
+	oldAi, oldAj := alpha[i], alpha[j]
+	if s.y[i] != s.y[j] {
+		quadCoef := s.QD[i] + s.QD[j] + 2*Qi[j]
+		if quadCoef <= 0 {
+			quadCoef = tau
+		}
+		delta := (-s.G[i] - s.G[j]) / quadCoef
+		diff := alpha[i] - alpha[j]
+		alpha[i] += delta
+		alpha[j] += delta
+		if diff > 0 {
+			if alpha[j] < 0 {
+				alpha[j], alpha[i] = 0, diff
+			}
+		} else if alpha[i] < 0 {
+			alpha[i], alpha[j] = 0, -diff
+		}
+		if diff > Ci-Cj {
+			if alpha[i] > Ci {
+				alpha[i], alpha[j] = Ci, Ci-diff
+			}
+		} else if alpha[j] > Cj {
+			alpha[j], alpha[i] = Cj, Cj+diff
+		}
+	} else {
+		quadCoef := s.QD[i] + s.QD[j] - 2*Qi[j]
+		if quadCoef <= 0 {
+			quadCoef = tau
+		}
+		delta := (s.G[i] - s.G[j]) / quadCoef
+		sum := alpha[i] + alpha[j]
+		alpha[i] -= delta
+		alpha[j] += delta
+		if sum > Ci {
+			if alpha[i] > Ci {
+				alpha[i], alpha[j] = Ci, sum-Ci
+			}
+		} else if alpha[j] < 0 {
+			alpha[j], alpha[i] = 0, sum
+		}
+		if sum > Cj {
+			if alpha[j] > Cj {
+				alpha[j], alpha[i] = Cj, sum-Cj
+			}
+		} else if alpha[i] < 0 {
+			alpha[i], alpha[j] = 0, sum
+		}
+	}
+
+	dAi, dAj := alpha[i]-oldAi, alpha[j]-oldAj
+	for k := 0; k < s.activeSize; k++ {
+		s.G[k] += Qi[k]*dAi + Qj[k]*dAj
+	}
+	for _, k := range []int{i, j} {
+		wasUpper := s.isUpperBound(k)
+		s.updateAlphaStatus(k)
+		if wasUpper == s.isUpperBound(k) {
+			continue
+		}
+		Qk, Ck := s.Q.row(k, s.l), s.C[k]
+		if wasUpper {
+			Ck = -Ck
+		}
+		for t := 0; t < s.l; t++ {
+			s.Gbar[t] += Ck * Qk[t]
+		}
+	}
+}
+
+// selectWorkingSet returns the maximal violating pair with second order information, or optimal true when
+// the stopping criterion is met
+func (s *solver) selectWorkingSet() (int, int, bool) {
+	if s.nu {
+		return s.selectWorkingSetNu()
+	}
+	Gmax, Gmax2 := math.Inf(-1), math.Inf(-1)
+	GmaxIdx, GminIdx := -1, -1
+	objDiffMin := math.Inf(1)
+	for t := 0; t < s.activeSize; t++ {
+		if s.y[t] == 1 {
+			if !s.isUpperBound(t) && -s.G[t] >= Gmax {
+				Gmax, GmaxIdx = -s.G[t], t
+			}
+		} else if !s.isLowerBound(t) && s.G[t] >= Gmax {
+			Gmax, GmaxIdx = s.G[t], t
+		}
+	}
+	i := GmaxIdx
+	var Qi []float64
+	if i != -1 {
+		Qi = s.Q.row(i, s.activeSize)
+	}
+	for j := 0; j < s.activeSize; j++ {
+		var gradDiff, quadCoef float64
+		if s.y[j] == 1 {
+			if s.isLowerBound(j) {
+				continue
+			}
+			gradDiff = Gmax + s.G[j]
+			if s.G[j] >= Gmax2 {
+				Gmax2 = s.G[j]
+			}
+			if gradDiff <= 0 {
+				continue
+			}
+			quadCoef = s.QD[i] + s.QD[j] - 2*s.y[i]*Qi[j]
+		} else {
+			if s.isUpperBound(j) {
+				continue
+			}
+			gradDiff = Gmax - s.G[j]
+			if -s.G[j] >= Gmax2 {
+				Gmax2 = -s.G[j]
+			}
+			if gradDiff <= 0 {
+				continue
+			}
+			quadCoef = s.QD[i] + s.QD[j] + 2*s.y[i]*Qi[j]
+		}
+		if quadCoef <= 0 {
+			quadCoef = tau
+		}
+		if objDiff := -gradDiff * gradDiff / quadCoef; objDiff <= objDiffMin {
+			GminIdx, objDiffMin = j, objDiff
+		}
+	}
+	if Gmax+Gmax2 < s.eps || GminIdx == -1 {
+		return 0, 0, true
+	}
+	return GmaxIdx, GminIdx, false
+}
+
+// beShrunk returns true if the bounded variable i is unlikely to move. Gmax are the maximal violations
+// -G of y=1 not upper bound, G of y=1 not lower bound, G of y=-1 not lower bound and -G of y=-1 not upper bound
+func (s *solver) beShrunk(i int, Gmax [4]float64) bool {
+	switch {
+	case s.isUpperBound(i):
+		if s.y[i] == 1 {
+			return -s.G[i] > Gmax[0]
+		}
+		return -s.G[i] > Gmax[3]
+	case s.isLowerBound(i):
+		if s.y[i] == 1 {
+			return s.G[i] > Gmax[1]
+		}
+		return s.G[i] > Gmax[2]
+	}
+	return false
+}
+
+// doShrinking removes from the active set the bounded variables which are unlikely to move
+func (s *solver) doShrinking() {
+	Gmax := [4]float64{math.Inf(-1), math.Inf(-1), math.Inf(-1), math.Inf(-1)}
+	for i := 0; i < s.activeSize; i++ {
+		if s.y[i] == 1 {
+			if !s.isUpperBound(i) {
+				Gmax[0] = math.Max(Gmax[0], -s.G[i])
+			}
+			if !s.isLowerBound(i) {
+				Gmax[1] = math.Max(Gmax[1], s.G[i])
+			}
+		} else {
+			if !s.isLowerBound(i) {
+				Gmax[2] = math.Max(Gmax[2], s.G[i])
+			}
+			if !s.isUpperBound(i) {
+				Gmax[3] = math.Max(Gmax[3], -s.G[i])
+			}
+		}
+	}
+	if !s.nu {
+		// max { -y_i * grad(f)_i | i in I_up(alpha) } and max { y_i * grad(f)_i | i in I_low(alpha) } over both classes
+		Gmax[0], Gmax[1] = math.Max(Gmax[0], Gmax[2]), math.Max(Gmax[1], Gmax[3])
+		Gmax[2], Gmax[3] = Gmax[0], Gmax[1]
+	}
+	if !s.unshrink && math.Max(Gmax[0]+Gmax[1], Gmax[2]+Gmax[3]) <= s.eps*10 {
+		s.unshrink = true
+		s.reconstructGradient()
+		s.activeSize = s.l
+	}
+	for i := 0; i < s.activeSize; i++ {
+		if !s.beShrunk(i, Gmax) {
+			continue
+		}
+		s.activeSize--
+		for s.activeSize > i {
+			if !s.beShrunk(s.activeSize, Gmax) {
+				s.swapIndex(i, s.activeSize)
+				break
+			}
+			s.activeSize--
+		}
+	}
+}
+
+// calculateRho returns the mean of y[i]*G[i] over free variables, or the middle of its feasible interval
+func (s *solver) calculateRho() float64 {
+	if s.nu {
+		return s.calculateRhoNu()
+	}
+	nFree, sumFree := 0, 0.
+	ub, lb := math.Inf(1), math.Inf(-1)
+	for i := 0; i < s.activeSize; i++ {
+		yG := s.y[i] * s.G[i]
+		switch {
+		case s.isUpperBound(i):
+			if s.y[i] == -1 {
+				ub = math.Min(ub, yG)
+			} else {
+				lb = math.Max(lb, yG)
+			}
+		case s.isLowerBound(i):
+			if s.y[i] == 1 {
+				ub = math.Min(ub, yG)
+			} else {
+				lb = math.Max(lb, yG)
+			}
+		default:
+			nFree++
+			sumFree += yG
+		}
+	}
+	if nFree > 0 {
+		return sumFree / float64(nFree)
+	}
+	return (ub + lb) / 2
+}
+
+// selectWorkingSetNu is selectWorkingSet for a pair of variables of the same y
+func (s *solver) selectWorkingSetNu() (int, int, bool) {
+	Gmaxp, Gmaxp2, Gmaxn, Gmaxn2 := math.Inf(-1), math.Inf(-1), math.Inf(-1), math.Inf(-1)
+	GmaxpIdx, GmaxnIdx, GminIdx := -1, -1, -1
+	objDiffMin := math.Inf(1)
+	for t := 0; t < s.activeSize; t++ {
+		if s.y[t] == 1 {
+			if !s.isUpperBound(t) && -s.G[t] >= Gmaxp {
+				Gmaxp, GmaxpIdx = -s.G[t], t
+			}
+		} else if !s.isLowerBound(t) && s.G[t] >= Gmaxn {
+			Gmaxn, GmaxnIdx = s.G[t], t
+		}
+	}
+	var Qip, Qin []float64
+	if GmaxpIdx != -1 {
+		Qip = s.Q.row(GmaxpIdx, s.activeSize)
+	}
+	if GmaxnIdx != -1 {
+		Qin = s.Q.row(GmaxnIdx, s.activeSize)
+	}
+	for j := 0; j < s.activeSize; j++ {
+		var gradDiff, quadCoef float64
+		if s.y[j] == 1 {
+			if s.isLowerBound(j) {
+				continue
+			}
+			gradDiff = Gmaxp + s.G[j]
+			if s.G[j] >= Gmaxp2 {
+				Gmaxp2 = s.G[j]
+			}
+			if gradDiff <= 0 {
+				continue
+			}
+			quadCoef = s.QD[GmaxpIdx] + s.QD[j] - 2*Qip[j]
+		} else {
+			if s.isUpperBound(j) {
+				continue
+			}
+			gradDiff = Gmaxn - s.G[j]
+			if -s.G[j] >= Gmaxn2 {
+				Gmaxn2 = -s.G[j]
+			}
+			if gradDiff <= 0 {
+				continue
+			}
+			quadCoef = s.QD[GmaxnIdx] + s.QD[j] - 2*Qin[j]
+		}
+		if quadCoef <= 0 {
+			quadCoef = tau
+		}
+		if objDiff := -gradDiff * gradDiff / quadCoef; objDiff <= objDiffMin {
+			GminIdx, objDiffMin = j, objDiff
+		}
+	}
+	if math.Max(Gmaxp+Gmaxp2, Gmaxn+Gmaxn2) < s.eps || GminIdx == -1 {
+		return 0, 0, true
+	}
+	if s.y[GminIdx] == 1 {
+		return GmaxpIdx, GminIdx, false
+	}
+	return GmaxnIdx, GminIdx, false
+}
+
+// calculateRhoNu returns rho and sets r from the mean gradients r1 and r2 of the free variables of each y, or the
+// middle of their feasible intervals
+func (s *solver) calculateRhoNu() float64 {
+	var nFree, sumFree [2]float64
+	ub := [2]float64{math.Inf(1), math.Inf(1)}
+	lb := [2]float64{math.Inf(-1), math.Inf(-1)}
+	for i := 0; i < s.activeSize; i++ {
+		k := 0
+		if s.y[i] != 1 {
+			k = 1
+		}
+		switch {
+		case s.isUpperBound(i):
+			lb[k] = math.Max(lb[k], s.G[i])
+		case s.isLowerBound(i):
+			ub[k] = math.Min(ub[k], s.G[i])
+		default:
+			nFree[k]++
+			sumFree[k] += s.G[i]
+		}
+	}
+	var r [2]float64
+	for k := range r {
+		if nFree[k] > 0 {
+			r[k] = sumFree[k] / nFree[k]
+		} else {
+			r[k] = (ub[k] + lb[k]) / 2
+		}
+	}
+	s.r = (r[0] + r[1]) / 2
+	return (r[0] - r[1]) / 2
+}
@@ -0,0 +1,112 @@
+package svm
+
+import (
+	"math"
+	"testing"
+
+	"github.com/pa-m/sklearn/datasets"
+	"github.com/pa-m/sklearn/preprocessing"
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestSVC_Analytic(t *testing.T) {
+	// the model of the pair of classes (-1, 1) is positive for -1: the hard margin of two points has w=x0-x1 and b=1
+	X := mat.NewDense(2, 2, []float64{0, 0, 1, 1})
+	Y := mat.NewDense(2, 1, []float64{-1, 1})
+	clf := NewSVC()
+	clf.Kernel = "linear"
+	clf.Fit(X, Y)
+	model := clf.Model[0]
+	if len(model.Support) != 2 || math.Abs(model.Alphas[0]-1) > 1e-6 || math.Abs(model.Alphas[1]-1) > 1e-6 || math.Abs(model.B-1) > 1e-6 {
+		t.Errorf("expected support [0 1], alphas [1 1] and b 1, got %v %v %g", model.Support, model.Alphas, model.B)
+	}
+}
+
+// breastCancer returns the first n samples of the scaled breast cancer dataset, with -1 and 1 labels
+func breastCancer(n int) (*mat.Dense, *mat.Dense) {
+	ds := datasets.LoadBreastCancer()
+	X, _ := preprocessing.NewStandardScaler().FitTransform(ds.X.Slice(0, n, 0, 30), nil)
+	Y := mat.DenseCopyOf(ds.Y.Slice(0, n, 0, 1))
+	Y.Apply(func(i, j int, v float64) float64 { return 2*v - 1 }, Y)
+	return X, Y
+}
+
+func TestSVC_KKT(t *testing.T) {
+	X, Y := breastCancer(200)
+	var decisions []*mat.Dense
+	for _, shrinking := range []bool{true, false} {
+		clf := NewSVC()
+		clf.C = 10
+		clf.Shrinking = shrinking
+		clf.Fit(X, Y)
+		model := clf.Model[0]
+		D := clf.DecisionFunction(X, nil)
+		decisions = append(decisions, D)
+
+		alpha := make([]float64, 200)
+		sum := 0.
+		for k, i := range model.Support {
+			alpha[i] = model.Alphas[k]
+			sum += model.Alphas[k] * model.Y[k]
+			// the model labels the first class 1
+			if model.Y[k] != -Y.At(i, 0) {
+				t.Fatalf("support vector %d: expected label %g, got %g", i, -Y.At(i, 0), model.Y[k])
+			}
+		}
+		if math.Abs(sum) > 1e-9 {
+			t.Errorf("shrinking %v: expected sum(alpha*y)=0, got %g", shrinking, sum)
+		}
+		const tol = 1e-2
+		for i := 0; i < 200; i++ {
+			yf := Y.At(i, 0) * D.At(i, 0)
+			switch {
+			case alpha[i] == 0 && yf < 1-tol, alpha[i] >= clf.C && yf > 1+tol, alpha[i] > 0 && alpha[i] < clf.C && math.Abs(yf-1) > tol:
+				t.Errorf("shrinking %v: sample %d violates KKT conditions: alpha %g y*f %g", shrinking, i, alpha[i], yf)
+			}
+		}
+	}
+	if !mat.EqualApprox(decisions[0], decisions[1], 1e-2) {
+		t.Error("expected the same decision function with and without shrinking")
+	}
+}
+
+func TestSVR_KKT(t *testing.T) {
+	X, _ := breastCancer(200)
+	// regress the first feature on the others
+	Y := mat.DenseCopyOf(X.ColView(0))
+	X = mat.DenseCopyOf(X.Slice(0, 200, 1, 30))
+	var predictions []*mat.Dense
+	for _, shrinking := range []bool{true, false} {
+		reg := NewSVR()
+		reg.C = 10
+		reg.Shrinking = shrinking
+		reg.Fit(X, Y)
+		model := reg.Model[0]
+		P := reg.Predict(X, nil)
+		predictions = append(predictions, P)
+
+		alpha := make([]float64, 200)
+		sum := 0.
+		for k, i := range model.Support {
+			alpha[i] = model.Alphas[k]
+			sum += model.Alphas[k]
+		}
+		if math.Abs(sum) > 1e-9 {
+			t.Errorf("shrinking %v: expected sum(alpha)=0, got %g", shrinking, sum)
+		}
+		const tol = 1e-2
+		for i := 0; i < 200; i++ {
+			r := Y.At(i, 0) - P.At(i, 0)
+			switch {
+			case alpha[i] == 0 && math.Abs(r) > reg.Epsilon+tol,
+				alpha[i] > 0 && alpha[i] < reg.C && math.Abs(r-reg.Epsilon) > tol,
+				alpha[i] < 0 && alpha[i] > -reg.C && math.Abs(r+reg.Epsilon) > tol,
+				math.Abs(alpha[i]) > reg.C:
+				t.Errorf("shrinking %v: sample %d violates KKT conditions: alpha %g residual %g", shrinking, i, alpha[i], r)
+			}
+		}
+	}
+	if !mat.EqualApprox(predictions[0], predictions[1], 1e-2) {
+		t.Error("expected the same predictions with and without shrinking")
+	}
+}
@@ -0,0 +1,93 @@
+package svm
+
+import (
+	"github.com/pa-m/sklearn/base"
+	"gonum.org/v1/gonum/mat"
+)
+
+// sparseKernelFunc returns the SparseFunc of kernel if it is a SparseKernel, else kernel.Func applied to densified rows
+func sparseKernelFunc(kernel Kernel) func(a, b base.SparseVector) float64 {
+	if sk, ok := kernel.(SparseKernel); ok {
+		return sk.SparseFunc
+	}
+	return func(a, b base.SparseVector) float64 { return kernel.Func(a.Dense(nil), b.Dense(nil)) }
+}
+
+// float32KernelFunc returns the Float32Func of kernel if it is a Float32Kernel, else kernel.Func applied to converted rows
+func float32KernelFunc(kernel Kernel) func(a, b []float32) float64 {
+	if fk, ok := kernel.(Float32Kernel); ok {
+		return fk.Float32Func
+	}
+	return func(a, b []float32) float64 {
+		a64, b64 := make([]float64, len(a)), make([]float64, len(b))
+		for i := range a {
+			a64[i], b64[i] = float64(a[i]), float64(b[i])
+		}
+		return kernel.Func(a64, b64)
+	}
+}
+
+// rowsKernel returns the kernel between rows i and j of X, which is a *mat.Dense, a *base.CSR or a base.General32
+func rowsKernel(X mat.Matrix, kernel Kernel) func(i, j int) float64 {
+	if kernel == (precomputedKernel{}) {
+		Xd := base.ToDense(X)
+		return Xd.At
+	}
+	switch Xt := X.(type) {
+	case *base.CSR:
+		K := sparseKernelFunc(kernel)
+		return func(i, j int) float64 { return K(Xt.Row(i), Xt.Row(j)) }
+	case base.General32:
+		K := float32KernelFunc(kernel)
+		return func(i, j int) float64 { return K(Xt.RawRowView(i), Xt.RawRowView(j)) }
+	}
+	Xd := base.ToDense(X)
+	return func(i, j int) float64 { return kernel.Func(Xd.RawRowView(i), Xd.RawRowView(j)) }
+}
+
+// setKernel sets the kernel functions of model
+func (model *Model) setKernel(kernel Kernel) {
+	model.Precomputed = kernel == (precomputedKernel{})
+	model.KernelFunction = kernel.Func
+	model.SparseKernelFunction = sparseKernelFunc(kernel)
+	model.Float32KernelFunction = float32KernelFunc(kernel)
+}
+
+// setSupportVectors copies the rows idx of X to model.X, or to model.SparseX if X is a *base.CSR, or to model.X32 if X is a base.General32
+func (model *Model) setSupportVectors(X mat.Matrix, idx []int) {
+	switch Xt := X.(type) {
+	case *base.CSR:
+		model.SparseX = Xt.SelectRows(idx)
+		return
+	case base.General32:
+		model.X32 = Xt.SelectRows(idx)
+		return
+	}
+	Xd := base.ToDense(X)
+	_, n := Xd.Dims()
+	model.X = mat.NewDense(len(idx), n, nil)
+	for ii, i := range idx {
+		model.X.SetRow(ii, Xd.RawRowView(i))
+	}
+}
+
+// kernelTo returns the kernel between row i of X and the support vector j of model
+func (model *Model) kernelTo(X mat.Matrix) func(i, j int) float64 {
+	if model.Precomputed {
+		Xd := base.ToDense(X)
+		return func(i, j int) float64 { return Xd.At(i, model.Support[j]) }
+	}
+	if model.SparseX != nil {
+		Xcsr := base.ToCSR(X)
+		return func(i, j int) float64 { return model.SparseKernelFunction(Xcsr.Row(i), model.SparseX.Row(j)) }
+	}
+	if model.X32.Data != nil {
+		X32, ok := X.(base.General32)
+		if !ok {
+			X32 = base.General32Of(X)
+		}
+		return func(i, j int) float64 { return model.Float32KernelFunction(X32.RawRowView(i), model.X32.RawRowView(j)) }
+	}
+	Xd := base.ToDense(X)
+	return func(i, j int) float64 { return model.KernelFunction(Xd.RawRowView(i), model.X.RawRowView(j)) }
+}
@@ -0,0 +1,46 @@
+package svm
+
+import (
+	"testing"
+
+	"github.com/pa-m/sklearn/base"
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestSVC_Sparse(t *testing.T) {
+	X := mat.NewDense(16, 2, []float64{0.4, -0.7, -1.5, -1., -1.4, -0.9, -1.3, -1.2, -1.1, -0.2, -1.2,
+		-0.4, -0.5, 1.2, -1.5, 2.1, 1., 1., 1.3, 0.8, 1.2, 0.5,
+		0.2, -2., 0.5, -2.4, 0.2, 0, 0., -2.7, 1.3, 2.1})
+	Y := mat.NewDense(16, 1, []float64{-1, -1, -1, -1, -1, -1, -1, -1, 1, 1, 1, 1, 1, 1, 1, 1})
+	for _, kernel := range []string{"linear", "poly", "rbf", "sigmoid"} {
+		dense, sparse := NewSVC(), NewSVC()
+		for _, m := range []*SVC{dense, sparse} {
+			m.Kernel = kernel
+			m.Gamma = 2
+			m.RandomState = base.NewLockedSource(7)
+		}
+		dense.Fit(X, Y)
+		sparse.Fit(base.ToCSR(X), Y)
+		if sparse.Model[0].X != nil || sparse.Model[0].SparseX == nil {
+			t.Errorf("%s: sparse model must keep sparse support vectors", kernel)
+		}
+		if !mat.EqualApprox(dense.DecisionFunction(X, nil), sparse.DecisionFunction(base.ToCSR(X), nil), 1e-10) {
+			t.Errorf("%s: dense and sparse decision functions differ", kernel)
+		}
+	}
+}
+
+func TestSVR_Sparse(t *testing.T) {
+	X := mat.NewDense(8, 2, []float64{0, 1, 1, 0, 2, 0, 0, 3, 4, 0, 0, 0, 6, 1, 0, 7})
+	Y := mat.NewDense(8, 1, []float64{1, 1, 2, 3, 4, 0, 7, 7})
+	dense, sparse := NewSVR(), NewSVR()
+	for _, m := range []*SVR{dense, sparse} {
+		m.Kernel = "rbf"
+		m.RandomState = base.NewLockedSource(7)
+	}
+	dense.Fit(X, Y)
+	sparse.Fit(base.ToCSR(X), Y)
+	if !mat.EqualApprox(dense.Predict(X, nil), sparse.Predict(base.ToCSR(X), nil), 1e-10) {
+		t.Error("dense and sparse predictions differ")
+	}
+}
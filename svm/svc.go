@@ -0,0 +1,342 @@
+package svm
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/pa-m/sklearn/base"
+	"github.com/pa-m/sklearn/metrics"
+	"gonum.org/v1/gonum/mat"
+)
+
+// SVC is a C-Support Vector Classifier. multiclass problems are solved one-vs-one, with a model per pair of classes
+type SVC struct {
+	BaseLibSVM
+	// Probability enables PredictProba. it fits Platt scaling on 5-fold cross validated decisions of each pair of classes
+	Probability bool
+	// ClassWeight scales C for the samples of each class. it is nil, "balanced" for weights inversely proportional
+	// to class frequencies, a map[float64]float64 from class label to weight, or a []float64 of weights in Classes order
+	ClassWeight interface{}
+	// DecisionFunctionShape is "ovr" for a decision per class, or "ovo" for a decision per pair of classes
+	DecisionFunctionShape string
+
+	// Classes are the sorted class labels. Model[k] separates the k-th pair of classes (i, j), i<j, in the order
+	// (0,1),...,(0,n-1),(1,2),...; its decision is positive for Classes[j]
+	Classes []float64 `param:"-"`
+	// ProbA and ProbB are the Platt scaling parameters of each pair of classes, set by Fit when Probability is true
+	ProbA, ProbB []float64 `param:"-"`
+}
+
+// NewSVC ...
+// Kernel: "linear","poly","rbf","sigmoid" default is "rbf"
+// if Gamma<=0 il will be changed to 1/NFeatures
+// Cachesize is in MB. defaults to 200
+func NewSVC() *SVC {
+	m := &SVC{
+		BaseLibSVM:            BaseLibSVM{C: 1., Epsilon: 0.1, Kernel: "rbf", Degree: 3., Gamma: 0., Coef0: 0., Shrinking: true, Tol: 1e-3, CacheSize: 200},
+		DecisionFunctionShape: "ovr",
+	}
+	return m
+}
+
+// PredicterClone for SVC
+func (m *SVC) PredicterClone() base.Predicter {
+	if m == nil {
+		return nil
+	}
+	clone := *m
+	clone.BaseLibSVM.resetFitted()
+	clone.Classes, clone.ProbA, clone.ProbB = nil, nil, nil
+	return base.DeepCopy(&clone).(*SVC)
+}
+
+// IsClassifier returns true for SVC
+func (m *SVC) IsClassifier() bool { return true }
+
+func (m *SVC) checkParams() error {
+	if err := m.BaseLibSVM.checkParams(); err != nil {
+		return err
+	}
+	switch m.DecisionFunctionShape {
+	case "ovr", "ovo":
+	default:
+		return fmt.Errorf("%w: DecisionFunctionShape must be \"ovr\" or \"ovo\", got %q", base.ErrInvalidParam, m.DecisionFunctionShape)
+	}
+	return nil
+}
+
+// classWeight returns the weight of each class for the class indices of the samples
+func (m *SVC) classWeight(class []int) ([]float64, error) {
+	nClasses := len(m.Classes)
+	w := make([]float64, nClasses)
+	switch cw := m.ClassWeight.(type) {
+	case nil:
+		for c := range w {
+			w[c] = 1
+		}
+	case string:
+		if cw != "balanced" {
+			return nil, fmt.Errorf("%w: ClassWeight must be \"balanced\", got %q", base.ErrInvalidParam, cw)
+		}
+		for _, c := range class {
+			w[c]++
+		}
+		for c, count := range w {
+			w[c] = float64(len(class)) / (float64(nClasses) * count)
+		}
+	case map[float64]float64:
+		for c, label := range m.Classes {
+			w[c] = 1
+			if v, ok := cw[label]; ok {
+				w[c] = v
+			}
+		}
+	case []float64:
+		if len(cw) != nClasses {
+			return nil, fmt.Errorf("%w: ClassWeight has %d weights for %d classes", base.ErrInvalidParam, len(cw), nClasses)
+		}
+		copy(w, cw)
+	default:
+		return nil, fmt.Errorf("%w: unsupported ClassWeight %T", base.ErrInvalidParam, m.ClassWeight)
+	}
+	return w, nil
+}
+
+// Fit for SVC. Y is a column of class labels
+func (m *SVC) Fit(Xmatrix, Ymatrix mat.Matrix) base.Fiter {
+	return m.FitWeighted(Xmatrix, Ymatrix, nil)
+}
+
+// FitWeighted for SVC scales C by the weight of each sample
+func (m *SVC) FitWeighted(Xmatrix, Ymatrix mat.Matrix, sampleWeight []float64) base.Fiter {
+	if err := m.checkParams(); err != nil {
+		panic(err)
+	}
+	if err := m.fit(Xmatrix, base.ToDense(Ymatrix), sampleWeight, nil); err != nil {
+		panic(err)
+	}
+	return m
+}
+
+func (m *SVC) fit(X mat.Matrix, Y *mat.Dense, sampleWeight []float64, mon *base.Monitor) error {
+	ys, Cs, err := m.pairProblems(Y, sampleWeight)
+	if err != nil {
+		return err
+	}
+	return m.fitPairs(X, ys, Cs, svmTrain, mon)
+}
+
+// pairProblems sets Classes and returns the output and the upper bound of the dual coefficients of the samples of
+// the problem of each pair of classes (i, j). the output is 1 for class i and -1 for class j, as in libsvm, the other
+// samples are ignored
+func (m *SVC) pairProblems(Y *mat.Dense, sampleWeight []float64) (ys, Cs [][]float64, err error) {
+	nSamples, nOutputs := Y.Dims()
+	if nOutputs != 1 {
+		return nil, nil, fmt.Errorf("%w: SVC expects a single column of labels, got %d columns", base.ErrShapeMismatch, nOutputs)
+	}
+	y := mat.Col(nil, 0, Y)
+	m.Classes = uniqueSorted(y)
+	nClasses := len(m.Classes)
+	if nClasses < 2 {
+		return nil, nil, fmt.Errorf("%w: SVC needs at least 2 classes, got %d", base.ErrInvalidParam, nClasses)
+	}
+	class := make([]int, nSamples)
+	for i, yi := range y {
+		class[i] = sort.SearchFloat64s(m.Classes, yi)
+	}
+	classWeight, err := m.classWeight(class)
+	if err != nil {
+		return nil, nil, err
+	}
+	C := m.BaseLibSVM.sampleC(nSamples, sampleWeight)
+	nPairs := nClasses * (nClasses - 1) / 2
+	ys, Cs = make([][]float64, nPairs), make([][]float64, nPairs)
+	for i, k := 0, 0; i < nClasses; i++ {
+		for j := i + 1; j < nClasses; j, k = j+1, k+1 {
+			ys[k], Cs[k] = make([]float64, nSamples), make([]float64, nSamples)
+			for s, c := range class {
+				switch c {
+				case i:
+					ys[k][s], Cs[k][s] = 1, C[s]*classWeight[c]
+				case j:
+					ys[k][s], Cs[k][s] = -1, C[s]*classWeight[c]
+				}
+			}
+		}
+	}
+	return ys, Cs, nil
+}
+
+// fitPairs fits the model of each pair of classes with train, and their Platt scaling if Probability is set
+func (m *SVC) fitPairs(X mat.Matrix, ys, Cs [][]float64, train trainer, mon *base.Monitor) error {
+	if err := m.BaseLibSVM.fit(X, ys, Cs, train, mon); err != nil {
+		return err
+	}
+	m.ProbA, m.ProbB = nil, nil
+	if m.Probability {
+		nPairs := len(ys)
+		X, K, tp := m.BaseLibSVM.prepare(X)
+		rnds := newRands(m.RandomState, nPairs)
+		m.ProbA, m.ProbB = make([]float64, nPairs), make([]float64, nPairs)
+		base.Parallelize(-1, nPairs, func(th, start, end int) {
+			for k := start; k < end; k++ {
+				m.ProbA[k], m.ProbB[k] = plattCV(X, ys[k], Cs[k], K, tp, train, mon, rnds[k])
+			}
+		})
+	}
+	return mon.Err()
+}
+
+// FitE is Fit returning an error instead of panicking
+func (m *SVC) FitE(X, Y mat.Matrix) error {
+	return m.FitContext(context.Background(), X, Y)
+}
+
+// FitContext is FitE stopping when ctx is done. progress is reported to the base.ProgressFunc of ctx after each SMO pass
+func (m *SVC) FitContext(ctx context.Context, X, Y mat.Matrix) (err error) {
+	if err = m.checkParams(); err != nil {
+		return
+	}
+	if err = base.CheckXY(X, Y); err != nil {
+		return
+	}
+	defer base.Recover(&err)
+	return m.fit(X, base.ToDense(Y), nil, base.NewMonitor(ctx))
+}
+
+// GetNOutputs returns 1 for SVC
+func (m *SVC) GetNOutputs() int { return 1 }
+
+// pairDecisions returns the decision of the model of each pair of classes (i, j), positive for class i
+func (m *SVC) pairDecisions(X mat.Matrix) *mat.Dense {
+	nSamples, _ := X.Dims()
+	D := mat.NewDense(nSamples, len(m.Model), nil)
+	base.Parallelize(-1, len(m.Model), func(th, start, end int) {
+		for k := start; k < end; k++ {
+			svmPredict(m.Model[k], X, D, k, false)
+		}
+	})
+	return D
+}
+
+// Predict for SVC returns the class with the most one-vs-one votes, ties going to the first class
+func (m *SVC) Predict(X mat.Matrix, Ymutable mat.Mutable) *mat.Dense {
+	base.MustBeFitted(m)
+	Y := base.ToDense(Ymutable)
+	nSamples, _ := X.Dims()
+	if Y.IsZero() {
+		*Y = *mat.NewDense(nSamples, m.GetNOutputs(), nil)
+	}
+	D := m.pairDecisions(X)
+	nClasses := len(m.Classes)
+	votes := make([]int, nClasses)
+	for s := 0; s < nSamples; s++ {
+		for c := range votes {
+			votes[c] = 0
+		}
+		for i, k := 0, 0; i < nClasses; i++ {
+			for j := i + 1; j < nClasses; j, k = j+1, k+1 {
+				if D.At(s, k) > 0 {
+					votes[i]++
+				} else {
+					votes[j]++
+				}
+			}
+		}
+		best := 0
+		for c, v := range votes {
+			if v > votes[best] {
+				best = c
+			}
+		}
+		Y.Set(s, 0, m.Classes[best])
+	}
+	return base.FromDense(Ymutable, Y)
+}
+
+// DecisionFunction returns a single column, positive for Classes[1], for binary problems. for more classes it returns
+// the decision of each pair of classes (i, j), positive for Classes[i] as in sklearn, if DecisionFunctionShape is "ovo",
+// else the one-vs-one votes of each class plus their confidence scaled to (-1/3, 1/3)
+func (m *SVC) DecisionFunction(X mat.Matrix, Y mat.Mutable) *mat.Dense {
+	base.MustBeFitted(m)
+	D := m.pairDecisions(X)
+	nClasses := len(m.Classes)
+	if nClasses == 2 {
+		D.Scale(-1, D)
+	}
+	if nClasses == 2 || m.DecisionFunctionShape == "ovo" {
+		return base.FromDense(Y, D)
+	}
+	nSamples, _ := X.Dims()
+	votes, confidences := mat.NewDense(nSamples, nClasses, nil), mat.NewDense(nSamples, nClasses, nil)
+	for s := 0; s < nSamples; s++ {
+		for i, k := 0, 0; i < nClasses; i++ {
+			for j := i + 1; j < nClasses; j, k = j+1, k+1 {
+				dec := D.At(s, k)
+				if dec > 0 {
+					votes.Set(s, i, votes.At(s, i)+1)
+				} else {
+					votes.Set(s, j, votes.At(s, j)+1)
+				}
+				confidences.Set(s, i, confidences.At(s, i)+dec)
+				confidences.Set(s, j, confidences.At(s, j)-dec)
+			}
+		}
+	}
+	votes.Apply(func(s, c int, v float64) float64 {
+		conf := confidences.At(s, c)
+		return v + conf/(3*(math.Abs(conf)+1))
+	}, votes)
+	return base.FromDense(Y, votes)
+}
+
+// PredictProba returns the probability of each class, in Classes order. the Platt scaled probabilities of each pair
+// of classes are coupled for multiclass problems. Probability must be set before Fit
+func (m *SVC) PredictProba(X mat.Matrix, Y mat.Mutable) *mat.Dense {
+	base.MustBeFitted(m)
+	if len(m.ProbA) != len(m.Model) {
+		panic(fmt.Errorf("%w: Probability must be set before Fit to use PredictProba", base.ErrInvalidParam))
+	}
+	const minProb = 1e-7
+	D := m.pairDecisions(X)
+	nSamples, _ := X.Dims()
+	nClasses := len(m.Classes)
+	P := mat.NewDense(nSamples, nClasses, nil)
+	base.Parallelize(-1, nSamples, func(th, start, end int) {
+		r := make([][]float64, nClasses)
+		for i := range r {
+			r[i] = make([]float64, nClasses)
+		}
+		for s := start; s < end; s++ {
+			for i, k := 0, 0; i < nClasses; i++ {
+				for j := i + 1; j < nClasses; j, k = j+1, k+1 {
+					pi := math.Min(math.Max(plattPredict(D.At(s, k), m.ProbA[k], m.ProbB[k]), minProb), 1-minProb)
+					r[i][j], r[j][i] = pi, 1-pi
+				}
+			}
+			p := P.RawRowView(s)
+			if nClasses == 2 {
+				p[0], p[1] = r[0][1], r[1][0]
+				continue
+			}
+			coupleProbabilities(r, p)
+		}
+	})
+	return base.FromDense(Y, P)
+}
+
+// PredictE is Predict returning an error instead of panicking
+func (m *SVC) PredictE(X mat.Matrix, Y mat.Mutable) (*mat.Dense, error) {
+	if err := m.BaseLibSVM.checkFitted(X); err != nil {
+		return nil, err
+	}
+	return base.PredictE(m, X, Y)
+}
+
+// Score for SVC returns accuracy
+func (m *SVC) Score(X, Y mat.Matrix) float64 {
+	Ypred := m.Predict(X, nil)
+	return metrics.AccuracyScore(Y, Ypred, true, nil)
+}
@@ -0,0 +1,163 @@
+package svm
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/pa-m/sklearn/base"
+	"github.com/pa-m/sklearn/datasets"
+	"github.com/pa-m/sklearn/preprocessing"
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestSVC_Multiclass(t *testing.T) {
+	ds := datasets.LoadIris()
+	X, _ := preprocessing.NewStandardScaler().FitTransform(ds.X, nil)
+	clf := NewSVC()
+	clf.Probability = true
+	clf.RandomState = base.NewSource(7)
+	clf.Fit(X, ds.Y)
+	if len(clf.Classes) != 3 || len(clf.Model) != 3 {
+		t.Fatalf("expected 3 classes and 3 pairs, got %v and %d models", clf.Classes, len(clf.Model))
+	}
+	if score := clf.Score(X, ds.Y); score < .95 {
+		t.Errorf("expected accuracy >= .95, got %g", score)
+	}
+	Ypred := clf.Predict(X, nil)
+
+	// the model of the pair (0, 2) is the binary classifier of classes 0 and 2, its ovo decision being positive for 0
+	var rows []int
+	for i := 0; i < 150; i++ {
+		if ds.Y.At(i, 0) != 1 {
+			rows = append(rows, i)
+		}
+	}
+	binary := NewSVC()
+	binary.Fit(base.ToCSR(X).SelectRows(rows).ToDense(), base.ToCSR(ds.Y).SelectRows(rows).ToDense())
+	clf.DecisionFunctionShape = "ovo"
+	expected := binary.DecisionFunction(X, nil)
+	expected.Scale(-1, expected)
+	if D := clf.DecisionFunction(X, nil); !mat.EqualApprox(D.ColView(1), expected, 1e-6) {
+		t.Error("expected the decision of the pair (0, 2) to be the opposite of the binary decision of classes 0 and 2")
+	}
+
+	// ovr decisions are votes plus a confidence in (-1/3, 1/3)
+	clf.DecisionFunctionShape = "ovr"
+	D := clf.DecisionFunction(X, nil)
+	if _, c := D.Dims(); c != 3 {
+		t.Fatalf("expected a decision per class, got %d", c)
+	}
+	P := clf.PredictProba(X, nil)
+	agree := 0
+	for i := 0; i < 150; i++ {
+		votes := 0.
+		for c := 0; c < 3; c++ {
+			votes += math.Round(D.At(i, c))
+		}
+		if votes != 3 {
+			t.Errorf("row %d: expected 3 votes, got %v", i, D.RawRowView(i))
+		}
+		if best := floatsArgmax(D.RawRowView(i)); math.Round(D.At(i, best)) == 2 && clf.Classes[best] != Ypred.At(i, 0) {
+			t.Errorf("row %d: the class with 2 votes is not predicted", i)
+		}
+		if p := P.RawRowView(i); math.Abs(p[0]+p[1]+p[2]-1) > 1e-9 {
+			t.Errorf("row %d: probabilities %v do not sum to 1", i, p)
+		}
+		if clf.Classes[floatsArgmax(P.RawRowView(i))] == Ypred.At(i, 0) {
+			agree++
+		}
+	}
+	if agree < 145 {
+		t.Errorf("expected the most probable class to be predicted, got %d agreements out of 150", agree)
+	}
+}
+
+// floatsArgmax returns the index of the first maximum of a
+func floatsArgmax(a []float64) int {
+	best := 0
+	for i, v := range a {
+		if v > a[best] {
+			best = i
+		}
+	}
+	return best
+}
+
+func TestSVC_DecisionFunctionSign(t *testing.T) {
+	// classes 0, 1 and 2 are around x=0, x=5 and x=10
+	X := mat.NewDense(9, 1, []float64{-1, 0, 1, 4, 5, 6, 9, 10, 11})
+	Y := mat.NewDense(9, 1, []float64{0, 0, 0, 1, 1, 1, 2, 2, 2})
+	clf := NewSVC()
+	clf.Kernel = "linear"
+	clf.DecisionFunctionShape = "ovo"
+	clf.Fit(X, Y)
+	// the ovo decision of the pairs (0, 1), (0, 2) and (1, 2) is positive for the first class of the pair, as in sklearn
+	expected := [][]float64{{1, 1, 0}, {-1, 0, 1}, {0, -1, -1}}
+	D := clf.DecisionFunction(mat.NewDense(3, 1, []float64{0, 5, 10}), nil)
+	for s, signs := range expected {
+		for k, sign := range signs {
+			if sign != 0 && D.At(s, k)*sign <= 0 {
+				t.Errorf("class %d, pair %d: expected a decision of sign %g, got %g", s, k, sign, D.At(s, k))
+			}
+		}
+	}
+	// the binary decision is positive for Classes[1]
+	binary := NewSVC()
+	binary.Kernel = "linear"
+	binary.Fit(X.Slice(0, 6, 0, 1), Y.Slice(0, 6, 0, 1))
+	if D := binary.DecisionFunction(mat.NewDense(2, 1, []float64{0, 5}), nil); D.At(0, 0) >= 0 || D.At(1, 0) <= 0 {
+		t.Errorf("expected a binary decision negative for class 0 and positive for class 1, got %v", D.RawMatrix().Data)
+	}
+}
+
+func TestSVC_ClassWeight(t *testing.T) {
+	// 50, 50 and 10 samples of the 3 iris classes
+	ds := datasets.LoadIris()
+	X, _ := preprocessing.NewStandardScaler().FitTransform(ds.X, nil)
+	rows := make([]int, 110)
+	for i := range rows {
+		rows[i] = i
+	}
+	X, Y := base.ToCSR(X).SelectRows(rows).ToDense(), base.ToCSR(ds.Y).SelectRows(rows).ToDense()
+	weights := []float64{110. / 150, 110. / 150, 110. / 30}
+	sampleWeight := make([]float64, 110)
+	for i := range sampleWeight {
+		sampleWeight[i] = weights[int(Y.At(i, 0))]
+	}
+	balanced := NewSVC()
+	balanced.ClassWeight = "balanced"
+	balanced.Fit(X, Y)
+	expected := balanced.DecisionFunction(X, nil)
+	for _, classWeight := range []interface{}{weights, map[float64]float64{0: weights[0], 1: weights[1], 2: weights[2]}} {
+		clf := NewSVC()
+		clf.ClassWeight = classWeight
+		clf.Fit(X, Y)
+		if !mat.EqualApprox(clf.DecisionFunction(X, nil), expected, 1e-6) {
+			t.Errorf("%T: expected the balanced decisions", classWeight)
+		}
+	}
+	weighted := NewSVC()
+	weighted.FitWeighted(X, Y, sampleWeight)
+	if !mat.EqualApprox(weighted.DecisionFunction(X, nil), expected, 1e-6) {
+		t.Error("expected class weights to scale sample weights")
+	}
+
+	for _, params := range []map[string]interface{}{
+		{"ClassWeight": "auto"},
+		{"ClassWeight": []float64{1, 2}},
+		{"DecisionFunctionShape": "ovx"},
+	} {
+		clf := NewSVC()
+		clf.SetParams(params)
+		if err := clf.FitE(X, Y); !errors.Is(err, base.ErrInvalidParam) {
+			t.Errorf("%v: expected ErrInvalidParam, got %v", params, err)
+		}
+	}
+	if err := NewSVC().FitE(X.Slice(0, 50, 0, 4), Y.Slice(0, 50, 0, 1)); !errors.Is(err, base.ErrInvalidParam) {
+		t.Errorf("expected ErrInvalidParam for a single class, got %v", err)
+	}
+	if err := NewSVC().FitE(X, mat.NewDense(110, 2, nil)); !errors.Is(err, base.ErrShapeMismatch) {
+		t.Errorf("expected ErrShapeMismatch for 2 outputs, got %v", err)
+	}
+}
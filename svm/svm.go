@@ -4,10 +4,8 @@ import (
 	"fmt"
 	"math"
 
-	"golang.org/x/exp/rand"
-
 	"github.com/pa-m/sklearn/base"
-	"github.com/pa-m/sklearn/metrics"
+	"github.com/pa-m/sklearn/gaussian_process/kernels"
 	"gonum.org/v1/gonum/mat"
 )
 
@@ -21,168 +19,111 @@ import (
 
 // Model for SVM
 type Model struct {
-	X              *mat.Dense
-	Y              []float64
-	KernelFunction func(X1, X2 []float64) float64
+	X *mat.Dense
+	// SparseX holds the support vectors instead of X when the model was fitted on a sparse X
+	SparseX *base.CSR
+	// X32 holds the support vectors instead of X when the model was fitted on a base.General32 X
+	X32                   base.General32
+	Y                     []float64
+	KernelFunction        func(X1, X2 []float64) float64         `persist:"-"`
+	SparseKernelFunction  func(X1, X2 base.SparseVector) float64 `persist:"-"`
+	Float32KernelFunction func(X1, X2 []float32) float64         `persist:"-"`
+
+	// Precomputed is true when X is the kernel matrix between samples and the training samples
+	Precomputed bool
 
 	B       float64
 	Alphas  []float64
 	Support []int
+	// CacheStats are the lookups of the kernel cache during training
+	CacheStats CacheStats
+}
+
+// trainParams are the parameters of the training of a svm model
+type trainParams struct {
+	Epsilon, Tol float64
+	MaxIter      int
+	CacheSize    uint
+	Shrinking    bool
 }
 
-// %svmTrain Trains an SVM classifier using a simplified version of the SMO
-// %algorithm.
-// %   [model] = SVMTRAIN(X, Y, C, kernelFunction, tol, max_passes) trains an
-// %   SVM classifier and returns trained model. X is the matrix of training
-// %   examples.  Each row is a training example, and the jth column holds the
-// %   jth feature.  Y is a column matrix containing 1 for positive examples
-// %   and 0 for negative examples.  C is the standard SVM regularization
-// %   parameter.  tol is a tolerance value used for determining equality of
-// %   floating point numbers. max_passes controls the number of iterations
-// %   over the dataset (without changes to alpha) before the algorithm quits.
-// %
-// % Note: This is a simplified version of the SMO algorithm for training
-// %       SVMs. In practice, if you want to train an SVM classifier, we
-// %       recommend using an optimized package such as:
-// %
-// %           LIBSVM   (http://www.csie.ntu.edu.tw/~cjlin/libsvm/)
-// %           SVMLight (http://svmlight.joachims.org/)
-func svmTrain(X *mat.Dense, Y []float64, C, Epsilon float64, KernelFunction func(X1, X2 []float64) float64, Tol float64, MaxPasses int, CacheSize uint, RandomState base.RandomState) *Model {
-	m, n := X.Dims()
-	alphas := make([]float64, m)
-	b := 0.
-	E := make([]float64, m)
-	eta := 0.
-	passes := 0
-	L, H := 0., 0.
-	K := cachedKernel(X, CacheSize, KernelFunction)
-	f := func(i int) float64 {
-		y := b
-		for i1 := 0; i1 < m; i1++ {
-			y += alphas[i1] * Y[i1] * K(i1, i)
+// trainer fits a model on X and the output y. C is the upper bound of the dual coefficient of each sample,
+// samples whose C is 0 are ignored
+type trainer func(X mat.Matrix, y, C []float64, kernel Kernel, tp trainParams, mon *base.Monitor) *Model
+
+// weightedSamples returns the indices of the samples whose C is positive
+func weightedSamples(C []float64) []int {
+	samples := make([]int, 0, len(C))
+	for i, c := range C {
+		if c > 0 {
+			samples = append(samples, i)
 		}
-		return y
 	}
-	randIntn := rand.Intn
-	if RandomState != nil {
-		type Intner interface{ Intn(int) int }
-		if intner, ok := RandomState.(Intner); ok {
-			randIntn = intner.Intn
-		} else {
-			randIntn = rand.New(RandomState).Intn
+	return samples
+}
+
+// svcQ returns the matrix Q of the dual of the classification of Y>0 against Y<=0 over samples, its diagonal,
+// and the -1 or 1 output of each sample
+func svcQ(X mat.Matrix, Y []float64, samples []int, kernel Kernel, tp trainParams) (*kernelQ, []float64, []float64) {
+	l := len(samples)
+	Q := &kernelQ{cache: newKernelCache(rowsKernel(X, kernel), samples, tp.CacheSize), pos: make([]int, l), sign: make([]float64, l)}
+	QD := make([]float64, l)
+	for t, i := range samples {
+		Q.pos[t], Q.sign[t] = t, -1
+		if Y[i] > 0 {
+			Q.sign[t] = 1
 		}
+		QD[t] = Q.cache.K(i, i)
 	}
-	for passes < MaxPasses {
-		numChangedAlphas := 0
-		// Step 1 Find a Lagrange multiplier α 1 {that violates the Karush–Kuhn–Tucker (KKT) conditions for the optimization problem.
-		var KKTviolated bool
-		for i := 0; i < m; i++ {
-			Kii := K(i, i)
-			E[i] = f(i) - Y[i]
-			if (Y[i]*E[i] < -Epsilon && alphas[i] < C) || (Y[i]*E[i] > Epsilon && alphas[i] > 0) {
-				KKTviolated = true
-				// Step 2 Pick a second multiplier α 2  and optimize the pair ( α 1 , α 2 )
-				// % In practice, there are many heuristics one can use to select
-				// % the i and j. In this simplified code, we select them randomly.
-				j := randIntn(m - 1)
-				if j >= i {
-					j++
-				}
-				E[j] = f(j) - Y[j]
-
-				alphaiold, alphajold := alphas[i], alphas[j]
-				//% Compute L and H by (10) or (11).
-				if Y[i] == Y[j] {
-					L, H = math.Max(0, alphas[j]+alphas[i]-C), math.Min(C, alphas[j]+alphas[i])
-				} else {
-					L, H = math.Max(0, alphas[j]-alphas[i]), math.Min(C, C+alphas[j]-alphas[i])
-				}
-				if L == H {
-					continue
-				}
-				Kij := K(i, j)
-				Kjj := K(j, j)
-				eta = 2*Kij - Kii - Kjj
-				if eta >= 0 {
-					continue
-				}
-				// % Compute and clip new value for alpha j using (12) and (15).
-				alphas[j] = alphas[j] - (Y[j]*(E[i]-E[j]))/eta
+	return Q, QD, append([]float64(nil), Q.sign...)
+}
 
-				// % Clip
-				alphas[j] = math.Max(L, math.Min(H, alphas[j]))
-				// % Check if change in alpha is significant
-				if alphas[j]-alphajold < Tol {
-					alphas[j] = alphajold
-					continue
-				}
-				// % Determine value for alpha i using (16).
-				alphas[i] += Y[i] * Y[j] * (alphajold - alphas[j])
-				// % Compute b1 and b2 using (17) and (18) respectively.
-				b1 := b - E[i] - Y[i]*(alphas[i]-alphaiold)*Kii - Y[j]*(alphas[j]-alphajold)*Kij
-				b2 := b - E[j] - Y[i]*(alphas[i]-alphaiold)*Kij - Y[j]*(alphas[j]-alphajold)*Kjj
-				// % Compute b by (19).
-				if 0 < alphas[i] && alphas[i] < C {
-					b = b1
-				} else if 0 < alphas[j] && alphas[j] < C {
-					b = b2
-				} else {
-					b = (b1 + b2) / 2
-				}
-				// {
-				// 	Eold := E[i]
-				// 	calcE(i)
-				// 	fmt.Printf("passes %d alpha[%d] %g => %g E[%d] %g=>%g\n", passes, i, alphaiold, alphas[i], i, Eold, E[i])
-				// }
-				numChangedAlphas++
+// newModel returns the model of the samples whose dual coefficient alpha is not 0, trained with Q. y is the output
+// of each sample, nil for regression
+func newModel(X mat.Matrix, kernel Kernel, Q *kernelQ, samples []int, alpha, y []float64, B float64) *Model {
+	model := &Model{B: B, CacheStats: Q.cache.stats}
+	for t, i := range samples {
+		if alpha[t] != 0 {
+			model.Support = append(model.Support, i)
+			model.Alphas = append(model.Alphas, alpha[t])
+			if y != nil {
+				model.Y = append(model.Y, y[t])
 			}
-			// Step 3: Repeat steps 1 and 2 until convergence.
-		}
-		if !KKTviolated {
-			break
-		}
-		if numChangedAlphas == 0 {
-			passes++
-		} else {
-			passes = 0
-		}
-	}
-	idx := make([]int, 0)
-	for i := 0; i < m; i++ {
-		if alphas[i] > Tol {
-			idx = append(idx, i)
 		}
 	}
-	model := &Model{
-		X:              mat.NewDense(len(idx), n, nil),
-		Y:              make([]float64, len(idx)),
-		KernelFunction: KernelFunction,
-		B:              b,
-		Alphas:         make([]float64, len(idx)),
-		Support:        idx,
-	}
-	for ii, i := range idx {
-		model.X.SetRow(ii, X.RawRowView(i))
-		model.Y[ii] = Y[i]
-		model.Alphas[ii] = alphas[i]
-	}
+	model.setKernel(kernel)
+	model.setSupportVectors(X, model.Support)
 	return model
 }
 
+// svmTrain trains a C-SVC classifying y>0 against y<=0 by solving its dual with the libsvm solver
+func svmTrain(X mat.Matrix, Y, C []float64, kernel Kernel, tp trainParams, mon *base.Monitor) *Model {
+	samples := weightedSamples(C)
+	l := len(samples)
+	Q, QD, y := svcQ(X, Y, samples, kernel, tp)
+	p, Cs := make([]float64, l), make([]float64, l)
+	for t, i := range samples {
+		p[t], Cs[t] = -1, C[i]
+	}
+	alpha, rho, _ := newSolver(Q, QD, p, append([]float64(nil), y...), Cs, make([]float64, l), tp.Tol, tp.Shrinking, tp.MaxIter, mon).solve()
+	return newModel(X, kernel, Q, samples, alpha, y, -rho)
+}
+
 // %svmPredict returns a vector of predictions using a trained SVM model
 // %(svmTrain).
 // %   pred = SVMPREDICT(model, X) returns a vector of predictions using a
 // %   trained SVM model (svmTrain). X is a mxn matrix where there each
 // %   example is a row. model is a svm model returned from svmTrain.
 // %   predictions pred is a m x 1 column of predictions of {0, 1} values.
-func svmPredict(model *Model, X, Y *mat.Dense, output int, binary bool) {
+func svmPredict(model *Model, X mat.Matrix, Y *mat.Dense, output int, binary bool) {
 	NSamples, _ := X.Dims()
+	K := model.kernelTo(X)
 
 	Ymat := Y.RawMatrix()
 	for i, yoff := 0, output; i < NSamples; i, yoff = i+1, yoff+Ymat.Stride {
 		prediction := 0.
 		for j := range model.Alphas {
-			prediction += model.Alphas[j] * model.Y[j] * model.KernelFunction(X.RawRowView(i), model.X.RawRowView(j))
+			prediction += model.Alphas[j] * model.Y[j] * K(i, j)
 		}
 		prediction += model.B
 		if binary {
@@ -199,136 +140,166 @@ func svmPredict(model *Model, X, Y *mat.Dense, output int, binary bool) {
 
 // BaseLibSVM is a base for SVC and SVR
 type BaseLibSVM struct {
-	C, Epsilon  float64
-	Kernel      interface{} // string or func(a, b []float64) float64
-	Degree      float64
-	Gamma       float64
-	Coef0       float64
-	Tol         float64
-	Shrinking   bool
+	C, Epsilon float64
+	// Kernel is "linear", "poly", "rbf", "sigmoid", "precomputed", a func(a, b []float64) float64, a Kernel
+	// or a gaussian_process/kernels.Kernel. with "precomputed", X is the kernel matrix between samples and the training samples
+	Kernel interface{}
+	Degree float64
+	Gamma  float64
+	Coef0  float64
+	Tol    float64
+	// Shrinking removes from the optimization the bounded dual coefficients which are unlikely to change
+	Shrinking bool
+	// CacheSize bounds the kernel cache of the training of each model, in MB
 	CacheSize   uint
 	RandomState base.Source
 
+	// MaxIter is the maximum number of iterations of the solver, no limit if <= 0
 	MaxIter        int
-	Model          []*Model
-	Support        [][]int
-	SupportVectors [][][]float64
-}
-
-// SVC struct
-type SVC struct {
-	BaseLibSVM
-	Probability bool
-	ClassWeight []float64
-	nOutputs    int
+	Model          []*Model      `param:"-"`
+	Support        [][]int       `param:"-"`
+	SupportVectors [][][]float64 `param:"-"`
 }
 
-// NewSVC ...
-// Kernel: "linear","poly","rbf","sigmoid" default is "rbf"
-// if Gamma<=0 il will be changed to 1/NFeatures
-// Cachesize is in MB. defaults to 200
-func NewSVC() *SVC {
-	m := &SVC{
-		BaseLibSVM: BaseLibSVM{C: 1., Epsilon: 0.1, Kernel: "rbf", Degree: 3., Gamma: 0., Coef0: 0., Shrinking: true, Tol: 1e-3, CacheSize: 200},
+func (m *BaseLibSVM) checkParams() error {
+	if m.C <= 0 {
+		return fmt.Errorf("%w: C must be positive, got %g", base.ErrInvalidParam, m.C)
 	}
-	return m
+	if m.Epsilon < 0 {
+		return fmt.Errorf("%w: Epsilon must be non-negative, got %g", base.ErrInvalidParam, m.Epsilon)
+	}
+	return nil
 }
 
-// PredicterClone for SVC
-func (m *SVC) PredicterClone() base.Predicter {
-	if m == nil {
-		return nil
+// CacheStats returns the kernel cache lookups of the training of the fitted models
+func (m *BaseLibSVM) CacheStats() CacheStats {
+	var stats CacheStats
+	for _, model := range m.Model {
+		stats.Hits += model.CacheStats.Hits
+		stats.Misses += model.CacheStats.Misses
 	}
-	clone := *m
-	if sourceCloner, ok := clone.RandomState.(base.SourceCloner); ok && sourceCloner != base.SourceCloner(nil) {
-		clone.RandomState = sourceCloner.Clone()
-	}
-	return &clone
+	return stats
 }
 
-// IsClassifier returns true for SVC
-func (m *SVC) IsClassifier() bool { return true }
+// IsFitted returns true when the svm models have been fitted. see base.FittedChecker
+func (m *BaseLibSVM) IsFitted() bool { return len(m.Model) > 0 }
 
-// Fit for SVC
-func (m *SVC) Fit(Xmatrix, Ymatrix mat.Matrix) base.Fiter {
-	_, m.nOutputs = Ymatrix.Dims()
-	X, Y := base.ToDense(Xmatrix), base.ToDense(Ymatrix)
-	m.BaseLibSVM.fit(X, Y, svmTrain)
-	return m
-}
+// resetFitted clears the fitted models
+func (m *BaseLibSVM) resetFitted() { m.Model, m.Support, m.SupportVectors = nil, nil, nil }
 
-// GetNOutputs ...
-func (m *SVC) GetNOutputs() int { return m.nOutputs }
-
-func (m *BaseLibSVM) fit(X, Y *mat.Dense, svmTrain func(X *mat.Dense, Y []float64, C, Epsilon float64, KernelFunction func(X1, X2 []float64) float64, Tol float64, MaxPasses int, CacheSize uint, RandomState base.Source) *Model) {
-	NSamples, NFeatures := X.Dims()
-	_, Noutputs := Y.Dims()
-	if m.Gamma <= 0. {
-		m.Gamma = 1. / float64(NFeatures)
+func (m *BaseLibSVM) checkFitted(X mat.Matrix) error {
+	if !m.IsFitted() {
+		return base.ErrNotFitted
 	}
-	m.Model = make([]*Model, Noutputs)
-	var K func(a, b []float64) float64
+	if sx := m.Model[0].SparseX; sx != nil {
+		return base.CheckNFeatures(X, sx.Cols)
+	}
+	if x32 := m.Model[0].X32; x32.Data != nil {
+		return base.CheckNFeatures(X, x32.Cols)
+	}
+	if m.Model[0].X == nil || m.Model[0].X.IsZero() {
+		return nil
+	}
+	_, nFeatures := m.Model[0].X.Dims()
+	return base.CheckNFeatures(X, nFeatures)
+}
+
+// kernel returns the Kernel for m.Kernel. Gamma must have been set
+func (m *BaseLibSVM) kernel() Kernel {
 	switch v := m.Kernel.(type) {
 	case func(a, b []float64) float64:
-		K = v
+		return funcKernel(v)
 	case string:
 		switch v {
 		case "linear":
-			K = (LinearKernel{}).Func
+			return LinearKernel{}
 		case "poly", "polynomial":
-			K = (PolynomialKernel{gamma: m.Gamma, coef0: m.Coef0, degree: m.Degree}).Func
+			return PolynomialKernel{gamma: m.Gamma, coef0: m.Coef0, degree: m.Degree}
 		case "sigmoid":
-			K = (SigmoidKernel{gamma: m.Gamma, coef0: m.Coef0}).Func
+			return SigmoidKernel{gamma: m.Gamma, coef0: m.Coef0}
+		case "precomputed":
+			return precomputedKernel{}
 		default: //rbf
-			K = (RBFKernel{gamma: m.Gamma}).Func
+			return RBFKernel{gamma: m.Gamma}
 		}
 	case Kernel:
-		K = v.Func
+		return v
+	case kernels.Kernel:
+		return GPKernel{v}
 	default:
-		panic(fmt.Errorf("unknown kernel %#v", v))
+		panic(fmt.Errorf("%w: unknown kernel %#v", base.ErrInvalidParam, v))
 	}
-	if m.MaxIter <= 0 {
-		m.MaxIter = math.MaxInt32
-	}
-	m.Support = make([][]int, Noutputs)
-	m.SupportVectors = make([][][]float64, Noutputs)
-	base.Parallelize(-1, Noutputs, func(th, start, end int) {
-		y := make([]float64, NSamples)
-		for output := start; output < end; output++ {
-			mat.Col(y, output, Y)
-			m.Model[output] = svmTrain(X, y, m.C, m.Epsilon, K, m.Tol, m.MaxIter, m.CacheSize, m.RandomState)
-			model := m.Model[output]
-			m.Support[output] = model.Support
-			m.SupportVectors[output] = make([][]float64, len(model.Support))
-			for i := range model.Support {
-				m.SupportVectors[output][i] = model.X.RawRowView(i)
-			}
-		}
-	})
 }
 
-// Predict for SVC
-func (m *SVC) Predict(Xmatrix mat.Matrix, Ymutable mat.Mutable) *mat.Dense {
-	X, Y := base.ToDense(Xmatrix), base.ToDense(Ymutable)
-	nSamples, _ := X.Dims()
-
-	if Y.IsZero() {
-		*Y = *mat.NewDense(nSamples, m.GetNOutputs(), nil)
+// sampleC returns the upper bound of the dual coefficient of each sample: C scaled by sampleWeight if it is not nil
+func (m *BaseLibSVM) sampleC(nSamples int, sampleWeight []float64) []float64 {
+	C := make([]float64, nSamples)
+	for i := range C {
+		C[i] = m.C
+		if sampleWeight != nil {
+			C[i] *= sampleWeight[i]
+		}
 	}
-	binary := true
-	base.Parallelize(-1, m.GetNOutputs(), func(th, start, end int) {
+	return C
+}
 
-		for output := start; output < end; output++ {
+// prepare sets Gamma if it is not positive, and returns X as a *base.CSR if it is sparse, or as a *mat.Dense for a
+// precomputed kernel, the kernel and the parameters of train
+func (m *BaseLibSVM) prepare(X mat.Matrix) (mat.Matrix, Kernel, trainParams) {
+	_, NFeatures := X.Dims()
+	if m.Gamma <= 0. {
+		m.Gamma = 1. / float64(NFeatures)
+	}
+	K := m.kernel()
+	switch {
+	case K == (precomputedKernel{}):
+		X = base.ToDense(X)
+	case base.IsSparse(X):
+		X = base.ToCSR(X)
+	}
+	tp := trainParams{Epsilon: m.Epsilon, Tol: m.Tol, MaxIter: m.MaxIter, CacheSize: m.CacheSize, Shrinking: m.Shrinking}
+	if tp.MaxIter <= 0 {
+		tp.MaxIter = math.MaxInt32
+	}
+	return X, K, tp
+}
 
-			svmPredict(m.Model[output], X, Y, output, binary)
+// fitOutputs fits a model per output. the upper bound of each dual coefficient is C scaled by the weight of its sample if sampleWeight is not nil
+func (m *BaseLibSVM) fitOutputs(X mat.Matrix, Y *mat.Dense, sampleWeight []float64, train trainer, mon *base.Monitor) error {
+	NSamples, _ := X.Dims()
+	_, NOutputs := Y.Dims()
+	C := m.sampleC(NSamples, sampleWeight)
+	ys, Cs := make([][]float64, NOutputs), make([][]float64, NOutputs)
+	for output := range ys {
+		ys[output], Cs[output] = mat.Col(nil, output, Y), C
+	}
+	return m.fit(X, ys, Cs, train, mon)
+}
 
+// fit fits a model per problem, given by the output y of each sample and the upper bound C of its dual coefficient,
+// samples whose C is 0 being ignored. X is a *mat.Dense, a *base.CSR, a *base.CSC or a base.General32
+func (m *BaseLibSVM) fit(X mat.Matrix, ys, Cs [][]float64, train trainer, mon *base.Monitor) error {
+	X, K, tp := m.prepare(X)
+	if r, c := X.Dims(); K == (precomputedKernel{}) && r != c {
+		return fmt.Errorf("%w: a precomputed kernel matrix must be square, got %dx%d", base.ErrShapeMismatch, r, c)
+	}
+	nProblems := len(ys)
+	m.Model = make([]*Model, nProblems)
+	m.Support = make([][]int, nProblems)
+	m.SupportVectors = make([][][]float64, nProblems)
+	base.Parallelize(-1, nProblems, func(th, start, end int) {
+		for k := start; k < end; k++ {
+			m.Model[k] = train(X, ys[k], Cs[k], K, tp, mon)
+			model := m.Model[k]
+			m.Support[k] = model.Support
+			if model.SparseX != nil || model.X32.Data != nil {
+				continue
+			}
+			m.SupportVectors[k] = make([][]float64, len(model.Support))
+			for i := range model.Support {
+				m.SupportVectors[k][i] = model.X.RawRowView(i)
+			}
 		}
 	})
-	return base.FromDense(Ymutable, Y)
-}
-
-// Score for SVC returns accuracy
-func (m *SVC) Score(X, Y mat.Matrix) float64 {
-	Ypred := m.Predict(X, nil)
-	return metrics.AccuracyScore(Y, Ypred, true, nil)
+	return mon.Err()
 }
@@ -1,15 +1,21 @@
 package svm
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"image/color"
+	"math"
 	"os"
 	"os/exec"
+	"testing"
 	"time"
 
+	"golang.org/x/exp/rand"
+
+	"github.com/pa-m/sklearn/base"
 	"github.com/pa-m/sklearn/metrics"
-	"github.com/pa-m/sklearn/preprocessing"
 	"gonum.org/v1/gonum/mat"
 	"gonum.org/v1/plot"
 	"gonum.org/v1/plot/plotter"
@@ -18,6 +24,10 @@ import (
 	"gonum.org/v1/plot/vg/vgimg"
 )
 
+var _ = []base.PredicterE{&SVC{}, &SVR{}}
+var _ base.ProbaPredicter = &SVC{}
+var _ base.DecisionFunctioner = &SVC{}
+
 var visualDebug = flag.Bool("visual", false, "output images for benchmarks and test data")
 
 func ExampleSVC() {
@@ -27,10 +37,6 @@ func ExampleSVC() {
 		0.2, -2., 0.5, -2.4, 0.2, -2.3, 0., -2.7, 1.3, 2.1})
 	Y := mat.NewDense(16, 1, []float64{0, 0, 0, 0, 0, 0, 0, 0, 1, 1, 1, 1, 1, 1, 1, 1})
 
-	// rescale Y to -1..1
-	yscaler := preprocessing.NewMinMaxScaler([]float64{-1, 1})
-	X1 := X
-	Y1, _ := yscaler.FitTransform(Y, nil)
 	plots := [][]*plot.Plot{make([]*plot.Plot, 0, 4)}
 	var clf *SVC
 	for _, kernel := range []string{
@@ -43,8 +49,7 @@ func ExampleSVC() {
 		//clf.C = 1.
 		clf.Gamma = 2.
 		//clf.Tol = 1.e-3
-		clf.MaxIter = 20
-		clf.Fit(X1, Y1)
+		clf.Fit(X, Y)
 		Ypred := mat.NewDense(16, 1, nil)
 		clf.Predict(X, Ypred)
 		fmt.Printf("%s kernel, accuracy:%.3f\n", kernel, metrics.AccuracyScore(Y, Ypred, true, nil))
@@ -172,3 +177,120 @@ func ExampleSVC() {
 	// poly kernel, accuracy:1.000
 	// rbf kernel, accuracy:1.000
 }
+
+func TestSVC_FitContext(t *testing.T) {
+	// random labels need many passes
+	rnd := rand.New(base.NewSource(7))
+	X, Y := mat.NewDense(100, 2, nil), mat.NewDense(100, 1, nil)
+	for i := 0; i < 100; i++ {
+		X.Set(i, 0, rnd.NormFloat64())
+		X.Set(i, 1, rnd.NormFloat64())
+		Y.Set(i, 0, float64(2*rnd.Intn(2)-1))
+	}
+	errStop := errors.New("stop")
+	passes := 0
+	ctx := base.WithProgress(context.Background(), func(p base.Progress) error {
+		passes = p.Epoch
+		if p.Epoch == 2 {
+			return errStop
+		}
+		return nil
+	})
+	clf, reg := NewSVC(), NewSVR()
+	clf.C, reg.C = 1e3, 1e3
+	for _, m := range []base.ContextFiter{clf, reg} {
+		passes = 0
+		if err := m.FitContext(ctx, X, Y); !errors.Is(err, errStop) || passes != 2 {
+			t.Errorf("%T: expected errStop after 2 passes, got %v after %d", m, err, passes)
+		}
+	}
+}
+
+func TestSVC_PredictProba(t *testing.T) {
+	X := mat.NewDense(16, 2, []float64{0.4, -0.7, -1.5, -1., -1.4, -0.9, -1.3, -1.2, -1.1, -0.2, -1.2,
+		-0.4, -0.5, 1.2, -1.5, 2.1, 1., 1., 1.3, 0.8, 1.2, 0.5,
+		0.2, -2., 0.5, -2.4, 0.2, -2.3, 0., -2.7, 1.3, 2.1})
+	Y := mat.NewDense(16, 1, []float64{-1, -1, -1, -1, -1, -1, -1, -1, 1, 1, 1, 1, 1, 1, 1, 1})
+	Y01 := mat.NewDense(16, 1, []float64{0, 0, 0, 0, 0, 0, 0, 0, 1, 1, 1, 1, 1, 1, 1, 1})
+	clf := NewSVC()
+	clf.Kernel = "rbf"
+	clf.Gamma = 2
+	clf.Fit(X, Y)
+	if _, err := base.PredictE(clf, X, nil); err != nil {
+		t.Fatal(err)
+	}
+	func() {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Error("expected a panic without Probability")
+			}
+		}()
+		clf.PredictProba(X, nil)
+	}()
+	clf.Probability = true
+	clf.Fit(X, Y)
+	P := clf.PredictProba(X, nil)
+	D := clf.DecisionFunction(X, nil)
+	for i := 0; i < 16; i++ {
+		if p0, p1 := P.At(i, 0), P.At(i, 1); p0 < 0 || p1 > 1 || math.Abs(p0+p1-1) > 1e-12 {
+			t.Errorf("row %d: invalid probabilities %g %g", i, p0, p1)
+		}
+		if (D.At(i, 0) > 0) != (P.At(i, 1) > .5) {
+			t.Errorf("row %d: decision %g and probability %g disagree", i, D.At(i, 0), P.At(i, 1))
+		}
+	}
+	if auc := metrics.ROCAUCScore(Y01, mat.DenseCopyOf(P.ColView(1)), "", nil); auc < .99 {
+		t.Errorf("expected auc>=.99, got %g", auc)
+	}
+}
+
+func TestSVC_PredicterClone(t *testing.T) {
+	X := mat.NewDense(8, 2, []float64{0, 0, 0, 1, 1, 0, 1, 1, 4, 4, 4, 5, 5, 4, 5, 5})
+	Y := mat.NewDense(8, 1, []float64{-1, -1, -1, -1, 1, 1, 1, 1})
+	clf := NewSVC()
+	clf.Fit(X, Y)
+	unfitted := clf.PredicterClone().(*SVC)
+	if _, err := unfitted.PredictE(X, nil); !errors.Is(err, base.ErrNotFitted) {
+		t.Errorf("expected ErrNotFitted, got %v", err)
+	}
+	fitted := base.FittedClone(clf).(*SVC)
+	if fitted.Model[0] == clf.Model[0] || &fitted.Model[0].Alphas[0] == &clf.Model[0].Alphas[0] {
+		t.Error("fitted clone shares its models")
+	}
+	expected := clf.Predict(X, nil)
+	clf.Fit(X, mat.NewDense(8, 1, []float64{1, 1, 1, 1, -1, -1, -1, -1}))
+	if actual := fitted.Predict(X, nil); !mat.Equal(expected, actual) {
+		t.Errorf("refitting the original changed the clone predictions %v", mat.Formatted(actual.T()))
+	}
+}
+
+func TestSVC_FitWeighted(t *testing.T) {
+	// the last sample is close to the margin and its zero weight must keep it out of the support vectors
+	X := mat.NewDense(9, 2, []float64{0, 0, 0, 1, 1, 0, 1, 1, 4, 4, 4, 5, 5, 4, 5, 5, 2, 2})
+	Y := mat.NewDense(9, 1, []float64{-1, -1, -1, -1, 1, 1, 1, 1, 1})
+	sampleWeight := []float64{1, 1, 1, 1, 1, 1, 1, 1, 0}
+	clf, reg := NewSVC(), NewSVR()
+	for _, m := range []base.WeightedFiter{clf, reg} {
+		if err := base.FitWeighted(m, X, Y, sampleWeight); err != nil {
+			t.Fatal(err)
+		}
+		var support []int
+		switch m := m.(type) {
+		case *SVC:
+			support = m.Support[0]
+		case *SVR:
+			support = m.Support[0]
+		}
+		for _, i := range support {
+			if i == 8 {
+				t.Errorf("%T: a zero weight sample is a support vector", m)
+			}
+		}
+		Ypred := m.(base.Predicter).Predict(X.Slice(0, 8, 0, 2), nil)
+		for i := 0; i < 8; i++ {
+			if (Ypred.At(i, 0) > .5) != (Y.At(i, 0) > 0) {
+				t.Errorf("%T: wrong prediction %g for sample %d", m, Ypred.At(i, 0), i)
+			}
+		}
+	}
+}
@@ -72,7 +72,6 @@ func ExampleSVR() {
 		svr.RandomState = randomState
 		svr.Tol = math.Sqrt(Epsilon)
 
-		svr.MaxIter = 5
 		svr.Fit(Xsc, Ysc)
 		svr.Predict(Xsc, Ypred[opt.kernel])
 		Ypred[opt.kernel], _ = yscaler.InverseTransform(Ypred[opt.kernel], nil)
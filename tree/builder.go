@@ -0,0 +1,303 @@
+package tree
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"golang.org/x/exp/rand"
+
+	"github.com/pa-m/sklearn/base"
+	"gonum.org/v1/gonum/blas/blas64"
+	"gonum.org/v1/gonum/mat"
+)
+
+// featureThreshold is the minimum difference between two feature values for them to be split apart
+const featureThreshold = 1e-7
+
+// BaseDecisionTree holds the parameters and the fitted tree shared by DecisionTreeClassifier and DecisionTreeRegressor.
+// Splitter is "best" (or "") to evaluate all thresholds, or "random" to draw one threshold per feature as extra-trees do.
+// MaxDepth 0 means unlimited depth.
+// MaxFeatures is the number of features considered for each split: nil or "" for all features, "sqrt" (or "auto"), "log2",
+// an int count or a float64 fraction of the features.
+// RandomState draws the features considered when MaxFeatures is less than the number of features, and the thresholds of the random splitter
+type BaseDecisionTree struct {
+	Criterion           string
+	Splitter            string
+	MaxDepth            int
+	MinSamplesSplit     int
+	MinSamplesLeaf      int
+	MaxFeatures         interface{}
+	MinImpurityDecrease float64
+	RandomState         base.RandomState
+	// runtime filled members
+	Tree               *Tree     `param:"-"`
+	FeatureImportances []float64 `param:"-"`
+}
+
+// IsFitted returns true when the tree has been built
+func (m *BaseDecisionTree) IsFitted() bool { return m.Tree != nil }
+
+// GetNOutputs returns the number of outputs of the fitted tree
+func (m *BaseDecisionTree) GetNOutputs() int {
+	if m.Tree == nil {
+		return 0
+	}
+	return m.Tree.NOutputs
+}
+
+// GetDepth returns the depth of the fitted tree
+func (m *BaseDecisionTree) GetDepth() int { return m.Tree.MaxDepth }
+
+// GetNLeaves returns the number of leaves of the fitted tree
+func (m *BaseDecisionTree) GetNLeaves() int { return m.Tree.NLeaves() }
+
+// Apply returns the index of the leaf each sample of X falls in, as a NSamples x 1 matrix
+func (m *BaseDecisionTree) Apply(X mat.Matrix) *mat.Dense {
+	base.MustBeFitted(m)
+	Xd := base.ToDense(X)
+	nSamples, _ := Xd.Dims()
+	leaves := mat.NewDense(nSamples, 1, nil)
+	for i := 0; i < nSamples; i++ {
+		leaves.Set(i, 0, float64(m.Tree.Apply(Xd.RawRowView(i))))
+	}
+	return leaves
+}
+
+// resetFitted clears the runtime filled members
+func (m *BaseDecisionTree) resetFitted() {
+	m.Tree, m.FeatureImportances = nil, nil
+}
+
+// nMaxFeatures returns the number of features to consider for each split
+func (m *BaseDecisionTree) nMaxFeatures(nFeatures int) int {
+	n := nFeatures
+	switch v := m.MaxFeatures.(type) {
+	case nil:
+	case string:
+		switch v {
+		case "":
+		case "sqrt", "auto":
+			n = int(math.Sqrt(float64(nFeatures)))
+		case "log2":
+			n = int(math.Log2(float64(nFeatures)))
+		default:
+			panic(fmt.Errorf("%w: unknown MaxFeatures %q", base.ErrInvalidParam, v))
+		}
+	case int:
+		n = v
+	case float64:
+		if v <= 0 || v > 1 {
+			panic(fmt.Errorf("%w: MaxFeatures fraction must be in (0,1], got %g", base.ErrInvalidParam, v))
+		}
+		n = int(v * float64(nFeatures))
+	default:
+		panic(fmt.Errorf("%w: MaxFeatures must be a string, an int or a float64, got %T", base.ErrInvalidParam, v))
+	}
+	if n < 1 {
+		n = 1
+	}
+	if n > nFeatures {
+		panic(fmt.Errorf("%w: MaxFeatures %d > %d features", base.ErrInvalidParam, n, nFeatures))
+	}
+	return n
+}
+
+// fit builds m.Tree. tree has the shape and classes of the outputs set, crit evaluates splits
+func (m *BaseDecisionTree) fit(X *mat.Dense, sampleWeight []float64, tree *Tree, crit criterion) {
+	nSamples, nFeatures := X.Dims()
+	if m.MaxDepth < 0 {
+		panic(fmt.Errorf("%w: MaxDepth must be >= 0, got %d", base.ErrInvalidParam, m.MaxDepth))
+	}
+	switch m.Splitter {
+	case "", "best", "random":
+	default:
+		panic(fmt.Errorf("%w: unknown Splitter %q", base.ErrInvalidParam, m.Splitter))
+	}
+	if m.MinImpurityDecrease < 0 {
+		panic(fmt.Errorf("%w: MinImpurityDecrease must be >= 0, got %g", base.ErrInvalidParam, m.MinImpurityDecrease))
+	}
+	if err := base.CheckSampleWeight(X, sampleWeight); err != nil {
+		panic(err)
+	}
+	b := &builder{
+		BaseDecisionTree: m,
+		X:                X.RawMatrix(),
+		w:                sampleWeight,
+		crit:             crit,
+		tree:             tree,
+		samples:          make([]int, nSamples),
+		xf:               make([]float64, nSamples),
+		features:         make([]int, nFeatures),
+		value:            make([]float64, tree.NValues),
+		minSamplesSplit:  m.MinSamplesSplit,
+		minSamplesLeaf:   m.MinSamplesLeaf,
+		maxFeatures:      m.nMaxFeatures(nFeatures),
+	}
+	if b.minSamplesSplit < 2 {
+		b.minSamplesSplit = 2
+	}
+	if b.minSamplesLeaf < 1 {
+		b.minSamplesLeaf = 1
+	}
+	if b.maxFeatures < nFeatures || m.Splitter == "random" {
+		src := m.RandomState
+		if src == nil {
+			src = base.NewLockedSource(uint64(time.Now().UnixNano()))
+		}
+		b.rnd = rand.New(src)
+	}
+	for i := range b.samples {
+		b.samples[i] = i
+	}
+	for f := range b.features {
+		b.features[f] = f
+	}
+	tree.NFeatures = nFeatures
+	b.build(0, nSamples, 0)
+	m.Tree = tree
+	m.FeatureImportances = tree.FeatureImportances()
+}
+
+// builder grows a tree depth first. each node owns samples[start:end], which are partitioned between its children
+type builder struct {
+	*BaseDecisionTree
+	X                               blas64.General
+	w                               []float64
+	crit                            criterion
+	tree                            *Tree
+	samples                         []int
+	xf                              []float64
+	features                        []int
+	value                           []float64
+	minSamplesSplit, minSamplesLeaf int
+	maxFeatures                     int
+	rnd                             *rand.Rand
+}
+
+type split struct {
+	feature     int
+	threshold   float64
+	pos         int
+	improvement float64
+}
+
+// build adds the node for samples[start:end] and its subtree, and returns its index
+func (b *builder) build(start, end, depth int) int {
+	b.crit.init(b.samples[start:end])
+	impurity := b.crit.nodeImpurity()
+	wNode, _ := b.crit.weights()
+	b.crit.nodeValue(b.value)
+	node := b.tree.AddNode(impurity, end-start, wNode, b.value)
+	if depth > b.tree.MaxDepth {
+		b.tree.MaxDepth = depth
+	}
+	if (b.MaxDepth > 0 && depth >= b.MaxDepth) || end-start < b.minSamplesSplit || end-start < 2*b.minSamplesLeaf || impurity <= 1e-12 {
+		return node
+	}
+	sp, ok := b.bestSplit(start, end, impurity)
+	if !ok || sp.improvement+1e-12 < b.MinImpurityDecrease {
+		return node
+	}
+	b.tree.Feature[node], b.tree.Threshold[node] = sp.feature, sp.threshold
+	left := b.build(start, sp.pos, depth+1)
+	right := b.build(sp.pos, end, depth+1)
+	b.tree.Left[node], b.tree.Right[node] = left, right
+	return node
+}
+
+// bestSplit returns the split of samples[start:end] with the largest impurity decrease, and partitions samples accordingly.
+// the "random" splitter evaluates a single threshold drawn uniformly between the min and max of each feature
+func (b *builder) bestSplit(start, end int, impurity float64) (best split, found bool) {
+	samples, xf := b.samples[start:end], b.xf[start:end]
+	nFeatures := len(b.features)
+	bestProxy := math.Inf(-1)
+	// consider evaluates the split of samples sorted or partitioned so that the p first ones go left
+	consider := func(f, p int, threshold float64) {
+		b.crit.update(p)
+		wNode, wLeft := b.crit.weights()
+		if wLeft <= 0 || wNode-wLeft <= 0 {
+			return
+		}
+		impLeft, impRight := b.crit.childrenImpurity()
+		proxy := -wLeft*impLeft - (wNode-wLeft)*impRight
+		if proxy > bestProxy {
+			bestProxy = proxy
+			wRoot := b.tree.WeightedNNodeSamples[0]
+			best = split{feature: f, threshold: threshold, pos: start + p,
+				improvement: wNode / wRoot * (impurity + proxy/wNode)}
+			found = true
+		}
+	}
+	for i, visited := 0, 0; i < nFeatures && visited < b.maxFeatures; i++ {
+		if b.rnd != nil {
+			j := i + b.rnd.Intn(nFeatures-i)
+			b.features[i], b.features[j] = b.features[j], b.features[i]
+		}
+		f := b.features[i]
+		xmin, xmax := math.Inf(1), math.Inf(-1)
+		for k, s := range samples {
+			xf[k] = b.X.Data[s*b.X.Stride+f]
+			xmin, xmax = math.Min(xmin, xf[k]), math.Max(xmax, xf[k])
+		}
+		if xmax <= xmin+featureThreshold {
+			// constant features are not counted in visited
+			continue
+		}
+		visited++
+		b.crit.reset()
+		if b.Splitter == "random" {
+			threshold := xmin + b.rnd.Float64()*(xmax-xmin)
+			if threshold >= xmax {
+				threshold = xmin
+			}
+			p := b.partition(start, end, f, threshold) - start
+			if p >= b.minSamplesLeaf && len(samples)-p >= b.minSamplesLeaf {
+				consider(f, p, threshold)
+			}
+			continue
+		}
+		sort.Sort(byFeature{samples, xf})
+		for p := b.minSamplesLeaf; p <= len(samples)-b.minSamplesLeaf; p++ {
+			if xf[p] <= xf[p-1]+featureThreshold {
+				continue
+			}
+			threshold := (xf[p-1] + xf[p]) / 2
+			if threshold >= xf[p] {
+				threshold = xf[p-1]
+			}
+			consider(f, p, threshold)
+		}
+	}
+	if found {
+		b.partition(start, end, best.feature, best.threshold)
+	}
+	return
+}
+
+// partition moves the samples of samples[start:end] whose feature is <= threshold first, and returns the position of the first other one
+func (b *builder) partition(start, end, feature int, threshold float64) int {
+	i, j := start, end-1
+	for i <= j {
+		if b.X.Data[b.samples[i]*b.X.Stride+feature] <= threshold {
+			i++
+		} else {
+			b.samples[i], b.samples[j] = b.samples[j], b.samples[i]
+			j--
+		}
+	}
+	return i
+}
+
+type byFeature struct {
+	samples []int
+	xf      []float64
+}
+
+func (s byFeature) Len() int           { return len(s.samples) }
+func (s byFeature) Less(i, j int) bool { return s.xf[i] < s.xf[j] }
+func (s byFeature) Swap(i, j int) {
+	s.samples[i], s.samples[j] = s.samples[j], s.samples[i]
+	s.xf[i], s.xf[j] = s.xf[j], s.xf[i]
+}
@@ -0,0 +1,150 @@
+package tree
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/pa-m/sklearn/base"
+	"github.com/pa-m/sklearn/metrics"
+	"gonum.org/v1/gonum/mat"
+)
+
+// DecisionTreeClassifier is a decision tree classifier. Criterion is "gini" or "entropy".
+// multiple outputs are supported, each column of Y being a separate classification task
+type DecisionTreeClassifier struct {
+	BaseDecisionTree
+	// runtime filled members
+	Classes [][]float64 `param:"-"`
+}
+
+// NewDecisionTreeClassifier returns a DecisionTreeClassifier with the gini criterion and unlimited depth
+func NewDecisionTreeClassifier() *DecisionTreeClassifier {
+	return &DecisionTreeClassifier{BaseDecisionTree: BaseDecisionTree{Criterion: "gini", Splitter: "best", MinSamplesSplit: 2, MinSamplesLeaf: 1}}
+}
+
+// PredicterClone returns an unfitted copy of predicter
+func (m *DecisionTreeClassifier) PredicterClone() base.Predicter {
+	clone := *m
+	clone.resetFitted()
+	clone.Classes = nil
+	return base.DeepCopy(&clone).(*DecisionTreeClassifier)
+}
+
+// IsClassifier returns true for DecisionTreeClassifier
+func (*DecisionTreeClassifier) IsClassifier() bool { return true }
+
+// Fit builds the tree from X and the class labels Y
+func (m *DecisionTreeClassifier) Fit(X, Y mat.Matrix) base.Fiter {
+	return m.FitWeighted(X, Y, nil)
+}
+
+// FitWeighted is Fit with class counts and impurities weighted by sampleWeight
+func (m *DecisionTreeClassifier) FitWeighted(Xmatrix, Ymatrix mat.Matrix, sampleWeight []float64) base.Fiter {
+	X, Y := base.ToDense(Xmatrix), base.ToDense(Ymatrix)
+	switch m.Criterion {
+	case "", "gini", "entropy":
+	default:
+		panic(fmt.Errorf("%w: unknown Criterion %q for DecisionTreeClassifier", base.ErrInvalidParam, m.Criterion))
+	}
+	nSamples, nOutputs := Y.Dims()
+	m.Classes = make([][]float64, nOutputs)
+	nClasses := make([]int, nOutputs)
+	y := make([]int, nSamples*nOutputs)
+	for o := range m.Classes {
+		m.Classes[o] = uniqueSorted(mat.Col(nil, o, Y))
+		nClasses[o] = len(m.Classes[o])
+		for i := 0; i < nSamples; i++ {
+			y[i*nOutputs+o] = sort.SearchFloat64s(m.Classes[o], Y.At(i, o))
+		}
+	}
+	crit := newClassificationCriterion(m.Criterion, y, sampleWeight, nClasses)
+	m.fit(X, sampleWeight, &Tree{NOutputs: nOutputs, NClasses: nClasses, NValues: len(crit.total)}, crit)
+	return m
+}
+
+// Predict returns the most represented class of the leaf of each sample, for each output
+func (m *DecisionTreeClassifier) Predict(X mat.Matrix, Ymutable mat.Mutable) *mat.Dense {
+	base.MustBeFitted(m)
+	Xd := base.ToDense(X)
+	Y := base.ToDense(Ymutable)
+	nSamples, _ := Xd.Dims()
+	if Y.IsZero() {
+		*Y = *mat.NewDense(nSamples, m.GetNOutputs(), nil)
+	}
+	for i := 0; i < nSamples; i++ {
+		value := m.Tree.NodeValue(m.Tree.Apply(Xd.RawRowView(i)))
+		for o, classes := range m.Classes {
+			best := 0
+			for c := range classes {
+				if value[c] > value[best] {
+					best = c
+				}
+			}
+			Y.Set(i, o, classes[best])
+			value = value[len(classes):]
+		}
+	}
+	return base.FromDense(Ymutable, Y)
+}
+
+// PredictProba returns the weighted fraction of training samples of each class in the leaf of each sample.
+// columns of the classes of each output follow each other. see base.ProbaPredicter
+func (m *DecisionTreeClassifier) PredictProba(X mat.Matrix, Ymutable mat.Mutable) *mat.Dense {
+	base.MustBeFitted(m)
+	Xd := base.ToDense(X)
+	nSamples, _ := Xd.Dims()
+	P := mat.NewDense(nSamples, m.Tree.NValues, nil)
+	for i := 0; i < nSamples; i++ {
+		p := P.RawRowView(i)
+		copy(p, m.Tree.NodeValue(m.Tree.Apply(Xd.RawRowView(i))))
+		for _, classes := range m.Classes {
+			sum := 0.
+			for _, v := range p[:len(classes)] {
+				sum += v
+			}
+			if sum > 0 {
+				for c := range classes {
+					p[c] /= sum
+				}
+			}
+			p = p[len(classes):]
+		}
+	}
+	return base.FromDense(Ymutable, P)
+}
+
+// FitE is Fit returning an error instead of panicking
+func (m *DecisionTreeClassifier) FitE(X, Y mat.Matrix) error {
+	return base.FitE(m, X, Y)
+}
+
+// PredictE is Predict returning an error instead of panicking
+func (m *DecisionTreeClassifier) PredictE(X mat.Matrix, Y mat.Mutable) (*mat.Dense, error) {
+	if m.Tree == nil {
+		return nil, base.ErrNotFitted
+	}
+	if err := base.CheckNFeatures(X, m.Tree.NFeatures); err != nil {
+		return nil, err
+	}
+	return base.PredictE(m, X, Y)
+}
+
+// Score for DecisionTreeClassifier returns the accuracy of Predict
+func (m *DecisionTreeClassifier) Score(X, Y mat.Matrix) float64 {
+	NSamples, NOutputs := Y.Dims()
+	Ypred := mat.NewDense(NSamples, NOutputs, nil)
+	m.Predict(X, Ypred)
+	return metrics.AccuracyScore(Y, Ypred, true, nil)
+}
+
+// uniqueSorted returns the distinct values of a, sorted
+func uniqueSorted(a []float64) []float64 {
+	sort.Float64s(a)
+	u := a[:0]
+	for _, v := range a {
+		if len(u) == 0 || v != u[len(u)-1] {
+			u = append(u, v)
+		}
+	}
+	return u
+}
@@ -0,0 +1,150 @@
+package tree
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/pa-m/sklearn/base"
+	"github.com/pa-m/sklearn/datasets"
+	"gonum.org/v1/gonum/mat"
+)
+
+var _ = []base.PredicterE{&DecisionTreeClassifier{}, &DecisionTreeRegressor{}}
+var _ base.ProbaPredicter = &DecisionTreeClassifier{}
+var _ = []base.WeightedFiter{&DecisionTreeClassifier{}, &DecisionTreeRegressor{}}
+var _ = []base.FittedChecker{&DecisionTreeClassifier{}, &DecisionTreeRegressor{}}
+
+func ExampleDecisionTreeClassifier() {
+	ds := datasets.LoadIris()
+	clf := NewDecisionTreeClassifier()
+	clf.MaxDepth = 2
+	clf.Fit(ds.X, ds.Y)
+	fmt.Printf("accuracy: %.3f depth: %d leaves: %d\n", clf.Score(ds.X, ds.Y), clf.GetDepth(), clf.GetNLeaves())
+	fmt.Printf("importances: %.3f\n", clf.FeatureImportances)
+	Xtest := mat.NewDense(1, 4, []float64{6, 3, 5, 1.7})
+	fmt.Printf("proba: %.3f\n", mat.Formatted(clf.PredictProba(Xtest, nil)))
+	// Output:
+	// accuracy: 0.960 depth: 2 leaves: 3
+	// importances: [0.000 0.000 0.562 0.438]
+	// proba: [0.000  0.907  0.093]
+}
+
+func TestDecisionTreeClassifier(t *testing.T) {
+	ds := datasets.LoadIris()
+	for _, criterion := range []string{"gini", "entropy"} {
+		clf := NewDecisionTreeClassifier()
+		clf.Criterion = criterion
+		clf.Fit(ds.X, ds.Y)
+		if score := clf.Score(ds.X, ds.Y); score < .99 {
+			t.Errorf("%s: expected a fully grown tree to fit iris, got %g", criterion, score)
+		}
+		P := clf.PredictProba(ds.X, nil)
+		nSamples, nClasses := P.Dims()
+		if nClasses != 3 {
+			t.Fatalf("%s: expected 3 proba columns, got %d", criterion, nClasses)
+		}
+		for i := 0; i < nSamples; i++ {
+			if sum := mat.Sum(P.RowView(i)); math.Abs(sum-1) > 1e-12 {
+				t.Errorf("%s: probas of sample %d sum to %g", criterion, i, sum)
+				break
+			}
+		}
+		sum := 0.
+		for _, v := range clf.FeatureImportances {
+			sum += v
+		}
+		if math.Abs(sum-1) > 1e-12 {
+			t.Errorf("%s: feature importances sum to %g", criterion, sum)
+		}
+	}
+}
+
+func TestDecisionTreeClassifier_Params(t *testing.T) {
+	ds := datasets.LoadIris()
+	clf := NewDecisionTreeClassifier()
+	clf.MinSamplesLeaf = 10
+	clf.Fit(ds.X, ds.Y)
+	for node, n := range clf.Tree.NNodeSamples {
+		if clf.Tree.IsLeaf(node) && n < 10 {
+			t.Errorf("leaf %d has %d < MinSamplesLeaf samples", node, n)
+		}
+	}
+	clf = NewDecisionTreeClassifier()
+	clf.MinSamplesSplit = 40
+	clf.Fit(ds.X, ds.Y)
+	for node, n := range clf.Tree.NNodeSamples {
+		if !clf.Tree.IsLeaf(node) && n < 40 {
+			t.Errorf("node %d with %d < MinSamplesSplit samples was split", node, n)
+		}
+	}
+
+	clf = NewDecisionTreeClassifier()
+	clf.MaxFeatures = "sqrt"
+	clf.RandomState = base.NewSource(7)
+	clf.Fit(ds.X, ds.Y)
+	clf2 := clf.PredicterClone().(*DecisionTreeClassifier)
+	clf2.RandomState = base.NewSource(7)
+	clf2.Fit(ds.X, ds.Y)
+	if clf.Tree.NodeCount() != clf2.Tree.NodeCount() || ExportText(clf, nil) != ExportText(clf2, nil) {
+		t.Error("trees grown with the same RandomState must be equal")
+	}
+
+	clf = NewDecisionTreeClassifier()
+	clf.Splitter = "random"
+	clf.RandomState = base.NewSource(7)
+	clf.Fit(ds.X, ds.Y)
+	if score := clf.Score(ds.X, ds.Y); score < .99 {
+		t.Errorf("expected a fully grown random tree to fit iris, got %g", score)
+	}
+
+	for _, params := range []map[string]interface{}{
+		{"Criterion": "mse"},
+		{"Splitter": "middle"},
+		{"MaxDepth": -1},
+		{"MaxFeatures": "cube"},
+		{"MaxFeatures": 1.5},
+		{"MaxFeatures": 5},
+	} {
+		clf := NewDecisionTreeClassifier()
+		if err := clf.SetParams(params); err != nil {
+			t.Fatal(err)
+		}
+		if err := clf.FitE(ds.X, ds.Y); !errors.Is(err, base.ErrInvalidParam) {
+			t.Errorf("%v: expected ErrInvalidParam, got %v", params, err)
+		}
+	}
+}
+
+func TestDecisionTreeClassifier_FitWeighted(t *testing.T) {
+	X := mat.NewDense(8, 1, []float64{0, 1, 2, 3, 4, 5, 6, 7})
+	Y := mat.NewDense(8, 1, []float64{0, 0, 0, 1, 0, 1, 1, 1})
+	// zero weights ignore the noisy samples 3 and 4
+	clf := NewDecisionTreeClassifier()
+	clf.FitWeighted(X, Y, []float64{1, 1, 1, 0, 0, 1, 1, 1})
+	if clf.GetNLeaves() != 2 {
+		t.Errorf("expected a single split, got\n%s", ExportText(clf, nil))
+	}
+	if th := clf.Tree.Threshold[0]; th < 2 || th >= 5 {
+		t.Errorf("unexpected threshold %g", th)
+	}
+	// integer weights count as repeated samples
+	clf.FitWeighted(X, Y, []float64{3, 1, 1, 1, 1, 1, 1, 1})
+	if v := clf.Tree.NodeValue(0); v[0] != 6 || v[1] != 4 {
+		t.Errorf("unexpected root class counts %v", v)
+	}
+}
+
+func TestDecisionTreeClassifier_MultiOutput(t *testing.T) {
+	X := mat.NewDense(6, 2, []float64{0, 0, 0, 1, 1, 0, 1, 1, 2, 0, 2, 1})
+	Y := mat.NewDense(6, 2, []float64{0, 5, 0, 6, 1, 5, 1, 6, 2, 5, 2, 6})
+	clf := NewDecisionTreeClassifier()
+	clf.Fit(X, Y)
+	if !mat.Equal(clf.Predict(X, nil), Y) {
+		t.Errorf("unexpected prediction\n%v", mat.Formatted(clf.Predict(X, nil)))
+	}
+	if _, c := clf.PredictProba(X, nil).Dims(); c != 5 {
+		t.Errorf("expected 3+2 proba columns, got %d", c)
+	}
+}
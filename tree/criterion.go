@@ -0,0 +1,314 @@
+package tree
+
+import (
+	"math"
+	"sort"
+)
+
+// criterion evaluates the impurity of a node and of its children while the splitter moves sorted samples from the right child to the left one
+type criterion interface {
+	// init computes the statistics of samples, all in the right child
+	init(samples []int)
+	// reset moves all samples back to the right child
+	reset()
+	// update moves samples[pos:newPos] to the left child
+	update(newPos int)
+	nodeImpurity() float64
+	childrenImpurity() (left, right float64)
+	// weights returns the weighted number of samples of the node and of the left child
+	weights() (node, left float64)
+	nodeValue(dst []float64)
+}
+
+// classificationCriterion is the gini or entropy criterion. y holds the class indices of each sample for each output
+type classificationCriterion struct {
+	y          []int
+	w          []float64
+	nOutputs   int
+	offsets    []int
+	entropy    bool
+	samples    []int
+	pos        int
+	total      []float64
+	left       []float64
+	wTotal     float64
+	wLeft      float64
+	rightCount []float64
+}
+
+func newClassificationCriterion(name string, y []int, w []float64, nClasses []int) *classificationCriterion {
+	c := &classificationCriterion{y: y, w: w, nOutputs: len(nClasses), entropy: name == "entropy", offsets: make([]int, len(nClasses)+1)}
+	for o, n := range nClasses {
+		c.offsets[o+1] = c.offsets[o] + n
+	}
+	nValues := c.offsets[len(nClasses)]
+	c.total, c.left, c.rightCount = make([]float64, nValues), make([]float64, nValues), make([]float64, nValues)
+	return c
+}
+
+func (c *classificationCriterion) init(samples []int) {
+	c.samples = samples
+	for i := range c.total {
+		c.total[i] = 0
+	}
+	c.wTotal = 0
+	for _, s := range samples {
+		w := sampleWeightAt(c.w, s)
+		for o := 0; o < c.nOutputs; o++ {
+			c.total[c.offsets[o]+c.y[s*c.nOutputs+o]] += w
+		}
+		c.wTotal += w
+	}
+	c.reset()
+}
+
+func (c *classificationCriterion) reset() {
+	c.pos = 0
+	for i := range c.left {
+		c.left[i] = 0
+	}
+	c.wLeft = 0
+}
+
+func (c *classificationCriterion) update(newPos int) {
+	for _, s := range c.samples[c.pos:newPos] {
+		w := sampleWeightAt(c.w, s)
+		for o := 0; o < c.nOutputs; o++ {
+			c.left[c.offsets[o]+c.y[s*c.nOutputs+o]] += w
+		}
+		c.wLeft += w
+	}
+	c.pos = newPos
+}
+
+func (c *classificationCriterion) impurity(counts []float64, wsum float64) float64 {
+	if wsum <= 0 {
+		return 0
+	}
+	imp := 0.
+	for o := 0; o < c.nOutputs; o++ {
+		if c.entropy {
+			for _, count := range counts[c.offsets[o]:c.offsets[o+1]] {
+				if count > 0 {
+					p := count / wsum
+					imp -= p * math.Log2(p)
+				}
+			}
+		} else {
+			sq := 0.
+			for _, count := range counts[c.offsets[o]:c.offsets[o+1]] {
+				sq += count * count
+			}
+			imp += 1 - sq/(wsum*wsum)
+		}
+	}
+	return imp / float64(c.nOutputs)
+}
+
+func (c *classificationCriterion) nodeImpurity() float64 { return c.impurity(c.total, c.wTotal) }
+
+func (c *classificationCriterion) childrenImpurity() (left, right float64) {
+	for i := range c.rightCount {
+		c.rightCount[i] = c.total[i] - c.left[i]
+	}
+	return c.impurity(c.left, c.wLeft), c.impurity(c.rightCount, c.wTotal-c.wLeft)
+}
+
+func (c *classificationCriterion) weights() (node, left float64) { return c.wTotal, c.wLeft }
+
+func (c *classificationCriterion) nodeValue(dst []float64) { copy(dst, c.total) }
+
+// mseCriterion is the mean squared error criterion. y is the row major NSamples x nOutputs target
+type mseCriterion struct {
+	y          []float64
+	w          []float64
+	nOutputs   int
+	samples    []int
+	pos        int
+	sumTotal   []float64
+	sumLeft    []float64
+	sqSumTotal float64
+	sqSumLeft  float64
+	wTotal     float64
+	wLeft      float64
+}
+
+func newMSECriterion(y, w []float64, nOutputs int) *mseCriterion {
+	return &mseCriterion{y: y, w: w, nOutputs: nOutputs, sumTotal: make([]float64, nOutputs), sumLeft: make([]float64, nOutputs)}
+}
+
+func (c *mseCriterion) init(samples []int) {
+	c.samples = samples
+	for o := range c.sumTotal {
+		c.sumTotal[o] = 0
+	}
+	c.sqSumTotal, c.wTotal = 0, 0
+	for _, s := range samples {
+		w := sampleWeightAt(c.w, s)
+		for o, y := range c.y[s*c.nOutputs : (s+1)*c.nOutputs] {
+			c.sumTotal[o] += w * y
+			c.sqSumTotal += w * y * y
+		}
+		c.wTotal += w
+	}
+	c.reset()
+}
+
+func (c *mseCriterion) reset() {
+	c.pos = 0
+	for o := range c.sumLeft {
+		c.sumLeft[o] = 0
+	}
+	c.sqSumLeft, c.wLeft = 0, 0
+}
+
+func (c *mseCriterion) update(newPos int) {
+	for _, s := range c.samples[c.pos:newPos] {
+		w := sampleWeightAt(c.w, s)
+		for o, y := range c.y[s*c.nOutputs : (s+1)*c.nOutputs] {
+			c.sumLeft[o] += w * y
+			c.sqSumLeft += w * y * y
+		}
+		c.wLeft += w
+	}
+	c.pos = newPos
+}
+
+func (c *mseCriterion) nodeImpurity() float64 {
+	if c.wTotal <= 0 {
+		return 0
+	}
+	imp := c.sqSumTotal / c.wTotal
+	for _, sum := range c.sumTotal {
+		imp -= (sum / c.wTotal) * (sum / c.wTotal)
+	}
+	return math.Max(0, imp/float64(c.nOutputs))
+}
+
+func (c *mseCriterion) childrenImpurity() (left, right float64) {
+	wRight := c.wTotal - c.wLeft
+	if c.wLeft > 0 {
+		left = c.sqSumLeft / c.wLeft
+	}
+	if wRight > 0 {
+		right = (c.sqSumTotal - c.sqSumLeft) / wRight
+	}
+	for o, sum := range c.sumLeft {
+		if c.wLeft > 0 {
+			left -= (sum / c.wLeft) * (sum / c.wLeft)
+		}
+		if wRight > 0 {
+			sumRight := c.sumTotal[o] - sum
+			right -= (sumRight / wRight) * (sumRight / wRight)
+		}
+	}
+	return math.Max(0, left/float64(c.nOutputs)), math.Max(0, right/float64(c.nOutputs))
+}
+
+func (c *mseCriterion) weights() (node, left float64) { return c.wTotal, c.wLeft }
+
+func (c *mseCriterion) nodeValue(dst []float64) {
+	for o, sum := range c.sumTotal {
+		dst[o] = sum / c.wTotal
+	}
+}
+
+// maeCriterion is the mean absolute error criterion. the impurity of a child is the weighted mean absolute deviation from its weighted median,
+// which is recomputed for each candidate split, so that MAE is much slower than MSE on large nodes
+type maeCriterion struct {
+	y        []float64
+	w        []float64
+	nOutputs int
+	samples  []int
+	pos      int
+	wTotal   float64
+	wLeft    float64
+	buf      []weightedValue
+}
+
+type weightedValue struct{ v, w float64 }
+
+func newMAECriterion(y, w []float64, nOutputs int) *maeCriterion {
+	return &maeCriterion{y: y, w: w, nOutputs: nOutputs}
+}
+
+func (c *maeCriterion) init(samples []int) {
+	c.samples = samples
+	c.wTotal = 0
+	for _, s := range samples {
+		c.wTotal += sampleWeightAt(c.w, s)
+	}
+	c.reset()
+}
+
+func (c *maeCriterion) reset() {
+	c.pos, c.wLeft = 0, 0
+}
+
+func (c *maeCriterion) update(newPos int) {
+	for _, s := range c.samples[c.pos:newPos] {
+		c.wLeft += sampleWeightAt(c.w, s)
+	}
+	c.pos = newPos
+}
+
+// median returns the weighted median of output o over samples, and the weighted mean absolute deviation from it
+func (c *maeCriterion) median(samples []int, o int) (median, mad float64) {
+	c.buf = c.buf[:0]
+	wsum := 0.
+	for _, s := range samples {
+		w := sampleWeightAt(c.w, s)
+		c.buf = append(c.buf, weightedValue{c.y[s*c.nOutputs+o], w})
+		wsum += w
+	}
+	if wsum <= 0 {
+		return 0, 0
+	}
+	sort.Slice(c.buf, func(i, j int) bool { return c.buf[i].v < c.buf[j].v })
+	cum := 0.
+	for i, wv := range c.buf {
+		cum += wv.w
+		if cum >= wsum/2 {
+			median = wv.v
+			if cum == wsum/2 && i+1 < len(c.buf) {
+				median = (wv.v + c.buf[i+1].v) / 2
+			}
+			break
+		}
+	}
+	for _, wv := range c.buf {
+		mad += wv.w * math.Abs(wv.v-median)
+	}
+	return median, mad / wsum
+}
+
+func (c *maeCriterion) impurity(samples []int) float64 {
+	imp := 0.
+	for o := 0; o < c.nOutputs; o++ {
+		_, mad := c.median(samples, o)
+		imp += mad
+	}
+	return imp / float64(c.nOutputs)
+}
+
+func (c *maeCriterion) nodeImpurity() float64 { return c.impurity(c.samples) }
+
+func (c *maeCriterion) childrenImpurity() (left, right float64) {
+	return c.impurity(c.samples[:c.pos]), c.impurity(c.samples[c.pos:])
+}
+
+func (c *maeCriterion) weights() (node, left float64) { return c.wTotal, c.wLeft }
+
+func (c *maeCriterion) nodeValue(dst []float64) {
+	for o := range dst {
+		dst[o], _ = c.median(c.samples, o)
+	}
+}
+
+// sampleWeightAt returns the weight of sample i, or 1 if sampleWeight is nil
+func sampleWeightAt(sampleWeight []float64, i int) float64 {
+	if sampleWeight == nil {
+		return 1
+	}
+	return sampleWeight[i]
+}
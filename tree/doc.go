@@ -0,0 +1,2 @@
+// Package tree implements decision trees. it contains DecisionTreeClassifier and DecisionTreeRegressor
+package tree
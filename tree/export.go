@@ -0,0 +1,139 @@
+package tree
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/pa-m/sklearn/base"
+)
+
+// exportedTree returns the fitted tree of m, the name of its criterion and the labels of its leaves
+func exportedTree(m base.Predicter) (t *Tree, criterion string, leafLabel func(node int) string) {
+	switch v := m.(type) {
+	case *DecisionTreeClassifier:
+		base.MustBeFitted(v)
+		criterion = v.Criterion
+		if criterion == "" {
+			criterion = "gini"
+		}
+		return v.Tree, criterion, func(node int) string {
+			value := v.Tree.NodeValue(node)
+			labels := make([]string, len(v.Classes))
+			for o, classes := range v.Classes {
+				best := 0
+				for c := range classes {
+					if value[c] > value[best] {
+						best = c
+					}
+				}
+				labels[o] = formatValue(classes[best])
+				value = value[len(classes):]
+			}
+			if len(labels) == 1 {
+				return labels[0]
+			}
+			return "[" + strings.Join(labels, ", ") + "]"
+		}
+	case *DecisionTreeRegressor:
+		base.MustBeFitted(v)
+		criterion = v.Criterion
+		if criterion == "" {
+			criterion = "mse"
+		}
+		return v.Tree, criterion, nil
+	}
+	panic(fmt.Errorf("%w: %T is not a decision tree", base.ErrInvalidParam, m))
+}
+
+func featureName(featureNames []string, f int) string {
+	if featureNames == nil {
+		return "feature_" + strconv.Itoa(f)
+	}
+	return featureNames[f]
+}
+
+// formatValue returns v rounded to 3 decimals
+func formatValue(v float64) string {
+	return strconv.FormatFloat(math.Round(v*1e3)/1e3, 'f', -1, 64)
+}
+
+func formatValues(values []float64) string {
+	s := make([]string, len(values))
+	for i, v := range values {
+		s[i] = formatValue(v)
+	}
+	return "[" + strings.Join(s, ", ") + "]"
+}
+
+// ExportText returns the rules of a fitted DecisionTreeClassifier or DecisionTreeRegressor, one line per node.
+// featureNames may be nil, features are then named feature_0, feature_1...
+func ExportText(m base.Predicter, featureNames []string) string {
+	t, _, leafLabel := exportedTree(m)
+	if featureNames != nil && len(featureNames) != t.NFeatures {
+		panic(fmt.Errorf("%w: %d feature names for %d features", base.ErrShapeMismatch, len(featureNames), t.NFeatures))
+	}
+	b := new(strings.Builder)
+	var walk func(node, depth int)
+	walk = func(node, depth int) {
+		indent := strings.Repeat("|   ", depth) + "|--- "
+		if t.IsLeaf(node) {
+			if leafLabel != nil {
+				fmt.Fprintf(b, "%sclass: %s\n", indent, leafLabel(node))
+			} else {
+				fmt.Fprintf(b, "%svalue: %s\n", indent, formatValues(t.NodeValue(node)))
+			}
+			return
+		}
+		name := featureName(featureNames, t.Feature[node])
+		fmt.Fprintf(b, "%s%s <= %.2f\n", indent, name, t.Threshold[node])
+		walk(t.Left[node], depth+1)
+		fmt.Fprintf(b, "%s%s >  %.2f\n", indent, name, t.Threshold[node])
+		walk(t.Right[node], depth+1)
+	}
+	walk(0, 0)
+	return b.String()
+}
+
+// ExportGraphviz returns the fitted tree of a DecisionTreeClassifier or DecisionTreeRegressor in the dot language of Graphviz.
+// featureNames may be nil, features are then named X[0], X[1]...
+func ExportGraphviz(m base.Predicter, featureNames []string) string {
+	t, criterion, leafLabel := exportedTree(m)
+	if featureNames != nil && len(featureNames) != t.NFeatures {
+		panic(fmt.Errorf("%w: %d feature names for %d features", base.ErrShapeMismatch, len(featureNames), t.NFeatures))
+	}
+	b := new(strings.Builder)
+	b.WriteString("digraph Tree {\nnode [shape=box] ;\n")
+	for node := 0; node < t.NodeCount(); node++ {
+		var label []string
+		if !t.IsLeaf(node) {
+			name := "X[" + strconv.Itoa(t.Feature[node]) + "]"
+			if featureNames != nil {
+				name = featureNames[t.Feature[node]]
+			}
+			label = append(label, name+" <= "+formatValue(t.Threshold[node]))
+		}
+		label = append(label,
+			criterion+" = "+formatValue(t.Impurity[node]),
+			"samples = "+strconv.Itoa(t.NNodeSamples[node]),
+			"value = "+formatValues(t.NodeValue(node)))
+		if leafLabel != nil {
+			label = append(label, "class = "+leafLabel(node))
+		}
+		fmt.Fprintf(b, "%d [label=%s] ;\n", node, strconv.Quote(strings.Join(label, "\n")))
+	}
+	for node := 0; node < t.NodeCount(); node++ {
+		if t.IsLeaf(node) {
+			continue
+		}
+		if node == 0 {
+			fmt.Fprintf(b, "0 -> %d [labeldistance=2.5, labelangle=45, headlabel=\"True\"] ;\n", t.Left[0])
+			fmt.Fprintf(b, "0 -> %d [labeldistance=2.5, labelangle=-45, headlabel=\"False\"] ;\n", t.Right[0])
+			continue
+		}
+		fmt.Fprintf(b, "%d -> %d ;\n%d -> %d ;\n", node, t.Left[node], node, t.Right[node])
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
@@ -0,0 +1,62 @@
+package tree
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/pa-m/sklearn/datasets"
+	"gonum.org/v1/gonum/mat"
+)
+
+func ExampleExportText() {
+	ds := datasets.LoadIris()
+	clf := NewDecisionTreeClassifier()
+	clf.MaxDepth = 2
+	clf.Fit(ds.X, ds.Y)
+	fmt.Print(ExportText(clf, []string{"sepal length", "sepal width", "petal length", "petal width"}))
+	// Output:
+	// |--- petal length <= 2.45
+	// |   |--- class: 0
+	// |--- petal length >  2.45
+	// |   |--- petal width <= 1.75
+	// |   |   |--- class: 1
+	// |   |--- petal width >  1.75
+	// |   |   |--- class: 2
+}
+
+func ExampleExportGraphviz() {
+	X := mat.NewDense(4, 1, []float64{0, 1, 2, 3})
+	Y := mat.NewDense(4, 1, []float64{1, 1, 3, 4})
+	reg := NewDecisionTreeRegressor()
+	reg.MaxDepth = 1
+	reg.Fit(X, Y)
+	fmt.Print(ExportGraphviz(reg, []string{"x"}))
+	// Output:
+	// digraph Tree {
+	// node [shape=box] ;
+	// 0 [label="x <= 1.5\nmse = 1.688\nsamples = 4\nvalue = [2.25]"] ;
+	// 1 [label="mse = 0\nsamples = 2\nvalue = [1]"] ;
+	// 2 [label="mse = 0.25\nsamples = 2\nvalue = [3.5]"] ;
+	// 0 -> 1 [labeldistance=2.5, labelangle=45, headlabel="True"] ;
+	// 0 -> 2 [labeldistance=2.5, labelangle=-45, headlabel="False"] ;
+	// }
+}
+
+func TestExportGraphviz(t *testing.T) {
+	ds := datasets.LoadIris()
+	clf := NewDecisionTreeClassifier()
+	clf.MaxDepth = 3
+	clf.Fit(ds.X, ds.Y)
+	dot := ExportGraphviz(clf, nil)
+	nodes, edges := strings.Count(dot, "[label="), strings.Count(dot, " -> ")
+	if nodes != clf.Tree.NodeCount() || edges != nodes-1 {
+		t.Errorf("expected %d nodes and %d edges, got %d and %d", clf.Tree.NodeCount(), clf.Tree.NodeCount()-1, nodes, edges)
+	}
+	if !strings.Contains(dot, `0 [label="X[2] <= 2.45\ngini = 0.667\nsamples = 150\nvalue = [50, 50, 50]\nclass = 0"]`) {
+		t.Errorf("unexpected root node in\n%s", dot)
+	}
+	if text := ExportText(clf, nil); strings.Count(text, "class: ") != clf.GetNLeaves() {
+		t.Errorf("expected a line per leaf in\n%s", text)
+	}
+}
@@ -0,0 +1,19 @@
+package tree
+
+import "github.com/pa-m/sklearn/base"
+
+// GetParams returns the parameters of DecisionTreeClassifier. see base.GetFieldParams
+func (m *DecisionTreeClassifier) GetParams() map[string]interface{} { return base.GetFieldParams(m) }
+
+// SetParams sets the parameters of DecisionTreeClassifier. see base.SetFieldParams
+func (m *DecisionTreeClassifier) SetParams(params map[string]interface{}) error {
+	return base.SetFieldParams(m, params)
+}
+
+// GetParams returns the parameters of DecisionTreeRegressor. see base.GetFieldParams
+func (m *DecisionTreeRegressor) GetParams() map[string]interface{} { return base.GetFieldParams(m) }
+
+// SetParams sets the parameters of DecisionTreeRegressor. see base.SetFieldParams
+func (m *DecisionTreeRegressor) SetParams(params map[string]interface{}) error {
+	return base.SetFieldParams(m, params)
+}
@@ -0,0 +1,24 @@
+package tree
+
+import "github.com/pa-m/sklearn/base"
+
+func init() {
+	base.Register(&DecisionTreeClassifier{})
+	base.Register(&DecisionTreeRegressor{})
+}
+
+// MarshalState allows DecisionTreeClassifier to be saved by base.Save
+func (m *DecisionTreeClassifier) MarshalState() (*base.State, error) { return base.MarshalFields(m) }
+
+// UnmarshalState restores a DecisionTreeClassifier saved by base.Save
+func (m *DecisionTreeClassifier) UnmarshalState(st *base.State) error {
+	return base.UnmarshalFields(m, st)
+}
+
+// MarshalState allows DecisionTreeRegressor to be saved by base.Save
+func (m *DecisionTreeRegressor) MarshalState() (*base.State, error) { return base.MarshalFields(m) }
+
+// UnmarshalState restores a DecisionTreeRegressor saved by base.Save
+func (m *DecisionTreeRegressor) UnmarshalState(st *base.State) error {
+	return base.UnmarshalFields(m, st)
+}
@@ -0,0 +1,34 @@
+package tree
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/pa-m/sklearn/base"
+	"github.com/pa-m/sklearn/datasets"
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestSaveLoad(t *testing.T) {
+	ds := datasets.LoadIris()
+	clf := NewDecisionTreeClassifier()
+	clf.MaxFeatures = "sqrt"
+	clf.RandomState = base.NewSource(7)
+	for _, m := range []base.Predicter{clf, NewDecisionTreeRegressor()} {
+		m.Fit(ds.X, ds.Y)
+		buf := new(bytes.Buffer)
+		if err := base.Save(buf, m.(base.Persister)); err != nil {
+			t.Fatalf("%T: %s", m, err)
+		}
+		loaded, err := base.Load(buf)
+		if err != nil {
+			t.Fatalf("%T: %s", m, err)
+		}
+		if !mat.Equal(m.Predict(ds.X, nil), loaded.Predict(ds.X, nil)) {
+			t.Errorf("%T: loaded model predictions differ", m)
+		}
+		if ExportText(m, nil) != ExportText(loaded, nil) {
+			t.Errorf("%T: loaded tree differs", m)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package tree
+
+import (
+	"fmt"
+
+	"github.com/pa-m/sklearn/base"
+	"github.com/pa-m/sklearn/metrics"
+	"gonum.org/v1/gonum/mat"
+)
+
+// DecisionTreeRegressor is a decision tree regressor. Criterion is "mse" (or "squared_error"), whose leaves predict the mean of their samples,
+// or "mae" (or "absolute_error"), whose leaves predict the median of their samples
+type DecisionTreeRegressor struct {
+	BaseDecisionTree
+}
+
+// NewDecisionTreeRegressor returns a DecisionTreeRegressor with the mse criterion and unlimited depth
+func NewDecisionTreeRegressor() *DecisionTreeRegressor {
+	return &DecisionTreeRegressor{BaseDecisionTree: BaseDecisionTree{Criterion: "mse", Splitter: "best", MinSamplesSplit: 2, MinSamplesLeaf: 1}}
+}
+
+// PredicterClone returns an unfitted copy of predicter
+func (m *DecisionTreeRegressor) PredicterClone() base.Predicter {
+	clone := *m
+	clone.resetFitted()
+	return base.DeepCopy(&clone).(*DecisionTreeRegressor)
+}
+
+// IsClassifier returns false for DecisionTreeRegressor
+func (*DecisionTreeRegressor) IsClassifier() bool { return false }
+
+// Fit builds the tree from X and the targets Y
+func (m *DecisionTreeRegressor) Fit(X, Y mat.Matrix) base.Fiter {
+	return m.FitWeighted(X, Y, nil)
+}
+
+// FitWeighted is Fit with leaf values and impurities weighted by sampleWeight
+func (m *DecisionTreeRegressor) FitWeighted(Xmatrix, Ymatrix mat.Matrix, sampleWeight []float64) base.Fiter {
+	X, Y := base.ToDense(Xmatrix), mat.DenseCopyOf(Ymatrix)
+	_, nOutputs := Y.Dims()
+	y := Y.RawMatrix().Data
+	var crit criterion
+	switch m.Criterion {
+	case "", "mse", "squared_error":
+		crit = newMSECriterion(y, sampleWeight, nOutputs)
+	case "mae", "absolute_error":
+		crit = newMAECriterion(y, sampleWeight, nOutputs)
+	default:
+		panic(fmt.Errorf("%w: unknown Criterion %q for DecisionTreeRegressor", base.ErrInvalidParam, m.Criterion))
+	}
+	m.fit(X, sampleWeight, &Tree{NOutputs: nOutputs, NValues: nOutputs}, crit)
+	return m
+}
+
+// Predict returns the value of the leaf of each sample
+func (m *DecisionTreeRegressor) Predict(X mat.Matrix, Ymutable mat.Mutable) *mat.Dense {
+	base.MustBeFitted(m)
+	Xd := base.ToDense(X)
+	Y := base.ToDense(Ymutable)
+	nSamples, _ := Xd.Dims()
+	if Y.IsZero() {
+		*Y = *mat.NewDense(nSamples, m.GetNOutputs(), nil)
+	}
+	for i := 0; i < nSamples; i++ {
+		Y.SetRow(i, m.Tree.NodeValue(m.Tree.Apply(Xd.RawRowView(i))))
+	}
+	return base.FromDense(Ymutable, Y)
+}
+
+// FitE is Fit returning an error instead of panicking
+func (m *DecisionTreeRegressor) FitE(X, Y mat.Matrix) error {
+	return base.FitE(m, X, Y)
+}
+
+// PredictE is Predict returning an error instead of panicking
+func (m *DecisionTreeRegressor) PredictE(X mat.Matrix, Y mat.Mutable) (*mat.Dense, error) {
+	if m.Tree == nil {
+		return nil, base.ErrNotFitted
+	}
+	if err := base.CheckNFeatures(X, m.Tree.NFeatures); err != nil {
+		return nil, err
+	}
+	return base.PredictE(m, X, Y)
+}
+
+// Score for DecisionTreeRegressor returns the R2 score of Predict
+func (m *DecisionTreeRegressor) Score(X, Y mat.Matrix) float64 {
+	NSamples, NOutputs := Y.Dims()
+	Ypred := mat.NewDense(NSamples, NOutputs, nil)
+	m.Predict(X, Ypred)
+	return metrics.R2Score(Y, Ypred, nil, "").At(0, 0)
+}
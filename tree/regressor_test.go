@@ -0,0 +1,65 @@
+package tree
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/pa-m/sklearn/datasets"
+	"gonum.org/v1/gonum/mat"
+)
+
+func ExampleDecisionTreeRegressor() {
+	X := mat.NewDense(8, 1, []float64{0, 1, 2, 3, 4, 5, 6, 7})
+	Y := mat.NewDense(8, 1, []float64{1, 1, 1, 1, 5, 5, 5, 50})
+	for _, criterion := range []string{"mse", "mae"} {
+		reg := NewDecisionTreeRegressor()
+		reg.Criterion = criterion
+		reg.MaxDepth = 1
+		reg.Fit(X, Y)
+		fmt.Printf("%s: threshold %g %.3f\n", criterion, reg.Tree.Threshold[0], mat.Formatted(reg.Predict(mat.NewDense(2, 1, []float64{0, 7}), nil).T()))
+	}
+	// Output:
+	// mse: threshold 6.5 [ 2.714  50.000]
+	// mae: threshold 6.5 [ 1.000  50.000]
+}
+
+func TestDecisionTreeRegressor(t *testing.T) {
+	ds := datasets.LoadDiabetes()
+	for _, criterion := range []string{"mse", "mae"} {
+		reg := NewDecisionTreeRegressor()
+		reg.Criterion = criterion
+		reg.MaxDepth = 5
+		reg.Fit(ds.X, ds.Y)
+		if score := reg.Score(ds.X, ds.Y); score < .6 {
+			t.Errorf("%s: unexpected score %g", criterion, score)
+		}
+		if reg.GetDepth() != 5 {
+			t.Errorf("%s: expected depth 5, got %d", criterion, reg.GetDepth())
+		}
+	}
+	reg := NewDecisionTreeRegressor()
+	reg.Fit(ds.X, ds.Y)
+	if score := reg.Score(ds.X, ds.Y); math.Abs(score-1) > 1e-12 {
+		t.Errorf("expected a fully grown tree to interpolate, got %g", score)
+	}
+}
+
+func TestDecisionTreeRegressor_MultiOutput(t *testing.T) {
+	X := mat.NewDense(6, 1, []float64{0, 1, 2, 3, 4, 5})
+	Y := mat.NewDense(6, 2, []float64{0, 10, 0, 10, 1, 10, 1, 20, 2, 20, 2, 20})
+	reg := NewDecisionTreeRegressor()
+	reg.Fit(X, Y)
+	if !mat.Equal(reg.Predict(X, nil), Y) {
+		t.Errorf("unexpected prediction\n%v", mat.Formatted(reg.Predict(X, nil)))
+	}
+	if leaves := reg.Apply(X); leaves.At(0, 0) != leaves.At(1, 0) || leaves.At(0, 0) == leaves.At(5, 0) {
+		t.Errorf("unexpected leaves %v", mat.Formatted(leaves.T()))
+	}
+	// splitting {0,0,1} only decreases the impurity averaged over outputs by 3/6*(2/9)/2
+	reg.MinImpurityDecrease = .1
+	reg.Fit(X, Y)
+	if reg.GetNLeaves() != 2 {
+		t.Errorf("expected 2 leaves, got\n%s", ExportText(reg, nil))
+	}
+}
@@ -0,0 +1,104 @@
+package tree
+
+// Tree is the binary tree of a fitted decision tree, stored as parallel arrays indexed by node. node 0 is the root.
+// samples with X[Feature[node]] <= Threshold[node] go to Left[node], others to Right[node]. leaves have Feature -1
+type Tree struct {
+	NFeatures, NOutputs int
+	// NValues is the number of values per node: the sum of NClasses for a classifier, NOutputs for a regressor
+	NValues  int
+	NClasses []int
+	MaxDepth int
+
+	Feature              []int
+	Threshold            []float64
+	Left, Right          []int
+	Impurity             []float64
+	NNodeSamples         []int
+	WeightedNNodeSamples []float64
+	// Value holds NValues values per node: weighted class counts for a classifier, predicted outputs for a regressor
+	Value []float64
+}
+
+// NodeCount returns the number of nodes of the tree
+func (t *Tree) NodeCount() int { return len(t.Feature) }
+
+// IsLeaf returns true if node has no children
+func (t *Tree) IsLeaf(node int) bool { return t.Feature[node] < 0 }
+
+// NLeaves returns the number of leaves of the tree
+func (t *Tree) NLeaves() int {
+	n := 0
+	for _, f := range t.Feature {
+		if f < 0 {
+			n++
+		}
+	}
+	return n
+}
+
+// NodeValue returns the values of node. the returned slice shares Value
+func (t *Tree) NodeValue(node int) []float64 {
+	return t.Value[node*t.NValues : (node+1)*t.NValues]
+}
+
+// Apply returns the index of the leaf x falls in
+func (t *Tree) Apply(x []float64) int {
+	node := 0
+	for t.Feature[node] >= 0 {
+		if x[t.Feature[node]] <= t.Threshold[node] {
+			node = t.Left[node]
+		} else {
+			node = t.Right[node]
+		}
+	}
+	return node
+}
+
+// DecisionPath returns the nodes traversed by x from the root to its leaf
+func (t *Tree) DecisionPath(x []float64) []int {
+	path := []int{0}
+	for node := 0; t.Feature[node] >= 0; path = append(path, node) {
+		if x[t.Feature[node]] <= t.Threshold[node] {
+			node = t.Left[node]
+		} else {
+			node = t.Right[node]
+		}
+	}
+	return path
+}
+
+// AddNode appends a leaf node and returns its index. a split is made by setting Feature, Threshold, Left and Right of the node
+func (t *Tree) AddNode(impurity float64, nSamples int, weightedNSamples float64, value []float64) int {
+	t.Feature = append(t.Feature, -1)
+	t.Threshold = append(t.Threshold, 0)
+	t.Left = append(t.Left, -1)
+	t.Right = append(t.Right, -1)
+	t.Impurity = append(t.Impurity, impurity)
+	t.NNodeSamples = append(t.NNodeSamples, nSamples)
+	t.WeightedNNodeSamples = append(t.WeightedNNodeSamples, weightedNSamples)
+	t.Value = append(t.Value, value...)
+	return len(t.Feature) - 1
+}
+
+// FeatureImportances returns the total weighted impurity decrease brought by each feature, normalized to sum to 1
+func (t *Tree) FeatureImportances() []float64 {
+	importances := make([]float64, t.NFeatures)
+	for node, f := range t.Feature {
+		if f < 0 {
+			continue
+		}
+		l, r := t.Left[node], t.Right[node]
+		importances[f] += t.WeightedNNodeSamples[node]*t.Impurity[node] -
+			t.WeightedNNodeSamples[l]*t.Impurity[l] - t.WeightedNNodeSamples[r]*t.Impurity[r]
+	}
+	sum := 0.
+	for _, v := range importances {
+		sum += v
+	}
+	if sum > 0 {
+		for i := range importances {
+			importances[i] /= sum
+		}
+	}
+	return importances
+}